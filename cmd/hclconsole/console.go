@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl2/ext/typeexpr"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hclparse"
+	"github.com/hashicorp/hcl2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const helpText = `Enter an expression to evaluate it and print its value. An expression
+continues onto the following lines until all of its brackets are closed.
+
+Commands:
+  :type <expr>          evaluate an expression and print its type
+  :set <name> = <expr>  evaluate an expression and assign it to a variable
+  :vars                 list the available variables and their types
+  :funcs                list the available functions
+  :help                 show this help
+  :quit                 exit the console
+`
+
+type console struct {
+	parser      *hclparse.Parser
+	ctx         *hcl.EvalContext
+	out         io.Writer
+	interactive bool
+
+	// inputs counts the inputs so far, to give each one a distinct
+	// filename in diagnostics.
+	inputs int
+
+	// newDiagWriter returns a writer for diagnostics. A new writer is
+	// created each time so that it can see all of the inputs so far.
+	newDiagWriter func() hcl.DiagnosticWriter
+}
+
+// run reads and executes inputs until the end of the given reader or until
+// the user quits, returning false if any input produced errors.
+func (c *console) run(r io.Reader) bool {
+	ok := true
+	sc := bufio.NewScanner(r)
+	var buf []byte
+	for {
+		c.prompt(len(buf) != 0)
+		if !sc.Scan() {
+			break
+		}
+		buf = append(buf, sc.Bytes()...)
+		buf = append(buf, '\n')
+		if incomplete(buf) {
+			continue
+		}
+
+		src := buf
+		buf = nil
+		success, quit := c.execute(src)
+		ok = ok && success
+		if quit {
+			return ok
+		}
+	}
+	if c.interactive {
+		fmt.Fprintln(c.out)
+	}
+
+	if len(bytes.TrimSpace(buf)) != 0 {
+		// We ran out of input partway through an expression, so we'll
+		// let the parser report what's missing.
+		success, _ := c.execute(buf)
+		ok = ok && success
+	}
+	if err := sc.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to read input: %s\n", err)
+		return false
+	}
+	return ok
+}
+
+func (c *console) prompt(continuation bool) {
+	if !c.interactive {
+		return
+	}
+	if continuation {
+		fmt.Fprint(c.out, ". ")
+	} else {
+		fmt.Fprint(c.out, "> ")
+	}
+}
+
+// execute runs a single input, which is either an expression or a command,
+// returning false if it produced errors and true for quit if the user
+// asked to exit.
+func (c *console) execute(src []byte) (ok, quit bool) {
+	trimmed := strings.TrimSpace(string(src))
+	if trimmed == "" {
+		return true, false
+	}
+	if !strings.HasPrefix(trimmed, ":") {
+		return c.printValue(src), false
+	}
+
+	cmd, rest := trimmed, ""
+	if space := strings.IndexAny(trimmed, " \t\n"); space >= 0 {
+		cmd, rest = trimmed[:space], trimmed[space+1:]
+	}
+	switch cmd {
+	case ":quit", ":q", ":exit":
+		return true, true
+	case ":help", ":h":
+		fmt.Fprint(c.out, helpText)
+		return true, false
+	case ":type", ":t":
+		return c.printType([]byte(rest)), false
+	case ":set":
+		return c.set([]byte(rest)), false
+	case ":vars":
+		c.listVars()
+		return true, false
+	case ":funcs":
+		c.listFuncs()
+		return true, false
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %s; enter :help for a list of commands.\n", cmd)
+		return false, false
+	}
+}
+
+// addInput registers the given source code with the parser under a new
+// filename, so that diagnostics about it can include source excerpts.
+func (c *console) addInput(src []byte) string {
+	c.inputs++
+	filename := fmt.Sprintf("<input %d>", c.inputs)
+	c.parser.AddFile(filename, &hcl.File{Bytes: src})
+	return filename
+}
+
+func (c *console) eval(src []byte) (cty.Value, bool) {
+	filename := c.addInput(src)
+	expr, diags := hclsyntax.ParseExpression(src, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		c.newDiagWriter().WriteDiagnostics(diags)
+		return cty.DynamicVal, false
+	}
+	val, moreDiags := expr.Value(c.ctx)
+	diags = append(diags, moreDiags...)
+	c.newDiagWriter().WriteDiagnostics(diags)
+	return val, !diags.HasErrors()
+}
+
+func (c *console) printValue(src []byte) bool {
+	val, ok := c.eval(src)
+	if ok {
+		fmt.Fprintln(c.out, formatValue(val))
+	}
+	return ok
+}
+
+func (c *console) printType(src []byte) bool {
+	val, ok := c.eval(src)
+	if ok {
+		fmt.Fprintln(c.out, typeexpr.TypeString(val.Type()))
+	}
+	return ok
+}
+
+func (c *console) set(src []byte) bool {
+	filename := c.addInput(src)
+	f, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		c.newDiagWriter().WriteDiagnostics(diags)
+		return false
+	}
+	attrs, diags := f.Body.JustAttributes()
+	if !diags.HasErrors() && len(attrs) != 1 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid assignment",
+			Detail:   "The :set command expects a single assignment, like :set name = \"value\".",
+			Subject:  f.Body.(*hclsyntax.Body).SrcRange.Ptr(),
+		})
+	}
+	if diags.HasErrors() {
+		c.newDiagWriter().WriteDiagnostics(diags)
+		return false
+	}
+
+	for name, attr := range attrs {
+		val, moreDiags := attr.Expr.Value(c.ctx)
+		diags = append(diags, moreDiags...)
+		c.newDiagWriter().WriteDiagnostics(diags)
+		if diags.HasErrors() {
+			return false
+		}
+		c.ctx.Variables[name] = val
+	}
+	return true
+}
+
+func (c *console) listVars() {
+	names := make([]string, 0, len(c.ctx.Variables))
+	for name := range c.ctx.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(c.out, "%s: %s\n", name, typeexpr.TypeString(c.ctx.Variables[name].Type()))
+	}
+}
+
+func (c *console) listFuncs() {
+	names := make([]string, 0, len(c.ctx.Functions))
+	for name := range c.ctx.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(c.out, name)
+	}
+}
+
+// formatValue returns the given value in native syntax, or a placeholder
+// if it isn't wholly known, since unknown values have no syntax.
+func formatValue(val cty.Value) string {
+	if !val.IsWhollyKnown() {
+		return fmt.Sprintf("(unknown %s)", typeexpr.TypeString(val.Type()))
+	}
+	src := hclwrite.Format(hclwrite.TokensForValue(val).Bytes())
+	return string(src)
+}
+
+// incomplete returns true if the given source code has unclosed brackets,
+// template sequences or heredocs, in which case the console waits for
+// further lines before evaluating it.
+func incomplete(src []byte) bool {
+	tokens, _ := hclsyntax.LexExpression(src, "", hcl.Pos{Line: 1, Column: 1})
+	depth := 0
+	for _, tok := range tokens {
+		switch tok.Type {
+		case hclsyntax.TokenOBrace, hclsyntax.TokenOBrack, hclsyntax.TokenOParen,
+			hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl, hclsyntax.TokenOHeredoc:
+			depth++
+		case hclsyntax.TokenCBrace, hclsyntax.TokenCBrack, hclsyntax.TokenCParen,
+			hclsyntax.TokenTemplateSeqEnd, hclsyntax.TokenCHeredoc:
+			depth--
+		}
+	}
+	return depth > 0
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl2/ext/userfunc"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hclgraph"
+	"github.com/hashicorp/hcl2/hclparse"
+)
+
+// loadDefs loads the definitions in the given file into the given context.
+//
+// A definitions file contains "function" blocks, as understood by the
+// userfunc extension, and attributes that define variables. Variables may
+// refer to one another by name, and to the variables and functions defined
+// in earlier files, and functions may refer to all of the variables.
+func loadDefs(parser *hclparse.Parser, filename string, ctx *hcl.EvalContext) hcl.Diagnostics {
+	var f *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(filename, ".json") {
+		f, diags = parser.ParseJSONFile(filename)
+	} else {
+		f, diags = parser.ParseHCLFile(filename)
+	}
+	if diags.HasErrors() {
+		return diags
+	}
+
+	fns, remain, moreDiags := userfunc.DecodeUserFunctions(f.Body, "function", func() *hcl.EvalContext {
+		return ctx
+	})
+	diags = append(diags, moreDiags...)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+	for name, fn := range fns {
+		ctx.Functions[name] = fn
+	}
+
+	attrs, moreDiags := remain.JustAttributes()
+	diags = append(diags, moreDiags...)
+	ordered, moreDiags := hclgraph.EvalOrder(attrs, nil)
+	diags = append(diags, moreDiags...)
+	for _, attr := range ordered {
+		val, moreDiags := attr.Expr.Value(ctx)
+		diags = append(diags, moreDiags...)
+		ctx.Variables[attr.Name] = val
+	}
+	return diags
+}
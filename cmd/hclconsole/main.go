@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl2/ext/funcs"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hclparse"
+	flag "github.com/spf13/pflag"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const versionStr = "0.0.1-dev"
+
+var (
+	defsFiles   = flag.StringArrayP("defs", "d", nil, "load variables and functions from the given file, which may be given more than once")
+	noFuncs     = flag.BoolP("no-builtins", "", false, "don't make the built-in function library available")
+	showVersion = flag.BoolP("version", "v", false, "show the version number and immediately exit")
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionStr)
+		os.Exit(0)
+	}
+
+	if len(flag.Args()) != 0 {
+		usage()
+	}
+
+	os.Exit(realmain())
+}
+
+func realmain() int {
+	parser := hclparse.NewParser()
+	color := terminal.IsTerminal(int(os.Stderr.Fd()))
+	w, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		w = 80
+	}
+	newDiagWriter := func() hcl.DiagnosticWriter {
+		return hcl.NewDiagnosticTextWriter(os.Stderr, parser.FilesSnapshot(), uint(w), color)
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{},
+	}
+	if *noFuncs {
+		ctx.Functions = map[string]function.Function{}
+	} else {
+		ctx.Functions = funcs.Functions()
+	}
+
+	for _, filename := range *defsFiles {
+		diags := loadDefs(parser, filename, ctx)
+		newDiagWriter().WriteDiagnostics(diags)
+		if diags.HasErrors() {
+			return 1
+		}
+	}
+
+	c := &console{
+		parser:        parser,
+		ctx:           ctx,
+		out:           os.Stdout,
+		interactive:   terminal.IsTerminal(int(os.Stdin.Fd())),
+		newDiagWriter: newDiagWriter,
+	}
+	if !c.run(os.Stdin) {
+		return 1
+	}
+	return 0
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hclconsole [options]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
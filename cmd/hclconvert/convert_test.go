@@ -0,0 +1,312 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := map[string]struct {
+		src        string
+		blockTypes map[string]int
+		wantJSON   string
+		wantNative string // if empty, the same as src
+	}{
+		"literals": {
+			`a = 1
+b = 2.50
+c = true
+d = null
+e = [1, "two"]
+`,
+			nil,
+			`{
+  "a": 1,
+  "b": 2.50,
+  "c": true,
+  "d": null,
+  "e": [
+    1,
+    "two"
+  ]
+}
+`,
+			"",
+		},
+		"escapes": {
+			`a = "tab\there \"quoted\" back\\slash"
+`,
+			nil,
+			`{
+  "a": "tab\there \"quoted\" back\\slash"
+}
+`,
+			"",
+		},
+		"template escapes": {
+			`a = "literal $${a} and %%{b} then ${var.c}"
+b = "${var.d} %%{ if x }"
+`,
+			nil,
+			`{
+  "a": "literal $${a} and %%{b} then ${var.c}",
+  "b": "${var.d} %%{ if x }"
+}
+`,
+			"",
+		},
+		"template directives": {
+			`a = "%{if x}yes%{else}no%{endif}"
+`,
+			nil,
+			`{
+  "a": "%{if x}yes%{else}no%{endif}"
+}
+`,
+			"",
+		},
+		"interpolation only": {
+			`a = "${var.a}"
+b = var.b + 1
+c = [for x in var.c : upper(x)]
+`,
+			nil,
+			`{
+  "a": "${var.a}",
+  "b": "${var.b + 1}",
+  "c": "${[for x in var.c : upper(x)]}"
+}
+`,
+			`a = var.a
+b = var.b + 1
+c = [for x in var.c : upper(x)]
+`,
+		},
+		"heredoc": {
+			`a = <<EOT
+hello ${name}
+$${literal}
+EOT
+`,
+			nil,
+			`{
+  "a": "${<<EOT\nhello ${name}\n$${literal}\nEOT\n}"
+}
+`,
+			"",
+		},
+		"object keys": {
+			`a = {
+  b           = 1
+  "not ident" = "x"
+  "${var.k}"  = 2
+}
+`,
+			nil,
+			`{
+  "a": {
+    "b": 1,
+    "not ident": "x",
+    "${var.k}": 2
+  }
+}
+`,
+			"",
+		},
+		"labelled blocks": {
+			`service "web" "a" {
+  port = 80
+}
+service "web" "b" {
+  port = 81
+}
+service "db" "a" {
+  port = 5432
+}
+`,
+			map[string]int{"service": 2},
+			`{
+  "service": {
+    "web": {
+      "a": {
+        "port": 80
+      },
+      "b": {
+        "port": 81
+      }
+    },
+    "db": {
+      "a": {
+        "port": 5432
+      }
+    }
+  }
+}
+`,
+			"",
+		},
+		"repeated blocks": {
+			`tag {
+  n = 1
+}
+tag {
+  n = 2
+}
+`,
+			map[string]int{"tag": 0},
+			`{
+  "tag": [
+    {
+      "n": 1
+    },
+    {
+      "n": 2
+    }
+  ]
+}
+`,
+			"",
+		},
+		"nested blocks": {
+			`service "web" {
+  port = 80
+  health {
+    path = "/"
+    check "http" {
+      interval = "10s"
+    }
+  }
+}
+`,
+			map[string]int{"service": 1, "health": 0, "check": 1},
+			`{
+  "service": {
+    "web": {
+      "port": 80,
+      "health": {
+        "path": "/",
+        "check": {
+          "http": {
+            "interval": "10s"
+          }
+        }
+      }
+    }
+  }
+}
+`,
+			"",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotJSON, diags := nativeToJSON([]byte(test.src), "test.hcl")
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics converting to JSON: %s", diags.Error())
+			}
+			if string(gotJSON) != test.wantJSON {
+				t.Fatalf("wrong JSON\ngot:\n%s\nwant:\n%s", gotJSON, test.wantJSON)
+			}
+
+			gotNative, diags := jsonToNative(gotJSON, "test.json", test.blockTypes)
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics converting from JSON: %s", diags.Error())
+			}
+			wantNative := test.wantNative
+			if wantNative == "" {
+				wantNative = test.src
+			}
+			if string(gotNative) != wantNative {
+				t.Errorf("wrong native syntax\ngot:\n%s\nwant:\n%s", gotNative, wantNative)
+			}
+		})
+	}
+}
+
+func TestNativeToJSONErrors(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want []string
+	}{
+		"attribute before blocks": {
+			`a = 1
+a {
+}
+`,
+			[]string{"Attribute conflicts with block type"},
+		},
+		"attribute after blocks": {
+			`a {
+}
+a = 1
+`,
+			[]string{"Attribute conflicts with block type"},
+		},
+		"inconsistent labels": {
+			`a "x" {
+}
+a {
+}
+`,
+			[]string{"Inconsistent block labels"},
+		},
+		"comments": {
+			`# comment
+a = 1
+`,
+			[]string{"Comments will be lost"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, diags := nativeToJSON([]byte(test.src), "test.hcl")
+			assertDiagSummaries(t, diags, test.want)
+		})
+	}
+}
+
+func TestJSONToNativeErrors(t *testing.T) {
+	tests := map[string]struct {
+		src        string
+		blockTypes map[string]int
+		want       []string
+	}{
+		"invalid attribute name": {
+			`{"a.b": 1}`,
+			nil,
+			[]string{"Invalid attribute name"},
+		},
+		"block is not an object": {
+			`{"a": 1}`,
+			map[string]int{"a": 0},
+			[]string{"Invalid block representation"},
+		},
+		"invalid template": {
+			`{"a": "${"}`,
+			nil,
+			[]string{"Invalid template"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, diags := jsonToNative([]byte(test.src), "test.json", test.blockTypes)
+			assertDiagSummaries(t, diags, test.want)
+		})
+	}
+}
+
+func assertDiagSummaries(t *testing.T, diags hcl.Diagnostics, want []string) {
+	t.Helper()
+	if len(diags) != len(want) {
+		t.Fatalf("wrong number of diagnostics %d; want %d\n%s", len(diags), len(want), diags.Error())
+	}
+	for i, diag := range diags {
+		if diag.Summary != want[i] {
+			t.Errorf("wrong summary for diagnostic %d %q; want %q", i, diag.Summary, want[i])
+		}
+	}
+}
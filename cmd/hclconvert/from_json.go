@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	hcljson "github.com/hashicorp/hcl2/hcl/json"
+	"github.com/hashicorp/hcl2/hclwrite"
+)
+
+// jsonToNative converts the given JSON syntax source code into the
+// equivalent native syntax.
+//
+// The JSON syntax does not distinguish between attributes and blocks, so
+// the caller must provide the names of the block types along with the number
+// of labels expected for each, as for the blockTypes flag. All other
+// properties are converted to attributes.
+func jsonToNative(src []byte, filename string, blockTypes map[string]int) ([]byte, hcl.Diagnostics) {
+	_, diags := hcljson.Parse(src, filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	root, err := decodeJSON(src)
+	if err != nil {
+		// Should never happen, since the HCL JSON parser accepted it.
+		return nil, append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid JSON",
+			Detail:   fmt.Sprintf("Failed to decode %s: %s.", filename, err),
+		})
+	}
+
+	c := &fromJSON{blockTypes: blockTypes}
+	c.body(root.(jsonObject), "")
+	diags = append(diags, c.diags...)
+	return hclwrite.Format([]byte(c.buf.String())), diags
+}
+
+type fromJSON struct {
+	blockTypes map[string]int
+	buf        strings.Builder
+	diags      hcl.Diagnostics
+}
+
+func (c *fromJSON) errorf(summary, format string, args ...interface{}) {
+	c.diags = append(c.diags, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  summary,
+		Detail:   fmt.Sprintf(format, args...),
+	})
+}
+
+// body writes the attributes and blocks for the given object, where path
+// describes the location of the object for use in diagnostics.
+func (c *fromJSON) body(obj jsonObject, path string) {
+	for _, prop := range obj {
+		propPath := strings.TrimPrefix(path+"."+prop.Name, ".")
+
+		if prop.Name == "//" {
+			// Properties named "//" are comments in the JSON syntax.
+			if comment, ok := prop.Value.(string); ok {
+				for _, line := range strings.Split(comment, "\n") {
+					fmt.Fprintf(&c.buf, "# %s\n", line)
+				}
+			}
+			continue
+		}
+
+		if labels, isBlock := c.blockTypes[prop.Name]; isBlock {
+			c.blocks(prop.Name, prop.Value, labels, nil, propPath)
+			continue
+		}
+
+		if !hclsyntax.ValidIdentifier(prop.Name) {
+			c.errorf("Invalid attribute name", "The property %s cannot be converted to an attribute because %q is not a valid identifier in the native syntax.", propPath, prop.Name)
+			continue
+		}
+		fmt.Fprintf(&c.buf, "%s = %s\n", prop.Name, c.expr(prop.Value))
+	}
+}
+
+// blocks writes the blocks of the given type represented by the given value,
+// which has the given number of labels still to be consumed.
+func (c *fromJSON) blocks(typeName string, val interface{}, labels int, prefix []string, path string) {
+	switch tv := val.(type) {
+	case []interface{}:
+		for i, elem := range tv {
+			c.blocks(typeName, elem, labels, prefix, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case jsonObject:
+		if labels == 0 {
+			c.buf.WriteString(typeName)
+			for _, label := range prefix {
+				fmt.Fprintf(&c.buf, " %s", quoteString(label))
+			}
+			c.buf.WriteString(" {\n")
+			c.body(tv, path)
+			c.buf.WriteString("}\n")
+			return
+		}
+		for _, prop := range tv {
+			labelVals := append(prefix[:len(prefix):len(prefix)], prop.Name)
+			c.blocks(typeName, prop.Value, labels-1, labelVals, path+"."+prop.Name)
+		}
+	default:
+		c.errorf("Invalid block representation", "The property %s must be an object or an array of objects, because %q is a block type.", path, typeName)
+	}
+}
+
+func (c *fromJSON) expr(val interface{}) string {
+	switch tv := val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		if tv {
+			return "true"
+		}
+		return "false"
+	case json.Number:
+		return string(tv)
+	case string:
+		return c.template(tv, true)
+	case []interface{}:
+		elems := make([]string, len(tv))
+		for i, elem := range tv {
+			elems[i] = c.expr(elem)
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case jsonObject:
+		if len(tv) == 0 {
+			return "{}"
+		}
+		var buf strings.Builder
+		buf.WriteString("{\n")
+		for _, prop := range tv {
+			key := prop.Name
+			if !hclsyntax.ValidIdentifier(key) {
+				key = c.template(key, false)
+			}
+			fmt.Fprintf(&buf, "%s = %s\n", key, c.expr(prop.Value))
+		}
+		buf.WriteString("}")
+		return buf.String()
+	default:
+		// Should never happen, since decodeJSON produces only the above.
+		panic(fmt.Sprintf("unsupported JSON value %#v", val))
+	}
+}
+
+// template returns a native syntax expression equivalent to the given JSON
+// string, which is a template. If unwrap is set and the template consists
+// only of a single interpolation sequence then the interpolated expression
+// is returned alone, since that is what the template evaluates to.
+func (c *fromJSON) template(s string, unwrap bool) string {
+	src := []byte(s)
+	expr, diags := hclsyntax.ParseTemplate(src, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		c.errorf("Invalid template", "The string %q is not a valid template: %s.", s, diags.Error())
+		return quoteString(s)
+	}
+	if wrap, ok := expr.(*hclsyntax.TemplateWrapExpr); ok && unwrap {
+		return string(wrap.Wrapped.Range().SliceBytes(src))
+	}
+
+	tokens, _ := hclsyntax.LexTemplate(src, "", hcl.Pos{Line: 1, Column: 1})
+	var buf strings.Builder
+	buf.WriteByte('"')
+	depth := 0
+	prevEnd := 0
+	for _, tok := range tokens {
+		if tok.Type == hclsyntax.TokenEOF {
+			break
+		}
+		if depth == 0 && tok.Type == hclsyntax.TokenStringLit {
+			buf.WriteString(escapeQuoted(string(tok.Bytes)))
+		} else {
+			buf.Write(src[prevEnd:tok.Range.End.Byte])
+		}
+		switch tok.Type {
+		case hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl:
+			depth++
+		case hclsyntax.TokenTemplateSeqEnd:
+			depth--
+		}
+		prevEnd = tok.Range.End.Byte
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+// quoteString returns a quoted native syntax string whose value is the given
+// string, escaping any template sequences.
+func quoteString(s string) string {
+	s = strings.Replace(s, "${", "$${", -1)
+	s = strings.Replace(s, "%{", "%%{", -1)
+	return `"` + escapeQuoted(s) + `"`
+}
+
+// escapeQuoted escapes the given literal for use in a quoted template. Any
+// template escape sequences are left unchanged.
+func escapeQuoted(s string) string {
+	var buf strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\':
+			buf.WriteString(`\\`)
+		case '"':
+			buf.WriteString(`\"`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	return buf.String()
+}
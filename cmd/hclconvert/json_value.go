@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// jsonObject is a JSON object whose properties are kept in order, since
+// encoding/json would otherwise sort them and lose the order of the source.
+type jsonObject []jsonProperty
+
+type jsonProperty struct {
+	Name  string
+	Value interface{}
+}
+
+// get returns the value of the last property with the given name, or nil
+// if there is no such property.
+func (o jsonObject) get(name string) interface{} {
+	for i := len(o) - 1; i >= 0; i-- {
+		if o[i].Name == name {
+			return o[i].Value
+		}
+	}
+	return nil
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, prop := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := marshalJSON(prop.Name, "")
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		val, err := marshalJSON(prop.Value, "")
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshalJSON is like json.Marshal, or json.MarshalIndent if an indent is
+// given, except that it does not escape HTML characters, which would make
+// expressions in the result harder to read.
+func marshalJSON(v interface{}, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
+
+// decodeJSON decodes the given JSON source into values of the types
+// produced by encoding/json, except that objects are decoded as jsonObject
+// and numbers as json.Number, so that the order of properties and the
+// precision of numbers are preserved.
+func decodeJSON(src []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+	return decodeJSONValue(dec)
+}
+
+func decodeJSONValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := jsonObject{}
+		for dec.More() {
+			nameTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			val, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonProperty{Name: nameTok.(string), Value: val})
+		}
+		_, err := dec.Token() // closing brace
+		return obj, err
+	case json.Delim('['):
+		arr := []interface{}{}
+		for dec.More() {
+			val, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, val)
+		}
+		_, err := dec.Token() // closing bracket
+		return arr, err
+	default:
+		return tok, nil
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	flag "github.com/spf13/pflag"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const versionStr = "0.0.1-dev"
+
+var (
+	toFormat    = flag.StringP("to", "t", "", "the syntax to convert to, either \"json\" or \"hcl\"; by default, JSON files are converted to native syntax and other files to JSON")
+	outputFile  = flag.StringP("out", "o", "", "write to the given file, instead of stdout")
+	blockFlags  = flag.StringArrayP("block", "b", nil, "when converting from JSON, treat properties with the given name as blocks, given as name or name:labels where labels is the number of block labels")
+	showVersion = flag.BoolP("version", "v", false, "show the version number and immediately exit")
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionStr)
+		os.Exit(0)
+	}
+
+	err := realmain(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func realmain(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("at most one input file may be given")
+	}
+
+	filename := "<stdin>"
+	var src []byte
+	var err error
+	if len(args) == 1 {
+		filename = args[0]
+		src, err = ioutil.ReadFile(filename)
+	} else {
+		src, err = ioutil.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %s", filename, err)
+	}
+
+	to := *toFormat
+	if to == "" {
+		to = "json"
+		if strings.HasSuffix(filename, ".json") {
+			to = "hcl"
+		}
+	}
+
+	blockTypes, err := parseBlockFlags(*blockFlags)
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	var diags hcl.Diagnostics
+	switch to {
+	case "json":
+		out, diags = nativeToJSON(src, filename)
+	case "hcl":
+		out, diags = jsonToNative(src, filename, blockTypes)
+	default:
+		return fmt.Errorf("invalid target syntax %q: must be either \"json\" or \"hcl\"", to)
+	}
+
+	if len(diags) != 0 {
+		files := map[string]*hcl.File{
+			filename: {Bytes: src},
+		}
+		color := terminal.IsTerminal(int(os.Stderr.Fd()))
+		w, _, err := terminal.GetSize(int(os.Stderr.Fd()))
+		if err != nil {
+			w = 80
+		}
+		hcl.NewDiagnosticTextWriter(os.Stderr, files, uint(w), color).WriteDiagnostics(diags)
+	}
+	if diags.HasErrors() {
+		return fmt.Errorf("failed to convert %s", filename)
+	}
+
+	if *outputFile != "" {
+		return ioutil.WriteFile(*outputFile, out, 0644)
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+func parseBlockFlags(flags []string) (map[string]int, error) {
+	ret := make(map[string]int)
+	for _, f := range flags {
+		name, labels := f, 0
+		if colon := strings.LastIndexByte(f, ':'); colon >= 0 {
+			var err error
+			name = f[:colon]
+			labels, err = strconv.Atoi(f[colon+1:])
+			if err != nil || labels < 0 {
+				return nil, fmt.Errorf("invalid --block value %q: the number of labels must be a non-negative integer", f)
+			}
+		}
+		ret[name] = labels
+	}
+	return ret, nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hclconvert [options] [file]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// nativeToJSON converts the given native syntax source code into the
+// equivalent JSON syntax.
+func nativeToJSON(src []byte, filename string) ([]byte, hcl.Diagnostics) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	// Comments have no equivalent in the JSON syntax, except as properties
+	// named "//" which cannot appear in all of the same places.
+	tokens, _ := hclsyntax.LexConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	for _, tok := range tokens {
+		if tok.Type == hclsyntax.TokenComment {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Comments will be lost",
+				Detail:   "The JSON syntax has no equivalent of comments, so the comments in this file are not included in the result.",
+				Subject:  tok.Range.Ptr(),
+			})
+			break
+		}
+	}
+
+	c := &toJSON{src: src}
+	obj := c.body(file.Body.(*hclsyntax.Body))
+	diags = append(diags, c.diags...)
+
+	out, err := marshalJSON(obj, "  ")
+	if err != nil {
+		// Should never happen, since we constructed the value ourselves.
+		panic(fmt.Sprintf("failed to serialize JSON: %s", err))
+	}
+	return append(out, '\n'), diags
+}
+
+type toJSON struct {
+	src   []byte
+	diags hcl.Diagnostics
+}
+
+func (c *toJSON) body(body *hclsyntax.Body) jsonObject {
+	type item struct {
+		start int
+		name  string
+		attr  *hclsyntax.Attribute
+	}
+	var items []item
+	for name, attr := range body.Attributes {
+		items = append(items, item{attr.SrcRange.Start.Byte, name, attr})
+	}
+	for _, block := range body.Blocks {
+		items = append(items, item{block.TypeRange.Start.Byte, block.Type, nil})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].start < items[j].start
+	})
+
+	blockTypes := make(map[string]bool)
+	for _, block := range body.Blocks {
+		blockTypes[block.Type] = true
+	}
+
+	ret := jsonObject{}
+	seen := make(map[string]bool)
+	for _, it := range items {
+		if it.attr != nil {
+			if blockTypes[it.name] {
+				c.diags = append(c.diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Attribute conflicts with block type",
+					Detail:   fmt.Sprintf("The JSON syntax cannot represent both an attribute and blocks named %q in the same body.", it.name),
+					Subject:  it.attr.NameRange.Ptr(),
+				})
+				continue
+			}
+			ret = append(ret, jsonProperty{Name: it.name, Value: c.expr(it.attr.Expr)})
+			continue
+		}
+		if seen[it.name] {
+			continue // all blocks of a type are handled together below
+		}
+		seen[it.name] = true
+
+		var blocks []*hclsyntax.Block
+		for _, block := range body.Blocks {
+			if block.Type == it.name {
+				blocks = append(blocks, block)
+			}
+		}
+		ret = append(ret, jsonProperty{Name: it.name, Value: c.blocks(blocks, 0)})
+	}
+	return ret
+}
+
+// blocks returns the JSON representation of the given blocks, which all
+// have the same type and the same labels before the given label index.
+func (c *toJSON) blocks(blocks []*hclsyntax.Block, label int) interface{} {
+	if label >= len(blocks[0].Labels) {
+		if len(blocks) == 1 {
+			return c.body(blocks[0].Body)
+		}
+		arr := make([]interface{}, len(blocks))
+		for i, block := range blocks {
+			arr[i] = c.body(block.Body)
+		}
+		return arr
+	}
+
+	var order []string
+	byLabel := make(map[string][]*hclsyntax.Block)
+	for _, block := range blocks {
+		if label >= len(block.Labels) {
+			c.diags = append(c.diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Inconsistent block labels",
+				Detail:   fmt.Sprintf("The JSON syntax cannot represent blocks of type %q with different numbers of labels in the same body.", block.Type),
+				Subject:  block.TypeRange.Ptr(),
+			})
+			continue
+		}
+		name := block.Labels[label]
+		if _, exists := byLabel[name]; !exists {
+			order = append(order, name)
+		}
+		byLabel[name] = append(byLabel[name], block)
+	}
+
+	ret := jsonObject{}
+	for _, name := range order {
+		ret = append(ret, jsonProperty{Name: name, Value: c.blocks(byLabel[name], label+1)})
+	}
+	return ret
+}
+
+func (c *toJSON) expr(expr hclsyntax.Expression) interface{} {
+	switch te := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		if lit := te.NumberLit(); lit != "" && json.Valid([]byte(lit)) {
+			// Preserve the original form of the number where JSON allows.
+			return json.Number(lit)
+		}
+		return literalJSON(te.Val)
+
+	case *hclsyntax.TupleConsExpr:
+		arr := make([]interface{}, len(te.Exprs))
+		for i, elem := range te.Exprs {
+			arr[i] = c.expr(elem)
+		}
+		return arr
+
+	case *hclsyntax.ObjectConsExpr:
+		obj := jsonObject{}
+		for _, item := range te.Items {
+			var name string
+			if kw := hcl.ExprAsKeyword(item.KeyExpr); kw != "" {
+				name = kw
+			} else {
+				key, ok := c.expr(item.KeyExpr).(string)
+				if !ok {
+					// Object keys must always be strings in JSON, so we
+					// must interpolate any other key expression.
+					key = c.interpolation(item.KeyExpr)
+				}
+				name = key
+			}
+			obj = append(obj, jsonProperty{Name: name, Value: c.expr(item.ValueExpr)})
+		}
+		return obj
+
+	case *hclsyntax.ObjectConsKeyExpr:
+		return c.expr(te.Wrapped)
+
+	case *hclsyntax.TemplateExpr:
+		if str, ok := c.quotedTemplate(te); ok {
+			return str
+		}
+	}
+
+	return c.interpolation(expr)
+}
+
+// interpolation returns a JSON string containing a single interpolation
+// sequence that evaluates the given expression, which is equivalent to the
+// expression itself in the JSON syntax.
+func (c *toJSON) interpolation(expr hclsyntax.Expression) string {
+	var inner hcl.Range
+	if wrap, ok := expr.(*hclsyntax.TemplateWrapExpr); ok && c.isQuoted(wrap.SrcRange) {
+		inner = wrap.Wrapped.Range()
+	} else {
+		inner = expr.Range()
+	}
+	src := string(inner.SliceBytes(c.src))
+	if strings.HasPrefix(strings.TrimSpace(src), "<<") {
+		// A heredoc must be followed by a newline.
+		return "${" + src + "\n}"
+	}
+	return "${" + src + "}"
+}
+
+func (c *toJSON) isQuoted(rng hcl.Range) bool {
+	return rng.Start.Byte < len(c.src) && c.src[rng.Start.Byte] == '"'
+}
+
+// quotedTemplate returns the content of the given template as a JSON
+// template string, if it is a quoted template. Literal parts of the template
+// have their escape sequences decoded, while interpolation and directive
+// sequences are retained verbatim.
+func (c *toJSON) quotedTemplate(expr *hclsyntax.TemplateExpr) (string, bool) {
+	if !c.isQuoted(expr.SrcRange) {
+		return "", false
+	}
+	src := expr.SrcRange.SliceBytes(c.src)
+	tokens, diags := hclsyntax.LexExpression(src, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return "", false
+	}
+
+	var buf strings.Builder
+	depth := 0
+	prevEnd := -1
+	for _, tok := range tokens {
+		if prevEnd < 0 {
+			// Skip the opening quote.
+			prevEnd = tok.Range.End.Byte
+			continue
+		}
+		if depth == 0 && tok.Type == hclsyntax.TokenCQuote {
+			break
+		}
+		switch {
+		case depth == 0 && tok.Type == hclsyntax.TokenQuotedLit:
+			lit, ok := unescapeQuoted(string(tok.Bytes))
+			if !ok {
+				return "", false
+			}
+			buf.WriteString(lit)
+		default:
+			buf.Write(src[prevEnd:tok.Range.End.Byte])
+		}
+		switch tok.Type {
+		case hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl:
+			depth++
+		case hclsyntax.TokenTemplateSeqEnd:
+			depth--
+		}
+		prevEnd = tok.Range.End.Byte
+	}
+	return buf.String(), true
+}
+
+// unescapeQuoted decodes the backslash escape sequences in a literal part of
+// a quoted template, leaving the template escapes $${ and %%{ unchanged.
+func unescapeQuoted(lit string) (string, bool) {
+	if !strings.Contains(lit, `\`) {
+		return lit, true
+	}
+	var buf strings.Builder
+	for i := 0; i < len(lit); i++ {
+		if lit[i] != '\\' {
+			buf.WriteByte(lit[i])
+			continue
+		}
+		i++
+		if i >= len(lit) {
+			return "", false
+		}
+		switch lit[i] {
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 't':
+			buf.WriteByte('\t')
+		case '"':
+			buf.WriteByte('"')
+		case '\\':
+			buf.WriteByte('\\')
+		case 'u', 'U':
+			digits := 4
+			if lit[i] == 'U' {
+				digits = 8
+			}
+			if i+1+digits > len(lit) {
+				return "", false
+			}
+			code, err := strconv.ParseUint(lit[i+1:i+1+digits], 16, 32)
+			if err != nil {
+				return "", false
+			}
+			buf.WriteRune(rune(code))
+			i += digits
+		default:
+			return "", false
+		}
+	}
+	return buf.String(), true
+}
+
+func literalJSON(val cty.Value) interface{} {
+	switch {
+	case val.IsNull():
+		return nil
+	case val.Type() == cty.Bool:
+		return val.True()
+	case val.Type() == cty.Number:
+		return json.Number(val.AsBigFloat().Text('f', -1))
+	case val.Type() == cty.String:
+		// Literal strings are templates in the JSON syntax.
+		s := strings.Replace(val.AsString(), "${", "$${", -1)
+		return strings.Replace(s, "%{", "%%{", -1)
+	default:
+		// Should never happen, since the parser produces only the above.
+		panic(fmt.Sprintf("unsupported literal type %#v", val.Type()))
+	}
+}
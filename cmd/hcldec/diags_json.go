@@ -58,6 +58,8 @@ func (wr *jsonDiagWriter) Flush() error {
 			diagJSON.Severity = "error"
 		case hcl.DiagWarning:
 			diagJSON.Severity = "warning"
+		case hcl.DiagInfo:
+			diagJSON.Severity = "info"
 		default:
 			diagJSON.Severity = "(unknown)" // should never happen
 		}
@@ -33,7 +33,7 @@ var (
 )
 
 var parser = hclparse.NewParser()
-var diagWr hcl.DiagnosticWriter // initialized in init
+var newDiagWriter func() hcl.DiagnosticWriter // initialized in main
 
 func init() {
 	flag.VarP(vars, "vars", "V", "provide variables to the given configuration file(s)")
@@ -57,9 +57,15 @@ func main() {
 		if err != nil {
 			w = 80
 		}
-		diagWr = hcl.NewDiagnosticTextWriter(os.Stderr, parser.Files(), uint(w), color)
+		newDiagWriter = func() hcl.DiagnosticWriter {
+			// The writer is created only when needed so that it can see
+			// all of the files parsed so far.
+			return hcl.NewDiagnosticTextWriter(os.Stderr, parser.FilesSnapshot(), uint(w), color)
+		}
 	case "json":
-		diagWr = &jsonDiagWriter{w: os.Stderr}
+		newDiagWriter = func() hcl.DiagnosticWriter {
+			return &jsonDiagWriter{w: os.Stderr}
+		}
 	default:
 		fmt.Fprintf(os.Stderr, "Invalid diagnostics format %q: only \"json\" is supported.\n", *diagsFormat)
 		os.Exit(2)
@@ -84,6 +90,7 @@ func realmain(args []string) error {
 	specContent, specDiags := loadSpecFile(*specFile)
 	diags = append(diags, specDiags...)
 	if specDiags.HasErrors() {
+		diagWr := newDiagWriter()
 		diagWr.WriteDiagnostics(diags)
 		flush(diagWr)
 		os.Exit(2)
@@ -162,6 +169,7 @@ func realmain(args []string) error {
 	}
 
 	if diags.HasErrors() {
+		diagWr := newDiagWriter()
 		diagWr.WriteDiagnostics(diags)
 		flush(diagWr)
 		os.Exit(2)
@@ -187,6 +195,7 @@ func realmain(args []string) error {
 	diags = append(diags, decDiags...)
 
 	if diags.HasErrors() {
+		diagWr := newDiagWriter()
 		diagWr.WriteDiagnostics(diags)
 		flush(diagWr)
 		os.Exit(2)
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// diffLine is a single line of a unified diff, along with the operation
+// that applies to it.
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// bytesDiff returns a unified diff between the given original and formatted
+// versions of the file with the given name, in the same form as the output
+// of diff -u. The result is nil if the two versions are identical.
+func bytesDiff(orig, formatted []byte, fn string) []byte {
+	if bytes.Equal(orig, formatted) {
+		return nil
+	}
+
+	dmp := diffmatchpatch.New()
+	dmp.DiffTimeout = 0 // always find the smallest diff
+	a, b, lineArray := dmp.DiffLinesToChars(string(orig), string(formatted))
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lineArray)
+
+	var lines []diffLine
+	for _, d := range diffs {
+		var op byte
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			op = '-'
+		case diffmatchpatch.DiffInsert:
+			op = '+'
+		default:
+			op = ' '
+		}
+		for _, text := range strings.SplitAfter(d.Text, "\n") {
+			if text != "" {
+				lines = append(lines, diffLine{op, text})
+			}
+		}
+	}
+
+	// origLines[i] and formattedLines[i] are the number of lines of each
+	// version that appear before lines[i].
+	origLines := make([]int, len(lines)+1)
+	formattedLines := make([]int, len(lines)+1)
+	for i, line := range lines {
+		origLines[i+1] = origLines[i]
+		formattedLines[i+1] = formattedLines[i]
+		if line.op != '+' {
+			origLines[i+1]++
+		}
+		if line.op != '-' {
+			formattedLines[i+1]++
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s.orig\n+++ %s\n", fn, fn)
+	for i := 0; i < len(lines); {
+		for i < len(lines) && lines[i].op == ' ' {
+			i++
+		}
+		if i == len(lines) {
+			break
+		}
+
+		// A hunk continues through any run of unchanged lines that is short
+		// enough for the context of the changes either side of it to overlap.
+		end := i
+		for {
+			for end < len(lines) && lines[end].op != ' ' {
+				end++
+			}
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next == len(lines) || next-end > 2*diffContext {
+				break
+			}
+			end = next
+		}
+
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end += diffContext
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		fmt.Fprintf(
+			&buf, "@@ -%s +%s @@\n",
+			hunkRange(origLines[start], origLines[end]),
+			hunkRange(formattedLines[start], formattedLines[end]),
+		)
+		for _, line := range lines[start:end] {
+			buf.WriteByte(line.op)
+			buf.WriteString(line.text)
+			if !strings.HasSuffix(line.text, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return buf.Bytes()
+}
+
+// hunkRange formats the range of lines of one version of a file that is
+// covered by a hunk, given the number of lines before the hunk and the
+// number of lines up to its end.
+func hunkRange(before, end int) string {
+	switch count := end - before; count {
+	case 0:
+		// An empty range is identified by the line before it.
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	default:
+		return fmt.Sprintf("%d,%d", before+1, count)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/hashicorp/hcl2/hcl"
@@ -21,21 +22,24 @@ var (
 	check       = flag.Bool("check", false, "perform a syntax check on the given files and produce diagnostics")
 	reqNoChange = flag.Bool("require-no-change", false, "return a non-zero status if any files are changed during formatting")
 	overwrite   = flag.Bool("w", false, "overwrite source files instead of writing to stdout")
+	showDiff    = flag.Bool("d", false, "display diffs of formatting changes instead of writing the formatted source to stdout")
+	extensions  = flag.String("ext", ".hcl", "comma-separated list of filename extensions of the files to format when walking directories")
 	showVersion = flag.Bool("version", false, "show the version number and immediately exit")
 )
 
 var parser = hclparse.NewParser()
-var diagWr hcl.DiagnosticWriter // initialized in init
+var diagWidth uint // initialized in init
+var diagColor bool // initialized in init
 var checkErrs = false
 var changed []string
 
 func init() {
-	color := terminal.IsTerminal(int(os.Stderr.Fd()))
+	diagColor = terminal.IsTerminal(int(os.Stderr.Fd()))
 	w, _, err := terminal.GetSize(int(os.Stdout.Fd()))
 	if err != nil {
 		w = 80
 	}
-	diagWr = hcl.NewDiagnosticTextWriter(os.Stderr, parser.Files(), uint(w), color)
+	diagWidth = uint(w)
 }
 
 func main() {
@@ -89,11 +93,12 @@ func processFiles() error {
 		case err != nil:
 			return err
 		case dir.IsDir():
-			// This tool can't walk a whole directory because it doesn't
-			// know what file naming schemes will be used by different
-			// HCL-embedding applications, so it'll leave that sort of
-			// functionality for apps themselves to implement.
-			return fmt.Errorf("can't format directory %s", path)
+			// This tool doesn't know what file naming schemes will be used
+			// by different HCL-embedding applications, so the user must
+			// tell us which extensions to look for with -ext.
+			if err := processDir(path); err != nil {
+				return err
+			}
 		default:
 			if err := processFile(path, nil); err != nil {
 				return err
@@ -104,6 +109,34 @@ func processFiles() error {
 	return nil
 }
 
+func processDir(path string) error {
+	exts := strings.Split(*extensions, ",")
+	return filepath.Walk(path, func(fn string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// As with gofmt, we skip hidden files and directories, which are
+		// likely to belong to other tools.
+		if fn != path && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		for _, ext := range exts {
+			if ext != "" && strings.HasSuffix(fn, ext) {
+				return processFile(fn, nil)
+			}
+		}
+		return nil
+	})
+}
+
 func processFile(fn string, in *os.File) error {
 	var err error
 	if in == nil {
@@ -111,6 +144,7 @@ func processFile(fn string, in *os.File) error {
 		if err != nil {
 			return fmt.Errorf("failed to open %s: %s", fn, err)
 		}
+		defer in.Close()
 	}
 
 	inSrc, err := ioutil.ReadAll(in)
@@ -120,6 +154,7 @@ func processFile(fn string, in *os.File) error {
 
 	if *check {
 		_, diags := parser.ParseHCL(inSrc, fn)
+		diagWr := hcl.NewDiagnosticTextWriter(os.Stderr, parser.FilesSnapshot(), diagWidth, diagColor)
 		diagWr.WriteDiagnostics(diags)
 		if diags.HasErrors() {
 			checkErrs = true
@@ -133,10 +168,24 @@ func processFile(fn string, in *os.File) error {
 		changed = append(changed, fn)
 	}
 
+	if *showDiff {
+		diff := bytesDiff(inSrc, outSrc, fn)
+		if _, err := os.Stdout.Write(diff); err != nil {
+			return err
+		}
+	}
+
 	if *overwrite {
+		if bytes.Equal(inSrc, outSrc) {
+			return nil
+		}
 		return ioutil.WriteFile(fn, outSrc, 0644)
 	}
 
+	if *showDiff {
+		return nil
+	}
+
 	_, err = os.Stdout.Write(outSrc)
 	return err
 }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcled"
+)
+
+// LSP completion item kinds used for completion candidates.
+const (
+	lspCompletionFunction = 3
+	lspCompletionVariable = 6
+	lspCompletionProperty = 10
+	lspCompletionStruct   = 22
+)
+
+type completionItem struct {
+	Label    string   `json:"label"`
+	Kind     int      `json:"kind,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+	TextEdit textEdit `json:"textEdit"`
+}
+
+type textEdit struct {
+	Range   hcled.LSPRange `json:"range"`
+	NewText string         `json:"newText"`
+}
+
+func completionItems(file *hcl.File, candidates []hcled.Completion) []completionItem {
+	ret := make([]completionItem, len(candidates))
+	for i, c := range candidates {
+		var kind int
+		switch c.Kind {
+		case hcled.CompletionAttribute:
+			kind = lspCompletionProperty
+		case hcled.CompletionBlock:
+			kind = lspCompletionStruct
+		case hcled.CompletionVariable:
+			kind = lspCompletionVariable
+		case hcled.CompletionFunction:
+			kind = lspCompletionFunction
+		}
+		ret[i] = completionItem{
+			Label:  c.Label,
+			Kind:   kind,
+			Detail: c.Detail,
+			TextEdit: textEdit{
+				Range:   hcled.LSPRangeForRange(file, c.Replace),
+				NewText: c.InsertText,
+			},
+		}
+	}
+	return ret
+}
+
+type hoverResult struct {
+	Contents markupContent  `json:"contents"`
+	Range    hcled.LSPRange `json:"range"`
+}
+
+type markupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+func hover(file *hcl.File, info *hcled.HoverInfo) hoverResult {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Type: `%s`", info.Type.FriendlyName())
+	switch {
+	case info.Sensitive:
+		buf.WriteString("\n\nThe value is sensitive.")
+	case info.Preview != "":
+		fmt.Fprintf(&buf, "\n\n```\n%s\n```", info.Preview)
+	}
+	return hoverResult{
+		Contents: markupContent{
+			Kind:  "markdown",
+			Value: buf.String(),
+		},
+		Range: hcled.LSPRangeForRange(file, info.Range),
+	}
+}
+
+// applyChange returns the result of applying the given change to the given
+// document text. A change with no range replaces the entire text.
+func applyChange(text []byte, change textDocumentContentChangeEvent) []byte {
+	if change.Range == nil {
+		return []byte(change.Text)
+	}
+
+	file := &hcl.File{Bytes: text}
+	start := hcled.PosForLSPPosition(file, change.Range.Start).Byte
+	end := hcled.PosForLSPPosition(file, change.Range.End).Byte
+	if end < start {
+		end = start
+	}
+
+	ret := make([]byte, 0, len(text)-(end-start)+len(change.Text))
+	ret = append(ret, text[:start]...)
+	ret = append(ret, change.Text...)
+	ret = append(ret, text[end:]...)
+	return ret
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcldec/specfile"
+	"github.com/hashicorp/hcl2/hclparse"
+	flag "github.com/spf13/pflag"
+)
+
+const versionStr = "0.0.1-dev"
+
+var (
+	specFile    = flag.StringP("spec", "s", "", "path to a spec file describing the configuration language")
+	showVersion = flag.BoolP("version", "v", false, "show the version number and immediately exit")
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionStr)
+		os.Exit(0)
+	}
+
+	// The protocol uses stdout, so all logging must go to stderr.
+	log.SetOutput(os.Stderr)
+	log.SetPrefix("hclls: ")
+
+	err := realmain()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func realmain() error {
+	var spec *specfile.File
+	if *specFile != "" {
+		parser := hclparse.NewParser()
+		file, diags := parser.ParseHCLFile(*specFile)
+		if !diags.HasErrors() {
+			var specDiags hcl.Diagnostics
+			spec, specDiags = specfile.Decode(file.Body)
+			diags = append(diags, specDiags...)
+		}
+		if diags.HasErrors() {
+			wr := hcl.NewDiagnosticTextWriter(os.Stderr, parser.FilesSnapshot(), 78, false)
+			wr.WriteDiagnostics(diags)
+			return fmt.Errorf("invalid spec file %s", *specFile)
+		}
+	}
+
+	srv := newServer(spec)
+	return srv.serve(bufio.NewReader(os.Stdin), os.Stdout)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hclls [options]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	hcljson "github.com/hashicorp/hcl2/hcl/json"
+	"github.com/hashicorp/hcl2/hcldec"
+	"github.com/hashicorp/hcl2/hcldec/specfile"
+	"github.com/hashicorp/hcl2/hcled"
+)
+
+// server is a language server for configuration files described by an
+// optional spec file. Requests are handled one at a time, in the order
+// they are received.
+type server struct {
+	spec   *specfile.File
+	schema *hcled.Schema
+	ctx    *hcl.EvalContext
+	docs   map[string]*document
+	out    io.Writer
+
+	shutdown bool
+}
+
+// document is a text document that is open in the client.
+type document struct {
+	text []byte
+	file *hcl.File
+}
+
+func newServer(spec *specfile.File) *server {
+	s := &server{
+		spec: spec,
+		docs: make(map[string]*document),
+		ctx:  &hcl.EvalContext{},
+	}
+	if spec != nil {
+		s.schema = schemaForSpec(spec.RootSpec)
+		s.ctx.Variables = spec.Variables
+		s.ctx.Functions = spec.Functions
+	}
+	return s
+}
+
+// schemaForSpec returns the editor schema for bodies decoded with the given
+// spec, including the schemas of any nested block bodies.
+func schemaForSpec(spec hcldec.Spec) *hcled.Schema {
+	ret := &hcled.Schema{
+		Body:   hcldec.ImpliedSchema(spec),
+		Blocks: make(map[string]*hcled.Schema),
+	}
+	for typeName, nested := range hcldec.ImpliedNestedSpecs(spec) {
+		ret.Blocks[typeName] = schemaForSpec(nested)
+	}
+	return ret
+}
+
+// serve reads messages from the given reader and writes responses and
+// notifications to the given writer until the client sends the "exit"
+// notification or closes the input.
+func (s *server) serve(r *bufio.Reader, w io.Writer) error {
+	s.out = w
+	for {
+		buf, err := readMessage(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg rpcMessage
+		if err := json.Unmarshal(buf, &msg); err != nil {
+			err = writeMessage(w, rpcResponse{
+				JSONRPC: "2.0",
+				Error:   &rpcError{Code: rpcParseError, Message: err.Error()},
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("exit requested before shutdown")
+			}
+			return nil
+		}
+
+		result, err := s.handle(msg.Method, msg.Params)
+		if msg.ID == nil {
+			// Notifications have no response, so we can only log errors.
+			if err != nil {
+				log.Printf("%s: %s", msg.Method, err)
+			}
+			continue
+		}
+
+		resp := rpcResponse{
+			JSONRPC: "2.0",
+			ID:      msg.ID,
+			Result:  result,
+		}
+		if err != nil {
+			rpcErr, ok := err.(*rpcError)
+			if !ok {
+				rpcErr = &rpcError{Code: rpcInternalError, Message: err.Error()}
+			}
+			resp.Result = nil
+			resp.Error = rpcErr
+		}
+		if err := writeMessage(w, resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *server) notify(method string, params interface{}) error {
+	return writeMessage(s.out, rpcNotification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	})
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type textDocumentPositionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     hcled.LSPPosition      `json:"position"`
+}
+
+type textDocumentContentChangeEvent struct {
+	Range *hcled.LSPRange `json:"range,omitempty"`
+	Text  string          `json:"text"`
+}
+
+func (s *server) handle(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "initialize":
+		return s.initialize()
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var p struct {
+			TextDocument struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"textDocument"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		doc := &document{text: []byte(p.TextDocument.Text)}
+		s.docs[p.TextDocument.URI] = doc
+		return nil, s.update(p.TextDocument.URI, doc)
+
+	case "textDocument/didChange":
+		var p struct {
+			TextDocument   textDocumentIdentifier           `json:"textDocument"`
+			ContentChanges []textDocumentContentChangeEvent `json:"contentChanges"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(p.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range p.ContentChanges {
+			doc.text = applyChange(doc.text, change)
+		}
+		return nil, s.update(p.TextDocument.URI, doc)
+
+	case "textDocument/didClose":
+		var p struct {
+			TextDocument textDocumentIdentifier `json:"textDocument"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		delete(s.docs, p.TextDocument.URI)
+		return nil, s.publishDiagnostics(p.TextDocument.URI, []hcled.LSPDiagnostic{})
+
+	case "textDocument/completion":
+		doc, pos, err := s.documentPosition(params)
+		if err != nil {
+			return nil, err
+		}
+		return completionItems(doc.file, hcled.Completions(doc.file, pos, s.schema, s.ctx)), nil
+
+	case "textDocument/hover":
+		doc, pos, err := s.documentPosition(params)
+		if err != nil {
+			return nil, err
+		}
+		info, ok := hcled.Hover(doc.file, pos, s.ctx)
+		if !ok {
+			return nil, nil
+		}
+		return hover(doc.file, info), nil
+
+	case "textDocument/documentSymbol":
+		var p struct {
+			TextDocument textDocumentIdentifier `json:"textDocument"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(p.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		syms := hcled.LSPDocumentSymbols(doc.file)
+		if syms == nil {
+			syms = []hcled.LSPDocumentSymbol{}
+		}
+		return syms, nil
+
+	case "textDocument/semanticTokens/full":
+		var p struct {
+			TextDocument textDocumentIdentifier `json:"textDocument"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(p.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		data := hcled.LSPSemanticTokens(doc.file, hcled.SemanticTokens(doc.file))
+		if data == nil {
+			data = []uint32{}
+		}
+		return map[string]interface{}{"data": data}, nil
+
+	case "initialized":
+		return nil, nil
+	}
+
+	return nil, &rpcError{
+		Code:    rpcMethodNotFound,
+		Message: fmt.Sprintf("method %q is not supported", method),
+	}
+}
+
+func (s *server) initialize() (interface{}, error) {
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"textDocumentSync": map[string]interface{}{
+				"openClose": true,
+				"change":    2, // incremental
+			},
+			"completionProvider": map[string]interface{}{
+				"triggerCharacters": []string{"."},
+			},
+			"hoverProvider":          true,
+			"documentSymbolProvider": true,
+			"semanticTokensProvider": map[string]interface{}{
+				"legend": map[string]interface{}{
+					"tokenTypes":     hcled.LSPSemanticTokenTypes,
+					"tokenModifiers": []string{},
+				},
+				"full": true,
+			},
+		},
+		"serverInfo": map[string]interface{}{
+			"name":    "hclls",
+			"version": versionStr,
+		},
+	}, nil
+}
+
+// update re-parses the given document after its text has changed and
+// publishes its diagnostics to the client.
+func (s *server) update(uri string, doc *document) error {
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(uri, ".json") {
+		doc.file, diags = hcljson.Parse(doc.text, uri)
+	} else {
+		doc.file, diags = hclsyntax.ParseConfig(doc.text, uri, hcl.Pos{Line: 1, Column: 1})
+	}
+	if doc.file == nil {
+		doc.file = &hcl.File{Body: hcl.EmptyBody(), Bytes: doc.text}
+	}
+
+	// Decoding against the spec is only useful if the file was parsed
+	// successfully, since otherwise the content of the body is incomplete.
+	if s.spec != nil && !diags.HasErrors() {
+		_, decDiags := hcldec.Decode(doc.file.Body, s.spec.RootSpec, s.ctx)
+		diags = append(diags, decDiags...)
+	}
+
+	return s.publishDiagnostics(uri, hcled.LSPDiagnostics(diags, doc.file, uri, "hclls"))
+}
+
+func (s *server) publishDiagnostics(uri string, diags []hcled.LSPDiagnostic) error {
+	return s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": diags,
+	})
+}
+
+func (s *server) document(uri string) (*document, error) {
+	doc, ok := s.docs[uri]
+	if !ok {
+		return nil, &rpcError{
+			Code:    rpcInvalidParams,
+			Message: fmt.Sprintf("document %q is not open", uri),
+		}
+	}
+	return doc, nil
+}
+
+func (s *server) documentPosition(params json.RawMessage) (*document, hcl.Pos, error) {
+	var p textDocumentPositionParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, hcl.Pos{}, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, hcl.Pos{}, err
+	}
+	return doc, hcled.PosForLSPPosition(doc.file, p.Position), nil
+}
+
+func decodeParams(params json.RawMessage, into interface{}) error {
+	if err := json.Unmarshal(params, into); err != nil {
+		return &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
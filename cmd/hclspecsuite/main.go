@@ -9,6 +9,7 @@ import (
 
 	"github.com/hashicorp/hcl2/hcl"
 	"github.com/hashicorp/hcl2/hclparse"
+	"github.com/hashicorp/hcl2/specsuite"
 )
 
 func main() {
@@ -37,30 +38,33 @@ func realMain(args []string) int {
 	if err != nil {
 		w = 80
 	}
-	diagWr := hcl.NewDiagnosticTextWriter(os.Stderr, parser.Files(), uint(w), color)
+	writeDiags := func(diags hcl.Diagnostics) {
+		diagWr := hcl.NewDiagnosticTextWriter(os.Stderr, parser.FilesSnapshot(), uint(w), color)
+		diagWr.WriteDiagnostics(diags)
+	}
 	var diagCount int
 
-	runner := &Runner{
-		parser:     parser,
-		hcldecPath: hcldecPath,
-		baseDir:    testsDir,
-		logBegin: func(name string, file *TestFile) {
+	runner := specsuite.NewRunner(
+		testsDir,
+		specsuite.ExecDecoder(hcldecPath),
+		parser,
+		func(name string, file *specsuite.TestFile) {
 			fmt.Printf("- %s\n", name)
 		},
-		logProblems: func(name string, file *TestFile, diags hcl.Diagnostics) {
+		func(name string, file *specsuite.TestFile, diags hcl.Diagnostics) {
 			if len(diags) != 0 {
 				os.Stderr.WriteString("\n")
-				diagWr.WriteDiagnostics(diags)
+				writeDiags(diags)
 				diagCount += len(diags)
 			}
 			fmt.Printf("- %s\n", name)
 		},
-	}
+	)
 	diags := runner.Run()
 
 	if len(diags) != 0 {
 		os.Stderr.WriteString("\n\n\n== Test harness problems:\n\n")
-		diagWr.WriteDiagnostics(diags)
+		writeDiags(diags)
 		diagCount += len(diags)
 	}
 
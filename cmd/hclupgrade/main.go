@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hclupgrade"
+	flag "github.com/spf13/pflag"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const versionStr = "0.0.1-dev"
+
+var (
+	overwrite   = flag.BoolP("write", "w", false, "overwrite source files instead of writing to stdout")
+	mapAttrs    = flag.StringArrayP("map-attr", "m", nil, "treat constructs with the given name that use block syntax as map attributes")
+	showVersion = flag.BoolP("version", "v", false, "show the version number and immediately exit")
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionStr)
+		os.Exit(0)
+	}
+
+	err := realmain(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func realmain(args []string) error {
+	opts := &hclupgrade.Options{
+		MapAttributes: *mapAttrs,
+	}
+
+	if len(args) == 0 {
+		if *overwrite {
+			return fmt.Errorf("cannot use -w without source filenames")
+		}
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %s", err)
+		}
+		return processFile("<stdin>", src, opts)
+	}
+
+	for _, filename := range args {
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %s", filename, err)
+		}
+		if err := processFile(filename, src, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func processFile(filename string, src []byte, opts *hclupgrade.Options) error {
+	out, diags := hclupgrade.Upgrade(src, filename, opts)
+	if len(diags) != 0 {
+		// Diagnostics about the upgraded result refer to the result rather
+		// than the original source, so we show them against it when present.
+		snippetSrc := src
+		if out != nil {
+			snippetSrc = out
+		}
+		files := map[string]*hcl.File{
+			filename: {Bytes: snippetSrc},
+		}
+		color := terminal.IsTerminal(int(os.Stderr.Fd()))
+		w, _, err := terminal.GetSize(int(os.Stderr.Fd()))
+		if err != nil {
+			w = 80
+		}
+		hcl.NewDiagnosticTextWriter(os.Stderr, files, uint(w), color).WriteDiagnostics(diags)
+	}
+	if diags.HasErrors() {
+		return fmt.Errorf("failed to upgrade %s", filename)
+	}
+
+	if !*overwrite {
+		_, err := os.Stdout.Write(out)
+		return err
+	}
+	if bytes.Equal(src, out) {
+		return nil
+	}
+	return ioutil.WriteFile(filename, out, 0644)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hclupgrade [options] [file ...]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcldec"
+	"github.com/hashicorp/hcl2/hcldec/specfile"
+	"github.com/hashicorp/hcl2/hclparse"
+	flag "github.com/spf13/pflag"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const versionStr = "0.0.1-dev"
+
+var (
+	specFile    = flag.StringP("spec", "s", "", "path to spec file (required)")
+	diagsFormat = flag.StringP("diags", "", "text", "format diagnostics as either \"text\" or \"json\"")
+	extensions  = flag.StringP("ext", "", ".hcl,.hcl.json", "comma-separated list of filename extensions of the files to validate when walking directories")
+	merge       = flag.BoolP("merge", "m", false, "validate all of the given files together as a single configuration, rather than each separately")
+	showVersion = flag.BoolP("version", "v", false, "show the version number and immediately exit")
+)
+
+var parser = hclparse.NewParser()
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(versionStr)
+		os.Exit(0)
+	}
+
+	os.Exit(realmain(flag.Args()))
+}
+
+// realmain returns the exit status, which is 1 if any of the files are
+// invalid and 2 if validation could not be attempted at all.
+func realmain(args []string) int {
+	if *specFile == "" || len(args) == 0 {
+		usage()
+	}
+
+	var newDiagWriter func() hcl.DiagnosticWriter
+	switch *diagsFormat {
+	case "text":
+		color := terminal.IsTerminal(int(os.Stderr.Fd()))
+		w, _, err := terminal.GetSize(int(os.Stderr.Fd()))
+		if err != nil {
+			w = 80
+		}
+		newDiagWriter = func() hcl.DiagnosticWriter {
+			// The writer is created only when needed so that it can see
+			// all of the files parsed so far.
+			return hcl.NewDiagnosticTextWriter(os.Stderr, parser.FilesSnapshot(), uint(w), color)
+		}
+	case "json":
+		newDiagWriter = func() hcl.DiagnosticWriter {
+			return hcl.NewDiagnosticJSONWriter(os.Stdout)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid diagnostics format %q: must be either \"text\" or \"json\"\n", *diagsFormat)
+		return 2
+	}
+
+	spec, ctx, diags := loadSpec(*specFile)
+	if diags.HasErrors() {
+		newDiagWriter().WriteDiagnostics(diags)
+		return 2
+	}
+
+	filenames, err := findFiles(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 2
+	}
+
+	var bodies []hcl.Body
+	for _, filename := range filenames {
+		var f *hcl.File
+		var fDiags hcl.Diagnostics
+		if strings.HasSuffix(filename, ".json") {
+			f, fDiags = parser.ParseJSONFile(filename)
+		} else {
+			f, fDiags = parser.ParseHCLFile(filename)
+		}
+		diags = append(diags, fDiags...)
+		if fDiags.HasErrors() {
+			continue
+		}
+
+		if *merge {
+			bodies = append(bodies, f.Body)
+			continue
+		}
+		_, decDiags := hcldec.Decode(f.Body, spec, ctx)
+		diags = append(diags, decDiags...)
+	}
+	if *merge && !diags.HasErrors() {
+		_, decDiags := hcldec.Decode(hcl.MergeBodies(bodies), spec, ctx)
+		diags = append(diags, decDiags...)
+	}
+
+	newDiagWriter().WriteDiagnostics(diags)
+	if diags.HasErrors() {
+		return 1
+	}
+	return 0
+}
+
+// loadSpec loads the given spec file, returning its root spec and a context
+// containing its variables and functions, as in the hcldec tool.
+func loadSpec(filename string) (hcldec.Spec, *hcl.EvalContext, hcl.Diagnostics) {
+	var f *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(filename, ".json") {
+		f, diags = parser.ParseJSONFile(filename)
+	} else {
+		f, diags = parser.ParseHCLFile(filename)
+	}
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+
+	content, specDiags := specfile.Decode(f.Body)
+	diags = append(diags, specDiags...)
+	if specDiags.HasErrors() {
+		return nil, nil, diags
+	}
+
+	// We leave the context nil if there are no variables or functions, so
+	// that we'll produce "variables are not allowed" errors instead of
+	// "variable not found" errors.
+	var ctx *hcl.EvalContext
+	if len(content.Variables) != 0 || len(content.Functions) != 0 {
+		ctx = &hcl.EvalContext{}
+		if len(content.Variables) != 0 {
+			ctx.Variables = make(map[string]cty.Value, len(content.Variables))
+			for name, val := range content.Variables {
+				ctx.Variables[name] = val
+			}
+		}
+		if len(content.Functions) != 0 {
+			ctx.Functions = make(map[string]function.Function, len(content.Functions))
+			for name, f := range content.Functions {
+				ctx.Functions[name] = f
+			}
+		}
+	}
+
+	return content.RootSpec, ctx, diags
+}
+
+// findFiles expands the given paths into a list of files, by walking any
+// directories for files with the extensions given by -ext.
+func findFiles(paths []string) ([]string, error) {
+	exts := strings.Split(*extensions, ",")
+	var ret []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			ret = append(ret, path)
+			continue
+		}
+
+		err = filepath.Walk(path, func(fn string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			// As with gofmt, we skip hidden files and directories, which
+			// are likely to belong to other tools.
+			if fn != path && strings.HasPrefix(info.Name(), ".") {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() {
+				return nil
+			}
+
+			for _, ext := range exts {
+				if ext != "" && strings.HasSuffix(fn, ext) {
+					ret = append(ret, fn)
+					return nil
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: hclvalidate --spec=<spec-file> [options] <file-or-dir> ...\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
@@ -2,6 +2,7 @@ package dynblock
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/hashicorp/hcl2/hcl"
 	"github.com/zclconf/go-cty/cty"
@@ -97,7 +98,9 @@ func (b *expandBody) extendSchema(schema *hcl.BodySchema) *hcl.BodySchema {
 	// so that a call to "Content" on the underlying body won't fail.
 	// (We'll filter these out again once we process the result of either
 	// Content or PartialContent.)
+	// These must not be reported as deprecated again.
 	for _, blockS := range b.hiddenBlocks {
+		blockS.Deprecated = ""
 		extSchema.Blocks = append(extSchema.Blocks, blockS)
 	}
 
@@ -170,16 +173,29 @@ func (b *expandBody) expandBlocks(schema *hcl.BodySchema, rawBlocks hcl.Blocks,
 			if blockS == nil {
 				// Not a block type that the caller requested.
 				if !partial {
+					blockTypes := make([]string, 0, len(schema.Blocks))
+					for _, candidate := range schema.Blocks {
+						blockTypes = append(blockTypes, candidate.Type)
+					}
+					suggestion := nameSuggestion(realBlockType, blockTypes)
+					if suggestion != "" {
+						suggestion = fmt.Sprintf(" Did you mean %q?", suggestion)
+					}
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Unsupported block type",
-						Detail:   fmt.Sprintf("Blocks of type %q are not expected here.", realBlockType),
+						Code:     hcl.DiagUnsupportedBlockType,
+						Detail:   fmt.Sprintf("Blocks of type %q are not expected here.%s", realBlockType, suggestion),
 						Subject:  &rawBlock.LabelRanges[0],
 					})
 				}
 				continue
 			}
 
+			if blockS.Deprecated != "" {
+				diags = append(diags, deprecationDiagnostics(blockS, rawBlock)...)
+			}
+
 			spec, specDiags := b.decodeSpec(blockS, rawBlock)
 			diags = append(diags, specDiags...)
 			if specDiags.HasErrors() {
@@ -197,6 +213,7 @@ func (b *expandBody) expandBlocks(schema *hcl.BodySchema, rawBlocks hcl.Blocks,
 						// Attach our new iteration context so that attributes
 						// and other nested blocks can refer to our iterator.
 						block.Body = b.expandChild(block.Body, i)
+						block.Index = rawBlock.Index
 						blocks = append(blocks, block)
 					}
 				}
@@ -222,6 +239,7 @@ func (b *expandBody) expandBlocks(schema *hcl.BodySchema, rawBlocks hcl.Blocks,
 					// structure of the generated body but forces all of its
 					// leaf attribute values to be unknown.
 					block.Body = unknownBody{block.Body}
+					block.Index = rawBlock.Index
 
 					blocks = append(blocks, block)
 				}
@@ -243,6 +261,37 @@ func (b *expandBody) expandBlocks(schema *hcl.BodySchema, rawBlocks hcl.Blocks,
 	return blocks, diags
 }
 
+// deprecationDiagnostics returns the warning for a "dynamic" block that
+// generates blocks of the given deprecated type, which is reported once for
+// the "dynamic" block itself rather than for each generated block. Since the
+// block type is given as a quoted label, any suggested replacement is quoted
+// too.
+func deprecationDiagnostics(blockS *hcl.BlockHeaderSchema, rawBlock *hcl.Block) hcl.Diagnostics {
+	diags := hcl.DeprecationDiagnostics(
+		&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{*blockS},
+		},
+		&hcl.BodyContent{
+			Blocks: hcl.Blocks{
+				{
+					Type:      blockS.Type,
+					TypeRange: rawBlock.LabelRanges[0],
+					DefRange:  rawBlock.DefRange,
+				},
+			},
+		},
+	)
+	for _, diag := range diags {
+		for _, suggestion := range diag.Suggestions {
+			for i := range suggestion.Edits {
+				edit := &suggestion.Edits[i]
+				edit.Replacement = strconv.Quote(edit.Replacement)
+			}
+		}
+	}
+	return diags
+}
+
 func (b *expandBody) expandChild(child hcl.Body, i *iteration) hcl.Body {
 	chiCtx := i.EvalContext(b.forEachCtx)
 	ret := Expand(child, chiCtx)
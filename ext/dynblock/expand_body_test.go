@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
 	"github.com/hashicorp/hcl2/hcldec"
 	"github.com/hashicorp/hcl2/hcltest"
 	"github.com/zclconf/go-cty/cty"
@@ -381,3 +382,195 @@ func TestExpand(t *testing.T) {
 	})
 
 }
+
+func TestExpandDiagnostics(t *testing.T) {
+	dynBlock := func(blockType string, attrs map[string]hcl.Expression, content int) *hcl.Block {
+		blocks := make(hcl.Blocks, content)
+		for i := range blocks {
+			blocks[i] = &hcl.Block{
+				Type: "content",
+				Body: hcltest.MockBody(&hcl.BodyContent{}),
+			}
+		}
+		return &hcl.Block{
+			Type:        "dynamic",
+			Labels:      []string{blockType},
+			LabelRanges: []hcl.Range{{}},
+			Body: hcltest.MockBody(&hcl.BodyContent{
+				Attributes: hcltest.MockAttrs(attrs),
+				Blocks:     blocks,
+			}),
+		}
+	}
+	forEach := hcltest.MockExprLiteral(cty.ListVal([]cty.Value{cty.True}))
+
+	tests := []struct {
+		Block      *hcl.Block
+		WantCode   string
+		WantDetail string
+	}{
+		{
+			dynBlock("foo", map[string]hcl.Expression{
+				"for_each": forEach,
+			}, 1),
+			hcl.DiagUnsupportedBlockType,
+			`Blocks of type "foo" are not expected here. Did you mean "food"?`,
+		},
+		{
+			dynBlock("other", map[string]hcl.Expression{
+				"for_each": forEach,
+			}, 1),
+			hcl.DiagUnsupportedBlockType,
+			`Blocks of type "other" are not expected here.`,
+		},
+		{
+			dynBlock("food", map[string]hcl.Expression{
+				"for_each": hcltest.MockExprLiteral(cty.True),
+			}, 1),
+			hcl.DiagInvalidDynamicForEach,
+			"Cannot use a bool value in for_each. An iterable collection is required.",
+		},
+		{
+			dynBlock("food", map[string]hcl.Expression{
+				"for_each": forEach,
+			}, 0),
+			hcl.DiagMissingDynamicContentBlock,
+			`A dynamic block must have a nested block of type "content" to describe the body of each generated block.`,
+		},
+		{
+			dynBlock("food", map[string]hcl.Expression{
+				"for_each": forEach,
+			}, 2),
+			hcl.DiagExtraneousDynamicContentBlock,
+			"Only one nested content block is allowed for each dynamic block.",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.WantDetail, func(t *testing.T) {
+			body := Expand(hcltest.MockBody(&hcl.BodyContent{
+				Blocks: hcl.Blocks{test.Block},
+			}), nil)
+			_, diags := body.Content(&hcl.BodySchema{
+				Blocks: []hcl.BlockHeaderSchema{
+					{Type: "food"},
+				},
+			})
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got := diags[0].Code; got != test.WantCode {
+				t.Errorf("wrong code %q; want %q", got, test.WantCode)
+			}
+			if got := diags[0].Detail; got != test.WantDetail {
+				t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, test.WantDetail)
+			}
+		})
+	}
+}
+
+func TestExpandDeprecated(t *testing.T) {
+	src := `old {
+}
+dynamic "old" {
+  for_each = ["a", "b"]
+  content {
+  }
+}
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	schema := &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "old", Deprecated: "Use new instead.", ReplacedBy: "new"},
+		},
+	}
+	content, remain, diags := Expand(f.Body, nil).PartialContent(schema)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+	if got, want := len(content.Blocks), 3; got != want {
+		t.Errorf("wrong number of blocks %d; want %d", got, want)
+	}
+
+	// The static block is reported by the underlying body, and the dynamic
+	// block is reported once rather than once for each generated block.
+	if got, want := len(diags), 2; got != want {
+		t.Fatalf("wrong number of diagnostics %d; want %d\n%s", got, want, diags.Error())
+	}
+	var edits []hcl.Edit
+	for _, diag := range diags {
+		if diag.Code != hcl.DiagDeprecatedBlockType {
+			t.Errorf("wrong code %q; want %q", diag.Code, hcl.DiagDeprecatedBlockType)
+		}
+		edits = append(edits, diag.Suggestions[0].Edits...)
+	}
+	fixed, err := hcl.ApplyEdits([]byte(src), edits)
+	if err != nil {
+		t.Fatalf("unexpected error applying edits: %s", err)
+	}
+	want := `new {
+}
+dynamic "new" {
+  for_each = ["a", "b"]
+  content {
+  }
+}
+`
+	if string(fixed) != want {
+		t.Errorf("wrong result of applying suggestions\ngot:\n%s\nwant:\n%s", fixed, want)
+	}
+
+	// The blocks consumed by the first call are not reported again.
+	_, diags = remain.Content(&hcl.BodySchema{})
+	if len(diags) != 0 {
+		t.Errorf("unexpected diagnostics: %s", diags.Error())
+	}
+}
+
+func TestExpandProvenance(t *testing.T) {
+	src := `b {
+  v = "static"
+}
+dynamic "b" {
+  for_each = ["a", "b"]
+  content {
+    v = b.value
+  }
+}
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	spec := &hcldec.BlockListSpec{
+		TypeName: "b",
+		Nested:   &hcldec.AttrSpec{Name: "v", Type: cty.String},
+	}
+	_, prov, diags := hcldec.DecodeWithProvenance(Expand(f.Body, nil), spec, nil)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+
+	// Each generated block's value comes from the expression in the
+	// content block.
+	wants := []string{
+		"test.hcl:2,7-15",
+		"test.hcl:7,9-16",
+		"test.hcl:7,9-16",
+	}
+	for i, want := range wants {
+		rng, ok := prov.Range(cty.IndexPath(cty.NumberIntVal(int64(i))))
+		if !ok {
+			t.Errorf("no provenance for element %d", i)
+			continue
+		}
+		if got := rng.String(); got != want {
+			t.Errorf("wrong range for element %d %s; want %s", i, got, want)
+		}
+	}
+}
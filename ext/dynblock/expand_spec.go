@@ -48,6 +48,7 @@ func (b *expandBody) decodeSpec(blockS *hcl.BlockHeaderSchema, rawSpec *hcl.Bloc
 		diags = append(diags, &hcl.Diagnostic{
 			Severity:    hcl.DiagError,
 			Summary:     "Invalid dynamic for_each value",
+			Code:        hcl.DiagInvalidDynamicForEach,
 			Detail:      fmt.Sprintf("Cannot use a %s value in for_each. An iterable collection is required.", eachVal.Type().FriendlyName()),
 			Subject:     eachAttr.Expr.Range().Ptr(),
 			Expression:  eachAttr.Expr,
@@ -59,6 +60,7 @@ func (b *expandBody) decodeSpec(blockS *hcl.BlockHeaderSchema, rawSpec *hcl.Bloc
 		diags = append(diags, &hcl.Diagnostic{
 			Severity:    hcl.DiagError,
 			Summary:     "Invalid dynamic for_each value",
+			Code:        hcl.DiagInvalidDynamicForEach,
 			Detail:      "Cannot use a null value in for_each.",
 			Subject:     eachAttr.Expr.Range().Ptr(),
 			Expression:  eachAttr.Expr,
@@ -81,6 +83,7 @@ func (b *expandBody) decodeSpec(blockS *hcl.BlockHeaderSchema, rawSpec *hcl.Bloc
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid dynamic iterator name",
+				Code:     hcl.DiagInvalidDynamicIteratorName,
 				Detail:   "Dynamic iterator must be a single variable name.",
 				Subject:  itTraversal.SourceRange().Ptr(),
 			})
@@ -103,6 +106,7 @@ func (b *expandBody) decodeSpec(blockS *hcl.BlockHeaderSchema, rawSpec *hcl.Bloc
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Extraneous dynamic block label",
+				Code:     hcl.DiagExtraneousDynamicBlockLabel,
 				Detail:   fmt.Sprintf("Blocks of type %q require %d label(s).", blockS.Type, len(blockS.LabelNames)),
 				Subject:  labelExprs[len(blockS.LabelNames)].Range().Ptr(),
 			})
@@ -111,6 +115,7 @@ func (b *expandBody) decodeSpec(blockS *hcl.BlockHeaderSchema, rawSpec *hcl.Bloc
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Insufficient dynamic block labels",
+				Code:     hcl.DiagInsufficientDynamicBlockLabels,
 				Detail:   fmt.Sprintf("Blocks of type %q require %d label(s).", blockS.Type, len(blockS.LabelNames)),
 				Subject:  labelsAttr.Expr.Range().Ptr(),
 			})
@@ -124,6 +129,7 @@ func (b *expandBody) decodeSpec(blockS *hcl.BlockHeaderSchema, rawSpec *hcl.Bloc
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Missing dynamic content block",
+			Code:     hcl.DiagMissingDynamicContentBlock,
 			Detail:   "A dynamic block must have a nested block of type \"content\" to describe the body of each generated block.",
 			Subject:  &specContent.MissingItemRange,
 		})
@@ -133,6 +139,7 @@ func (b *expandBody) decodeSpec(blockS *hcl.BlockHeaderSchema, rawSpec *hcl.Bloc
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Extraneous dynamic content block",
+			Code:     hcl.DiagExtraneousDynamicContentBlock,
 			Detail:   "Only one nested content block is allowed for each dynamic block.",
 			Subject:  &specContent.Blocks[1].DefRange,
 		})
@@ -168,6 +175,7 @@ func (s *expandSpec) newBlock(i *iteration, ctx *hcl.EvalContext) (*hcl.Block, h
 			diags = append(diags, &hcl.Diagnostic{
 				Severity:    hcl.DiagError,
 				Summary:     "Invalid dynamic block label",
+				Code:        hcl.DiagInvalidDynamicBlockLabel,
 				Detail:      fmt.Sprintf("Cannot use this value as a dynamic block label: %s.", convErr),
 				Subject:     labelExpr.Range().Ptr(),
 				Expression:  labelExpr,
@@ -179,6 +187,7 @@ func (s *expandSpec) newBlock(i *iteration, ctx *hcl.EvalContext) (*hcl.Block, h
 			diags = append(diags, &hcl.Diagnostic{
 				Severity:    hcl.DiagError,
 				Summary:     "Invalid dynamic block label",
+				Code:        hcl.DiagInvalidDynamicBlockLabel,
 				Detail:      "Cannot use a null value as a dynamic block label.",
 				Subject:     labelExpr.Range().Ptr(),
 				Expression:  labelExpr,
@@ -190,6 +199,7 @@ func (s *expandSpec) newBlock(i *iteration, ctx *hcl.EvalContext) (*hcl.Block, h
 			diags = append(diags, &hcl.Diagnostic{
 				Severity:    hcl.DiagError,
 				Summary:     "Invalid dynamic block label",
+				Code:        hcl.DiagInvalidDynamicBlockLabel,
 				Detail:      "This value is not yet known. Dynamic block labels must be immediately-known values.",
 				Subject:     labelExpr.Range().Ptr(),
 				Expression:  labelExpr,
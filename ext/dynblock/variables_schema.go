@@ -0,0 +1,54 @@
+package dynblock
+
+import (
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// SchemaFunc is the signature of a function that returns the schema for the
+// body of a block, given the path of block type names leading to it from the
+// root body. The root body itself has an empty path.
+//
+// The function should return nil for any path it does not recognize, in
+// which case that body and everything nested inside it is skipped.
+type SchemaFunc func(path []string) *hcl.BodySchema
+
+// VariablesSchemaFunc is a wrapper around WalkVariables that uses the given
+// function to obtain the schema for each body in the walk.
+//
+// This is useful for applications that don't use hcldec but do have their
+// own higher-level representation of their configuration structure, such as
+// the schemas implied by gohcl target structs, from which the schema for
+// each level can be produced. For applications that do use hcldec,
+// VariablesHCLDec is more convenient.
+func VariablesSchemaFunc(body hcl.Body, schemaFn SchemaFunc) []hcl.Traversal {
+	rootNode := WalkVariables(body)
+	return walkVariablesWithSchemaFunc(rootNode, nil, schemaFn)
+}
+
+// ExpandVariablesSchemaFunc is like VariablesSchemaFunc but it includes only
+// the minimal set of variables required to call Expand, ignoring variables
+// that are referenced only inside normal block contents. See
+// WalkExpandVariables for more information.
+func ExpandVariablesSchemaFunc(body hcl.Body, schemaFn SchemaFunc) []hcl.Traversal {
+	rootNode := WalkExpandVariables(body)
+	return walkVariablesWithSchemaFunc(rootNode, nil, schemaFn)
+}
+
+func walkVariablesWithSchemaFunc(node WalkVariablesNode, path []string, schemaFn SchemaFunc) []hcl.Traversal {
+	schema := schemaFn(path)
+	if schema == nil {
+		return nil
+	}
+
+	vars, children := node.Visit(schema)
+	for _, child := range children {
+		// Always allocate a new slice for the child path so that sibling
+		// walks can't interfere with each other's paths.
+		childPath := make([]string, len(path), len(path)+1)
+		copy(childPath, path)
+		childPath = append(childPath, child.BlockTypeName)
+		vars = append(vars, walkVariablesWithSchemaFunc(child.Node, childPath, schemaFn)...)
+	}
+
+	return vars
+}
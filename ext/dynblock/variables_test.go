@@ -2,6 +2,7 @@ package dynblock
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/hcl2/hcldec"
@@ -124,6 +125,50 @@ dynamic "a" {
 		}
 	})
 
+	t.Run("VariablesSchemaFunc", func(t *testing.T) {
+		// This should produce the same result as VariablesHCLDec, since
+		// the schemas given here are the ones implied by the spec.
+		schemaFn := func(path []string) *hcl.BodySchema {
+			switch strings.Join(path, ".") {
+			case "":
+				return hcldec.ImpliedSchema(spec)
+			case "a":
+				return hcldec.ImpliedSchema(spec.Nested)
+			case "a.b":
+				return hcldec.ImpliedSchema(spec.Nested.(*hcldec.BlockMapSpec).Nested)
+			default:
+				return nil
+			}
+		}
+
+		want := VariablesHCLDec(f.Body, spec)
+		got := VariablesSchemaFunc(f.Body, schemaFn)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong result\ngot: %swant: %s", spew.Sdump(got), spew.Sdump(want))
+		}
+
+		want = ExpandVariablesHCLDec(f.Body, spec)
+		got = ExpandVariablesSchemaFunc(f.Body, schemaFn)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong expand result\ngot: %swant: %s", spew.Sdump(got), spew.Sdump(want))
+		}
+
+		// Returning nil for a path skips that body and its descendents.
+		got = VariablesSchemaFunc(f.Body, func(path []string) *hcl.BodySchema {
+			if len(path) > 0 {
+				return nil
+			}
+			return hcldec.ImpliedSchema(spec)
+		})
+		var gotNames []string
+		for _, traversal := range got {
+			gotNames = append(gotNames, traversal.RootName())
+		}
+		if wantNames := []string{"some_list_1", "some_list_3"}; !reflect.DeepEqual(gotNames, wantNames) {
+			t.Errorf("wrong result with skipped children\ngot: %swant: %s", spew.Sdump(gotNames), spew.Sdump(wantNames))
+		}
+	})
+
 	t.Run("WalkExpandVariables", func(t *testing.T) {
 		traversals := ExpandVariablesHCLDec(f.Body, spec)
 		got := make([]string, len(traversals))
@@ -0,0 +1,187 @@
+package fileio
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrOutsideBaseDir is the underlying error returned by a Resolver created
+// by DirResolver for a path that refers to a location outside of its base
+// directory.
+var ErrOutsideBaseDir = errors.New("path is outside of the permitted directory")
+
+// ErrFileTooLarge is the underlying error returned by a Resolver created by
+// DirResolver for a file that is larger than its size limit.
+var ErrFileTooLarge = errors.New("file is too large")
+
+// DirResolver returns a Resolver that reads files from the local filesystem,
+// interpreting paths relative to the given base directory.
+//
+// Only files within the base directory and its subdirectories can be read.
+// Absolute paths are refused, as are paths that refer to locations outside
+// of the base directory, whether by using ".." or by way of symbolic links,
+// even if a later step of the path would lead back inside.
+// If maxSize is greater than zero then files larger than that many bytes are
+// also refused.
+//
+// All errors are of type *os.PathError, with the path as given rather than
+// the path on the host filesystem. The underlying error is ErrOutsideBaseDir
+// or ErrFileTooLarge if the file was refused, or otherwise the error
+// reported by the operating system.
+//
+// The checks are made each time a file is read, but they cannot protect
+// against the base directory being modified concurrently by a process that
+// is not trusted.
+func DirResolver(baseDir string, maxSize int64) Resolver {
+	return &dirResolver{
+		BaseDir: baseDir,
+		MaxSize: maxSize,
+	}
+}
+
+type dirResolver struct {
+	BaseDir string
+	MaxSize int64
+}
+
+func (r *dirResolver) ReadFile(path string) ([]byte, error) {
+	src, err := r.readFile(path)
+	if err != nil {
+		if pathErr, ok := err.(*os.PathError); ok {
+			err = pathErr.Err
+		}
+		return nil, &os.PathError{Op: "read", Path: path, Err: err}
+	}
+	return src, nil
+}
+
+func (r *dirResolver) readFile(path string) ([]byte, error) {
+	if filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return nil, ErrOutsideBaseDir
+	}
+
+	baseDir, err := filepath.Abs(r.BaseDir)
+	if err == nil {
+		baseDir, err = filepath.EvalSymlinks(baseDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	filename, err := resolveWithin(baseDir, path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rd io.Reader = f
+	if r.MaxSize > 0 {
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if info.Size() > r.MaxSize {
+			return nil, ErrFileTooLarge
+		}
+		// The size reported for special files may not reflect their
+		// content, and regular files may grow while being read.
+		rd = io.LimitReader(f, r.MaxSize+1)
+	}
+	src, err := ioutil.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+	if r.MaxSize > 0 && int64(len(src)) > r.MaxSize {
+		return nil, ErrFileTooLarge
+	}
+	return src, nil
+}
+
+// maxLinks is the maximum number of symbolic links that resolveWithin will
+// follow, to detect loops.
+const maxLinks = 255
+
+// resolveWithin returns the location of the file with the given relative path
+// within the given clean, absolute directory, which must not itself contain
+// any symbolic links, following any symbolic links along the way.
+//
+// The path is resolved one component at a time, and ErrOutsideBaseDir is
+// returned as soon as a ".." component or a symbolic link leads outside of
+// the directory. Nothing outside of the directory is examined, so the result
+// cannot reveal anything about what lies there, not even whether a file
+// exists.
+func resolveWithin(dir, path string) (string, error) {
+	current := dir
+	pending := splitPath(path)
+	links := 0
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			current = filepath.Dir(current)
+			if !withinDir(dir, current) {
+				return "", ErrOutsideBaseDir
+			}
+			continue
+		}
+
+		next := filepath.Join(current, name)
+		info, err := os.Lstat(next)
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			current = next
+			continue
+		}
+
+		links++
+		if links > maxLinks {
+			return "", errors.New("too many levels of symbolic links")
+		}
+		target, err := os.Readlink(next)
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(target) || filepath.VolumeName(target) != "" {
+			target = filepath.Clean(target)
+			if !withinDir(dir, target) {
+				return "", ErrOutsideBaseDir
+			}
+			// The target is resolved again from the top, since it may
+			// itself contain symbolic links.
+			current = dir
+			target, _ = filepath.Rel(dir, target)
+		}
+		pending = append(splitPath(target), pending...)
+	}
+	return current, nil
+}
+
+// splitPath splits the given relative path into its components.
+func splitPath(path string) []string {
+	return strings.Split(filepath.ToSlash(path), "/")
+}
+
+// withinDir returns true if the given clean, absolute filename is within
+// the given clean, absolute directory.
+func withinDir(dir, filename string) bool {
+	rel, err := filepath.Rel(dir, filename)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,114 @@
+package fileio
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirResolver(t *testing.T) {
+	root := t.TempDir()
+	base := filepath.Join(root, "base")
+	for name, content := range map[string]string{
+		"base/a.txt":       "a",
+		"base/sub/b.txt":   "b",
+		"base/large.txt":   strings.Repeat("x", 11),
+		"secret.txt":       "secret",
+		"base/sub/c/d.txt": "d",
+		"outdir/e.txt":     "e",
+	} {
+		filename := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for link, target := range map[string]string{
+		"base/inside":  "sub/b.txt",
+		"base/outside": "../secret.txt",
+		"base/updir":   "..",
+		"base/outdir":  "../outdir",
+		"base/abs":     filepath.Join(base, "sub"),
+		"base/absout":  filepath.Join(root, "outdir"),
+		"base/loop":    "loop",
+	} {
+		if err := os.Symlink(target, filepath.Join(root, filepath.FromSlash(link))); err != nil {
+			t.Skipf("cannot create symbolic links: %s", err)
+		}
+	}
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr error // nil if any error is acceptable
+		ok      bool
+	}{
+		{"a.txt", "a", nil, true},
+		{"sub/b.txt", "b", nil, true},
+		{"./sub/../a.txt", "a", nil, true},
+		{"sub/c/d.txt", "d", nil, true},
+		{"inside", "b", nil, true},
+		{"nope.txt", "", os.ErrNotExist, false},
+		{"sub", "", nil, false},
+		{"large.txt", "", ErrFileTooLarge, false},
+		{"../secret.txt", "", ErrOutsideBaseDir, false},
+		{"sub/../../secret.txt", "", ErrOutsideBaseDir, false},
+		{"../nope.txt", "", ErrOutsideBaseDir, false},
+		{"outside", "", ErrOutsideBaseDir, false},
+		{"updir/secret.txt", "", ErrOutsideBaseDir, false},
+		{"updir/base/a.txt", "", ErrOutsideBaseDir, false},
+		{"abs/b.txt", "b", nil, true},
+		{"abs/../a.txt", "a", nil, true},
+		{"loop", "", nil, false},
+
+		// A file that exists outside of the base directory must not be
+		// distinguishable from one that does not.
+		{"outdir/e.txt", "", ErrOutsideBaseDir, false},
+		{"outdir/nope.txt", "", ErrOutsideBaseDir, false},
+		{"absout/e.txt", "", ErrOutsideBaseDir, false},
+		{"absout/nope.txt", "", ErrOutsideBaseDir, false},
+		{filepath.Join(root, "secret.txt"), "", ErrOutsideBaseDir, false},
+		{filepath.Join(base, "a.txt"), "", ErrOutsideBaseDir, false},
+	}
+
+	r := DirResolver(base, 10)
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			got, err := r.ReadFile(test.path)
+			if test.ok {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if string(got) != test.want {
+					t.Errorf("wrong result %q; want %q", got, test.want)
+				}
+				return
+			}
+
+			pathErr, ok := err.(*os.PathError)
+			if !ok {
+				t.Fatalf("wrong error %#v; want *os.PathError", err)
+			}
+			if pathErr.Path != test.path {
+				t.Errorf("wrong path %q in error; want %q", pathErr.Path, test.path)
+			}
+			if test.wantErr != nil && !errors.Is(err, test.wantErr) {
+				t.Errorf("wrong error %#v; want %#v", pathErr.Err, test.wantErr)
+			}
+		})
+	}
+
+	// With no size limit, any size of file can be read.
+	got, err := DirResolver(base, 0).ReadFile("large.txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(got) != 11 {
+		t.Errorf("wrong length %d; want 11", len(got))
+	}
+}
@@ -0,0 +1,21 @@
+// Package fileio provides functions for use in HCL expressions that read
+// files, such as file and templatefile, with all reads delegated to a
+// Resolver supplied by the calling application.
+//
+// Functions that read files are a common need, but a naive implementation
+// allows configuration to read any file that the application can, which is
+// often undesirable and sometimes a security problem. The Resolver decides
+// how paths are interpreted and which files may be read. DirResolver is a
+// Resolver for the common case of reading files from a particular directory
+// and its subdirectories, with a limit on their size:
+//
+//     ctx := &hcl.EvalContext{
+//         Functions: fileio.Functions(fileio.DirResolver(configDir, 1<<20)),
+//     }
+//
+// When a file cannot be read, the function call fails and its diagnostic
+// refers to the call expression. The error returned by the Resolver, which
+// is usually an *os.PathError, is available from the Extra field of the
+// diagnostic via the hclsyntax.FunctionCallDiagExtra interface, so that
+// applications can distinguish problems such as missing files.
+package fileio
@@ -0,0 +1,164 @@
+package fileio
+
+import (
+	"encoding/base64"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl2/ext/funcs"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// Functions returns a new map containing the functions in this package,
+// keyed by the names they are conventionally called by in configuration,
+// with all files read using the given resolver.
+//
+// The templates rendered by templatefile may call the functions returned by
+// funcs.Functions along with file and filebase64, but not templatefile
+// itself.
+func Functions(r Resolver) map[string]function.Function {
+	templateFuncs := funcs.Functions()
+	templateFuncs["file"] = FileFunc(r)
+	templateFuncs["filebase64"] = FileBase64Func(r)
+
+	return map[string]function.Function{
+		"file":         templateFuncs["file"],
+		"filebase64":   templateFuncs["filebase64"],
+		"templatefile": TemplateFileFunc(r, templateFuncs),
+	}
+}
+
+// FileFunc returns a function that reads the file at the given path using
+// the given resolver and returns its content as a string, which must be
+// valid UTF-8.
+func FileFunc(r Resolver) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path := args[0].AsString()
+			src, err := r.ReadFile(path)
+			if err != nil {
+				return cty.UnknownVal(cty.String), err
+			}
+			if !utf8.Valid(src) {
+				return cty.UnknownVal(cty.String), function.NewArgErrorf(0, "the content of %q is not valid UTF-8; use filebase64 to read binary files", path)
+			}
+			return cty.StringVal(string(src)), nil
+		},
+	})
+}
+
+// FileBase64Func returns a function that reads the file at the given path
+// using the given resolver and returns its content encoded using the
+// standard Base64 encoding defined in RFC 4648 section 4, allowing files
+// that do not contain valid UTF-8 to be read.
+func FileBase64Func(r Resolver) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			src, err := r.ReadFile(args[0].AsString())
+			if err != nil {
+				return cty.UnknownVal(cty.String), err
+			}
+			return cty.StringVal(base64.StdEncoding.EncodeToString(src)), nil
+		},
+	})
+}
+
+// TemplateFileFunc returns a function that reads the file at the given path
+// using the given resolver, interprets its content as a native syntax
+// template, and returns the result of rendering it with the given variables.
+//
+// The variables are given as an object or map whose attribute names or keys
+// must be valid identifiers. Templates may call the given functions, which
+// may be nil if templates should not call any functions. Since a function
+// cannot refer to itself, a template cannot call templatefile unless the
+// caller adds a separately-constructed function to the given map.
+//
+// Errors in the template are returned as hcl.Diagnostics, with ranges that
+// refer to the template file.
+func TemplateFileFunc(r Resolver, funcs map[string]function.Function) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+			{
+				Name: "vars",
+				Type: cty.DynamicPseudoType,
+			},
+		},
+		Type: func(args []cty.Value) (cty.Type, error) {
+			// The result type depends on the template, which is known only
+			// when it is rendered.
+			return cty.DynamicPseudoType, nil
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path, varsVal := args[0].AsString(), args[1]
+
+			vars, err := templateVars(varsVal)
+			if err != nil {
+				return cty.DynamicVal, function.NewArgError(1, err)
+			}
+
+			src, err := r.ReadFile(path)
+			if err != nil {
+				return cty.DynamicVal, err
+			}
+
+			expr, diags := hclsyntax.ParseTemplate(src, path, hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				return cty.DynamicVal, diags
+			}
+			val, diags := expr.Value(&hcl.EvalContext{
+				Variables: vars,
+				Functions: funcs,
+			})
+			if diags.HasErrors() {
+				return cty.DynamicVal, diags
+			}
+			return val, nil
+		},
+	})
+}
+
+// templateVars returns the variables for a template given as the vars
+// argument of templatefile.
+func templateVars(val cty.Value) (map[string]cty.Value, error) {
+	ty := val.Type()
+	switch {
+	case !(ty.IsObjectType() || ty.IsMapType()):
+		return nil, fmt.Errorf("an object or map of variables is required")
+	case val.IsNull():
+		return nil, fmt.Errorf("must not be null")
+	case !val.IsWhollyKnown():
+		return nil, fmt.Errorf("all variables must be known")
+	}
+
+	vars := make(map[string]cty.Value, val.LengthInt())
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		name := k.AsString()
+		if !hcl.ValidIdentifier(name) {
+			return nil, fmt.Errorf("invalid variable name %q: must be a valid identifier", name)
+		}
+		vars[name] = v
+	}
+	return vars, nil
+}
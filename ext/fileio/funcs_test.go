@@ -0,0 +1,139 @@
+package fileio
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestFunctions(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Functions: Functions(MapResolver(map[string][]byte{
+			"hello.txt":   []byte("Hello, world!\n"),
+			"binary":      {0xff, 0x00},
+			"greeting.tf": []byte("%{ for n in names }Hello, ${upper(n)}!\n%{ endfor }${chomp(file(\"hello.txt\"))}"),
+			"list.tf":     []byte("${names}"),
+			"broken.tf":   []byte("${"),
+			"unknown.tf":  []byte("${nope}"),
+		})),
+	}
+
+	tests := []struct {
+		Src       string
+		Want      cty.Value
+		WantDiags int
+	}{
+		{
+			`file("hello.txt")`,
+			cty.StringVal("Hello, world!\n"),
+			0,
+		},
+		{
+			`filebase64("binary")`,
+			cty.StringVal("/wA="),
+			0,
+		},
+		{
+			`templatefile("greeting.tf", {names = ["a", "b"]})`,
+			cty.StringVal("Hello, A!\nHello, B!\nHello, world!"),
+			0,
+		},
+		{
+			`templatefile("list.tf", {names = ["a"]})`,
+			cty.TupleVal([]cty.Value{cty.StringVal("a")}),
+			0,
+		},
+		{
+			`file("binary")`,
+			cty.DynamicVal,
+			1, // Invalid function argument
+		},
+		{
+			`file("nope.txt")`,
+			cty.DynamicVal,
+			1, // Error in function call
+		},
+		{
+			`templatefile("broken.tf", {})`,
+			cty.DynamicVal,
+			1, // Error in function call
+		},
+		{
+			`templatefile("unknown.tf", {})`,
+			cty.DynamicVal,
+			1, // Error in function call
+		},
+		{
+			`templatefile("list.tf", {"not valid" = 1})`,
+			cty.DynamicVal,
+			1, // Invalid function argument
+		},
+		{
+			`templatefile("list.tf", "names")`,
+			cty.DynamicVal,
+			1, // Invalid function argument
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Src, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(test.Src), "", hcl.Pos{Line: 1, Column: 1})
+			if len(diags) != 0 {
+				t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+			}
+			got, diags := expr.Value(ctx)
+			if len(diags) != test.WantDiags {
+				t.Errorf("wrong number of diagnostics %d; want %d\n%s", len(diags), test.WantDiags, diags.Error())
+			}
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestFunctionsDiagnostic(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Functions: Functions(MapResolver(nil)),
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(`"${file("nope.txt")}"`), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+	_, diags = expr.Value(ctx)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	diag := diags[0]
+
+	wantRange := hcl.Range{
+		Filename: "test.hcl",
+		Start:    hcl.Pos{Line: 1, Column: 4, Byte: 3},
+		End:      hcl.Pos{Line: 1, Column: 20, Byte: 19},
+	}
+	if diag.Context == nil || *diag.Context != wantRange {
+		t.Errorf("wrong context range %#v; want %#v", diag.Context, wantRange)
+	}
+
+	extra, ok := diag.Extra.(hclsyntax.FunctionCallDiagExtra)
+	if !ok {
+		t.Fatalf("diagnostic has wrong extra %#v; want FunctionCallDiagExtra", diag.Extra)
+	}
+	if got, want := extra.CalledFunctionName(), "file"; got != want {
+		t.Errorf("wrong function name %q; want %q", got, want)
+	}
+	err, ok := extra.FunctionCallError().(*os.PathError)
+	if !ok {
+		t.Fatalf("wrong error %#v; want *os.PathError", extra.FunctionCallError())
+	}
+	if !os.IsNotExist(err) {
+		t.Errorf("wrong error %#v; want one satisfying os.IsNotExist", err)
+	}
+	if got, want := err.Path, "nope.txt"; got != want {
+		t.Errorf("wrong path %q; want %q", got, want)
+	}
+}
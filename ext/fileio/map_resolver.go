@@ -0,0 +1,20 @@
+package fileio
+
+import (
+	"os"
+)
+
+// MapResolver returns a Resolver that consults the given map for the content
+// (the values) of the files at the given paths (the keys), which is useful
+// for files embedded in an application and for testing.
+//
+// An error satisfying os.IsNotExist is returned for any path that does not
+// appear as a key in the given map.
+func MapResolver(m map[string][]byte) Resolver {
+	return ResolverFunc(func(path string) ([]byte, error) {
+		if src, ok := m[path]; ok {
+			return src, nil
+		}
+		return nil, &os.PathError{Op: "read", Path: path, Err: os.ErrNotExist}
+	})
+}
@@ -0,0 +1,21 @@
+package fileio
+
+// A Resolver reads the content of the file at the given path on behalf of a
+// function called from configuration.
+//
+// The interpretation of the path is up to the Resolver. It should return an
+// error rather than the content of any file that configuration is not
+// permitted to read. Errors should preferably be of type *os.PathError, with
+// the path as given, so that they can be recognized by callers and do not
+// reveal details of the host filesystem.
+type Resolver interface {
+	ReadFile(path string) ([]byte, error)
+}
+
+// ResolverFunc is a function type that implements Resolver.
+type ResolverFunc func(path string) ([]byte, error)
+
+// ReadFile is an implementation of Resolver.ReadFile.
+func (f ResolverFunc) ReadFile(path string) ([]byte, error) {
+	return f(path)
+}
@@ -0,0 +1,266 @@
+package funcs
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// KeysFunc is a function that returns a list of the keys of a map, or of the
+// attribute names of an object, in lexicographical order.
+var KeysFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "inputMap",
+			Type: cty.DynamicPseudoType,
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		ty := args[0].Type()
+		if !(ty.IsMapType() || ty.IsObjectType()) {
+			return cty.NilType, function.NewArgErrorf(0, "must be a map or object, not %s", ty.FriendlyName())
+		}
+		return cty.List(cty.String), nil
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		m := args[0]
+
+		var keys []cty.Value
+		switch {
+		case m.Type().IsObjectType():
+			names := make([]string, 0, len(m.Type().AttributeTypes()))
+			for name := range m.Type().AttributeTypes() {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				keys = append(keys, cty.StringVal(name))
+			}
+		default:
+			// Map element iteration is already in lexicographical order.
+			for it := m.ElementIterator(); it.Next(); {
+				k, _ := it.Element()
+				keys = append(keys, k)
+			}
+		}
+
+		if len(keys) == 0 {
+			return cty.ListValEmpty(cty.String), nil
+		}
+		return cty.ListVal(keys), nil
+	},
+})
+
+// ValuesFunc is a function that returns the values of a map, or of the
+// attributes of an object, in the same order as KeysFunc returns their keys.
+//
+// The result is a list for a map and a tuple for an object, since the
+// attributes of an object may have different types.
+var ValuesFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "inputMap",
+			Type: cty.DynamicPseudoType,
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		ty := args[0].Type()
+		switch {
+		case ty.IsMapType():
+			return cty.List(ty.ElementType()), nil
+		case ty.IsObjectType():
+			atys := ty.AttributeTypes()
+			names := make([]string, 0, len(atys))
+			for name := range atys {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			etys := make([]cty.Type, len(names))
+			for i, name := range names {
+				etys[i] = atys[name]
+			}
+			return cty.Tuple(etys), nil
+		default:
+			return cty.NilType, function.NewArgErrorf(0, "must be a map or object, not %s", ty.FriendlyName())
+		}
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		m := args[0]
+
+		var vals []cty.Value
+		switch {
+		case m.Type().IsObjectType():
+			names := make([]string, 0, len(m.Type().AttributeTypes()))
+			for name := range m.Type().AttributeTypes() {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				vals = append(vals, m.GetAttr(name))
+			}
+			return cty.TupleVal(vals), nil
+		default:
+			for it := m.ElementIterator(); it.Next(); {
+				_, v := it.Element()
+				vals = append(vals, v)
+			}
+			if len(vals) == 0 {
+				return cty.ListValEmpty(retType.ElementType()), nil
+			}
+			return cty.ListVal(vals), nil
+		}
+	},
+})
+
+// ContainsFunc is a function that determines whether a list, set or tuple
+// contains a given value.
+var ContainsFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "collection",
+			Type: cty.DynamicPseudoType,
+		},
+		{
+			Name: "value",
+			Type: cty.DynamicPseudoType,
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		ty := args[0].Type()
+		if !(ty.IsListType() || ty.IsSetType() || ty.IsTupleType()) {
+			return cty.NilType, function.NewArgErrorf(0, "must be a list, set or tuple, not %s", ty.FriendlyName())
+		}
+		return cty.Bool, nil
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		coll := args[0]
+		want := args[1]
+
+		unknown := false
+		for it := coll.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			eq := v.Equals(want)
+			if !eq.IsKnown() {
+				unknown = true
+				continue
+			}
+			if eq.True() {
+				return cty.True, nil
+			}
+		}
+		if unknown {
+			return cty.UnknownVal(cty.Bool), nil
+		}
+		return cty.False, nil
+	},
+})
+
+// DistinctFunc is a function that removes duplicate elements from a list,
+// keeping the first occurrence of each.
+var DistinctFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "list",
+			Type: cty.List(cty.DynamicPseudoType),
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		return args[0].Type(), nil
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		list := args[0]
+		if !list.IsWhollyKnown() {
+			return cty.UnknownVal(retType), nil
+		}
+
+		var vals []cty.Value
+	Elements:
+		for it := list.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			for _, existing := range vals {
+				if existing.RawEquals(v) {
+					continue Elements
+				}
+			}
+			vals = append(vals, v)
+		}
+
+		if len(vals) == 0 {
+			return cty.ListValEmpty(retType.ElementType()), nil
+		}
+		return cty.ListVal(vals), nil
+	},
+})
+
+// LookupFunc is a function that returns the element of a map with the given
+// key, or the given default value if the map has no such element.
+var LookupFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "inputMap",
+			Type: cty.Map(cty.DynamicPseudoType),
+		},
+		{
+			Name: "key",
+			Type: cty.String,
+		},
+		{
+			Name:      "default",
+			Type:      cty.DynamicPseudoType,
+			AllowNull: true,
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		ety := args[0].Type().ElementType()
+		if ety == cty.DynamicPseudoType {
+			// An empty map has no element type, so the default decides.
+			return args[2].Type(), nil
+		}
+		return ety, nil
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		m := args[0]
+		key := args[1]
+
+		if m.HasIndex(key).True() {
+			return m.Index(key), nil
+		}
+
+		def, err := convert.Convert(args[2], retType)
+		if err != nil {
+			return cty.UnknownVal(retType), function.NewArgError(2, fmt.Errorf("must be compatible with the map element type: %s", err))
+		}
+		return def, nil
+	},
+})
+
+// Keys returns a list of the keys of the given map or object.
+func Keys(m cty.Value) (cty.Value, error) {
+	return KeysFunc.Call([]cty.Value{m})
+}
+
+// Values returns the values of the given map or object, in the same order as
+// Keys returns their keys.
+func Values(m cty.Value) (cty.Value, error) {
+	return ValuesFunc.Call([]cty.Value{m})
+}
+
+// Contains determines whether the given list, set or tuple contains the given
+// value.
+func Contains(collection, value cty.Value) (cty.Value, error) {
+	return ContainsFunc.Call([]cty.Value{collection, value})
+}
+
+// Distinct removes duplicate elements from the given list.
+func Distinct(list cty.Value) (cty.Value, error) {
+	return DistinctFunc.Call([]cty.Value{list})
+}
+
+// Lookup returns the element of the given map with the given key, or the
+// given default value if there is no such element.
+func Lookup(m, key, def cty.Value) (cty.Value, error) {
+	return LookupFunc.Call([]cty.Value{m, key, def})
+}
@@ -0,0 +1,149 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestCollectionFuncs(t *testing.T) {
+	m := cty.MapVal(map[string]cty.Value{
+		"b": cty.StringVal("beta"),
+		"a": cty.StringVal("alpha"),
+	})
+	obj := cty.ObjectVal(map[string]cty.Value{
+		"name":  cty.StringVal("x"),
+		"count": cty.NumberIntVal(2),
+	})
+
+	tests := []struct {
+		Name string
+		Call func() (cty.Value, error)
+		Want cty.Value
+		Err  bool
+	}{
+		{
+			"keys of map",
+			func() (cty.Value, error) { return Keys(m) },
+			cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			false,
+		},
+		{
+			"keys of object",
+			func() (cty.Value, error) { return Keys(obj) },
+			cty.ListVal([]cty.Value{cty.StringVal("count"), cty.StringVal("name")}),
+			false,
+		},
+		{
+			"keys of empty map",
+			func() (cty.Value, error) { return Keys(cty.MapValEmpty(cty.String)) },
+			cty.ListValEmpty(cty.String),
+			false,
+		},
+		{
+			"keys of string",
+			func() (cty.Value, error) { return Keys(cty.StringVal("nope")) },
+			cty.NilVal,
+			true,
+		},
+		{
+			"values of map",
+			func() (cty.Value, error) { return Values(m) },
+			cty.ListVal([]cty.Value{cty.StringVal("alpha"), cty.StringVal("beta")}),
+			false,
+		},
+		{
+			"values of object",
+			func() (cty.Value, error) { return Values(obj) },
+			cty.TupleVal([]cty.Value{cty.NumberIntVal(2), cty.StringVal("x")}),
+			false,
+		},
+		{
+			"contains true",
+			func() (cty.Value, error) {
+				return Contains(cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}), cty.StringVal("b"))
+			},
+			cty.True,
+			false,
+		},
+		{
+			"contains false",
+			func() (cty.Value, error) {
+				return Contains(cty.SetVal([]cty.Value{cty.StringVal("a")}), cty.StringVal("b"))
+			},
+			cty.False,
+			false,
+		},
+		{
+			"contains unknown",
+			func() (cty.Value, error) {
+				return Contains(cty.ListVal([]cty.Value{cty.UnknownVal(cty.String)}), cty.StringVal("b"))
+			},
+			cty.UnknownVal(cty.Bool),
+			false,
+		},
+		{
+			"contains on map",
+			func() (cty.Value, error) { return Contains(m, cty.StringVal("a")) },
+			cty.NilVal,
+			true,
+		},
+		{
+			"distinct",
+			func() (cty.Value, error) {
+				return Distinct(cty.ListVal([]cty.Value{
+					cty.StringVal("b"), cty.StringVal("a"), cty.StringVal("b"),
+				}))
+			},
+			cty.ListVal([]cty.Value{cty.StringVal("b"), cty.StringVal("a")}),
+			false,
+		},
+		{
+			"distinct empty",
+			func() (cty.Value, error) { return Distinct(cty.ListValEmpty(cty.Number)) },
+			cty.ListValEmpty(cty.Number),
+			false,
+		},
+		{
+			"lookup present",
+			func() (cty.Value, error) { return Lookup(m, cty.StringVal("a"), cty.StringVal("default")) },
+			cty.StringVal("alpha"),
+			false,
+		},
+		{
+			"lookup absent",
+			func() (cty.Value, error) { return Lookup(m, cty.StringVal("c"), cty.StringVal("default")) },
+			cty.StringVal("default"),
+			false,
+		},
+		{
+			"lookup converts default",
+			func() (cty.Value, error) { return Lookup(m, cty.StringVal("c"), cty.NumberIntVal(1)) },
+			cty.StringVal("1"),
+			false,
+		},
+		{
+			"lookup incompatible default",
+			func() (cty.Value, error) {
+				return Lookup(m, cty.StringVal("c"), cty.ListValEmpty(cty.String))
+			},
+			cty.NilVal,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			got, err := test.Call()
+			if (err != nil) != test.Err {
+				t.Fatalf("wrong error result %v; want error %t", err, test.Err)
+			}
+			if err != nil {
+				return
+			}
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestTimeAdd(t *testing.T) {
+	tests := []struct {
+		Timestamp cty.Value
+		Duration  cty.Value
+		Want      cty.Value
+		Err       bool
+	}{
+		{
+			cty.StringVal("2017-11-22T00:00:00Z"),
+			cty.StringVal("1h30m"),
+			cty.StringVal("2017-11-22T01:30:00Z"),
+			false,
+		},
+		{
+			cty.StringVal("2017-11-22T00:00:00+01:00"),
+			cty.StringVal("-10s"),
+			cty.StringVal("2017-11-21T23:59:50+01:00"),
+			false,
+		},
+		{
+			cty.StringVal("yesterday"),
+			cty.StringVal("1h"),
+			cty.NilVal,
+			true,
+		},
+		{
+			cty.StringVal("2017-11-22T00:00:00Z"),
+			cty.StringVal("a while"),
+			cty.NilVal,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Timestamp.GoString()+" "+test.Duration.GoString(), func(t *testing.T) {
+			got, err := TimeAdd(test.Timestamp, test.Duration)
+			if (err != nil) != test.Err {
+				t.Fatalf("wrong error result %v; want error %t", err, test.Err)
+			}
+			if err != nil {
+				return
+			}
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+// Package funcs provides a curated library of cty functions that can be
+// used directly in a hcl.EvalContext.
+//
+// Most applications that embed HCL want to offer a similar set of general
+// purpose functions to their users. Rather than assembling an incompatible
+// set in each application, an application can start with the table returned
+// by Functions and then add or remove functions as needed:
+//
+//     ctx := &hcl.EvalContext{
+//         Functions: funcs.Functions(),
+//     }
+//
+// The library includes a selection of the functions from the cty stdlib
+// package along with some additional functions defined in this package,
+// covering string manipulation, collections, encoding and decoding, numbers,
+// and date and time formatting.
+//
+// All of the functions are pure, with the exception that functions with
+// invalid arguments return errors. In particular, none of them access the
+// filesystem or the current time, so their results depend only on their
+// arguments. Functions that read files, with reads controlled by the calling
+// application, are provided by package fileio.
+package funcs
@@ -0,0 +1,81 @@
+package funcs
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"unicode/utf8"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// Base64EncodeFunc is a function that encodes a string using the standard
+// Base64 encoding defined in RFC 4648 section 4.
+var Base64EncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "str",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.StringVal(base64.StdEncoding.EncodeToString([]byte(args[0].AsString()))), nil
+	},
+})
+
+// Base64DecodeFunc is a function that decodes a string containing the
+// standard Base64 encoding defined in RFC 4648 section 4.
+//
+// Since cty strings must contain valid UTF-8, the decoded bytes must also be
+// valid UTF-8.
+var Base64DecodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "str",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		buf, err := base64.StdEncoding.DecodeString(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.String), function.NewArgError(0, fmt.Errorf("invalid Base64 data: %s", err))
+		}
+		if !utf8.Valid(buf) {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(0, "the decoded data is not valid UTF-8")
+		}
+		return cty.StringVal(string(buf)), nil
+	},
+})
+
+// URLEncodeFunc is a function that escapes a string so that it can be safely
+// placed inside a URL query.
+var URLEncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "str",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.StringVal(url.QueryEscape(args[0].AsString())), nil
+	},
+})
+
+// Base64Encode encodes the given string using standard Base64 encoding.
+func Base64Encode(str cty.Value) (cty.Value, error) {
+	return Base64EncodeFunc.Call([]cty.Value{str})
+}
+
+// Base64Decode decodes the given string from standard Base64 encoding.
+func Base64Decode(str cty.Value) (cty.Value, error) {
+	return Base64DecodeFunc.Call([]cty.Value{str})
+}
+
+// URLEncode escapes the given string for inclusion in a URL query.
+func URLEncode(str cty.Value) (cty.Value, error) {
+	return URLEncodeFunc.Call([]cty.Value{str})
+}
@@ -0,0 +1,62 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestEncodingFuncs(t *testing.T) {
+	tests := []struct {
+		Name string
+		Call func() (cty.Value, error)
+		Want cty.Value
+		Err  bool
+	}{
+		{
+			"base64encode",
+			func() (cty.Value, error) { return Base64Encode(cty.StringVal("hello, ✓")) },
+			cty.StringVal("aGVsbG8sIOKckw=="),
+			false,
+		},
+		{
+			"base64decode",
+			func() (cty.Value, error) { return Base64Decode(cty.StringVal("aGVsbG8sIOKckw==")) },
+			cty.StringVal("hello, ✓"),
+			false,
+		},
+		{
+			"base64decode invalid",
+			func() (cty.Value, error) { return Base64Decode(cty.StringVal("not base64!")) },
+			cty.NilVal,
+			true,
+		},
+		{
+			"base64decode not utf8",
+			func() (cty.Value, error) { return Base64Decode(cty.StringVal("/w==")) },
+			cty.NilVal,
+			true,
+		},
+		{
+			"urlencode",
+			func() (cty.Value, error) { return URLEncode(cty.StringVal("a b&c=d/e")) },
+			cty.StringVal("a+b%26c%3Dd%2Fe"),
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			got, err := test.Call()
+			if (err != nil) != test.Err {
+				t.Fatalf("wrong error result %v; want error %t", err, test.Err)
+			}
+			if err != nil {
+				return
+			}
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
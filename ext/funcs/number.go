@@ -0,0 +1,124 @@
+package funcs
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// FloorFunc is a function that returns the greatest whole number that is
+// less than or equal to the given number.
+var FloorFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "num",
+			Type: cty.Number,
+		},
+	},
+	Type: function.StaticReturnType(cty.Number),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.NumberVal(roundBigFloat(args[0].AsBigFloat(), false)), nil
+	},
+})
+
+// CeilFunc is a function that returns the least whole number that is
+// greater than or equal to the given number.
+var CeilFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "num",
+			Type: cty.Number,
+		},
+	},
+	Type: function.StaticReturnType(cty.Number),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.NumberVal(roundBigFloat(args[0].AsBigFloat(), true)), nil
+	},
+})
+
+// PowFunc is a function that raises a number to the given power.
+//
+// The calculation is done with float64 precision, so very large or very
+// precise results will be approximate.
+var PowFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "num",
+			Type: cty.Number,
+		},
+		{
+			Name: "power",
+			Type: cty.Number,
+		},
+	},
+	Type: function.StaticReturnType(cty.Number),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		num, _ := args[0].AsBigFloat().Float64()
+		pow, _ := args[1].AsBigFloat().Float64()
+		result := math.Pow(num, pow)
+		if math.IsNaN(result) {
+			return cty.UnknownVal(cty.Number), function.NewArgErrorf(1, "result is not a number")
+		}
+		if math.IsInf(result, 0) {
+			return cty.UnknownVal(cty.Number), function.NewArgErrorf(1, "result is too large")
+		}
+		return cty.NumberFloatVal(result), nil
+	},
+})
+
+// SignumFunc is a function that returns -1, 0 or 1 depending on whether the
+// given number is negative, zero or positive.
+var SignumFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "num",
+			Type: cty.Number,
+		},
+	},
+	Type: function.StaticReturnType(cty.Number),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.NumberIntVal(int64(args[0].AsBigFloat().Sign())), nil
+	},
+})
+
+// roundBigFloat rounds the given number to a whole number, either towards
+// positive infinity (if up is true) or towards negative infinity.
+func roundBigFloat(f *big.Float, up bool) *big.Float {
+	if f.IsInf() {
+		return f
+	}
+	i, acc := f.Int(nil)
+	// Int truncates towards zero, so we must adjust in the direction we
+	// want for any number that had a fractional part.
+	switch {
+	case up && acc == big.Below:
+		i.Add(i, big.NewInt(1))
+	case !up && acc == big.Above:
+		i.Sub(i, big.NewInt(1))
+	}
+	return new(big.Float).SetInt(i)
+}
+
+// Floor returns the greatest whole number less than or equal to the given
+// number.
+func Floor(num cty.Value) (cty.Value, error) {
+	return FloorFunc.Call([]cty.Value{num})
+}
+
+// Ceil returns the least whole number greater than or equal to the given
+// number.
+func Ceil(num cty.Value) (cty.Value, error) {
+	return CeilFunc.Call([]cty.Value{num})
+}
+
+// Pow raises the given number to the given power.
+func Pow(num, power cty.Value) (cty.Value, error) {
+	return PowFunc.Call([]cty.Value{num, power})
+}
+
+// Signum returns the sign of the given number as -1, 0 or 1.
+func Signum(num cty.Value) (cty.Value, error) {
+	return SignumFunc.Call([]cty.Value{num})
+}
@@ -0,0 +1,80 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestNumberFuncs(t *testing.T) {
+	tests := []struct {
+		Name string
+		Call func() (cty.Value, error)
+		Want cty.Value
+		Err  bool
+	}{
+		{
+			"floor positive",
+			func() (cty.Value, error) { return Floor(cty.NumberFloatVal(2.5)) },
+			cty.NumberIntVal(2),
+			false,
+		},
+		{
+			"floor negative",
+			func() (cty.Value, error) { return Floor(cty.NumberFloatVal(-2.5)) },
+			cty.NumberIntVal(-3),
+			false,
+		},
+		{
+			"floor whole",
+			func() (cty.Value, error) { return Floor(cty.NumberIntVal(-4)) },
+			cty.NumberIntVal(-4),
+			false,
+		},
+		{
+			"ceil positive",
+			func() (cty.Value, error) { return Ceil(cty.NumberFloatVal(2.5)) },
+			cty.NumberIntVal(3),
+			false,
+		},
+		{
+			"ceil negative",
+			func() (cty.Value, error) { return Ceil(cty.NumberFloatVal(-2.5)) },
+			cty.NumberIntVal(-2),
+			false,
+		},
+		{
+			"pow",
+			func() (cty.Value, error) { return Pow(cty.NumberIntVal(2), cty.NumberIntVal(10)) },
+			cty.NumberIntVal(1024),
+			false,
+		},
+		{
+			"pow not a number",
+			func() (cty.Value, error) { return Pow(cty.NumberIntVal(-8), cty.NumberFloatVal(0.5)) },
+			cty.NilVal,
+			true,
+		},
+		{
+			"signum",
+			func() (cty.Value, error) { return Signum(cty.NumberFloatVal(-0.1)) },
+			cty.NumberIntVal(-1),
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			got, err := test.Call()
+			if (err != nil) != test.Err {
+				t.Fatalf("wrong error result %v; want error %t", err, test.Err)
+			}
+			if err != nil {
+				return
+			}
+			if !got.Equals(test.Want).True() {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package funcs
+
+import (
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// Functions returns a new map containing all of the functions in the
+// library, keyed by the names they are conventionally called by in
+// configuration.
+//
+// A new map is returned on each call, so the caller is free to modify it to
+// add or remove functions or to rename them to avoid conflicts with
+// application-specific functions.
+func Functions() map[string]function.Function {
+	return map[string]function.Function{
+		// Strings
+		"chomp":      ChompFunc,
+		"format":     stdlib.FormatFunc,
+		"formatlist": stdlib.FormatListFunc,
+		"join":       JoinFunc,
+		"lower":      stdlib.LowerFunc,
+		"regex":      stdlib.RegexFunc,
+		"regexall":   stdlib.RegexAllFunc,
+		"replace":    ReplaceFunc,
+		"split":      SplitFunc,
+		"strlen":     stdlib.StrlenFunc,
+		"strrev":     stdlib.ReverseFunc,
+		"substr":     stdlib.SubstrFunc,
+		"title":      TitleFunc,
+		"trimspace":  TrimSpaceFunc,
+		"upper":      stdlib.UpperFunc,
+
+		// Collections
+		"coalesce":               stdlib.CoalesceFunc,
+		"concat":                 stdlib.ConcatFunc,
+		"contains":               ContainsFunc,
+		"distinct":               DistinctFunc,
+		"hasindex":               stdlib.HasIndexFunc,
+		"keys":                   KeysFunc,
+		"length":                 stdlib.LengthFunc,
+		"lookup":                 LookupFunc,
+		"range":                  stdlib.RangeFunc,
+		"setintersection":        stdlib.SetIntersectionFunc,
+		"setsubtract":            stdlib.SetSubtractFunc,
+		"setsymmetricdifference": stdlib.SetSymmetricDifferenceFunc,
+		"setunion":               stdlib.SetUnionFunc,
+		"values":                 ValuesFunc,
+
+		// Encoding
+		"base64decode": Base64DecodeFunc,
+		"base64encode": Base64EncodeFunc,
+		"csvdecode":    stdlib.CSVDecodeFunc,
+		"jsondecode":   stdlib.JSONDecodeFunc,
+		"jsonencode":   stdlib.JSONEncodeFunc,
+		"urlencode":    URLEncodeFunc,
+
+		// Numbers
+		"abs":    stdlib.AbsoluteFunc,
+		"ceil":   CeilFunc,
+		"floor":  FloorFunc,
+		"int":    stdlib.IntFunc,
+		"max":    stdlib.MaxFunc,
+		"min":    stdlib.MinFunc,
+		"pow":    PowFunc,
+		"signum": SignumFunc,
+
+		// Date and time
+		"formatdate": stdlib.FormatDateFunc,
+		"timeadd":    TimeAddFunc,
+	}
+}
@@ -0,0 +1,60 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestFunctions(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Functions: Functions(),
+	}
+
+	tests := []struct {
+		Src  string
+		Want cty.Value
+	}{
+		{
+			`join("-", split(",", upper("a,b")))`,
+			cty.StringVal("A-B"),
+		},
+		{
+			`lookup({a = 1}, "b", floor(2.5))`,
+			cty.NumberIntVal(2),
+		},
+		{
+			`base64decode(base64encode(jsonencode(keys({b = 1, a = 2}))))`,
+			cty.StringVal(`["a","b"]`),
+		},
+		{
+			`formatdate("YYYY-MM-DD hh:mm", timeadd("2017-11-22T00:00:00Z", "36h"))`,
+			cty.StringVal("2017-11-23 12:00"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Src, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(test.Src), "", hcl.Pos{Line: 1, Column: 1})
+			if len(diags) != 0 {
+				t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+			}
+			got, diags := expr.Value(ctx)
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			if !got.Equals(test.Want).True() {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+
+	// Each call must return an independent map.
+	a := Functions()
+	delete(a, "join")
+	if _, ok := Functions()["join"]; !ok {
+		t.Errorf("modifying one result affected another")
+	}
+}
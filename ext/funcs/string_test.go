@@ -0,0 +1,126 @@
+package funcs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		Sep   cty.Value
+		Lists []cty.Value
+		Want  cty.Value
+		Err   bool
+	}{
+		{
+			cty.StringVal(", "),
+			[]cty.Value{
+				cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			},
+			cty.StringVal("a, b"),
+			false,
+		},
+		{
+			cty.StringVal("-"),
+			[]cty.Value{
+				cty.ListVal([]cty.Value{cty.StringVal("a")}),
+				cty.ListValEmpty(cty.String),
+				cty.ListVal([]cty.Value{cty.StringVal("b"), cty.StringVal("c")}),
+			},
+			cty.StringVal("a-b-c"),
+			false,
+		},
+		{
+			cty.StringVal("-"),
+			[]cty.Value{
+				cty.ListVal([]cty.Value{cty.StringVal("a"), cty.UnknownVal(cty.String)}),
+			},
+			cty.UnknownVal(cty.String),
+			false,
+		},
+		{
+			cty.StringVal("-"),
+			[]cty.Value{
+				cty.ListVal([]cty.Value{cty.StringVal("a"), cty.NullVal(cty.String)}),
+			},
+			cty.UnknownVal(cty.String),
+			true,
+		},
+		{
+			cty.StringVal("-"),
+			nil,
+			cty.UnknownVal(cty.String),
+			true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			got, err := Join(test.Sep, test.Lists...)
+			if (err != nil) != test.Err {
+				t.Fatalf("wrong error result %v; want error %t", err, test.Err)
+			}
+			if err != nil {
+				return
+			}
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestStringFuncs(t *testing.T) {
+	tests := []struct {
+		Name string
+		Call func() (cty.Value, error)
+		Want cty.Value
+	}{
+		{
+			"split",
+			func() (cty.Value, error) { return Split(cty.StringVal(","), cty.StringVal("a,b,,c")) },
+			cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b"), cty.StringVal(""), cty.StringVal("c")}),
+		},
+		{
+			"split unknown",
+			func() (cty.Value, error) { return Split(cty.StringVal(","), cty.UnknownVal(cty.String)) },
+			cty.UnknownVal(cty.List(cty.String)),
+		},
+		{
+			"replace",
+			func() (cty.Value, error) {
+				return Replace(cty.StringVal("a.b.c"), cty.StringVal("."), cty.StringVal("::"))
+			},
+			cty.StringVal("a::b::c"),
+		},
+		{
+			"trimspace",
+			func() (cty.Value, error) { return TrimSpace(cty.StringVal("  \thello\n")) },
+			cty.StringVal("hello"),
+		},
+		{
+			"chomp",
+			func() (cty.Value, error) { return Chomp(cty.StringVal("hello\r\n\n")) },
+			cty.StringVal("hello"),
+		},
+		{
+			"title",
+			func() (cty.Value, error) { return Title(cty.StringVal("hello world")) },
+			cty.StringVal("Hello World"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			got, err := test.Call()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
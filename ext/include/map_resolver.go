@@ -21,6 +21,7 @@ func MapResolver(m map[string]hcl.Body) Resolver {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid include path",
+				Code:     hcl.DiagInvalidIncludePath,
 				Detail:   fmt.Sprintf("The include path %q is not recognized.", path),
 				Subject:  &refRange,
 			},
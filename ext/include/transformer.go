@@ -1,6 +1,9 @@
 package include
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/hashicorp/hcl2/ext/transform"
 	"github.com/hashicorp/hcl2/gohcl"
 	"github.com/hashicorp/hcl2/hcl"
@@ -20,6 +23,14 @@ import (
 // evaluation) into bodies. FileResolver returns a reasonable implementation for
 // applications that read configuration files from local disk.
 //
+// Include blocks within included bodies are resolved too, relative to the
+// body that contains them. An include that would (directly or indirectly)
+// include a body that is already being included produces an error
+// diagnostic describing the chain of includes, rather than looping forever.
+// Included bodies are identified by the filename of their source range,
+// or by their include path if the resolver returns bodies without a
+// filename.
+//
 // The returned Transformer can either be used directly to process includes
 // in a shallow fashion on a single body, or it can be used with
 // transform.Deep (from the sibling transform package) to allow includes
@@ -51,6 +62,20 @@ type transformer struct {
 }
 
 func (t *transformer) TransformBody(in hcl.Body) hcl.Body {
+	root := includeStep{
+		key: in.MissingItemRange().Filename,
+	}
+	return t.transformBody(in, []includeStep{root})
+}
+
+// includeStep is one step in a chain of includes, used to detect cycles.
+type includeStep struct {
+	// key identifies the included body: the filename it was loaded from if
+	// known, or otherwise the include path that was used to obtain it.
+	key string
+}
+
+func (t *transformer) transformBody(in hcl.Body, chain []includeStep) hcl.Body {
 	content, remain, diags := in.PartialContent(t.Schema)
 
 	if content == nil || len(content.Blocks) == 0 {
@@ -76,10 +101,61 @@ func (t *transformer) TransformBody(in hcl.Body) hcl.Body {
 		}
 
 		incBody, incDiags := t.Resolver.ResolveBodyPath(path, pathExpr.Range())
+		if incBody == nil {
+			diags = append(diags, incDiags...)
+			continue
+		}
+
+		step := includeStep{
+			key: incBody.MissingItemRange().Filename,
+		}
+		if step.key == "" {
+			step.key = path
+		}
+		if cycleDiag := includeCycleDiag(chain, step, path, pathExpr.Range()); cycleDiag != nil {
+			diags = diags.Append(cycleDiag)
+			continue
+		}
+
+		// The included body may itself contain includes, which we resolve
+		// recursively so that they are merged in too.
+		incChain := make([]includeStep, len(chain), len(chain)+1)
+		copy(incChain, chain)
+		incChain = append(incChain, step)
+		incBody = t.transformBody(incBody, incChain)
+
 		bodies = append(bodies, transform.BodyWithDiagnostics(incBody, incDiags))
 	}
 
-	return hcl.MergeBodies(bodies)
+	return transform.BodyWithDiagnostics(hcl.MergeBodies(bodies), diags)
+}
+
+// includeCycleDiag returns a diagnostic describing the cycle if the given
+// step already appears in the given chain of includes, or nil otherwise.
+func includeCycleDiag(chain []includeStep, step includeStep, path string, rng hcl.Range) *hcl.Diagnostic {
+	for i, existing := range chain {
+		if existing.key != step.key {
+			continue
+		}
+
+		names := make([]string, 0, len(chain)-i+1)
+		for _, s := range chain[i:] {
+			names = append(names, fmt.Sprintf("%q", s.key))
+		}
+		names = append(names, fmt.Sprintf("%q", step.key))
+
+		return &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Include cycle",
+			Code:     hcl.DiagIncludeCycle,
+			Detail: fmt.Sprintf(
+				"The include path %q refers to %q, which is already being included. The chain of includes is %s.",
+				path, step.key, strings.Join(names, ", which includes "),
+			),
+			Subject: &rng,
+		}
+	}
+	return nil
 }
 
 var includeBlockSchema = &hcl.BodySchema{
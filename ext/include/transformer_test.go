@@ -7,6 +7,7 @@ import (
 	"github.com/davecgh/go-spew/spew"
 	"github.com/hashicorp/hcl2/gohcl"
 	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
 	"github.com/hashicorp/hcl2/hcltest"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -110,3 +111,67 @@ func TestTransformer(t *testing.T) {
 		t.Errorf("wrong result\ngot: %swant: %s", spew.Sdump(got), spew.Sdump(want))
 	}
 }
+
+func TestTransformerNested(t *testing.T) {
+	parse := func(src, filename string) hcl.Body {
+		f, diags := hclsyntax.ParseConfig([]byte(src), filename, hcl.Pos{Line: 1, Column: 1})
+		if len(diags) != 0 {
+			t.Fatalf("unexpected diagnostics parsing %s: %s", filename, diags.Error())
+		}
+		return f.Body
+	}
+
+	type foo struct {
+		From string `hcl:"from,attr"`
+	}
+	type result struct {
+		Foos []foo `hcl:"foo,block"`
+	}
+
+	t.Run("nested", func(t *testing.T) {
+		resolver := MapResolver(map[string]hcl.Body{
+			"b.hcl": parse("include {\n  path = \"c.hcl\"\n}\nfoo {\n  from = \"b\"\n}\n", "b.hcl"),
+			"c.hcl": parse("foo {\n  from = \"c\"\n}\n", "c.hcl"),
+		})
+		root := parse("include {\n  path = \"b.hcl\"\n}\nfoo {\n  from = \"a\"\n}\n", "a.hcl")
+
+		merged := Transformer("include", nil, resolver).TransformBody(root)
+		var got result
+		diags := gohcl.DecodeBody(merged, nil, &got)
+		if len(diags) != 0 {
+			t.Fatalf("unexpected diagnostics: %s", diags.Error())
+		}
+
+		want := result{
+			Foos: []foo{{From: "a"}, {From: "b"}, {From: "c"}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong result\ngot: %swant: %s", spew.Sdump(got), spew.Sdump(want))
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		root := parse("include {\n  path = \"b.hcl\"\n}\n", "a.hcl")
+		resolver := MapResolver(map[string]hcl.Body{
+			"a.hcl": root,
+			"b.hcl": parse("include {\n  path = \"a.hcl\"\n}\n", "b.hcl"),
+		})
+
+		merged := Transformer("include", nil, resolver).TransformBody(root)
+		var got result
+		diags := gohcl.DecodeBody(merged, nil, &got)
+		if len(diags) != 1 {
+			t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+		}
+		if got, want := diags[0].Code, hcl.DiagIncludeCycle; got != want {
+			t.Errorf("wrong code %q; want %q", got, want)
+		}
+		wantDetail := `The include path "a.hcl" refers to "a.hcl", which is already being included. The chain of includes is "a.hcl", which includes "b.hcl", which includes "a.hcl".`
+		if got := diags[0].Detail; got != wantDetail {
+			t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, wantDetail)
+		}
+		if got, want := diags[0].Subject.Filename, "b.hcl"; got != want {
+			t.Errorf("wrong subject filename %q; want %q", got, want)
+		}
+	})
+}
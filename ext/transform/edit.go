@@ -0,0 +1,249 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// An Edit is a change to configuration that can be applied both to a body
+// as a Transformer, for evaluation, and to the source code of that body via
+// hclwrite, for persisting the change. Migration tools can use edits to make
+// the same change in both places consistently.
+//
+// An edit applies only to the items of the body it is given. Use Nested to
+// apply an edit to the bodies of nested blocks.
+type Edit interface {
+	Transformer
+
+	// EditSource applies the change to the given body in-place.
+	EditSource(body *hclwrite.Body)
+}
+
+// RenameAttribute returns an Edit that renames the attribute with the given
+// name, if present, so that it appears under the new name. The new name
+// must not already be in use in the body.
+func RenameAttribute(fromName, toName string) Edit {
+	return renameAttribute{
+		From: fromName,
+		To:   toName,
+	}
+}
+
+type renameAttribute struct {
+	From, To string
+}
+
+func (e renameAttribute) TransformBody(body hcl.Body) hcl.Body {
+	content, remain, diags := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: e.From}},
+	})
+	kept := &hcl.BodyContent{
+		Attributes: make(hcl.Attributes),
+	}
+	if attr := content.Attributes[e.From]; attr != nil {
+		// Shallow-copy the attribute so we can mutate it
+		newAttr := *attr
+		newAttr.Name = e.To
+		kept.Attributes[e.To] = &newAttr
+	}
+	return BodyWithDiagnostics(newFilterBody(remain, kept), diags)
+}
+
+func (e renameAttribute) EditSource(body *hclwrite.Body) {
+	body.RenameAttribute(e.From, e.To)
+}
+
+// WrapAttribute returns an Edit that wraps the expression of the attribute
+// with the given name, if present, in a call to the function of the given
+// name, passing the original value as the function's only argument.
+func WrapAttribute(name, funcName string) Edit {
+	return wrapAttribute{
+		Name:     name,
+		FuncName: funcName,
+	}
+}
+
+type wrapAttribute struct {
+	Name, FuncName string
+}
+
+func (e wrapAttribute) TransformBody(body hcl.Body) hcl.Body {
+	content, remain, diags := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: e.Name}},
+	})
+	kept := &hcl.BodyContent{
+		Attributes: make(hcl.Attributes),
+	}
+	if attr := content.Attributes[e.Name]; attr != nil {
+		// Shallow-copy the attribute so we can mutate it
+		newAttr := *attr
+		newAttr.Expr = wrapCallExpr{
+			Inner:    attr.Expr,
+			FuncName: e.FuncName,
+		}
+		kept.Attributes[e.Name] = &newAttr
+	}
+	return BodyWithDiagnostics(newFilterBody(remain, kept), diags)
+}
+
+func (e wrapAttribute) EditSource(body *hclwrite.Body) {
+	if attr := body.GetAttribute(e.Name); attr != nil {
+		attr.WrapExpressionInCall(e.FuncName)
+	}
+}
+
+// wrapCallExpr is an expression that passes the value of another expression
+// to a function, as if the other expression were written as the only
+// argument of a call to that function.
+type wrapCallExpr struct {
+	Inner    hcl.Expression
+	FuncName string
+}
+
+func (e wrapCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	f, exists := ctx.Function(e.FuncName)
+	if !exists {
+		return cty.DynamicVal, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Call to unknown function",
+			Code:     hcl.DiagCallToUnknownFunction,
+			Detail:   fmt.Sprintf("There is no function named %q.", e.FuncName),
+			Subject:  e.Inner.Range().Ptr(),
+		}}
+	}
+
+	val, diags := e.Inner.Value(ctx)
+	if diags.HasErrors() {
+		return cty.DynamicVal, diags
+	}
+	result, err := f.Call([]cty.Value{val})
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Error in function call",
+			Code:     hcl.DiagErrorInFunctionCall,
+			Detail:   fmt.Sprintf("Call to function %q failed: %s.", e.FuncName, err),
+			Subject:  e.Inner.Range().Ptr(),
+		})
+		return cty.DynamicVal, diags
+	}
+	return result, diags
+}
+
+func (e wrapCallExpr) Variables() []hcl.Traversal {
+	return e.Inner.Variables()
+}
+
+func (e wrapCallExpr) Range() hcl.Range {
+	return e.Inner.Range()
+}
+
+func (e wrapCallExpr) StartRange() hcl.Range {
+	return e.Inner.StartRange()
+}
+
+// DeleteBlocks returns an Edit that deletes the blocks of the type described
+// by the given schema whose labels begin with the given labels. If no labels
+// are given then all blocks of the given type are deleted.
+func DeleteBlocks(blockS hcl.BlockHeaderSchema, labels ...string) Edit {
+	return deleteBlocks{
+		Schema: blockS,
+		Labels: labels,
+	}
+}
+
+type deleteBlocks struct {
+	Schema hcl.BlockHeaderSchema
+	Labels []string
+}
+
+func (e deleteBlocks) TransformBody(body hcl.Body) hcl.Body {
+	return RemoveBlocks(e.Schema, BlockLabels(e.Labels...)).TransformBody(body)
+}
+
+func (e deleteBlocks) EditSource(body *hclwrite.Body) {
+	for _, block := range sourceBlocks(body, e.Schema.Type, e.Labels) {
+		body.RemoveBlock(block)
+	}
+}
+
+// Nested returns an Edit that applies the given edit to the bodies of the
+// blocks of the type described by the given schema.
+func Nested(blockS hcl.BlockHeaderSchema, edit Edit) Edit {
+	return nestedEdit{
+		Schema: blockS,
+		Edit:   edit,
+	}
+}
+
+type nestedEdit struct {
+	Schema hcl.BlockHeaderSchema
+	Edit   Edit
+}
+
+func (e nestedEdit) TransformBody(body hcl.Body) hcl.Body {
+	content, remain, diags := body.PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{e.Schema},
+	})
+	kept := &hcl.BodyContent{}
+	for _, block := range content.Blocks {
+		// Shallow-copy the block so we can mutate it
+		newBlock := *block
+		newBlock.Body = e.Edit.TransformBody(block.Body)
+		kept.Blocks = append(kept.Blocks, &newBlock)
+	}
+	return BodyWithDiagnostics(newFilterBody(remain, kept), diags)
+}
+
+func (e nestedEdit) EditSource(body *hclwrite.Body) {
+	for _, block := range sourceBlocks(body, e.Schema.Type, nil) {
+		e.Edit.EditSource(block.Body())
+	}
+}
+
+// Edits returns a single Edit that applies each of the given edits in
+// sequence.
+func Edits(edits ...Edit) Edit {
+	return editChain(edits)
+}
+
+type editChain []Edit
+
+func (c editChain) TransformBody(body hcl.Body) hcl.Body {
+	for _, e := range c {
+		body = e.TransformBody(body)
+	}
+	return body
+}
+
+func (c editChain) EditSource(body *hclwrite.Body) {
+	for _, e := range c {
+		e.EditSource(body)
+	}
+}
+
+// sourceBlocks returns the blocks in the given body that have the given type
+// and whose labels begin with the given labels.
+func sourceBlocks(body *hclwrite.Body, typeName string, labels []string) []*hclwrite.Block {
+	var ret []*hclwrite.Block
+Blocks:
+	for _, block := range body.Blocks() {
+		if block.Type() != typeName {
+			continue
+		}
+		blockLabels := block.Labels()
+		if len(blockLabels) < len(labels) {
+			continue
+		}
+		for i, label := range labels {
+			if blockLabels[i] != label {
+				continue Blocks
+			}
+		}
+		ret = append(ret, block)
+	}
+	return ret
+}
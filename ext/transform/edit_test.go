@@ -0,0 +1,117 @@
+package transform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/gohcl"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hclwrite"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestEdits(t *testing.T) {
+	src := `
+old_name = "a"
+
+service "web" {
+  image = "nginx"
+}
+
+service "legacy" {
+  image = "old"
+}
+`
+	serviceS := hcl.BlockHeaderSchema{
+		Type:       "service",
+		LabelNames: []string{"name"},
+	}
+	edit := Edits(
+		RenameAttribute("old_name", "name"),
+		DeleteBlocks(serviceS, "legacy"),
+		Nested(serviceS, WrapAttribute("image", "upper")),
+	)
+
+	// First we apply the edit to the live body and decode it.
+	type Service struct {
+		Name  string `hcl:"name,label"`
+		Image string `hcl:"image"`
+	}
+	type Config struct {
+		Name     string    `hcl:"name"`
+		Services []Service `hcl:"service,block"`
+	}
+	ctx := &hcl.EvalContext{
+		Functions: map[string]function.Function{
+			"upper": stdlib.UpperFunc,
+		},
+	}
+	var got Config
+	diags := gohcl.DecodeBody(edit.TransformBody(parseTestBody(t, src, "test.hcl")), ctx, &got)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	want := Config{
+		Name: "a",
+		Services: []Service{
+			{Name: "web", Image: "NGINX"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong decoded result\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	// Then we apply the same edit to the source, and decoding the result
+	// without the edit must produce the same result.
+	f, diags := hclwrite.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	edit.EditSource(f.Body())
+	gotSrc := string(f.Bytes())
+	wantSrc := `
+name = "a"
+
+service "web" {
+  image = upper("nginx")
+}
+
+`
+	if gotSrc != wantSrc {
+		t.Errorf("wrong source result\ngot:\n%s\nwant:\n%s", gotSrc, wantSrc)
+	}
+
+	var gotFromSrc Config
+	diags = gohcl.DecodeBody(parseTestBody(t, gotSrc, "edited.hcl"), ctx, &gotFromSrc)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	if !reflect.DeepEqual(gotFromSrc, want) {
+		t.Errorf("wrong result from edited source\ngot:  %#v\nwant: %#v", gotFromSrc, want)
+	}
+}
+
+func TestWrapAttributeUnknownFunction(t *testing.T) {
+	body := WrapAttribute("a", "nope").TransformBody(parseTestBody(t, `a = 1`, "test.hcl"))
+	attrs, diags := body.JustAttributes()
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	_, diags = attrs["a"].Expr.Value(&hcl.EvalContext{})
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1", len(diags))
+	}
+	if got, want := diags[0].Code, hcl.DiagCallToUnknownFunction; got != want {
+		t.Errorf("wrong diagnostic code %q; want %q", got, want)
+	}
+
+	_, diags = attrs["a"].Expr.Value(&hcl.EvalContext{
+		Functions: map[string]function.Function{
+			"nope": stdlib.AbsoluteFunc,
+		},
+	})
+	if diags.HasErrors() {
+		t.Errorf("unexpected diagnostics: %s", diags)
+	}
+}
@@ -0,0 +1,104 @@
+package transform
+
+import (
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// ExprRewriter is a function that takes an expression from an attribute
+// and returns an expression to use in its place. It may return the given
+// expression unchanged.
+//
+// A rewriter must not mutate the given expression in-place, but it may
+// return a new expression that wraps it.
+type ExprRewriter func(hcl.Expression) hcl.Expression
+
+// RewriteExpressions returns a Transformer that passes the expression of
+// each attribute extracted from the given body through the given rewriter.
+//
+// This can be used, for example, to substitute deprecated variable names,
+// to provide default values when expressions are evaluated, or to record
+// which expressions are evaluated.
+//
+// The transform applies only to the attributes of the body itself. Use it
+// with Deep or Recursive to also rewrite the attributes of nested blocks.
+func RewriteExpressions(rewrite ExprRewriter) Transformer {
+	return TransformerFunc(func(body hcl.Body) hcl.Body {
+		return exprRewriteBody{
+			Wrapped: body,
+			Rewrite: rewrite,
+		}
+	})
+}
+
+type exprRewriteBody struct {
+	Wrapped hcl.Body
+	Rewrite ExprRewriter
+}
+
+func (b exprRewriteBody) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
+	content, diags := b.Wrapped.Content(schema)
+	return b.rewriteContent(content), diags
+}
+
+func (b exprRewriteBody) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
+	content, remain, diags := b.Wrapped.PartialContent(schema)
+	if remain != nil {
+		remain = exprRewriteBody{
+			Wrapped: remain,
+			Rewrite: b.Rewrite,
+		}
+	}
+	return b.rewriteContent(content), remain, diags
+}
+
+func (b exprRewriteBody) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
+	attrs, diags := b.Wrapped.JustAttributes()
+	return b.rewriteAttrs(attrs), diags
+}
+
+// JustAttributesInOrder is an implementation of the optional method
+// recognized by hcl.AttributesInOrder.
+func (b exprRewriteBody) JustAttributesInOrder() ([]*hcl.Attribute, hcl.Diagnostics) {
+	attrs, diags := hcl.AttributesInOrder(b.Wrapped)
+	ret := make([]*hcl.Attribute, len(attrs))
+	for i, attr := range attrs {
+		ret[i] = b.rewriteAttr(attr)
+	}
+	return ret, diags
+}
+
+func (b exprRewriteBody) MissingItemRange() hcl.Range {
+	return b.Wrapped.MissingItemRange()
+}
+
+func (b exprRewriteBody) rewriteContent(content *hcl.BodyContent) *hcl.BodyContent {
+	if content == nil || len(content.Attributes) == 0 {
+		return content
+	}
+
+	// We'll clone the structure so that we don't risk impacting any
+	// internal state of the original body.
+	return &hcl.BodyContent{
+		Attributes:       b.rewriteAttrs(content.Attributes),
+		Blocks:           content.Blocks,
+		MissingItemRange: content.MissingItemRange,
+	}
+}
+
+func (b exprRewriteBody) rewriteAttrs(attrs hcl.Attributes) hcl.Attributes {
+	if attrs == nil {
+		return nil
+	}
+	ret := make(hcl.Attributes, len(attrs))
+	for name, attr := range attrs {
+		ret[name] = b.rewriteAttr(attr)
+	}
+	return ret
+}
+
+func (b exprRewriteBody) rewriteAttr(attr *hcl.Attribute) *hcl.Attribute {
+	// Shallow-copy the attribute so we can mutate it
+	newAttr := *attr
+	newAttr.Expr = b.Rewrite(attr.Expr)
+	return &newAttr
+}
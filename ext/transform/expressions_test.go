@@ -0,0 +1,93 @@
+package transform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestRewriteExpressions(t *testing.T) {
+	src := `
+a = "a"
+b = "b"
+
+child {
+  c = "c"
+}
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+
+	// The rewriter records which expressions were rewritten and replaces
+	// each one with a static uppercase version of its constant value.
+	var seen []string
+	rewrite := ExprRewriter(func(expr hcl.Expression) hcl.Expression {
+		val, _ := expr.Value(nil)
+		seen = append(seen, val.AsString())
+		return hcl.StaticExpr(cty.StringVal(strings.ToUpper(val.AsString())), expr.Range())
+	})
+	body := Deep(f.Body, RewriteExpressions(rewrite))
+
+	content, remain, diags := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "a"},
+		},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	if got, want := attrString(t, content.Attributes["a"]), "A"; got != want {
+		t.Errorf("wrong value for a %q; want %q", got, want)
+	}
+
+	content, diags = remain.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "b"},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "child"},
+		},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	if got, want := attrString(t, content.Attributes["b"]), "B"; got != want {
+		t.Errorf("wrong value for b %q; want %q", got, want)
+	}
+	if got, want := content.Attributes["b"].Range, (hcl.Range{
+		Filename: "test.hcl",
+		Start:    hcl.Pos{Line: 3, Column: 1, Byte: 9},
+		End:      hcl.Pos{Line: 3, Column: 8, Byte: 16},
+	}); got != want {
+		t.Errorf("wrong attribute range\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	attrs, diags := content.Blocks[0].Body.JustAttributes()
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	if got, want := attrString(t, attrs["c"]), "C"; got != want {
+		t.Errorf("wrong value for c %q; want %q", got, want)
+	}
+
+	if got, want := len(seen), 3; got != want {
+		t.Errorf("rewriter called %d times; want %d", got, want)
+	}
+}
+
+func attrString(t *testing.T, attr *hcl.Attribute) string {
+	t.Helper()
+	if attr == nil {
+		t.Fatalf("attribute is missing")
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	return val.AsString()
+}
@@ -0,0 +1,227 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// RemoveBlocks returns a Transformer that removes blocks of the type
+// described by the given schema for which the given function returns true,
+// producing a body that behaves as if those blocks were never present. If
+// the function is nil then all blocks of the given type are removed.
+//
+// The schema must give the label names expected for the block type, so that
+// the blocks can be extracted before they are passed to the function. Any
+// errors in extracting the blocks are returned when content is later
+// extracted from the resulting body.
+//
+// Blocks of the given type that are not removed keep their order relative
+// to one another, but are returned after any blocks of other types that are
+// extracted in the same call.
+//
+// The transform applies only to the blocks of the body itself. Use it with
+// Deep or Recursive to also remove matching blocks nested inside other
+// blocks.
+func RemoveBlocks(blockS hcl.BlockHeaderSchema, remove func(*hcl.Block) bool) Transformer {
+	return TransformerFunc(func(body hcl.Body) hcl.Body {
+		content, remain, diags := body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{blockS},
+		})
+		kept := &hcl.BodyContent{}
+		for _, block := range content.Blocks {
+			if remove != nil && !remove(block) {
+				kept.Blocks = append(kept.Blocks, block)
+			}
+		}
+		return BodyWithDiagnostics(newFilterBody(remain, kept), diags)
+	})
+}
+
+// RemoveAttributes returns a Transformer that removes attributes with any
+// of the given names for which the given function returns true, producing
+// a body that behaves as if those attributes were never present. If the
+// function is nil then all attributes with the given names are removed.
+//
+// The transform applies only to the attributes of the body itself. Use it
+// with Deep or Recursive to also remove matching attributes nested inside
+// blocks.
+func RemoveAttributes(names []string, remove func(*hcl.Attribute) bool) Transformer {
+	schema := &hcl.BodySchema{}
+	for _, name := range names {
+		schema.Attributes = append(schema.Attributes, hcl.AttributeSchema{Name: name})
+	}
+	return TransformerFunc(func(body hcl.Body) hcl.Body {
+		content, remain, diags := body.PartialContent(schema)
+		kept := &hcl.BodyContent{
+			Attributes: make(hcl.Attributes),
+		}
+		for name, attr := range content.Attributes {
+			if remove != nil && !remove(attr) {
+				kept.Attributes[name] = attr
+			}
+		}
+		return BodyWithDiagnostics(newFilterBody(remain, kept), diags)
+	})
+}
+
+// BlockLabels returns a function for use with RemoveBlocks that matches
+// blocks whose labels begin with the given labels.
+func BlockLabels(labels ...string) func(*hcl.Block) bool {
+	return func(block *hcl.Block) bool {
+		if len(block.Labels) < len(labels) {
+			return false
+		}
+		for i, label := range labels {
+			if block.Labels[i] != label {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// filterBody is a hcl.Body implementation that combines a body from which
+// some items were extracted with those extracted items that were not
+// removed, so that the kept items behave as if they were never extracted.
+type filterBody struct {
+	Wrapped hcl.Body
+	Kept    *hcl.BodyContent
+}
+
+func newFilterBody(remain hcl.Body, kept *hcl.BodyContent) hcl.Body {
+	if len(kept.Attributes) == 0 && len(kept.Blocks) == 0 {
+		// Nothing to put back, so the remaining body is all we need.
+		return remain
+	}
+	return filterBody{
+		Wrapped: remain,
+		Kept:    kept,
+	}
+}
+
+func (b filterBody) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
+	content, diags := b.Wrapped.Content(b.wrappedSchema(schema))
+	content, leftover := b.addKept(schema, content)
+
+	for _, attr := range leftover.Attributes {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported argument",
+			Code:     hcl.DiagUnsupportedArgument,
+			Detail:   fmt.Sprintf("An argument named %q is not expected here.", attr.Name),
+			Subject:  &attr.NameRange,
+		})
+	}
+	for _, block := range leftover.Blocks {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported block type",
+			Code:     hcl.DiagUnsupportedBlockType,
+			Detail:   fmt.Sprintf("Blocks of type %q are not expected here.", block.Type),
+			Subject:  &block.TypeRange,
+		})
+	}
+
+	return content, diags
+}
+
+func (b filterBody) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
+	content, remain, diags := b.Wrapped.PartialContent(b.wrappedSchema(schema))
+	content, leftover := b.addKept(schema, content)
+	return content, newFilterBody(remain, leftover), diags
+}
+
+func (b filterBody) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
+	attrs, diags := b.Wrapped.JustAttributes()
+	for _, block := range b.Kept.Blocks {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Unexpected %q block", block.Type),
+			Code:     hcl.DiagUnexpectedBlock,
+			Detail:   "Blocks are not allowed here.",
+			Subject:  &block.TypeRange,
+		})
+		break
+	}
+	if len(b.Kept.Attributes) == 0 {
+		return attrs, diags
+	}
+
+	ret := make(hcl.Attributes, len(attrs)+len(b.Kept.Attributes))
+	for name, attr := range attrs {
+		ret[name] = attr
+	}
+	for name, attr := range b.Kept.Attributes {
+		ret[name] = attr
+	}
+	return ret, diags
+}
+
+func (b filterBody) MissingItemRange() hcl.Range {
+	return b.Wrapped.MissingItemRange()
+}
+
+// wrappedSchema returns a version of the given schema to use with the
+// wrapped body, where the kept attributes are not marked as required because
+// the wrapped body no longer contains them.
+func (b filterBody) wrappedSchema(schema *hcl.BodySchema) *hcl.BodySchema {
+	if len(b.Kept.Attributes) == 0 {
+		return schema
+	}
+	ret := &hcl.BodySchema{
+		Blocks: schema.Blocks,
+	}
+	for _, attrS := range schema.Attributes {
+		if _, kept := b.Kept.Attributes[attrS.Name]; kept {
+			attrS.Required = false
+		}
+		ret.Attributes = append(ret.Attributes, attrS)
+	}
+	return ret
+}
+
+// addKept returns a copy of the given content with any kept items that are
+// included in the given schema added to it, along with the kept items that
+// are not included in the schema.
+func (b filterBody) addKept(schema *hcl.BodySchema, content *hcl.BodyContent) (*hcl.BodyContent, *hcl.BodyContent) {
+	// We'll clone the structure so that we don't risk impacting any
+	// internal state of the wrapped body.
+	ret := &hcl.BodyContent{
+		Attributes:       make(hcl.Attributes, len(content.Attributes)),
+		Blocks:           append(hcl.Blocks(nil), content.Blocks...),
+		MissingItemRange: content.MissingItemRange,
+	}
+	for name, attr := range content.Attributes {
+		ret.Attributes[name] = attr
+	}
+	leftover := &hcl.BodyContent{
+		Attributes: make(hcl.Attributes),
+	}
+
+	wantAttrs := make(map[string]struct{}, len(schema.Attributes))
+	for _, attrS := range schema.Attributes {
+		wantAttrs[attrS.Name] = struct{}{}
+	}
+	for name, attr := range b.Kept.Attributes {
+		if _, wanted := wantAttrs[name]; wanted {
+			ret.Attributes[name] = attr
+		} else {
+			leftover.Attributes[name] = attr
+		}
+	}
+
+	wantBlocks := make(map[string]struct{}, len(schema.Blocks))
+	for _, blockS := range schema.Blocks {
+		wantBlocks[blockS.Type] = struct{}{}
+	}
+	for _, block := range b.Kept.Blocks {
+		if _, wanted := wantBlocks[block.Type]; wanted {
+			ret.Blocks = append(ret.Blocks, block)
+		} else {
+			leftover.Blocks = append(leftover.Blocks, block)
+		}
+	}
+
+	return ret, leftover
+}
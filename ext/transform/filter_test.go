@@ -0,0 +1,182 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+func TestRemoveBlocks(t *testing.T) {
+	src := `
+feature "a" {
+}
+feature "b" {
+}
+service "web" {
+}
+name = "example"
+`
+	featureS := hcl.BlockHeaderSchema{
+		Type:       "feature",
+		LabelNames: []string{"name"},
+	}
+	serviceS := hcl.BlockHeaderSchema{
+		Type:       "service",
+		LabelNames: []string{"name"},
+	}
+
+	tests := map[string]struct {
+		Transformer Transformer
+		Schema      *hcl.BodySchema
+		WantBlocks  []string
+		WantAttrs   []string
+		WantDiags   int
+	}{
+		"remove one by label": {
+			RemoveBlocks(featureS, BlockLabels("a")),
+			&hcl.BodySchema{
+				Attributes: []hcl.AttributeSchema{{Name: "name"}},
+				Blocks:     []hcl.BlockHeaderSchema{featureS, serviceS},
+			},
+			[]string{"service.web", "feature.b"},
+			[]string{"name"},
+			0,
+		},
+		"remove all of type": {
+			RemoveBlocks(featureS, nil),
+			&hcl.BodySchema{
+				Attributes: []hcl.AttributeSchema{{Name: "name"}},
+				Blocks:     []hcl.BlockHeaderSchema{serviceS},
+			},
+			[]string{"service.web"},
+			[]string{"name"},
+			0,
+		},
+		"kept block not in schema": {
+			RemoveBlocks(featureS, BlockLabels("a")),
+			&hcl.BodySchema{
+				Attributes: []hcl.AttributeSchema{{Name: "name"}},
+				Blocks:     []hcl.BlockHeaderSchema{serviceS},
+			},
+			[]string{"service.web"},
+			[]string{"name"},
+			1, // feature "b" is not expected
+		},
+		"remove attribute": {
+			RemoveAttributes([]string{"name"}, nil),
+			&hcl.BodySchema{
+				Blocks: []hcl.BlockHeaderSchema{featureS, serviceS},
+			},
+			[]string{"feature.a", "feature.b", "service.web"},
+			nil,
+			0,
+		},
+		"remove required attribute": {
+			RemoveAttributes([]string{"name"}, nil),
+			&hcl.BodySchema{
+				Attributes: []hcl.AttributeSchema{{Name: "name", Required: true}},
+				Blocks:     []hcl.BlockHeaderSchema{featureS, serviceS},
+			},
+			[]string{"feature.a", "feature.b", "service.web"},
+			nil,
+			1, // missing required argument "name"
+		},
+		"keep required attribute": {
+			RemoveAttributes([]string{"name"}, func(attr *hcl.Attribute) bool {
+				return false
+			}),
+			&hcl.BodySchema{
+				Attributes: []hcl.AttributeSchema{{Name: "name", Required: true}},
+				Blocks:     []hcl.BlockHeaderSchema{featureS, serviceS},
+			},
+			[]string{"feature.a", "feature.b", "service.web"},
+			[]string{"name"},
+			0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags)
+			}
+			body := test.Transformer.TransformBody(f.Body)
+
+			content, diags := body.Content(test.Schema)
+			if len(diags) != test.WantDiags {
+				t.Errorf("wrong number of diagnostics %d; want %d", len(diags), test.WantDiags)
+				for _, diag := range diags {
+					t.Logf("- %s", diag)
+				}
+			}
+
+			var gotBlocks []string
+			for _, block := range content.Blocks {
+				gotBlocks = append(gotBlocks, block.Type+"."+block.Labels[0])
+			}
+			if !stringsEqual(gotBlocks, test.WantBlocks) {
+				t.Errorf("wrong blocks\ngot:  %#v\nwant: %#v", gotBlocks, test.WantBlocks)
+			}
+			var gotAttrs []string
+			for name := range content.Attributes {
+				gotAttrs = append(gotAttrs, name)
+			}
+			if !stringsEqual(gotAttrs, test.WantAttrs) {
+				t.Errorf("wrong attributes\ngot:  %#v\nwant: %#v", gotAttrs, test.WantAttrs)
+			}
+		})
+	}
+}
+
+func TestRemoveBlocksPartialContent(t *testing.T) {
+	src := `
+feature "a" {
+}
+feature "b" {
+}
+name = "example"
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	featureS := hcl.BlockHeaderSchema{
+		Type:       "feature",
+		LabelNames: []string{"name"},
+	}
+	body := RemoveBlocks(featureS, BlockLabels("b")).TransformBody(f.Body)
+
+	// The kept block must stay in the remaining body until it is requested.
+	_, remain, diags := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "name"}},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	content, diags := remain.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{featureS},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	if got, want := len(content.Blocks), 1; got != want {
+		t.Fatalf("wrong number of blocks %d; want %d", got, want)
+	}
+	if got, want := content.Blocks[0].Labels[0], "a"; got != want {
+		t.Errorf("wrong block label %q; want %q", got, want)
+	}
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
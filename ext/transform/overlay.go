@@ -0,0 +1,158 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// Defaults returns a Transformer that layers each body it is given over the
+// given defaults body, as described for Overlay.
+func Defaults(defaults hcl.Body) Transformer {
+	return TransformerFunc(func(body hcl.Body) hcl.Body {
+		return Overlay(body, defaults)
+	})
+}
+
+// Overlay returns a body that combines the content of the given body with
+// that of the given defaults body, which allows layered configuration such
+// as a base configuration with environment-specific overrides.
+//
+// Attributes in the body take precedence over attributes of the same name
+// in the defaults, while attributes that are set only in the defaults are
+// included as-is. A required attribute may be set in either body.
+//
+// Blocks in the body that have the same type and labels as a block in the
+// defaults are merged with it, recursively applying the same rules to their
+// bodies. Blocks in the defaults that don't correspond to any block in the
+// body are included after the blocks of the body.
+//
+// Content extracted from both bodies must conform to the given schema, so
+// errors are reported for any unexpected items in the defaults body as well
+// as in the body itself.
+func Overlay(body, defaults hcl.Body) hcl.Body {
+	return overlayBody{
+		Body:     body,
+		Defaults: defaults,
+	}
+}
+
+type overlayBody struct {
+	Body     hcl.Body
+	Defaults hcl.Body
+}
+
+func (b overlayBody) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
+	innerSchema := overlaySchema(schema)
+	content, diags := b.Body.Content(innerSchema)
+	defContent, defDiags := b.Defaults.Content(innerSchema)
+	diags = append(diags, defDiags...)
+	return b.mergeContent(schema, content, defContent, diags)
+}
+
+func (b overlayBody) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
+	innerSchema := overlaySchema(schema)
+	content, remain, diags := b.Body.PartialContent(innerSchema)
+	defContent, defRemain, defDiags := b.Defaults.PartialContent(innerSchema)
+	diags = append(diags, defDiags...)
+	merged, diags := b.mergeContent(schema, content, defContent, diags)
+	return merged, Overlay(remain, defRemain), diags
+}
+
+func (b overlayBody) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
+	attrs, diags := b.Body.JustAttributes()
+	defAttrs, defDiags := b.Defaults.JustAttributes()
+	diags = append(diags, defDiags...)
+
+	ret := make(hcl.Attributes, len(attrs)+len(defAttrs))
+	for name, attr := range defAttrs {
+		ret[name] = attr
+	}
+	for name, attr := range attrs {
+		ret[name] = attr
+	}
+	return ret, diags
+}
+
+func (b overlayBody) MissingItemRange() hcl.Range {
+	return b.Body.MissingItemRange()
+}
+
+func (b overlayBody) mergeContent(schema *hcl.BodySchema, content, defContent *hcl.BodyContent, diags hcl.Diagnostics) (*hcl.BodyContent, hcl.Diagnostics) {
+	ret := &hcl.BodyContent{
+		Attributes:       make(hcl.Attributes, len(content.Attributes)+len(defContent.Attributes)),
+		MissingItemRange: content.MissingItemRange,
+	}
+	for name, attr := range defContent.Attributes {
+		ret.Attributes[name] = attr
+	}
+	for name, attr := range content.Attributes {
+		ret.Attributes[name] = attr
+	}
+
+	for _, attrS := range schema.Attributes {
+		if attrS.Required && ret.Attributes[attrS.Name] == nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Missing required argument",
+				Code:     hcl.DiagMissingRequiredArgument,
+				Detail:   fmt.Sprintf("The argument %q is required, but no definition was found.", attrS.Name),
+				Subject:  b.MissingItemRange().Ptr(),
+			})
+		}
+	}
+
+	defBlocks := make(map[string][]*hcl.Block)
+	for _, block := range defContent.Blocks {
+		key := overlayBlockKey(block)
+		defBlocks[key] = append(defBlocks[key], block)
+	}
+	used := make(map[string]bool)
+	for _, block := range content.Blocks {
+		key := overlayBlockKey(block)
+		defs := defBlocks[key]
+		if len(defs) == 0 {
+			ret.Blocks = append(ret.Blocks, block)
+			continue
+		}
+		used[key] = true
+
+		// Shallow-copy the block so we can mutate it
+		newBlock := *block
+		for _, def := range defs {
+			newBlock.Body = Overlay(newBlock.Body, def.Body)
+		}
+		ret.Blocks = append(ret.Blocks, &newBlock)
+	}
+	for _, block := range defContent.Blocks {
+		if !used[overlayBlockKey(block)] {
+			ret.Blocks = append(ret.Blocks, block)
+		}
+	}
+
+	return ret, diags
+}
+
+// overlaySchema returns a version of the given schema with none of the
+// attributes marked as required, since either of the bodies can provide
+// an attribute value. Overlay bodies check required attributes separately.
+func overlaySchema(schema *hcl.BodySchema) *hcl.BodySchema {
+	ret := &hcl.BodySchema{
+		Blocks: schema.Blocks,
+	}
+	for _, attrS := range schema.Attributes {
+		attrS.Required = false
+		ret.Attributes = append(ret.Attributes, attrS)
+	}
+	return ret
+}
+
+// overlayBlockKey returns a string that is unique for each distinct
+// combination of block type and labels.
+func overlayBlockKey(block *hcl.Block) string {
+	parts := make([]string, 0, len(block.Labels)+1)
+	parts = append(parts, block.Type)
+	parts = append(parts, block.Labels...)
+	return strings.Join(parts, "\x00")
+}
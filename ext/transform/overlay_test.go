@@ -0,0 +1,134 @@
+package transform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/gohcl"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+func TestOverlay(t *testing.T) {
+	defaultsSrc := `
+port   = 80
+region = "us"
+
+service "web" {
+  replicas = 1
+  image    = "base"
+}
+
+service "db" {
+  replicas = 1
+  image    = "postgres"
+}
+`
+	bodySrc := `
+port = 8080
+
+service "web" {
+  image = "custom"
+}
+
+service "api" {
+  replicas = 3
+  image    = "api"
+}
+`
+	type Service struct {
+		Name     string `hcl:"name,label"`
+		Replicas int    `hcl:"replicas"`
+		Image    string `hcl:"image"`
+	}
+	type Config struct {
+		Port     int       `hcl:"port"`
+		Region   string    `hcl:"region"`
+		Services []Service `hcl:"service,block"`
+	}
+
+	defaults := parseTestBody(t, defaultsSrc, "defaults.hcl")
+	body := Defaults(defaults).TransformBody(parseTestBody(t, bodySrc, "body.hcl"))
+
+	var got Config
+	diags := gohcl.DecodeBody(body, nil, &got)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+
+	want := Config{
+		Port:   8080,
+		Region: "us",
+		Services: []Service{
+			{Name: "web", Replicas: 1, Image: "custom"},
+			{Name: "api", Replicas: 3, Image: "api"},
+			{Name: "db", Replicas: 1, Image: "postgres"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestOverlayDiagnostics(t *testing.T) {
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "a", Required: true},
+			{Name: "b", Required: true},
+		},
+	}
+
+	tests := map[string]struct {
+		Body      string
+		Defaults  string
+		WantDiags int
+	}{
+		"required in body": {
+			`a = 1
+b = 2`,
+			``,
+			0,
+		},
+		"required in defaults": {
+			`a = 1`,
+			`b = 2`,
+			0,
+		},
+		"required missing": {
+			`a = 1`,
+			``,
+			1, // missing required argument "b"
+		},
+		"unexpected in defaults": {
+			`a = 1`,
+			`b = 2
+c = 3`,
+			1, // unsupported argument "c"
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			body := Overlay(
+				parseTestBody(t, test.Body, "body.hcl"),
+				parseTestBody(t, test.Defaults, "defaults.hcl"),
+			)
+			_, diags := body.Content(schema)
+			if len(diags) != test.WantDiags {
+				t.Errorf("wrong number of diagnostics %d; want %d", len(diags), test.WantDiags)
+				for _, diag := range diags {
+					t.Logf("- %s", diag)
+				}
+			}
+		})
+	}
+}
+
+func parseTestBody(t *testing.T, src, filename string) hcl.Body {
+	t.Helper()
+	f, diags := hclsyntax.ParseConfig([]byte(src), filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	return f.Body
+}
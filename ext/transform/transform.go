@@ -28,6 +28,20 @@ func Deep(body hcl.Body, transformer Transformer) hcl.Body {
 	}
 }
 
+// Recursive returns a Transformer that applies the given transformer to
+// each body it is given in the same way as Deep, so that the transform also
+// applies to the bodies of all of the nested blocks that are extracted from
+// the result.
+//
+// This allows a transformer that would normally apply only to a single body
+// to be combined with others, such as via Chain, while still applying at all
+// levels of the block structure.
+func Recursive(transformer Transformer) Transformer {
+	return TransformerFunc(func(body hcl.Body) hcl.Body {
+		return Deep(body, transformer)
+	})
+}
+
 // deepWrapper is a hcl.Body implementation that ensures that a given
 // transformer is applied to another given body when content is extracted,
 // and that it recursively applies to any child blocks that are extracted.
@@ -45,11 +59,19 @@ func (w deepWrapper) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diag
 func (w deepWrapper) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
 	content, remain, diags := w.Transformed.PartialContent(schema)
 	content = w.transformContent(content)
+	if remain != nil {
+		// The remaining body was already produced by the transformer, but
+		// blocks extracted from it later must still be transformed.
+		remain = deepWrapper{
+			Transformed: remain,
+			Transformer: w.Transformer,
+		}
+	}
 	return content, remain, diags
 }
 
 func (w deepWrapper) transformContent(content *hcl.BodyContent) *hcl.BodyContent {
-	if len(content.Blocks) == 0 {
+	if content == nil || len(content.Blocks) == 0 {
 		// Easy path: if there are no blocks then there are no child bodies to wrap
 		return content
 	}
@@ -78,6 +100,12 @@ func (w deepWrapper) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
 	return w.Transformed.JustAttributes()
 }
 
+// JustAttributesInOrder is an implementation of the optional method
+// recognized by hcl.AttributesInOrder.
+func (w deepWrapper) JustAttributesInOrder() ([]*hcl.Attribute, hcl.Diagnostics) {
+	return hcl.AttributesInOrder(w.Transformed)
+}
+
 func (w deepWrapper) MissingItemRange() hcl.Range {
 	return w.Transformed.MissingItemRange()
 }
@@ -100,3 +100,86 @@ func TestDeep(t *testing.T) {
 		t.Errorf("unexpected blocks in child content; want empty content")
 	}
 }
+
+func TestRecursivePartialContent(t *testing.T) {
+	removeBlocks := TransformerFunc(func(body hcl.Body) hcl.Body {
+		_, remain, diags := body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{
+				{
+					Type: "remove",
+				},
+			},
+		})
+		return BodyWithDiagnostics(remain, diags)
+	})
+
+	src := hcltest.MockBody(&hcl.BodyContent{
+		Attributes: hcltest.MockAttrs(map[string]hcl.Expression{
+			"a": hcltest.MockExprLiteral(cty.True),
+		}),
+		Blocks: []*hcl.Block{
+			{
+				Type: "remove",
+				Body: hcl.EmptyBody(),
+			},
+			{
+				Type: "child",
+				Body: hcltest.MockBody(&hcl.BodyContent{
+					Blocks: []*hcl.Block{
+						{
+							Type: "remove",
+							Body: hcl.EmptyBody(),
+						},
+						{
+							Type: "keep",
+							Body: hcl.EmptyBody(),
+						},
+					},
+				}),
+			},
+		},
+	})
+
+	wrapped := Chain([]Transformer{Recursive(removeBlocks)}).TransformBody(src)
+
+	// Extract the attribute first, leaving the child block in the remaining
+	// body, to ensure that the transform also applies to blocks extracted
+	// from a remaining body.
+	_, remain, diags := wrapped.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{
+				Name: "a",
+			},
+		},
+	})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	rootContent, diags := remain.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{
+				Type: "child",
+			},
+		},
+	})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	if got, want := len(rootContent.Blocks), 1; got != want {
+		t.Fatalf("wrong number of root blocks %d; want %d", got, want)
+	}
+
+	childContent, diags := rootContent.Blocks[0].Body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{
+				Type: "keep",
+			},
+		},
+	})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics for child content: %s", diags)
+	}
+	if got, want := len(childContent.Blocks), 1; got != want {
+		t.Fatalf("wrong number of child blocks %d; want %d", got, want)
+	}
+}
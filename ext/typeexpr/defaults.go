@@ -0,0 +1,129 @@
+package typeexpr
+
+import (
+	"strconv"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Defaults describes the optional attributes and their default values
+// declared within a type constraint by TypeConstraintWithDefaults.
+//
+// A Defaults value is associated with a single type within the constraint.
+// For an object type, Optional and DefaultValues describe the attributes of
+// that object. Children then describes any nested types that themselves
+// declare optional attributes, keyed by attribute name for object types, by
+// decimal element index for tuple types, and by the empty string for the
+// element type of a list, set or map type.
+type Defaults struct {
+	Type          cty.Type
+	Optional      map[string]struct{}
+	DefaultValues map[string]cty.Value
+	Children      map[string]*Defaults
+}
+
+// Apply returns a copy of the given value with any missing or null optional
+// attributes populated, either with their declared default value or with a
+// null value of the attribute type.
+//
+// The result is not converted to the type the defaults were declared for,
+// and collections within the given value may be returned as tuple or object
+// values, so callers should convert the result to the constraint type
+// afterwards:
+//
+//     ty, defaults, diags := typeexpr.TypeConstraintWithDefaults(expr)
+//     // ...
+//     val, err := convert.Convert(defaults.Apply(val), ty)
+//
+// Apply may be called on a nil *Defaults, in which case it returns the given
+// value unchanged. Null and unknown values are also returned unchanged.
+func (d *Defaults) Apply(val cty.Value) cty.Value {
+	if d == nil || val.IsNull() || !val.IsKnown() {
+		return val
+	}
+	ty := val.Type()
+
+	switch {
+	case d.Type.IsObjectType():
+		if !(ty.IsObjectType() || ty.IsMapType()) {
+			// Type conversion will report the problem with this value.
+			return val
+		}
+		attrs := make(map[string]cty.Value)
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			attrs[k.AsString()] = v
+		}
+		for name := range d.Optional {
+			if v, exists := attrs[name]; exists && !v.IsNull() {
+				continue
+			}
+			if def, ok := d.DefaultValues[name]; ok {
+				attrs[name] = def
+			} else {
+				attrs[name] = cty.NullVal(d.Type.AttributeType(name))
+			}
+		}
+		for name, child := range d.Children {
+			if v, exists := attrs[name]; exists {
+				attrs[name] = child.Apply(v)
+			}
+		}
+		return cty.ObjectVal(attrs)
+
+	case d.Type.IsTupleType():
+		if !(ty.IsTupleType() || ty.IsListType()) {
+			return val
+		}
+		elems := make([]cty.Value, 0, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			elems = append(elems, d.Children[strconv.Itoa(len(elems))].Apply(v))
+		}
+		if len(elems) == 0 {
+			return val
+		}
+		return cty.TupleVal(elems)
+
+	case d.Type.IsMapType():
+		child := d.Children[""]
+		if child == nil || !(ty.IsObjectType() || ty.IsMapType()) {
+			return val
+		}
+		attrs := make(map[string]cty.Value)
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			attrs[k.AsString()] = child.Apply(v)
+		}
+		return cty.ObjectVal(attrs)
+
+	case d.Type.IsListType() || d.Type.IsSetType():
+		child := d.Children[""]
+		if child == nil || !(ty.IsListType() || ty.IsSetType() || ty.IsTupleType()) {
+			return val
+		}
+		elems := make([]cty.Value, 0, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			elems = append(elems, child.Apply(v))
+		}
+		if len(elems) == 0 {
+			return val
+		}
+		return cty.TupleVal(elems)
+	}
+
+	return val
+}
+
+// collectionDefaults wraps the defaults for the element type of a list, set
+// or map type, returning nil if the element type has no defaults.
+func collectionDefaults(ty cty.Type, elem *Defaults) *Defaults {
+	if elem == nil {
+		return nil
+	}
+	return &Defaults{
+		Type:     ty,
+		Children: map[string]*Defaults{"": elem},
+	}
+}
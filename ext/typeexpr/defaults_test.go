@@ -0,0 +1,220 @@
+package typeexpr
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+func TestTypeConstraintWithDefaults(t *testing.T) {
+	tests := []struct {
+		Source    string
+		Input     cty.Value
+		WantType  cty.Type
+		Want      cty.Value
+		WantError string
+	}{
+		{
+			`string`,
+			cty.StringVal("a"),
+			cty.String,
+			cty.StringVal("a"),
+			``,
+		},
+		{
+			`object({name=string,port=optional(number,80)})`,
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("web"),
+			}),
+			cty.Object(map[string]cty.Type{
+				"name": cty.String,
+				"port": cty.Number,
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("web"),
+				"port": cty.NumberIntVal(80),
+			}),
+			``,
+		},
+		{
+			`object({name=string,port=optional(number,80)})`,
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("web"),
+				"port": cty.NumberIntVal(8080),
+			}),
+			cty.Object(map[string]cty.Type{
+				"name": cty.String,
+				"port": cty.Number,
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("web"),
+				"port": cty.NumberIntVal(8080),
+			}),
+			``,
+		},
+		{
+			`object({name=string,port=optional(number,80)})`,
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("web"),
+				"port": cty.NullVal(cty.Number),
+			}),
+			cty.Object(map[string]cty.Type{
+				"name": cty.String,
+				"port": cty.Number,
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("web"),
+				"port": cty.NumberIntVal(80),
+			}),
+			``,
+		},
+		{
+			`object({name=optional(string)})`,
+			cty.EmptyObjectVal,
+			cty.Object(map[string]cty.Type{
+				"name": cty.String,
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.NullVal(cty.String),
+			}),
+			``,
+		},
+		{
+			`list(object({name=string,port=optional(number,80)}))`,
+			cty.TupleVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal("a"),
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal("b"),
+					"port": cty.NumberIntVal(81),
+				}),
+			}),
+			cty.List(cty.Object(map[string]cty.Type{
+				"name": cty.String,
+				"port": cty.Number,
+			})),
+			cty.ListVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal("a"),
+					"port": cty.NumberIntVal(80),
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal("b"),
+					"port": cty.NumberIntVal(81),
+				}),
+			}),
+			``,
+		},
+		{
+			`map(object({tags=optional(list(string),["x"])}))`,
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.EmptyObjectVal,
+			}),
+			cty.Map(cty.Object(map[string]cty.Type{
+				"tags": cty.List(cty.String),
+			})),
+			cty.MapVal(map[string]cty.Value{
+				"a": cty.ObjectVal(map[string]cty.Value{
+					"tags": cty.ListVal([]cty.Value{cty.StringVal("x")}),
+				}),
+			}),
+			``,
+		},
+		{
+			`tuple([string,object({b=optional(bool,true)})])`,
+			cty.TupleVal([]cty.Value{
+				cty.StringVal("a"),
+				cty.EmptyObjectVal,
+			}),
+			cty.Tuple([]cty.Type{
+				cty.String,
+				cty.Object(map[string]cty.Type{"b": cty.Bool}),
+			}),
+			cty.TupleVal([]cty.Value{
+				cty.StringVal("a"),
+				cty.ObjectVal(map[string]cty.Value{"b": cty.True}),
+			}),
+			``,
+		},
+		{
+			`object({outer=optional(object({inner=optional(number,1)}),{})})`,
+			cty.EmptyObjectVal,
+			cty.Object(map[string]cty.Type{
+				"outer": cty.Object(map[string]cty.Type{"inner": cty.Number}),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"outer": cty.ObjectVal(map[string]cty.Value{
+					"inner": cty.NumberIntVal(1),
+				}),
+			}),
+			``,
+		},
+		{
+			`object({port=optional(number,"nope")})`,
+			cty.NilVal,
+			cty.Object(map[string]cty.Type{"port": cty.Number}),
+			cty.NilVal,
+			`The default value for attribute "port" is not compatible with the attribute type: a number is required.`,
+		},
+		{
+			`object({port=optional(number,80,1)})`,
+			cty.NilVal,
+			cty.EmptyObject,
+			cty.NilVal,
+			`The optional modifier requires the attribute type as its first argument and an optional default value as its second argument.`,
+		},
+		{
+			`list(optional(string))`,
+			cty.NilVal,
+			cty.List(cty.DynamicPseudoType),
+			cty.NilVal,
+			`The optional modifier can be used only to declare an attribute of an object type.`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Source, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(test.Source), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("failed to parse: %s", diags)
+			}
+
+			gotType, defaults, diags := TypeConstraintWithDefaults(expr)
+			if test.WantError == "" {
+				for _, diag := range diags {
+					t.Error(diag)
+				}
+			} else {
+				found := false
+				for _, diag := range diags {
+					t.Log(diag)
+					if diag.Severity == hcl.DiagError && diag.Detail == test.WantError {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("missing expected error detail message: %s", test.WantError)
+				}
+			}
+
+			if !gotType.Equals(test.WantType) {
+				t.Errorf("wrong type\ngot:  %#v\nwant: %#v", gotType, test.WantType)
+			}
+			if test.Input == cty.NilVal {
+				return
+			}
+
+			got, err := convert.Convert(defaults.Apply(test.Input), gotType)
+			if err != nil {
+				t.Fatalf("conversion failed: %s", err)
+			}
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
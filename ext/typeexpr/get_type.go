@@ -2,9 +2,11 @@ package typeexpr
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/hashicorp/hcl2/hcl"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 )
 
 const invalidTypeSummary = "Invalid type specification"
@@ -13,41 +15,49 @@ const invalidTypeSummary = "Invalid type specification"
 // using the passed flag to distinguish. When constraint is false, the "any"
 // keyword will produce an error.
 func getType(expr hcl.Expression, constraint bool) (cty.Type, hcl.Diagnostics) {
+	ty, _, diags := getTypeDefaults(expr, constraint, false)
+	return ty, diags
+}
+
+// getTypeDefaults is the internal implementation of Type, TypeConstraint
+// and TypeConstraintWithDefaults. When withDefaults is false, the "optional"
+// modifier will produce an error and the returned defaults are always nil.
+func getTypeDefaults(expr hcl.Expression, constraint, withDefaults bool) (cty.Type, *Defaults, hcl.Diagnostics) {
 	// First we'll try for one of our keywords
 	kw := hcl.ExprAsKeyword(expr)
 	switch kw {
 	case "bool":
-		return cty.Bool, nil
+		return cty.Bool, nil, nil
 	case "string":
-		return cty.String, nil
+		return cty.String, nil, nil
 	case "number":
-		return cty.Number, nil
+		return cty.Number, nil, nil
 	case "any":
 		if constraint {
-			return cty.DynamicPseudoType, nil
+			return cty.DynamicPseudoType, nil, nil
 		}
-		return cty.DynamicPseudoType, hcl.Diagnostics{{
+		return cty.DynamicPseudoType, nil, hcl.Diagnostics{{
 			Severity: hcl.DiagError,
 			Summary:  invalidTypeSummary,
 			Detail:   fmt.Sprintf("The keyword %q cannot be used in this type specification: an exact type is required.", kw),
 			Subject:  expr.Range().Ptr(),
 		}}
 	case "list", "map", "set":
-		return cty.DynamicPseudoType, hcl.Diagnostics{{
+		return cty.DynamicPseudoType, nil, hcl.Diagnostics{{
 			Severity: hcl.DiagError,
 			Summary:  invalidTypeSummary,
 			Detail:   fmt.Sprintf("The %s type constructor requires one argument specifying the element type.", kw),
 			Subject:  expr.Range().Ptr(),
 		}}
 	case "object":
-		return cty.DynamicPseudoType, hcl.Diagnostics{{
+		return cty.DynamicPseudoType, nil, hcl.Diagnostics{{
 			Severity: hcl.DiagError,
 			Summary:  invalidTypeSummary,
 			Detail:   "The object type constructor requires one argument specifying the attribute types and values as a map.",
 			Subject:  expr.Range().Ptr(),
 		}}
 	case "tuple":
-		return cty.DynamicPseudoType, hcl.Diagnostics{{
+		return cty.DynamicPseudoType, nil, hcl.Diagnostics{{
 			Severity: hcl.DiagError,
 			Summary:  invalidTypeSummary,
 			Detail:   "The tuple type constructor requires one argument specifying the element types as a list.",
@@ -56,7 +66,7 @@ func getType(expr hcl.Expression, constraint bool) (cty.Type, hcl.Diagnostics) {
 	case "":
 		// okay! we'll fall through and try processing as a call, then.
 	default:
-		return cty.DynamicPseudoType, hcl.Diagnostics{{
+		return cty.DynamicPseudoType, nil, hcl.Diagnostics{{
 			Severity: hcl.DiagError,
 			Summary:  invalidTypeSummary,
 			Detail:   fmt.Sprintf("The keyword %q is not a valid type specification.", kw),
@@ -68,7 +78,7 @@ func getType(expr hcl.Expression, constraint bool) (cty.Type, hcl.Diagnostics) {
 	// try to process it as a call instead.
 	call, diags := hcl.ExprCall(expr)
 	if diags.HasErrors() {
-		return cty.DynamicPseudoType, hcl.Diagnostics{{
+		return cty.DynamicPseudoType, nil, hcl.Diagnostics{{
 			Severity: hcl.DiagError,
 			Summary:  invalidTypeSummary,
 			Detail:   "A type specification is either a primitive type keyword (bool, number, string) or a complex type constructor call, like list(string).",
@@ -78,7 +88,7 @@ func getType(expr hcl.Expression, constraint bool) (cty.Type, hcl.Diagnostics) {
 
 	switch call.Name {
 	case "bool", "string", "number", "any":
-		return cty.DynamicPseudoType, hcl.Diagnostics{{
+		return cty.DynamicPseudoType, nil, hcl.Diagnostics{{
 			Severity: hcl.DiagError,
 			Summary:  invalidTypeSummary,
 			Detail:   fmt.Sprintf("Primitive type keyword %q does not expect arguments.", call.Name),
@@ -98,7 +108,7 @@ func getType(expr hcl.Expression, constraint bool) (cty.Type, hcl.Diagnostics) {
 
 		switch call.Name {
 		case "list", "set", "map":
-			return cty.DynamicPseudoType, hcl.Diagnostics{{
+			return cty.DynamicPseudoType, nil, hcl.Diagnostics{{
 				Severity: hcl.DiagError,
 				Summary:  invalidTypeSummary,
 				Detail:   fmt.Sprintf("The %s type constructor requires one argument specifying the element type.", call.Name),
@@ -106,7 +116,7 @@ func getType(expr hcl.Expression, constraint bool) (cty.Type, hcl.Diagnostics) {
 				Context:  &contextRange,
 			}}
 		case "object":
-			return cty.DynamicPseudoType, hcl.Diagnostics{{
+			return cty.DynamicPseudoType, nil, hcl.Diagnostics{{
 				Severity: hcl.DiagError,
 				Summary:  invalidTypeSummary,
 				Detail:   "The object type constructor requires one argument specifying the attribute types and values as a map.",
@@ -114,7 +124,7 @@ func getType(expr hcl.Expression, constraint bool) (cty.Type, hcl.Diagnostics) {
 				Context:  &contextRange,
 			}}
 		case "tuple":
-			return cty.DynamicPseudoType, hcl.Diagnostics{{
+			return cty.DynamicPseudoType, nil, hcl.Diagnostics{{
 				Severity: hcl.DiagError,
 				Summary:  invalidTypeSummary,
 				Detail:   "The tuple type constructor requires one argument specifying the element types as a list.",
@@ -127,18 +137,18 @@ func getType(expr hcl.Expression, constraint bool) (cty.Type, hcl.Diagnostics) {
 	switch call.Name {
 
 	case "list":
-		ety, diags := getType(call.Arguments[0], constraint)
-		return cty.List(ety), diags
+		ety, edefs, diags := getTypeDefaults(call.Arguments[0], constraint, withDefaults)
+		return cty.List(ety), collectionDefaults(cty.List(ety), edefs), diags
 	case "set":
-		ety, diags := getType(call.Arguments[0], constraint)
-		return cty.Set(ety), diags
+		ety, edefs, diags := getTypeDefaults(call.Arguments[0], constraint, withDefaults)
+		return cty.Set(ety), collectionDefaults(cty.Set(ety), edefs), diags
 	case "map":
-		ety, diags := getType(call.Arguments[0], constraint)
-		return cty.Map(ety), diags
+		ety, edefs, diags := getTypeDefaults(call.Arguments[0], constraint, withDefaults)
+		return cty.Map(ety), collectionDefaults(cty.Map(ety), edefs), diags
 	case "object":
 		attrDefs, diags := hcl.ExprMap(call.Arguments[0])
 		if diags.HasErrors() {
-			return cty.DynamicPseudoType, hcl.Diagnostics{{
+			return cty.DynamicPseudoType, nil, hcl.Diagnostics{{
 				Severity: hcl.DiagError,
 				Summary:  invalidTypeSummary,
 				Detail:   "Object type constructor requires a map whose keys are attribute names and whose values are the corresponding attribute types.",
@@ -148,6 +158,9 @@ func getType(expr hcl.Expression, constraint bool) (cty.Type, hcl.Diagnostics) {
 		}
 
 		atys := make(map[string]cty.Type)
+		var optional map[string]struct{}
+		var defaultVals map[string]cty.Value
+		var children map[string]*Defaults
 		for _, attrDef := range attrDefs {
 			attrName := hcl.ExprAsKeyword(attrDef.Key)
 			if attrName == "" {
@@ -160,15 +173,88 @@ func getType(expr hcl.Expression, constraint bool) (cty.Type, hcl.Diagnostics) {
 				})
 				continue
 			}
-			aty, attrDiags := getType(attrDef.Value, constraint)
+
+			atyExpr := attrDef.Value
+			var defaultExpr hcl.Expression
+			isOptional := false
+			if withDefaults {
+				if optCall, callDiags := hcl.ExprCall(atyExpr); !callDiags.HasErrors() && optCall.Name == "optional" {
+					if len(optCall.Arguments) < 1 || len(optCall.Arguments) > 2 {
+						diags = append(diags, &hcl.Diagnostic{
+							Severity: hcl.DiagError,
+							Summary:  invalidTypeSummary,
+							Detail:   "The optional modifier requires the attribute type as its first argument and an optional default value as its second argument.",
+							Subject:  &optCall.ArgsRange,
+							Context:  atyExpr.Range().Ptr(),
+						})
+						continue
+					}
+					isOptional = true
+					atyExpr = optCall.Arguments[0]
+					if len(optCall.Arguments) == 2 {
+						defaultExpr = optCall.Arguments[1]
+					}
+				}
+			}
+
+			aty, adefs, attrDiags := getTypeDefaults(atyExpr, constraint, withDefaults)
 			diags = append(diags, attrDiags...)
 			atys[attrName] = aty
+			if adefs != nil {
+				if children == nil {
+					children = make(map[string]*Defaults)
+				}
+				children[attrName] = adefs
+			}
+			if !isOptional {
+				continue
+			}
+			if optional == nil {
+				optional = make(map[string]struct{})
+			}
+			optional[attrName] = struct{}{}
+			if defaultExpr == nil {
+				continue
+			}
+
+			defaultVal, valDiags := defaultExpr.Value(nil)
+			diags = append(diags, valDiags...)
+			if valDiags.HasErrors() {
+				continue
+			}
+			// The default value may itself rely on defaults for the
+			// attribute's nested optional attributes.
+			defaultVal, err := convert.Convert(adefs.Apply(defaultVal), aty)
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid default value for optional attribute",
+					Detail:   fmt.Sprintf("The default value for attribute %q is not compatible with the attribute type: %s.", attrName, err),
+					Subject:  defaultExpr.Range().Ptr(),
+					Context:  attrDef.Value.Range().Ptr(),
+				})
+				continue
+			}
+			if defaultVals == nil {
+				defaultVals = make(map[string]cty.Value)
+			}
+			defaultVals[attrName] = defaultVal
+		}
+
+		ty := cty.Object(atys)
+		if optional == nil && children == nil {
+			return ty, nil, diags
 		}
-		return cty.Object(atys), diags
+		return ty, &Defaults{
+			Type:          ty,
+			Optional:      optional,
+			DefaultValues: defaultVals,
+			Children:      children,
+		}, diags
 	case "tuple":
 		elemDefs, diags := hcl.ExprList(call.Arguments[0])
 		if diags.HasErrors() {
-			return cty.DynamicPseudoType, hcl.Diagnostics{{
+			return cty.DynamicPseudoType, nil, hcl.Diagnostics{{
 				Severity: hcl.DiagError,
 				Summary:  invalidTypeSummary,
 				Detail:   "Tuple type constructor requires a list of element types.",
@@ -177,16 +263,38 @@ func getType(expr hcl.Expression, constraint bool) (cty.Type, hcl.Diagnostics) {
 			}}
 		}
 		etys := make([]cty.Type, len(elemDefs))
+		var children map[string]*Defaults
 		for i, defExpr := range elemDefs {
-			ety, elemDiags := getType(defExpr, constraint)
+			ety, edefs, elemDiags := getTypeDefaults(defExpr, constraint, withDefaults)
 			diags = append(diags, elemDiags...)
 			etys[i] = ety
+			if edefs != nil {
+				if children == nil {
+					children = make(map[string]*Defaults)
+				}
+				children[strconv.Itoa(i)] = edefs
+			}
+		}
+		ty := cty.Tuple(etys)
+		if children == nil {
+			return ty, nil, diags
 		}
-		return cty.Tuple(etys), diags
+		return ty, &Defaults{Type: ty, Children: children}, diags
+	case "optional":
+		detail := "The optional modifier is not supported in this type specification."
+		if withDefaults {
+			detail = "The optional modifier can be used only to declare an attribute of an object type."
+		}
+		return cty.DynamicPseudoType, nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  invalidTypeSummary,
+			Detail:   detail,
+			Subject:  expr.Range().Ptr(),
+		}}
 	default:
 		// Can't access call.Arguments in this path because we've not validated
 		// that it contains exactly one expression here.
-		return cty.DynamicPseudoType, hcl.Diagnostics{{
+		return cty.DynamicPseudoType, nil, hcl.Diagnostics{{
 			Severity: hcl.DiagError,
 			Summary:  invalidTypeSummary,
 			Detail:   fmt.Sprintf("Keyword %q is not a valid type constructor.", call.Name),
@@ -245,6 +245,12 @@ func TestGetType(t *testing.T) {
 			cty.List(cty.Map(cty.EmptyTuple)),
 			``,
 		},
+		{
+			`object({name=optional(string)})`,
+			true,
+			cty.Object(map[string]cty.Type{"name": cty.DynamicPseudoType}),
+			`The optional modifier is not supported in this type specification.`,
+		},
 	}
 
 	for _, test := range tests {
@@ -29,6 +29,23 @@ func TypeConstraint(expr hcl.Expression) (cty.Type, hcl.Diagnostics) {
 	return getType(expr, true)
 }
 
+// TypeConstraintWithDefaults is like TypeConstraint but additionally allows
+// the attributes of object types to be declared optional using the
+// "optional" modifier, with an optional default value:
+//
+//     object({
+//       name = string
+//       port = optional(number, 80)
+//     })
+//
+// The returned Defaults describe the optional attributes, and should be
+// applied to a value using Defaults.Apply before converting it to the
+// returned type. The returned Defaults are nil if the constraint declares no
+// optional attributes.
+func TypeConstraintWithDefaults(expr hcl.Expression) (cty.Type, *Defaults, hcl.Diagnostics) {
+	return getTypeDefaults(expr, true, true)
+}
+
 // TypeString returns a string rendering of the given type as it would be
 // expected to appear in the HCL native syntax.
 //
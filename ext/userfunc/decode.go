@@ -1,8 +1,12 @@
 package userfunc
 
 import (
+	"fmt"
+
+	"github.com/hashicorp/hcl2/ext/typeexpr"
 	"github.com/hashicorp/hcl2/hcl"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 	"github.com/zclconf/go-cty/cty/function"
 )
 
@@ -16,6 +20,14 @@ var funcBodySchema = &hcl.BodySchema{
 			Name:     "variadic_param",
 			Required: false,
 		},
+		{
+			Name:     "param_types",
+			Required: false,
+		},
+		{
+			Name:     "param_defaults",
+			Required: false,
+		},
 		{
 			Name:     "result",
 			Required: true,
@@ -52,6 +64,19 @@ func decodeUserFunctions(body hcl.Body, blockType string, contextFunc ContextFun
 		return baseCtx
 	}
 
+	// Each user function is able to call all of the others, so we'll first
+	// gather a constructor for each one and then produce the final functions
+	// once they are all known. Each call instantiates a fresh set of the
+	// functions for its result expression, tracking the call depth.
+	makers := make(map[string]func(depth int, state *callState) function.Function)
+	makeFuncs := func(depth int, state *callState) map[string]function.Function {
+		ret := make(map[string]function.Function, len(makers))
+		for name, maker := range makers {
+			ret[name] = maker(depth, state)
+		}
+		return ret
+	}
+
 	funcs = make(map[string]function.Function)
 Blocks:
 	for _, block := range content.Blocks {
@@ -71,6 +96,7 @@ Blocks:
 
 		var params []string
 		var varParam string
+		paramRanges := make(map[string]hcl.Range)
 
 		paramExprs, paramsDiags := hcl.ExprList(paramsExpr)
 		diags = append(diags, paramsDiags...)
@@ -88,6 +114,11 @@ Blocks:
 				})
 				continue Blocks
 			}
+			if prevRng, exists := paramRanges[param]; exists {
+				diags = append(diags, duplicateParamDiag(param, prevRng, paramExpr.Range()))
+				continue Blocks
+			}
+			paramRanges[param] = paramExpr.Range()
 			params = append(params, param)
 		}
 
@@ -102,55 +133,300 @@ Blocks:
 				})
 				continue
 			}
+			if prevRng, exists := paramRanges[varParam]; exists {
+				diags = append(diags, duplicateParamDiag(varParam, prevRng, varParamExpr.Range()))
+				continue
+			}
 		}
 
+		paramTypes := make(map[string]cty.Type)
+		if attr := funcContent.Attributes["param_types"]; attr != nil {
+			typeDiags := decodeParamTypes(attr.Expr, paramRanges, varParam, paramTypes)
+			diags = append(diags, typeDiags...)
+			if typeDiags.HasErrors() {
+				continue
+			}
+		}
+		paramType := func(name string) cty.Type {
+			if ty, ok := paramTypes[name]; ok {
+				return ty
+			}
+			return cty.DynamicPseudoType
+		}
+
+		var defaults map[string]cty.Value
+		if attr := funcContent.Attributes["param_defaults"]; attr != nil {
+			if varParamExpr != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid param_defaults",
+					Detail:   "Parameters with default values cannot be combined with a variadic parameter.",
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+				continue
+			}
+			var defaultDiags hcl.Diagnostics
+			defaults, defaultDiags = decodeParamDefaults(attr.Expr, params, paramRanges, paramTypes)
+			diags = append(diags, defaultDiags...)
+			if defaultDiags.HasErrors() {
+				continue
+			}
+		}
+		required := len(params) - len(defaults)
+
 		spec := &function.Spec{}
-		for _, paramName := range params {
+		for _, paramName := range params[:required] {
 			spec.Params = append(spec.Params, function.Parameter{
 				Name: paramName,
-				Type: cty.DynamicPseudoType,
+				Type: paramType(paramName),
 			})
 		}
 		if varParamExpr != nil {
 			spec.VarParam = &function.Parameter{
 				Name: varParam,
-				Type: cty.DynamicPseudoType,
+				Type: paramType(varParam),
+			}
+		} else if required < len(params) {
+			// The cty function machinery has no concept of optional
+			// parameters, so we accept the optional ones as variadic
+			// arguments and then check and convert them ourselves.
+			spec.VarParam = &function.Parameter{
+				Name:      params[required],
+				Type:      cty.DynamicPseudoType,
+				AllowNull: true,
 			}
 		}
-		impl := func(args []cty.Value) (cty.Value, error) {
-			ctx := getBaseCtx()
-			ctx = ctx.NewChild()
-			ctx.Variables = make(map[string]cty.Value)
+		makers[name] = func(depth int, state *callState) function.Function {
+			impl := func(args []cty.Value) (cty.Value, error) {
+				state := state
+				if state == nil {
+					// This is an outermost call, so it begins a new chain
+					// of nested calls.
+					state = &callState{}
+				}
+				if depth > maxCallDepth {
+					state.err = fmt.Errorf("too many nested function calls while calling %q; the maximum depth is %d, which usually means that a function calls itself, directly or indirectly, without ever stopping", name, maxCallDepth)
+					return cty.DynamicVal, state.err
+				}
 
-			// The cty function machinery guarantees that we have at least
-			// enough args to fill all of our params.
-			for i, paramName := range params {
-				ctx.Variables[paramName] = args[i]
-			}
-			if spec.VarParam != nil {
-				varArgs := args[len(params):]
-				ctx.Variables[varParam] = cty.TupleVal(varArgs)
+				// The result expression can call any of the functions
+				// declared alongside this one, including itself.
+				ctx := getBaseCtx()
+				ctx = ctx.NewChild()
+				ctx.Functions = makeFuncs(depth+1, state)
+				ctx = ctx.NewChild()
+				ctx.Variables = make(map[string]cty.Value)
+
+				// The cty function machinery guarantees that we have at least
+				// enough args to fill all of our required params.
+				for i, paramName := range params {
+					if i < required {
+						ctx.Variables[paramName] = args[i]
+						continue
+					}
+					if i >= len(args) || args[i].IsNull() {
+						ctx.Variables[paramName] = defaults[paramName]
+						continue
+					}
+					arg, err := convert.Convert(args[i], paramType(paramName))
+					if err != nil {
+						return cty.DynamicVal, function.NewArgError(i, err)
+					}
+					ctx.Variables[paramName] = arg
+				}
+				if varParamExpr != nil {
+					varArgs := args[len(params):]
+					ctx.Variables[varParam] = cty.TupleVal(varArgs)
+				} else if len(args) > len(params) {
+					return cty.DynamicVal, fmt.Errorf("too many arguments; expected at most %d", len(params))
+				}
+
+				result, diags := resultExpr.Value(ctx)
+				if state.err != nil {
+					// Report the depth problem only once, rather than
+					// wrapping it in a diagnostic for every level of
+					// nested calls.
+					return cty.DynamicVal, state.err
+				}
+				if diags.HasErrors() {
+					// Smuggle the diagnostics out via the error channel, since
+					// a diagnostics sequence implements error. Caller can
+					// type-assert this to recover the individual diagnostics
+					// if desired.
+					return cty.DynamicVal, diags
+				}
+				return result, nil
 			}
 
-			result, diags := resultExpr.Value(ctx)
-			if diags.HasErrors() {
-				// Smuggle the diagnostics out via the error channel, since
-				// a diagnostics sequence implements error. Caller can
-				// type-assert this to recover the individual diagnostics
-				// if desired.
-				return cty.DynamicVal, diags
+			s := *spec
+			s.Type = func(args []cty.Value) (cty.Type, error) {
+				// If any argument is unknown then the cty function machinery
+				// won't call Impl, so we must evaluate the result here to
+				// find its type. Otherwise we leave the work to Impl, so
+				// that nested calls don't evaluate their results twice.
+				for _, arg := range args {
+					if !arg.IsKnown() {
+						val, err := impl(args)
+						return val.Type(), err
+					}
+				}
+				return cty.DynamicPseudoType, nil
+			}
+			s.Impl = func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+				return impl(args)
 			}
-			return result, nil
+			return function.New(&s)
+		}
+	}
+
+	for name, maker := range makers {
+		funcs[name] = maker(0, nil)
+	}
+	return funcs, remain, diags
+}
+
+// maxCallDepth is the maximum number of nested calls between user functions
+// that can be active at once, which protects against infinite recursion.
+const maxCallDepth = 100
+
+// callState is shared by all of the nested user function calls that result
+// from a single outermost call.
+type callState struct {
+	// err is set if the nested calls exceeded maxCallDepth.
+	err error
+}
+
+func duplicateParamDiag(name string, prevRng, rng hcl.Range) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Duplicate parameter name",
+		Detail:   fmt.Sprintf("A parameter named %q was already declared at %s. Each parameter must have a unique name.", name, prevRng),
+		Subject:  rng.Ptr(),
+	}
+}
+
+// decodeParamTypes interprets the given expression as a map from parameter
+// names to type constraints, populating the given types map.
+func decodeParamTypes(expr hcl.Expression, paramRanges map[string]hcl.Range, varParam string, types map[string]cty.Type) hcl.Diagnostics {
+	pairs, diags := hcl.ExprMap(expr)
+	if diags.HasErrors() {
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid param_types",
+			Detail:   "The param_types argument must be a map from parameter names to type constraints.",
+			Subject:  expr.Range().Ptr(),
+		}}
+	}
+
+	for _, pair := range pairs {
+		name := hcl.ExprAsKeyword(pair.Key)
+		if name == "" {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid param_types",
+				Detail:   "Each key in param_types must be a parameter name.",
+				Subject:  pair.Key.Range().Ptr(),
+			})
+			continue
 		}
-		spec.Type = func(args []cty.Value) (cty.Type, error) {
-			val, err := impl(args)
-			return val.Type(), err
+		if _, declared := paramRanges[name]; !declared && name != varParam {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unknown parameter",
+				Detail:   fmt.Sprintf("This function does not declare a parameter named %q.", name),
+				Subject:  pair.Key.Range().Ptr(),
+			})
+			continue
 		}
-		spec.Impl = func(args []cty.Value, retType cty.Type) (cty.Value, error) {
-			return impl(args)
+		ty, tyDiags := typeexpr.TypeConstraint(pair.Value)
+		diags = append(diags, tyDiags...)
+		if tyDiags.HasErrors() {
+			continue
 		}
-		funcs[name] = function.New(spec)
+		types[name] = ty
 	}
+	return diags
+}
 
-	return funcs, remain, diags
+// decodeParamDefaults interprets the given expression as a map from parameter
+// names to constant default values, which must be given for a contiguous
+// sequence of parameters at the end of the parameter list.
+func decodeParamDefaults(expr hcl.Expression, params []string, paramRanges map[string]hcl.Range, types map[string]cty.Type) (map[string]cty.Value, hcl.Diagnostics) {
+	pairs, diags := hcl.ExprMap(expr)
+	if diags.HasErrors() {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid param_defaults",
+			Detail:   "The param_defaults argument must be a map from parameter names to default values.",
+			Subject:  expr.Range().Ptr(),
+		}}
+	}
+
+	defaults := make(map[string]cty.Value)
+	for _, pair := range pairs {
+		name := hcl.ExprAsKeyword(pair.Key)
+		if name == "" {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid param_defaults",
+				Detail:   "Each key in param_defaults must be a parameter name.",
+				Subject:  pair.Key.Range().Ptr(),
+			})
+			continue
+		}
+		if _, declared := paramRanges[name]; !declared {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unknown parameter",
+				Detail:   fmt.Sprintf("This function does not declare a parameter named %q.", name),
+				Subject:  pair.Key.Range().Ptr(),
+			})
+			continue
+		}
+
+		val, valDiags := pair.Value.Value(nil)
+		diags = append(diags, valDiags...)
+		if valDiags.HasErrors() {
+			continue
+		}
+		if ty, ok := types[name]; ok {
+			var err error
+			val, err = convert.Convert(val, ty)
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid default value for parameter",
+					Detail:   fmt.Sprintf("The default value for parameter %q is not compatible with its type: %s.", name, err),
+					Subject:  pair.Value.Range().Ptr(),
+				})
+				continue
+			}
+		}
+		defaults[name] = val
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	// Once one parameter has a default value, all of the parameters after
+	// it must have one too, or callers couldn't omit the optional ones.
+	var firstOptional string
+	for _, name := range params {
+		if _, ok := defaults[name]; ok {
+			if firstOptional == "" {
+				firstOptional = name
+			}
+			continue
+		}
+		if firstOptional != "" {
+			rng := paramRanges[name]
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Missing default value for parameter",
+				Detail:   fmt.Sprintf("Parameter %q must have a default value because it follows the optional parameter %q. Only trailing parameters may be optional.", name, firstOptional),
+				Subject:  rng.Ptr(),
+			})
+		}
+	}
+	return defaults, diags
 }
@@ -7,6 +7,8 @@ import (
 	"github.com/hashicorp/hcl2/hcl"
 	"github.com/hashicorp/hcl2/hcl/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
 )
 
 func TestDecodeUserFunctions(t *testing.T) {
@@ -80,6 +82,408 @@ function "argstuple" {
 		},
 		{
 			`
+function "my_join" {
+  params = [sep]
+  variadic_param = strings
+  result = "${strings[0]}${sep}${strings[1]}"
+}
+`,
+			`my_join("-", "a", "b")`,
+			nil,
+			cty.StringVal("a-b"),
+			0,
+		},
+		{
+			`
+function "argstuple" {
+  params = []
+  variadic_param = args
+  result = args
+}
+`,
+			`argstuple()`,
+			nil,
+			cty.EmptyTupleVal,
+			0,
+		},
+		{
+			`
+function "dup" {
+  params = [a, a]
+  result = a
+}
+`,
+			`null`,
+			nil,
+			cty.NullVal(cty.DynamicPseudoType),
+			1, // duplicate parameter "a"
+		},
+		{
+			`
+function "dup" {
+  params = [a]
+  variadic_param = a
+  result = a
+}
+`,
+			`null`,
+			nil,
+			cty.NullVal(cty.DynamicPseudoType),
+			1, // variadic parameter conflicts with "a"
+		},
+		{
+			`
+function "add" {
+  params = [a, b]
+  param_types = {
+    a = number
+    b = number
+  }
+  result = a + b
+}
+`,
+			`add("1", 5)`,
+			nil,
+			cty.NumberIntVal(6),
+			0,
+		},
+		{
+			`
+function "add" {
+  params = [a, b]
+  param_types = {
+    a = number
+    b = number
+  }
+  result = a + b
+}
+`,
+			`add("one", 5)`,
+			nil,
+			cty.DynamicVal,
+			1, // "one" is not a number
+		},
+		{
+			`
+function "names" {
+  params = []
+  variadic_param = items
+  param_types = {
+    items = object({name=string})
+  }
+  result = items[*].name
+}
+`,
+			`names({name = "a", other = 1}, {name = "b"})`,
+			nil,
+			cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			0,
+		},
+		{
+			`
+function "typed" {
+  params = [a]
+  param_types = {
+    b = string
+  }
+  result = a
+}
+`,
+			`null`,
+			nil,
+			cty.NullVal(cty.DynamicPseudoType),
+			1, // no parameter named "b"
+		},
+		{
+			`
+function "typed" {
+  params = [a]
+  param_types = {
+    a = strin
+  }
+  result = a
+}
+`,
+			`null`,
+			nil,
+			cty.NullVal(cty.DynamicPseudoType),
+			1, // invalid type specification
+		},
+		{
+			`
+function "greet" {
+  params = [name, greeting, punct]
+  param_types = {
+    greeting = string
+  }
+  param_defaults = {
+    greeting = "Hello"
+    punct    = "."
+  }
+  result = "${greeting}, ${name}${punct}"
+}
+`,
+			`greet("Ermintrude")`,
+			nil,
+			cty.StringVal("Hello, Ermintrude."),
+			0,
+		},
+		{
+			`
+function "greet" {
+  params = [name, greeting, punct]
+  param_types = {
+    greeting = string
+  }
+  param_defaults = {
+    greeting = "Hello"
+    punct    = "."
+  }
+  result = "${greeting}, ${name}${punct}"
+}
+`,
+			`greet("Ermintrude", "Hi")`,
+			nil,
+			cty.StringVal("Hi, Ermintrude."),
+			0,
+		},
+		{
+			`
+function "greet" {
+  params = [name, greeting, punct]
+  param_types = {
+    greeting = string
+  }
+  param_defaults = {
+    greeting = "Hello"
+    punct    = "."
+  }
+  result = "${greeting}, ${name}${punct}"
+}
+`,
+			`greet("Ermintrude", null, "!")`,
+			nil,
+			cty.StringVal("Hello, Ermintrude!"),
+			0,
+		},
+		{
+			`
+function "greet" {
+  params = [name, greeting, punct]
+  param_types = {
+    greeting = string
+  }
+  param_defaults = {
+    greeting = "Hello"
+    punct    = "."
+  }
+  result = "${greeting}, ${name}${punct}"
+}
+`,
+			`greet("Ermintrude", true)`,
+			nil,
+			cty.StringVal("true, Ermintrude."),
+			0,
+		},
+		{
+			`
+function "greet" {
+  params = [name, greeting, punct]
+  param_types = {
+    greeting = string
+  }
+  param_defaults = {
+    greeting = "Hello"
+    punct    = "."
+  }
+  result = "${greeting}, ${name}${punct}"
+}
+`,
+			`greet("Ermintrude", [], "!")`,
+			nil,
+			cty.DynamicVal,
+			1, // greeting must be a string
+		},
+		{
+			`
+function "greet" {
+  params = [name, greeting, punct]
+  param_types = {
+    greeting = string
+  }
+  param_defaults = {
+    greeting = "Hello"
+    punct    = "."
+  }
+  result = "${greeting}, ${name}${punct}"
+}
+`,
+			`greet()`,
+			nil,
+			cty.DynamicVal,
+			1, // missing value for "name"
+		},
+		{
+			`
+function "greet" {
+  params = [name, greeting, punct]
+  param_types = {
+    greeting = string
+  }
+  param_defaults = {
+    greeting = "Hello"
+    punct    = "."
+  }
+  result = "${greeting}, ${name}${punct}"
+}
+`,
+			`greet("a", "b", "c", "d")`,
+			nil,
+			cty.DynamicVal,
+			1, // too many arguments
+		},
+		{
+			`
+function "f" {
+  params = [a, b]
+  param_defaults = {
+    a = 1
+  }
+  result = a
+}
+`,
+			`null`,
+			nil,
+			cty.NullVal(cty.DynamicPseudoType),
+			1, // "b" must also have a default
+		},
+		{
+			`
+function "f" {
+  params = [a]
+  variadic_param = rest
+  param_defaults = {
+    a = 1
+  }
+  result = a
+}
+`,
+			`null`,
+			nil,
+			cty.NullVal(cty.DynamicPseudoType),
+			1, // defaults cannot be combined with variadic_param
+		},
+		{
+			`
+function "f" {
+  params = [a]
+  param_types = {
+    a = number
+  }
+  param_defaults = {
+    a = "nope"
+  }
+  result = a
+}
+`,
+			`null`,
+			nil,
+			cty.NullVal(cty.DynamicPseudoType),
+			1, // default value is not a number
+		},
+		{
+			`
+function "f" {
+  params = [a]
+  param_defaults = {
+    a = var.x
+  }
+  result = a
+}
+`,
+			`null`,
+			nil,
+			cty.NullVal(cty.DynamicPseudoType),
+			1, // default value must be constant
+		},
+		{
+			`
+function "quadruple" {
+  params = [n]
+  result = double(double(n))
+}
+function "double" {
+  params = [n]
+  result = n * 2
+}
+`,
+			`quadruple(3)`,
+			nil,
+			cty.NumberIntVal(12),
+			0,
+		},
+		{
+			`
+function "double" {
+  params = [n]
+  result = n * 2
+}
+`,
+			`double(1)`,
+			&hcl.EvalContext{
+				Functions: map[string]function.Function{
+					"double": stdlib.UpperFunc,
+				},
+			},
+			cty.NumberIntVal(2),
+			0,
+		},
+		{
+			`
+function "shout" {
+  params = [s]
+  result = "${upper(s)}!"
+}
+`,
+			`shout("hi")`,
+			&hcl.EvalContext{
+				Functions: map[string]function.Function{
+					"upper": stdlib.UpperFunc,
+				},
+			},
+			cty.StringVal("HI!"),
+			0,
+		},
+		{
+			`
+function "forever" {
+  params = [n]
+  result = n > 0 ? forever(n - 1) : 0
+}
+`,
+			`forever(3)`,
+			nil,
+			cty.DynamicVal,
+			1, // too many nested function calls
+		},
+		{
+			`
+function "ping" {
+  params = []
+  result = pong()
+}
+function "pong" {
+  params = []
+  result = ping()
+}
+`,
+			`ping()`,
+			nil,
+			cty.DynamicVal,
+			1, // too many nested function calls
+		},
+		{
+			`
 function "missing_var" {
   params = []
   result = nonexist
@@ -172,3 +576,85 @@ function "neg" {
 		})
 	}
 }
+
+func TestDecodeUserFunctionsParamTypeDiagnostic(t *testing.T) {
+	src := `
+function "double" {
+  params = [n]
+  param_types = {
+    n = number
+  }
+  result = n * 2
+}
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "config", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	funcs, _, diags := DecodeUserFunctions(f.Body, "function", nil)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(`double("two")`), "testexpr", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	_, diags = expr.Value(&hcl.EvalContext{
+		Functions: funcs,
+	})
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags)
+	}
+
+	// The diagnostic should point at the caller's argument, not at the
+	// function's result expression.
+	got := diags[0].Subject
+	want := &hcl.Range{
+		Filename: "testexpr",
+		Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+		End:      hcl.Pos{Line: 1, Column: 13, Byte: 12},
+	}
+	if got == nil || *got != *want {
+		t.Errorf("wrong subject\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestDecodeUserFunctionsCallDepth(t *testing.T) {
+	src := `
+function "forever" {
+  params = []
+  result = forever()
+}
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "config", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	funcs, _, diags := DecodeUserFunctions(f.Body, "function", nil)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(`forever()`), "testexpr", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	ctx := &hcl.EvalContext{
+		Functions: funcs,
+	}
+
+	// Each call is independent, so repeating it must produce the same
+	// result rather than inheriting depth from the previous call.
+	for i := 0; i < 2; i++ {
+		_, diags = expr.Value(ctx)
+		if len(diags) != 1 {
+			t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags)
+		}
+		got := diags[0].Detail
+		want := `Call to function "forever" failed: too many nested function calls while calling "forever"; the maximum depth is 100, which usually means that a function calls itself, directly or indirectly, without ever stopping.`
+		if got != want {
+			t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, want)
+		}
+	}
+}
@@ -16,6 +16,31 @@
 // attribute is evaluated in an isolated evaluation context that defines variables
 // named after the given parameter names.
 //
+// A function may also accept any number of additional arguments after its
+// fixed parameters by declaring a variadic parameter, which is then available
+// to the result expression as a tuple of the additional argument values:
+//
+//     function "my_join" {
+//       params         = [sep]
+//       variadic_param = strings
+//       result         = join(sep, strings)
+//     }
+//
+// Parameters may also be given type constraints via the optional "param_types"
+// attribute, which maps parameter names to type expressions as defined by
+// the typeexpr extension. Arguments are converted to these types before the
+// result expression is evaluated:
+//
+//     function "add" {
+//       params      = [a, b]
+//       param_types = { a = number, b = number }
+//       result      = a + b
+//     }
+//
+// Trailing parameters can be made optional by giving them constant default
+// values via the "param_defaults" attribute, which maps parameter names to
+// the value to use when the caller omits the argument or passes null.
+//
 // The block name "function" may be overridden by the calling application, if
 // that default name conflicts with an existing block or attribute name in
 // the application.
@@ -35,6 +35,12 @@ type ContextFunc func() *hcl.EvalContext
 // variables named after the declared parameters. A non-nil context turns
 // the returned functions into closures, bound to the given context.
 //
+// In either case, the result expressions may also call any of the functions
+// declared in the same body, regardless of the order of their declarations.
+// These shadow any functions of the same name in the given context. A
+// function that calls itself, directly or indirectly, will fail with an
+// error once too many calls are nested.
+//
 // If the returned diagnostics set has errors then the function map and
 // remain body may be nil or incomplete.
 func DecodeUserFunctions(body hcl.Body, blockType string, context ContextFunc) (funcs map[string]function.Function, remain hcl.Body, diags hcl.Diagnostics) {
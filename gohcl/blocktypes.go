@@ -0,0 +1,269 @@
+package gohcl
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+var blockTypesMu sync.RWMutex
+var blockTypes = map[reflect.Type]map[string]reflect.Type{}
+
+// RegisterBlockType registers a concrete type to decode into when a block
+// is decoded into a field of an interface type.
+//
+// The iface argument must be a nil pointer to the interface type, such as
+// (*Backend)(nil), and impl must be a value of the concrete type to use,
+// which must be either a struct or a pointer to a struct and must implement
+// the interface. Each decoded block produces a new value of the same type
+// as impl; impl itself is used only for its type.
+//
+// Interface-typed fields, or slices of them, may be tagged as "block" in one
+// of two ways. If the field has a name, as in `hcl:"backend,block"`, then
+// blocks of that type are expected to have at least one label and the first
+// label is the key used to select the concrete type. All of the concrete
+// types registered for the interface must then have the same number of
+// label fields, with the first receiving the key itself. If the name is
+// empty, as in `hcl:",block"`, then each key is itself accepted as a block
+// type and the block type name selects the concrete type.
+//
+// Blocks that select a key that has not been registered are reported as
+// error diagnostics. Registering a type more than once for the same key, or
+// decoding into an interface with no registered types, is a bug in the
+// calling program and causes a panic.
+//
+// RegisterBlockType is intended to be called during program initialization,
+// but it is safe to call concurrently with decoding.
+func RegisterBlockType(iface interface{}, key string, impl interface{}) {
+	ifaceTy := reflect.TypeOf(iface)
+	if ifaceTy == nil || ifaceTy.Kind() != reflect.Ptr || ifaceTy.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("RegisterBlockType requires a pointer to an interface type, not %T", iface))
+	}
+	ifaceTy = ifaceTy.Elem()
+
+	implTy := reflect.TypeOf(impl)
+	if implTy == nil {
+		panic("RegisterBlockType requires a non-nil implementation value")
+	}
+	structTy := implTy
+	if structTy.Kind() == reflect.Ptr {
+		structTy = structTy.Elem()
+	}
+	if structTy.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("cannot register %s as a block type: struct or pointer to struct required", implTy.String()))
+	}
+	if !implTy.Implements(ifaceTy) {
+		panic(fmt.Sprintf("cannot register %s as a block type: does not implement %s", implTy.String(), ifaceTy.String()))
+	}
+
+	blockTypesMu.Lock()
+	defer blockTypesMu.Unlock()
+	impls := blockTypes[ifaceTy]
+	if impls == nil {
+		impls = map[string]reflect.Type{}
+		blockTypes[ifaceTy] = impls
+	}
+	if existing, exists := impls[key]; exists {
+		panic(fmt.Sprintf("block type %q for %s is already registered as %s", key, ifaceTy.String(), existing.String()))
+	}
+	impls[key] = implTy
+}
+
+// registeredBlockTypes returns the types registered for the given interface
+// type along with their keys in lexical order, panicking if there are none.
+func registeredBlockTypes(ifaceTy reflect.Type) (map[string]reflect.Type, []string) {
+	blockTypesMu.RLock()
+	defer blockTypesMu.RUnlock()
+
+	impls := blockTypes[ifaceTy]
+	if len(impls) == 0 {
+		panic(fmt.Sprintf("no block types are registered for %s", ifaceTy.String()))
+	}
+
+	ret := make(map[string]reflect.Type, len(impls))
+	keys := make([]string, 0, len(impls))
+	for k, ty := range impls {
+		ret[k] = ty
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return ret, keys
+}
+
+// blockInterfaceType returns the interface type of the given block field
+// type, if it is an interface or a slice of interfaces other than hcl.Body.
+func blockInterfaceType(ty reflect.Type) (reflect.Type, bool) {
+	if ty.Kind() == reflect.Slice {
+		ty = ty.Elem()
+	}
+	if ty.Kind() != reflect.Interface || ty == bodyType {
+		return nil, false
+	}
+	return ty, true
+}
+
+// blockTypeLabelNames returns the names of the label fields of the given
+// registered block type.
+func blockTypeLabelNames(implTy reflect.Type) []string {
+	if implTy.Kind() == reflect.Ptr {
+		implTy = implTy.Elem()
+	}
+	tags := getFieldTags(implTy)
+	if len(tags.Labels) == 0 {
+		return nil
+	}
+	ret := make([]string, len(tags.Labels))
+	for i, lf := range tags.Labels {
+		ret[i] = lf.Name
+	}
+	return ret
+}
+
+// interfaceBlockSchemas returns the block header schemas for an interface
+// field tagged with the given block type name, which may be empty to accept
+// each registered key as a block type.
+func interfaceBlockSchemas(typeName string, field reflect.StructField, ifaceTy reflect.Type) []hcl.BlockHeaderSchema {
+	impls, keys := registeredBlockTypes(ifaceTy)
+
+	if typeName == "" {
+		ret := make([]hcl.BlockHeaderSchema, len(keys))
+		for i, k := range keys {
+			ret[i] = hcl.BlockHeaderSchema{
+				Type:       k,
+				LabelNames: blockTypeLabelNames(impls[k]),
+			}
+		}
+		return ret
+	}
+
+	var labelNames []string
+	for i, k := range keys {
+		names := blockTypeLabelNames(impls[k])
+		if len(names) == 0 {
+			panic(fmt.Sprintf(
+				"hcl 'block' tag kind cannot be applied to %s field %s: registered type %s must have at least one label field", field.Type.String(), field.Name, impls[k].String(),
+			))
+		}
+		if i == 0 {
+			labelNames = names
+			continue
+		}
+		if len(names) != len(labelNames) {
+			panic(fmt.Sprintf(
+				"hcl 'block' tag kind cannot be applied to %s field %s: all registered types must have the same number of label fields", field.Type.String(), field.Name,
+			))
+		}
+	}
+	return []hcl.BlockHeaderSchema{
+		{
+			Type:       typeName,
+			LabelNames: labelNames,
+		},
+	}
+}
+
+// decodeBlocksToInterface decodes the given blocks into the given value,
+// which must be of an interface type or a slice of an interface type,
+// selecting a registered concrete type for each block.
+func decodeBlocksToInterface(blocks hcl.Blocks, typeName string, ctx *hcl.EvalContext, v reflect.Value) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	ifaceTy, _ := blockInterfaceType(v.Type())
+	impls, keys := registeredBlockTypes(ifaceTy)
+	isSlice := v.Kind() == reflect.Slice
+
+	if len(blocks) == 0 {
+		v.Set(reflect.Zero(v.Type()))
+		return diags
+	}
+
+	if len(blocks) > 1 && !isSlice {
+		name := typeName
+		if name == "" {
+			name = blocks[1].Type
+		}
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Duplicate %s block", name),
+			Detail: fmt.Sprintf(
+				"Only one %s block is allowed. Another was defined at %s.",
+				name, blocks[0].DefRange.String(),
+			),
+			Subject: &blocks[1].DefRange,
+		})
+		return diags
+	}
+
+	var sli reflect.Value
+	if isSlice {
+		sli = reflect.MakeSlice(v.Type(), 0, len(blocks))
+	}
+
+	for _, block := range blocks {
+		key := block.Type
+		rng := block.DefRange
+		if typeName != "" {
+			// The schema guarantees at least one label in this case.
+			key = block.Labels[0]
+			rng = block.LabelRanges[0]
+		}
+
+		implTy, ok := impls[key]
+		if !ok {
+			quoted := make([]string, len(keys))
+			for i, k := range keys {
+				quoted[i] = fmt.Sprintf("%q", k)
+			}
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Unsupported %s type", typeName),
+				Detail: fmt.Sprintf(
+					"There is no %s type named %q. Supported types are %s.",
+					typeName, key, strings.Join(quoted, ", "),
+				),
+				Subject: rng.Ptr(),
+			})
+			continue
+		}
+
+		var elem reflect.Value
+		if implTy.Kind() == reflect.Ptr {
+			elem = reflect.New(implTy.Elem())
+			diags = append(diags, decodeBlockToValue(block, ctx, elem.Elem())...)
+		} else {
+			ptr := reflect.New(implTy)
+			diags = append(diags, decodeBlockToValue(block, ctx, ptr.Elem())...)
+			elem = ptr.Elem()
+		}
+
+		if isSlice {
+			sli = reflect.Append(sli, elem)
+		} else {
+			v.Set(elem)
+		}
+	}
+
+	if isSlice {
+		v.Set(sli)
+	}
+
+	return diags
+}
+
+// registeredBlockTypeKey returns the key that the given concrete type is
+// registered under for the given interface type.
+func registeredBlockTypeKey(ifaceTy, implTy reflect.Type) (string, bool) {
+	blockTypesMu.RLock()
+	defer blockTypesMu.RUnlock()
+
+	for k, ty := range blockTypes[ifaceTy] {
+		if ty == implTy {
+			return k, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,274 @@
+package gohcl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hclwrite"
+)
+
+type testBackend interface {
+	backendName() string
+}
+
+type testLocalBackend struct {
+	Type string `hcl:"type,label"`
+	Path string `hcl:"path"`
+}
+
+func (b *testLocalBackend) backendName() string { return "local" }
+
+type testRemoteBackend struct {
+	Type string `hcl:"type,label"`
+	URL  string `hcl:"url"`
+}
+
+func (b testRemoteBackend) backendName() string { return "remote" }
+
+type testNotifier interface {
+	notify()
+}
+
+type testSlackNotifier struct {
+	Channel string `hcl:"channel"`
+}
+
+func (n *testSlackNotifier) notify() {}
+
+type testEmailNotifier struct {
+	Address string `hcl:"address,label"`
+}
+
+func (n *testEmailNotifier) notify() {}
+
+func init() {
+	RegisterBlockType((*testBackend)(nil), "local", (*testLocalBackend)(nil))
+	RegisterBlockType((*testBackend)(nil), "remote", testRemoteBackend{})
+	RegisterBlockType((*testNotifier)(nil), "slack", (*testSlackNotifier)(nil))
+	RegisterBlockType((*testNotifier)(nil), "email", (*testEmailNotifier)(nil))
+}
+
+func TestDecodeBodyBlockTypes(t *testing.T) {
+	tests := map[string]struct {
+		Config    string
+		Target    interface{}
+		Want      interface{}
+		DiagCount int
+	}{
+		"selected by label": {
+			`
+backend "local" {
+  path = "/tmp"
+}
+`,
+			struct {
+				Backend testBackend `hcl:"backend,block"`
+			}{},
+			struct {
+				Backend testBackend `hcl:"backend,block"`
+			}{
+				Backend: &testLocalBackend{Type: "local", Path: "/tmp"},
+			},
+			0,
+		},
+		"non-pointer type": {
+			`
+backend "remote" {
+  url = "https://example.com/"
+}
+`,
+			struct {
+				Backend testBackend `hcl:"backend,block"`
+			}{},
+			struct {
+				Backend testBackend `hcl:"backend,block"`
+			}{
+				Backend: testRemoteBackend{Type: "remote", URL: "https://example.com/"},
+			},
+			0,
+		},
+		"slice": {
+			`
+backend "remote" {
+  url = "https://example.com/"
+}
+backend "local" {
+  path = "/tmp"
+}
+`,
+			struct {
+				Backends []testBackend `hcl:"backend,block"`
+			}{},
+			struct {
+				Backends []testBackend `hcl:"backend,block"`
+			}{
+				Backends: []testBackend{
+					testRemoteBackend{Type: "remote", URL: "https://example.com/"},
+					&testLocalBackend{Type: "local", Path: "/tmp"},
+				},
+			},
+			0,
+		},
+		"absent": {
+			``,
+			struct {
+				Backend testBackend `hcl:"backend,block"`
+			}{},
+			struct {
+				Backend testBackend `hcl:"backend,block"`
+			}{},
+			0,
+		},
+		"unsupported": {
+			`
+backend "ftp" {
+}
+`,
+			struct {
+				Backend testBackend `hcl:"backend,block"`
+			}{},
+			struct {
+				Backend testBackend `hcl:"backend,block"`
+			}{},
+			1, // Unsupported backend type
+		},
+		"duplicate": {
+			`
+backend "local" {
+  path = "/tmp"
+}
+backend "local" {
+  path = "/var"
+}
+`,
+			struct {
+				Backend testBackend `hcl:"backend,block"`
+			}{},
+			struct {
+				Backend testBackend `hcl:"backend,block"`
+			}{},
+			1, // Duplicate backend block
+		},
+		"selected by block type": {
+			`
+slack {
+  channel = "#alerts"
+}
+email "ops@example.com" {
+}
+slack {
+  channel = "#ops"
+}
+`,
+			struct {
+				Notifiers []testNotifier `hcl:",block"`
+			}{},
+			struct {
+				Notifiers []testNotifier `hcl:",block"`
+			}{
+				Notifiers: []testNotifier{
+					&testSlackNotifier{Channel: "#alerts"},
+					&testEmailNotifier{Address: "ops@example.com"},
+					&testSlackNotifier{Channel: "#ops"},
+				},
+			},
+			0,
+		},
+		"unknown block type": {
+			`
+pager {
+}
+`,
+			struct {
+				Notifiers []testNotifier `hcl:",block"`
+			}{},
+			struct {
+				Notifiers []testNotifier `hcl:",block"`
+			}{},
+			1, // Unsupported block type
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.Config), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			if len(diags) != 0 {
+				t.Fatalf("diagnostics while parsing: %s", diags.Error())
+			}
+
+			targetVal := reflect.New(reflect.TypeOf(test.Target))
+
+			diags = DecodeBody(file.Body, nil, targetVal.Interface())
+			if len(diags) != test.DiagCount {
+				t.Errorf("wrong number of diagnostics %d; want %d", len(diags), test.DiagCount)
+				for _, diag := range diags {
+					t.Logf(" - %s", diag.Error())
+				}
+			}
+			got := targetVal.Elem().Interface()
+			if !reflect.DeepEqual(got, test.Want) {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", spew.Sdump(got), spew.Sdump(test.Want))
+			}
+		})
+	}
+}
+
+func TestImpliedBodySchemaBlockTypes(t *testing.T) {
+	schema, _ := ImpliedBodySchema(struct {
+		Backend   testBackend    `hcl:"backend,block"`
+		Notifiers []testNotifier `hcl:",block"`
+	}{})
+	want := &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{
+				Type:       "email",
+				LabelNames: []string{"address"},
+			},
+			{
+				Type: "slack",
+			},
+			{
+				Type:       "backend",
+				LabelNames: []string{"type"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(schema, want) {
+		t.Errorf("wrong schema\ngot:  %s\nwant: %s", spew.Sdump(schema), spew.Sdump(want))
+	}
+}
+
+func TestEncodeIntoBodyBlockTypes(t *testing.T) {
+	val := struct {
+		Backend   testBackend    `hcl:"backend,block"`
+		Notifiers []testNotifier `hcl:",block"`
+	}{
+		Backend: &testLocalBackend{Type: "local", Path: "/tmp"},
+		Notifiers: []testNotifier{
+			&testSlackNotifier{Channel: "#alerts"},
+			nil,
+			&testEmailNotifier{Address: "ops@example.com"},
+		},
+	}
+
+	f := hclwrite.NewEmptyFile()
+	EncodeIntoBody(&val, f.Body())
+	got := string(f.Bytes())
+	want := `
+backend "local" {
+  path = "/tmp"
+}
+
+slack {
+  channel = "#alerts"
+}
+email "ops@example.com" {
+}
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
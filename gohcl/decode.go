@@ -7,6 +7,7 @@ import (
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
 	"github.com/zclconf/go-cty/cty/convert"
 	"github.com/zclconf/go-cty/cty/gocty"
 )
@@ -22,6 +23,10 @@ import (
 // constant values, for simple applications that do not support variables or
 // functions.
 //
+// If the given value implements Unmarshaler then decoding is delegated
+// entirely to its UnmarshalHCL method. The same is true for any nested
+// values that implement Unmarshaler, as described in its documentation.
+//
 // The returned diagnostics should be inspected with its HasErrors method to
 // determine if the populated value is valid and complete. If error diagnostics
 // are returned then the given value may have been partially-populated but
@@ -37,6 +42,10 @@ func DecodeBody(body hcl.Body, ctx *hcl.EvalContext, val interface{}) hcl.Diagno
 }
 
 func decodeBodyToValue(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value) hcl.Diagnostics {
+	if u, ok := asUnmarshaler(val); ok {
+		return u.UnmarshalHCL(body, ctx)
+	}
+
 	et := val.Type()
 	switch et.Kind() {
 	case reflect.Struct:
@@ -66,9 +75,9 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 	tags := getFieldTags(val.Type())
 
 	if tags.Remain != nil {
-		fieldIdx := *tags.Remain
-		field := val.Type().Field(fieldIdx)
-		fieldV := val.Field(fieldIdx)
+		fieldIdx := tags.Remain
+		field := val.Type().FieldByIndex(fieldIdx)
+		fieldV := val.FieldByIndex(fieldIdx)
 		switch {
 		case bodyType.AssignableTo(field.Type):
 			fieldV.Set(reflect.ValueOf(leftovers))
@@ -83,12 +92,32 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 		}
 	}
 
+	if tags.RemainAttrs != nil {
+		attrs, attrsDiags := leftovers.JustAttributes()
+		diags = append(diags, attrsDiags...)
+		if attrs != nil {
+			diags = append(diags, decodeAttrsToMap(attrs, ctx, val.FieldByIndex(tags.RemainAttrs))...)
+		}
+	}
+
 	for name, fieldIdx := range tags.Attributes {
 		attr := content.Attributes[name]
-		field := val.Type().Field(fieldIdx)
-		fieldV := val.Field(fieldIdx)
+		field := val.Type().FieldByIndex(fieldIdx)
+		fieldV := val.FieldByIndex(fieldIdx)
 
 		if attr == nil {
+			if defSrc, hasDefault := tags.Defaults[name]; hasDefault {
+				defExpr := parseDefaultExpr(defSrc, field)
+				if exprType.AssignableTo(field.Type) {
+					fieldV.Set(reflect.ValueOf(defExpr))
+				} else {
+					diags = append(diags, DecodeExpression(
+						defExpr, ctx, exprTarget(fieldV),
+					)...)
+				}
+				continue
+			}
+
 			if !exprType.AssignableTo(field.Type) {
 				continue
 			}
@@ -108,9 +137,11 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 		case exprType.AssignableTo(field.Type):
 			fieldV.Set(reflect.ValueOf(attr.Expr))
 		default:
-			diags = append(diags, DecodeExpression(
-				attr.Expr, ctx, fieldV.Addr().Interface(),
-			)...)
+			valDiags := DecodeExpression(attr.Expr, ctx, exprTarget(fieldV))
+			diags = append(diags, valDiags...)
+			if !valDiags.HasErrors() {
+				diags = append(diags, validateAttribute(attr, tags.Validate[name], fieldV)...)
+			}
 		}
 	}
 
@@ -118,7 +149,28 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 
 	for typeName, fieldIdx := range tags.Blocks {
 		blocks := blocksByType[typeName]
-		field := val.Type().Field(fieldIdx)
+		field := val.Type().FieldByIndex(fieldIdx)
+
+		if ifaceTy, ok := blockInterfaceType(field.Type); ok {
+			if typeName == "" {
+				// Each registered key is a separate block type, so we
+				// gather the blocks of all of them in source order.
+				impls, _ := registeredBlockTypes(ifaceTy)
+				blocks = nil
+				for _, block := range content.Blocks {
+					if _, ok := impls[block.Type]; ok {
+						blocks = append(blocks, block)
+					}
+				}
+			}
+			diags = append(diags, decodeBlocksToInterface(blocks, typeName, ctx, val.FieldByIndex(fieldIdx))...)
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Map {
+			diags = append(diags, decodeBlocksToMap(blocks, typeName, ctx, val.FieldByIndex(fieldIdx))...)
+			continue
+		}
 
 		ty := field.Type
 		isSlice := false
@@ -147,7 +199,7 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 
 		if len(blocks) == 0 {
 			if isSlice || isPtr {
-				val.Field(fieldIdx).Set(reflect.Zero(field.Type))
+				val.FieldByIndex(fieldIdx).Set(reflect.Zero(field.Type))
 			} else {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
@@ -178,31 +230,79 @@ func decodeBodyToStruct(body hcl.Body, ctx *hcl.EvalContext, val reflect.Value)
 				}
 			}
 
-			val.Field(fieldIdx).Set(sli)
+			val.FieldByIndex(fieldIdx).Set(sli)
 
 		default:
 			block := blocks[0]
 			if isPtr {
 				v := reflect.New(ty)
 				diags = append(diags, decodeBlockToValue(block, ctx, v.Elem())...)
-				val.Field(fieldIdx).Set(v)
+				val.FieldByIndex(fieldIdx).Set(v)
 			} else {
-				diags = append(diags, decodeBlockToValue(block, ctx, val.Field(fieldIdx))...)
+				diags = append(diags, decodeBlockToValue(block, ctx, val.FieldByIndex(fieldIdx))...)
 			}
 
 		}
 
 	}
 
+	for name, fieldIdx := range tags.Ranges {
+		fieldV := val.FieldByIndex(fieldIdx)
+
+		if _, isAttr := tags.Attributes[name]; isAttr {
+			if attr := content.Attributes[name]; attr != nil {
+				fieldV.Set(reflect.ValueOf(attr.Range))
+			}
+			continue
+		}
+
+		if _, isBlock := tags.Blocks[name]; isBlock {
+			blocks := blocksByType[name]
+			if fieldV.Type() == rangesType {
+				rngs := make([]hcl.Range, len(blocks))
+				for i, block := range blocks {
+					rngs[i] = block.DefRange
+				}
+				fieldV.Set(reflect.ValueOf(rngs))
+			} else if len(blocks) > 0 {
+				fieldV.Set(reflect.ValueOf(blocks[0].DefRange))
+			}
+		}
+
+		// Ranges for labels are populated by decodeBlockToValue.
+	}
+
 	return diags
 }
 
+// parseDefaultExpr parses the given source code, taken from a "default"
+// struct tag on the given field, as a native syntax expression.
+//
+// An invalid default expression is a bug in the calling program, so this
+// function panics if the given source is not valid.
+func parseDefaultExpr(src string, field reflect.StructField) hcl.Expression {
+	filename := fmt.Sprintf("<default value for %s>", field.Name)
+	expr, diags := hclsyntax.ParseExpression([]byte(src), filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		panic(fmt.Sprintf("invalid default value for %s field %s: %s", field.Type.String(), field.Name, diags.Error()))
+	}
+	return expr
+}
+
 func decodeBodyToMap(body hcl.Body, ctx *hcl.EvalContext, v reflect.Value) hcl.Diagnostics {
 	attrs, diags := body.JustAttributes()
 	if attrs == nil {
 		return diags
 	}
 
+	return append(diags, decodeAttrsToMap(attrs, ctx, v)...)
+}
+
+// decodeAttrsToMap decodes the given attributes into the given map value,
+// whose element type may be *hcl.Attribute, hcl.Expression, or any type
+// that DecodeExpression can decode into.
+func decodeAttrsToMap(attrs hcl.Attributes, ctx *hcl.EvalContext, v reflect.Value) hcl.Diagnostics {
+	var diags hcl.Diagnostics
 	mv := reflect.MakeMap(v.Type())
 
 	for k, attr := range attrs {
@@ -212,9 +312,9 @@ func decodeBodyToMap(body hcl.Body, ctx *hcl.EvalContext, v reflect.Value) hcl.D
 		case exprType.AssignableTo(v.Type().Elem()):
 			mv.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(attr.Expr))
 		default:
-			ev := reflect.New(v.Type().Elem())
-			diags = append(diags, DecodeExpression(attr.Expr, ctx, ev.Interface())...)
-			mv.SetMapIndex(reflect.ValueOf(k), ev.Elem())
+			ev := reflect.New(v.Type().Elem()).Elem()
+			diags = append(diags, DecodeExpression(attr.Expr, ctx, exprTarget(ev))...)
+			mv.SetMapIndex(reflect.ValueOf(k), ev)
 		}
 	}
 
@@ -223,8 +323,75 @@ func decodeBodyToMap(body hcl.Body, ctx *hcl.EvalContext, v reflect.Value) hcl.D
 	return diags
 }
 
+// decodeBlocksToMap decodes the given blocks into the given map value, which
+// must be of type map[string]T or map[string]map[string]T, using the first
+// one or two block labels respectively as the map keys.
+func decodeBlocksToMap(blocks hcl.Blocks, typeName string, ctx *hcl.EvalContext, v reflect.Value) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	if len(blocks) == 0 {
+		v.Set(reflect.Zero(v.Type()))
+		return diags
+	}
+
+	depth, ty := blockMapDepth(v.Type())
+	isPtr := false
+	if ty.Kind() == reflect.Ptr {
+		isPtr = true
+		ty = ty.Elem()
+	}
+
+	m := reflect.MakeMap(v.Type())
+	seen := make(map[[2]string]*hcl.Block, len(blocks))
+	for _, block := range blocks {
+		if len(block.Labels) < depth {
+			// Content will already have produced an error diagnostic
+			// for the missing labels, so we'll just skip this one.
+			continue
+		}
+
+		var key [2]string
+		copy(key[:], block.Labels[:depth])
+		if prev, exists := seen[key]; exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Duplicate %s block", typeName),
+				Detail: fmt.Sprintf(
+					"A %s block with the same labels was already defined at %s. Each %s block must have a unique combination of labels.",
+					typeName, prev.DefRange.String(), typeName,
+				),
+				Subject: &block.DefRange,
+			})
+			continue
+		}
+		seen[key] = block
+
+		ev := reflect.New(ty)
+		diags = append(diags, decodeBlockToValue(block, ctx, ev.Elem())...)
+		if !isPtr {
+			ev = ev.Elem()
+		}
+
+		target := m
+		for _, k := range block.Labels[:depth-1] {
+			kv := reflect.ValueOf(k)
+			inner := target.MapIndex(kv)
+			if !inner.IsValid() {
+				inner = reflect.MakeMap(target.Type().Elem())
+				target.SetMapIndex(kv, inner)
+			}
+			target = inner
+		}
+		target.SetMapIndex(reflect.ValueOf(block.Labels[depth-1]), ev)
+	}
+
+	v.Set(m)
+	return diags
+}
+
 func decodeBlockToValue(block *hcl.Block, ctx *hcl.EvalContext, v reflect.Value) hcl.Diagnostics {
 	var diags hcl.Diagnostics
+	finish := ctx.StartDecodeBlock(block)
 
 	ty := v.Type()
 
@@ -242,16 +409,25 @@ func decodeBlockToValue(block *hcl.Block, ctx *hcl.EvalContext, v reflect.Value)
 	default:
 		diags = append(diags, decodeBodyToValue(block.Body, ctx, v)...)
 
-		if len(block.Labels) > 0 {
+		if len(block.Labels) > 0 && ty.Kind() == reflect.Struct {
 			blockTags := getFieldTags(ty)
 			for li, lv := range block.Labels {
+				if li >= len(blockTags.Labels) {
+					// Can happen when decoding into a map, where the map
+					// keys consume labels that have no corresponding field.
+					break
+				}
 				lfieldIdx := blockTags.Labels[li].FieldIndex
-				v.Field(lfieldIdx).Set(reflect.ValueOf(lv))
+				v.FieldByIndex(lfieldIdx).Set(reflect.ValueOf(lv))
+				if rfieldIdx, ok := blockTags.Ranges[blockTags.Labels[li].Name]; ok && li < len(block.LabelRanges) {
+					v.FieldByIndex(rfieldIdx).Set(reflect.ValueOf(block.LabelRanges[li]))
+				}
 			}
 		}
 
 	}
 
+	finish(diags)
 	return diags
 }
 
@@ -264,13 +440,29 @@ func decodeBlockToValue(block *hcl.Block, ctx *hcl.EvalContext, v reflect.Value)
 // constant values, for simple applications that do not support variables or
 // functions.
 //
+// If the given value implements ExpressionUnmarshaler then decoding is
+// delegated entirely to its UnmarshalHCLExpression method.
+//
+// Values of type time.Duration, time.Time, net.IP, net.IPNet and url.URL, or
+// pointers to them, are decoded from strings in the conventional format for
+// each type, such as "30s" for a duration or RFC 3339 for a timestamp.
+//
 // The returned diagnostics should be inspected with its HasErrors method to
 // determine if the populated value is valid and complete. If error diagnostics
 // are returned then the given value may have been partially-populated but
 // may still be accessed by a careful caller for static analysis and editor
 // integration use-cases.
 func DecodeExpression(expr hcl.Expression, ctx *hcl.EvalContext, val interface{}) hcl.Diagnostics {
+	if u, ok := val.(ExpressionUnmarshaler); ok {
+		return u.UnmarshalHCLExpression(expr, ctx)
+	}
+	if diags, ok := decodeStringTypeExpression(expr, ctx, val); ok {
+		return diags
+	}
+
+	finish := ctx.StartEvalExpression(expr)
 	srcVal, diags := expr.Value(ctx)
+	finish(diags)
 
 	convTy, err := gocty.ImpliedType(val)
 	if err != nil {
@@ -3,11 +3,17 @@ package gohcl
 import (
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
 	hclJSON "github.com/hashicorp/hcl2/hcl/json"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -45,6 +51,18 @@ func TestDecodeBody(t *testing.T) {
 			}{}),
 			1, // name is required
 		},
+		{
+			map[string]interface{}{
+				"app.name": "x",
+			},
+			struct {
+				Name string `hcl:"app.name"`
+			}{},
+			deepEquals(struct {
+				Name string `hcl:"app.name"`
+			}{"x"}),
+			0, // names that are not identifiers can be decoded from JSON
+		},
 		{
 			map[string]interface{}{},
 			struct {
@@ -519,6 +537,180 @@ func TestDecodeBody(t *testing.T) {
 			}),
 			0,
 		},
+		{
+			map[string]interface{}{},
+			struct {
+				Name string `hcl:"name" default:"\"Ermintrude\""`
+				Age  int    `hcl:"age,optional" default:"30 + 4"`
+			}{},
+			deepEquals(struct {
+				Name string `hcl:"name" default:"\"Ermintrude\""`
+				Age  int    `hcl:"age,optional" default:"30 + 4"`
+			}{"Ermintrude", 34}),
+			0,
+		},
+		{
+			map[string]interface{}{
+				"age": 89,
+			},
+			struct {
+				Age int `hcl:"age" default:"34"`
+			}{},
+			deepEquals(struct {
+				Age int `hcl:"age" default:"34"`
+			}{89}),
+			0,
+		},
+		{
+			map[string]interface{}{},
+			struct {
+				Tags []string `hcl:"tags" default:"[\"a\", \"b\"]"`
+			}{},
+			deepEquals(struct {
+				Tags []string `hcl:"tags" default:"[\"a\", \"b\"]"`
+			}{[]string{"a", "b"}}),
+			0,
+		},
+		{
+			map[string]interface{}{},
+			struct {
+				Age hcl.Expression `hcl:"age" default:"34"`
+			}{},
+			func(gotI interface{}) bool {
+				got := gotI.(struct {
+					Age hcl.Expression `hcl:"age" default:"34"`
+				})
+				v, diags := got.Age.Value(nil)
+				return len(diags) == 0 && v.RawEquals(cty.NumberIntVal(34))
+			},
+			0,
+		},
+		{
+			map[string]interface{}{},
+			struct {
+				Age int `hcl:"age" default:"\"old\""`
+			}{},
+			deepEquals(struct {
+				Age int `hcl:"age" default:"\"old\""`
+			}{}),
+			1, // default value is not a number
+		},
+		{
+			map[string]interface{}{
+				"name": "Ermintrude",
+				"age":  13,
+			},
+			testUnmarshaler{},
+			deepEquals(testUnmarshaler{
+				Names: []string{"age", "name"},
+			}),
+			0,
+		},
+		{
+			map[string]interface{}{
+				"noodle": map[string]interface{}{
+					"name": "Ermintrude",
+				},
+			},
+			struct {
+				Noodle testUnmarshaler `hcl:"noodle,block"`
+			}{},
+			deepEquals(struct {
+				Noodle testUnmarshaler `hcl:"noodle,block"`
+			}{
+				Noodle: testUnmarshaler{
+					Names: []string{"name"},
+				},
+			}),
+			0,
+		},
+		{
+			map[string]interface{}{
+				"name": "Ermintrude",
+			},
+			struct {
+				Name testExprUnmarshaler `hcl:"name"`
+			}{},
+			deepEquals(struct {
+				Name testExprUnmarshaler `hcl:"name"`
+			}{
+				Name: "ERMINTRUDE",
+			}),
+			0,
+		},
+		{
+			map[string]interface{}{
+				"attr1": true,
+				"attr2": true,
+				"thing": []map[string]interface{}{{}, {}},
+			},
+			struct {
+				testSquashed `hcl:",squash"`
+				Attr2        bool `hcl:"attr2"`
+			}{},
+			func(gotI interface{}) bool {
+				got := gotI.(struct {
+					testSquashed `hcl:",squash"`
+					Attr2        bool `hcl:"attr2"`
+				})
+				return got.Attr1 && got.Attr2 && len(got.Things) == 2
+			},
+			0,
+		},
+		{
+			map[string]interface{}{
+				"attr2": true,
+			},
+			struct {
+				testSquashed `hcl:",squash"`
+				Attr2        bool `hcl:"attr2"`
+			}{},
+			func(gotI interface{}) bool {
+				got := gotI.(struct {
+					testSquashed `hcl:",squash"`
+					Attr2        bool `hcl:"attr2"`
+				})
+				return !got.Attr1 && got.Attr2
+			},
+			1, // Missing required argument "attr1"
+		},
+		{
+			map[string]interface{}{
+				"name": true,
+			},
+			struct {
+				Name testExprUnmarshaler `hcl:"name"`
+			}{},
+			deepEquals(struct {
+				Name testExprUnmarshaler `hcl:"name"`
+			}{}),
+			1, // string required
+		},
+		{
+			map[string]interface{}{
+				"name": "Ermintrude",
+			},
+			struct {
+				Name *testExprUnmarshaler `hcl:"name"`
+			}{},
+			func(gotI interface{}) bool {
+				got := gotI.(struct {
+					Name *testExprUnmarshaler `hcl:"name"`
+				})
+				return got.Name != nil && *got.Name == "ERMINTRUDE"
+			},
+			0,
+		},
+		{
+			map[string]interface{}{},
+			struct {
+				Name *testExprUnmarshaler `hcl:"name"`
+			}{},
+			deepEquals(struct {
+				Name *testExprUnmarshaler `hcl:"name"`
+			}{}),
+			0,
+		},
 	}
 
 	for i, test := range tests {
@@ -553,6 +745,485 @@ func TestDecodeBody(t *testing.T) {
 
 }
 
+func TestDecodeBodyBlockMaps(t *testing.T) {
+	type Service struct {
+		Port int `hcl:"port"`
+	}
+	type NamedService struct {
+		Kind string `hcl:"kind,label"`
+		Name string `hcl:"name,label"`
+		Port int    `hcl:"port"`
+	}
+
+	tests := map[string]struct {
+		Config    string
+		Target    interface{}
+		Want      interface{}
+		DiagCount int
+	}{
+		"one label": {
+			`
+service "a" {
+  port = 1
+}
+service "b" {
+  port = 2
+}
+`,
+			struct {
+				Services map[string]Service `hcl:"service,block"`
+			}{},
+			struct {
+				Services map[string]Service `hcl:"service,block"`
+			}{
+				Services: map[string]Service{
+					"a": {Port: 1},
+					"b": {Port: 2},
+				},
+			},
+			0,
+		},
+		"one label, pointer elements": {
+			`
+service "a" {
+  port = 1
+}
+`,
+			struct {
+				Services map[string]*Service `hcl:"service,block"`
+			}{},
+			struct {
+				Services map[string]*Service `hcl:"service,block"`
+			}{
+				Services: map[string]*Service{
+					"a": {Port: 1},
+				},
+			},
+			0,
+		},
+		"two labels": {
+			`
+service "http" "a" {
+  port = 1
+}
+service "http" "b" {
+  port = 2
+}
+service "grpc" "a" {
+  port = 3
+}
+`,
+			struct {
+				Services map[string]map[string]NamedService `hcl:"service,block"`
+			}{},
+			struct {
+				Services map[string]map[string]NamedService `hcl:"service,block"`
+			}{
+				Services: map[string]map[string]NamedService{
+					"http": {
+						"a": {Kind: "http", Name: "a", Port: 1},
+						"b": {Kind: "http", Name: "b", Port: 2},
+					},
+					"grpc": {
+						"a": {Kind: "grpc", Name: "a", Port: 3},
+					},
+				},
+			},
+			0,
+		},
+		"no blocks": {
+			``,
+			struct {
+				Services map[string]Service `hcl:"service,block"`
+			}{},
+			struct {
+				Services map[string]Service `hcl:"service,block"`
+			}{},
+			0,
+		},
+		"duplicate": {
+			`
+service "a" {
+  port = 1
+}
+service "a" {
+  port = 2
+}
+`,
+			struct {
+				Services map[string]Service `hcl:"service,block"`
+			}{},
+			struct {
+				Services map[string]Service `hcl:"service,block"`
+			}{
+				Services: map[string]Service{
+					"a": {Port: 1},
+				},
+			},
+			1, // Duplicate service block
+		},
+		"missing label": {
+			`
+service {
+  port = 1
+}
+`,
+			struct {
+				Services map[string]Service `hcl:"service,block"`
+			}{},
+			struct {
+				Services map[string]Service `hcl:"service,block"`
+			}{},
+			1, // Missing key for service
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.Config), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			if len(diags) != 0 {
+				t.Fatalf("diagnostics while parsing: %s", diags.Error())
+			}
+
+			targetVal := reflect.New(reflect.TypeOf(test.Target))
+
+			diags = DecodeBody(file.Body, nil, targetVal.Interface())
+			if len(diags) != test.DiagCount {
+				t.Errorf("wrong number of diagnostics %d; want %d", len(diags), test.DiagCount)
+				for _, diag := range diags {
+					t.Logf(" - %s", diag.Error())
+				}
+			}
+			got := targetVal.Elem().Interface()
+			if !reflect.DeepEqual(got, test.Want) {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", spew.Sdump(got), spew.Sdump(test.Want))
+			}
+		})
+	}
+}
+
+func TestDecodeBodyValidation(t *testing.T) {
+	type Server struct {
+		Port  int       `hcl:"port" validate:"min=1,max=65535"`
+		Name  string    `hcl:"name,optional" validate:"min=1,max=8"`
+		Mode  string    `hcl:"mode,optional" validate:"oneof=fast slow"`
+		Tags  []string  `hcl:"tags,optional" validate:"max=2"`
+		Ratio *float64  `hcl:"ratio,optional" validate:"max=1"`
+		Color testColor `hcl:"color,optional"`
+	}
+
+	tests := map[string]struct {
+		Config    string
+		DiagCount int
+	}{
+		"valid": {
+			`
+port  = 8080
+name  = "web"
+mode  = "fast"
+tags  = ["a", "b"]
+ratio = 0.5
+color = "red"
+`,
+			0,
+		},
+		"only required": {
+			`port = 1`,
+			0,
+		},
+		"number too small": {
+			`port = 0`,
+			1, // must be at least 1
+		},
+		"number too large": {
+			`port = 70000`,
+			1, // must be at most 65535
+		},
+		"string too long": {
+			`
+port = 1
+name = "much too long"
+`,
+			1, // must have a length of at most 8
+		},
+		"string not in options": {
+			`
+port = 1
+mode = "medium"
+`,
+			1, // must be one of "fast", "slow"
+		},
+		"too many elements": {
+			`
+port = 1
+tags = ["a", "b", "c"]
+`,
+			1, // must have a length of at most 2
+		},
+		"pointer": {
+			`
+port  = 1
+ratio = 2
+`,
+			1, // must be at most 1
+		},
+		"validator": {
+			`
+port  = 1
+color = "mauve"
+`,
+			1, // unsupported color
+		},
+		"several": {
+			`
+port = 0
+name = ""
+`,
+			2,
+		},
+		"wrong type": {
+			`port = "http"`,
+			1, // Unsuitable value type; no validation diagnostic
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.Config), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			if len(diags) != 0 {
+				t.Fatalf("diagnostics while parsing: %s", diags.Error())
+			}
+
+			var got Server
+			diags = DecodeBody(file.Body, nil, &got)
+			if len(diags) != test.DiagCount {
+				t.Errorf("wrong number of diagnostics %d; want %d", len(diags), test.DiagCount)
+				for _, diag := range diags {
+					t.Logf(" - %s", diag.Error())
+				}
+			}
+			for _, diag := range diags {
+				if diag.Subject == nil || diag.Subject.Start.Line == 0 {
+					t.Errorf("diagnostic %q has no subject", diag.Summary)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeBodyRanges(t *testing.T) {
+	type Service struct {
+		Name      string    `hcl:"name,label"`
+		NameRange hcl.Range `hcl:"name,range"`
+		Port      int       `hcl:"port"`
+		PortRange hcl.Range `hcl:"port,range"`
+	}
+	type Config struct {
+		Region       string      `hcl:"region,optional"`
+		RegionRange  hcl.Range   `hcl:"region,range"`
+		Services     []Service   `hcl:"service,block"`
+		ServiceDefs  []hcl.Range `hcl:"service,range"`
+		Logging      *struct{}   `hcl:"logging,block"`
+		LoggingRange hcl.Range   `hcl:"logging,range"`
+	}
+
+	src := `
+service "web" {
+  port = 80
+}
+service "api" {
+  port = 8080
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("diagnostics while parsing: %s", diags.Error())
+	}
+
+	var got Config
+	diags = DecodeBody(file.Body, nil, &got)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	rng := func(startLine, startCol, startByte, endLine, endCol, endByte int) hcl.Range {
+		return hcl.Range{
+			Filename: "test.hcl",
+			Start:    hcl.Pos{Line: startLine, Column: startCol, Byte: startByte},
+			End:      hcl.Pos{Line: endLine, Column: endCol, Byte: endByte},
+		}
+	}
+
+	if got.RegionRange != (hcl.Range{}) {
+		t.Errorf("wrong range for absent attribute %#v; want zero range", got.RegionRange)
+	}
+	if got.LoggingRange != (hcl.Range{}) {
+		t.Errorf("wrong range for absent block %#v; want zero range", got.LoggingRange)
+	}
+	wantDefs := []hcl.Range{
+		rng(2, 1, 1, 2, 14, 14),
+		rng(5, 1, 31, 5, 14, 44),
+	}
+	if !reflect.DeepEqual(got.ServiceDefs, wantDefs) {
+		t.Errorf("wrong block ranges\ngot:  %s\nwant: %s", spew.Sdump(got.ServiceDefs), spew.Sdump(wantDefs))
+	}
+	if len(got.Services) != 2 {
+		t.Fatalf("wrong number of services %d; want 2", len(got.Services))
+	}
+	if want := rng(2, 9, 9, 2, 14, 14); got.Services[0].NameRange != want {
+		t.Errorf("wrong label range\ngot:  %#v\nwant: %#v", got.Services[0].NameRange, want)
+	}
+	if want := rng(6, 3, 49, 6, 14, 60); got.Services[1].PortRange != want {
+		t.Errorf("wrong attribute range\ngot:  %#v\nwant: %#v", got.Services[1].PortRange, want)
+	}
+}
+
+func TestDecodeBodyRemainAttrs(t *testing.T) {
+	deepEquals := func(other interface{}) func(v interface{}) bool {
+		return func(v interface{}) bool {
+			return reflect.DeepEqual(v, other)
+		}
+	}
+
+	tests := map[string]struct {
+		Config    string
+		Target    interface{}
+		Check     func(interface{}) bool
+		DiagCount int
+	}{
+		"values": {
+			`
+name  = "web"
+env   = "prod"
+debug = true
+`,
+			struct {
+				Name  string               `hcl:"name"`
+				Other map[string]cty.Value `hcl:",remainattrs"`
+			}{},
+			deepEquals(struct {
+				Name  string               `hcl:"name"`
+				Other map[string]cty.Value `hcl:",remainattrs"`
+			}{
+				Name: "web",
+				Other: map[string]cty.Value{
+					"env":   cty.StringVal("prod"),
+					"debug": cty.True,
+				},
+			}),
+			0,
+		},
+		"strings": {
+			`
+env   = "prod"
+count = 2
+`,
+			struct {
+				Tags map[string]string `hcl:",remainattrs"`
+			}{},
+			deepEquals(struct {
+				Tags map[string]string `hcl:",remainattrs"`
+			}{
+				Tags: map[string]string{
+					"env":   "prod",
+					"count": "2",
+				},
+			}),
+			0,
+		},
+		"expressions": {
+			`
+name = "web"
+env  = upper("prod")
+`,
+			struct {
+				Name  string                    `hcl:"name"`
+				Exprs map[string]hcl.Expression `hcl:",remainattrs"`
+			}{},
+			func(gotI interface{}) bool {
+				got := gotI.(struct {
+					Name  string                    `hcl:"name"`
+					Exprs map[string]hcl.Expression `hcl:",remainattrs"`
+				})
+				_, hasName := got.Exprs["name"]
+				_, hasEnv := got.Exprs["env"]
+				return got.Name == "web" && len(got.Exprs) == 1 && !hasName && hasEnv
+			},
+			0,
+		},
+		"none": {
+			`name = "web"`,
+			struct {
+				Name  string            `hcl:"name"`
+				Other map[string]string `hcl:",remainattrs"`
+			}{},
+			deepEquals(struct {
+				Name  string            `hcl:"name"`
+				Other map[string]string `hcl:",remainattrs"`
+			}{
+				Name:  "web",
+				Other: map[string]string{},
+			}),
+			0,
+		},
+		"wrong type": {
+			`count = [1]`,
+			struct {
+				Other map[string]string `hcl:",remainattrs"`
+			}{},
+			deepEquals(struct {
+				Other map[string]string `hcl:",remainattrs"`
+			}{
+				Other: map[string]string{
+					"count": "",
+				},
+			}),
+			1, // Unsuitable value type
+		},
+		"unexpected block": {
+			`
+name = "web"
+extra {}
+`,
+			struct {
+				Name  string            `hcl:"name"`
+				Other map[string]string `hcl:",remainattrs"`
+			}{},
+			deepEquals(struct {
+				Name  string            `hcl:"name"`
+				Other map[string]string `hcl:",remainattrs"`
+			}{
+				Name:  "web",
+				Other: map[string]string{},
+			}),
+			1, // Unexpected "extra" block
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.Config), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			if len(diags) != 0 {
+				t.Fatalf("diagnostics while parsing: %s", diags.Error())
+			}
+
+			targetVal := reflect.New(reflect.TypeOf(test.Target))
+
+			diags = DecodeBody(file.Body, nil, targetVal.Interface())
+			if len(diags) != test.DiagCount {
+				t.Errorf("wrong number of diagnostics %d; want %d", len(diags), test.DiagCount)
+				for _, diag := range diags {
+					t.Logf(" - %s", diag.Error())
+				}
+			}
+			got := targetVal.Elem().Interface()
+			if !test.Check(got) {
+				t.Errorf("wrong result\n%s", spew.Sdump(got))
+			}
+		})
+	}
+}
+
 func TestDecodeExpression(t *testing.T) {
 	tests := []struct {
 		Value     cty.Value
@@ -602,6 +1273,84 @@ func TestDecodeExpression(t *testing.T) {
 			false,
 			1, // bool required
 		},
+		{
+			cty.StringVal("1m30s"),
+			time.Duration(0),
+			90 * time.Second,
+			0,
+		},
+		{
+			cty.StringVal("90"),
+			time.Duration(0),
+			time.Duration(0),
+			1, // Invalid duration
+		},
+		{
+			cty.NullVal(cty.String),
+			time.Duration(0),
+			time.Duration(0),
+			1, // null value is not allowed
+		},
+		{
+			cty.NullVal(cty.String),
+			(*time.Duration)(nil),
+			(*time.Duration)(nil),
+			0,
+		},
+		{
+			cty.StringVal("2006-01-02T15:04:05Z"),
+			time.Time{},
+			time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+			0,
+		},
+		{
+			cty.StringVal("2006-01-02"),
+			time.Time{},
+			time.Time{},
+			1, // Invalid timestamp
+		},
+		{
+			cty.StringVal("10.1.2.3"),
+			net.IP(nil),
+			net.ParseIP("10.1.2.3"),
+			0,
+		},
+		{
+			cty.StringVal("10.1.2"),
+			net.IP(nil),
+			net.IP(nil),
+			1, // Invalid IP address
+		},
+		{
+			cty.StringVal("10.1.0.0/16"),
+			net.IPNet{},
+			net.IPNet{IP: net.IP{10, 1, 0, 0}, Mask: net.CIDRMask(16, 32)},
+			0,
+		},
+		{
+			cty.StringVal("10.1.0.0"),
+			net.IPNet{},
+			net.IPNet{},
+			1, // Invalid CIDR address
+		},
+		{
+			cty.StringVal("https://example.com/foo?bar=baz"),
+			(*url.URL)(nil),
+			&url.URL{Scheme: "https", Host: "example.com", Path: "/foo", RawQuery: "bar=baz"},
+			0,
+		},
+		{
+			cty.StringVal("http://[::1"),
+			url.URL{},
+			url.URL{},
+			1, // Invalid URL
+		},
+		{
+			cty.NumberIntVal(5),
+			time.Duration(0),
+			time.Duration(0),
+			1, // Invalid duration
+		},
 	}
 
 	for i, test := range tests {
@@ -643,3 +1392,48 @@ func (e *fixedExpression) StartRange() (r hcl.Range) {
 func (e *fixedExpression) Variables() []hcl.Traversal {
 	return nil
 }
+
+// testUnmarshaler is an Unmarshaler that just records the names of the
+// attributes in the body it is given.
+type testUnmarshaler struct {
+	Names []string
+}
+
+func (u *testUnmarshaler) UnmarshalHCL(body hcl.Body, ctx *hcl.EvalContext) hcl.Diagnostics {
+	attrs, diags := body.JustAttributes()
+	for name := range attrs {
+		u.Names = append(u.Names, name)
+	}
+	sort.Strings(u.Names)
+	return diags
+}
+
+// testExprUnmarshaler is an ExpressionUnmarshaler that records the
+// uppercase version of the string its expression evaluates to.
+type testExprUnmarshaler string
+
+func (u *testExprUnmarshaler) UnmarshalHCLExpression(expr hcl.Expression, ctx *hcl.EvalContext) hcl.Diagnostics {
+	val, diags := expr.Value(ctx)
+	if val.Type() != cty.String {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid value",
+			Detail:   "A string is required.",
+			Subject:  expr.Range().Ptr(),
+		})
+		return diags
+	}
+	*u = testExprUnmarshaler(strings.ToUpper(val.AsString()))
+	return diags
+}
+
+type testColor string
+
+func (c testColor) ValidateHCL() error {
+	switch c {
+	case "", "red", "green", "blue":
+		return nil
+	default:
+		return fmt.Errorf("unsupported color %q", string(c))
+	}
+}
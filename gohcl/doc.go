@@ -18,16 +18,54 @@
 //    block indicates that the value is to populated from a block
 //    label indicates that the value is to populated from a block label
 //    remain indicates that the value is to be populated from the remaining body after populating other fields
+//    remainattrs indicates that the value is to be populated from any attributes not matched by other fields
+//    squash indicates that the fields of an embedded struct are to be treated as fields of the outer struct
+//    range indicates that the value is to be populated with the source range of the construct of the given name
+//
+// Names that are not valid identifiers, as checked by hcl.ValidIdentifier,
+// can be decoded only from syntaxes that allow them, such as JSON. Encoding
+// such a name with EncodeIntoBody or EncodeAsBlock causes a panic, since it
+// could not be written in the native syntax.
 //
 // "attr" fields may either be of type *hcl.Expression, in which case the raw
 // expression is assigned, or of any type accepted by gocty, in which case
 // gocty will be used to assign the value to a native Go type.
+// The standard library types time.Duration, time.Time, net.IP, net.IPNet and
+// url.URL are also supported, decoded from strings such as "30s",
+// "2006-01-02T15:04:05Z", "10.0.0.1", "10.0.0.0/8" and "https://example.com/"
+// respectively.
+//
+// "attr" and "optional" fields may additionally have a "default" tag whose
+// value is a native syntax expression to use when the attribute is absent
+// from the configuration. The expression is evaluated in the same
+// EvalContext as the configuration itself:
+//
+//    Port int `hcl:"port,optional" default:"8080"`
+//
+// They may also have a "validate" tag giving a comma-separated list of
+// constraints to check once the value has been decoded. "min=N" and "max=N"
+// constrain numbers by value and strings, slices and maps by length, while
+// "oneof=a b c" requires a string to be one of the given space-separated
+// options. Violations are reported as error diagnostics referring to the
+// attribute's expression:
+//
+//    Port int `hcl:"port" validate:"min=1,max=65535"`
+//
+// Attribute value types can also implement Validator to apply their own
+// checks after decoding.
 //
 // "block" fields may be of type *hcl.Block or hcl.Body, in which case the
 // corresponding raw value is assigned, or may be a struct that recursively
 // uses the same tags. Block fields may also be slices of any of these types,
 // in which case multiple blocks of the corresponding type are decoded into
-// the slice.
+// the slice. Block fields of type map[string]T or map[string]map[string]T,
+// where T is a struct or pointer to struct, decode multiple blocks into a map
+// keyed by the first one or two block labels, respectively.
+//
+// Block fields may also be of an interface type, or a slice of an interface
+// type, in which case the concrete type to decode each block into is selected
+// from those registered with RegisterBlockType, either by the first block
+// label or by the block type name. See RegisterBlockType for details.
 //
 // "label" fields are considered only in a struct used as the type of a field
 // marked as "block", and are used sequentially to capture the labels of
@@ -40,6 +78,46 @@
 // present then any attributes or blocks not matched by another valid tag
 // will cause an error diagnostic.
 //
+// "remainattrs" can be placed on a single field, with an empty name, whose
+// type is a map with string keys. Any attributes not matched by other fields
+// are placed into the map, decoded in the same way as a map used as the
+// target of DecodeBody, which allows for an open-ended set of arguments such
+// as tags or environment variables. Unlike "remain", blocks not matched by
+// other fields are still reported as errors. "remain" and "remainattrs"
+// cannot be used together:
+//
+//    Tags map[string]string `hcl:",remainattrs"`
+//
+// "range" fields record where in the configuration another field's value
+// came from, so that later semantic checks can return diagnostics that refer
+// to it. The name must match an "attr", "optional", "block" or "label" field
+// in the same struct. The field type must be hcl.Range, which is populated
+// with the Range of an attribute, covering both its name and its expression,
+// the DefRange of the first block of a block type, or the range of a label.
+// Fields for blocks may instead be of type []hcl.Range to capture the
+// DefRange of every block of that type. For the range of just an attribute's
+// expression, decode the attribute into an hcl.Expression field instead.
+// Range fields are left as the zero hcl.Range if the construct is absent:
+//
+//    Port      int       `hcl:"port"`
+//    PortRange hcl.Range `hcl:"port,range"`
+//
+// "squash" can be placed only on an anonymous embedded struct field, with
+// an empty name, such as:
+//
+//    CommonArgs `hcl:",squash"`
+//
+// The tagged fields of the embedded struct are then decoded as if they were
+// declared directly in the outer struct, so that a group of arguments can be
+// shared between many block types. Names must be unique across the outer
+// struct and all of its squashed structs.
+//
+// Types with decoding requirements that cannot be expressed using the tags
+// above may implement the Unmarshaler interface, for types that decode from
+// a body, or the ExpressionUnmarshaler interface, for types that decode from
+// an attribute expression. These are then used in place of the usual
+// decoding rules wherever the type appears.
+//
 // Only a subset of this tagging/typing vocabulary is supported for the
 // "Encode" family of functions. See the EncodeIntoBody docs for full details
 // on the constraints there.
@@ -5,7 +5,9 @@ import (
 	"reflect"
 	"sort"
 
+	"github.com/hashicorp/hcl2/hcl"
 	"github.com/hashicorp/hcl2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/gocty"
 )
 
@@ -15,18 +17,23 @@ import (
 // in this package.
 //
 // This function can work only with fully-decoded data. It will ignore any
-// fields tagged as "remain", any fields that decode attributes into either
-// hcl.Attribute or hcl.Expression values, and any fields that decode blocks
-// into hcl.Attributes values. This function does not have enough information
-// to complete the decoding of these types.
+// fields tagged as "remain" or "remainattrs", any fields that decode
+// attributes into either hcl.Attribute or hcl.Expression values, and any
+// fields that decode blocks into hcl.Attributes values. This function does
+// not have enough information to complete the decoding of these types.
 //
 // Any fields tagged as "label" are ignored by this function. Use EncodeAsBlock
 // to produce a whole hclwrite.Block including block labels.
 //
+// Attribute fields of type cty.Value are written using the given value
+// directly, unless the field holds the zero value cty.NilVal, in which case
+// the attribute is omitted.
+//
 // As long as a suitable value is given to encode and the destination body
 // is non-nil, this function will always complete. It will panic in case of
-// any errors in the calling program, such as passing an inappropriate type
-// or a nil body.
+// any errors in the calling program, such as passing an inappropriate type,
+// a nil body, or a struct with a non-empty attribute or block field whose
+// name is not a valid identifier.
 //
 // The layout of the resulting HCL source is derived from the ordering of
 // the struct fields, with blank lines around nested blocks of different types.
@@ -56,8 +63,16 @@ func EncodeIntoBody(val interface{}, dst *hclwrite.Body) {
 // will be used in order to annotate the created block with labels.
 //
 // This function has the same constraints as EncodeIntoBody and will panic
-// if they are violated.
+// if they are violated, or if the block type is not a valid identifier.
 func EncodeAsBlock(val interface{}, blockType string) *hclwrite.Block {
+	return encodeAsBlock(val, blockType, nil)
+}
+
+// encodeAsBlock is the main implementation of EncodeAsBlock. If the given
+// struct type has no fields tagged with "label" then the given default labels
+// are used instead, which allows the keys of a map of blocks to be used as
+// labels.
+func encodeAsBlock(val interface{}, blockType string, defaultLabels []string) *hclwrite.Block {
 	rv := reflect.ValueOf(val)
 	ty := rv.Type()
 	if ty.Kind() == reflect.Ptr {
@@ -67,14 +82,20 @@ func EncodeAsBlock(val interface{}, blockType string) *hclwrite.Block {
 	if ty.Kind() != reflect.Struct {
 		panic(fmt.Sprintf("value is %s, not struct", ty.Kind()))
 	}
+	if !hcl.ValidIdentifier(blockType) {
+		panic(fmt.Sprintf("cannot encode block type %q: not a valid identifier (try %q)", blockType, hcl.SanitizeIdentifier(blockType)))
+	}
 
 	tags := getFieldTags(ty)
-	labels := make([]string, len(tags.Labels))
-	for i, lf := range tags.Labels {
-		lv := rv.Field(lf.FieldIndex)
-		// We just stringify whatever we find. It should always be a string
-		// but if not then we'll still do something reasonable.
-		labels[i] = fmt.Sprintf("%s", lv.Interface())
+	labels := defaultLabels
+	if len(tags.Labels) > 0 {
+		labels = make([]string, len(tags.Labels))
+		for i, lf := range tags.Labels {
+			lv := rv.FieldByIndex(lf.FieldIndex)
+			// We just stringify whatever we find. It should always be a string
+			// but if not then we'll still do something reasonable.
+			labels[i] = fmt.Sprintf("%s", lv.Interface())
+		}
 	}
 
 	block := hclwrite.NewBlock(blockType, labels)
@@ -83,7 +104,7 @@ func EncodeAsBlock(val interface{}, blockType string) *hclwrite.Block {
 }
 
 func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwrite.Body) {
-	nameIdxs := make(map[string]int, len(tags.Attributes)+len(tags.Blocks))
+	nameIdxs := make(map[string][]int, len(tags.Attributes)+len(tags.Blocks))
 	namesOrder := make([]string, 0, len(tags.Attributes)+len(tags.Blocks))
 	for n, i := range tags.Attributes {
 		nameIdxs[n] = i
@@ -95,7 +116,7 @@ func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwr
 	}
 	sort.SliceStable(namesOrder, func(i, j int) bool {
 		ni, nj := namesOrder[i], namesOrder[j]
-		return nameIdxs[ni] < nameIdxs[nj]
+		return fieldIndexLess(nameIdxs[ni], nameIdxs[nj])
 	})
 
 	dst.Clear()
@@ -103,9 +124,9 @@ func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwr
 	prevWasBlock := false
 	for _, name := range namesOrder {
 		fieldIdx := nameIdxs[name]
-		field := ty.Field(fieldIdx)
+		field := ty.FieldByIndex(fieldIdx)
 		fieldTy := field.Type
-		fieldVal := rv.Field(fieldIdx)
+		fieldVal := rv.FieldByIndex(fieldIdx)
 
 		if fieldTy.Kind() == reflect.Ptr {
 			fieldTy = fieldTy.Elem()
@@ -126,11 +147,22 @@ func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwr
 			if field.Type.Kind() != reflect.Ptr && tags.OmitEmpty[name] && fieldVal.IsZero() {
 				continue // ignore empty fields that are tagged as omitempty.
 			}
+			if cv, isCty := fieldVal.Interface().(cty.Value); isCty && cv == cty.NilVal {
+				continue // ignore (field value is the zero cty.Value)
+			}
+			if !hcl.ValidIdentifier(name) {
+				panic(fmt.Sprintf("cannot encode %s %q: attribute name is not a valid identifier (try %q)", field.Type.String(), field.Name, hcl.SanitizeIdentifier(name)))
+			}
 			if prevWasBlock {
 				dst.AppendNewline()
 				prevWasBlock = false
 			}
 
+			if val, ok := encodeStringType(fieldVal); ok {
+				dst.SetAttributeValue(name, val)
+				continue
+			}
+
 			valTy, err := gocty.ImpliedType(fieldVal.Interface())
 			if err != nil {
 				panic(fmt.Sprintf("cannot encode %T as HCL expression: %s", fieldVal.Interface(), err))
@@ -146,6 +178,18 @@ func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwr
 			dst.SetAttributeValue(name, val)
 
 		} else { // must be a block, then
+			if ifaceTy, ok := blockInterfaceType(field.Type); ok {
+				prevWasBlock = false
+				for _, block := range blocksForInterface(rv.FieldByIndex(fieldIdx), name, ifaceTy) {
+					if !prevWasBlock {
+						dst.AppendNewline()
+						prevWasBlock = true
+					}
+					dst.AppendBlock(block)
+				}
+				continue
+			}
+
 			elemTy := fieldTy
 			isSeq := false
 			if elemTy.Kind() == reflect.Slice || elemTy.Kind() == reflect.Array {
@@ -155,7 +199,15 @@ func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwr
 
 			prevWasBlock = false
 
-			if isSeq {
+			if elemTy.Kind() == reflect.Map {
+				for _, block := range blocksForMap(fieldVal, name, nil) {
+					if !prevWasBlock {
+						dst.AppendNewline()
+						prevWasBlock = true
+					}
+					dst.AppendBlock(block)
+				}
+			} else if isSeq {
 				l := fieldVal.Len()
 				for i := 0; i < l; i++ {
 					elemVal := fieldVal.Index(i)
@@ -192,3 +244,81 @@ func populateBody(rv reflect.Value, ty reflect.Type, tags *fieldTags, dst *hclwr
 		}
 	}
 }
+
+// blocksForInterface produces one block for each non-nil value in the given
+// value of an interface type, or slice of an interface type, whose concrete
+// types are registered with RegisterBlockType. If the type name is empty then
+// the key each concrete type was registered under is used as the block type.
+func blocksForInterface(v reflect.Value, typeName string, ifaceTy reflect.Type) []*hclwrite.Block {
+	var elems []reflect.Value
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			elems = append(elems, v.Index(i))
+		}
+	} else {
+		elems = append(elems, v)
+	}
+
+	var ret []*hclwrite.Block
+	for _, ev := range elems {
+		if ev.IsNil() {
+			continue // ignore
+		}
+		ev = ev.Elem() // the dynamic value inside the interface
+		if ev.Kind() == reflect.Ptr && ev.IsNil() {
+			continue // ignore
+		}
+		name := typeName
+		if name == "" {
+			key, ok := registeredBlockTypeKey(ifaceTy, ev.Type())
+			if !ok {
+				panic(fmt.Sprintf("cannot encode %s as a block: type is not registered for %s", ev.Type().String(), ifaceTy.String()))
+			}
+			name = key
+		}
+		ret = append(ret, EncodeAsBlock(ev.Interface(), name))
+	}
+	return ret
+}
+
+// fieldIndexLess returns true if the field with index sequence a is declared
+// before the field with index sequence b, taking into account the position
+// of any embedded structs.
+func fieldIndexLess(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// blocksForMap produces one block for each element of the given map value,
+// which must be of a map type accepted for decoding blocks, using the map
+// keys as block labels unless the element type has its own label fields.
+// The blocks are returned in lexical order by key.
+func blocksForMap(mv reflect.Value, typeName string, keyLabels []string) []*hclwrite.Block {
+	keys := make([]string, 0, mv.Len())
+	for _, kv := range mv.MapKeys() {
+		keys = append(keys, kv.String())
+	}
+	sort.Strings(keys)
+
+	var ret []*hclwrite.Block
+	for _, k := range keys {
+		elemVal := mv.MapIndex(reflect.ValueOf(k))
+		labels := make([]string, len(keyLabels), len(keyLabels)+1)
+		copy(labels, keyLabels)
+		labels = append(labels, k)
+
+		if elemVal.Kind() == reflect.Map && len(labels) < 2 {
+			ret = append(ret, blocksForMap(elemVal, typeName, labels)...)
+			continue
+		}
+		if elemVal.Kind() == reflect.Ptr && elemVal.IsNil() {
+			continue // ignore
+		}
+		ret = append(ret, encodeAsBlock(elemVal.Interface(), typeName, labels))
+	}
+	return ret
+}
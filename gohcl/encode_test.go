@@ -2,9 +2,14 @@ package gohcl_test
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
 
 	"github.com/hashicorp/hcl2/gohcl"
 	"github.com/hashicorp/hcl2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func ExampleEncodeIntoBody() {
@@ -62,3 +67,206 @@ func ExampleEncodeIntoBody() {
 	//   executable = ["./worker"]
 	// }
 }
+
+func TestEncodeIntoBody(t *testing.T) {
+	type Inner struct {
+		Name  string `hcl:"name,label"`
+		Count *int   `hcl:"count"`
+	}
+	type Outer struct {
+		Dynamic cty.Value         `hcl:"dynamic"`
+		Unset   cty.Value         `hcl:"unset,optional"`
+		Tags    map[string]string `hcl:"tags"`
+		Inners  []*Inner          `hcl:"inner,block"`
+	}
+
+	count := 2
+	val := Outer{
+		Dynamic: cty.ListVal([]cty.Value{cty.True}),
+		Tags:    map[string]string{"env": "prod"},
+		Inners: []*Inner{
+			{Name: "a", Count: &count},
+			nil,
+			{Name: "b"},
+		},
+	}
+
+	f := hclwrite.NewEmptyFile()
+	gohcl.EncodeIntoBody(&val, f.Body())
+	got := string(f.Bytes())
+	want := `dynamic = [true]
+tags    = { env = "prod" }
+
+inner "a" {
+  count = 2
+}
+inner "b" {
+}
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEncodeIntoBodyBlockMap(t *testing.T) {
+	type Service struct {
+		Port int `hcl:"port"`
+	}
+	type Config struct {
+		Services map[string]map[string]*Service `hcl:"service,block"`
+	}
+
+	val := Config{
+		Services: map[string]map[string]*Service{
+			"http": {
+				"b": {Port: 2},
+				"a": {Port: 1},
+			},
+			"grpc": {
+				"a": {Port: 3},
+				"z": nil,
+			},
+		},
+	}
+
+	f := hclwrite.NewEmptyFile()
+	gohcl.EncodeIntoBody(&val, f.Body())
+	got := string(f.Bytes())
+	want := `
+service "grpc" "a" {
+  port = 3
+}
+service "http" "a" {
+  port = 1
+}
+service "http" "b" {
+  port = 2
+}
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEncodeIntoBodyStringTypes(t *testing.T) {
+	type Config struct {
+		Timeout  time.Duration `hcl:"timeout"`
+		Created  time.Time     `hcl:"created"`
+		Address  net.IP        `hcl:"address"`
+		Endpoint *url.URL      `hcl:"endpoint"`
+		Proxy    *url.URL      `hcl:"proxy,optional"`
+	}
+
+	endpoint, _ := url.Parse("https://example.com/api")
+	val := Config{
+		Timeout:  90 * time.Second,
+		Created:  time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+		Address:  net.ParseIP("10.1.2.3"),
+		Endpoint: endpoint,
+	}
+
+	f := hclwrite.NewEmptyFile()
+	gohcl.EncodeIntoBody(&val, f.Body())
+	got := string(f.Bytes())
+	want := `timeout  = "1m30s"
+created  = "2006-01-02T15:04:05Z"
+address  = "10.1.2.3"
+endpoint = "https://example.com/api"
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEncodeIntoBodySquash(t *testing.T) {
+	type Common struct {
+		Name string            `hcl:"name"`
+		Tags map[string]string `hcl:"tags"`
+	}
+	type Service struct {
+		Kind   string `hcl:"kind"`
+		Common `hcl:",squash"`
+		Port   int `hcl:"port"`
+	}
+
+	val := Service{
+		Kind: "http",
+		Common: Common{
+			Name: "web",
+			Tags: map[string]string{"env": "prod"},
+		},
+		Port: 8080,
+	}
+
+	f := hclwrite.NewEmptyFile()
+	gohcl.EncodeIntoBody(&val, f.Body())
+	got := string(f.Bytes())
+	want := `kind = "http"
+name = "web"
+tags = { env = "prod" }
+port = 8080
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEncodeIntoBodyInvalidName(t *testing.T) {
+	type Inner struct{}
+	type Config struct {
+		Name  string `hcl:"app.name"`
+		Inner *Inner `hcl:"inner block,block"`
+	}
+
+	tests := map[string]struct {
+		encode func()
+		want   string
+	}{
+		"attribute": {
+			func() {
+				gohcl.EncodeIntoBody(&Config{Name: "x"}, hclwrite.NewEmptyFile().Body())
+			},
+			`cannot encode string "Name": attribute name is not a valid identifier (try "app_name")`,
+		},
+		"block": {
+			func() {
+				gohcl.EncodeIntoBody(&struct {
+					Inner *Inner `hcl:"inner block,block"`
+				}{&Inner{}}, hclwrite.NewEmptyFile().Body())
+			},
+			`cannot encode block type "inner block": not a valid identifier (try "inner_block")`,
+		},
+		"block type": {
+			func() {
+				gohcl.EncodeAsBlock(&struct{}{}, "9lives")
+			},
+			`cannot encode block type "9lives": not a valid identifier (try "_9lives")`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if r != test.want {
+					t.Errorf("wrong panic\ngot:  %#v\nwant: %#v", r, test.want)
+				}
+			}()
+			test.encode()
+		})
+	}
+}
+
+func TestEncodeIntoBodyInvalidNameOmitted(t *testing.T) {
+	type Config struct {
+		Name  string `hcl:"name"`
+		Count *int   `hcl:"count total"`
+	}
+
+	// Fields that are not written are not checked.
+	f := hclwrite.NewEmptyFile()
+	gohcl.EncodeIntoBody(&Config{Name: "x"}, f.Body())
+	if got, want := string(f.Bytes()), "name = \"x\"\n"; got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
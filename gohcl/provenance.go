@@ -0,0 +1,214 @@
+package gohcl
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// DecodeBodyWithProvenance is like DecodeBody except that it also returns
+// the provenance of the decoded values, recording where each of them came
+// from.
+//
+// The paths in the provenance use the names from the configuration rather
+// than the names of Go fields. Each attribute has an entry identified by a
+// GetAttrStep with its name, whose range is that of the attribute's
+// expression. The attributes within blocks follow a GetAttrStep with the
+// block type name, and then an IndexStep with the position of the block if
+// it is decoded into a slice, or an IndexStep for each of the labels used as
+// keys if it is decoded into a map. A field that holds blocks of several
+// registered block types adds no step for the block type. Labels decoded
+// into label fields have entries identified by a GetAttrStep with the name of
+// the label, within their block. Attributes in "remain" and "remainattrs"
+// fields are identified as if they were declared by the struct itself.
+//
+// There are no entries within values decoded by an Unmarshaler, nor for
+// attributes whose values are given by "default" tags.
+func DecodeBodyWithProvenance(body hcl.Body, ctx *hcl.EvalContext, val interface{}) (hcl.Provenance, hcl.Diagnostics) {
+	diags := DecodeBody(body, ctx, val)
+	var prov hcl.Provenance
+	bodyProvenance(&prov, body, reflect.TypeOf(val).Elem(), nil)
+	return prov, diags
+}
+
+func bodyProvenance(prov *hcl.Provenance, body hcl.Body, ty reflect.Type, path cty.Path) {
+	if reflect.PtrTo(ty).Implements(unmarshalerType) {
+		return
+	}
+
+	switch ty.Kind() {
+	case reflect.Struct:
+		structProvenance(prov, body, ty, path)
+	case reflect.Map:
+		attrs, _ := body.JustAttributes()
+		attrsProvenance(prov, attrs, path)
+	}
+}
+
+func structProvenance(prov *hcl.Provenance, body hcl.Body, ty reflect.Type, path cty.Path) {
+	schema, _ := ImpliedBodySchema(reflect.Zero(ty).Interface())
+	content, leftovers, _ := body.PartialContent(schema)
+	if content == nil {
+		return
+	}
+	tags := getFieldTags(ty)
+
+	attrs := make(hcl.Attributes, len(tags.Attributes))
+	for name := range tags.Attributes {
+		if attr := content.Attributes[name]; attr != nil {
+			attrs[name] = attr
+		}
+	}
+	attrsProvenance(prov, attrs, path)
+
+	switch {
+	case tags.RemainAttrs != nil:
+		attrs, _ := leftovers.JustAttributes()
+		attrsProvenance(prov, attrs, path)
+	case tags.Remain != nil:
+		field := ty.FieldByIndex(tags.Remain)
+		switch {
+		case bodyType.AssignableTo(field.Type):
+			// The body is decoded as-is, so there are no values yet.
+		case attrsType.AssignableTo(field.Type):
+			attrs, _ := leftovers.JustAttributes()
+			attrsProvenance(prov, attrs, path)
+		default:
+			bodyProvenance(prov, leftovers, field.Type, path)
+		}
+	}
+
+	typeNames := make([]string, 0, len(tags.Blocks))
+	for typeName := range tags.Blocks {
+		typeNames = append(typeNames, typeName)
+	}
+	sort.Strings(typeNames)
+
+	blocksByType := content.Blocks.ByType()
+	for _, typeName := range typeNames {
+		field := ty.FieldByIndex(tags.Blocks[typeName])
+		blocks := blocksByType[typeName]
+		blockPath := path.GetAttr(typeName)
+
+		if ifaceTy, ok := blockInterfaceType(field.Type); ok {
+			impls, _ := registeredBlockTypes(ifaceTy)
+			if typeName == "" {
+				blockPath = path
+				blocks = nil
+				for _, block := range content.Blocks {
+					if _, ok := impls[block.Type]; ok {
+						blocks = append(blocks, block)
+					}
+				}
+			}
+			interfaceBlocksProvenance(prov, blocks, typeName, impls, field.Type.Kind() == reflect.Slice, blockPath)
+			continue
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Map:
+			mapBlocksProvenance(prov, blocks, field.Type, blockPath)
+		case reflect.Slice:
+			for i, block := range blocks {
+				blockProvenance(prov, block, field.Type.Elem(), blockPath.Index(cty.NumberIntVal(int64(i))))
+			}
+		default:
+			// Several blocks of a type that allows only one are an error,
+			// in which case none of them are decoded.
+			if len(blocks) == 1 {
+				blockProvenance(prov, blocks[0], field.Type, blockPath)
+			}
+		}
+	}
+}
+
+// interfaceBlocksProvenance adds the entries for blocks decoded into a field
+// of an interface type, or a slice of one, as decodeBlocksToInterface does.
+func interfaceBlocksProvenance(prov *hcl.Provenance, blocks hcl.Blocks, typeName string, impls map[string]reflect.Type, isSlice bool, path cty.Path) {
+	if len(blocks) > 1 && !isSlice {
+		return
+	}
+
+	i := 0
+	for _, block := range blocks {
+		key := block.Type
+		if typeName != "" {
+			key = block.Labels[0]
+		}
+		implTy, ok := impls[key]
+		if !ok {
+			continue
+		}
+
+		elemPath := path
+		if isSlice {
+			elemPath = path.Index(cty.NumberIntVal(int64(i)))
+		}
+		blockProvenance(prov, block, implTy, elemPath)
+		i++
+	}
+}
+
+// mapBlocksProvenance adds the entries for blocks decoded into a map keyed
+// by their labels, as decodeBlocksToMap does.
+func mapBlocksProvenance(prov *hcl.Provenance, blocks hcl.Blocks, ty reflect.Type, path cty.Path) {
+	depth, elemTy := blockMapDepth(ty)
+	seen := make(map[string]struct{}, len(blocks))
+	for _, block := range blocks {
+		if len(block.Labels) < depth {
+			continue
+		}
+		key := strings.Join(block.Labels[:depth], "\x00")
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		elemPath := path
+		for _, label := range block.Labels[:depth] {
+			elemPath = elemPath.Index(cty.StringVal(label))
+		}
+		blockProvenance(prov, block, elemTy, elemPath)
+	}
+}
+
+// blockProvenance adds the entries for a single block decoded into a value
+// of the given type, which may be a pointer type.
+func blockProvenance(prov *hcl.Provenance, block *hcl.Block, ty reflect.Type, path cty.Path) {
+	if ty.Kind() == reflect.Ptr {
+		ty = ty.Elem()
+	}
+	if ty == blockType.Elem() || ty == bodyType || ty == attrsType {
+		// These are decoded as-is, so there are no values yet.
+		return
+	}
+
+	bodyProvenance(prov, block.Body, ty, path)
+
+	if ty.Kind() != reflect.Struct {
+		return
+	}
+	labelFields := getFieldTags(ty).Labels
+	for li := range block.Labels {
+		if li >= len(labelFields) || li >= len(block.LabelRanges) {
+			break
+		}
+		prov.Add(path.GetAttr(labelFields[li].Name), block.LabelRanges[li])
+	}
+}
+
+// attrsProvenance adds an entry for each of the given attributes, in order
+// of their names.
+func attrsProvenance(prov *hcl.Provenance, attrs hcl.Attributes, path cty.Path) {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prov.Add(path.GetAttr(name), attrs[name].Expr.Range())
+	}
+}
@@ -0,0 +1,134 @@
+package gohcl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecodeBodyWithProvenance(t *testing.T) {
+	type Listener struct {
+		Protocol string `hcl:"protocol,label"`
+		Port     int    `hcl:"port"`
+	}
+	type Config struct {
+		Name      string               `hcl:"name"`
+		Timeout   string               `hcl:"timeout,optional" default:"\"30s\""`
+		Listeners []Listener           `hcl:"listener,block"`
+		Logging   *struct{}            `hcl:"logging,block"`
+		Users     map[string]*Listener `hcl:"user,block"`
+		Backend   testBackend          `hcl:"backend,block"`
+		Tags      map[string]string    `hcl:",remainattrs"`
+	}
+
+	src := `name = "web"
+listener "http" {
+  port = 80
+}
+listener "https" {
+  port = 443
+}
+logging {
+}
+user "bob" {
+  port = 1
+}
+user "bob" {
+  port = 2
+}
+backend "local" {
+  path = "/tmp"
+}
+env = "prod"
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	var cfg Config
+	prov, diags := DecodeBodyWithProvenance(f.Body, nil, &cfg)
+	// The second user block is a duplicate.
+	if len(diags) != 1 {
+		t.Errorf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+
+	got := make([]string, len(prov))
+	for i, entry := range prov {
+		got[i] = fmt.Sprintf("%s %s", testPathString(entry.Path), entry.Range)
+	}
+	want := []string{
+		".name test.hcl:1,8-13",
+		".env test.hcl:19,7-13",
+		".backend.path test.hcl:17,10-16",
+		".backend.type test.hcl:16,9-16",
+		".listener[0].port test.hcl:3,10-12",
+		".listener[0].protocol test.hcl:2,10-16",
+		".listener[1].port test.hcl:6,10-13",
+		".listener[1].protocol test.hcl:5,10-17",
+		".user[\"bob\"].port test.hcl:11,10-11",
+		".user[\"bob\"].protocol test.hcl:10,6-11",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestDecodeBodyWithProvenanceBlockTypes(t *testing.T) {
+	type Config struct {
+		Notifiers []testNotifier `hcl:",block"`
+	}
+
+	src := `email "a@example.com" {
+}
+slack {
+  channel = "#alerts"
+}
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	var cfg Config
+	prov, diags := DecodeBodyWithProvenance(f.Body, nil, &cfg)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	got := make([]string, len(prov))
+	for i, entry := range prov {
+		got[i] = fmt.Sprintf("%s %s", testPathString(entry.Path), entry.Range)
+	}
+	want := []string{
+		"[0].address test.hcl:1,7-22",
+		"[1].channel test.hcl:4,13-22",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// testPathString returns a compact representation of the given path for
+// comparing in tests.
+func testPathString(path cty.Path) string {
+	var buf strings.Builder
+	for _, step := range path {
+		switch ts := step.(type) {
+		case cty.GetAttrStep:
+			fmt.Fprintf(&buf, ".%s", ts.Name)
+		case cty.IndexStep:
+			switch ts.Key.Type() {
+			case cty.String:
+				fmt.Fprintf(&buf, "[%q]", ts.Key.AsString())
+			case cty.Number:
+				fmt.Fprintf(&buf, "[%s]", ts.Key.AsBigFloat().String())
+			}
+		}
+	}
+	return buf.String()
+}
@@ -14,7 +14,8 @@ import (
 // inappropriate value is passed, this function will panic.
 //
 // The second return argument indicates whether the given struct includes
-// a "remain" field, and thus the returned schema is non-exhaustive.
+// a "remain" or "remainattrs" field, and thus the returned schema is
+// non-exhaustive.
 //
 // This uses the tags on the fields of the struct to discover how each
 // field's value should be expressed within configuration. If an invalid
@@ -43,7 +44,7 @@ func ImpliedBodySchema(val interface{}) (schema *hcl.BodySchema, partial bool) {
 	for _, n := range attrNames {
 		idx := tags.Attributes[n]
 		optional := tags.Optional[n]
-		field := ty.Field(idx)
+		field := ty.FieldByIndex(idx)
 
 		var required bool
 
@@ -53,6 +54,9 @@ func ImpliedBodySchema(val interface{}) (schema *hcl.BodySchema, partial bool) {
 			// indicated via a null value, so we don't specify that
 			// the field is required during decoding.
 			required = false
+		case tags.Defaults[n] != "":
+			// The default value will be used if the attribute is absent.
+			required = false
 		case field.Type.Kind() != reflect.Ptr && !optional:
 			required = true
 		default:
@@ -72,35 +76,68 @@ func ImpliedBodySchema(val interface{}) (schema *hcl.BodySchema, partial bool) {
 	sort.Strings(blockNames)
 	for _, n := range blockNames {
 		idx := tags.Blocks[n]
-		field := ty.Field(idx)
+		field := ty.FieldByIndex(idx)
+		if ifaceTy, ok := blockInterfaceType(field.Type); ok {
+			blockSchemas = append(blockSchemas, interfaceBlockSchemas(n, field, ifaceTy)...)
+			continue
+		}
+		if n == "" {
+			panic(fmt.Sprintf(
+				"hcl 'block' tag kind cannot be applied to %s field %s without a name: interface type required", field.Type.String(), field.Name,
+			))
+		}
 		fty := field.Type
-		if fty.Kind() == reflect.Slice {
+		mapDepth := 0
+		if fty.Kind() == reflect.Map {
+			mapDepth, fty = blockMapDepth(fty)
+		} else if fty.Kind() == reflect.Slice {
 			fty = fty.Elem()
 		}
 		if fty.Kind() == reflect.Ptr {
 			fty = fty.Elem()
 		}
-		if fty.Kind() != reflect.Struct {
+		if fty.Kind() != reflect.Struct && !reflect.PtrTo(fty).Implements(unmarshalerType) {
 			panic(fmt.Sprintf(
-				"hcl 'block' tag kind cannot be applied to %s field %s: struct required", field.Type.String(), field.Name,
+				"hcl 'block' tag kind cannot be applied to %s field %s: struct or Unmarshaler required", field.Type.String(), field.Name,
 			))
 		}
-		ftags := getFieldTags(fty)
 		var labelNames []string
-		if len(ftags.Labels) > 0 {
-			labelNames = make([]string, len(ftags.Labels))
-			for i, l := range ftags.Labels {
-				labelNames[i] = l.Name
+		if fty.Kind() == reflect.Struct {
+			// Only structs can have label fields. Other types are allowed
+			// only if they implement Unmarshaler, and can't accept labels.
+			ftags := getFieldTags(fty)
+			if len(ftags.Labels) > 0 {
+				labelNames = make([]string, len(ftags.Labels))
+				for i, l := range ftags.Labels {
+					labelNames[i] = l.Name
+				}
 			}
 		}
 
+		switch {
+		case mapDepth == 0:
+			// No additional constraints
+		case len(labelNames) == 0:
+			// The map keys are the only labels, so we'll synthesize some
+			// names for them to use in diagnostics.
+			if mapDepth == 1 {
+				labelNames = []string{"key"}
+			} else {
+				labelNames = []string{"key1", "key2"}
+			}
+		case len(labelNames) < mapDepth:
+			panic(fmt.Sprintf(
+				"hcl 'block' tag kind cannot be applied to %s field %s: element type must have either no label fields or at least %d", field.Type.String(), field.Name, mapDepth,
+			))
+		}
+
 		blockSchemas = append(blockSchemas, hcl.BlockHeaderSchema{
 			Type:       n,
 			LabelNames: labelNames,
 		})
 	}
 
-	partial = tags.Remain != nil
+	partial = tags.Remain != nil || tags.RemainAttrs != nil
 	schema = &hcl.BodySchema{
 		Attributes: attrSchemas,
 		Blocks:     blockSchemas,
@@ -108,26 +145,53 @@ func ImpliedBodySchema(val interface{}) (schema *hcl.BodySchema, partial bool) {
 	return schema, partial
 }
 
+// blockMapDepth returns the number of levels of map nesting in the given
+// block field type, which must be a map with string keys, along with the
+// type of the innermost map elements. At most two levels of map nesting are
+// supported, with deeper maps treated as the element type.
+func blockMapDepth(ty reflect.Type) (int, reflect.Type) {
+	depth := 0
+	for ty.Kind() == reflect.Map && depth < 2 {
+		if ty.Key().Kind() != reflect.String {
+			panic(fmt.Sprintf("hcl 'block' tag kind cannot be applied to %s: map key must be string", ty.String()))
+		}
+		depth++
+		ty = ty.Elem()
+	}
+	return depth, ty
+}
+
 type fieldTags struct {
-	Attributes map[string]int
-	Blocks     map[string]int
-	Labels     []labelField
-	Remain     *int
-	Optional   map[string]bool
-	OmitEmpty  map[string]bool
+	Attributes  map[string][]int
+	Blocks      map[string][]int
+	Labels      []labelField
+	Remain      []int
+	RemainAttrs []int
+	Optional    map[string]bool
+	OmitEmpty   map[string]bool
+	Defaults    map[string]string
+	Validate    map[string][]validationRule
+	Ranges      map[string][]int
 }
 
 type labelField struct {
-	FieldIndex int
+	FieldIndex []int
 	Name       string
 }
 
+// getFieldTags returns the field tags for the given struct type. The field
+// indices in the result are index sequences as accepted by
+// reflect.Value.FieldByIndex, since fields of embedded structs tagged as
+// "squash" are included as if they were declared in the given type itself.
 func getFieldTags(ty reflect.Type) *fieldTags {
 	ret := &fieldTags{
-		Attributes: map[string]int{},
-		Blocks:     map[string]int{},
+		Attributes: map[string][]int{},
+		Blocks:     map[string][]int{},
 		Optional:   map[string]bool{},
 		OmitEmpty:  map[string]bool{},
+		Defaults:   map[string]string{},
+		Validate:   map[string][]validationRule{},
+		Ranges:     map[string][]int{},
 	}
 
 	ct := ty.NumField()
@@ -156,29 +220,178 @@ func getFieldTags(ty reflect.Type) *fieldTags {
 			continue
 		}
 
+		if def := field.Tag.Get("default"); def != "" {
+			if kind != "attr" && kind != "optional" {
+				panic(fmt.Sprintf("'default' tag is only allowed for attributes, but %s %q is a %s", field.Type.String(), field.Name, kind))
+			}
+			ret.Defaults[name] = def
+		}
+
+		if rules := field.Tag.Get("validate"); rules != "" {
+			if kind != "attr" && kind != "optional" {
+				panic(fmt.Sprintf("'validate' tag is only allowed for attributes, but %s %q is a %s", field.Type.String(), field.Name, kind))
+			}
+			ret.Validate[name] = parseValidationRules(rules, field)
+		}
+
+		idx := []int{i}
+
+		switch kind {
+		case "attr", "block", "optional":
+			ret.checkSquashedDup(name, ty)
+		}
+
 		switch kind {
 		case "attr":
-			ret.Attributes[name] = i
+			ret.Attributes[name] = idx
 		case "block":
-			ret.Blocks[name] = i
+			ret.Blocks[name] = idx
 		case "label":
 			ret.Labels = append(ret.Labels, labelField{
-				FieldIndex: i,
+				FieldIndex: idx,
 				Name:       name,
 			})
 		case "remain":
 			if ret.Remain != nil {
 				panic("only one 'remain' tag is permitted")
 			}
-			idx := i // copy, because this loop will continue assigning to i
-			ret.Remain = &idx
+			ret.Remain = idx
+		case "remainattrs":
+			if ret.RemainAttrs != nil {
+				panic("only one 'remainattrs' tag is permitted")
+			}
+			if field.Type.Kind() != reflect.Map || field.Type.Key().Kind() != reflect.String {
+				panic(fmt.Sprintf("hcl 'remainattrs' tag kind cannot be applied to %s field %s: map with string keys required", field.Type.String(), field.Name))
+			}
+			ret.RemainAttrs = idx
 		case "optional":
-			ret.Attributes[name] = i
+			ret.Attributes[name] = idx
 			ret.Optional[name] = true
+		case "squash":
+			if !field.Anonymous || field.Type.Kind() != reflect.Struct {
+				panic(fmt.Sprintf("hcl 'squash' tag kind cannot be applied to %s field %s: embedded struct required", field.Type.String(), field.Name))
+			}
+			if name != "" {
+				panic(fmt.Sprintf("hcl 'squash' tag kind cannot be applied to %s field %s: name must be empty", field.Type.String(), field.Name))
+			}
+			ret.merge(getFieldTags(field.Type), idx, ty)
+		case "range":
+			if field.Type != rangeType && field.Type != rangesType {
+				panic(fmt.Sprintf("hcl 'range' tag kind cannot be applied to %s field %s: hcl.Range or []hcl.Range required", field.Type.String(), field.Name))
+			}
+			if _, exists := ret.Ranges[name]; exists {
+				panic(fmt.Sprintf("only one 'range' tag is permitted for %q", name))
+			}
+			ret.Ranges[name] = idx
 		default:
 			panic(fmt.Sprintf("invalid hcl field tag kind %q on %s %q", kind, field.Type.String(), field.Name))
 		}
 	}
 
+	for name, idx := range ret.Ranges {
+		field := ty.FieldByIndex(idx)
+		_, isAttr := ret.Attributes[name]
+		_, isBlock := ret.Blocks[name]
+		isLabel := false
+		for _, lf := range ret.Labels {
+			if lf.Name == name {
+				isLabel = true
+			}
+		}
+		switch {
+		case isBlock:
+			// Both hcl.Range and []hcl.Range are allowed for blocks.
+		case isAttr || isLabel:
+			if field.Type != rangeType {
+				panic(fmt.Sprintf("hcl 'range' tag kind cannot be applied to %s field %s: hcl.Range required for %q", field.Type.String(), field.Name, name))
+			}
+		default:
+			panic(fmt.Sprintf("hcl 'range' tag on field %s refers to %q, which is not an attribute, block or label field", field.Name, name))
+		}
+	}
+
+	if ret.Remain != nil && ret.RemainAttrs != nil {
+		panic("'remain' and 'remainattrs' tags cannot be used together")
+	}
+
 	return ret
 }
+
+// checkSquashedDup panics if the given attribute or block name, declared by a
+// field of struct type ty itself, was already declared by a field of an
+// embedded struct tagged as "squash". Together with the check made by merge,
+// this detects duplicates regardless of the order of the fields.
+func (t *fieldTags) checkSquashedDup(name string, ty reflect.Type) {
+	idx, exists := t.Attributes[name]
+	if !exists {
+		idx, exists = t.Blocks[name]
+	}
+	if exists && len(idx) > 1 {
+		// Only fields of squashed structs have index sequences longer
+		// than one.
+		panic(fmt.Sprintf("duplicate hcl name %q in %s after squashing embedded %s", name, ty.String(), ty.Field(idx[0]).Type.String()))
+	}
+}
+
+// merge adds the fields from the given tags, belonging to the embedded struct
+// at the given index within the struct type ty, to the receiver. It panics if
+// any of the given attribute or block names are already declared.
+func (t *fieldTags) merge(other *fieldTags, index []int, ty reflect.Type) {
+	prefixed := func(idx []int) []int {
+		ret := make([]int, 0, len(index)+len(idx))
+		ret = append(ret, index...)
+		return append(ret, idx...)
+	}
+	checkDup := func(name string) {
+		_, isAttr := t.Attributes[name]
+		_, isBlock := t.Blocks[name]
+		if isAttr || isBlock {
+			panic(fmt.Sprintf("duplicate hcl name %q in %s after squashing embedded %s", name, ty.String(), ty.FieldByIndex(index).Type.String()))
+		}
+	}
+
+	for name, idx := range other.Attributes {
+		checkDup(name)
+		t.Attributes[name] = prefixed(idx)
+	}
+	for name, idx := range other.Blocks {
+		checkDup(name)
+		t.Blocks[name] = prefixed(idx)
+	}
+	for _, lf := range other.Labels {
+		t.Labels = append(t.Labels, labelField{
+			FieldIndex: prefixed(lf.FieldIndex),
+			Name:       lf.Name,
+		})
+	}
+	if other.Remain != nil {
+		if t.Remain != nil {
+			panic("only one 'remain' tag is permitted")
+		}
+		t.Remain = prefixed(other.Remain)
+	}
+	if other.RemainAttrs != nil {
+		if t.RemainAttrs != nil {
+			panic("only one 'remainattrs' tag is permitted")
+		}
+		t.RemainAttrs = prefixed(other.RemainAttrs)
+	}
+	for name, v := range other.Optional {
+		t.Optional[name] = v
+	}
+	for name, v := range other.OmitEmpty {
+		t.OmitEmpty[name] = v
+	}
+	for name, v := range other.Defaults {
+		t.Defaults[name] = v
+	}
+	for name, v := range other.Validate {
+		t.Validate[name] = v
+	}
+	for name, idx := range other.Ranges {
+		if _, exists := t.Ranges[name]; exists {
+			panic(fmt.Sprintf("only one 'range' tag is permitted for %q", name))
+		}
+		t.Ranges[name] = prefixed(idx)
+	}
+}
@@ -207,6 +207,93 @@ func TestImpliedBodySchema(t *testing.T) {
 			},
 			false,
 		},
+		{
+			struct {
+				Port int `hcl:"port" default:"8080"`
+			}{},
+			&hcl.BodySchema{
+				Attributes: []hcl.AttributeSchema{
+					{
+						Name:     "port",
+						Required: false,
+					},
+				},
+			},
+			false,
+		},
+		{
+			struct {
+				Things map[string]struct {
+					Value string `hcl:"value"`
+				} `hcl:"thing,block"`
+			}{},
+			&hcl.BodySchema{
+				Blocks: []hcl.BlockHeaderSchema{
+					{
+						Type:       "thing",
+						LabelNames: []string{"key"},
+					},
+				},
+			},
+			false,
+		},
+		{
+			struct {
+				Things map[string]map[string]*struct {
+					Value string `hcl:"value"`
+				} `hcl:"thing,block"`
+			}{},
+			&hcl.BodySchema{
+				Blocks: []hcl.BlockHeaderSchema{
+					{
+						Type:       "thing",
+						LabelNames: []string{"key1", "key2"},
+					},
+				},
+			},
+			false,
+		},
+		{
+			struct {
+				Things map[string]struct {
+					Type string `hcl:"type,label"`
+					Name string `hcl:"name,label"`
+				} `hcl:"thing,block"`
+			}{},
+			&hcl.BodySchema{
+				Blocks: []hcl.BlockHeaderSchema{
+					{
+						Type:       "thing",
+						LabelNames: []string{"type", "name"},
+					},
+				},
+			},
+			false,
+		},
+		{
+			struct {
+				testSquashed `hcl:",squash"`
+				Attr2        bool `hcl:"attr2"`
+			}{},
+			&hcl.BodySchema{
+				Attributes: []hcl.AttributeSchema{
+					{
+						Name:     "attr1",
+						Required: true,
+					},
+					{
+						Name:     "attr2",
+						Required: true,
+					},
+				},
+				Blocks: []hcl.BlockHeaderSchema{
+					{
+						Type: "thing",
+					},
+				},
+			},
+			false,
+		},
 	}
 
 	for _, test := range tests {
@@ -228,3 +315,43 @@ func TestImpliedBodySchema(t *testing.T) {
 		})
 	}
 }
+
+type testSquashed struct {
+	Attr1  bool `hcl:"attr1"`
+	Things []struct {
+	} `hcl:"thing,block"`
+}
+
+func TestImpliedBodySchemaSquashDuplicate(t *testing.T) {
+	want := `duplicate hcl name "attr1" in %s after squashing embedded gohcl.testSquashed`
+	tests := map[string]interface{}{
+		"outer field first": &struct {
+			Attr1        string `hcl:"attr1"`
+			testSquashed `hcl:",squash"`
+		}{},
+		"outer field last": &struct {
+			testSquashed `hcl:",squash"`
+			Attr1        string `hcl:"attr1"`
+		}{},
+		"outer block last": &struct {
+			testSquashed `hcl:",squash"`
+			Attr1        []struct{} `hcl:"attr1,block"`
+		}{},
+		"outer optional last": &struct {
+			testSquashed `hcl:",squash"`
+			Attr1        *string `hcl:"attr1,optional"`
+		}{},
+	}
+
+	for name, val := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				want := fmt.Sprintf(want, reflect.TypeOf(val).Elem().String())
+				if got := recover(); got != want {
+					t.Errorf("wrong panic\ngot:  %#v\nwant: %#v", got, want)
+				}
+			}()
+			ImpliedBodySchema(val)
+		})
+	}
+}
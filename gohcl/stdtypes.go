@@ -0,0 +1,201 @@
+package gohcl
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// stringType describes a Go standard library type that is represented in
+// configuration as a string in a particular format, rather than using the
+// usual gocty mapping of its underlying Go type.
+type stringType struct {
+	// Summary is used as the summary of the diagnostic returned when a
+	// string cannot be parsed.
+	Summary string
+
+	// Parse converts the given string into a value of the Go type, or
+	// returns an error message suitable for inclusion in a diagnostic.
+	Parse func(s string) (interface{}, error)
+
+	// Format converts a value of the Go type back into a string.
+	Format func(v interface{}) string
+}
+
+var stringTypes = map[reflect.Type]stringType{
+	reflect.TypeOf(time.Duration(0)): {
+		Summary: "Invalid duration",
+		Parse: func(s string) (interface{}, error) {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return nil, fmt.Errorf("A duration must be a sequence of decimal numbers with unit suffixes, such as \"30s\" or \"1h15m\".")
+			}
+			return d, nil
+		},
+		Format: func(v interface{}) string {
+			return v.(time.Duration).String()
+		},
+	},
+	reflect.TypeOf(time.Time{}): {
+		Summary: "Invalid timestamp",
+		Parse: func(s string) (interface{}, error) {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return nil, fmt.Errorf("A timestamp must be written in RFC 3339 format, such as \"2006-01-02T15:04:05Z\".")
+			}
+			return t, nil
+		},
+		Format: func(v interface{}) string {
+			return v.(time.Time).Format(time.RFC3339Nano)
+		},
+	},
+	reflect.TypeOf(net.IP(nil)): {
+		Summary: "Invalid IP address",
+		Parse: func(s string) (interface{}, error) {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not a valid IPv4 or IPv6 address.", s)
+			}
+			return ip, nil
+		},
+		Format: func(v interface{}) string {
+			return v.(net.IP).String()
+		},
+	},
+	reflect.TypeOf(net.IPNet{}): {
+		Summary: "Invalid CIDR address",
+		Parse: func(s string) (interface{}, error) {
+			_, ipNet, err := net.ParseCIDR(s)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a valid IP address prefix in CIDR notation, such as \"10.0.0.0/8\".", s)
+			}
+			return *ipNet, nil
+		},
+		Format: func(v interface{}) string {
+			ipNet := v.(net.IPNet)
+			return ipNet.String()
+		},
+	},
+	reflect.TypeOf(url.URL{}): {
+		Summary: "Invalid URL",
+		Parse: func(s string) (interface{}, error) {
+			u, err := url.Parse(s)
+			if err != nil {
+				if urlErr, ok := err.(*url.Error); ok {
+					err = urlErr.Err
+				}
+				return nil, fmt.Errorf("%q is not a valid URL: %s.", s, err)
+			}
+			return *u, nil
+		},
+		Format: func(v interface{}) string {
+			u := v.(url.URL)
+			return u.String()
+		},
+	},
+}
+
+// decodeStringTypeExpression handles DecodeExpression for targets that are
+// pointers to one of the types in stringTypes, or pointers to pointers to
+// those types. The boolean result is false if the target is not of such a
+// type, in which case the caller must decode the expression some other way.
+func decodeStringTypeExpression(expr hcl.Expression, ctx *hcl.EvalContext, val interface{}) (hcl.Diagnostics, bool) {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Ptr {
+		return nil, false
+	}
+	rv = rv.Elem()
+	ty := rv.Type()
+	isPtr := false
+	if ty.Kind() == reflect.Ptr {
+		isPtr = true
+		ty = ty.Elem()
+	}
+	st, ok := stringTypes[ty]
+	if !ok {
+		return nil, false
+	}
+
+	finish := ctx.StartEvalExpression(expr)
+	srcVal, diags := expr.Value(ctx)
+	finish(diags)
+	if diags.HasErrors() {
+		return diags, true
+	}
+
+	srcVal, err := convert.Convert(srcVal, cty.String)
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsuitable value type",
+			Detail:   fmt.Sprintf("Unsuitable value: %s", err.Error()),
+			Subject:  expr.StartRange().Ptr(),
+			Context:  expr.Range().Ptr(),
+		})
+		return diags, true
+	}
+	if !srcVal.IsKnown() {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsuitable value type",
+			Detail:   "Unsuitable value: value must be known",
+			Subject:  expr.StartRange().Ptr(),
+			Context:  expr.Range().Ptr(),
+		})
+		return diags, true
+	}
+	if srcVal.IsNull() {
+		if !isPtr {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unsuitable value type",
+				Detail:   "Unsuitable value: null value is not allowed",
+				Subject:  expr.StartRange().Ptr(),
+				Context:  expr.Range().Ptr(),
+			})
+			return diags, true
+		}
+		rv.Set(reflect.Zero(rv.Type()))
+		return diags, true
+	}
+
+	result, err := st.Parse(srcVal.AsString())
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  st.Summary,
+			Detail:   err.Error(),
+			Subject:  expr.Range().Ptr(),
+		})
+		return diags, true
+	}
+
+	resultV := reflect.ValueOf(result)
+	if isPtr {
+		ptr := reflect.New(ty)
+		ptr.Elem().Set(resultV)
+		resultV = ptr
+	}
+	rv.Set(resultV)
+	return diags, true
+}
+
+// encodeStringType returns the string representation of the given value if
+// it is of one of the types in stringTypes. The boolean result is false if
+// the value is not of such a type.
+func encodeStringType(v reflect.Value) (cty.Value, bool) {
+	st, ok := stringTypes[v.Type()]
+	if !ok {
+		return cty.NilVal, false
+	}
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return cty.NullVal(cty.String), true
+	}
+	return cty.StringVal(st.Format(v.Interface())), true
+}
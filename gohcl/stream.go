@@ -0,0 +1,93 @@
+package gohcl
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// BlockDecoder decodes the blocks of a body one at a time, so that an
+// application processing a very large number of blocks can handle each one
+// in turn without retaining the decoded values of all of them at once.
+//
+// A BlockDecoder is used in a similar way to a bufio.Scanner:
+//
+//    dec, diags := gohcl.NewBlockDecoder(body, ctx, schema)
+//    for dec.Next() {
+//        var svc Service
+//        diags = append(diags, dec.Decode(&svc)...)
+//        // process svc
+//    }
+//
+// The underlying body must still be fully parsed before decoding begins, but
+// only the syntax tree is retained; each decoded value may be discarded or
+// reused once it has been processed.
+type BlockDecoder struct {
+	ctx     *hcl.EvalContext
+	content *hcl.BodyContent
+	next    int
+	current *hcl.Block
+}
+
+// NewBlockDecoder creates a BlockDecoder that iterates over the blocks in
+// the given body that conform to the given schema, in the order they
+// appear in the source. Diagnostics are returned for any content that does
+// not conform to the schema, in which case the decoder visits only the
+// blocks that could be recovered.
+//
+// The given EvalContext is used when decoding each block, and may be nil.
+func NewBlockDecoder(body hcl.Body, ctx *hcl.EvalContext, schema *hcl.BodySchema) (*BlockDecoder, hcl.Diagnostics) {
+	content, diags := body.Content(schema)
+	if content == nil {
+		content = &hcl.BodyContent{}
+	}
+	return &BlockDecoder{
+		ctx:     ctx,
+		content: content,
+	}, diags
+}
+
+// Next advances to the next block, returning false if there are no more
+// blocks. Next must be called before the first call to Block or Decode.
+func (d *BlockDecoder) Next() bool {
+	if d.next >= len(d.content.Blocks) {
+		d.current = nil
+		return false
+	}
+	d.current = d.content.Blocks[d.next]
+	// Release our reference to the block so that the caller alone
+	// determines how long it is retained.
+	d.content.Blocks[d.next] = nil
+	d.next++
+	return true
+}
+
+// Block returns the current block, or nil if Next has not been called or
+// has returned false.
+func (d *BlockDecoder) Block() *hcl.Block {
+	return d.current
+}
+
+// Decode decodes the current block into the given value, which must be a
+// pointer to a value that DecodeBody can decode into. Any label fields of
+// the target struct are populated from the block labels, as when decoding a
+// nested block with DecodeBody.
+//
+// Decode panics if there is no current block.
+func (d *BlockDecoder) Decode(val interface{}) hcl.Diagnostics {
+	if d.current == nil {
+		panic("Decode called without a current block")
+	}
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("target value must be a pointer, not %s", rv.Type().String()))
+	}
+	return decodeBlockToValue(d.current, d.ctx, rv.Elem())
+}
+
+// Attributes returns the attributes from the body that conform to the
+// schema given when creating the decoder.
+func (d *BlockDecoder) Attributes() hcl.Attributes {
+	return d.content.Attributes
+}
@@ -0,0 +1,112 @@
+package gohcl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+func TestBlockDecoder(t *testing.T) {
+	type Service struct {
+		Name string `hcl:"name,label"`
+		Port int    `hcl:"port"`
+	}
+
+	src := `
+version = 2
+
+service "a" {
+  port = 1
+}
+service "b" {
+  port = "nope"
+}
+service "c" {
+  port = 3
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("diagnostics while parsing: %s", diags.Error())
+	}
+
+	dec, diags := NewBlockDecoder(file.Body, nil, &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "version"},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "service", LabelNames: []string{"name"}},
+		},
+	})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	if dec.Block() != nil {
+		t.Errorf("have current block before first call to Next")
+	}
+	if _, ok := dec.Attributes()["version"]; !ok {
+		t.Errorf("version attribute is missing")
+	}
+
+	var got []Service
+	diagCount := 0
+	for dec.Next() {
+		if dec.Block().Type != "service" {
+			t.Errorf("wrong block type %q", dec.Block().Type)
+		}
+		var svc Service
+		diagCount += len(dec.Decode(&svc))
+		got = append(got, svc)
+	}
+
+	want := []Service{
+		{Name: "a", Port: 1},
+		{Name: "b"},
+		{Name: "c", Port: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if diagCount != 1 {
+		t.Errorf("wrong number of diagnostics %d; want 1", diagCount)
+	}
+	if dec.Next() {
+		t.Errorf("Next returned true after the final block")
+	}
+	if dec.Block() != nil {
+		t.Errorf("have current block after the final block")
+	}
+}
+
+func TestBlockDecoderInvalid(t *testing.T) {
+	src := `
+service "a" {
+}
+other {
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("diagnostics while parsing: %s", diags.Error())
+	}
+
+	dec, diags := NewBlockDecoder(file.Body, nil, &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "service", LabelNames: []string{"name"}},
+		},
+	})
+	if len(diags) != 1 {
+		t.Errorf("wrong number of diagnostics %d; want 1", len(diags))
+	}
+
+	count := 0
+	for dec.Next() {
+		count++
+	}
+	if count != 1 {
+		t.Errorf("wrong number of blocks %d; want 1", count)
+	}
+}
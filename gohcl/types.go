@@ -14,3 +14,5 @@ var bodyType = reflect.TypeOf(&victimBody).Elem()
 var blockType = reflect.TypeOf((*hcl.Block)(nil))
 var attrType = reflect.TypeOf((*hcl.Attribute)(nil))
 var attrsType = reflect.TypeOf(hcl.Attributes(nil))
+var rangeType = reflect.TypeOf(hcl.Range{})
+var rangesType = reflect.TypeOf([]hcl.Range(nil))
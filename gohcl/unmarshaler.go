@@ -0,0 +1,66 @@
+package gohcl
+
+import (
+	"reflect"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// Unmarshaler is the interface implemented by types that can decode
+// themselves from a body.
+//
+// When DecodeBody encounters a value whose pointer type implements this
+// interface, whether as the top-level target, as the target for a nested
+// block, or as a "remain" field, it calls UnmarshalHCL instead of applying
+// the usual struct tag conventions. This allows types with bespoke decoding
+// requirements to participate in decoding of a larger structure.
+//
+// UnmarshalHCL is responsible for consuming the entire body it is given,
+// typically via a call to Content, so that any unexpected content will be
+// reported. It must not call DecodeBody with its own receiver, since that
+// would recurse infinitely; a common pattern is to decode into a value of
+// a distinct type with the same underlying struct type and then convert.
+type Unmarshaler interface {
+	UnmarshalHCL(body hcl.Body, ctx *hcl.EvalContext) hcl.Diagnostics
+}
+
+// ExpressionUnmarshaler is the interface implemented by types that can decode
+// themselves from an expression.
+//
+// When DecodeExpression, or DecodeBody when processing an attribute,
+// encounters a target whose pointer type implements this interface, it calls
+// UnmarshalHCLExpression instead of converting the expression's value using
+// gocty. A pointer field of such a type is allocated before unmarshaling. The implementation is free to either evaluate the expression in the
+// given context or analyze it statically.
+type ExpressionUnmarshaler interface {
+	UnmarshalHCLExpression(expr hcl.Expression, ctx *hcl.EvalContext) hcl.Diagnostics
+}
+
+var unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+var exprUnmarshalerType = reflect.TypeOf((*ExpressionUnmarshaler)(nil)).Elem()
+
+// asUnmarshaler returns the Unmarshaler implementation for the given value,
+// if its pointer type implements Unmarshaler. The value must be addressable
+// for a result to be returned.
+func asUnmarshaler(val reflect.Value) (Unmarshaler, bool) {
+	if !val.CanAddr() {
+		return nil, false
+	}
+	u, ok := val.Addr().Interface().(Unmarshaler)
+	return u, ok
+}
+
+// exprTarget returns the value that DecodeExpression should decode into in
+// order to populate the given addressable value. This is usually the value's
+// address, but a pointer whose type implements ExpressionUnmarshaler is
+// allocated if necessary and used directly, so that a field of type *T can
+// be unmarshaled by T.
+func exprTarget(val reflect.Value) interface{} {
+	if val.Kind() == reflect.Ptr && val.Type().Implements(exprUnmarshalerType) {
+		if val.IsNil() {
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		return val.Interface()
+	}
+	return val.Addr().Interface()
+}
@@ -0,0 +1,182 @@
+package gohcl
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// Validator is the interface implemented by attribute value types that can
+// check their own values for validity after decoding.
+//
+// If the type of a field decoded from an attribute implements Validator,
+// either directly or via a pointer receiver, DecodeBody calls ValidateHCL
+// after successfully decoding the attribute value. A non-nil error is
+// returned to the user as an error diagnostic referring to the attribute's
+// expression, so it should be written with the configuration author as the
+// target audience.
+type Validator interface {
+	ValidateHCL() error
+}
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// validationRule is a single constraint parsed from a "validate" struct tag.
+type validationRule struct {
+	Kind    string // "min", "max" or "oneof"
+	Limit   float64
+	Options []string
+}
+
+// parseValidationRules parses the value of a "validate" struct tag for the
+// given field, which is a comma-separated sequence of rules:
+//
+//    min=N     the value must be at least N, or for strings and collections
+//              the length must be at least N
+//    max=N     the value must be at most N, or for strings and collections
+//              the length must be at most N
+//    oneof=A B the value must be one of the given space-separated strings
+//
+// Invalid rules are bugs in the calling program, and so cause a panic.
+func parseValidationRules(tag string, field reflect.StructField) []validationRule {
+	ty := field.Type
+	if ty.Kind() == reflect.Ptr {
+		ty = ty.Elem()
+	}
+
+	var ret []validationRule
+	for _, raw := range strings.Split(tag, ",") {
+		eq := strings.Index(raw, "=")
+		if eq == -1 {
+			panic(fmt.Sprintf("invalid validation rule %q on %s field %s: must be name=value", raw, field.Type.String(), field.Name))
+		}
+		rule := validationRule{
+			Kind: raw[:eq],
+		}
+		arg := raw[eq+1:]
+
+		switch rule.Kind {
+		case "min", "max":
+			if _, ok := validationMagnitude(reflect.Zero(ty)); !ok {
+				panic(fmt.Sprintf("invalid validation rule %q on %s field %s: type has no magnitude", raw, field.Type.String(), field.Name))
+			}
+			limit, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				panic(fmt.Sprintf("invalid validation rule %q on %s field %s: %s", raw, field.Type.String(), field.Name, err))
+			}
+			rule.Limit = limit
+		case "oneof":
+			if ty.Kind() != reflect.String {
+				panic(fmt.Sprintf("invalid validation rule %q on %s field %s: only strings are supported", raw, field.Type.String(), field.Name))
+			}
+			rule.Options = strings.Fields(arg)
+		default:
+			panic(fmt.Sprintf("invalid validation rule %q on %s field %s: unknown rule %q", raw, field.Type.String(), field.Name, rule.Kind))
+		}
+
+		ret = append(ret, rule)
+	}
+	return ret
+}
+
+// validationMagnitude returns the value that "min" and "max" rules are
+// compared with for the given value: the value itself for numbers, or the
+// length for strings and collections.
+func validationMagnitude(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), true
+	default:
+		return 0, false
+	}
+}
+
+// validateAttribute checks the given decoded attribute value against the
+// given rules and, if applicable, its Validator implementation, returning
+// error diagnostics for any failures.
+func validateAttribute(attr *hcl.Attribute, rules []validationRule, v reflect.Value) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return diags
+		}
+		v = v.Elem()
+	}
+
+	for _, rule := range rules {
+		var problem string
+
+		switch rule.Kind {
+		case "min", "max":
+			mag, _ := validationMagnitude(v)
+			what := "be"
+			switch v.Kind() {
+			case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+				what = "have a length of"
+			}
+			if rule.Kind == "min" && mag < rule.Limit {
+				problem = fmt.Sprintf("must %s at least %s", what, strconv.FormatFloat(rule.Limit, 'f', -1, 64))
+			}
+			if rule.Kind == "max" && mag > rule.Limit {
+				problem = fmt.Sprintf("must %s at most %s", what, strconv.FormatFloat(rule.Limit, 'f', -1, 64))
+			}
+		case "oneof":
+			found := false
+			for _, opt := range rule.Options {
+				if v.String() == opt {
+					found = true
+					break
+				}
+			}
+			if !found {
+				quoted := make([]string, len(rule.Options))
+				for i, opt := range rule.Options {
+					quoted[i] = strconv.Quote(opt)
+				}
+				problem = fmt.Sprintf("must be one of %s", strings.Join(quoted, ", "))
+			}
+		}
+
+		if problem != "" {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid value",
+				Detail:   fmt.Sprintf("The value of argument %q %s.", attr.Name, problem),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+
+	var validator Validator
+	switch {
+	case v.Type().Implements(validatorType):
+		validator = v.Interface().(Validator)
+	case v.CanAddr() && reflect.PtrTo(v.Type()).Implements(validatorType):
+		validator = v.Addr().Interface().(Validator)
+	}
+	if validator != nil {
+		if err := validator.ValidateHCL(); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid value",
+				Detail:   fmt.Sprintf("Invalid value for argument %q: %s.", attr.Name, err),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,57 @@
+package hcl
+
+import (
+	"sort"
+)
+
+// InSourceOrder returns the attributes in the receiver as a slice ordered by
+// their position in the configuration source.
+//
+// Attributes are ordered first by the filename of their range and then by
+// their starting byte offset within that file. Since filenames are compared
+// lexically, the relative order of attributes from different files is
+// stable but not necessarily meaningful; use AttributesInOrder with a merged
+// body to preserve the order in which files were merged.
+func (a Attributes) InSourceOrder() []*Attribute {
+	ret := make([]*Attribute, 0, len(a))
+	for _, attr := range a {
+		ret = append(ret, attr)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		ri, rj := ret[i].Range, ret[j].Range
+		if ri.Filename != rj.Filename {
+			return ri.Filename < rj.Filename
+		}
+		if ri.Start.Byte != rj.Start.Byte {
+			return ri.Start.Byte < rj.Start.Byte
+		}
+		// Attributes with identical ranges are synthetic, so we fall back
+		// on the name just to keep the result deterministic.
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+// AttributesInOrder is a helper function that behaves like the
+// JustAttributes method of the given body, but returns the attributes as a
+// slice in the order they were defined rather than as a map. This is useful
+// for callers that need a stable ordering, such as formatters, documentation
+// generators and serializers.
+//
+// Bodies can customize the ordering by offering a method called
+// JustAttributesInOrder that takes no arguments and returns the same results
+// as this function. The bodies returned by MergeBodies and MergeFiles do so,
+// returning the attributes of each merged body in turn. For any other body,
+// the result of JustAttributes is sorted with Attributes.InSourceOrder.
+func AttributesInOrder(body Body) ([]*Attribute, Diagnostics) {
+	type orderedAttributes interface {
+		JustAttributesInOrder() ([]*Attribute, Diagnostics)
+	}
+
+	if ob, supported := body.(orderedAttributes); supported {
+		return ob.JustAttributesInOrder()
+	}
+
+	attrs, diags := body.JustAttributes()
+	return attrs.InSourceOrder(), diags
+}
@@ -0,0 +1,94 @@
+package hcl
+
+import (
+	"io"
+)
+
+// Bundle associates diagnostics with the files they refer to, so that both
+// can be passed together between the stages of a tool, such as from the
+// code that loads configuration to the code that reports problems with it,
+// without those stages needing to share a parser.
+//
+// The zero value of Bundle is an empty bundle, ready to use. A Bundle is not
+// safe for concurrent use by multiple goroutines.
+type Bundle struct {
+	// Files are the files that the diagnostics may refer to, keyed by
+	// filename. These must not be modified.
+	Files map[string]*File
+
+	// Diagnostics are the diagnostics accumulated so far, in the order they
+	// were added.
+	Diagnostics Diagnostics
+}
+
+// AddFile adds the given file to the bundle under the given filename, along
+// with any diagnostics from parsing it, and returns the file. The file may
+// be nil, such as if it could not be read, in which case only the
+// diagnostics are added.
+//
+// This is designed to wrap a call to a parser, like this:
+//
+//     file := bundle.AddFile(filename, hclsyntax.ParseConfig(src, filename, pos))
+//
+// If the bundle already has a file of the same name then it is replaced.
+func (b *Bundle) AddFile(filename string, file *File, diags Diagnostics) *File {
+	if file != nil {
+		if b.Files == nil {
+			b.Files = make(map[string]*File)
+		}
+		b.Files[filename] = file
+	}
+	b.Diagnostics = append(b.Diagnostics, diags...)
+	return file
+}
+
+// AddFiles adds each of the given files to the bundle, as if by AddFile.
+// This can be used with the result of hclparse.Parser.FilesSnapshot.
+func (b *Bundle) AddFiles(files map[string]*File) {
+	for filename, file := range files {
+		b.AddFile(filename, file, nil)
+	}
+}
+
+// AddDiagnostics appends the given diagnostics to those of the bundle.
+func (b *Bundle) AddDiagnostics(diags Diagnostics) {
+	b.Diagnostics = append(b.Diagnostics, diags...)
+}
+
+// Merge adds the files and diagnostics of the given bundle to the receiver.
+// The diagnostics of the other bundle are appended after those of the
+// receiver, and its files replace any of the receiver's files of the same
+// names. The other bundle is not modified.
+func (b *Bundle) Merge(other *Bundle) {
+	b.AddFiles(other.Files)
+	b.AddDiagnostics(other.Diagnostics)
+}
+
+// HasErrors returns true if any of the bundle's diagnostics are errors.
+func (b *Bundle) HasErrors() bool {
+	return b.Diagnostics.HasErrors()
+}
+
+// Sources returns a new map from the filenames of the bundle's files to
+// their source code. The arrays underlying the returned slices must not be
+// modified.
+func (b *Bundle) Sources() map[string][]byte {
+	ret := make(map[string][]byte, len(b.Files))
+	for filename, file := range b.Files {
+		ret[filename] = file.Bytes
+	}
+	return ret
+}
+
+// WriteText writes the bundle's diagnostics to the given writer using a
+// writer created by NewDiagnosticTextWriter, which is given the bundle's
+// files so that it can include source code snippets.
+func (b *Bundle) WriteText(wr io.Writer, width uint, color bool) error {
+	return NewDiagnosticTextWriter(wr, b.Files, width, color).WriteDiagnostics(b.Diagnostics)
+}
+
+// WriteJSON writes the bundle's diagnostics to the given writer using a
+// writer created by NewDiagnosticJSONWriter.
+func (b *Bundle) WriteJSON(wr io.Writer) error {
+	return NewDiagnosticJSONWriter(wr).WriteDiagnostics(b.Diagnostics)
+}
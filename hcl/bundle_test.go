@@ -0,0 +1,67 @@
+package hcl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	fileA := &File{Bytes: []byte("a = 1\n")}
+	fileB := &File{Bytes: []byte("b = 2\n")}
+	rng := Range{
+		Filename: "b.hcl",
+		Start:    Pos{Line: 1, Column: 1, Byte: 0},
+		End:      Pos{Line: 1, Column: 2, Byte: 1},
+	}
+
+	var first Bundle
+	if got := first.AddFile("a.hcl", fileA, nil); got != fileA {
+		t.Errorf("AddFile did not return the given file")
+	}
+	first.AddFile("missing.hcl", nil, Diagnostics{{
+		Severity: DiagError,
+		Summary:  "Failed to read file",
+	}})
+
+	var second Bundle
+	second.AddFile("b.hcl", fileB, nil)
+	second.AddDiagnostics(Diagnostics{{
+		Severity: DiagWarning,
+		Summary:  "Unused argument",
+		Subject:  &rng,
+	}})
+
+	first.Merge(&second)
+	if len(first.Files) != 2 || first.Files["a.hcl"] != fileA || first.Files["b.hcl"] != fileB {
+		t.Errorf("wrong files %#v", first.Files)
+	}
+	if len(first.Diagnostics) != 2 || first.Diagnostics[0].Summary != "Failed to read file" || first.Diagnostics[1].Summary != "Unused argument" {
+		t.Errorf("wrong diagnostics\n%s", first.Diagnostics.Error())
+	}
+	if !first.HasErrors() {
+		t.Errorf("HasErrors returned false")
+	}
+	if second.HasErrors() || len(second.Files) != 1 {
+		t.Errorf("merge modified the other bundle")
+	}
+	if got := string(first.Sources()["b.hcl"]); got != "b = 2\n" {
+		t.Errorf("wrong source %q", got)
+	}
+
+	var buf bytes.Buffer
+	if err := first.WriteText(&buf, 0, false); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := buf.String(); !strings.Contains(got, "b = 2") {
+		t.Errorf("text output does not include source snippet:\n%s", got)
+	}
+
+	buf.Reset()
+	if err := first.WriteJSON(&buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 2 {
+		t.Errorf("wrong number of JSON lines %d:\n%s", got, buf.String())
+	}
+}
@@ -0,0 +1,99 @@
+package hcl
+
+import (
+	"fmt"
+)
+
+// DiagnosticCategoryDeprecation is the Category of the diagnostics returned
+// by DeprecationDiagnostics.
+const DiagnosticCategoryDeprecation = "deprecation"
+
+// DeprecationDiagnostics returns a warning for each of the attributes and
+// blocks in the given content that the given schema marks as deprecated.
+//
+// The Body implementations in this module include these warnings in the
+// results of their Content and PartialContent methods, so applications don't
+// usually need to call this function. It is exported for the benefit of
+// other Body implementations.
+//
+// If the schema gives a replacement for a deprecated item then the warning
+// suggests replacing the name range of the attribute, or the type range of
+// the block, with the bare replacement name, as is appropriate for the
+// native syntax.
+func DeprecationDiagnostics(schema *BodySchema, content *BodyContent) Diagnostics {
+	var diags Diagnostics
+
+	for _, attrS := range schema.Attributes {
+		if attrS.Deprecated == "" {
+			continue
+		}
+		attr, exists := content.Attributes[attrS.Name]
+		if !exists {
+			continue
+		}
+		diags = append(diags, deprecationDiagnostic(
+			"Deprecated argument",
+			DiagDeprecatedArgument,
+			fmt.Sprintf("The argument %q is deprecated. %s", attrS.Name, attrS.Deprecated),
+			attrS.ReplacedBy,
+			attr.NameRange,
+			attr.Range,
+		))
+	}
+
+	deprecated := make(map[string]BlockHeaderSchema)
+	for _, blockS := range schema.Blocks {
+		if blockS.Deprecated != "" {
+			deprecated[blockS.Type] = blockS
+		}
+	}
+	if len(deprecated) == 0 {
+		return diags
+	}
+	reported := make(map[Range]bool)
+	for _, block := range content.Blocks {
+		blockS, isDeprecated := deprecated[block.Type]
+		if !isDeprecated || reported[block.TypeRange] {
+			// In the JSON syntax, several blocks can share a single
+			// property name, which we report only once.
+			continue
+		}
+		reported[block.TypeRange] = true
+		diags = append(diags, deprecationDiagnostic(
+			"Deprecated block type",
+			DiagDeprecatedBlockType,
+			fmt.Sprintf("Blocks of type %q are deprecated. %s", blockS.Type, blockS.Deprecated),
+			blockS.ReplacedBy,
+			block.TypeRange,
+			block.DefRange,
+		))
+	}
+
+	return diags
+}
+
+func deprecationDiagnostic(summary, code, detail, replacedBy string, subject, context Range) *Diagnostic {
+	diag := &Diagnostic{
+		Severity: DiagWarning,
+		Summary:  summary,
+		Code:     code,
+		Category: DiagnosticCategoryDeprecation,
+		Detail:   detail,
+		Subject:  subject.Ptr(),
+		Context:  context.Ptr(),
+	}
+	if replacedBy != "" {
+		diag.Suggestions = []Suggestion{
+			{
+				Description: fmt.Sprintf("Rename to %q", replacedBy),
+				Edits: []Edit{
+					{
+						Range:       subject,
+						Replacement: replacedBy,
+					},
+				},
+			},
+		}
+	}
+	return diag
+}
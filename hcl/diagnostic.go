@@ -19,6 +19,11 @@ const (
 	// user attention but does not prevent further progress. It is most
 	// commonly used for showing deprecation notices.
 	DiagWarning
+
+	// DiagInfo indicates that a diagnostic is purely informational, such as
+	// a suggestion from a linter, and does not necessarily indicate a
+	// problem at all.
+	DiagInfo
 )
 
 // Diagnostic represents information to be presented to a user about an
@@ -33,6 +38,22 @@ type Diagnostic struct {
 	Summary string
 	Detail  string
 
+	// Category is an optional short, machine-readable string that groups
+	// related diagnostics together, such as "deprecation" or "style", so
+	// that callers can filter or present them differently. The set of
+	// categories is defined by the application or tool that produces the
+	// diagnostics. It is empty for diagnostics produced by HCL itself.
+	Category string
+
+	// Code is an optional stable identifier for the specific kind of problem
+	// the diagnostic describes, such as "HCL1029" for an unterminated string
+	// literal, allowing callers to recognize particular diagnostics without
+	// matching on their English-language descriptions. The codes used by
+	// the diagnostics produced by HCL itself are defined as constants with
+	// names beginning with "Diag", such as DiagUnterminatedStringLiteral.
+	// Applications may define their own codes for their own diagnostics.
+	Code string
+
 	// Subject and Context are both source ranges relating to the diagnostic.
 	//
 	// Subject is a tight range referring to exactly the construct that
@@ -46,6 +67,10 @@ type Diagnostic struct {
 	Subject *Range
 	Context *Range
 
+	// Suggestions are optional changes to the source code that would
+	// resolve the problem, in order of preference. See Suggestion.
+	Suggestions []Suggestion
+
 	// For diagnostics that occur when evaluating an expression, Expression
 	// may refer to that expression and EvalContext may point to the
 	// EvalContext that was active when evaluating it. This may allow for the
@@ -63,6 +88,14 @@ type Diagnostic struct {
 	// case of colliding names.
 	Expression  Expression
 	EvalContext *EvalContext
+
+	// Extra is an optional field for additional information about the
+	// diagnostic, intended for callers that know how to interpret it rather
+	// than for presentation to the user. Its type depends on the component
+	// that produced the diagnostic, so callers should use type assertions
+	// against interfaces documented by that component, such as
+	// FunctionCallDiagExtra in package hclsyntax.
+	Extra interface{}
 }
 
 // Diagnostics is a list of Diagnostic instances.
@@ -115,7 +148,7 @@ func (d Diagnostics) Extend(diags Diagnostics) Diagnostics {
 }
 
 // HasErrors returns true if the receiver contains any diagnostics of
-// severity DiagError.
+// severity DiagError. Warnings and informational diagnostics are ignored.
 func (d Diagnostics) HasErrors() bool {
 	for _, diag := range d {
 		if diag.Severity == DiagError {
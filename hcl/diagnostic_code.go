@@ -0,0 +1,157 @@
+package hcl
+
+// The following are the codes of the diagnostics produced by the parsers,
+// evaluators and body implementations in this module, for use in the Code
+// field of Diagnostic.
+//
+// Codes are stable: once assigned, a code will not be reused for a different
+// kind of problem even if the diagnostic it identifies is later removed, and
+// so applications may safely compare against these constants, or record the
+// code strings themselves in configuration, in order to handle or suppress
+// specific kinds of problem. The summary and detail messages of a
+// diagnostic, on the other hand, may change in any release.
+//
+// The codes are grouped by the component that produces them, with each group
+// using a different range of numbers.
+const (
+	// Native syntax parsing, produced by package hclsyntax.
+	DiagUnsupportedOperator                         = "HCL1001"
+	DiagInvalidCharacter                            = "HCL1002"
+	DiagInvalidCharacterEncoding                    = "HCL1003"
+	DiagInvalidMultiLineString                      = "HCL1004"
+	DiagAttributeRedefined                          = "HCL1005"
+	DiagInvalidArgumentName                         = "HCL1006"
+	DiagArgumentOrBlockDefinitionRequired           = "HCL1007"
+	DiagArgumentDefinitionRequired                  = "HCL1008"
+	DiagInvalidBlockDefinition                      = "HCL1009"
+	DiagInvalidSingleArgumentBlockDefinition        = "HCL1010"
+	DiagMissingNewlineAfterBlockDefinition          = "HCL1011"
+	DiagMissingFalseExpressionInConditional         = "HCL1012"
+	DiagInvalidLegacyIndexSyntax                    = "HCL1013"
+	DiagNestedSplatExpressionNotAllowed             = "HCL1014"
+	DiagInvalidAttributeName                        = "HCL1015"
+	DiagMissingCloseBracketOnSplatIndex             = "HCL1016"
+	DiagMissingCloseBracketOnIndex                  = "HCL1017"
+	DiagUnbalancedParentheses                       = "HCL1018"
+	DiagInvalidExpression                           = "HCL1019"
+	DiagInvalidNumberLiteral                        = "HCL1020"
+	DiagMissingClosingParenthesis                   = "HCL1021"
+	DiagMissingArgumentSeparator                    = "HCL1022"
+	DiagMissingItemSeparator                        = "HCL1023"
+	DiagMissingAttributeValue                       = "HCL1024"
+	DiagMissingKeyValueSeparator                    = "HCL1025"
+	DiagMissingAttributeSeparator                   = "HCL1026"
+	DiagInvalidForExpression                        = "HCL1027"
+	DiagInvalidStringLiteral                        = "HCL1028"
+	DiagUnterminatedStringLiteral                   = "HCL1029"
+	DiagInvalidEscapeSequence                       = "HCL1030"
+	DiagUnexpectedEndOfTemplate                     = "HCL1031"
+	DiagUnexpectedTemplateDirective                 = "HCL1032"
+	DiagUnexpectedElseDirective                     = "HCL1033"
+	DiagExtraCharactersAfterInterpolationExpression = "HCL1034"
+	DiagInvalidTemplateDirective                    = "HCL1035"
+	DiagInvalidForDirective                         = "HCL1036"
+	DiagInvalidTemplateControlKeyword               = "HCL1037"
+	DiagExtraCharactersInStripMarker                = "HCL1038"
+	DiagUnterminatedTemplateString                  = "HCL1039"
+	DiagVariableNameRequired                        = "HCL1040"
+	DiagAttributeNameRequired                       = "HCL1041"
+	DiagUnclosedIndexBrackets                       = "HCL1042"
+	DiagIndexValueRequired                          = "HCL1043"
+	DiagExtraCharactersAfterExpression              = "HCL1044"
+	DiagInvalidFunctionName                         = "HCL1045"
+	DiagMissingFunctionCall                         = "HCL1046"
+
+	// Native syntax expression evaluation, produced by package hclsyntax.
+	DiagFunctionCallsNotAllowed            = "HCL2001"
+	DiagCallToUnknownFunction              = "HCL2002"
+	DiagInvalidExpandingArgumentValue      = "HCL2003"
+	DiagNotEnoughFunctionArguments         = "HCL2004"
+	DiagTooManyFunctionArguments           = "HCL2005"
+	DiagInvalidFunctionArgument            = "HCL2006"
+	DiagErrorInFunctionCall                = "HCL2007"
+	DiagInconsistentConditionalResultTypes = "HCL2008"
+	DiagNullCondition                      = "HCL2009"
+	DiagIncorrectConditionType             = "HCL2010"
+	DiagNullValueAsKey                     = "HCL2011"
+	DiagIncorrectKeyType                   = "HCL2012"
+	DiagAmbiguousAttributeKey              = "HCL2013"
+	DiagIterationOverNullValue             = "HCL2014"
+	DiagIterationOverNonIterableValue      = "HCL2015"
+	DiagConditionIsNull                    = "HCL2016"
+	DiagInvalidForCondition                = "HCL2017"
+	DiagInvalidObjectKey                   = "HCL2018"
+	DiagDuplicateObjectKey                 = "HCL2019"
+	DiagSplatOfNullValue                   = "HCL2020"
+	DiagInvalidOperand                     = "HCL2021"
+	DiagOperationFailed                    = "HCL2022"
+	DiagInvalidTemplateInterpolationValue  = "HCL2023"
+	DiagInterpolationOnlyTemplate          = "HCL2024"
+	DiagTemplateTooLong                    = "HCL2025"
+	DiagTooManyForElements                 = "HCL2026"
+
+	// JSON syntax parsing, produced by package json. DiagFileReadFailed is
+	// also produced by package hclparse for any file that cannot be read.
+	DiagJSONRootNotObject            = "HCL3001"
+	DiagFileOpenFailed               = "HCL3002"
+	DiagFileReadFailed               = "HCL3003"
+	DiagExtraneousJSONData           = "HCL3004"
+	DiagMissingJSONValue             = "HCL3005"
+	DiagMissingJSONArrayElement      = "HCL3006"
+	DiagUnexpectedEndOfJSON          = "HCL3007"
+	DiagInvalidStartOfJSONValue      = "HCL3008"
+	DiagInvalidJSONPropertyName      = "HCL3009"
+	DiagMissingJSONObjectValue       = "HCL3010"
+	DiagMissingJSONPropertyColon     = "HCL3011"
+	DiagTrailingCommaInJSONObject    = "HCL3012"
+	DiagUnclosedJSONContainer        = "HCL3013"
+	DiagMismatchedJSONBraces         = "HCL3014"
+	DiagMissingJSONPropertySeparator = "HCL3015"
+	DiagTrailingCommaInJSONArray     = "HCL3016"
+	DiagInvalidJSONArrayValue        = "HCL3017"
+	DiagMismatchedJSONBrackets       = "HCL3018"
+	DiagInvalidJSONNumber            = "HCL3019"
+	DiagInvalidJSONString            = "HCL3020"
+	DiagInvalidJSONKeyword           = "HCL3021"
+
+	// Body content decoding, produced by the Body implementations
+	// in this module.
+	DiagUnsupportedArgument      = "HCL4001"
+	DiagUnsupportedBlockType     = "HCL4002"
+	DiagMissingRequiredArgument  = "HCL4003"
+	DiagExtraneousBlockLabel     = "HCL4004"
+	DiagMissingBlockLabel        = "HCL4005"
+	DiagUnexpectedBlock          = "HCL4006"
+	DiagExtraneousJSONProperty   = "HCL4007"
+	DiagDuplicateArgument        = "HCL4008"
+	DiagIncorrectJSONValueType   = "HCL4009"
+	DiagInvalidJSONKeyExpression = "HCL4010"
+	DiagDuplicateJSONProperty    = "HCL4011"
+	DiagDeprecatedArgument       = "HCL4012"
+	DiagDeprecatedBlockType      = "HCL4013"
+	DiagMissingOverrideBase      = "HCL4014"
+
+	// Traversals and static analysis, produced by this package.
+	DiagAttemptToIndexNullValue            = "HCL5001"
+	DiagInvalidIndex                       = "HCL5002"
+	DiagAttemptToGetAttributeFromNullValue = "HCL5003"
+	DiagUnsupportedAttribute               = "HCL5004"
+	DiagMissingMapElement                  = "HCL5005"
+	DiagInvalidPathStep                    = "HCL5006"
+	DiagVariablesNotAllowed                = "HCL5007"
+	DiagUnknownVariable                    = "HCL5008"
+	DiagStaticExpressionRequired           = "HCL5009"
+
+	// Dynamic block expansion, produced by package ext/dynblock.
+	DiagInvalidDynamicForEach          = "HCL6001"
+	DiagInvalidDynamicIteratorName     = "HCL6002"
+	DiagExtraneousDynamicBlockLabel    = "HCL6003"
+	DiagInsufficientDynamicBlockLabels = "HCL6004"
+	DiagMissingDynamicContentBlock     = "HCL6005"
+	DiagExtraneousDynamicContentBlock  = "HCL6006"
+	DiagInvalidDynamicBlockLabel       = "HCL6007"
+
+	// File inclusion, produced by package ext/include.
+	DiagIncludeCycle       = "HCL6101"
+	DiagInvalidIncludePath = "HCL6102"
+)
@@ -0,0 +1,78 @@
+package hcl
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Suggestion is a machine-applicable change to source code that is expected
+// to resolve the problem described by a diagnostic, for use in "quick fix"
+// features of editors and automatic remediation in other tools.
+type Suggestion struct {
+	// Description is a short English-language description of the change,
+	// such as `Rename to "name"`, suitable for display in a menu of
+	// possible fixes.
+	Description string
+
+	// Edits are the changes to make to the source code. All of the edits
+	// must be applied together for the suggestion to be effective.
+	Edits []Edit
+}
+
+// Edit describes the replacement of a range of source code with new text.
+// An empty range represents an insertion and an empty replacement represents
+// a deletion.
+type Edit struct {
+	Range       Range
+	Replacement string
+}
+
+// ApplyEdits returns a copy of the given source code with the given edits
+// applied. Only the Byte offsets of the edit ranges are used, and so all of
+// the edits are assumed to refer to the given source.
+//
+// An error is returned if any edit is out of the bounds of the source or if
+// any two edits overlap, in which case they cannot be applied together.
+func ApplyEdits(src []byte, edits []Edit) ([]byte, error) {
+	sorted := make([]Edit, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Range.Start.Byte < sorted[j].Range.Start.Byte
+	})
+
+	ret := make([]byte, 0, len(src))
+	next := 0
+	for _, edit := range sorted {
+		start, end := edit.Range.Start.Byte, edit.Range.End.Byte
+		if start < 0 || end < start || end > len(src) {
+			return nil, fmt.Errorf("edit at %s is out of bounds", edit.Range)
+		}
+		if start < next {
+			return nil, fmt.Errorf("edit at %s overlaps an earlier edit", edit.Range)
+		}
+		ret = append(ret, src[next:start]...)
+		ret = append(ret, edit.Replacement...)
+		next = end
+	}
+	ret = append(ret, src[next:]...)
+	return ret, nil
+}
+
+// Edits returns all of the edits from the suggestions of the given
+// diagnostics for the given filename, taking only the first suggestion of
+// each diagnostic. The result can be passed to ApplyEdits to fix all of the
+// diagnostics at once, as long as their edits do not overlap.
+func (d Diagnostics) Edits(filename string) []Edit {
+	var ret []Edit
+	for _, diag := range d {
+		if len(diag.Suggestions) == 0 {
+			continue
+		}
+		for _, edit := range diag.Suggestions[0].Edits {
+			if edit.Range.Filename == filename {
+				ret = append(ret, edit)
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,143 @@
+package hcl
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApplyEdits(t *testing.T) {
+	edit := func(start, end int, replacement string) Edit {
+		return Edit{
+			Range: Range{
+				Start: Pos{Byte: start},
+				End:   Pos{Byte: end},
+			},
+			Replacement: replacement,
+		}
+	}
+
+	tests := map[string]struct {
+		Src     string
+		Edits   []Edit
+		Want    string
+		WantErr bool
+	}{
+		"no edits": {
+			`foo = 1`,
+			nil,
+			`foo = 1`,
+			false,
+		},
+		"replace": {
+			`fob = 1`,
+			[]Edit{edit(0, 3, "foo")},
+			`foo = 1`,
+			false,
+		},
+		"insert and delete": {
+			`a = 1 b = 2`,
+			[]Edit{edit(6, 11, ""), edit(5, 6, "\n")},
+			"a = 1\n",
+			false,
+		},
+		"out of order": {
+			"fob = 1\nbaz = 2\n",
+			[]Edit{edit(8, 11, "bar"), edit(0, 3, "foo")},
+			"foo = 1\nbar = 2\n",
+			false,
+		},
+		"overlapping": {
+			`foo = 1`,
+			[]Edit{edit(0, 3, "bar"), edit(2, 4, "baz")},
+			``,
+			true,
+		},
+		"out of bounds": {
+			`foo`,
+			[]Edit{edit(2, 5, "")},
+			``,
+			true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ApplyEdits([]byte(test.Src), test.Edits)
+			if test.WantErr {
+				if err == nil {
+					t.Fatalf("no error; want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(got) != test.Want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestDiagnosticsEdits(t *testing.T) {
+	rng := func(filename string, start, end int) Range {
+		return Range{
+			Filename: filename,
+			Start:    Pos{Line: 1, Column: start + 1, Byte: start},
+			End:      Pos{Line: 1, Column: end + 1, Byte: end},
+		}
+	}
+	diags := Diagnostics{
+		{
+			Severity: DiagError,
+			Summary:  "Unsupported argument",
+			Suggestions: []Suggestion{
+				{
+					Description: `Rename to "foo"`,
+					Edits:       []Edit{{Range: rng("a.hcl", 0, 3), Replacement: "foo"}},
+				},
+				{
+					Description: `Rename to "fob"`,
+					Edits:       []Edit{{Range: rng("a.hcl", 0, 3), Replacement: "fob"}},
+				},
+			},
+		},
+		{
+			Severity: DiagError,
+			Summary:  "Unsupported argument",
+			Suggestions: []Suggestion{
+				{
+					Description: `Rename to "bar"`,
+					Edits:       []Edit{{Range: rng("b.hcl", 0, 3), Replacement: "bar"}},
+				},
+			},
+		},
+		{
+			Severity: DiagWarning,
+			Summary:  "Deprecated",
+		},
+	}
+
+	got := diags.Edits("a.hcl")
+	want := []Edit{{Range: rng("a.hcl", 0, 3), Replacement: "foo"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	src, err := json.Marshal(diags[1])
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	wantJSON := `{"severity":"error","summary":"Unsupported argument","suggestions":[{"description":"Rename to \"bar\"","edits":[{"range":{"filename":"b.hcl","start":{"line":1,"column":1,"byte":0},"end":{"line":1,"column":4,"byte":3}},"replacement":"bar"}]}]}`
+	if string(src) != wantJSON {
+		t.Errorf("wrong JSON\ngot:  %s\nwant: %s", src, wantJSON)
+	}
+	var roundTrip Diagnostic
+	if err := json.Unmarshal(src, &roundTrip); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(&roundTrip, diags[1]) {
+		t.Errorf("wrong result after round trip\ngot:  %#v\nwant: %#v", &roundTrip, diags[1])
+	}
+}
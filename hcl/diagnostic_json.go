@@ -0,0 +1,198 @@
+package hcl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// diagnosticJSON is the JSON representation of a Diagnostic used by
+// Diagnostic.MarshalJSON, which is considered to be a stable interface.
+type diagnosticJSON struct {
+	Severity string     `json:"severity"`
+	Summary  string     `json:"summary"`
+	Detail   string     `json:"detail,omitempty"`
+	Category string     `json:"category,omitempty"`
+	Code     string     `json:"code,omitempty"`
+	Subject  *rangeJSON `json:"subject,omitempty"`
+	Context  *rangeJSON `json:"context,omitempty"`
+
+	Suggestions []suggestionJSON `json:"suggestions,omitempty"`
+}
+
+type suggestionJSON struct {
+	Description string     `json:"description"`
+	Edits       []editJSON `json:"edits"`
+}
+
+type editJSON struct {
+	Range       rangeJSON `json:"range"`
+	Replacement string    `json:"replacement"`
+}
+
+type rangeJSON struct {
+	Filename string  `json:"filename"`
+	Start    posJSON `json:"start"`
+	End      posJSON `json:"end"`
+}
+
+type posJSON struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+// MarshalJSON returns a JSON representation of the diagnostic, for use by
+// programs that consume diagnostics produced by another program. The
+// representation is an object with the following properties:
+//
+//    severity     "error", "warning" or "info"
+//    summary      the Summary field
+//    detail       the Detail field, omitted if empty
+//    category     the Category field, omitted if empty
+//    code         the Code field, omitted if empty
+//    subject      the Subject range, omitted if nil
+//    context      the Context range, omitted if nil
+//    suggestions  the Suggestions, omitted if empty
+//
+// Ranges are represented as objects with properties "filename", "start" and
+// "end", with the latter two being objects with properties "line", "column"
+// and "byte" as in Pos. Suggestions are represented as objects with
+// properties "description" and "edits", the latter being an array of objects
+// with properties "range" and "replacement".
+//
+// The Expression, EvalContext and Extra fields are not included, since they
+// cannot be represented in JSON. This format will only be extended in
+// backward-compatible ways, by adding new properties.
+func (d *Diagnostic) MarshalJSON() ([]byte, error) {
+	var severity string
+	switch d.Severity {
+	case DiagError:
+		severity = "error"
+	case DiagWarning:
+		severity = "warning"
+	case DiagInfo:
+		severity = "info"
+	default:
+		return nil, fmt.Errorf("invalid diagnostic severity %d", d.Severity)
+	}
+
+	var suggestions []suggestionJSON
+	for _, s := range d.Suggestions {
+		sj := suggestionJSON{
+			Description: s.Description,
+			Edits:       make([]editJSON, len(s.Edits)),
+		}
+		for i, edit := range s.Edits {
+			sj.Edits[i] = editJSON{
+				Range:       *rangeForJSON(&edit.Range),
+				Replacement: edit.Replacement,
+			}
+		}
+		suggestions = append(suggestions, sj)
+	}
+
+	return json.Marshal(diagnosticJSON{
+		Severity:    severity,
+		Summary:     d.Summary,
+		Detail:      d.Detail,
+		Category:    d.Category,
+		Code:        d.Code,
+		Subject:     rangeForJSON(d.Subject),
+		Context:     rangeForJSON(d.Context),
+		Suggestions: suggestions,
+	})
+}
+
+// UnmarshalJSON populates the diagnostic from the JSON representation
+// produced by MarshalJSON.
+func (d *Diagnostic) UnmarshalJSON(src []byte) error {
+	var raw diagnosticJSON
+	if err := json.Unmarshal(src, &raw); err != nil {
+		return err
+	}
+
+	switch raw.Severity {
+	case "error":
+		d.Severity = DiagError
+	case "warning":
+		d.Severity = DiagWarning
+	case "info":
+		d.Severity = DiagInfo
+	default:
+		return fmt.Errorf("invalid diagnostic severity %q", raw.Severity)
+	}
+	d.Summary = raw.Summary
+	d.Detail = raw.Detail
+	d.Category = raw.Category
+	d.Code = raw.Code
+	d.Subject = raw.Subject.rng()
+	d.Context = raw.Context.rng()
+	d.Suggestions = nil
+	for _, sj := range raw.Suggestions {
+		s := Suggestion{
+			Description: sj.Description,
+			Edits:       make([]Edit, len(sj.Edits)),
+		}
+		for i, ej := range sj.Edits {
+			s.Edits[i] = Edit{
+				Range:       *ej.Range.rng(),
+				Replacement: ej.Replacement,
+			}
+		}
+		d.Suggestions = append(d.Suggestions, s)
+	}
+	return nil
+}
+
+func rangeForJSON(rng *Range) *rangeJSON {
+	if rng == nil {
+		return nil
+	}
+	return &rangeJSON{
+		Filename: rng.Filename,
+		Start:    posJSON{Line: rng.Start.Line, Column: rng.Start.Column, Byte: rng.Start.Byte},
+		End:      posJSON{Line: rng.End.Line, Column: rng.End.Column, Byte: rng.End.Byte},
+	}
+}
+
+func (r *rangeJSON) rng() *Range {
+	if r == nil {
+		return nil
+	}
+	return &Range{
+		Filename: r.Filename,
+		Start:    Pos{Line: r.Start.Line, Column: r.Start.Column, Byte: r.Start.Byte},
+		End:      Pos{Line: r.End.Line, Column: r.End.Column, Byte: r.End.Byte},
+	}
+}
+
+type diagnosticJSONWriter struct {
+	enc *json.Encoder
+}
+
+// NewDiagnosticJSONWriter creates a DiagnosticWriter that writes diagnostics
+// to the given writer in the JSON representation described for
+// Diagnostic.MarshalJSON, with each diagnostic on a separate line.
+func NewDiagnosticJSONWriter(wr io.Writer) DiagnosticWriter {
+	return &diagnosticJSONWriter{
+		enc: json.NewEncoder(wr),
+	}
+}
+
+func (w *diagnosticJSONWriter) WriteDiagnostic(diag *Diagnostic) error {
+	if diag == nil {
+		return fmt.Errorf("nil diagnostic")
+	}
+	return w.enc.Encode(diag)
+}
+
+func (w *diagnosticJSONWriter) WriteDiagnostics(diags Diagnostics) error {
+	for _, diag := range diags {
+		err := w.WriteDiagnostic(diag)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package hcl
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiagnosticJSON(t *testing.T) {
+	diags := Diagnostics{
+		{
+			Severity: DiagError,
+			Summary:  "Unsupported attribute",
+			Detail:   `"baz" is not supported.`,
+			Code:     DiagUnsupportedAttribute,
+			Subject: &Range{
+				Filename: "test.hcl",
+				Start:    Pos{Line: 3, Column: 1, Byte: 16},
+				End:      Pos{Line: 3, Column: 4, Byte: 19},
+			},
+		},
+		{
+			Severity: DiagWarning,
+			Summary:  "Deprecated",
+		},
+		{
+			Severity: DiagInfo,
+			Summary:  "Redundant parentheses",
+			Category: "style",
+		},
+	}
+
+	got, err := json.Marshal(diags)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := `[{"severity":"error","summary":"Unsupported attribute","detail":"\"baz\" is not supported.","code":"HCL5004","subject":{"filename":"test.hcl","start":{"line":3,"column":1,"byte":16},"end":{"line":3,"column":4,"byte":19}}},{"severity":"warning","summary":"Deprecated"},{"severity":"info","summary":"Redundant parentheses","category":"style"}]`
+	if string(got) != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+
+	var roundTrip Diagnostics
+	if err := json.Unmarshal(got, &roundTrip); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(roundTrip, diags) {
+		t.Errorf("wrong result after round trip\ngot:  %#v\nwant: %#v", roundTrip, diags)
+	}
+
+	if diags[1:].HasErrors() {
+		t.Errorf("HasErrors returned true for warning and info diagnostics")
+	}
+
+	if _, err := json.Marshal(&Diagnostic{Summary: "Invalid"}); err == nil {
+		t.Errorf("no error for invalid severity")
+	}
+}
+
+func TestDiagnosticJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	wr := NewDiagnosticJSONWriter(&buf)
+	err := wr.WriteDiagnostics(Diagnostics{
+		{Severity: DiagError, Summary: "First"},
+		{Severity: DiagWarning, Summary: "Second"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		`{"severity":"error","summary":"First"}`,
+		`{"severity":"warning","summary":"Second"}`,
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", lines, want)
+	}
+}
+
+func TestDiagnosticsSARIF(t *testing.T) {
+	diags := Diagnostics{
+		{
+			Severity: DiagError,
+			Summary:  "Unsupported attribute",
+			Detail:   `"baz" is not supported.`,
+			Subject: &Range{
+				Filename: "dir/test.hcl",
+				Start:    Pos{Line: 3, Column: 1, Byte: 16},
+				End:      Pos{Line: 3, Column: 4, Byte: 19},
+			},
+		},
+		{
+			Severity: DiagWarning,
+			Summary:  "Deprecated",
+		},
+	}
+
+	src, err := DiagnosticsSARIF(diags, "hcltool")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(src, &got); err != nil {
+		t.Fatalf("result is not valid JSON: %s", err)
+	}
+	var want map[string]interface{}
+	err = json.Unmarshal([]byte(`{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {"driver": {"name": "hcltool"}},
+      "columnKind": "unicodeCodePoints",
+      "results": [
+        {
+          "level": "error",
+          "message": {"text": "Unsupported attribute: \"baz\" is not supported."},
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {"uri": "dir/test.hcl"},
+                "region": {"startLine": 3, "startColumn": 1, "endLine": 3, "endColumn": 4}
+              }
+            }
+          ]
+        },
+        {
+          "level": "warning",
+          "message": {"text": "Deprecated"}
+        }
+      ]
+    }
+  ]
+}`), &want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:\n%s", src)
+	}
+}
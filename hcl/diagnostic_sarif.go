@@ -0,0 +1,124 @@
+package hcl
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+)
+
+// DiagnosticsSARIF returns a SARIF 2.1.0 log containing the given diagnostics
+// as the results of a single run of a tool with the given name, for use with
+// code scanning systems that accept SARIF.
+//
+// SARIF regions are expressed in lines and columns. The columns in HCL
+// source ranges count Unicode grapheme clusters, which is the same as
+// counting code points for the vast majority of source code, so the run
+// declares its column kind as "unicodeCodePoints".
+//
+// The Code of each diagnostic, if any, is used as the SARIF rule id.
+//
+// Absolute filenames are written as file URIs and relative filenames are
+// written as relative URIs, so a caller wishing to
+// produce results that can be correlated with a repository should pass
+// diagnostics whose ranges use paths relative to the repository root.
+func DiagnosticsSARIF(diags Diagnostics, toolName string) ([]byte, error) {
+	type sarifMessage struct {
+		Text string `json:"text"`
+	}
+	type sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+		EndLine     int `json:"endLine"`
+		EndColumn   int `json:"endColumn"`
+	}
+	type sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	type sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+	type sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	type sarifResult struct {
+		RuleID    string          `json:"ruleId,omitempty"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations,omitempty"`
+	}
+	type sarifDriver struct {
+		Name string `json:"name"`
+	}
+	type sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	type sarifRun struct {
+		Tool       sarifTool     `json:"tool"`
+		ColumnKind string        `json:"columnKind"`
+		Results    []sarifResult `json:"results"`
+	}
+	type sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	results := make([]sarifResult, 0, len(diags))
+	for _, diag := range diags {
+		result := sarifResult{
+			RuleID: diag.Code,
+			Level:  "error",
+			Message: sarifMessage{
+				Text: diag.Summary,
+			},
+		}
+		switch diag.Severity {
+		case DiagWarning:
+			result.Level = "warning"
+		case DiagInfo:
+			result.Level = "note"
+		}
+		if diag.Detail != "" {
+			result.Message.Text += ": " + diag.Detail
+		}
+		if rng := diag.Subject; rng != nil {
+			uri := filepath.ToSlash(rng.Filename)
+			if filepath.IsAbs(rng.Filename) {
+				uri = (&url.URL{Scheme: "file", Path: uri}).String()
+			}
+			result.Locations = []sarifLocation{
+				{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{
+							URI: uri,
+						},
+						Region: sarifRegion{
+							StartLine:   rng.Start.Line,
+							StartColumn: rng.Start.Column,
+							EndLine:     rng.End.Line,
+							EndColumn:   rng.End.Column,
+						},
+					},
+				},
+			}
+		}
+		results = append(results, result)
+	}
+
+	return json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name: toolName,
+					},
+				},
+				ColumnKind: "unicodeCodePoints",
+				Results:    results,
+			},
+		},
+	}, "", "  ")
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
+	"unicode/utf8"
 
 	wordwrap "github.com/mitchellh/go-wordwrap"
 	"github.com/zclconf/go-cty/cty"
@@ -29,8 +31,10 @@ type diagnosticTextWriter struct {
 // and truncation of source code snippets.
 //
 // If color is set to true, the output will include VT100 escape sequences to
-// color-code the severity indicators. It is suggested to turn this off if
-// the target writer is not a terminal.
+// color-code the severity indicators and to highlight the subject of each
+// diagnostic within the source code snippet. It is suggested to turn this off
+// if the target writer is not a terminal, in which case the subject is instead
+// marked by a line of carets beneath each source line.
 func NewDiagnosticTextWriter(wr io.Writer, files map[string]*File, width uint, color bool) DiagnosticWriter {
 	return &diagnosticTextWriter{
 		files: files,
@@ -52,6 +56,8 @@ func (w *diagnosticTextWriter) WriteDiagnostic(diag *Diagnostic) error {
 			colorCode = "\x1b[31m"
 		case DiagWarning:
 			colorCode = "\x1b[33m"
+		case DiagInfo:
+			colorCode = "\x1b[36m"
 		}
 		resetCode = "\x1b[0m"
 		highlightCode = "\x1b[1;4m"
@@ -63,6 +69,8 @@ func (w *diagnosticTextWriter) WriteDiagnostic(diag *Diagnostic) error {
 		severityStr = "Error"
 	case DiagWarning:
 		severityStr = "Warning"
+	case DiagInfo:
+		severityStr = "Info"
 	default:
 		// should never happen
 		severityStr = "???????"
@@ -130,6 +138,11 @@ func (w *diagnosticTextWriter) WriteDiagnostic(diag *Diagnostic) error {
 						highlightCode, highlighted, resetCode,
 						after,
 					)
+					if !w.color {
+						// Without color we can't highlight inline, so
+						// we'll mark the subject with carets instead.
+						fmt.Fprintf(w.wr, "      %s\n", markerLine(before, highlighted))
+					}
 				}
 
 			}
@@ -157,11 +170,13 @@ func (w *diagnosticTextWriter) WriteDiagnostic(diag *Diagnostic) error {
 					continue
 				}
 
-				traversalStr := w.traversalStr(traversal)
+				traversalStr := traversal.String()
 				if _, exists := seen[traversalStr]; exists {
 					continue // don't show duplicates when the same variable is referenced multiple times
 				}
 				switch {
+				case ctx.Sensitive(traversal):
+					stmts = append(stmts, fmt.Sprintf("%s as (sensitive value)", traversalStr))
 				case !val.IsKnown():
 					// Can't say anything about this yet, then.
 					continue
@@ -215,34 +230,6 @@ func (w *diagnosticTextWriter) WriteDiagnostics(diags Diagnostics) error {
 	return nil
 }
 
-func (w *diagnosticTextWriter) traversalStr(traversal Traversal) string {
-	// This is a specialized subset of traversal rendering tailored to
-	// producing helpful contextual messages in diagnostics. It is not
-	// comprehensive nor intended to be used for other purposes.
-
-	var buf bytes.Buffer
-	for _, step := range traversal {
-		switch tStep := step.(type) {
-		case TraverseRoot:
-			buf.WriteString(tStep.Name)
-		case TraverseAttr:
-			buf.WriteByte('.')
-			buf.WriteString(tStep.Name)
-		case TraverseIndex:
-			buf.WriteByte('[')
-			if keyTy := tStep.Key.Type(); keyTy.IsPrimitiveType() {
-				buf.WriteString(w.valueStr(tStep.Key))
-			} else {
-				// We'll just use a placeholder for more complex values,
-				// since otherwise our result could grow ridiculously long.
-				buf.WriteString("...")
-			}
-			buf.WriteByte(']')
-		}
-	}
-	return buf.String()
-}
-
 func (w *diagnosticTextWriter) valueStr(val cty.Value) string {
 	// This is a specialized subset of value rendering tailored to producing
 	// helpful but concise messages in diagnostics. It is not comprehensive
@@ -299,6 +286,27 @@ func (w *diagnosticTextWriter) valueStr(val cty.Value) string {
 	}
 }
 
+// markerLine returns a line of carets that, when printed beneath a source
+// line, marks the given highlighted part of the line following the given
+// prefix. Tabs in the prefix are retained so that the markers will align
+// regardless of the tab width.
+func markerLine(before, highlighted []byte) string {
+	var buf bytes.Buffer
+	for _, r := range string(before) {
+		if r == '\t' {
+			buf.WriteRune('\t')
+		} else {
+			buf.WriteRune(' ')
+		}
+	}
+	count := utf8.RuneCount(highlighted)
+	if count == 0 {
+		count = 1
+	}
+	buf.WriteString(strings.Repeat("^", count))
+	return buf.String()
+}
+
 func contextString(file *File, offset int) string {
 	type contextStringer interface {
 		ContextString(offset int) string
@@ -35,6 +35,7 @@ func TestDiagnosticTextWriter(t *testing.T) {
 
   on  line 1, in hardcoded-context:
    1: foo = 1
+      ^^^
 
 All splines must be pre-reticulated.
 
@@ -62,6 +63,7 @@ All splines must be pre-reticulated.
 
   on  line 3, in hardcoded-context:
    3: baz = 3
+      ^^^
 
 "baz" is not a supported top-level
 attribute. Did you mean "bam"?
@@ -105,6 +107,7 @@ attribute. Did you mean "bam"?
   on  line 5, in hardcoded-context:
    4: block "party" {
    5:   pizza = "cheese"
+        ^^^^^
    6: }
 
 "pizza" is not a supported attribute.
@@ -175,6 +178,7 @@ Did you mean "pizzetta"?
 
   on  line 5, in hardcoded-context:
    5:   pizza = "cheese"
+        ^^^^^
 
 with bar.baz as empty list of string,
      boz as 5,
@@ -183,6 +187,57 @@ with bar.baz as empty list of string,
 This diagnostic includes an expression
 and an evalcontext.
 
+`,
+		},
+		{
+			&Diagnostic{
+				Severity: DiagError,
+				Summary:  "Test of redacting sensitive values",
+				Subject: &Range{
+					Start: Pos{
+						Byte:   42,
+						Column: 3,
+						Line:   5,
+					},
+					End: Pos{
+						Byte:   47,
+						Column: 8,
+						Line:   5,
+					},
+				},
+				Expression: &diagnosticTestExpr{
+					vars: []Traversal{
+						{
+							TraverseRoot{
+								Name: "password",
+							},
+						},
+						{
+							TraverseRoot{
+								Name: "user",
+							},
+						},
+					},
+				},
+				EvalContext: &EvalContext{
+					Variables: map[string]cty.Value{
+						"password": cty.StringVal("hunter2"),
+						"user":     cty.StringVal("admin"),
+					},
+					SensitiveVariables: map[string]bool{
+						"password": true,
+					},
+				},
+			},
+			`Error: Test of redacting sensitive values
+
+  on  line 5, in hardcoded-context:
+   5:   pizza = "cheese"
+        ^^^^^
+
+with password as (sensitive value),
+     user as "admin".
+
 `,
 		},
 	}
@@ -210,6 +265,44 @@ and an evalcontext.
 	}
 }
 
+func TestDiagnosticTextWriterColor(t *testing.T) {
+	files := map[string]*File{
+		"": &File{
+			Bytes: []byte(testDiagnosticTextWriterSource),
+			Nav:   &diagnosticTestNav{},
+		},
+	}
+	diag := &Diagnostic{
+		Severity: DiagWarning,
+		Summary:  "Deprecated attribute",
+		Subject: &Range{
+			Start: Pos{Byte: 8, Column: 1, Line: 2},
+			End:   Pos{Byte: 11, Column: 4, Line: 2},
+		},
+	}
+
+	bwr := &bytes.Buffer{}
+	dwr := NewDiagnosticTextWriter(bwr, files, 40, true)
+	if err := dwr.WriteDiagnostic(diag); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got := bwr.String()
+	want := "\x1b[33mWarning\x1b[0m: Deprecated attribute\n\n" +
+		"  on  line 2, in hardcoded-context:\n" +
+		"   2: \x1b[1;4mbar\x1b[0m = 2\n\n"
+	if got != want {
+		t.Errorf("wrong result\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestDiagnosticTextWriterMarkerTabs(t *testing.T) {
+	got := markerLine([]byte("\t\tfoo "), []byte("b\u00e4r"))
+	want := "\t\t    ^^^"
+	if got != want {
+		t.Errorf("wrong result %q; want %q", got, want)
+	}
+}
+
 const testDiagnosticTextWriterSource = `foo = 1
 bar = 2
 baz = 3
@@ -1 +1,22 @@
+// Package hcl contains the main modelling types and general utility functions
+// for HCL.
+//
+// Concurrency
+//
+// The parsers in this module share no state between calls, and so different
+// files can be parsed concurrently. hclparse.Parser, which keeps a registry of
+// the files it has parsed, synchronizes access to it internally, while
+// applications that do not need the registry can avoid that cost by calling
+// the parsers of the individual syntaxes directly.
+//
+// A parsed file is never modified by the operations that read it, and so the
+// bodies and expressions of the same file can be decoded and evaluated by
+// several goroutines at once, including with the same EvalContext. The
+// EvalContext must not be modified while it is in use, but each evaluation
+// can have its own child context to define additional variables; see
+// EvalContext.Snapshot. The functions called by expressions must themselves
+// be safe for concurrent use.
+//
+// Types that accumulate state, such as Bundle and EvalCache, document
+// whether they are safe for concurrent use.
 package hcl
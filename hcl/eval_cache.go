@@ -0,0 +1,138 @@
+package hcl
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// EvalCache memoizes the results of evaluating expressions, for applications
+// that evaluate the same expressions in the same contexts many times.
+//
+// Results are keyed by the identity of the expression and of the context, so
+// an expression evaluated in a different context, even one with the same
+// content, is evaluated again. Only expressions whose dynamic type is a
+// pointer, as are those produced by the parsers and by TraversalExpr, have an
+// identity, and so other expressions, such as those returned by StaticExpr,
+// are evaluated each time. A cached result is used only if the values of
+// all of the variables the expression refers to are unchanged since it was
+// evaluated, so that modifying the Variables maps of a context does not
+// produce stale results. This check considers only the variables in the
+// Variables maps of the context and its ancestors, and so expressions that
+// refer to variables provided by a VariableResolver are never cached.
+//
+// Changes to the functions of a context are not detected, and so an
+// application that changes them must call Invalidate or Reset afterwards.
+//
+// The cache holds references to the expressions and contexts that it has
+// seen, and so an application should discard it, or call Reset, once they
+// are no longer needed. An EvalCache is safe for concurrent use.
+type EvalCache struct {
+	mu      sync.Mutex
+	entries map[evalCacheKey]*evalCacheEntry
+}
+
+type evalCacheKey struct {
+	expr Expression
+	ctx  *EvalContext
+}
+
+type evalCacheEntry struct {
+	val   cty.Value
+	diags Diagnostics
+
+	// vars are the values of the root variables that the expression refers
+	// to, at the time it was evaluated.
+	vars map[string]cty.Value
+}
+
+// NewEvalCache returns a new, empty cache.
+func NewEvalCache() *EvalCache {
+	return &EvalCache{
+		entries: make(map[evalCacheKey]*evalCacheEntry),
+	}
+}
+
+// Value returns the result of evaluating the given expression in the given
+// context, as returned by expr.Value(ctx), using a cached result if there
+// is a valid one. The context may be nil.
+func (c *EvalCache) Value(expr Expression, ctx *EvalContext) (cty.Value, Diagnostics) {
+	if reflect.TypeOf(expr).Kind() != reflect.Ptr {
+		// Values of other types may not be comparable at all, or may
+		// panic when hashed because they contain slices or maps, as with
+		// a staticExpr holding a list value. Equal values of such types
+		// also need not be the same expression.
+		return expr.Value(ctx)
+	}
+	key := evalCacheKey{expr, ctx}
+
+	c.mu.Lock()
+	entry := c.entries[key]
+	c.mu.Unlock()
+	if entry != nil && entry.valid(ctx) {
+		return entry.val, append(Diagnostics(nil), entry.diags...)
+	}
+
+	val, diags := expr.Value(ctx)
+
+	vars := make(map[string]cty.Value)
+	for _, traversal := range expr.Variables() {
+		name := traversal.RootName()
+		varVal, exists := ctx.Variable(name)
+		if !exists {
+			// We can't tell when this variable changes, so we can't cache
+			// this result.
+			return val, diags
+		}
+		vars[name] = varVal
+	}
+
+	c.mu.Lock()
+	c.entries[key] = &evalCacheEntry{
+		val:   val,
+		diags: append(Diagnostics(nil), diags...),
+		vars:  vars,
+	}
+	c.mu.Unlock()
+	return val, diags
+}
+
+func (e *evalCacheEntry) valid(ctx *EvalContext) bool {
+	for name, want := range e.vars {
+		got, exists := ctx.Variable(name)
+		if !exists || !got.RawEquals(want) {
+			return false
+		}
+	}
+	return true
+}
+
+// Invalidate discards any cached results for expressions evaluated in the
+// given context or in any of its descendents.
+func (c *EvalCache) Invalidate(ctx *EvalContext) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		for thisCtx := key.ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+			if thisCtx == ctx {
+				delete(c.entries, key)
+				break
+			}
+		}
+	}
+}
+
+// Reset discards all of the cached results.
+func (c *EvalCache) Reset() {
+	c.mu.Lock()
+	c.entries = make(map[evalCacheKey]*evalCacheEntry)
+	c.mu.Unlock()
+}
+
+// Len returns the number of cached results.
+func (c *EvalCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
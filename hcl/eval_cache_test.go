@@ -0,0 +1,160 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// countingExpr is an expression that returns the value of a variable and
+// counts how many times it has been evaluated.
+type countingExpr struct {
+	name  string
+	count int
+}
+
+func (e *countingExpr) Value(ctx *EvalContext) (cty.Value, Diagnostics) {
+	e.count++
+	val, exists := ctx.Variable(e.name)
+	if !exists {
+		return cty.DynamicVal, Diagnostics{
+			{
+				Severity: DiagError,
+				Summary:  "Unknown variable",
+			},
+		}
+	}
+	return val, nil
+}
+
+func (e *countingExpr) Variables() []Traversal {
+	return []Traversal{
+		{TraverseRoot{Name: e.name}},
+	}
+}
+
+func (e *countingExpr) Range() Range {
+	return Range{}
+}
+
+func (e *countingExpr) StartRange() Range {
+	return Range{}
+}
+
+func TestEvalCache(t *testing.T) {
+	cache := NewEvalCache()
+	expr := &countingExpr{name: "a"}
+	ctx := &EvalContext{
+		Variables: map[string]cty.Value{
+			"a": cty.StringVal("hello"),
+		},
+	}
+
+	check := func(wantVal cty.Value, wantCount int) {
+		t.Helper()
+		val, diags := cache.Value(expr, ctx)
+		if len(diags) != 0 {
+			t.Errorf("unexpected diagnostics: %s", diags.Error())
+		}
+		if !val.RawEquals(wantVal) {
+			t.Errorf("wrong value %#v; want %#v", val, wantVal)
+		}
+		if expr.count != wantCount {
+			t.Errorf("wrong evaluation count %d; want %d", expr.count, wantCount)
+		}
+	}
+
+	check(cty.StringVal("hello"), 1)
+	check(cty.StringVal("hello"), 1)
+
+	// Changing an unrelated variable doesn't invalidate the result.
+	ctx.Variables["b"] = cty.True
+	check(cty.StringVal("hello"), 1)
+
+	// Changing the referenced variable does.
+	ctx.Variables["a"] = cty.StringVal("goodbye")
+	check(cty.StringVal("goodbye"), 2)
+	check(cty.StringVal("goodbye"), 2)
+
+	// A child context is a different context, even though it sees the
+	// same variables.
+	child := ctx.NewChild()
+	if _, diags := cache.Value(expr, child); len(diags) != 0 {
+		t.Errorf("unexpected diagnostics: %s", diags.Error())
+	}
+	if got, want := cache.Len(), 2; got != want {
+		t.Errorf("wrong cache length %d; want %d", got, want)
+	}
+
+	// Invalidating the parent invalidates the child too.
+	cache.Invalidate(ctx)
+	if got, want := cache.Len(), 0; got != want {
+		t.Errorf("wrong cache length %d; want %d", got, want)
+	}
+	check(cty.StringVal("goodbye"), 4)
+
+	cache.Reset()
+	check(cty.StringVal("goodbye"), 5)
+}
+
+func TestEvalCacheUnknownVariable(t *testing.T) {
+	cache := NewEvalCache()
+	expr := &countingExpr{name: "a"}
+
+	for i := 0; i < 2; i++ {
+		_, diags := cache.Value(expr, nil)
+		if len(diags) != 1 {
+			t.Errorf("wrong number of diagnostics %d; want 1", len(diags))
+		}
+	}
+	if got, want := expr.count, 2; got != want {
+		t.Errorf("wrong evaluation count %d; want %d", got, want)
+	}
+	if got, want := cache.Len(), 0; got != want {
+		t.Errorf("wrong cache length %d; want %d", got, want)
+	}
+}
+
+func TestEvalCacheSyntheticExprs(t *testing.T) {
+	cache := NewEvalCache()
+	ctx := &EvalContext{
+		Variables: map[string]cty.Value{
+			"a": cty.ListVal([]cty.Value{cty.StringVal("hello")}),
+		},
+	}
+
+	// The result of TraversalExpr is a pointer and so can be cached.
+	traversal := TraversalExpr(Traversal{TraverseRoot{Name: "a"}}, Range{})
+	for i := 0; i < 2; i++ {
+		val, diags := cache.Value(traversal, ctx)
+		if len(diags) != 0 {
+			t.Errorf("unexpected diagnostics: %s", diags.Error())
+		}
+		if want := ctx.Variables["a"]; !val.RawEquals(want) {
+			t.Errorf("wrong value %#v; want %#v", val, want)
+		}
+	}
+	if got, want := cache.Len(), 1; got != want {
+		t.Errorf("wrong cache length %d; want %d", got, want)
+	}
+
+	// The result of StaticExpr is not a pointer, and a collection value
+	// inside it cannot be hashed, so it must be evaluated without caching.
+	tests := []cty.Value{
+		cty.ListVal([]cty.Value{cty.True}),
+		cty.MapVal(map[string]cty.Value{"a": cty.True}),
+		cty.SetVal([]cty.Value{cty.True}),
+	}
+	for _, want := range tests {
+		val, diags := cache.Value(StaticExpr(want, Range{}), ctx)
+		if len(diags) != 0 {
+			t.Errorf("unexpected diagnostics: %s", diags.Error())
+		}
+		if !val.RawEquals(want) {
+			t.Errorf("wrong value %#v; want %#v", val, want)
+		}
+	}
+	if got, want := cache.Len(), 1; got != want {
+		t.Errorf("wrong cache length %d; want %d", got, want)
+	}
+}
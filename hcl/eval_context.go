@@ -1,25 +1,495 @@
 package hcl
 
 import (
+	"strings"
+
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
 )
 
 // An EvalContext provides the variables and functions that should be used
 // to evaluate an expression.
+//
+// Function names may be qualified with one or more namespaces, separated by
+// FunctionNamespaceSeparator, such as "provider::cidr::host". The native
+// syntax allows calling such functions by their full qualified name, which
+// is looked up in Functions in the same way as an unqualified name. This
+// allows applications with large function catalogs to organize them and to
+// avoid name collisions between functions from different sources.
 type EvalContext struct {
 	Variables map[string]cty.Value
 	Functions map[string]function.Function
-	parent    *EvalContext
+
+	// VariableResolver, if set, is consulted for any variable name that is
+	// not present in Variables, before moving on to the parent context.
+	// This allows an application to construct the values of variables only
+	// when an expression actually refers to them. See VariableResolver for
+	// more information.
+	VariableResolver VariableResolver
+
+	// SensitiveVariables, if set, names variables defined in this context
+	// whose values must not be revealed in diagnostics, such as credentials.
+	// Diagnostics produced while evaluating expressions that refer to these
+	// variables will avoid including the values involved, and the
+	// diagnostic text writer will not render them. See Sensitive for the
+	// rules used to decide whether a particular reference is sensitive.
+	SensitiveVariables map[string]bool
+
+	// FunctionDocs, if set, provides documentation for some or all of the
+	// functions in Functions, using the same keys. This is not used during
+	// evaluation, but is returned by FunctionSignature for the benefit of
+	// tools such as completion engines.
+	FunctionDocs map[string]*FunctionDoc
+
+	// Trace, if set, is called after the evaluation of each expression in
+	// this context or in any of its descendents, including each of the
+	// sub-expressions that make up a larger expression. See TraceFunc for
+	// more information.
+	Trace TraceFunc
+
+	// Instrumentation, if set, receives events describing the decoding of
+	// blocks and the evaluation of expressions in this context or in any
+	// of its descendents, unless a descendent sets its own. See
+	// Instrumentation for more information.
+	Instrumentation Instrumentation
+
+	// WarnInterpolationOnly, if set in this context or any of its
+	// ancestors, causes the evaluation of a quoted template that consists
+	// only of a single interpolation sequence, like "${var.name}", to
+	// produce a warning that suggests using the interpolated expression
+	// directly. Such templates were required by older versions of the
+	// language, and so this can help users to clean up legacy configurations.
+	// The JSON syntax, which requires all expressions to be templates, never
+	// produces this warning.
+	WarnInterpolationOnly bool
+
+	// UnknownForMissing, if set in this context or any of its ancestors,
+	// causes references to variables, attributes, map elements and sequence
+	// indices that do not exist to produce unknown values of the appropriate
+	// type rather than errors. This is useful for applications that evaluate
+	// configuration before all of the values it refers to are available,
+	// such as to check it for other errors, and which will evaluate it
+	// again once the missing values are known.
+	UnknownForMissing bool
+
+	// MaxTemplateLength and MaxForElements, if greater than zero, limit the
+	// size of the values produced by the expressions evaluated in this
+	// context and its descendents, unless a descendent sets its own limit.
+	// This protects applications that evaluate untrusted expressions from
+	// expressions that would consume excessive memory.
+	//
+	// MaxTemplateLength is the maximum length in bytes of a string produced
+	// by a template, and MaxForElements is the maximum number of elements,
+	// or of attributes, produced by a 'for' expression. Evaluation of an
+	// expression that would exceed one of these limits stops as soon as it
+	// does so, and returns an error diagnostic. The values produced in other
+	// ways, such as by functions, are not limited.
+	MaxTemplateLength int
+	MaxForElements    int
+
+	parent *EvalContext
+}
+
+// TraceFunc is the signature of a function that observes the evaluation of
+// expressions, for use in EvalContext.
+//
+// It is called with each expression, the context it was evaluated in, and
+// the resulting value and diagnostics. Since sub-expressions are evaluated
+// before the expressions that contain them, it is called for them first. An
+// expression may be evaluated more than once, such as the result expression
+// of a "for" expression, which is evaluated once for each element in a child
+// context that defines the iteration variables.
+//
+// This allows an application to explain how a value was derived, or to
+// record which parts of a configuration were evaluated. Only the native
+// syntax and the templates in the JSON syntax report their sub-expressions.
+// Tracing is intended for debugging and has a cost, so a trace function
+// should not be used in normal operation. If expressions are evaluated
+// concurrently then it is called concurrently.
+type TraceFunc func(expr Expression, ctx *EvalContext, val cty.Value, diags Diagnostics)
+
+// TraceEval reports the evaluation of the given expression in the receiver
+// to the Trace function of the receiver or of its nearest ancestor that has
+// one, if any. It is called by the implementations of Expression.Value, and
+// is not usually called directly by applications. The receiver may be nil.
+func (ctx *EvalContext) TraceEval(expr Expression, val cty.Value, diags Diagnostics) {
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		if thisCtx.Trace != nil {
+			thisCtx.Trace(expr, ctx, val, diags)
+			return
+		}
+	}
+}
+
+// InterpolationOnlyWarnings returns true if the receiver or any of its
+// ancestors has WarnInterpolationOnly set. The receiver may be nil.
+func (ctx *EvalContext) InterpolationOnlyWarnings() bool {
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		if thisCtx.WarnInterpolationOnly {
+			return true
+		}
+	}
+	return false
 }
 
+// UnknownForMissingEnabled returns true if the receiver or any of its
+// ancestors has UnknownForMissing set. The receiver may be nil.
+func (ctx *EvalContext) UnknownForMissingEnabled() bool {
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		if thisCtx.UnknownForMissing {
+			return true
+		}
+	}
+	return false
+}
+
+// TemplateLengthLimit returns the value of MaxTemplateLength in the receiver
+// or, if it is not set there, in its nearest ancestor that sets it. The
+// result is zero if there is no limit. The receiver may be nil.
+func (ctx *EvalContext) TemplateLengthLimit() int {
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		if thisCtx.MaxTemplateLength > 0 {
+			return thisCtx.MaxTemplateLength
+		}
+	}
+	return 0
+}
+
+// ForElementsLimit returns the value of MaxForElements in the receiver or,
+// if it is not set there, in its nearest ancestor that sets it. The result
+// is zero if there is no limit. The receiver may be nil.
+func (ctx *EvalContext) ForElementsLimit() int {
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		if thisCtx.MaxForElements > 0 {
+			return thisCtx.MaxForElements
+		}
+	}
+	return 0
+}
+
+// VariableResolver is the signature of a function that can provide the value
+// of a variable on request, for use in EvalContext.
+//
+// The function should return cty.NilVal and no error diagnostics if it does
+// not recognize the given name, in which case the lookup continues in the
+// parent context. It may return error diagnostics if the name is recognized
+// but its value cannot be produced, in which case the returned value, if not
+// cty.NilVal, is used as a placeholder for the failed variable; returning
+// cty.DynamicVal is a good choice in that case. Any returned diagnostics
+// that have no subject are reported with the range of the reference.
+//
+// A resolver may be called many times for the same name, once for each
+// reference to it, so a resolver whose values are expensive to produce
+// should cache them.
+type VariableResolver func(name string) (cty.Value, Diagnostics)
+
 // NewChild returns a new EvalContext that is a child of the receiver.
+//
+// Variables and functions that are not defined in the child are looked up in
+// the receiver and then in its ancestors, while those defined in the child
+// shadow any of the same name in the receiver. This allows, for example, a
+// nested block to introduce its own local variables and helper functions
+// without affecting the evaluation of its surroundings.
 func (ctx *EvalContext) NewChild() *EvalContext {
 	return &EvalContext{parent: ctx}
 }
 
+// Clone returns a copy of the receiver that has no parent, in which the
+// variables, functions and other settings of the receiver's ancestors are
+// merged with those of the receiver, taking shadowing into account.
+//
+// The copy has its own maps, so later changes to the maps of the receiver or
+// of its ancestors do not affect the copy, and vice-versa. The values and
+// functions themselves are immutable, and so are shared.
+//
+// If more than one context in the chain has a VariableResolver, the copy has
+// a resolver that consults each of them in turn, starting with that of the
+// receiver. Since the copy's Variables map is consulted before its resolver,
+// a variable defined in the Variables map of an ancestor then takes
+// precedence over one of the same name provided by the resolver of one of
+// its descendents.
+func (ctx *EvalContext) Clone() *EvalContext {
+	if ctx == nil {
+		return nil
+	}
+
+	ret := &EvalContext{}
+	var resolvers []VariableResolver
+	decided := make(map[string]bool) // variables whose sensitivity is known
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		if thisCtx.Variables != nil && ret.Variables == nil {
+			ret.Variables = make(map[string]cty.Value)
+		}
+		if thisCtx.Functions != nil && ret.Functions == nil {
+			ret.Functions = make(map[string]function.Function)
+		}
+		if thisCtx.FunctionDocs != nil && ret.FunctionDocs == nil {
+			ret.FunctionDocs = make(map[string]*FunctionDoc)
+		}
+		if ret.Trace == nil {
+			ret.Trace = thisCtx.Trace
+		}
+		if ret.Instrumentation == nil {
+			ret.Instrumentation = thisCtx.Instrumentation
+		}
+		if thisCtx.WarnInterpolationOnly {
+			ret.WarnInterpolationOnly = true
+		}
+		if thisCtx.UnknownForMissing {
+			ret.UnknownForMissing = true
+		}
+		if ret.MaxTemplateLength <= 0 {
+			ret.MaxTemplateLength = thisCtx.MaxTemplateLength
+		}
+		if ret.MaxForElements <= 0 {
+			ret.MaxForElements = thisCtx.MaxForElements
+		}
+		if thisCtx.VariableResolver != nil {
+			resolvers = append(resolvers, thisCtx.VariableResolver)
+		}
+
+		// Sensitive decides sensitivity at the nearest context that either
+		// marks a variable as sensitive or defines it.
+		for name, sensitive := range thisCtx.SensitiveVariables {
+			if sensitive && !decided[name] {
+				if ret.SensitiveVariables == nil {
+					ret.SensitiveVariables = make(map[string]bool)
+				}
+				ret.SensitiveVariables[name] = true
+				decided[name] = true
+			}
+		}
+		for name, val := range thisCtx.Variables {
+			decided[name] = true
+			if _, shadowed := ret.Variables[name]; !shadowed {
+				ret.Variables[name] = val
+			}
+		}
+		for name, f := range thisCtx.Functions {
+			if _, shadowed := ret.Functions[name]; !shadowed {
+				ret.Functions[name] = f
+			}
+		}
+		for name, doc := range thisCtx.FunctionDocs {
+			if _, shadowed := ret.FunctionDocs[name]; !shadowed {
+				ret.FunctionDocs[name] = doc
+			}
+		}
+	}
+
+	switch len(resolvers) {
+	case 0:
+	case 1:
+		ret.VariableResolver = resolvers[0]
+	default:
+		ret.VariableResolver = func(name string) (cty.Value, Diagnostics) {
+			for _, resolver := range resolvers {
+				val, diags := resolver(name)
+				if val != cty.NilVal || diags.HasErrors() {
+					return val, diags
+				}
+			}
+			return cty.NilVal, nil
+		}
+	}
+
+	return ret
+}
+
+// Snapshot returns an immutable snapshot of the receiver, as produced by
+// Clone, which can be shared by concurrent evaluations.
+func (ctx *EvalContext) Snapshot() *EvalSnapshot {
+	return &EvalSnapshot{
+		ctx: ctx.Clone(),
+	}
+}
+
+// EvalSnapshot is an immutable snapshot of an EvalContext, created by
+// EvalContext.Snapshot.
+//
+// An EvalContext is not safe to modify while it is being used to evaluate
+// expressions, and so an application that evaluates expressions
+// concurrently, with each evaluation adding its own variables to a shared
+// base context, must take care to avoid races. A snapshot makes this easy:
+// each evaluation calls NewContext to obtain its own context that inherits
+// the variables and functions of the snapshot, and which it can modify
+// freely. The snapshot itself cannot be modified, except by modifying the
+// parent of one of these contexts, which applications must not do.
+type EvalSnapshot struct {
+	ctx *EvalContext
+}
+
+// NewContext returns a new, empty context whose parent is the snapshot.
+func (s *EvalSnapshot) NewContext() *EvalContext {
+	return s.ctx.NewChild()
+}
+
+// Variable returns the value of the variable with the given name in the
+// snapshot, as for EvalContext.Variable.
+func (s *EvalSnapshot) Variable(name string) (cty.Value, bool) {
+	return s.ctx.Variable(name)
+}
+
+// Function returns the function with the given name in the snapshot, as for
+// EvalContext.Function.
+func (s *EvalSnapshot) Function(name string) (function.Function, bool) {
+	return s.ctx.Function(name)
+}
+
 // Parent returns the parent of the receiver, or nil if the receiver has
 // no parent.
 func (ctx *EvalContext) Parent() *EvalContext {
 	return ctx.parent
 }
+
+// Variable returns the value of the variable with the given name as seen
+// from the receiver, along with a boolean that is false if no such variable
+// is defined.
+//
+// Variables defined in a child context shadow those of the same name in its
+// ancestors. Only the static Variables maps are consulted; variables that
+// would be provided by a VariableResolver are not visible to this method.
+func (ctx *EvalContext) Variable(name string) (cty.Value, bool) {
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		if val, exists := thisCtx.Variables[name]; exists {
+			return val, true
+		}
+	}
+	return cty.NilVal, false
+}
+
+// Function returns the function with the given name as seen from the
+// receiver, along with a boolean that is false if no such function is
+// defined.
+//
+// Functions defined in a child context shadow those of the same name in its
+// ancestors, so a child context can both add new functions and replace the
+// implementation of existing ones for the expressions evaluated in it.
+func (ctx *EvalContext) Function(name string) (function.Function, bool) {
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		if f, exists := thisCtx.Functions[name]; exists {
+			return f, true
+		}
+	}
+	return function.Function{}, false
+}
+
+// FunctionsAllowed returns true if function calls are permitted in
+// expressions evaluated in the receiver, which is the case if the receiver
+// or any of its ancestors has a non-nil Functions map, even if that map is
+// empty.
+func (ctx *EvalContext) FunctionsAllowed() bool {
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		if thisCtx.Functions != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// HasFunctionNamespace returns true if at least one function visible from
+// the receiver belongs to the given namespace or to one of its nested
+// namespaces. For example, a function named "provider::cidr::host" belongs
+// to both the "provider::cidr" and "provider" namespaces.
+func (ctx *EvalContext) HasFunctionNamespace(namespace string) bool {
+	prefix := namespace + FunctionNamespaceSeparator
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		for name := range thisCtx.Functions {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FunctionNamespaceSeparator is the separator between the parts of a
+// namespaced function name.
+const FunctionNamespaceSeparator = "::"
+
+// FunctionNamespace returns the namespace portion of the given function
+// name, which is everything before its final FunctionNamespaceSeparator, or
+// an empty string if the name is not namespaced.
+func FunctionNamespace(name string) string {
+	idx := strings.LastIndex(name, FunctionNamespaceSeparator)
+	if idx < 0 {
+		return ""
+	}
+	return name[:idx]
+}
+
+// EffectiveVariables returns a new map containing all of the variables
+// visible from the receiver, taking shadowing into account. As with
+// Variable, variables that would be provided by a VariableResolver are not
+// included.
+func (ctx *EvalContext) EffectiveVariables() map[string]cty.Value {
+	ret := make(map[string]cty.Value)
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		for name, val := range thisCtx.Variables {
+			if _, shadowed := ret[name]; !shadowed {
+				ret[name] = val
+			}
+		}
+	}
+	return ret
+}
+
+// EffectiveFunctions returns a new map containing all of the functions
+// visible from the receiver, taking shadowing into account.
+func (ctx *EvalContext) EffectiveFunctions() map[string]function.Function {
+	ret := make(map[string]function.Function)
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		for name, f := range thisCtx.Functions {
+			if _, shadowed := ret[name]; !shadowed {
+				ret[name] = f
+			}
+		}
+	}
+	return ret
+}
+
+// Sensitive returns true if the given absolute traversal refers to a variable
+// that is marked as sensitive in the context that defines it.
+//
+// Sensitivity follows the same shadowing rules as variable lookup: a variable
+// defined in a child context that is not itself marked as sensitive hides a
+// sensitive variable of the same name in an ancestor. Variables that are
+// provided by a VariableResolver can be marked as sensitive by including
+// their names in SensitiveVariables of the context that owns the resolver.
+//
+// Sensitivity applies to the variable as a whole, so any traversal that
+// begins with a sensitive variable is considered sensitive. Relative
+// traversals are never sensitive.
+func (ctx *EvalContext) Sensitive(traversal Traversal) bool {
+	if traversal.IsRelative() {
+		return false
+	}
+	name := traversal.RootName()
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		if thisCtx.SensitiveVariables[name] {
+			return true
+		}
+		if _, exists := thisCtx.Variables[name]; exists {
+			return false
+		}
+	}
+	return false
+}
+
+// ExpressionSensitive returns true if any of the variables referenced by the
+// given expression are sensitive, as defined by Sensitive.
+//
+// An expression for which this returns true may produce a value derived
+// from a sensitive value, and so callers generating diagnostics about the
+// result of evaluating it should avoid including that result in messages.
+func (ctx *EvalContext) ExpressionSensitive(expr Expression) bool {
+	if ctx == nil || expr == nil {
+		return false
+	}
+	for _, traversal := range expr.Variables() {
+		if ctx.Sensitive(traversal) {
+			return true
+		}
+	}
+	return false
+}
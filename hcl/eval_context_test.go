@@ -0,0 +1,427 @@
+package hcl
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestEvalContextVariableResolver(t *testing.T) {
+	var calls []string
+	parent := &EvalContext{
+		Variables: map[string]cty.Value{
+			"shadowed": cty.StringVal("parent"),
+			"fallback": cty.StringVal("parent"),
+		},
+	}
+	ctx := parent.NewChild()
+	ctx.Variables = map[string]cty.Value{
+		"static": cty.StringVal("static"),
+	}
+	ctx.VariableResolver = func(name string) (cty.Value, Diagnostics) {
+		calls = append(calls, name)
+		switch name {
+		case "lazy", "shadowed":
+			return cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal(name),
+			}), nil
+		case "broken":
+			return cty.NilVal, Diagnostics{
+				{
+					Severity: DiagError,
+					Summary:  "Failed to load",
+				},
+			}
+		default:
+			return cty.NilVal, nil
+		}
+	}
+
+	rng := Range{Filename: "test.hcl", Start: Pos{Line: 1, Column: 1}, End: Pos{Line: 1, Column: 5, Byte: 4}}
+	traversal := func(name string, attrs ...string) Traversal {
+		ret := Traversal{TraverseRoot{Name: name, SrcRange: rng}}
+		for _, attr := range attrs {
+			ret = append(ret, TraverseAttr{Name: attr})
+		}
+		return ret
+	}
+
+	tests := []struct {
+		Traversal Traversal
+		Want      cty.Value
+		DiagCount int
+	}{
+		{traversal("static"), cty.StringVal("static"), 0},
+		{traversal("lazy", "name"), cty.StringVal("lazy"), 0},
+		{traversal("shadowed", "name"), cty.StringVal("shadowed"), 0},
+		{traversal("fallback"), cty.StringVal("parent"), 0},
+		{traversal("broken"), cty.DynamicVal, 1},
+		{traversal("missing"), cty.DynamicVal, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Traversal.String(), func(t *testing.T) {
+			got, diags := test.Traversal.TraverseAbs(ctx)
+			if len(diags) != test.DiagCount {
+				t.Errorf("wrong number of diagnostics %d; want %d", len(diags), test.DiagCount)
+				for _, diag := range diags {
+					t.Logf("- %s", diag.Error())
+				}
+			}
+			for _, diag := range diags {
+				if diag.Subject == nil || *diag.Subject != rng {
+					t.Errorf("wrong subject %#v for %q", diag.Subject, diag.Summary)
+				}
+			}
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+
+	if len(calls) != 5 {
+		t.Errorf("resolver called %d times; want 5 (not for static variables)", len(calls))
+	}
+}
+
+func TestEvalContextVariableResolverOnly(t *testing.T) {
+	ctx := &EvalContext{
+		VariableResolver: func(name string) (cty.Value, Diagnostics) {
+			return cty.NilVal, nil
+		},
+	}
+	_, diags := Traversal{TraverseRoot{Name: "a"}}.TraverseAbs(ctx)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1", len(diags))
+	}
+	if got, want := diags[0].Code, DiagUnknownVariable; got != want {
+		t.Errorf("wrong diagnostic code %q; want %q", got, want)
+	}
+}
+
+func TestEvalContextScope(t *testing.T) {
+	parent := &EvalContext{
+		Variables: map[string]cty.Value{
+			"a": cty.StringVal("parent"),
+			"b": cty.StringVal("parent"),
+		},
+		Functions: map[string]function.Function{
+			"upper": stdlib.UpperFunc,
+			"lower": stdlib.LowerFunc,
+		},
+	}
+	child := parent.NewChild()
+	child.Variables = map[string]cty.Value{
+		"b": cty.StringVal("child"),
+	}
+	child.Functions = map[string]function.Function{
+		"upper": stdlib.LowerFunc,
+		"len":   stdlib.StrlenFunc,
+	}
+
+	if got, _ := child.Variable("a"); !got.RawEquals(cty.StringVal("parent")) {
+		t.Errorf("wrong value for a: %#v", got)
+	}
+	if got, _ := child.Variable("b"); !got.RawEquals(cty.StringVal("child")) {
+		t.Errorf("wrong value for b: %#v", got)
+	}
+	if _, exists := child.Variable("c"); exists {
+		t.Errorf("variable c exists")
+	}
+	if got := len(child.EffectiveVariables()); got != 2 {
+		t.Errorf("wrong number of effective variables %d; want 2", got)
+	}
+
+	f, exists := child.Function("upper")
+	if !exists {
+		t.Fatalf("function upper does not exist")
+	}
+	got, err := f.Call([]cty.Value{cty.StringVal("Hello")})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := cty.StringVal("hello"); !got.RawEquals(want) {
+		t.Errorf("child function does not shadow parent: got %#v, want %#v", got, want)
+	}
+	if _, exists := parent.Function("len"); exists {
+		t.Errorf("child function is visible in parent")
+	}
+	if got := len(child.EffectiveFunctions()); got != 3 {
+		t.Errorf("wrong number of effective functions %d; want 3", got)
+	}
+
+	if !child.FunctionsAllowed() {
+		t.Errorf("functions not allowed in child")
+	}
+	if (&EvalContext{}).NewChild().FunctionsAllowed() {
+		t.Errorf("functions allowed with no function tables")
+	}
+	var nilCtx *EvalContext
+	if nilCtx.FunctionsAllowed() {
+		t.Errorf("functions allowed in nil context")
+	}
+}
+
+func TestEvalContextSensitive(t *testing.T) {
+	parent := &EvalContext{
+		Variables: map[string]cty.Value{
+			"secret":   cty.StringVal("hunter2"),
+			"shadowed": cty.StringVal("hunter2"),
+			"public":   cty.StringVal("hello"),
+		},
+		SensitiveVariables: map[string]bool{
+			"secret":   true,
+			"shadowed": true,
+		},
+	}
+	child := parent.NewChild()
+	child.Variables = map[string]cty.Value{
+		"shadowed": cty.StringVal("not secret"),
+	}
+
+	tests := []struct {
+		ctx  *EvalContext
+		name string
+		want bool
+	}{
+		{parent, "secret", true},
+		{parent, "shadowed", true},
+		{parent, "public", false},
+		{parent, "missing", false},
+		{child, "secret", true},
+		{child, "shadowed", false},
+		{child, "public", false},
+		{nil, "secret", false},
+	}
+
+	for _, test := range tests {
+		traversal := Traversal{
+			TraverseRoot{Name: test.name},
+			TraverseAttr{Name: "attr"},
+		}
+		if got := test.ctx.Sensitive(traversal); got != test.want {
+			t.Errorf("wrong result for %s in %p: got %t, want %t", test.name, test.ctx, got, test.want)
+		}
+	}
+
+	expr := TraversalExpr(Traversal{TraverseRoot{Name: "secret"}}, Range{})
+	if !child.ExpressionSensitive(expr) {
+		t.Errorf("expression referring to secret is not sensitive")
+	}
+	if child.ExpressionSensitive(StaticExpr(cty.True, Range{})) {
+		t.Errorf("static expression is sensitive")
+	}
+}
+
+func TestEvalContextFunctionNamespaces(t *testing.T) {
+	parent := &EvalContext{
+		Functions: map[string]function.Function{
+			"provider::cidr::host": stdlib.UpperFunc,
+		},
+	}
+	child := parent.NewChild()
+	child.Functions = map[string]function.Function{
+		"upper": stdlib.UpperFunc,
+	}
+
+	for ns, want := range map[string]bool{
+		"provider::cidr": true,
+		"provider":       true,
+		"cidr":           false,
+		"provider::ci":   false,
+		"":               false,
+	} {
+		if got := child.HasFunctionNamespace(ns); got != want {
+			t.Errorf("wrong result for %q: got %t, want %t", ns, got, want)
+		}
+	}
+
+	for name, want := range map[string]string{
+		"provider::cidr::host": "provider::cidr",
+		"math::round":          "math",
+		"upper":                "",
+	} {
+		if got := FunctionNamespace(name); got != want {
+			t.Errorf("wrong namespace for %q: got %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestEvalContextClone(t *testing.T) {
+	upper := stdlib.UpperFunc
+	lower := stdlib.LowerFunc
+	parent := &EvalContext{
+		Variables: map[string]cty.Value{
+			"shadowed":  cty.StringVal("parent"),
+			"inherited": cty.StringVal("parent"),
+		},
+		SensitiveVariables: map[string]bool{
+			"shadowed":  true,
+			"inherited": true,
+		},
+		Functions: map[string]function.Function{
+			"upper": upper,
+		},
+		VariableResolver: func(name string) (cty.Value, Diagnostics) {
+			if name == "resolved" {
+				return cty.StringVal("parent resolver"), nil
+			}
+			return cty.NilVal, nil
+		},
+	}
+	child := parent.NewChild()
+	child.Variables = map[string]cty.Value{
+		"shadowed": cty.StringVal("child"),
+	}
+	child.Functions = map[string]function.Function{
+		"upper": lower,
+	}
+	child.VariableResolver = func(name string) (cty.Value, Diagnostics) {
+		if name == "child_resolved" {
+			return cty.StringVal("child resolver"), nil
+		}
+		return cty.NilVal, nil
+	}
+
+	parent.WarnInterpolationOnly = true
+	parent.MaxTemplateLength = 10
+	parent.MaxForElements = 5
+	child.MaxTemplateLength = 20
+
+	clone := child.Clone()
+	if clone.Parent() != nil {
+		t.Fatalf("clone has a parent")
+	}
+	if !clone.InterpolationOnlyWarnings() {
+		t.Errorf("clone does not inherit WarnInterpolationOnly")
+	}
+	if got, want := clone.TemplateLengthLimit(), 20; got != want {
+		t.Errorf("wrong template length limit %d; want %d", got, want)
+	}
+	if got, want := clone.ForElementsLimit(), 5; got != want {
+		t.Errorf("wrong 'for' elements limit %d; want %d", got, want)
+	}
+
+	for name, want := range map[string]cty.Value{
+		"shadowed":       cty.StringVal("child"),
+		"inherited":      cty.StringVal("parent"),
+		"resolved":       cty.StringVal("parent resolver"),
+		"child_resolved": cty.StringVal("child resolver"),
+	} {
+		got, diags := Traversal{TraverseRoot{Name: name}}.TraverseAbs(clone)
+		if diags.HasErrors() {
+			t.Errorf("unexpected errors for %s: %s", name, diags.Error())
+			continue
+		}
+		if !got.RawEquals(want) {
+			t.Errorf("wrong value for %s: got %#v, want %#v", name, got, want)
+		}
+	}
+
+	if f, _ := clone.Function("upper"); f != lower {
+		t.Errorf("clone does not use the child's shadowing function")
+	}
+
+	for name, want := range map[string]bool{
+		"shadowed":  false,
+		"inherited": true,
+	} {
+		if got := clone.Sensitive(Traversal{TraverseRoot{Name: name}}); got != want {
+			t.Errorf("wrong sensitivity for %s: got %t, want %t", name, got, want)
+		}
+	}
+
+	// The clone must be unaffected by changes to the original's maps, and
+	// vice-versa.
+	parent.Variables["added"] = cty.True
+	child.Functions["added"] = upper
+	clone.Variables["inherited"] = cty.StringVal("clone")
+	if _, exists := clone.Variable("added"); exists {
+		t.Errorf("variable added to original is visible in clone")
+	}
+	if _, exists := clone.Function("added"); exists {
+		t.Errorf("function added to original is visible in clone")
+	}
+	if got, _ := child.Variable("inherited"); !got.RawEquals(cty.StringVal("parent")) {
+		t.Errorf("change to clone is visible in original: got %#v", got)
+	}
+}
+
+func TestEvalContextCloneNilMaps(t *testing.T) {
+	if (*EvalContext)(nil).Clone() != nil {
+		t.Fatalf("clone of nil context is not nil")
+	}
+
+	clone := (&EvalContext{}).NewChild().Clone()
+	if clone.Variables != nil || clone.Functions != nil {
+		t.Errorf("clone of context without maps has non-nil maps")
+	}
+	_, diags := Traversal{TraverseRoot{Name: "a"}}.TraverseAbs(clone)
+	if len(diags) != 1 || diags[0].Summary != "Variables not allowed" {
+		t.Errorf("wrong diagnostics for clone without variables: %s", diags.Error())
+	}
+
+	parent := &EvalContext{
+		Variables: map[string]cty.Value{},
+		Functions: map[string]function.Function{},
+	}
+	clone = parent.NewChild().Clone()
+	if clone.Variables == nil || clone.Functions == nil {
+		t.Errorf("clone of context with empty maps has nil maps")
+	}
+	if !clone.FunctionsAllowed() {
+		t.Errorf("functions not allowed in clone")
+	}
+}
+
+func TestEvalSnapshot(t *testing.T) {
+	base := &EvalContext{
+		Variables: map[string]cty.Value{
+			"base": cty.NumberIntVal(1),
+		},
+	}
+	snap := base.Snapshot()
+	base.Variables["base"] = cty.NumberIntVal(2)
+
+	if got, _ := snap.Variable("base"); !got.RawEquals(cty.NumberIntVal(1)) {
+		t.Fatalf("snapshot sees later change to base: got %#v", got)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := snap.NewContext()
+			ctx.Variables = map[string]cty.Value{
+				"i": cty.NumberIntVal(int64(i)),
+			}
+			for _, want := range []struct {
+				name string
+				val  cty.Value
+			}{
+				{"base", cty.NumberIntVal(1)},
+				{"i", cty.NumberIntVal(int64(i))},
+			} {
+				got, diags := Traversal{TraverseRoot{Name: want.name}}.TraverseAbs(ctx)
+				if diags.HasErrors() || !got.RawEquals(want.val) {
+					errs <- fmt.Sprintf("wrong value for %s in goroutine %d: %#v", want.name, i, got)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if _, exists := snap.Variable("i"); exists {
+		t.Errorf("variable of derived context is visible in snapshot")
+	}
+}
@@ -30,6 +30,7 @@ func ExprCall(expr Expression) (*StaticCall, Diagnostics) {
 		&Diagnostic{
 			Severity: DiagError,
 			Summary:  "Invalid expression",
+			Code:     DiagStaticExpressionRequired,
 			Detail:   "A static function call is required.",
 			Subject:  expr.StartRange().Ptr(),
 		},
@@ -30,6 +30,7 @@ func ExprList(expr Expression) ([]Expression, Diagnostics) {
 		&Diagnostic{
 			Severity: DiagError,
 			Summary:  "Invalid expression",
+			Code:     DiagStaticExpressionRequired,
 			Detail:   "A static list expression is required.",
 			Subject:  expr.StartRange().Ptr(),
 		},
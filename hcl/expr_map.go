@@ -30,6 +30,7 @@ func ExprMap(expr Expression) ([]KeyValuePair, Diagnostics) {
 		&Diagnostic{
 			Severity: DiagError,
 			Summary:  "Invalid expression",
+			Code:     DiagStaticExpressionRequired,
 			Detail:   "A static map expression is required.",
 			Subject:  expr.StartRange().Ptr(),
 		},
@@ -0,0 +1,40 @@
+package hcl
+
+// ContentWithExtraAttributes is like Body.Content except that the body may
+// also contain attributes that are not declared in the given schema, which
+// are returned separately rather than being reported as errors.
+//
+// This is useful for bodies that mix a fixed set of arguments and nested
+// blocks with arbitrary user-chosen arguments, such as a map of tags given
+// directly as arguments:
+//
+//     resource "example" {
+//       name = "foo"       # declared in the schema
+//       environment = "production" # an extra attribute
+//
+//       lifecycle {        # a block declared in the schema
+//       }
+//     }
+//
+// Blocks that are not declared in the schema are still reported as errors,
+// as are any other problems detected by Content. As with JustAttributes,
+// source languages that cannot distinguish attributes from blocks without a
+// schema, such as JSON, will return any undeclared properties as extra
+// attributes.
+//
+// The returned extra attributes map is never nil, so that callers can
+// always iterate over it.
+func ContentWithExtraAttributes(body Body, schema *BodySchema) (*BodyContent, Attributes, Diagnostics) {
+	content, remain, diags := body.PartialContent(schema)
+	extra := Attributes{}
+	if remain == nil {
+		return content, extra, diags
+	}
+
+	attrs, moreDiags := remain.JustAttributes()
+	diags = append(diags, moreDiags...)
+	for name, attr := range attrs {
+		extra[name] = attr
+	}
+	return content, extra, diags
+}
@@ -0,0 +1,170 @@
+package hcl
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// FunctionDoc is documentation for a function, for use by tools such as
+// completion engines and documentation generators.
+//
+// cty functions do not carry any documentation of their own, so an
+// application can attach it by populating the FunctionDocs map of an
+// EvalContext using the same keys as its Functions map.
+type FunctionDoc struct {
+	// Description is a human-readable description of what the function
+	// does, which may span multiple sentences.
+	Description string
+
+	// Params maps parameter names to human-readable descriptions of those
+	// parameters. It need not contain an entry for every parameter.
+	Params map[string]string
+}
+
+// FunctionSignature describes how a function can be called, as returned by
+// EvalContext.FunctionSignature.
+type FunctionSignature struct {
+	Name        string
+	Description string
+
+	// Params describes the fixed parameters of the function, in order.
+	Params []FunctionParam
+
+	// VarParam describes the variadic parameter of the function, which
+	// accepts zero or more additional arguments after the fixed ones, or is
+	// nil if the function is not variadic.
+	VarParam *FunctionParam
+
+	// ReturnType is the type of value the function returns when called
+	// with arguments of the types of its parameters. This is
+	// cty.DynamicPseudoType if the return type depends on the argument
+	// values or cannot be determined without them.
+	ReturnType cty.Type
+}
+
+// FunctionParam describes a single parameter of a function.
+type FunctionParam struct {
+	Name        string
+	Description string
+	Type        cty.Type
+
+	AllowNull    bool
+	AllowUnknown bool
+}
+
+// FunctionSignature returns the signature of the function with the given
+// name as seen from the receiver, along with a boolean that is false if no
+// such function is defined.
+//
+// Documentation is taken from the FunctionDocs map of the same context that
+// defines the function, so a child context that overrides a function does
+// not inherit the documentation of the function it shadows.
+func (ctx *EvalContext) FunctionSignature(name string) (*FunctionSignature, bool) {
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		if f, exists := thisCtx.Functions[name]; exists {
+			return newFunctionSignature(name, f, thisCtx.FunctionDocs[name]), true
+		}
+	}
+	return nil, false
+}
+
+// FunctionSignatures returns the signatures of all of the functions visible
+// from the receiver, taking shadowing into account, ordered by name.
+func (ctx *EvalContext) FunctionSignatures() []*FunctionSignature {
+	funcs := ctx.EffectiveFunctions()
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ret := make([]*FunctionSignature, len(names))
+	for i, name := range names {
+		ret[i], _ = ctx.FunctionSignature(name)
+	}
+	return ret
+}
+
+func newFunctionSignature(name string, f function.Function, doc *FunctionDoc) *FunctionSignature {
+	if doc == nil {
+		doc = &FunctionDoc{}
+	}
+
+	sig := &FunctionSignature{
+		Name:        name,
+		Description: doc.Description,
+	}
+
+	params := f.Params()
+	argTypes := make([]cty.Type, len(params))
+	sig.Params = make([]FunctionParam, len(params))
+	for i, param := range params {
+		sig.Params[i] = newFunctionParam(param, doc)
+		argTypes[i] = param.Type
+	}
+	if varParam := f.VarParam(); varParam != nil {
+		p := newFunctionParam(*varParam, doc)
+		sig.VarParam = &p
+	}
+
+	// We can determine the return type only for functions whose return type
+	// is decided by the parameter types alone; functions that need to see
+	// argument values will either fail or return cty.DynamicPseudoType here.
+	sig.ReturnType = cty.DynamicPseudoType
+	if retTy, err := f.ReturnType(argTypes); err == nil {
+		sig.ReturnType = retTy
+	}
+
+	return sig
+}
+
+func newFunctionParam(param function.Parameter, doc *FunctionDoc) FunctionParam {
+	return FunctionParam{
+		Name:         param.Name,
+		Description:  doc.Params[param.Name],
+		Type:         param.Type,
+		AllowNull:    param.AllowNull,
+		AllowUnknown: param.AllowUnknown,
+	}
+}
+
+// String returns a compact representation of the signature, such as
+//
+//     join(separator string, lists ...list of string) string
+//
+// Types are given by their cty friendly names, with the dynamic
+// pseudo-type written as "any".
+func (s *FunctionSignature) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(s.Name)
+	buf.WriteByte('(')
+	for i, param := range s.Params {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(param.Name)
+		buf.WriteByte(' ')
+		buf.WriteString(signatureTypeName(param.Type))
+	}
+	if s.VarParam != nil {
+		if len(s.Params) > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(s.VarParam.Name)
+		buf.WriteString(" ...")
+		buf.WriteString(signatureTypeName(s.VarParam.Type))
+	}
+	buf.WriteString(") ")
+	buf.WriteString(signatureTypeName(s.ReturnType))
+	return buf.String()
+}
+
+func signatureTypeName(ty cty.Type) string {
+	if ty == cty.DynamicPseudoType {
+		return "any"
+	}
+	return ty.FriendlyName()
+}
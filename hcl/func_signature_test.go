@@ -0,0 +1,79 @@
+package hcl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestEvalContextFunctionSignature(t *testing.T) {
+	parent := &EvalContext{
+		Functions: map[string]function.Function{
+			"upper":  stdlib.UpperFunc,
+			"concat": stdlib.ConcatFunc,
+			"substr": stdlib.SubstrFunc,
+		},
+		FunctionDocs: map[string]*FunctionDoc{
+			"upper": {
+				Description: "Converts a string to uppercase.",
+				Params: map[string]string{
+					"str": "The string to convert.",
+				},
+			},
+		},
+	}
+	child := parent.NewChild()
+	child.Functions = map[string]function.Function{
+		"upper": stdlib.LowerFunc,
+	}
+
+	tests := []struct {
+		ctx         *EvalContext
+		name        string
+		want        string
+		description string
+	}{
+		{parent, "upper", "upper(str string) string", "Converts a string to uppercase."},
+		{child, "upper", "upper(str string) string", ""},
+		{child, "concat", "concat(seqs ...any) any", ""},
+		{child, "substr", "substr(str string, offset number, length number) string", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			sig, exists := test.ctx.FunctionSignature(test.name)
+			if !exists {
+				t.Fatalf("function %q does not exist", test.name)
+			}
+			if got := sig.String(); got != test.want {
+				t.Errorf("wrong signature\ngot:  %s\nwant: %s", got, test.want)
+			}
+			if sig.Description != test.description {
+				t.Errorf("wrong description %q; want %q", sig.Description, test.description)
+			}
+		})
+	}
+
+	sig, _ := parent.FunctionSignature("upper")
+	if got, want := sig.Params[0].Description, "The string to convert."; got != want {
+		t.Errorf("wrong parameter description %q; want %q", got, want)
+	}
+	if got := sig.ReturnType; got != cty.String {
+		t.Errorf("wrong return type %#v", got)
+	}
+	if _, exists := parent.FunctionSignature("nonexist"); exists {
+		t.Errorf("nonexistent function exists")
+	}
+
+	sigs := child.FunctionSignatures()
+	var names []string
+	for _, sig := range sigs {
+		names = append(names, sig.Name)
+	}
+	if got, want := names, []string{"concat", "substr", "upper"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong function names %#v; want %#v", got, want)
+	}
+}
@@ -0,0 +1,290 @@
+package hclsyntax
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Difference describes the first difference found between two bodies or
+// expressions by CompareBodies or CompareExpressions.
+type Difference struct {
+	// Path locates the difference, as a sequence of block headers, such as
+	// `service "web"`, followed by an attribute name and then, if the
+	// difference is within the attribute's expression, a description of
+	// the location within the expression's syntax tree.
+	Path []string
+
+	// Summary describes the difference.
+	Summary string
+
+	// A and B are the source ranges of the differing items in the first and
+	// second body, or the ranges of their containing bodies if the item is
+	// present in only one of them.
+	A, B hcl.Range
+}
+
+func (d *Difference) String() string {
+	if len(d.Path) == 0 {
+		return d.Summary
+	}
+	return fmt.Sprintf("%s: %s", strings.Join(d.Path, " > "), d.Summary)
+}
+
+// CompareBodies compares two bodies for structural equality, returning nil
+// if they are equal or a description of the first difference otherwise.
+//
+// Source ranges are ignored, so bodies parsed from source code that differs
+// only in layout, comments, redundant parentheses or the spelling of equal
+// literal values are considered equal. Attributes are compared by name,
+// regardless of their order, while blocks are compared in order.
+//
+// This is useful for testing that a transformation of source code, such as
+// a round trip through a writer, preserves its meaning.
+func CompareBodies(a, b *Body) *Difference {
+	return compareBodies(a, b, nil)
+}
+
+// CompareExpressions compares two expressions for structural equality,
+// returning nil if they are equal or a description of the first difference
+// otherwise. Source ranges are ignored, as described for CompareBodies.
+func CompareExpressions(a, b Expression) *Difference {
+	return compareExprs(a, b, nil)
+}
+
+func compareBodies(a, b *Body, path []string) *Difference {
+	names := make([]string, 0, len(a.Attributes)+len(b.Attributes))
+	for name := range a.Attributes {
+		names = append(names, name)
+	}
+	for name := range b.Attributes {
+		if _, exists := a.Attributes[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		attrA, attrB := a.Attributes[name], b.Attributes[name]
+		switch {
+		case attrB == nil:
+			return &Difference{
+				Path:    path,
+				Summary: fmt.Sprintf("attribute %q is present only in the first body", name),
+				A:       attrA.SrcRange,
+				B:       b.SrcRange,
+			}
+		case attrA == nil:
+			return &Difference{
+				Path:    path,
+				Summary: fmt.Sprintf("attribute %q is present only in the second body", name),
+				A:       a.SrcRange,
+				B:       attrB.SrcRange,
+			}
+		}
+		if diff := compareExprs(attrA.Expr, attrB.Expr, appendPath(path, name)); diff != nil {
+			return diff
+		}
+	}
+
+	for i := 0; i < len(a.Blocks) || i < len(b.Blocks); i++ {
+		switch {
+		case i == len(b.Blocks):
+			return &Difference{
+				Path:    path,
+				Summary: fmt.Sprintf("block %s is present only in the first body", blockHeader(a.Blocks[i])),
+				A:       a.Blocks[i].DefRange(),
+				B:       b.SrcRange,
+			}
+		case i == len(a.Blocks):
+			return &Difference{
+				Path:    path,
+				Summary: fmt.Sprintf("block %s is present only in the second body", blockHeader(b.Blocks[i])),
+				A:       a.SrcRange,
+				B:       b.Blocks[i].DefRange(),
+			}
+		}
+
+		blockA, blockB := a.Blocks[i], b.Blocks[i]
+		headerA, headerB := blockHeader(blockA), blockHeader(blockB)
+		if headerA != headerB {
+			return &Difference{
+				Path:    path,
+				Summary: fmt.Sprintf("block %s in the first body corresponds to block %s in the second body", headerA, headerB),
+				A:       blockA.DefRange(),
+				B:       blockB.DefRange(),
+			}
+		}
+		if diff := compareBodies(blockA.Body, blockB.Body, appendPath(path, headerA)); diff != nil {
+			return diff
+		}
+	}
+
+	return nil
+}
+
+func blockHeader(block *Block) string {
+	var buf strings.Builder
+	buf.WriteString(block.Type)
+	for _, label := range block.Labels {
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.Quote(label))
+	}
+	return buf.String()
+}
+
+// appendPath returns a new path with the given element appended, leaving
+// the given path unchanged so that it can be shared between siblings.
+func appendPath(path []string, elem string) []string {
+	ret := make([]string, len(path), len(path)+1)
+	copy(ret, path)
+	return append(ret, elem)
+}
+
+var (
+	rangeType     = reflect.TypeOf(hcl.Range{})
+	posType       = reflect.TypeOf(hcl.Pos{})
+	ctyValueType  = reflect.TypeOf(cty.Value{})
+	ctyTypeType   = reflect.TypeOf(cty.Type{})
+	operationType = reflect.TypeOf(&Operation{})
+)
+
+func compareExprs(a, b Expression, path []string) *Difference {
+	c := &exprComparer{
+		path:   path,
+		rangeA: a.Range(),
+		rangeB: b.Range(),
+	}
+	if c.compare(reflect.ValueOf(a), reflect.ValueOf(b), "") {
+		return nil
+	}
+	return c.diff
+}
+
+// exprComparer compares expressions by walking their syntax trees with
+// reflection, so that it need not be updated for each new expression type.
+type exprComparer struct {
+	path           []string
+	rangeA, rangeB hcl.Range
+	diff           *Difference
+}
+
+// compare returns true if the two given values are equal, or otherwise
+// records a Difference and returns false. The given location describes the
+// position of the values within the expressions being compared.
+func (c *exprComparer) compare(a, b reflect.Value, loc string) bool {
+	if a.IsValid() != b.IsValid() {
+		return c.differ(loc, "one expression is nil")
+	}
+	if !a.IsValid() {
+		return true
+	}
+	if a.Type() != b.Type() {
+		return c.differ(loc, fmt.Sprintf("different expression types %s and %s", a.Type(), b.Type()))
+	}
+
+	switch a.Type() {
+	case rangeType, posType:
+		return true
+	case ctyValueType:
+		va, vb := a.Interface().(cty.Value), b.Interface().(cty.Value)
+		if !va.RawEquals(vb) {
+			return c.differ(loc, fmt.Sprintf("different values %#v and %#v", va, vb))
+		}
+		return true
+	case ctyTypeType:
+		ta, tb := a.Interface().(cty.Type), b.Interface().(cty.Type)
+		if !ta.Equals(tb) {
+			return c.differ(loc, fmt.Sprintf("different types %s and %s", ta.FriendlyName(), tb.FriendlyName()))
+		}
+		return true
+	case operationType:
+		// Operations are singletons, so we can compare them by identity.
+		if a.Pointer() != b.Pointer() {
+			return c.differ(loc, "different operators")
+		}
+		return true
+	}
+
+	switch a.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if a.IsNil() != b.IsNil() {
+			return c.differ(loc, "one expression is nil")
+		}
+		if a.IsNil() {
+			return true
+		}
+		if a.Kind() == reflect.Ptr {
+			if r, ok := a.Interface().(Expression); ok {
+				// Point at the innermost expression known to differ.
+				c.rangeA, c.rangeB = r.Range(), b.Interface().(Expression).Range()
+			}
+		}
+		return c.compare(a.Elem(), b.Elem(), loc)
+
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			field := a.Type().Field(i)
+			if field.PkgPath != "" {
+				// Unexported fields hold only evaluation state and
+				// details of the source text.
+				continue
+			}
+			if !c.compare(a.Field(i), b.Field(i), joinLoc(loc, field.Name)) {
+				return false
+			}
+		}
+		return true
+
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return c.differ(loc, fmt.Sprintf("different lengths %d and %d", a.Len(), b.Len()))
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !c.compare(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", loc, i)) {
+				return false
+			}
+		}
+		return true
+
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if a.Interface() != b.Interface() {
+			return c.differ(loc, fmt.Sprintf("different values %#v and %#v", a.Interface(), b.Interface()))
+		}
+		return true
+
+	default:
+		// Should never happen, since the above covers all of the kinds
+		// used in the syntax tree.
+		panic(fmt.Sprintf("can't compare %s in expressions", a.Type()))
+	}
+}
+
+func (c *exprComparer) differ(loc, summary string) bool {
+	path := c.path
+	if loc != "" {
+		path = appendPath(path, loc)
+	}
+	c.diff = &Difference{
+		Path:    path,
+		Summary: summary,
+		A:       c.rangeA,
+		B:       c.rangeB,
+	}
+	return false
+}
+
+func joinLoc(loc, field string) string {
+	if loc == "" {
+		return field
+	}
+	return loc + "." + field
+}
@@ -0,0 +1,134 @@
+package hclsyntax
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+func TestCompareBodies(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		wantDiff string
+	}{
+		{
+			``,
+			``,
+			``,
+		},
+		{
+			"a = 1\nb = \"x\"\n",
+			"# comment\nb   =   \"x\"\n\n\na = (1.0)\n",
+			``,
+		},
+		{
+			"a = <<EOT\nhello\nEOT\n",
+			"a = \"hello\\n\"\n",
+			``,
+		},
+		{
+			"a = [for x in y : x * 2 if x > 1]\nb = foo(a, b...)\nc = a.b[0].*.c\n",
+			"a = [ for x in y: x*2 if x>1 ]\nb = foo(a,b...)\nc = a.b[0].*.c\n",
+			``,
+		},
+		{
+			"a = 1\n",
+			"a = 2\n",
+			`a > Val: different values cty.NumberIntVal(1) and cty.NumberIntVal(2)`,
+		},
+		{
+			"a = 1\n",
+			"b = 1\n",
+			`attribute "a" is present only in the first body`,
+		},
+		{
+			"a = 1\nb = 1\n",
+			"a = 1\n",
+			`attribute "b" is present only in the first body`,
+		},
+		{
+			"a = 1 + 2\n",
+			"a = 1 - 2\n",
+			`a > Op: different operators`,
+		},
+		{
+			"a = [1, x]\n",
+			"a = [1, y]\n",
+			`a > Exprs[1].Traversal[0].Name: different values "x" and "y"`,
+		},
+		{
+			"a = [1, 2]\n",
+			"a = [1]\n",
+			`a > Exprs: different lengths 2 and 1`,
+		},
+		{
+			"a = \"x\"\n",
+			"a = x\n",
+			`a: different expression types *hclsyntax.TemplateExpr and *hclsyntax.ScopeTraversalExpr`,
+		},
+		{
+			"service \"web\" {\n  port = 80\n}\n",
+			"service \"web\" {\n  port = 8080\n}\n",
+			`service "web" > port > Val: different values cty.NumberIntVal(80) and cty.NumberIntVal(8080)`,
+		},
+		{
+			"service \"web\" {\n}\n",
+			"service \"db\" {\n}\n",
+			`block service "web" in the first body corresponds to block service "db" in the second body`,
+		},
+		{
+			"service \"web\" {\n}\n",
+			"service \"web\" {\n}\nservice \"db\" {\n}\n",
+			`block service "db" is present only in the second body`,
+		},
+		{
+			"a {\n  b {\n    c = true\n  }\n}\n",
+			"a {\n  b {\n  }\n}\n",
+			`a > b: attribute "c" is present only in the first body`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+"|"+test.b, func(t *testing.T) {
+			fileA, diags := ParseConfig([]byte(test.a), "a.hcl", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			fileB, diags := ParseConfig([]byte(test.b), "b.hcl", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			diff := CompareBodies(fileA.Body.(*Body), fileB.Body.(*Body))
+			var got string
+			if diff != nil {
+				got = diff.String()
+			}
+			if got != test.wantDiff {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.wantDiff)
+			}
+		})
+	}
+}
+
+func TestCompareExpressionsRanges(t *testing.T) {
+	a, diags := ParseExpression([]byte(`[1, [2, x]]`), "a.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	b, diags := ParseExpression([]byte(`[1, [2,    y]]`), "b.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	diff := CompareExpressions(a, b)
+	if diff == nil {
+		t.Fatalf("no difference found")
+	}
+	if got, want := diff.A.Start.Byte, 8; got != want {
+		t.Errorf("wrong start byte for A %d; want %d", got, want)
+	}
+	if got, want := diff.B.Start.Byte, 11; got != want {
+		t.Errorf("wrong start byte for B %d; want %d", got, want)
+	}
+}
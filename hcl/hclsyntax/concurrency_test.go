@@ -0,0 +1,141 @@
+package hclsyntax
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// The tests in this file exercise the concurrency guarantees described in
+// the documentation of the hcl package. They are most useful when run with
+// the race detector, and with more than one CPU:
+//
+//     go test -race -cpu 4 -run Concurrent ./hcl/hclsyntax
+
+const concurrencyTestConfig = `
+splat     = items[*].name
+full      = [for i, item in items : "${i}:${item.name}" if item.enabled]
+object    = {for item in items : item.name => strlen(item.name)}
+template  = <<EOT
+%{ for item in items ~}
+- ${upper(item.name)}
+%{ endfor ~}
+EOT
+nested    = [for item in items : [for tag in item.tags : tag]][*][0]
+condition = length(items) > 2 ? items[2].name : "none"
+
+block "a" {
+  value = items[*].tags[*]
+}
+`
+
+func TestConcurrentParse(t *testing.T) {
+	const count = 20
+
+	files := make([]*hcl.File, count)
+	diags := make([]hcl.Diagnostics, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			src := fmt.Sprintf("%sindex = %d\n", concurrencyTestConfig, i)
+			filename := fmt.Sprintf("file%d.hcl", i)
+			files[i], diags[i] = ParseConfig([]byte(src), filename, hcl.Pos{Line: 1, Column: 1})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, file := range files {
+		if diags[i].HasErrors() {
+			t.Fatalf("unexpected errors for file %d: %s", i, diags[i].Error())
+		}
+		attrs := file.Body.(*Body).Attributes
+		if got, want := len(attrs), 7; got != want {
+			t.Errorf("file %d has %d attributes; want %d", i, got, want)
+		}
+		val, _ := attrs["index"].Expr.Value(nil)
+		if !val.RawEquals(cty.NumberIntVal(int64(i))) {
+			t.Errorf("file %d has wrong index %#v", i, val)
+		}
+		if got, want := attrs["index"].SrcRange.Filename, fmt.Sprintf("file%d.hcl", i); got != want {
+			t.Errorf("file %d has wrong filename %q; want %q", i, got, want)
+		}
+	}
+}
+
+func TestConcurrentEvaluation(t *testing.T) {
+	file, diags := ParseConfig([]byte(concurrencyTestConfig), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+	body := file.Body.(*Body)
+
+	var items []cty.Value
+	for i := 0; i < 1000; i++ {
+		items = append(items, cty.ObjectVal(map[string]cty.Value{
+			"name":    cty.StringVal(fmt.Sprintf("item%d", i)),
+			"enabled": cty.BoolVal(i%2 == 0),
+			"tags":    cty.TupleVal([]cty.Value{cty.StringVal(strings.Repeat("t", i%5+1))}),
+		}))
+	}
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"items": cty.TupleVal(items),
+		},
+		Functions: map[string]function.Function{
+			"length": stdlib.LengthFunc,
+			"strlen": stdlib.StrlenFunc,
+			"upper":  stdlib.UpperFunc,
+		},
+	}
+
+	var exprs []hcl.Expression
+	for _, attr := range body.Attributes {
+		exprs = append(exprs, attr.Expr)
+	}
+	for _, block := range body.Blocks {
+		for _, attr := range block.Body.Attributes {
+			exprs = append(exprs, attr.Expr)
+		}
+	}
+
+	// We evaluate each expression once up front to obtain the expected
+	// results, and then many times concurrently, all in the same context.
+	wants := make([]cty.Value, len(exprs))
+	for i, expr := range exprs {
+		val, diags := expr.Value(ctx)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Error())
+		}
+		wants[i] = val
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 10; n++ {
+				for i, expr := range exprs {
+					val, diags := expr.Value(ctx)
+					if diags.HasErrors() {
+						t.Errorf("unexpected errors: %s", diags.Error())
+						return
+					}
+					if !val.RawEquals(wants[i]) {
+						t.Errorf("wrong result for %s\ngot:  %#v\nwant: %#v", expr.Range(), val, wants[i])
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
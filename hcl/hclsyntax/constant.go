@@ -0,0 +1,55 @@
+package hclsyntax
+
+import (
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// ConstantValue determines whether the given expression is constant, which
+// is to say that its value does not depend on the evaluation context, and
+// if so returns its value. The second return value is false if the
+// expression is not constant, in which case the returned value is
+// cty.DynamicVal.
+//
+// An expression is constant if it refers to no variables, calls only
+// functions from the given table, evaluates without errors and produces a
+// wholly known value. The caller must include only pure functions in the
+// table, whose results depend only on their arguments. It may be nil, in
+// which case expressions that call functions are never constant.
+//
+// This is useful for tools that want to replace constant expressions with
+// their values, or detect conditional expressions whose conditions are
+// always true or always false:
+//
+//     if cond, ok := expr.(*hclsyntax.ConditionalExpr); ok {
+//         if val, ok := hclsyntax.ConstantValue(cond.Condition, nil); ok {
+//             // The condition always has the value val.
+//         }
+//     }
+func ConstantValue(expr Expression, funcs map[string]function.Function) (cty.Value, bool) {
+	if len(expr.Variables()) != 0 {
+		return cty.DynamicVal, false
+	}
+
+	constant := true
+	VisitAll(expr, func(node Node) hcl.Diagnostics {
+		if call, ok := node.(*FunctionCallExpr); ok {
+			if _, exists := funcs[call.Name]; !exists {
+				constant = false
+			}
+		}
+		return nil
+	})
+	if !constant {
+		return cty.DynamicVal, false
+	}
+
+	val, diags := expr.Value(&hcl.EvalContext{
+		Functions: funcs,
+	})
+	if diags.HasErrors() || !val.IsWhollyKnown() {
+		return cty.DynamicVal, false
+	}
+	return val, true
+}
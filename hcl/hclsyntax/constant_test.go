@@ -0,0 +1,58 @@
+package hclsyntax
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestConstantValue(t *testing.T) {
+	funcs := map[string]function.Function{
+		"upper": stdlib.UpperFunc,
+		"unknown": function.New(&function.Spec{
+			Type: function.StaticReturnType(cty.String),
+			Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+				return cty.UnknownVal(cty.String), nil
+			},
+		}),
+	}
+
+	tests := []struct {
+		src    string
+		want   cty.Value
+		wantOK bool
+	}{
+		{`1`, cty.NumberIntVal(1), true},
+		{`"a${1 + 2}"`, cty.StringVal("a3"), true},
+		{`1 > 2 ? "yes" : "no"`, cty.StringVal("no"), true},
+		{`upper("a")`, cty.StringVal("A"), true},
+		{`[for x in [1, 2] : x * 2]`, cty.TupleVal([]cty.Value{cty.NumberIntVal(2), cty.NumberIntVal(4)}), true},
+		{`{ a = [1, 2][*] }`, cty.ObjectVal(map[string]cty.Value{"a": cty.TupleVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)})}), true},
+		{`var.a`, cty.DynamicVal, false},
+		{`[for x in var.xs : x]`, cty.DynamicVal, false},
+		{`"${var.a}"`, cty.DynamicVal, false},
+		{`lower("A")`, cty.DynamicVal, false},
+		{`unknown()`, cty.DynamicVal, false},
+		{`1 + "a"`, cty.DynamicVal, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			got, ok := ConstantValue(expr, funcs)
+			if ok != test.wantOK {
+				t.Errorf("wrong ok %t; want %t", ok, test.wantOK)
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong value\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
@@ -21,3 +21,50 @@ func setDiagEvalContext(diags hcl.Diagnostics, expr hcl.Expression, ctx *hcl.Eva
 		}
 	}
 }
+
+// sensitiveErrorDetail is appended to the detail of diagnostics whose
+// original detail was withheld because it might reveal a sensitive value.
+const sensitiveErrorDetail = "The underlying error message is not shown because it may include a sensitive value."
+
+// redactIndexDiags is an internal helper that replaces, in-place, the detail
+// of any "Invalid index" diagnostics with a generic message that does not
+// include the given key or describe the collection, for situations where
+// either is derived from a sensitive value. The same immutability caveats
+// apply as for setDiagEvalContext.
+func redactIndexDiags(diags hcl.Diagnostics) {
+	for _, diag := range diags {
+		if diag.Code == hcl.DiagInvalidIndex {
+			diag.Detail = "The given key does not identify an element in this collection value."
+		}
+	}
+}
+
+// FunctionCallDiagExtra is an interface implemented by the value in the Extra
+// field of the diagnostics returned when a function called by a
+// FunctionCallExpr returns an error, allowing callers to recognize errors
+// from particular functions:
+//
+//     if extra, ok := diag.Extra.(hclsyntax.FunctionCallDiagExtra); ok {
+//         if pathErr, ok := extra.FunctionCallError().(*os.PathError); ok {
+//             // ...
+//         }
+//     }
+//
+// The Extra field is not set if the error might reveal a sensitive value.
+type FunctionCallDiagExtra interface {
+	CalledFunctionName() string
+	FunctionCallError() error
+}
+
+type functionCallDiagExtra struct {
+	calledFunctionName string
+	functionCallError  error
+}
+
+func (e *functionCallDiagExtra) CalledFunctionName() string {
+	return e.calledFunctionName
+}
+
+func (e *functionCallDiagExtra) FunctionCallError() error {
+	return e.functionCallError
+}
@@ -1,7 +1,11 @@
 package hclsyntax
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/agext/levenshtein"
+	"github.com/hashicorp/hcl2/hcl"
 )
 
 // nameSuggestion tries to find a name from the given slice of suggested names
@@ -22,3 +26,49 @@ func nameSuggestion(given string, suggestions []string) string {
 	}
 	return ""
 }
+
+// renameSuggestion returns a single suggested fix that replaces the
+// identifier at the given range with the given suggested name.
+func renameSuggestion(rng hcl.Range, name string) []hcl.Suggestion {
+	return []hcl.Suggestion{
+		{
+			Description: fmt.Sprintf("Rename to %q", name),
+			Edits: []hcl.Edit{
+				{
+					Range:       rng,
+					Replacement: name,
+				},
+			},
+		},
+	}
+}
+
+// maxCandidateNames is the largest number of supported names that
+// candidateNames will list in a diagnostic message.
+const maxCandidateNames = 8
+
+// candidateNames returns a sentence listing the given supported names, for
+// appending to a diagnostic message about an unsupported name when there is
+// no close suggestion. It returns the empty string if there are no names or
+// too many names for the list to be helpful.
+//
+// The noun and plural arguments name the kind of item being listed, such as
+// "argument" and "arguments".
+func candidateNames(noun, plural string, names []string) string {
+	switch {
+	case len(names) == 0 || len(names) > maxCandidateNames:
+		return ""
+	case len(names) == 1:
+		return fmt.Sprintf(" The only supported %s is %q.", noun, names[0])
+	}
+
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	last := len(quoted) - 1
+	return fmt.Sprintf(
+		" The supported %s are %s and %s.",
+		plural, strings.Join(quoted[:last], ", "), quoted[last],
+	)
+}
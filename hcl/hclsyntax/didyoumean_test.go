@@ -1,6 +1,9 @@
 package hclsyntax
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 func TestNameSuggestion(t *testing.T) {
 	var keywords = []string{"false", "true", "null"}
@@ -49,3 +52,25 @@ func TestNameSuggestion(t *testing.T) {
 		})
 	}
 }
+
+func TestCandidateNames(t *testing.T) {
+	tests := []struct {
+		Names []string
+		Want  string
+	}{
+		{nil, ``},
+		{[]string{"a"}, ` The only supported argument is "a".`},
+		{[]string{"a", "b"}, ` The supported arguments are "a" and "b".`},
+		{[]string{"a", "b", "c"}, ` The supported arguments are "a", "b" and "c".`},
+		{[]string{"a", "b", "c", "d", "e", "f", "g", "h", "i"}, ``},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d names", len(test.Names)), func(t *testing.T) {
+			got := candidateNames("argument", "arguments", test.Names)
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.Want)
+			}
+		})
+	}
+}
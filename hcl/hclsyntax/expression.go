@@ -2,6 +2,9 @@ package hclsyntax
 
 import (
 	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
 	"sync"
 
 	"github.com/hashicorp/hcl2/hcl"
@@ -30,13 +33,16 @@ var assertExprImplExpr hcl.Expression = Expression(nil)
 type LiteralValueExpr struct {
 	Val      cty.Value
 	SrcRange hcl.Range
+
+	numberLit string
 }
 
 func (e *LiteralValueExpr) walkChildNodes(w internalWalkFunc) {
 	// Literal values have no child nodes
 }
 
-func (e *LiteralValueExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *LiteralValueExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	return e.Val, nil
 }
 
@@ -48,6 +54,15 @@ func (e *LiteralValueExpr) StartRange() hcl.Range {
 	return e.SrcRange
 }
 
+// NumberLit returns the text of the number literal that the expression was
+// parsed from, such as "1.50" or "1e3", so that tools rendering the
+// expression as source code can preserve its original form. The result is
+// empty for all other literal values, including numbers that were
+// constructed rather than parsed.
+func (e *LiteralValueExpr) NumberLit() string {
+	return e.numberLit
+}
+
 // Implementation for hcl.AbsTraversalForExpr.
 func (e *LiteralValueExpr) AsTraversal() hcl.Traversal {
 	// This one's a little weird: the contract for AsTraversal is to interpret
@@ -104,7 +119,8 @@ func (e *ScopeTraversalExpr) walkChildNodes(w internalWalkFunc) {
 	// Scope traversals have no child nodes
 }
 
-func (e *ScopeTraversalExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *ScopeTraversalExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	val, diags := e.Traversal.TraverseAbs(ctx)
 	setDiagEvalContext(diags, e, ctx)
 	return val, diags
@@ -135,9 +151,13 @@ func (e *RelativeTraversalExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.Source)
 }
 
-func (e *RelativeTraversalExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *RelativeTraversalExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	src, diags := e.Source.Value(ctx)
-	ret, travDiags := e.Traversal.TraverseRel(src)
+	ret, travDiags := ctx.TraverseRel(e.Traversal, src)
+	if ctx.ExpressionSensitive(e.Source) {
+		redactIndexDiags(travDiags)
+	}
 	setDiagEvalContext(travDiags, e, ctx)
 	diags = append(diags, travDiags...)
 	return ret, diags
@@ -186,32 +206,18 @@ func (e *FunctionCallExpr) walkChildNodes(w internalWalkFunc) {
 	}
 }
 
-func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	var diags hcl.Diagnostics
 
-	var f function.Function
-	exists := false
-	hasNonNilMap := false
-	thisCtx := ctx
-	for thisCtx != nil {
-		if thisCtx.Functions == nil {
-			thisCtx = thisCtx.Parent()
-			continue
-		}
-		hasNonNilMap = true
-		f, exists = thisCtx.Functions[e.Name]
-		if exists {
-			break
-		}
-		thisCtx = thisCtx.Parent()
-	}
-
+	f, exists := ctx.Function(e.Name)
 	if !exists {
-		if !hasNonNilMap {
+		if !ctx.FunctionsAllowed() {
 			return cty.DynamicVal, hcl.Diagnostics{
 				{
 					Severity:    hcl.DiagError,
 					Summary:     "Function calls not allowed",
+					Code:        hcl.DiagFunctionCallsNotAllowed,
 					Detail:      "Functions may not be called here.",
 					Subject:     e.Range().Ptr(),
 					Expression:  e,
@@ -220,19 +226,25 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 			}
 		}
 
-		avail := make([]string, 0, len(ctx.Functions))
-		for name := range ctx.Functions {
+		funcs := ctx.EffectiveFunctions()
+		avail := make([]string, 0, len(funcs))
+		for name := range funcs {
 			avail = append(avail, name)
 		}
+		sort.Strings(avail)
 		suggestion := nameSuggestion(e.Name, avail)
 		if suggestion != "" {
 			suggestion = fmt.Sprintf(" Did you mean %q?", suggestion)
 		}
+		if ns := hcl.FunctionNamespace(e.Name); ns != "" && !ctx.HasFunctionNamespace(ns) {
+			suggestion = fmt.Sprintf(" No functions are available in the %q namespace.%s", ns, suggestion)
+		}
 
 		return cty.DynamicVal, hcl.Diagnostics{
 			{
 				Severity:    hcl.DiagError,
 				Summary:     "Call to unknown function",
+				Code:        hcl.DiagCallToUnknownFunction,
 				Detail:      fmt.Sprintf("There is no function named %q.%s", e.Name, suggestion),
 				Subject:     &e.NameRange,
 				Context:     e.Range().Ptr(),
@@ -246,6 +258,7 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 	varParam := f.VarParam()
 
 	args := e.Args
+	expandedFrom := -1 // index of the first argument produced by expansion, if any
 	if e.ExpandFinal {
 		if len(args) < 1 {
 			// should never happen if the parser is behaving
@@ -264,6 +277,7 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 				diags = append(diags, &hcl.Diagnostic{
 					Severity:    hcl.DiagError,
 					Summary:     "Invalid expanding argument value",
+					Code:        hcl.DiagInvalidExpandingArgumentValue,
 					Detail:      "The expanding argument (indicated by ...) must not be null.",
 					Subject:     expandExpr.Range().Ptr(),
 					Context:     e.Range().Ptr(),
@@ -286,11 +300,13 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 					SrcRange: expandExpr.Range(),
 				})
 			}
+			expandedFrom = len(args) - 1
 			args = newArgs
 		default:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity:    hcl.DiagError,
 				Summary:     "Invalid expanding argument value",
+				Code:        hcl.DiagInvalidExpandingArgumentValue,
 				Detail:      "The expanding argument (indicated by ...) must be of a tuple, list, or set type.",
 				Subject:     expandExpr.Range().Ptr(),
 				Context:     e.Range().Ptr(),
@@ -311,6 +327,7 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Not enough function arguments",
+				Code:     hcl.DiagNotEnoughFunctionArguments,
 				Detail: fmt.Sprintf(
 					"Function %q expects%s %d argument(s). Missing value for %q.",
 					e.Name, qual, len(params), missing.Name,
@@ -328,6 +345,7 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Too many function arguments",
+				Code:     hcl.DiagTooManyFunctionArguments,
 				Detail: fmt.Sprintf(
 					"Function %q expects only %d argument(s).",
 					e.Name, len(params),
@@ -361,13 +379,14 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid function argument",
+				Code:     hcl.DiagInvalidFunctionArgument,
 				Detail: fmt.Sprintf(
-					"Invalid value for %q parameter: %s.",
-					param.Name, err,
+					"Invalid value for %s: %s.",
+					e.paramDesc(param, i, expandedFrom), err,
 				),
-				Subject:     argExpr.StartRange().Ptr(),
+				Subject:     argExpr.Range().Ptr(),
 				Context:     e.Range().Ptr(),
-				Expression:  argExpr,
+				Expression:  e.argSourceExpr(i, expandedFrom),
 				EvalContext: ctx,
 			})
 		}
@@ -384,8 +403,14 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 
 	resultVal, err := f.Call(argVals)
 	if err != nil {
-		switch terr := err.(type) {
-		case function.ArgError:
+		terr, isArgErr := err.(function.ArgError)
+		if isArgErr && (terr.Index < 0 || terr.Index >= len(args)) {
+			// A misbehaving function reported an argument that doesn't
+			// exist, so we'll just report it as a failure of the whole call.
+			isArgErr = false
+		}
+		switch {
+		case isArgErr:
 			i := terr.Index
 			var param *function.Parameter
 			if i < len(params) {
@@ -393,35 +418,49 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 			} else {
 				param = varParam
 			}
-			argExpr := e.Args[i]
+			argExpr := args[i]
+			srcExpr := e.argSourceExpr(i, expandedFrom)
+
+			// The function's error message may include the argument value,
+			// so we must not show it if that value is sensitive.
+			detail := fmt.Sprintf("Invalid value for %s: %s.", e.paramDesc(param, i, expandedFrom), err)
+			var extra interface{} = &functionCallDiagExtra{e.Name, err}
+			if ctx.ExpressionSensitive(srcExpr) {
+				detail = fmt.Sprintf("Invalid value for %s. %s", e.paramDesc(param, i, expandedFrom), sensitiveErrorDetail)
+				extra = nil
+			}
 
 			// TODO: we should also unpick a PathError here and show the
 			// path to the deep value where the error was detected.
 			diags = append(diags, &hcl.Diagnostic{
-				Severity: hcl.DiagError,
-				Summary:  "Invalid function argument",
-				Detail: fmt.Sprintf(
-					"Invalid value for %q parameter: %s.",
-					param.Name, err,
-				),
-				Subject:     argExpr.StartRange().Ptr(),
+				Severity:    hcl.DiagError,
+				Summary:     "Invalid function argument",
+				Code:        hcl.DiagInvalidFunctionArgument,
+				Detail:      detail,
+				Subject:     argExpr.Range().Ptr(),
 				Context:     e.Range().Ptr(),
-				Expression:  argExpr,
+				Expression:  srcExpr,
 				EvalContext: ctx,
+				Extra:       extra,
 			})
 
 		default:
+			detail := fmt.Sprintf("Call to function %q failed: %s.", e.Name, err)
+			var extra interface{} = &functionCallDiagExtra{e.Name, err}
+			if ctx.ExpressionSensitive(e) {
+				detail = fmt.Sprintf("Call to function %q failed. %s", e.Name, sensitiveErrorDetail)
+				extra = nil
+			}
 			diags = append(diags, &hcl.Diagnostic{
-				Severity: hcl.DiagError,
-				Summary:  "Error in function call",
-				Detail: fmt.Sprintf(
-					"Call to function %q failed: %s.",
-					e.Name, err,
-				),
+				Severity:    hcl.DiagError,
+				Summary:     "Error in function call",
+				Code:        hcl.DiagErrorInFunctionCall,
+				Detail:      detail,
 				Subject:     e.StartRange().Ptr(),
 				Context:     e.Range().Ptr(),
 				Expression:  e,
 				EvalContext: ctx,
+				Extra:       extra,
 			})
 		}
 
@@ -431,6 +470,28 @@ func (e *FunctionCallExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 	return resultVal, diags
 }
 
+// paramDesc returns a description of the parameter that the argument at the
+// given index is passed to, for use in diagnostic messages. expandedFrom is
+// the index of the first argument produced by expanding the final argument,
+// or -1 if there was no expansion.
+func (e *FunctionCallExpr) paramDesc(param *function.Parameter, i, expandedFrom int) string {
+	if expandedFrom >= 0 && i >= expandedFrom {
+		return fmt.Sprintf("%q parameter (element %d of the expanded argument)", param.Name, i-expandedFrom)
+	}
+	return fmt.Sprintf("%q parameter", param.Name)
+}
+
+// argSourceExpr returns the expression in the configuration that produced
+// the argument at the given index. This is usually just the argument
+// expression itself, but arguments produced by expanding the final argument
+// all originate from that final argument expression.
+func (e *FunctionCallExpr) argSourceExpr(i, expandedFrom int) Expression {
+	if expandedFrom >= 0 && i >= expandedFrom {
+		return e.Args[len(e.Args)-1]
+	}
+	return e.Args[i]
+}
+
 func (e *FunctionCallExpr) Range() hcl.Range {
 	return hcl.RangeBetween(e.NameRange, e.CloseParenRange)
 }
@@ -468,7 +529,8 @@ func (e *ConditionalExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.FalseResult)
 }
 
-func (e *ConditionalExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *ConditionalExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	trueResult, trueDiags := e.TrueResult.Value(ctx)
 	falseResult, falseDiags := e.FalseResult.Value(ctx)
 	var diags hcl.Diagnostics
@@ -507,6 +569,7 @@ func (e *ConditionalExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostic
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Inconsistent conditional result types",
+				Code:     hcl.DiagInconsistentConditionalResultTypes,
 				Detail: fmt.Sprintf(
 					// FIXME: Need a helper function for showing natural-language type diffs,
 					// since this will generate some useless messages in some cases, like
@@ -529,6 +592,7 @@ func (e *ConditionalExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostic
 		diags = append(diags, &hcl.Diagnostic{
 			Severity:    hcl.DiagError,
 			Summary:     "Null condition",
+			Code:        hcl.DiagNullCondition,
 			Detail:      "The condition value is null. Conditions must either be true or false.",
 			Subject:     e.Condition.Range().Ptr(),
 			Context:     &e.SrcRange,
@@ -545,6 +609,7 @@ func (e *ConditionalExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostic
 		diags = append(diags, &hcl.Diagnostic{
 			Severity:    hcl.DiagError,
 			Summary:     "Incorrect condition type",
+			Code:        hcl.DiagIncorrectConditionType,
 			Detail:      fmt.Sprintf("The condition expression must be of type bool."),
 			Subject:     e.Condition.Range().Ptr(),
 			Context:     &e.SrcRange,
@@ -564,6 +629,7 @@ func (e *ConditionalExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostic
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Inconsistent conditional result types",
+					Code:     hcl.DiagInconsistentConditionalResultTypes,
 					Detail: fmt.Sprintf(
 						"The true result value has the wrong type: %s.",
 						err.Error(),
@@ -587,6 +653,7 @@ func (e *ConditionalExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostic
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Inconsistent conditional result types",
+					Code:     hcl.DiagInconsistentConditionalResultTypes,
 					Detail: fmt.Sprintf(
 						"The false result value has the wrong type: %s.",
 						err.Error(),
@@ -624,14 +691,18 @@ func (e *IndexExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.Key)
 }
 
-func (e *IndexExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *IndexExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	var diags hcl.Diagnostics
 	coll, collDiags := e.Collection.Value(ctx)
 	key, keyDiags := e.Key.Value(ctx)
 	diags = append(diags, collDiags...)
 	diags = append(diags, keyDiags...)
 
-	val, indexDiags := hcl.Index(coll, key, &e.SrcRange)
+	val, indexDiags := ctx.Index(coll, key, &e.SrcRange)
+	if ctx.ExpressionSensitive(e.Key) || ctx.ExpressionSensitive(e.Collection) {
+		redactIndexDiags(indexDiags)
+	}
 	setDiagEvalContext(indexDiags, e, ctx)
 	diags = append(diags, indexDiags...)
 	return val, diags
@@ -658,7 +729,8 @@ func (e *TupleConsExpr) walkChildNodes(w internalWalkFunc) {
 	}
 }
 
-func (e *TupleConsExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *TupleConsExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	var vals []cty.Value
 	var diags hcl.Diagnostics
 
@@ -708,7 +780,8 @@ func (e *ObjectConsExpr) walkChildNodes(w internalWalkFunc) {
 	}
 }
 
-func (e *ObjectConsExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *ObjectConsExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	var vals map[string]cty.Value
 	var diags hcl.Diagnostics
 
@@ -739,6 +812,7 @@ func (e *ObjectConsExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics
 			diags = append(diags, &hcl.Diagnostic{
 				Severity:    hcl.DiagError,
 				Summary:     "Null value as key",
+				Code:        hcl.DiagNullValueAsKey,
 				Detail:      "Can't use a null value as a key.",
 				Subject:     item.ValueExpr.Range().Ptr(),
 				Expression:  item.KeyExpr,
@@ -754,6 +828,7 @@ func (e *ObjectConsExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics
 			diags = append(diags, &hcl.Diagnostic{
 				Severity:    hcl.DiagError,
 				Summary:     "Incorrect key type",
+				Code:        hcl.DiagIncorrectKeyType,
 				Detail:      fmt.Sprintf("Can't use this value as a key: %s.", err.Error()),
 				Subject:     item.KeyExpr.Range().Ptr(),
 				Expression:  item.KeyExpr,
@@ -824,7 +899,8 @@ func (e *ObjectConsKeyExpr) walkChildNodes(w internalWalkFunc) {
 	}
 }
 
-func (e *ObjectConsKeyExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *ObjectConsKeyExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	// Because we accept a naked identifier as a literal key rather than a
 	// reference, it's confusing to accept a traversal containing periods
 	// here since we can't tell if the user intends to create a key with
@@ -839,6 +915,7 @@ func (e *ObjectConsKeyExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnost
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Ambiguous attribute key",
+			Code:     hcl.DiagAmbiguousAttributeKey,
 			Detail:   "If this expression is intended to be a reference, wrap it in parentheses. If it's instead intended as a literal name containing periods, wrap it in quotes to create a string literal.",
 			Subject:  e.Range().Ptr(),
 		})
@@ -896,7 +973,8 @@ type ForExpr struct {
 	CloseRange hcl.Range
 }
 
-func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *ForExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	var diags hcl.Diagnostics
 
 	collVal, collDiags := e.CollExpr.Value(ctx)
@@ -906,6 +984,7 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity:    hcl.DiagError,
 			Summary:     "Iteration over null value",
+			Code:        hcl.DiagIterationOverNullValue,
 			Detail:      "A null value cannot be used as the collection in a 'for' expression.",
 			Subject:     e.CollExpr.Range().Ptr(),
 			Context:     &e.SrcRange,
@@ -921,6 +1000,7 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Iteration over non-iterable value",
+			Code:     hcl.DiagIterationOverNonIterableValue,
 			Detail: fmt.Sprintf(
 				"A value of type %s cannot be used as the collection in a 'for' expression.",
 				collVal.Type().FriendlyName(),
@@ -954,6 +1034,7 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity:    hcl.DiagError,
 				Summary:     "Condition is null",
+				Code:        hcl.DiagConditionIsNull,
 				Detail:      "The value of the 'if' clause must not be null.",
 				Subject:     e.CondExpr.Range().Ptr(),
 				Context:     &e.SrcRange,
@@ -967,6 +1048,7 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity:    hcl.DiagError,
 				Summary:     "Invalid 'for' condition",
+				Code:        hcl.DiagInvalidForCondition,
 				Detail:      fmt.Sprintf("The 'if' clause value is invalid: %s.", err.Error()),
 				Subject:     e.CondExpr.Range().Ptr(),
 				Context:     &e.SrcRange,
@@ -980,6 +1062,19 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 		}
 	}
 
+	// If the collection is derived from a sensitive value then so are the
+	// iterator symbols, so that diagnostics about the key and value
+	// expressions will not reveal the elements of the collection.
+	var iterSensitive map[string]bool
+	if ctx.ExpressionSensitive(e.CollExpr) {
+		iterSensitive = map[string]bool{e.ValVar: true}
+		if e.KeyVar != "" {
+			iterSensitive[e.KeyVar] = true
+		}
+	}
+
+	limit := ctx.ForElementsLimit()
+
 	if e.KeyExpr != nil {
 		// Producing an object
 		var vals map[string]cty.Value
@@ -990,10 +1085,16 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 			vals = map[string]cty.Value{}
 		}
 
+		// firstIters records the item that first produced each result key,
+		// to describe any later collision.
+		firstIters := map[string]forItem{}
+		isSet := collVal.Type().IsSetType()
+
 		it := collVal.ElementIterator()
 
 		known := true
-		for it.Next() {
+		produced := 0 // includes values for duplicate and grouped keys
+		for pos := 0; it.Next(); pos++ {
 			k, v := it.Element()
 			childCtx := ctx.NewChild()
 			childCtx.Variables = map[string]cty.Value{}
@@ -1001,6 +1102,7 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 				childCtx.Variables[e.KeyVar] = k
 			}
 			childCtx.Variables[e.ValVar] = v
+			childCtx.SensitiveVariables = iterSensitive
 
 			if e.CondExpr != nil {
 				includeRaw, condDiags := e.CondExpr.Value(childCtx)
@@ -1010,6 +1112,7 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 						diags = append(diags, &hcl.Diagnostic{
 							Severity:    hcl.DiagError,
 							Summary:     "Invalid 'for' condition",
+							Code:        hcl.DiagInvalidForCondition,
 							Detail:      "The value of the 'if' clause must not be null.",
 							Subject:     e.CondExpr.Range().Ptr(),
 							Context:     &e.SrcRange,
@@ -1026,6 +1129,7 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 						diags = append(diags, &hcl.Diagnostic{
 							Severity:    hcl.DiagError,
 							Summary:     "Invalid 'for' condition",
+							Code:        hcl.DiagInvalidForCondition,
 							Detail:      fmt.Sprintf("The 'if' clause value is invalid: %s.", err.Error()),
 							Subject:     e.CondExpr.Range().Ptr(),
 							Context:     &e.SrcRange,
@@ -1054,6 +1158,7 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity:    hcl.DiagError,
 						Summary:     "Invalid object key",
+						Code:        hcl.DiagInvalidObjectKey,
 						Detail:      "Key expression in 'for' expression must not produce a null value.",
 						Subject:     e.KeyExpr.Range().Ptr(),
 						Context:     &e.SrcRange,
@@ -1075,6 +1180,7 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity:    hcl.DiagError,
 						Summary:     "Invalid object key",
+						Code:        hcl.DiagInvalidObjectKey,
 						Detail:      fmt.Sprintf("The key expression produced an invalid result: %s.", err.Error()),
 						Subject:     e.KeyExpr.Range().Ptr(),
 						Context:     &e.SrcRange,
@@ -1089,18 +1195,36 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 			val, valDiags := e.ValExpr.Value(childCtx)
 			diags = append(diags, valDiags...)
 
+			produced++
+			if limit > 0 && produced > limit {
+				return cty.DynamicVal, append(diags, e.tooManyElementsDiag(ctx, limit))
+			}
+
 			if e.Group {
 				k := key.AsString()
 				groupVals[k] = append(groupVals[k], val)
 			} else {
-				k := key.AsString()
-				if _, exists := vals[k]; exists {
+				rk := key.AsString()
+				if _, exists := vals[rk]; exists {
+					keyStr := strconv.Quote(rk)
+					if childCtx.ExpressionSensitive(e.KeyExpr) {
+						keyStr = "(sensitive value)"
+					}
+					var items string
+					if iterSensitive == nil {
+						items = fmt.Sprintf(
+							": %s and %s",
+							e.describeItem(firstIters[rk], isSet),
+							e.describeItem(forItem{k, pos}, isSet),
+						)
+					}
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Duplicate object key",
+						Code:     hcl.DiagDuplicateObjectKey,
 						Detail: fmt.Sprintf(
-							"Two different items produced the key %q in this 'for' expression. If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.",
-							k,
+							"Two different items produced the key %s in this 'for' expression%s. If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.",
+							keyStr, items,
 						),
 						Subject:     e.KeyExpr.Range().Ptr(),
 						Context:     &e.SrcRange,
@@ -1108,7 +1232,8 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 						EvalContext: childCtx,
 					})
 				} else {
-					vals[key.AsString()] = val
+					vals[rk] = val
+					firstIters[rk] = forItem{k, pos}
 				}
 			}
 		}
@@ -1141,6 +1266,7 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 				childCtx.Variables[e.KeyVar] = k
 			}
 			childCtx.Variables[e.ValVar] = v
+			childCtx.SensitiveVariables = iterSensitive
 
 			if e.CondExpr != nil {
 				includeRaw, condDiags := e.CondExpr.Value(childCtx)
@@ -1150,6 +1276,7 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 						diags = append(diags, &hcl.Diagnostic{
 							Severity:    hcl.DiagError,
 							Summary:     "Invalid 'for' condition",
+							Code:        hcl.DiagInvalidForCondition,
 							Detail:      "The value of the 'if' clause must not be null.",
 							Subject:     e.CondExpr.Range().Ptr(),
 							Context:     &e.SrcRange,
@@ -1174,6 +1301,7 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 						diags = append(diags, &hcl.Diagnostic{
 							Severity:    hcl.DiagError,
 							Summary:     "Invalid 'for' condition",
+							Code:        hcl.DiagInvalidForCondition,
 							Detail:      fmt.Sprintf("The 'if' clause value is invalid: %s.", err.Error()),
 							Subject:     e.CondExpr.Range().Ptr(),
 							Context:     &e.SrcRange,
@@ -1194,6 +1322,9 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 			val, valDiags := e.ValExpr.Value(childCtx)
 			diags = append(diags, valDiags...)
 			vals = append(vals, val)
+			if limit > 0 && len(vals) > limit {
+				return cty.DynamicVal, append(diags, e.tooManyElementsDiag(ctx, limit))
+			}
 		}
 
 		if !known {
@@ -1204,6 +1335,21 @@ func (e *ForExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 	}
 }
 
+// tooManyElementsDiag returns the diagnostic for a 'for' expression that
+// produces more elements than the given limit, as returned by
+// EvalContext.ForElementsLimit.
+func (e *ForExpr) tooManyElementsDiag(ctx *hcl.EvalContext, limit int) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity:    hcl.DiagError,
+		Summary:     "Too many elements",
+		Code:        hcl.DiagTooManyForElements,
+		Detail:      fmt.Sprintf("This 'for' expression would produce more than the maximum of %d elements.", limit),
+		Subject:     e.SrcRange.Ptr(),
+		Expression:  e,
+		EvalContext: ctx,
+	}
+}
+
 func (e *ForExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.CollExpr)
 
@@ -1233,6 +1379,67 @@ func (e *ForExpr) walkChildNodes(w internalWalkFunc) {
 	}
 }
 
+// forItem identifies an item of the collection of a ForExpr, for use in
+// diagnostics.
+type forItem struct {
+	key cty.Value // the key or index, or the element itself for a set
+	pos int       // the position of the item in iteration order
+}
+
+// describeItem describes the given item of the collection, which is a set if
+// isSet is true, for use in diagnostics. If the collection is written as a
+// tuple or object constructor then the description includes the source range
+// of the item's value.
+//
+// Set elements have no key or index, so they are described by their value
+// where it is of a primitive type, or otherwise by their position in the
+// set's iteration order.
+func (e *ForExpr) describeItem(item forItem, isSet bool) string {
+	k := item.key
+	if isSet {
+		switch {
+		case !k.IsKnown() || k.IsNull():
+			// Described by position, below.
+		case k.Type() == cty.String:
+			return fmt.Sprintf("the element %q", k.AsString())
+		case k.Type() == cty.Number:
+			return fmt.Sprintf("the element %s", k.AsBigFloat().Text('f', -1))
+		case k.Type() == cty.Bool:
+			return fmt.Sprintf("the element %t", k.True())
+		}
+		return fmt.Sprintf("the element at position %d", item.pos)
+	}
+
+	var desc string
+	var rng *hcl.Range
+	switch {
+	case k.Type() == cty.Number:
+		bf := k.AsBigFloat()
+		desc = fmt.Sprintf("the item at index %s", bf.Text('f', -1))
+		if idx, acc := bf.Int64(); acc == big.Exact {
+			if tuple, ok := e.CollExpr.(*TupleConsExpr); ok && idx >= 0 && idx < int64(len(tuple.Exprs)) {
+				rng = tuple.Exprs[idx].Range().Ptr()
+			}
+		}
+	case k.Type() == cty.String:
+		desc = fmt.Sprintf("the item with key %q", k.AsString())
+		if obj, ok := e.CollExpr.(*ObjectConsExpr); ok {
+			for _, item := range obj.Items {
+				itemKey, diags := item.KeyExpr.Value(nil)
+				if !diags.HasErrors() && itemKey.Type() == cty.String && itemKey.IsKnown() && !itemKey.IsNull() && itemKey.AsString() == k.AsString() {
+					rng = item.ValueExpr.Range().Ptr()
+				}
+			}
+		}
+	default:
+		return "another item"
+	}
+	if rng != nil {
+		desc = fmt.Sprintf("%s (%s)", desc, rng)
+	}
+	return desc
+}
+
 func (e *ForExpr) Range() hcl.Range {
 	return e.SrcRange
 }
@@ -1250,7 +1457,8 @@ type SplatExpr struct {
 	MarkerRange hcl.Range
 }
 
-func (e *SplatExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *SplatExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	sourceVal, diags := e.Source.Value(ctx)
 	if diags.HasErrors() {
 		// We'll evaluate our "Each" expression here just to see if it
@@ -1283,6 +1491,7 @@ func (e *SplatExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity:    hcl.DiagError,
 			Summary:     "Splat of null value",
+			Code:        hcl.DiagSplatOfNullValue,
 			Detail:      "Splat expressions (with the * symbol) cannot be applied to null sequences.",
 			Subject:     e.Source.Range().Ptr(),
 			Context:     hcl.RangeBetween(e.Source.Range(), e.MarkerRange).Ptr(),
@@ -1338,23 +1547,25 @@ func (e *SplatExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 
 	vals := make([]cty.Value, 0, sourceVal.LengthInt())
 	it := sourceVal.ElementIterator()
-	if ctx == nil {
-		// we need a context to use our AnonSymbolExpr, so we'll just
-		// make an empty one here to use as a placeholder.
-		ctx = ctx.NewChild()
-	}
+
+	// Our AnonSymbolExpr holds a value for each context it is evaluated in,
+	// so we use a new child context for each evaluation rather than the
+	// given one, so that concurrent evaluations of this expression in the
+	// same context can't see each other's items. This also gives us a
+	// context to use if none was given.
+	itemCtx := ctx.NewChild()
 	isKnown := true
 	for it.Next() {
 		_, sourceItem := it.Element()
-		e.Item.setValue(ctx, sourceItem)
-		newItem, itemDiags := e.Each.Value(ctx)
+		e.Item.setValue(itemCtx, sourceItem)
+		newItem, itemDiags := e.Each.Value(itemCtx)
 		diags = append(diags, itemDiags...)
 		if itemDiags.HasErrors() {
 			isKnown = false
 		}
 		vals = append(vals, newItem)
 	}
-	e.Item.clearValue(ctx) // clean up our temporary value
+	e.Item.clearValue(itemCtx) // clean up our temporary value
 
 	if !isKnown {
 		// We'll ingore the resultTy diagnostics in this case since they
@@ -1404,15 +1615,16 @@ type AnonSymbolExpr struct {
 	SrcRange hcl.Range
 
 	// values and its associated lock are used to isolate concurrent
-	// evaluations of a symbol from one another. It is the calling application's
-	// responsibility to ensure that the same splat expression is not evalauted
-	// concurrently within the _same_ EvalContext, but it is fine and safe to
-	// do cuncurrent evaluations with distinct EvalContexts.
+	// evaluations of a symbol from one another. The splat expression that
+	// owns the symbol assigns its values in a new child context for each
+	// evaluation, so that the same splat expression can be evaluated
+	// concurrently, whether in the same EvalContext or in different ones.
 	values     map[*hcl.EvalContext]cty.Value
 	valuesLock sync.RWMutex
 }
 
-func (e *AnonSymbolExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *AnonSymbolExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	if ctx == nil {
 		return cty.DynamicVal, nil
 	}
@@ -1466,3 +1678,10 @@ func (e *AnonSymbolExpr) Range() hcl.Range {
 func (e *AnonSymbolExpr) StartRange() hcl.Range {
 	return e.SrcRange
 }
+
+// traceValue reports the result of evaluating the given expression to the
+// trace function of the given context, if any. Each of the Value methods
+// defers a call to it.
+func traceValue(expr Expression, ctx *hcl.EvalContext, val *cty.Value, diags *hcl.Diagnostics) {
+	ctx.TraceEval(expr, *val, *diags)
+}
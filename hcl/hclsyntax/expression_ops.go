@@ -133,7 +133,8 @@ func (e *BinaryOpExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.RHS)
 }
 
-func (e *BinaryOpExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *BinaryOpExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	impl := e.Op.Impl // assumed to be a function taking exactly two arguments
 	params := impl.Params()
 	lhsParam := params[0]
@@ -151,6 +152,7 @@ func (e *BinaryOpExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics)
 		diags = append(diags, &hcl.Diagnostic{
 			Severity:    hcl.DiagError,
 			Summary:     "Invalid operand",
+			Code:        hcl.DiagInvalidOperand,
 			Detail:      fmt.Sprintf("Unsuitable value for left operand: %s.", err),
 			Subject:     e.LHS.Range().Ptr(),
 			Context:     &e.SrcRange,
@@ -163,6 +165,7 @@ func (e *BinaryOpExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics)
 		diags = append(diags, &hcl.Diagnostic{
 			Severity:    hcl.DiagError,
 			Summary:     "Invalid operand",
+			Code:        hcl.DiagInvalidOperand,
 			Detail:      fmt.Sprintf("Unsuitable value for right operand: %s.", err),
 			Subject:     e.RHS.Range().Ptr(),
 			Context:     &e.SrcRange,
@@ -184,6 +187,7 @@ func (e *BinaryOpExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics)
 			// FIXME: This diagnostic is useless.
 			Severity:    hcl.DiagError,
 			Summary:     "Operation failed",
+			Code:        hcl.DiagOperationFailed,
 			Detail:      fmt.Sprintf("Error during operation: %s.", err),
 			Subject:     &e.SrcRange,
 			Expression:  e,
@@ -215,7 +219,8 @@ func (e *UnaryOpExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.Val)
 }
 
-func (e *UnaryOpExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *UnaryOpExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	impl := e.Op.Impl // assumed to be a function taking exactly one argument
 	params := impl.Params()
 	param := params[0]
@@ -227,6 +232,7 @@ func (e *UnaryOpExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity:    hcl.DiagError,
 			Summary:     "Invalid operand",
+			Code:        hcl.DiagInvalidOperand,
 			Detail:      fmt.Sprintf("Unsuitable value for unary operand: %s.", err),
 			Subject:     e.Val.Range().Ptr(),
 			Context:     &e.SrcRange,
@@ -248,6 +254,7 @@ func (e *UnaryOpExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 			// FIXME: This diagnostic is useless.
 			Severity:    hcl.DiagError,
 			Summary:     "Operation failed",
+			Code:        hcl.DiagOperationFailed,
 			Detail:      fmt.Sprintf("Error during operation: %s.", err),
 			Subject:     &e.SrcRange,
 			Expression:  e,
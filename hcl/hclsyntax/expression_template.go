@@ -21,10 +21,12 @@ func (e *TemplateExpr) walkChildNodes(w internalWalkFunc) {
 	}
 }
 
-func (e *TemplateExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *TemplateExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	buf := &bytes.Buffer{}
 	var diags hcl.Diagnostics
 	isKnown := true
+	limit := ctx.TemplateLengthLimit()
 
 	for _, part := range e.Parts {
 		partVal, partDiags := part.Value(ctx)
@@ -34,6 +36,7 @@ func (e *TemplateExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics)
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid template interpolation value",
+				Code:     hcl.DiagInvalidTemplateInterpolationValue,
 				Detail: fmt.Sprintf(
 					"The expression result is null. Cannot include a null value in a string template.",
 				),
@@ -59,6 +62,7 @@ func (e *TemplateExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics)
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid template interpolation value",
+				Code:     hcl.DiagInvalidTemplateInterpolationValue,
 				Detail: fmt.Sprintf(
 					"Cannot include the given value in a string template: %s.",
 					err.Error(),
@@ -72,6 +76,9 @@ func (e *TemplateExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics)
 		}
 
 		buf.WriteString(strVal.AsString())
+		if limit > 0 && buf.Len() > limit {
+			return cty.UnknownVal(cty.String), append(diags, templateTooLongDiag(e, e.SrcRange, ctx, limit))
+		}
 	}
 
 	if !isKnown {
@@ -109,6 +116,29 @@ func (e *TemplateExpr) IsStringLiteral() bool {
 	return ok
 }
 
+// AsQuotedTraversal interprets a template that is a single string literal as
+// a string containing absolute traversal syntax, as accepted by
+// hcl.RelaxedAbsTraversalForExpr. It returns nil and no diagnostics if the
+// template is not a string literal, or a nil traversal with error
+// diagnostics if the string does not contain a valid traversal.
+func (e *TemplateExpr) AsQuotedTraversal() (hcl.Traversal, hcl.Diagnostics) {
+	if !e.IsStringLiteral() {
+		return nil, nil
+	}
+	lit := e.Parts[0].(*LiteralValueExpr)
+	if lit.Val.Type() != cty.String || lit.Val.IsNull() {
+		return nil, nil
+	}
+
+	// The source ranges in the result are exact only if the string contains
+	// no escape sequences, but that's true for any reasonable traversal.
+	traversal, diags := ParseTraversalAbs([]byte(lit.Val.AsString()), lit.SrcRange.Filename, lit.SrcRange.Start)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return traversal, diags
+}
+
 // TemplateJoinExpr is used to convert tuples of strings produced by template
 // constructs (i.e. for loops) into flat strings, by converting the values
 // tos strings and joining them. This AST node is not used directly; it's
@@ -121,7 +151,8 @@ func (e *TemplateJoinExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.Tuple)
 }
 
-func (e *TemplateJoinExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *TemplateJoinExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
 	tuple, diags := e.Tuple.Value(ctx)
 
 	if tuple.IsNull() {
@@ -140,6 +171,7 @@ func (e *TemplateJoinExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 	}
 
 	buf := &bytes.Buffer{}
+	limit := ctx.TemplateLengthLimit()
 	it := tuple.ElementIterator()
 	for it.Next() {
 		_, val := it.Element()
@@ -148,6 +180,7 @@ func (e *TemplateJoinExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid template interpolation value",
+				Code:     hcl.DiagInvalidTemplateInterpolationValue,
 				Detail: fmt.Sprintf(
 					"An iteration result is null. Cannot include a null value in a string template.",
 				),
@@ -165,6 +198,7 @@ func (e *TemplateJoinExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid template interpolation value",
+				Code:     hcl.DiagInvalidTemplateInterpolationValue,
 				Detail: fmt.Sprintf(
 					"Cannot include one of the interpolation results into the string template: %s.",
 					err.Error(),
@@ -180,11 +214,28 @@ func (e *TemplateJoinExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnosti
 		}
 
 		buf.WriteString(strVal.AsString())
+		if limit > 0 && buf.Len() > limit {
+			return cty.UnknownVal(cty.String), append(diags, templateTooLongDiag(e, e.Range(), ctx, limit))
+		}
 	}
 
 	return cty.StringVal(buf.String()), diags
 }
 
+// templateTooLongDiag returns the diagnostic for a template whose result
+// exceeds the given limit, as returned by EvalContext.TemplateLengthLimit.
+func templateTooLongDiag(expr Expression, rng hcl.Range, ctx *hcl.EvalContext, limit int) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity:    hcl.DiagError,
+		Summary:     "Template result too long",
+		Code:        hcl.DiagTemplateTooLong,
+		Detail:      fmt.Sprintf("The result of this template would be longer than the maximum of %d bytes.", limit),
+		Subject:     rng.Ptr(),
+		Expression:  expr,
+		EvalContext: ctx,
+	}
+}
+
 func (e *TemplateJoinExpr) Range() hcl.Range {
 	return e.Tuple.Range()
 }
@@ -201,14 +252,52 @@ type TemplateWrapExpr struct {
 	Wrapped Expression
 
 	SrcRange hcl.Range
+
+	// quotedExprRange is set by the parser if the template was written as
+	// a quoted string, to the range of the tokens of the wrapped expression,
+	// for use in the suggested fix for the interpolation-only warning.
+	quotedExprRange *hcl.Range
 }
 
 func (e *TemplateWrapExpr) walkChildNodes(w internalWalkFunc) {
 	w(e.Wrapped)
 }
 
-func (e *TemplateWrapExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
-	return e.Wrapped.Value(ctx)
+func (e *TemplateWrapExpr) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer traceValue(e, ctx, &retVal, &retDiags)
+	val, diags := e.Wrapped.Value(ctx)
+	if e.quotedExprRange != nil && ctx.InterpolationOnlyWarnings() {
+		exprRange := *e.quotedExprRange
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Interpolation-only expression",
+			Code:     hcl.DiagInterpolationOnlyTemplate,
+			Detail:   "A quoted template that contains only a single interpolation sequence is redundant. Use the interpolated expression directly instead.",
+			Subject:  e.SrcRange.Ptr(),
+			Suggestions: []hcl.Suggestion{{
+				Description: "Remove the template",
+				Edits: []hcl.Edit{
+					{
+						Range: hcl.Range{
+							Filename: e.SrcRange.Filename,
+							Start:    e.SrcRange.Start,
+							End:      exprRange.Start,
+						},
+					},
+					{
+						Range: hcl.Range{
+							Filename: e.SrcRange.Filename,
+							Start:    exprRange.End,
+							End:      e.SrcRange.End,
+						},
+					},
+				},
+			}},
+			Expression:  e,
+			EvalContext: ctx,
+		})
+	}
+	return val, diags
 }
 
 func (e *TemplateWrapExpr) Range() hcl.Range {
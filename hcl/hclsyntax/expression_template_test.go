@@ -189,6 +189,24 @@ trim`,
 			cty.StringVal("abc"),
 			0,
 		},
+		{
+			"%{ for v in [\"a\", \"b\"] ~}\n  ${v}\n%{~ endfor ~}\n\ndone",
+			nil,
+			cty.StringVal("abdone"),
+			0,
+		},
+		{
+			"a\n  \n  ${~ \"b\" ~}\n\n  c",
+			nil,
+			cty.StringVal("abc"),
+			0,
+		},
+		{
+			"a\n  %{~ if true ~}\n  \n b %{~ endif ~}\n",
+			nil,
+			cty.StringVal("ab"),
+			0,
+		},
 		{
 			`%{ for v in [] }${v}%{ endfor }`,
 			nil,
@@ -280,3 +298,125 @@ trim`,
 	}
 
 }
+
+func TestTemplateWrapExprInterpolationOnlyWarning(t *testing.T) {
+	tests := []struct {
+		src       string
+		ctx       *hcl.EvalContext
+		wantFixed string // empty if no warning is expected
+	}{
+		{
+			`"${a}"`,
+			&hcl.EvalContext{WarnInterpolationOnly: true},
+			`a`,
+		},
+		{
+			`"${ (a)[0] }"`,
+			(&hcl.EvalContext{WarnInterpolationOnly: true}).NewChild(),
+			`(a)[0]`,
+		},
+		{
+			`"${~ f(x)[0] ~}"`,
+			&hcl.EvalContext{WarnInterpolationOnly: true},
+			`f(x)[0]`,
+		},
+		{
+			`"${a}"`,
+			&hcl.EvalContext{},
+			``,
+		},
+		{
+			`"${a}b"`,
+			&hcl.EvalContext{WarnInterpolationOnly: true},
+			``,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics while parsing: %s", diags.Error())
+			}
+			_, diags = expr.Value(test.ctx)
+			var warnings hcl.Diagnostics
+			for _, diag := range diags {
+				if diag.Code == hcl.DiagInterpolationOnlyTemplate {
+					warnings = append(warnings, diag)
+				}
+			}
+
+			if test.wantFixed == "" {
+				if len(warnings) != 0 {
+					t.Fatalf("unexpected warning: %s", warnings.Error())
+				}
+				return
+			}
+			if len(warnings) != 1 {
+				t.Fatalf("wrong number of warnings %d; want 1", len(warnings))
+			}
+			if warnings[0].Severity != hcl.DiagWarning {
+				t.Errorf("diagnostic is not a warning")
+			}
+			fixed, err := hcl.ApplyEdits([]byte(test.src), warnings[0].Suggestions[0].Edits)
+			if err != nil {
+				t.Fatalf("failed to apply fix: %s", err)
+			}
+			if got := string(fixed); got != test.wantFixed {
+				t.Errorf("wrong fixed source %q; want %q", got, test.wantFixed)
+			}
+		})
+	}
+
+	// A standalone template is not quoted, and so is not redundant.
+	expr, _ := ParseTemplate([]byte(`${"a"}`), "test.tmpl", hcl.Pos{Line: 1, Column: 1})
+	_, diags := expr.Value(&hcl.EvalContext{WarnInterpolationOnly: true})
+	if len(diags) != 0 {
+		t.Errorf("unexpected diagnostics for standalone template: %s", diags.Error())
+	}
+}
+
+func TestTemplateExprAsQuotedTraversal(t *testing.T) {
+	tests := []struct {
+		Src       string
+		Want      string
+		DiagCount int
+	}{
+		{`"a.b[0]"`, `a.b[0]`, 0},
+		{`a.b[0]`, `a.b[0]`, 0},
+		{`"a.${b}"`, ``, 1},
+		{`"a.b +"`, ``, 1},
+		{`"1"`, ``, 1},
+		{`a + 1`, ``, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.Src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics while parsing: %s", diags.Error())
+			}
+			traversal, diags := hcl.RelaxedAbsTraversalForExpr(expr)
+			if len(diags) != test.DiagCount {
+				t.Errorf("wrong number of diagnostics %d; want %d", len(diags), test.DiagCount)
+				for _, diag := range diags {
+					t.Logf("- %s", diag.Error())
+				}
+			}
+			if got := traversal.String(); got != test.Want {
+				t.Errorf("wrong result %q; want %q", got, test.Want)
+			}
+		})
+	}
+
+	expr, _ := ParseExpression([]byte(`"a.b"`), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	traversal, _ := hcl.RelaxedAbsTraversalForExpr(expr)
+	want := hcl.Range{
+		Filename: "test.hcl",
+		Start:    hcl.Pos{Line: 1, Column: 2, Byte: 1},
+		End:      hcl.Pos{Line: 1, Column: 5, Byte: 4},
+	}
+	if got := traversal.SourceRange(); got != want {
+		t.Errorf("wrong source range %#v; want %#v", got, want)
+	}
+}
@@ -1,6 +1,8 @@
 package hclsyntax
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/hcl2/hcl"
@@ -1531,6 +1533,42 @@ func TestFunctionCallExprValue(t *testing.T) {
 			cty.NumberIntVal(5),
 			0,
 		},
+		"function from parent context shadowed by child": {
+			&FunctionCallExpr{
+				Name: "length",
+				Args: []Expression{
+					&LiteralValueExpr{
+						Val: cty.StringVal("hello"),
+					},
+				},
+			},
+			func() *hcl.EvalContext {
+				ctx := (&hcl.EvalContext{
+					Functions: funcs,
+				}).NewChild()
+				ctx.Functions = map[string]function.Function{
+					"length": stdlib.UpperFunc,
+				}
+				return ctx
+			}(),
+			cty.StringVal("HELLO"),
+			0,
+		},
+		"function from parent context": {
+			&FunctionCallExpr{
+				Name: "jsondecode",
+				Args: []Expression{
+					&LiteralValueExpr{
+						Val: cty.StringVal(`"hello"`),
+					},
+				},
+			},
+			(&hcl.EvalContext{
+				Functions: funcs,
+			}).NewChild(),
+			cty.StringVal("hello"),
+			0,
+		},
 		"valid call with arg conversion": {
 			&FunctionCallExpr{
 				Name: "length",
@@ -1734,3 +1772,424 @@ func TestStaticExpressionList(t *testing.T) {
 		t.Fatalf("wrong first value %#v; want cty.Zero", first.Val)
 	}
 }
+
+func TestExpressionSensitiveDiagnostics(t *testing.T) {
+	checkFunc := function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "value",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.DynamicVal, function.NewArgErrorf(0, "%q is not valid", args[0].AsString())
+		},
+	})
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"secret": cty.StringVal("hunter2"),
+			"public": cty.StringVal("hello"),
+			"list":   cty.ListVal([]cty.Value{cty.True}),
+			"frac":   cty.NumberFloatVal(0.5),
+		},
+		SensitiveVariables: map[string]bool{
+			"secret": true,
+			"frac":   true,
+		},
+		Functions: map[string]function.Function{
+			"check": checkFunc,
+		},
+	}
+
+	tests := []struct {
+		input  string
+		leaked string
+		want   string
+	}{
+		{
+			`check(public)`,
+			"",
+			`Invalid value for "value" parameter: "hello" is not valid.`,
+		},
+		{
+			`check(secret)`,
+			"hunter2",
+			`Invalid value for "value" parameter. The underlying error message is not shown because it may include a sensitive value.`,
+		},
+		{
+			`{for v in [secret, secret]: v => v}`,
+			"hunter2",
+			`Two different items produced the key (sensitive value) in this 'for' expression. If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.`,
+		},
+		{
+			`list[frac]`,
+			"0.5",
+			`The given key does not identify an element in this collection value.`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, parseDiags := ParseExpression([]byte(test.input), "", hcl.Pos{Line: 1, Column: 1})
+			if len(parseDiags) != 0 {
+				t.Fatalf("unexpected parse diagnostics:\n%s", parseDiags.Error())
+			}
+
+			_, diags := expr.Value(ctx)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got := diags[0].Detail; got != test.want {
+				t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, test.want)
+			}
+			if test.leaked != "" && strings.Contains(diags[0].Detail, test.leaked) {
+				t.Errorf("detail includes sensitive value %q", test.leaked)
+			}
+		})
+	}
+}
+
+func TestForExprDuplicateKeyDiagnostics(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"list":  cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("a")}),
+			"tuple": cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.True, cty.StringVal("a")}),
+			"map": cty.MapVal(map[string]cty.Value{
+				"x": cty.StringVal("a"),
+				"y": cty.StringVal("a"),
+			}),
+			"set":     cty.SetVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			"numbers": cty.SetVal([]cty.Value{cty.NumberIntVal(1), cty.NumberFloatVal(2.5)}),
+			"objects": cty.SetVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{"n": cty.NumberIntVal(1)}),
+				cty.ObjectVal(map[string]cty.Value{"n": cty.NumberIntVal(2)}),
+			}),
+		},
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			`{for v in ["a", "b", "a"]: v => v}`,
+			`Two different items produced the key "a" in this 'for' expression: the item at index 0 (test.hcl:1,12-15) and the item at index 2 (test.hcl:1,22-25). If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.`,
+		},
+		{
+			`{for k, v in {x = 1, y = 1}: v => k}`,
+			`Two different items produced the key "1" in this 'for' expression: the item with key "x" (test.hcl:1,19-20) and the item with key "y" (test.hcl:1,26-27). If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.`,
+		},
+		{
+			`{for v in list: v => v}`,
+			`Two different items produced the key "a" in this 'for' expression: the item at index 0 and the item at index 1. If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.`,
+		},
+		{
+			`{for v in tuple: "${v}" => v}`,
+			`Two different items produced the key "a" in this 'for' expression: the item at index 0 and the item at index 2. If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.`,
+		},
+		{
+			`{for k, v in map: v => k}`,
+			`Two different items produced the key "a" in this 'for' expression: the item with key "x" and the item with key "y". If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.`,
+		},
+		{
+			`{for v in set: "k" => v}`,
+			`Two different items produced the key "k" in this 'for' expression: the element "a" and the element "b". If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.`,
+		},
+		{
+			`{for v in numbers: "k" => v}`,
+			`Two different items produced the key "k" in this 'for' expression: the element 1 and the element 2.5. If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.`,
+		},
+		{
+			`{for v in objects: "k" => v}`,
+			`Two different items produced the key "k" in this 'for' expression: the element at position 0 and the element at position 1. If duplicates are expected, use the ellipsis (...) after the value expression to enable grouping by key.`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, parseDiags := ParseExpression([]byte(test.input), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			if len(parseDiags) != 0 {
+				t.Fatalf("unexpected parse diagnostics:\n%s", parseDiags.Error())
+			}
+
+			_, diags := expr.Value(ctx)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got, want := diags[0].Code, hcl.DiagDuplicateObjectKey; got != want {
+				t.Errorf("wrong code %q; want %q", got, want)
+			}
+			if got := diags[0].Detail; got != test.want {
+				t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFunctionCallArgDiagnostics(t *testing.T) {
+	pickFunc := function.New(&function.Spec{
+		VarParam: &function.Parameter{
+			Name: "items",
+			Type: cty.String,
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			for i, arg := range args {
+				if arg.AsString() == "bad" {
+					return cty.DynamicVal, function.NewArgErrorf(i, "bad item")
+				}
+			}
+			return args[0], nil
+		},
+	})
+	ctx := &hcl.EvalContext{
+		Functions: map[string]function.Function{
+			"pick": pickFunc,
+		},
+	}
+
+	tests := []struct {
+		input       string
+		wantDetail  string
+		wantSubject hcl.Range
+	}{
+		{
+			`pick("a", "bad")`,
+			`Invalid value for "items" parameter: bad item.`,
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 11, Byte: 10},
+				End:   hcl.Pos{Line: 1, Column: 16, Byte: 15},
+			},
+		},
+		{
+			`pick("a", ["b", "bad"]...)`,
+			`Invalid value for "items" parameter (element 1 of the expanded argument): bad item.`,
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 11, Byte: 10},
+				End:   hcl.Pos{Line: 1, Column: 23, Byte: 22},
+			},
+		},
+		{
+			`pick("a", ["b"])`,
+			`Invalid value for "items" parameter: string required.`,
+			hcl.Range{
+				Start: hcl.Pos{Line: 1, Column: 11, Byte: 10},
+				End:   hcl.Pos{Line: 1, Column: 16, Byte: 15},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, parseDiags := ParseExpression([]byte(test.input), "", hcl.Pos{Line: 1, Column: 1})
+			if len(parseDiags) != 0 {
+				t.Fatalf("unexpected parse diagnostics:\n%s", parseDiags.Error())
+			}
+
+			_, diags := expr.Value(ctx)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got := diags[0].Detail; got != test.wantDetail {
+				t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, test.wantDetail)
+			}
+			if got := *diags[0].Subject; got != test.wantSubject {
+				t.Errorf("wrong subject\ngot:  %#v\nwant: %#v", got, test.wantSubject)
+			}
+		})
+	}
+}
+
+func TestFunctionCallDiagExtra(t *testing.T) {
+	wantErr := errors.New("failed")
+	failFunc := function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "value",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.DynamicVal, wantErr
+		},
+	})
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"secret": cty.StringVal("hunter2"),
+		},
+		SensitiveVariables: map[string]bool{
+			"secret": true,
+		},
+		Functions: map[string]function.Function{
+			"fail": failFunc,
+		},
+	}
+
+	expr, parseDiags := ParseExpression([]byte(`fail("a")`), "", hcl.Pos{Line: 1, Column: 1})
+	if len(parseDiags) != 0 {
+		t.Fatalf("unexpected parse diagnostics:\n%s", parseDiags.Error())
+	}
+	_, diags := expr.Value(ctx)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	extra, ok := diags[0].Extra.(FunctionCallDiagExtra)
+	if !ok {
+		t.Fatalf("diagnostic has wrong extra %#v; want FunctionCallDiagExtra", diags[0].Extra)
+	}
+	if got, want := extra.CalledFunctionName(), "fail"; got != want {
+		t.Errorf("wrong function name %q; want %q", got, want)
+	}
+	if got := extra.FunctionCallError(); got != wantErr {
+		t.Errorf("wrong error %#v; want %#v", got, wantErr)
+	}
+
+	expr, parseDiags = ParseExpression([]byte(`fail(secret)`), "", hcl.Pos{Line: 1, Column: 1})
+	if len(parseDiags) != 0 {
+		t.Fatalf("unexpected parse diagnostics:\n%s", parseDiags.Error())
+	}
+	_, diags = expr.Value(ctx)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+	}
+	if diags[0].Extra != nil {
+		t.Errorf("diagnostic for sensitive call has extra %#v; want nil", diags[0].Extra)
+	}
+}
+
+func TestNamespacedFunctionCall(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Functions: map[string]function.Function{
+			"upper":                  stdlib.UpperFunc,
+			"str::upper":             stdlib.UpperFunc,
+			"provider::str::reverse": stdlib.ReverseFunc,
+		},
+	}
+
+	tests := []struct {
+		input         string
+		want          cty.Value
+		parseDiagWant string
+		valueDiagWant string
+	}{
+		{
+			input: `str::upper("a")`,
+			want:  cty.StringVal("A"),
+		},
+		{
+			input: `provider :: str::reverse("abc")`,
+			want:  cty.StringVal("cba"),
+		},
+		{
+			input: `{ a: str::upper("b") }`,
+			want: cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("B"),
+			}),
+		},
+		{
+			input: `true ? upper("a") : str::upper("b")`,
+			want:  cty.StringVal("A"),
+		},
+		{
+			input:         `str::uper("A")`,
+			want:          cty.DynamicVal,
+			valueDiagWant: `There is no function named "str::uper". Did you mean "str::upper"?`,
+		},
+		{
+			input:         `nope::upper("A")`,
+			want:          cty.DynamicVal,
+			valueDiagWant: `There is no function named "nope::upper". No functions are available in the "nope" namespace.`,
+		},
+		{
+			input:         `str::upper`,
+			want:          cty.DynamicVal,
+			parseDiagWant: `The namespaced name str::upper can only be used to call a function, so it must be followed by an argument list in parentheses.`,
+		},
+		{
+			input:         `str::("a")`,
+			want:          cty.DynamicVal,
+			parseDiagWant: `A namespace separator (::) must be followed by another namespace or by the function name.`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, parseDiags := ParseExpression([]byte(test.input), "", hcl.Pos{Line: 1, Column: 1})
+			if test.parseDiagWant != "" {
+				if len(parseDiags) == 0 || parseDiags[0].Detail != test.parseDiagWant {
+					t.Fatalf("wrong parse diagnostics\ngot:  %s\nwant: %s", parseDiags.Error(), test.parseDiagWant)
+				}
+				return
+			}
+			if len(parseDiags) != 0 {
+				t.Fatalf("unexpected parse diagnostics:\n%s", parseDiags.Error())
+			}
+
+			got, diags := expr.Value(ctx)
+			if test.valueDiagWant != "" {
+				if len(diags) != 1 || diags[0].Detail != test.valueDiagWant {
+					t.Fatalf("wrong diagnostics\ngot:  %s\nwant: %s", diags.Error(), test.valueDiagWant)
+				}
+			} else if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics:\n%s", diags.Error())
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestExpressionEvalLimits(t *testing.T) {
+	tests := []struct {
+		input       string
+		maxTemplate int
+		maxFor      int
+		want        cty.Value
+		wantCode    string
+	}{
+		{`"${s}${s}"`, 8, 0, cty.StringVal("abcdabcd"), ""},
+		{`"${s}${s}"`, 7, 0, cty.UnknownVal(cty.String), hcl.DiagTemplateTooLong},
+		{`"%{ for x in l }${x}%{ endfor }"`, 3, 0, cty.StringVal("abc"), ""},
+		{`"%{ for x in l }${x}%{ endfor }"`, 2, 0, cty.UnknownVal(cty.String), hcl.DiagTemplateTooLong},
+		{`[for x in l : x]`, 0, 3, cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b"), cty.StringVal("c")}), ""},
+		{`[for x in l : x]`, 0, 2, cty.DynamicVal, hcl.DiagTooManyForElements},
+		{`[for x in l : x if x != "a"]`, 0, 2, cty.TupleVal([]cty.Value{cty.StringVal("b"), cty.StringVal("c")}), ""},
+		{`{for x in l : x => x}`, 0, 2, cty.DynamicVal, hcl.DiagTooManyForElements},
+		{`{for x in l : "k" => x...}`, 0, 2, cty.DynamicVal, hcl.DiagTooManyForElements},
+		{`"%{ for x in l }${x}%{ endfor }"`, 0, 2, cty.UnknownVal(cty.String), hcl.DiagTooManyForElements},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, parseDiags := ParseExpression([]byte(test.input), "", hcl.Pos{Line: 1, Column: 1})
+			if len(parseDiags) != 0 {
+				t.Fatalf("unexpected parse diagnostics:\n%s", parseDiags.Error())
+			}
+
+			// The limits are inherited by child contexts.
+			ctx := (&hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"s": cty.StringVal("abcd"),
+					"l": cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b"), cty.StringVal("c")}),
+				},
+				MaxTemplateLength: test.maxTemplate,
+				MaxForElements:    test.maxFor,
+			}).NewChild()
+
+			got, diags := expr.Value(ctx)
+			var gotCode string
+			if len(diags) > 0 {
+				gotCode = diags[0].Code
+			}
+			if len(diags) > 1 || gotCode != test.wantCode {
+				t.Fatalf("wrong diagnostics\ngot:  %s\nwant code: %q", diags.Error(), test.wantCode)
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
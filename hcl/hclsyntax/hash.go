@@ -0,0 +1,203 @@
+package hclsyntax
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"reflect"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// HashSize is the size in bytes of the hashes returned by HashBody and
+// HashExpression.
+const HashSize = sha256.Size
+
+// HashBody returns a hash of the semantic content of the given body, which
+// can be used to cheaply detect whether a new revision of a configuration
+// has any effect, such as to skip or reuse the results of further processing.
+//
+// Two bodies that CompareBodies considers equal have the same hash: source
+// ranges, layout, comments and the order of attributes are ignored, while
+// the order of blocks is significant. The hash is stable across processes
+// and versions of this package, as long as the syntax tree types are
+// unchanged.
+func HashBody(body *Body) [HashSize]byte {
+	h := newHasher()
+	h.body(body)
+	return h.sum()
+}
+
+// HashExpression returns a hash of the semantic content of the given
+// expression, as described for HashBody.
+func HashExpression(expr Expression) [HashSize]byte {
+	h := newHasher()
+	h.expr(reflect.ValueOf(expr))
+	return h.sum()
+}
+
+// hasher walks a syntax tree in the same way as exprComparer, writing an
+// unambiguous encoding of everything that the comparison considers.
+type hasher struct {
+	h hash.Hash
+}
+
+func newHasher() *hasher {
+	return &hasher{h: sha256.New()}
+}
+
+func (h *hasher) sum() [HashSize]byte {
+	var ret [HashSize]byte
+	h.h.Sum(ret[:0])
+	return ret
+}
+
+func (h *hasher) int(n int) {
+	var buf [binary.MaxVarintLen64]byte
+	h.h.Write(buf[:binary.PutVarint(buf[:], int64(n))])
+}
+
+func (h *hasher) string(s string) {
+	h.int(len(s))
+	h.h.Write([]byte(s))
+}
+
+func (h *hasher) body(body *Body) {
+	names := make([]string, 0, len(body.Attributes))
+	for name := range body.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h.int(len(names))
+	for _, name := range names {
+		h.string(name)
+		h.expr(reflect.ValueOf(body.Attributes[name].Expr))
+	}
+
+	h.int(len(body.Blocks))
+	for _, block := range body.Blocks {
+		h.string(block.Type)
+		h.int(len(block.Labels))
+		for _, label := range block.Labels {
+			h.string(label)
+		}
+		h.body(block.Body)
+	}
+}
+
+func (h *hasher) expr(v reflect.Value) {
+	if !v.IsValid() {
+		h.string("")
+		return
+	}
+	h.string(v.Type().String())
+
+	switch v.Type() {
+	case rangeType, posType:
+		return
+	case ctyValueType:
+		h.value(v.Interface().(cty.Value))
+		return
+	case ctyTypeType:
+		buf, err := ctyjson.MarshalType(v.Interface().(cty.Type))
+		if err != nil {
+			// Should never happen, since all types can be marshalled.
+			panic(fmt.Sprintf("can't hash type: %s", err))
+		}
+		h.string(string(buf))
+		return
+	case operationType:
+		h.string(operationName(v.Interface().(*Operation)))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			h.int(0)
+			return
+		}
+		h.int(1)
+		h.expr(v.Elem())
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			h.expr(v.Field(i))
+		}
+
+	case reflect.Slice:
+		h.int(v.Len())
+		for i := 0; i < v.Len(); i++ {
+			h.expr(v.Index(i))
+		}
+
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		h.string(fmt.Sprintf("%#v", v.Interface()))
+
+	default:
+		panic(fmt.Sprintf("can't hash %s in expressions", v.Type()))
+	}
+}
+
+func (h *hasher) value(val cty.Value) {
+	if val == cty.NilVal {
+		h.string("")
+		return
+	}
+	ty, err := ctyjson.MarshalType(val.Type())
+	if err != nil {
+		panic(fmt.Sprintf("can't hash type: %s", err))
+	}
+	h.string(string(ty))
+	if !val.IsWhollyKnown() {
+		// The JSON encoding can't represent unknown values, which are
+		// never produced by the parser, so we fall back on the Go syntax
+		// representation.
+		h.string(val.GoString())
+		return
+	}
+	buf, err := ctyjson.Marshal(val, val.Type())
+	if err != nil {
+		panic(fmt.Sprintf("can't hash value: %s", err))
+	}
+	h.string(string(buf))
+}
+
+// operationNames identifies the predefined operations for hashing, since
+// Operation has no stable representation of its own.
+var operationNames = map[*Operation]string{
+	OpLogicalOr:          "||",
+	OpLogicalAnd:         "&&",
+	OpLogicalNot:         "!",
+	OpEqual:              "==",
+	OpNotEqual:           "!=",
+	OpGreaterThan:        ">",
+	OpGreaterThanOrEqual: ">=",
+	OpLessThan:           "<",
+	OpLessThanOrEqual:    "<=",
+	OpAdd:                "+",
+	OpSubtract:           "-",
+	OpMultiply:           "*",
+	OpDivide:             "/",
+	OpModulo:             "%",
+	OpNegate:             "-",
+}
+
+func operationName(op *Operation) string {
+	if name, ok := operationNames[op]; ok {
+		return name
+	}
+	// Operations constructed by the caller are distinguished by identity,
+	// and so hash consistently only within a single process.
+	return fmt.Sprintf("%p", op)
+}
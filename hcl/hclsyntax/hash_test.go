@@ -0,0 +1,122 @@
+package hclsyntax
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+func TestHashBody(t *testing.T) {
+	tests := []struct {
+		a, b      string
+		wantEqual bool
+	}{
+		{
+			``,
+			``,
+			true,
+		},
+		{
+			"a = 1\nb = \"x\"\n",
+			"# comment\nb   =   \"x\"\n\n\na = (1.0)\n",
+			true,
+		},
+		{
+			"a = <<EOT\nhello\nEOT\n",
+			"a = \"hello\\n\"\n",
+			true,
+		},
+		{
+			"a = [for x in y : x * 2 if x > 1]\nb = foo(a, b...)\nc = a.b[0].*.c\n",
+			"a = [ for x in y: x*2 if x>1 ]\nb = foo(a,b...)\nc = a.b[0].*.c\n",
+			true,
+		},
+		{
+			"a = 1\n",
+			"a = 2\n",
+			false,
+		},
+		{
+			"a = 1\n",
+			"b = 1\n",
+			false,
+		},
+		{
+			"a = 1 + 2\n",
+			"a = 1 - 2\n",
+			false,
+		},
+		{
+			"a = -1\n",
+			"a = 0 - 1\n",
+			false,
+		},
+		{
+			"a = \"1\"\n",
+			"a = 1\n",
+			false,
+		},
+		{
+			"a = [\"ab\", \"c\"]\n",
+			"a = [\"a\", \"bc\"]\n",
+			false,
+		},
+		{
+			"a {}\nb {}\n",
+			"b {}\na {}\n",
+			false,
+		},
+		{
+			"a \"x\" {}\n",
+			"a {\n  x {}\n}\n",
+			false,
+		},
+		{
+			"a {\n  b = 1\n}\n",
+			"a {}\nb = 1\n",
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+"|"+test.b, func(t *testing.T) {
+			fileA, diags := ParseConfig([]byte(test.a), "a.hcl", hcl.Pos{Line: 1, Column: 1})
+			fileB, moreDiags := ParseConfig([]byte(test.b), "b.hcl", hcl.Pos{Line: 1, Column: 1})
+			diags = append(diags, moreDiags...)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			bodyA, bodyB := fileA.Body.(*Body), fileB.Body.(*Body)
+			if HashBody(bodyA) != HashBody(bodyA) {
+				t.Fatalf("hash is not deterministic")
+			}
+			if got := HashBody(bodyA) == HashBody(bodyB); got != test.wantEqual {
+				t.Errorf("wrong result: hashes equal is %t, want %t", got, test.wantEqual)
+			}
+			if test.wantEqual != (CompareBodies(bodyA, bodyB) == nil) {
+				t.Errorf("result is inconsistent with CompareBodies")
+			}
+		})
+	}
+}
+
+func TestHashExpression(t *testing.T) {
+	parse := func(src string) Expression {
+		expr, diags := ParseExpression([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			t.Fatalf("unexpected diagnostics: %s", diags.Error())
+		}
+		return expr
+	}
+
+	if HashExpression(parse("a.b[0]")) != HashExpression(parse("a . b [ 0 ]")) {
+		t.Errorf("equal expressions have different hashes")
+	}
+	if HashExpression(parse("a.b[0]")) == HashExpression(parse("a.b[1]")) {
+		t.Errorf("different expressions have equal hashes")
+	}
+	if HashExpression(parse("!a")) == HashExpression(parse("-a")) {
+		t.Errorf("different operators have equal hashes")
+	}
+}
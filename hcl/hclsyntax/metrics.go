@@ -0,0 +1,118 @@
+package hclsyntax
+
+import (
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// Metrics describes the size and complexity of some part of a syntax tree,
+// as returned by ExpressionMetrics and BodyMetrics.
+//
+// These can be used to enforce limits on the complexity of configuration
+// provided by untrusted users, or to find expressions that are hard to
+// maintain.
+type Metrics struct {
+	// Expressions is the total number of expression nodes, including
+	// each of the expressions nested inside others.
+	Expressions int
+
+	// Depth is the greatest nesting depth of the expressions, where an
+	// expression that has no sub-expressions has a depth of one.
+	Depth int
+
+	// FunctionCalls is the number of function call expressions.
+	FunctionCalls int
+
+	// References is the number of references to variables, including
+	// references to the temporary symbols declared by 'for' expressions.
+	// Each reference is counted separately, even if several refer to the
+	// same variable.
+	References int
+
+	// Operators is the number of uses of the unary, binary and conditional
+	// operators.
+	Operators int
+
+	// ForExprs is the number of 'for' expressions, including 'for'
+	// directives in templates.
+	ForExprs int
+
+	// Attributes and Blocks are the total number of attributes and blocks
+	// in a body, including those of nested blocks. These are always zero
+	// for the metrics of an expression.
+	Attributes int
+	Blocks     int
+
+	// BlockDepth is the greatest nesting depth of the blocks in a body,
+	// where a body that has no nested blocks has a depth of zero.
+	BlockDepth int
+}
+
+// ExpressionMetrics returns metrics for the given expression.
+func ExpressionMetrics(expr Expression) Metrics {
+	w := &metricsWalker{}
+	Walk(expr, w)
+	return w.metrics
+}
+
+// BodyMetrics returns metrics for the given body and all of its attributes
+// and nested blocks. Depth is the greatest depth of any of the expressions
+// within the body.
+func BodyMetrics(body *Body) Metrics {
+	w := &metricsWalker{}
+	Walk(body, w)
+	return w.metrics
+}
+
+type metricsWalker struct {
+	metrics    Metrics
+	depth      int
+	blockDepth int
+}
+
+func (w *metricsWalker) Enter(node Node) hcl.Diagnostics {
+	m := &w.metrics
+	switch node.(type) {
+	case *Attribute:
+		m.Attributes++
+		return nil
+	case *Block:
+		m.Blocks++
+		w.blockDepth++
+		if w.blockDepth > m.BlockDepth {
+			m.BlockDepth = w.blockDepth
+		}
+		return nil
+	case Expression:
+		// Handled below.
+	default:
+		return nil
+	}
+
+	m.Expressions++
+	w.depth++
+	if w.depth > m.Depth {
+		m.Depth = w.depth
+	}
+
+	switch node.(type) {
+	case *FunctionCallExpr:
+		m.FunctionCalls++
+	case *ScopeTraversalExpr:
+		m.References++
+	case *BinaryOpExpr, *UnaryOpExpr, *ConditionalExpr:
+		m.Operators++
+	case *ForExpr:
+		m.ForExprs++
+	}
+	return nil
+}
+
+func (w *metricsWalker) Exit(node Node) hcl.Diagnostics {
+	switch node.(type) {
+	case *Block:
+		w.blockDepth--
+	case Expression:
+		w.depth--
+	}
+	return nil
+}
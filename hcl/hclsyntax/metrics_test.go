@@ -0,0 +1,90 @@
+package hclsyntax
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+func TestExpressionMetrics(t *testing.T) {
+	tests := []struct {
+		src  string
+		want Metrics
+	}{
+		{
+			`1`,
+			Metrics{Expressions: 1, Depth: 1},
+		},
+		{
+			`var.a`,
+			Metrics{Expressions: 1, Depth: 1, References: 1},
+		},
+		{
+			`a + b * c`,
+			Metrics{Expressions: 5, Depth: 3, References: 3, Operators: 2},
+		},
+		{
+			`upper(a ? "x" : b)`,
+			Metrics{Expressions: 6, Depth: 4, FunctionCalls: 1, References: 2, Operators: 1},
+		},
+		{
+			`[for v in list : v if v != ""]`,
+			Metrics{Expressions: 7, Depth: 4, References: 3, Operators: 1, ForExprs: 1},
+		},
+		{
+			`"%{ for v in list }${v}%{ endfor }"`,
+			Metrics{Expressions: 6, Depth: 5, References: 2, ForExprs: 1},
+		},
+		{
+			`{ a = 1 }`,
+			Metrics{Expressions: 3, Depth: 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+			got := ExpressionMetrics(expr)
+			if got != test.want {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBodyMetrics(t *testing.T) {
+	src := `
+a = 1
+b = f(x)
+
+outer {
+  inner {
+    c = [y, z]
+  }
+}
+
+other {
+}
+`
+	file, diags := ParseConfig([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	got := BodyMetrics(file.Body.(*Body))
+	want := Metrics{
+		Expressions:   6,
+		Depth:         2,
+		FunctionCalls: 1,
+		References:    3,
+		Attributes:    3,
+		Blocks:        3,
+		BlockDepth:    2,
+	}
+	if got != want {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"strconv"
+	"strings"
 	"unicode/utf8"
 
 	"github.com/apparentlymart/go-textseg/textseg"
@@ -53,6 +54,7 @@ Token:
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Attribute redefined",
+						Code:     hcl.DiagAttributeRedefined,
 						Detail: fmt.Sprintf(
 							"The argument %q was already set at %s. Each argument may be set only once.",
 							titem.Name, existing.NameRange.String(),
@@ -80,6 +82,7 @@ Token:
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Invalid argument name",
+						Code:     hcl.DiagInvalidArgumentName,
 						Detail:   "Argument names must not be quoted.",
 						Subject:  &bad.Range,
 					})
@@ -87,6 +90,7 @@ Token:
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Argument or block definition required",
+						Code:     hcl.DiagArgumentOrBlockDefinitionRequired,
 						Detail:   "An argument or block definition is required here.",
 						Subject:  &bad.Range,
 					})
@@ -120,6 +124,7 @@ func (p *parser) ParseBodyItem() (Node, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Argument or block definition required",
+				Code:     hcl.DiagArgumentOrBlockDefinitionRequired,
 				Detail:   "An argument or block definition is required here.",
 				Subject:  &ident.Range,
 			},
@@ -139,6 +144,7 @@ func (p *parser) ParseBodyItem() (Node, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Argument or block definition required",
+				Code:     hcl.DiagArgumentOrBlockDefinitionRequired,
 				Detail:   "An argument or block definition is required here. To set an argument, use the equals sign \"=\" to introduce the argument value.",
 				Subject:  &ident.Range,
 			},
@@ -160,6 +166,7 @@ func (p *parser) parseSingleAttrBody(end TokenType) (*Body, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Argument or block definition required",
+				Code:     hcl.DiagArgumentOrBlockDefinitionRequired,
 				Detail:   "An argument or block definition is required here.",
 				Subject:  &ident.Range,
 			},
@@ -182,6 +189,7 @@ func (p *parser) parseSingleAttrBody(end TokenType) (*Body, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Argument definition required",
+				Code:     hcl.DiagArgumentDefinitionRequired,
 				Detail:   fmt.Sprintf("A single-line block definition can contain only a single argument. If you meant to define argument %q, use an equals sign to assign it a value. To define a nested block, place it on a line of its own within its parent block.", ident.Bytes),
 				Subject:  hcl.RangeBetween(ident.Range, next.Range).Ptr(),
 			},
@@ -192,6 +200,7 @@ func (p *parser) parseSingleAttrBody(end TokenType) (*Body, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Argument or block definition required",
+				Code:     hcl.DiagArgumentOrBlockDefinitionRequired,
 				Detail:   "An argument or block definition is required here. To set an argument, use the equals sign \"=\" to introduce the argument value.",
 				Subject:  &ident.Range,
 			},
@@ -309,6 +318,7 @@ Token:
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid block definition",
+					Code:     hcl.DiagInvalidBlockDefinition,
 					Detail:   "The equals sign \"=\" indicates an argument definition, and must not be used when defining a block.",
 					Subject:  &tok.Range,
 					Context:  hcl.RangeBetween(ident.Range, tok.Range).Ptr(),
@@ -317,6 +327,7 @@ Token:
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid block definition",
+					Code:     hcl.DiagInvalidBlockDefinition,
 					Detail:   "A block definition must have block content delimited by \"{\" and \"}\", starting on the same line as the block header.",
 					Subject:  &tok.Range,
 					Context:  hcl.RangeBetween(ident.Range, tok.Range).Ptr(),
@@ -326,6 +337,7 @@ Token:
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Invalid block definition",
+						Code:     hcl.DiagInvalidBlockDefinition,
 						Detail:   "Either a quoted string block label or an opening brace (\"{\") is expected here.",
 						Subject:  &tok.Range,
 						Context:  hcl.RangeBetween(ident.Range, tok.Range).Ptr(),
@@ -370,6 +382,7 @@ Token:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid single-argument block definition",
+				Code:     hcl.DiagInvalidSingleArgumentBlockDefinition,
 				Detail:   "Single-line block syntax can include only one argument definition. To define multiple arguments, use the multi-line block syntax with one argument definition per line.",
 				Subject:  p.Peek().Range.Ptr(),
 			})
@@ -379,6 +392,7 @@ Token:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid single-argument block definition",
+				Code:     hcl.DiagInvalidSingleArgumentBlockDefinition,
 				Detail:   "An argument definition on the same line as its containing block creates a single-line block definition, which must also be closed on the same line. Place the block's closing brace immediately after the argument definition.",
 				Subject:  p.Peek().Range.Ptr(),
 			})
@@ -391,6 +405,7 @@ Token:
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid single-argument block definition",
+					Code:     hcl.DiagInvalidSingleArgumentBlockDefinition,
 					Detail:   "A single-line block definition must end with a closing brace immediately after its single argument definition.",
 					Subject:  p.Peek().Range.Ptr(),
 				})
@@ -409,6 +424,7 @@ Token:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Missing newline after block definition",
+				Code:     hcl.DiagMissingNewlineAfterBlockDefinition,
 				Detail:   "A block definition must end with a newline.",
 				Subject:  &eol.Range,
 				Context:  hcl.RangeBetween(ident.Range, eol.Range).Ptr(),
@@ -481,6 +497,7 @@ func (p *parser) parseTernaryConditional() (Expression, hcl.Diagnostics) {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Missing false expression in conditional",
+			Code:     hcl.DiagMissingFalseExpressionInConditional,
 			Detail:   "The conditional operator (...?...:...) requires a false expression, delimited by a colon.",
 			Subject:  &colon.Range,
 			Context:  hcl.RangeBetween(startRange, colon.Range).Ptr(),
@@ -637,6 +654,7 @@ Traversal:
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Invalid legacy index syntax",
+						Code:     hcl.DiagInvalidLegacyIndexSyntax,
 						Detail:   fmt.Sprintf("When using the legacy index syntax, chaining two indexes together is not permitted. Use the proper index syntax instead, like [%s][%s].", first, second),
 						Subject:  &attrTok.Range,
 					})
@@ -690,6 +708,7 @@ Traversal:
 							diags = append(diags, &hcl.Diagnostic{
 								Severity: hcl.DiagError,
 								Summary:  "Invalid legacy index syntax",
+								Code:     hcl.DiagInvalidLegacyIndexSyntax,
 								Detail:   fmt.Sprintf("When using the legacy index syntax, chaining two indexes together is not permitted. Use the proper index syntax with a full splat expression [*] instead, like [%s][%s].", first, second),
 								Subject:  &attrTok.Range,
 							})
@@ -717,6 +736,7 @@ Traversal:
 								diags = append(diags, &hcl.Diagnostic{
 									Severity: hcl.DiagError,
 									Summary:  "Nested splat expression not allowed",
+									Code:     hcl.DiagNestedSplatExpressionNotAllowed,
 									Detail:   "A splat expression (*) cannot be used inside another attribute-only splat expression.",
 									Subject:  p.Peek().Range.Ptr(),
 								})
@@ -724,6 +744,7 @@ Traversal:
 								diags = append(diags, &hcl.Diagnostic{
 									Severity: hcl.DiagError,
 									Summary:  "Invalid attribute name",
+									Code:     hcl.DiagInvalidAttributeName,
 									Detail:   "An attribute name is required after a dot.",
 									Subject:  &attrTok.Range,
 								})
@@ -768,6 +789,7 @@ Traversal:
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid attribute name",
+					Code:     hcl.DiagInvalidAttributeName,
 					Detail:   "An attribute name is required after a dot.",
 					Subject:  &attrTok.Range,
 				})
@@ -798,6 +820,7 @@ Traversal:
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Missing close bracket on splat index",
+						Code:     hcl.DiagMissingCloseBracketOnSplatIndex,
 						Detail:   "The star for a full splat operator must be immediately followed by a closing bracket (\"]\").",
 						Subject:  &close.Range,
 					})
@@ -837,6 +860,7 @@ Traversal:
 						diags = append(diags, &hcl.Diagnostic{
 							Severity: hcl.DiagError,
 							Summary:  "Missing close bracket on index",
+							Code:     hcl.DiagMissingCloseBracketOnIndex,
 							Detail:   "The index operator must end with a closing bracket (\"]\").",
 							Subject:  &close.Range,
 						})
@@ -928,6 +952,7 @@ func (p *parser) parseExpressionTerm() (Expression, hcl.Diagnostics) {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Unbalanced parentheses",
+				Code:     hcl.DiagUnbalancedParentheses,
 				Detail:   "Expected a closing parenthesis to terminate the expression.",
 				Subject:  &close.Range,
 				Context:  hcl.RangeBetween(start.Range, close.Range).Ptr(),
@@ -945,13 +970,18 @@ func (p *parser) parseExpressionTerm() (Expression, hcl.Diagnostics) {
 
 		numVal, diags := p.numberLitValue(tok)
 		return &LiteralValueExpr{
-			Val:      numVal,
-			SrcRange: tok.Range,
+			Val:       numVal,
+			SrcRange:  tok.Range,
+			numberLit: string(tok.Bytes),
 		}, diags
 
 	case TokenIdent:
 		tok := p.Read() // eat identifier token
 
+		if p.peekNamespaceSep() {
+			return p.finishParsingNamespacedFunctionCall(tok)
+		}
+
 		if p.Peek().Type == TokenOParen {
 			return p.finishParsingFunctionCall(tok)
 		}
@@ -992,14 +1022,18 @@ func (p *parser) parseExpressionTerm() (Expression, hcl.Diagnostics) {
 
 		closeRange := p.PrevRange()
 
-		if passthru {
+		if passthru != nil {
 			if len(exprs) != 1 {
 				panic("passthru set with len(exprs) != 1")
 			}
-			return &TemplateWrapExpr{
+			ret := &TemplateWrapExpr{
 				Wrapped:  exprs[0],
 				SrcRange: hcl.RangeBetween(open.Range, closeRange),
-			}, diags
+			}
+			if open.Type == TokenOQuote {
+				ret.quotedExprRange = passthru.ExprRange.Ptr()
+			}
+			return ret, diags
 		}
 
 		return &TemplateExpr{
@@ -1050,6 +1084,7 @@ func (p *parser) parseExpressionTerm() (Expression, hcl.Diagnostics) {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid expression",
+				Code:     hcl.DiagInvalidExpression,
 				Detail:   "Expected the start of an expression, but found an invalid expression token.",
 				Subject:  &start.Range,
 			})
@@ -1076,6 +1111,7 @@ func (p *parser) numberLitValue(tok Token) (cty.Value, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid number literal",
+				Code:     hcl.DiagInvalidNumberLiteral,
 				// FIXME: not a very good error message, but convert only
 				// gives us "a number is required", so not much help either.
 				Detail:  "Failed to recognize the value of this number literal.",
@@ -1086,6 +1122,85 @@ func (p *parser) numberLitValue(tok Token) (cty.Value, hcl.Diagnostics) {
 	return numVal, nil
 }
 
+// peekNamespaceSep returns true if the next two tokens are colons with
+// nothing between them, which together form the "::" separator used in
+// namespaced function names.
+//
+// Two adjacent colons are not valid anywhere else in the native syntax, so
+// this does not create any ambiguity with the other uses of colons.
+func (p *parser) peekNamespaceSep() bool {
+	first, nextIdx := p.nextToken()
+	if first.Type != TokenColon || nextIdx >= len(p.Tokens) {
+		return false
+	}
+	second := p.Tokens[nextIdx]
+	return second.Type == TokenColon && second.Range.Start.Byte == first.Range.End.Byte
+}
+
+// finishParsingNamespacedFunctionCall parses the remainder of a function
+// call whose name is qualified with one or more namespaces, such as
+// provider::cidr::host(...), given the first identifier in the name.
+//
+// The resulting FunctionCallExpr has the full qualified name, with its parts
+// separated by "::", as its Name.
+func (p *parser) finishParsingNamespacedFunctionCall(first Token) (Expression, hcl.Diagnostics) {
+	parts := []string{string(first.Bytes)}
+	last := first
+
+	for p.peekNamespaceSep() {
+		p.Read() // eat first colon
+		sep := p.Read()
+
+		next := p.Peek()
+		if next.Type != TokenIdent {
+			diags := hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid function name",
+					Code:     hcl.DiagInvalidFunctionName,
+					Detail:   "A namespace separator (::) must be followed by another namespace or by the function name.",
+					Subject:  &next.Range,
+					Context:  hcl.RangeBetween(first.Range, next.Range).Ptr(),
+				},
+			}
+			p.setRecovery()
+			return &LiteralValueExpr{
+				Val:      cty.DynamicVal,
+				SrcRange: hcl.RangeBetween(first.Range, sep.Range),
+			}, diags
+		}
+		last = p.Read() // eat identifier
+		parts = append(parts, string(last.Bytes))
+	}
+
+	name := Token{
+		Type:  TokenIdent,
+		Bytes: []byte(strings.Join(parts, hcl.FunctionNamespaceSeparator)),
+		Range: hcl.RangeBetween(first.Range, last.Range),
+	}
+
+	if p.Peek().Type != TokenOParen {
+		next := p.Peek()
+		diags := hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Missing function call",
+				Code:     hcl.DiagMissingFunctionCall,
+				Detail:   fmt.Sprintf("The namespaced name %s can only be used to call a function, so it must be followed by an argument list in parentheses.", name.Bytes),
+				Subject:  &next.Range,
+				Context:  hcl.RangeBetween(first.Range, next.Range).Ptr(),
+			},
+		}
+		p.setRecovery()
+		return &LiteralValueExpr{
+			Val:      cty.DynamicVal,
+			SrcRange: name.Range,
+		}, diags
+	}
+
+	return p.finishParsingFunctionCall(name)
+}
+
 // finishParsingFunctionCall parses a function call assuming that the function
 // name was already read, and so the peeker should be pointing at the opening
 // parenthesis after the name.
@@ -1138,6 +1253,7 @@ Token:
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Missing closing parenthesis",
+						Code:     hcl.DiagMissingClosingParenthesis,
 						Detail:   "An expanded function argument (with ...) must be immediately followed by closing parentheses.",
 						Subject:  &sep.Range,
 						Context:  hcl.RangeBetween(name.Range, sep.Range).Ptr(),
@@ -1154,6 +1270,7 @@ Token:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Missing argument separator",
+				Code:     hcl.DiagMissingArgumentSeparator,
 				Detail:   "A comma is required to separate each function argument from the next.",
 				Subject:  &sep.Range,
 				Context:  hcl.RangeBetween(name.Range, sep.Range).Ptr(),
@@ -1233,6 +1350,7 @@ func (p *parser) parseTupleCons() (Expression, hcl.Diagnostics) {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Missing item separator",
+					Code:     hcl.DiagMissingItemSeparator,
 					Detail:   "Expected a comma to mark the beginning of the next item.",
 					Subject:  &next.Range,
 					Context:  hcl.RangeBetween(open.Range, next.Range).Ptr(),
@@ -1317,6 +1435,7 @@ func (p *parser) parseObjectCons() (Expression, hcl.Diagnostics) {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Missing attribute value",
+						Code:     hcl.DiagMissingAttributeValue,
 						Detail:   "Expected an attribute value, introduced by an equals sign (\"=\").",
 						Subject:  &next.Range,
 						Context:  hcl.RangeBetween(open.Range, next.Range).Ptr(),
@@ -1333,6 +1452,7 @@ func (p *parser) parseObjectCons() (Expression, hcl.Diagnostics) {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Missing key/value separator",
+						Code:     hcl.DiagMissingKeyValueSeparator,
 						Detail:   "Expected an equals sign (\"=\") to mark the beginning of the attribute value. If you intended to given an attribute name containing periods or spaces, write the name in quotes to create a string literal.",
 						Subject:  &next.Range,
 						Context:  hcl.RangeBetween(open.Range, next.Range).Ptr(),
@@ -1341,6 +1461,7 @@ func (p *parser) parseObjectCons() (Expression, hcl.Diagnostics) {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Missing key/value separator",
+						Code:     hcl.DiagMissingKeyValueSeparator,
 						Detail:   "Expected an equals sign (\"=\") to mark the beginning of the attribute value.",
 						Subject:  &next.Range,
 						Context:  hcl.RangeBetween(open.Range, next.Range).Ptr(),
@@ -1380,6 +1501,7 @@ func (p *parser) parseObjectCons() (Expression, hcl.Diagnostics) {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Missing attribute separator",
+					Code:     hcl.DiagMissingAttributeSeparator,
 					Detail:   "Expected a newline or comma to mark the beginning of the next attribute.",
 					Subject:  &next.Range,
 					Context:  hcl.RangeBetween(open.Range, next.Range).Ptr(),
@@ -1432,6 +1554,7 @@ func (p *parser) finishParsingForExpr(open Token) (Expression, hcl.Diagnostics)
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid 'for' expression",
+				Code:     hcl.DiagInvalidForExpression,
 				Detail:   "For expression requires variable name after 'for'.",
 				Subject:  p.Peek().Range.Ptr(),
 				Context:  hcl.RangeBetween(open.Range, p.Peek().Range).Ptr(),
@@ -1456,6 +1579,7 @@ func (p *parser) finishParsingForExpr(open Token) (Expression, hcl.Diagnostics)
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid 'for' expression",
+					Code:     hcl.DiagInvalidForExpression,
 					Detail:   "For expression requires value variable name after comma.",
 					Subject:  p.Peek().Range.Ptr(),
 					Context:  hcl.RangeBetween(open.Range, p.Peek().Range).Ptr(),
@@ -1476,6 +1600,7 @@ func (p *parser) finishParsingForExpr(open Token) (Expression, hcl.Diagnostics)
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid 'for' expression",
+				Code:     hcl.DiagInvalidForExpression,
 				Detail:   "For expression requires the 'in' keyword after its name declarations.",
 				Subject:  p.Peek().Range.Ptr(),
 				Context:  hcl.RangeBetween(open.Range, p.Peek().Range).Ptr(),
@@ -1504,6 +1629,7 @@ func (p *parser) finishParsingForExpr(open Token) (Expression, hcl.Diagnostics)
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid 'for' expression",
+				Code:     hcl.DiagInvalidForExpression,
 				Detail:   "For expression requires a colon after the collection expression.",
 				Subject:  p.Peek().Range.Ptr(),
 				Context:  hcl.RangeBetween(open.Range, p.Peek().Range).Ptr(),
@@ -1567,6 +1693,7 @@ func (p *parser) finishParsingForExpr(open Token) (Expression, hcl.Diagnostics)
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid 'for' expression",
+				Code:     hcl.DiagInvalidForExpression,
 				Detail:   "Extra characters after the end of the 'for' expression.",
 				Subject:  p.Peek().Range.Ptr(),
 				Context:  hcl.RangeBetween(open.Range, p.Peek().Range).Ptr(),
@@ -1580,6 +1707,7 @@ func (p *parser) finishParsingForExpr(open Token) (Expression, hcl.Diagnostics)
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid 'for' expression",
+				Code:     hcl.DiagInvalidForExpression,
 				Detail:   "Key expression is not valid when building a tuple.",
 				Subject:  keyExpr.Range().Ptr(),
 				Context:  hcl.RangeBetween(open.Range, close.Range).Ptr(),
@@ -1590,6 +1718,7 @@ func (p *parser) finishParsingForExpr(open Token) (Expression, hcl.Diagnostics)
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid 'for' expression",
+				Code:     hcl.DiagInvalidForExpression,
 				Detail:   "Grouping ellipsis (...) cannot be used when building a tuple.",
 				Subject:  &ellipsis.Range,
 				Context:  hcl.RangeBetween(open.Range, close.Range).Ptr(),
@@ -1600,6 +1729,7 @@ func (p *parser) finishParsingForExpr(open Token) (Expression, hcl.Diagnostics)
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid 'for' expression",
+				Code:     hcl.DiagInvalidForExpression,
 				Detail:   "Key expression is required when building an object.",
 				Subject:  valExpr.Range().Ptr(),
 				Context:  hcl.RangeBetween(open.Range, close.Range).Ptr(),
@@ -1631,6 +1761,7 @@ func (p *parser) parseQuotedStringLiteral() (string, hcl.Range, hcl.Diagnostics)
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid string literal",
+				Code:     hcl.DiagInvalidStringLiteral,
 				Detail:   "A quoted string is required here.",
 				Subject:  &oQuote.Range,
 			},
@@ -1664,6 +1795,7 @@ Token:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid string literal",
+				Code:     hcl.DiagInvalidStringLiteral,
 				Detail: fmt.Sprintf(
 					"Template sequences are not allowed in this string. To include a literal %q, double it (as \"%s%s\") to escape it.",
 					which, which, which,
@@ -1686,6 +1818,7 @@ Token:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Unterminated string literal",
+				Code:     hcl.DiagUnterminatedStringLiteral,
 				Detail:   "Unable to find the closing quote mark before the end of the file.",
 				Subject:  &tok.Range,
 				Context:  hcl.RangeBetween(oQuote.Range, tok.Range).Ptr(),
@@ -1697,6 +1830,7 @@ Token:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid string literal",
+				Code:     hcl.DiagInvalidStringLiteral,
 				Detail:   "This item is not valid in a string literal.",
 				Subject:  &tok.Range,
 				Context:  hcl.RangeBetween(oQuote.Range, tok.Range).Ptr(),
@@ -1775,6 +1909,7 @@ Slices:
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid escape sequence",
+					Code:     hcl.DiagInvalidEscapeSequence,
 					Detail:   "Backslash must be followed by an escape sequence selector character.",
 					Subject:  rng.Ptr(),
 				})
@@ -1803,6 +1938,7 @@ Slices:
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Invalid escape sequence",
+						Code:     hcl.DiagInvalidEscapeSequence,
 						Detail:   "The \\u escape sequence must be followed by four hexadecimal digits.",
 						Subject:  rng.Ptr(),
 					})
@@ -1811,6 +1947,7 @@ Slices:
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Invalid escape sequence",
+						Code:     hcl.DiagInvalidEscapeSequence,
 						Detail:   "The \\U escape sequence must be followed by eight hexadecimal digits.",
 						Subject:  rng.Ptr(),
 					})
@@ -1831,6 +1968,7 @@ Slices:
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Invalid escape sequence",
+						Code:     hcl.DiagInvalidEscapeSequence,
 						Detail:   fmt.Sprintf("Cannot encode character U+%04x in UTF-8.", num),
 						Subject:  rng.Ptr(),
 					})
@@ -1848,6 +1986,7 @@ Slices:
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid escape sequence",
+					Code:     hcl.DiagInvalidEscapeSequence,
 					Detail:   fmt.Sprintf("The symbol %q is not a valid escape sequence selector.", slice[1:]),
 					Subject:  rng.Ptr(),
 				})
@@ -17,7 +17,7 @@ func (p *parser) ParseTemplate() (Expression, hcl.Diagnostics) {
 func (p *parser) parseTemplate(end TokenType, flushHeredoc bool) (Expression, hcl.Diagnostics) {
 	exprs, passthru, rng, diags := p.parseTemplateInner(end, flushHeredoc)
 
-	if passthru {
+	if passthru != nil {
 		if len(exprs) != 1 {
 			panic("passthru set with len(exprs) != 1")
 		}
@@ -33,7 +33,10 @@ func (p *parser) parseTemplate(end TokenType, flushHeredoc bool) (Expression, hc
 	}, diags
 }
 
-func (p *parser) parseTemplateInner(end TokenType, flushHeredoc bool) ([]Expression, bool, hcl.Range, hcl.Diagnostics) {
+// parseTemplateInner parses the parts of a template. If the template consists
+// only of a single interpolation sequence then the returned passthru token is
+// that sequence, and the template's value is the value of its expression.
+func (p *parser) parseTemplateInner(end TokenType, flushHeredoc bool) ([]Expression, *templateInterpToken, hcl.Range, hcl.Diagnostics) {
 	parts, diags := p.parseTemplateParts(end)
 	if flushHeredoc {
 		flushHeredocTemplateParts(parts) // Trim off leading spaces on lines per the flush heredoc spec
@@ -45,16 +48,33 @@ func (p *parser) parseTemplateInner(end TokenType, flushHeredoc bool) ([]Express
 	exprs, exprsDiags := tp.parseRoot()
 	diags = append(diags, exprsDiags...)
 
-	passthru := false
+	var passthru *templateInterpToken
 	if len(parts.Tokens) == 2 { // one real token and one synthetic "end" token
-		if _, isInterp := parts.Tokens[0].(*templateInterpToken); isInterp {
-			passthru = true
+		if interp, isInterp := parts.Tokens[0].(*templateInterpToken); isInterp {
+			passthru = interp
 		}
 	}
 
 	return exprs, passthru, parts.SrcRange, diags
 }
 
+// trimPrevLiterals implements a strip marker at the start of a template
+// sequence by removing trailing whitespace from the literals at the end of
+// the given parts. The lexer splits literals at newlines, so the whitespace
+// to remove may span several of them.
+func trimPrevLiterals(parts []templateToken) {
+	for i := len(parts) - 1; i >= 0; i-- {
+		lexpr, ok := parts[i].(*templateLiteralToken)
+		if !ok {
+			return
+		}
+		lexpr.Val = strings.TrimRightFunc(lexpr.Val, unicode.IsSpace)
+		if lexpr.Val != "" {
+			return
+		}
+	}
+}
+
 type templateParser struct {
 	Tokens   []templateToken
 	SrcRange hcl.Range
@@ -110,6 +130,7 @@ func (p *templateParser) parseExpr() (Expression, hcl.Diagnostics) {
 				// diagnostic that is context-aware.
 				Severity: hcl.DiagError,
 				Summary:  "Unexpected end of template",
+				Code:     hcl.DiagUnexpectedEndOfTemplate,
 				Detail:   "The control directives within this template are unbalanced.",
 				Subject:  &tok.SrcRange,
 			},
@@ -121,6 +142,7 @@ func (p *templateParser) parseExpr() (Expression, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  fmt.Sprintf("Unexpected %s directive", tok.Name()),
+				Code:     hcl.DiagUnexpectedTemplateDirective,
 				Detail:   "The control directives within this template are unbalanced.",
 				Subject:  &tok.SrcRange,
 			},
@@ -152,6 +174,7 @@ Token:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Unexpected end of template",
+				Code:     hcl.DiagUnexpectedEndOfTemplate,
 				Detail: fmt.Sprintf(
 					"The if directive at %s is missing its corresponding endif directive.",
 					openIf.SrcRange,
@@ -174,6 +197,7 @@ Token:
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Unexpected else directive",
+					Code:     hcl.DiagUnexpectedElseDirective,
 					Detail: fmt.Sprintf(
 						"Already in the else clause for the if started at %s.",
 						openIf.SrcRange,
@@ -189,6 +213,7 @@ Token:
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  fmt.Sprintf("Unexpected %s directive", end.Name()),
+					Code:     hcl.DiagUnexpectedTemplateDirective,
 					Detail: fmt.Sprintf(
 						"Expecting an endif directive for the if started at %s.",
 						openIf.SrcRange,
@@ -263,6 +288,7 @@ Token:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Unexpected end of template",
+				Code:     hcl.DiagUnexpectedEndOfTemplate,
 				Detail: fmt.Sprintf(
 					"The for directive at %s is missing its corresponding endfor directive.",
 					openFor.SrcRange,
@@ -280,6 +306,7 @@ Token:
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Unexpected else directive",
+					Code:     hcl.DiagUnexpectedElseDirective,
 					Detail:   "An else clause is not expected for a for directive.",
 					Subject:  &end.SrcRange,
 				})
@@ -292,6 +319,7 @@ Token:
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  fmt.Sprintf("Unexpected %s directive", end.Name()),
+					Code:     hcl.DiagUnexpectedTemplateDirective,
 					Detail: fmt.Sprintf(
 						"Expecting an endfor directive corresponding to the for directive at %s.",
 						openFor.SrcRange,
@@ -388,6 +416,10 @@ Token:
 
 			if ltrim {
 				str = strings.TrimLeftFunc(str, unicode.IsSpace)
+				// The lexer splits literals at newlines, so if this literal
+				// was entirely whitespace the strip marker also applies to
+				// the next one.
+				ltrimNext = str == ""
 			}
 
 			parts = append(parts, &templateLiteralToken{
@@ -400,22 +432,22 @@ Token:
 			// if the opener is ${~ then we want to eat any trailing whitespace
 			// in the preceding literal token, assuming it is indeed a literal
 			// token.
-			if canTrimPrev && len(next.Bytes) == 3 && next.Bytes[2] == '~' && len(parts) > 0 {
-				prevExpr := parts[len(parts)-1]
-				if lexpr, ok := prevExpr.(*templateLiteralToken); ok {
-					lexpr.Val = strings.TrimRightFunc(lexpr.Val, unicode.IsSpace)
-				}
+			if canTrimPrev && len(next.Bytes) == 3 && next.Bytes[2] == '~' {
+				trimPrevLiterals(parts)
 			}
 
 			p.PushIncludeNewlines(false)
+			exprStart := p.NextRange()
 			expr, exprDiags := p.ParseExpression()
 			diags = append(diags, exprDiags...)
+			exprRange := hcl.RangeBetween(exprStart, p.PrevRange())
 			close := p.Peek()
 			if close.Type != TokenTemplateSeqEnd {
 				if !p.recovery {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Extra characters after interpolation expression",
+						Code:     hcl.DiagExtraCharactersAfterInterpolationExpression,
 						Detail:   "Expected a closing brace to end the interpolation expression, but found extra characters.",
 						Subject:  &close.Range,
 						Context:  hcl.RangeBetween(startRange, close.Range).Ptr(),
@@ -434,19 +466,17 @@ Token:
 			}
 			p.PopIncludeNewlines()
 			parts = append(parts, &templateInterpToken{
-				Expr:     expr,
-				SrcRange: hcl.RangeBetween(next.Range, close.Range),
+				Expr:      expr,
+				ExprRange: exprRange,
+				SrcRange:  hcl.RangeBetween(next.Range, close.Range),
 			})
 
 		case TokenTemplateControl:
 			// if the opener is %{~ then we want to eat any trailing whitespace
 			// in the preceding literal token, assuming it is indeed a literal
 			// token.
-			if canTrimPrev && len(next.Bytes) == 3 && next.Bytes[2] == '~' && len(parts) > 0 {
-				prevExpr := parts[len(parts)-1]
-				if lexpr, ok := prevExpr.(*templateLiteralToken); ok {
-					lexpr.Val = strings.TrimRightFunc(lexpr.Val, unicode.IsSpace)
-				}
+			if canTrimPrev && len(next.Bytes) == 3 && next.Bytes[2] == '~' {
+				trimPrevLiterals(parts)
 			}
 			p.PushIncludeNewlines(false)
 
@@ -456,6 +486,7 @@ Token:
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Invalid template directive",
+						Code:     hcl.DiagInvalidTemplateDirective,
 						Detail:   "A template directive keyword (\"if\", \"for\", etc) is expected at the beginning of a %{ sequence.",
 						Subject:  &kw.Range,
 						Context:  hcl.RangeBetween(next.Range, kw.Range).Ptr(),
@@ -496,6 +527,7 @@ Token:
 						diags = append(diags, &hcl.Diagnostic{
 							Severity: hcl.DiagError,
 							Summary:  "Invalid 'for' directive",
+							Code:     hcl.DiagInvalidForDirective,
 							Detail:   "For directive requires variable name after 'for'.",
 							Subject:  p.Peek().Range.Ptr(),
 						})
@@ -517,6 +549,7 @@ Token:
 							diags = append(diags, &hcl.Diagnostic{
 								Severity: hcl.DiagError,
 								Summary:  "Invalid 'for' directive",
+								Code:     hcl.DiagInvalidForDirective,
 								Detail:   "For directive requires value variable name after comma.",
 								Subject:  p.Peek().Range.Ptr(),
 							})
@@ -534,6 +567,7 @@ Token:
 						diags = append(diags, &hcl.Diagnostic{
 							Severity: hcl.DiagError,
 							Summary:  "Invalid 'for' directive",
+							Code:     hcl.DiagInvalidForDirective,
 							Detail:   "For directive requires 'in' keyword after names.",
 							Subject:  p.Peek().Range.Ptr(),
 						})
@@ -572,6 +606,7 @@ Token:
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Invalid template control keyword",
+						Code:     hcl.DiagInvalidTemplateControlKeyword,
 						Detail:   fmt.Sprintf("%q is not a valid template control keyword.%s", given, suggestion),
 						Subject:  &kw.Range,
 						Context:  hcl.RangeBetween(next.Range, kw.Range).Ptr(),
@@ -589,6 +624,7 @@ Token:
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  fmt.Sprintf("Extra characters in %s marker", kw.Bytes),
+						Code:     hcl.DiagExtraCharactersInStripMarker,
 						Detail:   "Expected a closing brace to end the sequence, but found extra characters.",
 						Subject:  &close.Range,
 						Context:  hcl.RangeBetween(startRange, close.Range).Ptr(),
@@ -612,6 +648,7 @@ Token:
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Unterminated template string",
+					Code:     hcl.DiagUnterminatedTemplateString,
 					Detail:   "No closing marker was found for the string.",
 					Subject:  &next.Range,
 					Context:  hcl.RangeBetween(startRange, next.Range).Ptr(),
@@ -740,7 +777,12 @@ type templateLiteralToken struct {
 }
 
 type templateInterpToken struct {
-	Expr     Expression
+	Expr Expression
+
+	// ExprRange is the range of the tokens that make up Expr, which unlike
+	// Expr.Range includes any parentheses.
+	ExprRange hcl.Range
+
 	SrcRange hcl.Range
 	isTemplateToken
 }
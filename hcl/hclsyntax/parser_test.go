@@ -2413,3 +2413,33 @@ block "valid" {}
 		})
 	}
 }
+
+func TestParseNumberLit(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`1`, `1`},
+		{`1.50`, `1.50`},
+		{`1e3`, `1e3`},
+		{`2.5E-03`, `2.5E-03`},
+		{`"1"`, ``},
+		{`true`, ``},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.input), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+			var got string
+			if lit, ok := expr.(*LiteralValueExpr); ok {
+				got = lit.NumberLit()
+			}
+			if got != test.want {
+				t.Errorf("wrong result %q; want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ func (p *parser) ParseTraversalAbs() (hcl.Traversal, hcl.Diagnostics) {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Variable name required",
+			Code:     hcl.DiagVariableNameRequired,
 			Detail:   "Must begin with a variable name.",
 			Subject:  &varTok.Range,
 		})
@@ -48,6 +49,7 @@ func (p *parser) ParseTraversalAbs() (hcl.Traversal, hcl.Diagnostics) {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Attribute name required",
+						Code:     hcl.DiagAttributeNameRequired,
 						Detail:   "Splat expressions (.*) may not be used here.",
 						Subject:  &nameTok.Range,
 						Context:  hcl.RangeBetween(varTok.Range, nameTok.Range).Ptr(),
@@ -56,6 +58,7 @@ func (p *parser) ParseTraversalAbs() (hcl.Traversal, hcl.Diagnostics) {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Attribute name required",
+						Code:     hcl.DiagAttributeNameRequired,
 						Detail:   "Dot must be followed by attribute name.",
 						Subject:  &nameTok.Range,
 						Context:  hcl.RangeBetween(varTok.Range, nameTok.Range).Ptr(),
@@ -85,6 +88,7 @@ func (p *parser) ParseTraversalAbs() (hcl.Traversal, hcl.Diagnostics) {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Unclosed index brackets",
+						Code:     hcl.DiagUnclosedIndexBrackets,
 						Detail:   "Index key must be followed by a closing bracket.",
 						Subject:  &close.Range,
 						Context:  hcl.RangeBetween(open.Range, close.Range).Ptr(),
@@ -109,6 +113,7 @@ func (p *parser) ParseTraversalAbs() (hcl.Traversal, hcl.Diagnostics) {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Unclosed index brackets",
+						Code:     hcl.DiagUnclosedIndexBrackets,
 						Detail:   "Index key must be followed by a closing bracket.",
 						Subject:  &close.Range,
 						Context:  hcl.RangeBetween(open.Range, close.Range).Ptr(),
@@ -129,6 +134,7 @@ func (p *parser) ParseTraversalAbs() (hcl.Traversal, hcl.Diagnostics) {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Attribute name required",
+						Code:     hcl.DiagAttributeNameRequired,
 						Detail:   "Splat expressions ([*]) may not be used here.",
 						Subject:  &next.Range,
 						Context:  hcl.RangeBetween(varTok.Range, next.Range).Ptr(),
@@ -137,6 +143,7 @@ func (p *parser) ParseTraversalAbs() (hcl.Traversal, hcl.Diagnostics) {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Index value required",
+						Code:     hcl.DiagIndexValueRequired,
 						Detail:   "Index brackets must contain either a literal number or a literal string.",
 						Subject:  &next.Range,
 						Context:  hcl.RangeBetween(varTok.Range, next.Range).Ptr(),
@@ -149,6 +156,7 @@ func (p *parser) ParseTraversalAbs() (hcl.Traversal, hcl.Diagnostics) {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid character",
+				Code:     hcl.DiagInvalidCharacter,
 				Detail:   "Expected an attribute access or an index operator.",
 				Subject:  &next.Range,
 				Context:  hcl.RangeBetween(varTok.Range, next.Range).Ptr(),
@@ -55,6 +55,7 @@ func ParseExpression(src []byte, filename string, start hcl.Pos) (Expression, hc
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Extra characters after expression",
+			Code:     hcl.DiagExtraCharactersAfterExpression,
 			Detail:   "An expression was successfully parsed, but extra characters were found after it.",
 			Subject:  &next.Range,
 		})
@@ -169,3 +170,9 @@ func ValidIdentifier(s string) bool {
 	tokens := scanTokens([]byte(s), "", hcl.Pos{}, scanIdentOnly)
 	return len(tokens) == 2 && tokens[0].Type == TokenIdent && tokens[1].Type == TokenEOF
 }
+
+// SanitizeIdentifier returns a valid identifier based on the given string.
+// It is equivalent to hcl.SanitizeIdentifier, which has more details.
+func SanitizeIdentifier(s string) string {
+	return hcl.SanitizeIdentifier(s)
+}
@@ -2,6 +2,11 @@ package hclsyntax
 
 import (
 	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestValidIdentifier(t *testing.T) {
@@ -44,3 +49,86 @@ func TestValidIdentifier(t *testing.T) {
 		})
 	}
 }
+
+func TestSanitizeIdentifier(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  string
+	}{
+		{"", "_"},
+		{"hello", "hello"},
+		{"foo-bar_baz", "foo-bar_baz"},
+		{"hello world", "hello_world"},
+		{"aws.instance", "aws_instance"},
+		{"1blah", "_1blah"},
+		{"-foo", "_-foo"},
+		{"${var}", "__var_"},
+		{"Χαίρετε κόσμε", "Χαίρετε_κόσμε"},
+		{"e\u0301", "e\u0301"},
+		{"\u0301e", "_\u0301e"},
+		{"a\x80b", "a_b"},
+		{"!", "_"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			got := SanitizeIdentifier(test.Input)
+			if got != test.Want {
+				t.Errorf("wrong result %q; want %q", got, test.Want)
+			}
+			if !ValidIdentifier(got) {
+				t.Errorf("result %q is not a valid identifier", got)
+			}
+		})
+	}
+}
+
+// TestValidIdentifierTables checks that the range tables used by
+// hcl.ValidIdentifier agree with the scanner for every character. If this
+// fails after changing the scanner, run "go generate" in package hcl.
+func TestValidIdentifierTables(t *testing.T) {
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if !utf8.ValidRune(r) {
+			continue
+		}
+		for _, s := range []string{string(r), "a" + string(r)} {
+			if got, want := hcl.ValidIdentifier(s), ValidIdentifier(s); got != want {
+				t.Errorf("hcl.ValidIdentifier(%q) returned %t; want %t", s, got, want)
+			}
+		}
+	}
+}
+
+func TestDiagnosticCodes(t *testing.T) {
+	tests := []struct {
+		Src  string
+		Want string
+	}{
+		{"a = \"foo\n", hcl.DiagInvalidMultiLineString},
+		{"a = 1\na = 2\n", hcl.DiagAttributeRedefined},
+		{"a = (1\n", hcl.DiagUnbalancedParentheses},
+		{"a = b\n", hcl.DiagUnknownVariable},
+		{"a = foo()\n", hcl.DiagFunctionCallsNotAllowed},
+		{"a = 1 + \"x\"\n", hcl.DiagInvalidOperand},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Src, func(t *testing.T) {
+			file, diags := ParseConfig([]byte(test.Src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			if !diags.HasErrors() {
+				attrs, moreDiags := file.Body.JustAttributes()
+				diags = append(diags, moreDiags...)
+				_, moreDiags = attrs["a"].Expr.Value(&hcl.EvalContext{
+					Variables: map[string]cty.Value{},
+				})
+				diags = append(diags, moreDiags...)
+			}
+			if len(diags) == 0 {
+				t.Fatalf("no diagnostics; want %s", test.Want)
+			}
+			if got := diags[0].Code; got != test.Want {
+				t.Errorf("wrong code %q for %q; want %q", got, diags[0].Summary, test.Want)
+			}
+		})
+	}
+}
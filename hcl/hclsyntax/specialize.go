@@ -0,0 +1,286 @@
+package hclsyntax
+
+import (
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Specialize partially evaluates the given expression in the given context,
+// returning a new expression in which each sub-expression that can be
+// evaluated in that context has been replaced by a literal of its value.
+// Only the parts of the expression that refer to variables or functions that
+// the context does not define remain.
+//
+// This allows an application that evaluates configuration in several phases
+// to bake the values that are available early into an expression, and then
+// evaluate the result once the remaining values are known. The result can
+// be rendered back into source code with hclwrite.TokensForExpression.
+//
+// A sub-expression is replaced only if its value is wholly known, and never
+// if it refers to a variable that the context marks as sensitive, so that
+// sensitive values do not appear in the result. Conditional expressions are
+// replaced only as a whole, so the branches that are not taken are retained.
+// Functions are assumed to return the same result whenever they are called
+// with the same arguments, so functions that do not, such as those returning
+// the current time, should not be defined in the context.
+//
+// If a sub-expression produces error diagnostics when evaluated, those
+// diagnostics are returned and the sub-expression is left as it was. The
+// given expression is not modified, but the result may share those of its
+// nodes that did not change.
+func Specialize(expr Expression, ctx *hcl.EvalContext) (Expression, hcl.Diagnostics) {
+	s := &specializer{ctx: ctx}
+	ret, _ := s.expr(expr, nil)
+	return ret, s.diags
+}
+
+type specializer struct {
+	ctx   *hcl.EvalContext
+	diags hcl.Diagnostics
+}
+
+// specDeps describes what a specialized expression depends on, which decides
+// whether it can be evaluated in the specializer's context.
+type specDeps struct {
+	// unresolved is true if the expression cannot be evaluated, because it
+	// refers to something the context does not define or because its
+	// evaluation produced an error or an unknown value.
+	unresolved bool
+
+	// locals are the symbols bound by enclosing expressions that the
+	// expression refers to: the names of the variables declared by 'for'
+	// expressions and the *AnonSymbolExpr of splat expressions. An
+	// expression with locals can only be evaluated by the expression that
+	// binds them.
+	locals map[interface{}]bool
+}
+
+func (d *specDeps) add(other specDeps) {
+	d.unresolved = d.unresolved || other.unresolved
+	for sym := range other.locals {
+		d.addLocal(sym)
+	}
+}
+
+func (d *specDeps) addLocal(sym interface{}) {
+	if d.locals == nil {
+		d.locals = make(map[interface{}]bool)
+	}
+	d.locals[sym] = true
+}
+
+func (d *specDeps) resolved() bool {
+	return !d.unresolved && len(d.locals) == 0
+}
+
+// expr specializes the given expression, where bound is the set of names
+// declared by the enclosing 'for' expressions.
+func (s *specializer) expr(expr Expression, bound map[string]bool) (Expression, specDeps) {
+	var deps specDeps
+	sub := func(e Expression) Expression {
+		if e == nil {
+			return nil
+		}
+		ret, subDeps := s.expr(e, bound)
+		deps.add(subDeps)
+		return ret
+	}
+
+	var ret Expression
+	switch e := expr.(type) {
+	case *LiteralValueExpr:
+		return e, deps
+
+	case *ScopeTraversalExpr:
+		name := e.Traversal.RootName()
+		switch {
+		case bound[name]:
+			deps.addLocal(name)
+		case !s.variableDefined(name):
+			deps.unresolved = true
+		}
+		ret = e
+
+	case *AnonSymbolExpr:
+		// Each splat expression has its own symbol, which must be shared
+		// by the specialized splat and so is never copied.
+		deps.addLocal(e)
+		return e, deps
+
+	case *RelativeTraversalExpr:
+		ret = &RelativeTraversalExpr{
+			Source:    sub(e.Source),
+			Traversal: e.Traversal,
+			SrcRange:  e.SrcRange,
+		}
+
+	case *FunctionCallExpr:
+		if _, defined := s.ctx.Function(e.Name); !defined {
+			deps.unresolved = true
+		}
+		n := *e
+		n.Args = make([]Expression, len(e.Args))
+		for i, arg := range e.Args {
+			n.Args[i] = sub(arg)
+		}
+		ret = &n
+
+	case *ConditionalExpr:
+		ret = &ConditionalExpr{
+			Condition:   sub(e.Condition),
+			TrueResult:  sub(e.TrueResult),
+			FalseResult: sub(e.FalseResult),
+			SrcRange:    e.SrcRange,
+		}
+
+	case *IndexExpr:
+		n := *e
+		n.Collection = sub(e.Collection)
+		n.Key = sub(e.Key)
+		ret = &n
+
+	case *TupleConsExpr:
+		n := *e
+		n.Exprs = make([]Expression, len(e.Exprs))
+		for i, elem := range e.Exprs {
+			n.Exprs[i] = sub(elem)
+		}
+		ret = &n
+
+	case *ObjectConsExpr:
+		n := *e
+		n.Items = make([]ObjectConsItem, len(e.Items))
+		for i, item := range e.Items {
+			n.Items[i] = ObjectConsItem{
+				KeyExpr:   sub(item.KeyExpr),
+				ValueExpr: sub(item.ValueExpr),
+			}
+		}
+		ret = &n
+
+	case *ObjectConsKeyExpr:
+		if e.literalName() != "" {
+			return e, deps
+		}
+		wrapped := sub(e.Wrapped)
+		if lit, ok := wrapped.(*LiteralValueExpr); ok && lit.Val.IsNull() {
+			// A null literal as a key would be taken as the keyword "null",
+			// rather than producing the error that the original expression
+			// will produce.
+			wrapped = e.Wrapped
+		}
+		ret = &ObjectConsKeyExpr{Wrapped: wrapped}
+
+	case *BinaryOpExpr:
+		ret = &BinaryOpExpr{
+			LHS:      sub(e.LHS),
+			Op:       e.Op,
+			RHS:      sub(e.RHS),
+			SrcRange: e.SrcRange,
+		}
+
+	case *UnaryOpExpr:
+		n := *e
+		n.Val = sub(e.Val)
+		ret = &n
+
+	case *TemplateExpr:
+		n := *e
+		n.Parts = make([]Expression, len(e.Parts))
+		for i, part := range e.Parts {
+			n.Parts[i] = sub(part)
+		}
+		ret = &n
+
+	case *TemplateJoinExpr:
+		ret = &TemplateJoinExpr{Tuple: sub(e.Tuple)}
+
+	case *TemplateWrapExpr:
+		n := *e
+		n.Wrapped = sub(e.Wrapped)
+		ret = &n
+
+	case *ForExpr:
+		n := *e
+		n.CollExpr = sub(e.CollExpr)
+
+		inner := make(map[string]bool, len(bound)+2)
+		for name := range bound {
+			inner[name] = true
+		}
+		if e.KeyVar != "" {
+			inner[e.KeyVar] = true
+		}
+		inner[e.ValVar] = true
+
+		var bodyDeps specDeps
+		for _, field := range []*Expression{&n.KeyExpr, &n.ValExpr, &n.CondExpr} {
+			if *field != nil {
+				var fieldDeps specDeps
+				*field, fieldDeps = s.expr(*field, inner)
+				bodyDeps.add(fieldDeps)
+			}
+		}
+		delete(bodyDeps.locals, e.KeyVar)
+		delete(bodyDeps.locals, e.ValVar)
+		deps.add(bodyDeps)
+		ret = &n
+
+	case *SplatExpr:
+		n := *e
+		n.Source = sub(e.Source)
+		n.Each = sub(e.Each)
+		delete(deps.locals, e.Item)
+		ret = &n
+
+	default:
+		// We don't know how to look inside other expression types, so we
+		// leave them as they are.
+		deps.unresolved = true
+		return expr, deps
+	}
+
+	return s.fold(ret, deps)
+}
+
+// fold replaces the given expression with a literal of its value, if it can
+// be evaluated.
+func (s *specializer) fold(expr Expression, deps specDeps) (Expression, specDeps) {
+	if !deps.resolved() {
+		return expr, deps
+	}
+	if s.ctx.ExpressionSensitive(expr) {
+		deps.unresolved = true
+		return expr, deps
+	}
+
+	val, diags := expr.Value(s.ctx)
+	s.diags = append(s.diags, diags...)
+	if diags.HasErrors() || !val.IsWhollyKnown() {
+		// The enclosing expressions can't be evaluated either, and in the
+		// case of errors we don't want to report them again.
+		deps.unresolved = true
+		return expr, deps
+	}
+	return &LiteralValueExpr{
+		Val:      val,
+		SrcRange: expr.Range(),
+	}, deps
+}
+
+// variableDefined returns true if the context defines the variable with the
+// given name, either in a Variables map or through a VariableResolver.
+func (s *specializer) variableDefined(name string) bool {
+	for ctx := s.ctx; ctx != nil; ctx = ctx.Parent() {
+		if _, exists := ctx.Variables[name]; exists {
+			return true
+		}
+		if ctx.VariableResolver != nil {
+			val, diags := ctx.VariableResolver(name)
+			if val != cty.NilVal || diags.HasErrors() {
+				return true
+			}
+		}
+	}
+	return false
+}
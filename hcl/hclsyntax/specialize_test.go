@@ -0,0 +1,168 @@
+package hclsyntax
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestSpecialize(t *testing.T) {
+	early := map[string]cty.Value{
+		"a":    cty.NumberIntVal(2),
+		"name": cty.StringVal("world"),
+		"list": cty.ListVal([]cty.Value{cty.StringVal("x"), cty.StringVal("y")}),
+		"objs": cty.TupleVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("p")}),
+			cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("q")}),
+		}),
+	}
+	late := map[string]cty.Value{
+		"b":    cty.NumberIntVal(3),
+		"sep":  cty.StringVal("-"),
+		"cond": cty.True,
+	}
+	funcs := map[string]function.Function{
+		"upper": stdlib.UpperFunc,
+	}
+
+	tests := []struct {
+		src string
+		// wantLiteral is true if the whole expression is expected to be
+		// replaced by a literal.
+		wantLiteral bool
+		// wantVars are the root names of the variables that the result is
+		// expected to still refer to.
+		wantVars []string
+	}{
+		{`a + 1`, true, nil},
+		{`a + b`, false, []string{"b"}},
+		{`a * 2 + b`, false, []string{"b"}},
+		{`upper(name)`, true, nil},
+		{`lower(name)`, false, nil},
+		{`"hello ${upper(name)}${sep}${b}"`, false, []string{"b", "sep"}},
+		{`cond ? a + 1 : name`, false, []string{"cond"}},
+		{`[for v in list : "${v}${sep}"]`, false, []string{"sep"}},
+		{`[for v in list : upper(v)]`, true, nil},
+		{`{for i, v in list : v => i + b}`, false, []string{"b"}},
+		{`objs[*].id`, true, nil},
+		{`objs[b - 3].id`, false, []string{"b"}},
+		{`{ upper(name) = b, (a + 1) = "two" }`, false, []string{"b"}},
+		{`"%{ for v in list }${v}${sep}%{ endfor }"`, false, []string{"sep"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := ParseExpression([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+
+			got, diags := Specialize(expr, &hcl.EvalContext{
+				Variables: early,
+				Functions: funcs,
+			})
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			if _, isLit := got.(*LiteralValueExpr); isLit != test.wantLiteral {
+				t.Errorf("got %T; want literal %t", got, test.wantLiteral)
+			}
+
+			var gotVars []string
+			for _, traversal := range got.Variables() {
+				gotVars = append(gotVars, traversal.RootName())
+			}
+			sort.Strings(gotVars)
+			if !reflect.DeepEqual(gotVars, test.wantVars) {
+				t.Errorf("wrong remaining variables\ngot:  %#v\nwant: %#v", gotVars, test.wantVars)
+			}
+
+			// The specialized expression must produce the same result as the
+			// original once all of the variables are available.
+			all := make(map[string]cty.Value)
+			for k, v := range early {
+				all[k] = v
+			}
+			for k, v := range late {
+				all[k] = v
+			}
+			funcs := map[string]function.Function{
+				"upper": stdlib.UpperFunc,
+				"lower": stdlib.LowerFunc,
+			}
+			ctx := &hcl.EvalContext{Variables: all, Functions: funcs}
+			want, diags := expr.Value(ctx)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors from original: %s", diags.Error())
+			}
+			gotVal, diags := got.Value(ctx)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors from result: %s", diags.Error())
+			}
+			if !gotVal.RawEquals(want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", gotVal, want)
+			}
+		})
+	}
+}
+
+func TestSpecializeSensitive(t *testing.T) {
+	expr, diags := ParseExpression([]byte(`"${password}:${user}"`), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	got, diags := Specialize(expr, &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"password": cty.StringVal("hunter2"),
+			"user":     cty.StringVal("admin"),
+		},
+		SensitiveVariables: map[string]bool{"password": true},
+	})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	tmpl, ok := got.(*TemplateExpr)
+	if !ok {
+		t.Fatalf("got %T; want *TemplateExpr", got)
+	}
+	if _, ok := tmpl.Parts[0].(*ScopeTraversalExpr); !ok {
+		t.Errorf("sensitive reference was replaced by %T", tmpl.Parts[0])
+	}
+	if lit, ok := tmpl.Parts[2].(*LiteralValueExpr); !ok || !lit.Val.RawEquals(cty.StringVal("admin")) {
+		t.Errorf("non-sensitive reference was not replaced: %#v", tmpl.Parts[2])
+	}
+}
+
+func TestSpecializeErrors(t *testing.T) {
+	expr, diags := ParseExpression([]byte(`[a.missing, a + b]`), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	got, diags := Specialize(expr, &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"a": cty.EmptyObjectVal,
+		},
+	})
+	if len(diags) != 1 || diags[0].Summary != "Unsupported attribute" {
+		t.Fatalf("wrong diagnostics: %s", diags.Error())
+	}
+
+	// The erroneous element is kept as it was, and no attempt is made to
+	// evaluate the tuple that contains it.
+	tuple, ok := got.(*TupleConsExpr)
+	if !ok {
+		t.Fatalf("got %T; want *TupleConsExpr", got)
+	}
+	if _, ok := tuple.Exprs[0].(*ScopeTraversalExpr); !ok {
+		t.Errorf("erroneous element was replaced by %T", tuple.Exprs[0])
+	}
+}
@@ -71,8 +71,10 @@ func (b *Body) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostic
 				}
 				suggestions = append(suggestions, attrS.Name)
 			}
+			var fixes []hcl.Suggestion
 			suggestion := nameSuggestion(name, suggestions)
 			if suggestion != "" {
+				fixes = renameSuggestion(attr.NameRange, suggestion)
 				suggestion = fmt.Sprintf(" Did you mean %q?", suggestion)
 			} else {
 				// Is there a block of the same name?
@@ -83,12 +85,17 @@ func (b *Body) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostic
 					}
 				}
 			}
+			if suggestion == "" {
+				suggestion = candidateNames("argument", "arguments", suggestions)
+			}
 
 			diags = append(diags, &hcl.Diagnostic{
-				Severity: hcl.DiagError,
-				Summary:  "Unsupported argument",
-				Detail:   fmt.Sprintf("An argument named %q is not expected here.%s", name, suggestion),
-				Subject:  &attr.NameRange,
+				Severity:    hcl.DiagError,
+				Summary:     "Unsupported argument",
+				Code:        hcl.DiagUnsupportedArgument,
+				Detail:      fmt.Sprintf("An argument named %q is not expected here.%s", name, suggestion),
+				Subject:     &attr.NameRange,
+				Suggestions: fixes,
 			})
 		}
 	}
@@ -100,8 +107,10 @@ func (b *Body) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostic
 			for _, blockS := range schema.Blocks {
 				suggestions = append(suggestions, blockS.Type)
 			}
+			var fixes []hcl.Suggestion
 			suggestion := nameSuggestion(blockTy, suggestions)
 			if suggestion != "" {
+				fixes = renameSuggestion(block.TypeRange, suggestion)
 				suggestion = fmt.Sprintf(" Did you mean %q?", suggestion)
 			} else {
 				// Is there an attribute of the same name?
@@ -112,12 +121,17 @@ func (b *Body) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostic
 					}
 				}
 			}
+			if suggestion == "" {
+				suggestion = candidateNames("block type", "block types", suggestions)
+			}
 
 			diags = append(diags, &hcl.Diagnostic{
-				Severity: hcl.DiagError,
-				Summary:  "Unsupported block type",
-				Detail:   fmt.Sprintf("Blocks of type %q are not expected here.%s", blockTy, suggestion),
-				Subject:  &block.TypeRange,
+				Severity:    hcl.DiagError,
+				Summary:     "Unsupported block type",
+				Code:        hcl.DiagUnsupportedBlockType,
+				Detail:      fmt.Sprintf("Blocks of type %q are not expected here.%s", blockTy, suggestion),
+				Subject:     &block.TypeRange,
+				Suggestions: fixes,
 			})
 		}
 	}
@@ -152,6 +166,7 @@ func (b *Body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Missing required argument",
+					Code:     hcl.DiagMissingRequiredArgument,
 					Detail:   fmt.Sprintf("The argument %q is required, but no definition was found.", attrS.Name),
 					Subject:  b.MissingItemRange().Ptr(),
 				})
@@ -168,7 +183,7 @@ func (b *Body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 		blocksWanted[blockS.Type] = blockS
 	}
 
-	for _, block := range b.Blocks {
+	for i, block := range b.Blocks {
 		if _, hidden := hiddenBlocks[block.Type]; hidden {
 			continue
 		}
@@ -183,6 +198,7 @@ func (b *Body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  fmt.Sprintf("Extraneous label for %s", name),
+					Code:     hcl.DiagExtraneousBlockLabel,
 					Detail: fmt.Sprintf(
 						"No labels are expected for %s blocks.", name,
 					),
@@ -193,6 +209,7 @@ func (b *Body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  fmt.Sprintf("Extraneous label for %s", name),
+					Code:     hcl.DiagExtraneousBlockLabel,
 					Detail: fmt.Sprintf(
 						"Only %d labels (%s) are expected for %s blocks.",
 						len(blockS.LabelNames), strings.Join(blockS.LabelNames, ", "), name,
@@ -209,6 +226,7 @@ func (b *Body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  fmt.Sprintf("Missing %s for %s", blockS.LabelNames[len(block.Labels)], name),
+				Code:     hcl.DiagMissingBlockLabel,
 				Detail: fmt.Sprintf(
 					"All %s blocks must have %d labels (%s).",
 					name, len(blockS.LabelNames), strings.Join(blockS.LabelNames, ", "),
@@ -219,7 +237,9 @@ func (b *Body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 			continue
 		}
 
-		blocks = append(blocks, block.AsHCLBlock())
+		hclBlock := block.AsHCLBlock()
+		hclBlock.Index = i
+		blocks = append(blocks, hclBlock)
 	}
 
 	// We hide blocks only after we've processed all of them, since otherwise
@@ -239,29 +259,37 @@ func (b *Body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 		EndRange: b.EndRange,
 	}
 
-	return &hcl.BodyContent{
+	content := &hcl.BodyContent{
 		Attributes: attrs,
 		Blocks:     blocks,
 
 		MissingItemRange: b.MissingItemRange(),
-	}, remain, diags
+	}
+	diags = append(diags, hcl.DeprecationDiagnostics(schema, content)...)
+
+	return content, remain, diags
 }
 
 func (b *Body) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
 	attrs := make(hcl.Attributes)
 	var diags hcl.Diagnostics
 
-	if len(b.Blocks) > 0 {
-		example := b.Blocks[0]
+	for _, example := range b.Blocks {
+		if _, hidden := b.hiddenBlocks[example.Type]; hidden {
+			// Blocks already consumed by PartialContent are not our concern.
+			continue
+		}
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  fmt.Sprintf("Unexpected %q block", example.Type),
+			Code:     hcl.DiagUnexpectedBlock,
 			Detail:   "Blocks are not allowed here.",
 			Subject:  &example.TypeRange,
 		})
 		// we will continue processing anyway, and return the attributes
 		// we are able to find so that certain analyses can still be done
 		// in the face of errors.
+		break
 	}
 
 	if b.Attributes == nil {
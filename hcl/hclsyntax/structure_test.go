@@ -3,6 +3,7 @@ package hclsyntax
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/hcl2/hcl"
@@ -196,8 +197,9 @@ func TestBodyContent(t *testing.T) {
 						Body: (*Body)(nil),
 					},
 					{
-						Type: "foo",
-						Body: (*Body)(nil),
+						Type:  "foo",
+						Body:  (*Body)(nil),
+						Index: 1,
 					},
 				},
 			},
@@ -531,3 +533,216 @@ func TestBodyJustAttributes(t *testing.T) {
 		})
 	}
 }
+
+func TestBodyContentSuggestions(t *testing.T) {
+	src := "nmae = \"a\"\nservce {\n}\n"
+	file, diags := ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics while parsing: %s", diags.Error())
+	}
+
+	_, diags = file.Body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "name"},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "service"},
+		},
+	})
+	if len(diags) != 2 {
+		t.Fatalf("wrong number of diagnostics %d; want 2", len(diags))
+	}
+	for _, diag := range diags {
+		if len(diag.Suggestions) != 1 {
+			t.Errorf("wrong number of suggestions %d for %q; want 1", len(diag.Suggestions), diag.Summary)
+		}
+	}
+
+	got, err := hcl.ApplyEdits([]byte(src), diags.Edits("test.hcl"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := "name = \"a\"\nservice {\n}\n"
+	if string(got) != want {
+		t.Errorf("wrong result\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestBodyContentDeprecated(t *testing.T) {
+	src := "ami = \"a\"\ntag {\n}\ntag {\n}\nimage = \"b\"\n"
+	file, diags := ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics while parsing: %s", diags.Error())
+	}
+
+	_, diags = file.Body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "ami", Deprecated: "Use image instead.", ReplacedBy: "image"},
+			{Name: "image", Deprecated: "Unused."},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "tag", Deprecated: "Use the tags argument instead."},
+		},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+
+	want := []string{
+		`test.hcl:1,1-4: Deprecated argument; The argument "ami" is deprecated. Use image instead.`,
+		`test.hcl:6,1-6: Deprecated argument; The argument "image" is deprecated. Unused.`,
+		`test.hcl:2,1-4: Deprecated block type; Blocks of type "tag" are deprecated. Use the tags argument instead.`,
+		`test.hcl:4,1-4: Deprecated block type; Blocks of type "tag" are deprecated. Use the tags argument instead.`,
+	}
+	var got []string
+	for _, diag := range diags {
+		if diag.Severity != hcl.DiagWarning || diag.Category != hcl.DiagnosticCategoryDeprecation {
+			t.Errorf("wrong severity or category for %q", diag.Error())
+		}
+		got = append(got, diag.Error())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong diagnostics\ngot:  %s\nwant: %s", strings.Join(got, "\n      "), strings.Join(want, "\n      "))
+	}
+
+	fixed, err := hcl.ApplyEdits([]byte(src), diags.Edits("test.hcl"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	wantSrc := "image = \"a\"\ntag {\n}\ntag {\n}\nimage = \"b\"\n"
+	if string(fixed) != wantSrc {
+		t.Errorf("wrong result\ngot:  %q\nwant: %q", fixed, wantSrc)
+	}
+}
+
+func TestContentWithExtraAttributes(t *testing.T) {
+	src := `
+name = "foo"
+environment = "production"
+owner = "ops"
+
+lifecycle {
+}
+`
+	file, diags := ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics while parsing: %s", diags.Error())
+	}
+
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "name", Required: true},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "lifecycle"},
+		},
+	}
+	content, extra, diags := hcl.ContentWithExtraAttributes(file.Body, schema)
+	if len(diags) != 0 {
+		t.Errorf("unexpected diagnostics: %s", diags.Error())
+	}
+	if _, ok := content.Attributes["name"]; !ok || len(content.Attributes) != 1 {
+		t.Errorf("wrong declared attributes %#v", content.Attributes)
+	}
+	if len(content.Blocks) != 1 {
+		t.Errorf("wrong number of blocks %d; want 1", len(content.Blocks))
+	}
+	if len(extra) != 2 || extra["environment"] == nil || extra["owner"] == nil {
+		t.Errorf("wrong extra attributes %#v", extra)
+	}
+
+	// Undeclared blocks are still an error.
+	file, _ = ParseConfig([]byte("name = \"foo\"\nother {\n}\n"), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	_, extra, diags = hcl.ContentWithExtraAttributes(file.Body, schema)
+	if len(diags) != 1 {
+		t.Errorf("wrong number of diagnostics %d; want 1", len(diags))
+	}
+	if extra == nil || len(extra) != 0 {
+		t.Errorf("wrong extra attributes %#v", extra)
+	}
+}
+
+func TestBodyContentCandidates(t *testing.T) {
+	src := "color = \"red\"\nwidget {\n}\n"
+	file, diags := ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics while parsing: %s", diags.Error())
+	}
+
+	_, diags = file.Body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "name"},
+			{Name: "size"},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "service"},
+		},
+	})
+	if len(diags) != 2 {
+		t.Fatalf("wrong number of diagnostics %d; want 2", len(diags))
+	}
+	want := []string{
+		`An argument named "color" is not expected here. The supported arguments are "name" and "size".`,
+		`Blocks of type "widget" are not expected here. The only supported block type is "service".`,
+	}
+	for i, diag := range diags {
+		if diag.Detail != want[i] {
+			t.Errorf("wrong detail for diagnostic %d\ngot:  %s\nwant: %s", i, diag.Detail, want[i])
+		}
+	}
+}
+
+func TestBodyAttributesInOrder(t *testing.T) {
+	src := "zebra = 1\napple = 2\nmango = 3\n"
+	file, diags := ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+
+	attrs, diags := hcl.AttributesInOrder(file.Body)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	var got []string
+	for _, attr := range attrs {
+		got = append(got, attr.Name)
+	}
+	want := []string{"zebra", "apple", "mango"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong order\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got, want := attrs[1].NameRange.Start.Line, 2; got != want {
+		t.Errorf("wrong name range line %d for %s; want %d", got, attrs[1].Name, want)
+	}
+}
+
+func TestBodyPartialContentBlockIndex(t *testing.T) {
+	src := "a {}\nb {}\nattr = 1\na {}\nb {}\n"
+	file, diags := ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+
+	aContent, remain, diags := file.Body.PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "a"}},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	bContent, diags := remain.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "attr"}},
+		Blocks:     []hcl.BlockHeaderSchema{{Type: "b"}},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	var got []int
+	for _, block := range append(aContent.Blocks, bContent.Blocks...) {
+		got = append(got, block.Index)
+	}
+	if want := []int{0, 2, 1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong indices %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+package hclsyntax
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// TemplatePart is one of the parts of a template, as returned by
+// TemplateParts. A part is either literal text or a dynamic part, which is an
+// interpolation sequence or a directive.
+type TemplatePart struct {
+	// Expr is the expression of a dynamic part, or nil for literal text.
+	//
+	// For an interpolation sequence this is the interpolated expression.
+	// For an "if" directive it is a ConditionalExpr, and for a "for"
+	// directive it is a TemplateJoinExpr, whose results are templates in
+	// their own right that can be inspected in the same way.
+	Expr Expression
+
+	// Literal is the text of a literal part, after the processing of escape
+	// sequences, strip markers and heredoc indentation.
+	Literal string
+
+	// Range is the source range of the part. For an interpolation sequence
+	// this is the range of its expression, excluding the delimiters.
+	Range hcl.Range
+}
+
+// IsLiteral returns true if the part is literal text.
+func (p TemplatePart) IsLiteral() bool {
+	return p.Expr == nil
+}
+
+// TemplateParts statically decomposes the given template expression into
+// its literal and dynamic parts, in order. Adjacent literal text is combined
+// into a single part, and literal text that is empty, such as because it was
+// removed by strip markers, is omitted.
+//
+// The second return value is false if the expression is not a template. A
+// template that consists only of a single interpolation sequence, which
+// evaluates to the interpolated value verbatim, has a single dynamic part.
+//
+// This allows tools such as documentation generators and refactoring tools
+// to inspect templates without evaluating them.
+func TemplateParts(expr Expression) ([]TemplatePart, bool) {
+	switch te := expr.(type) {
+	case *TemplateWrapExpr:
+		return []TemplatePart{{Expr: te.Wrapped, Range: te.Wrapped.Range()}}, true
+	case *TemplateExpr:
+		var parts []TemplatePart
+		for _, part := range te.Parts {
+			lit, isLit := templateLiteral(part)
+			switch {
+			case !isLit:
+				parts = append(parts, TemplatePart{Expr: part, Range: part.Range()})
+			case lit == "":
+				// Nothing to do.
+			case len(parts) > 0 && parts[len(parts)-1].IsLiteral():
+				prev := &parts[len(parts)-1]
+				prev.Literal += lit
+				prev.Range = hcl.RangeOver(prev.Range, part.Range())
+			default:
+				parts = append(parts, TemplatePart{Literal: lit, Range: part.Range()})
+			}
+		}
+		return parts, true
+	default:
+		return nil, false
+	}
+}
+
+// TemplateLiteralPrefix returns the literal text at the start of the given
+// template expression, before its first dynamic part, or the empty string
+// if it has no such text or is not a template. If the template has no
+// dynamic parts then the result is its entire value.
+//
+// This can be used, for example, to find the constant part of a path or URL
+// that is constructed by a template.
+func TemplateLiteralPrefix(expr Expression) string {
+	parts, _ := TemplateParts(expr)
+	var buf strings.Builder
+	for _, part := range parts {
+		if !part.IsLiteral() {
+			break
+		}
+		buf.WriteString(part.Literal)
+	}
+	return buf.String()
+}
+
+// TemplateLiteralSuffix returns the literal text at the end of the given
+// template expression, after its last dynamic part, or the empty string if
+// it has no such text or is not a template. As for TemplateLiteralPrefix, if
+// the template has no dynamic parts then the result is its entire value.
+func TemplateLiteralSuffix(expr Expression) string {
+	parts, _ := TemplateParts(expr)
+	i := len(parts)
+	for i > 0 && parts[i-1].IsLiteral() {
+		i--
+	}
+	var buf strings.Builder
+	for _, part := range parts[i:] {
+		buf.WriteString(part.Literal)
+	}
+	return buf.String()
+}
+
+// UnwrapTemplate returns the interpolated expression of a template that
+// consists only of a single interpolation sequence, like "${var.name}", and
+// true. It returns the given expression and false if the expression is any
+// other kind of expression or template.
+func UnwrapTemplate(expr Expression) (Expression, bool) {
+	if wrap, ok := expr.(*TemplateWrapExpr); ok {
+		return wrap.Wrapped, true
+	}
+	return expr, false
+}
+
+// templateLiteral returns the text of the given template part and true if it
+// is literal text. The parser represents literal text as string literals,
+// which cannot otherwise appear directly in a template's parts because a
+// quoted string in an interpolation sequence is itself a template.
+func templateLiteral(part Expression) (string, bool) {
+	lit, ok := part.(*LiteralValueExpr)
+	if !ok || lit.Val.Type() != cty.String || !lit.Val.IsKnown() || lit.Val.IsNull() {
+		return "", false
+	}
+	return lit.Val.AsString(), true
+}
@@ -0,0 +1,123 @@
+package hclsyntax
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+func TestTemplateParts(t *testing.T) {
+	tests := []struct {
+		src        string
+		wantParts  []string // literal text quoted, dynamic parts as "<Type src>"
+		wantPrefix string
+		wantSuffix string
+	}{
+		{
+			`"hello"`,
+			[]string{`"hello"`},
+			"hello",
+			"hello",
+		},
+		{
+			`""`,
+			nil,
+			"",
+			"",
+		},
+		{
+			`"https://${host}:${port}/api"`,
+			[]string{`"https://"`, `<*hclsyntax.ScopeTraversalExpr host>`, `":"`, `<*hclsyntax.ScopeTraversalExpr port>`, `"/api"`},
+			"https://",
+			"/api",
+		},
+		{
+			`"${a}"`,
+			[]string{`<*hclsyntax.ScopeTraversalExpr a>`},
+			"",
+			"",
+		},
+		{
+			`"a ${~ x ~} b"`,
+			[]string{`"a"`, `<*hclsyntax.ScopeTraversalExpr x>`, `"b"`},
+			"a",
+			"b",
+		},
+		{
+			"<<EOT\n  a\n  b ${x}\nEOT\n",
+			[]string{`"  a\n  b "`, `<*hclsyntax.ScopeTraversalExpr x>`, `"\n"`},
+			"  a\n  b ",
+			"\n",
+		},
+		{
+			`"x%{ if c }y%{ endif }${"z"}"`,
+			[]string{`"x"`, `<*hclsyntax.ConditionalExpr %{ if c }y%{ endif }>`, `<*hclsyntax.TemplateExpr "z">`},
+			"x",
+			"",
+		},
+		{
+			`"%{ for v in l }${v}%{ endfor }."`,
+			[]string{`<*hclsyntax.TemplateJoinExpr %{ for v in l }${v}%{ endfor }>`, `"."`},
+			"",
+			".",
+		},
+		{
+			`a`,
+			nil,
+			"",
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			src := []byte(test.src)
+			expr, diags := ParseExpression(src, "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+
+			parts, ok := TemplateParts(expr)
+			if _, isTraversal := expr.(*ScopeTraversalExpr); ok == isTraversal {
+				t.Fatalf("wrong ok result %t", ok)
+			}
+			var got []string
+			for _, part := range parts {
+				text := string(part.Range.SliceBytes(src))
+				if part.IsLiteral() {
+					got = append(got, fmt.Sprintf("%q", part.Literal))
+				} else {
+					got = append(got, fmt.Sprintf("<%T %s>", part.Expr, text))
+				}
+			}
+			if !reflect.DeepEqual(got, test.wantParts) {
+				t.Errorf("wrong parts\ngot:  %#v\nwant: %#v", got, test.wantParts)
+			}
+
+			if got := TemplateLiteralPrefix(expr); got != test.wantPrefix {
+				t.Errorf("wrong prefix %q; want %q", got, test.wantPrefix)
+			}
+			if got := TemplateLiteralSuffix(expr); got != test.wantSuffix {
+				t.Errorf("wrong suffix %q; want %q", got, test.wantSuffix)
+			}
+		})
+	}
+}
+
+func TestUnwrapTemplate(t *testing.T) {
+	expr, _ := ParseExpression([]byte(`"${a.b}"`), "", hcl.Pos{Line: 1, Column: 1})
+	inner, ok := UnwrapTemplate(expr)
+	if !ok {
+		t.Fatalf("interpolation-only template was not unwrapped")
+	}
+	if _, isTraversal := inner.(*ScopeTraversalExpr); !isTraversal {
+		t.Errorf("wrong inner expression %T", inner)
+	}
+
+	expr, _ = ParseExpression([]byte(`"${a.b}c"`), "", hcl.Pos{Line: 1, Column: 1})
+	if got, ok := UnwrapTemplate(expr); ok || got != expr {
+		t.Errorf("template with literal text was unwrapped")
+	}
+}
@@ -210,6 +210,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Unsupported operator",
+					Code:     hcl.DiagUnsupportedOperator,
 					Detail:   fmt.Sprintf("Bitwise operators are not supported.%s", suggestion),
 					Subject:  &tok.Range,
 				})
@@ -220,6 +221,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Unsupported operator",
+					Code:     hcl.DiagUnsupportedOperator,
 					Detail:   "\"**\" is not a supported operator. Exponentiation is not supported as an operator.",
 					Subject:  &tok.Range,
 				})
@@ -233,6 +235,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid character",
+					Code:     hcl.DiagInvalidCharacter,
 					Detail:   "The \"`\" character is not valid. To create a multi-line string, use the \"heredoc\" syntax, like \"<<EOT\".",
 					Subject:  &tok.Range,
 				})
@@ -245,6 +248,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 				newDiag := &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid character",
+					Code:     hcl.DiagInvalidCharacter,
 					Detail:   "Single quotes are not valid. Use double quotes (\") to enclose strings.",
 					Subject:  &tok.Range,
 				}
@@ -258,6 +262,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid character",
+					Code:     hcl.DiagInvalidCharacter,
 					Detail:   "The \";\" character is not valid. Use newlines to separate arguments and blocks, and commas to separate items in collection values.",
 					Subject:  &tok.Range,
 				})
@@ -269,6 +274,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid character",
+					Code:     hcl.DiagInvalidCharacter,
 					Detail:   "Tab characters may not be used. The recommended indentation style is two spaces per indent.",
 					Subject:  &tok.Range,
 				})
@@ -280,6 +286,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid character encoding",
+					Code:     hcl.DiagInvalidCharacterEncoding,
 					Detail:   "All input files must be UTF-8 encoded. Ensure that UTF-8 encoding is selected in your editor.",
 					Subject:  &tok.Range,
 				})
@@ -290,6 +297,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid multi-line string",
+				Code:     hcl.DiagInvalidMultiLineString,
 				Detail:   "Quoted strings may not be split over multiple lines. To produce a multi-line string, either use the \\n escape to represent a newline character or use the \"heredoc\" multi-line template syntax.",
 				Subject:  &tok.Range,
 			})
@@ -297,6 +305,7 @@ func checkInvalidTokens(tokens Tokens) hcl.Diagnostics {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid character",
+				Code:     hcl.DiagInvalidCharacter,
 				Detail:   "This character is not used within the language.",
 				Subject:  &tok.Range,
 			})
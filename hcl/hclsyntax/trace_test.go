@@ -0,0 +1,70 @@
+package hclsyntax
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestEvalTrace(t *testing.T) {
+	src := `a + [for x in [1, 2] : x * 10][1]`
+	expr, diags := ParseExpression([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	var got []string
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"a": cty.NumberIntVal(1),
+		},
+		Trace: func(expr hcl.Expression, ctx *hcl.EvalContext, val cty.Value, diags hcl.Diagnostics) {
+			rng := expr.Range()
+			entry := fmt.Sprintf("%s = %s", src[rng.Start.Byte:rng.End.Byte], val.GoString())
+			if x, ok := ctx.Variable("x"); ok {
+				entry += fmt.Sprintf(" (x = %s)", x.GoString())
+			}
+			got = append(got, entry)
+		},
+	}
+
+	// We evaluate in a child context to check that the trace function is
+	// inherited.
+	val, diags := expr.Value(ctx.NewChild())
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if want := cty.NumberIntVal(21); !val.RawEquals(want) {
+		t.Errorf("wrong result %#v; want %#v", val, want)
+	}
+
+	want := []string{
+		`a = cty.NumberIntVal(1)`,
+		`1 = cty.NumberIntVal(1)`,
+		`2 = cty.NumberIntVal(2)`,
+		`[1, 2] = cty.TupleVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)})`,
+		`x = cty.NumberIntVal(1) (x = cty.NumberIntVal(1))`,
+		`10 = cty.NumberIntVal(10) (x = cty.NumberIntVal(1))`,
+		`x * 10 = cty.NumberIntVal(10) (x = cty.NumberIntVal(1))`,
+		`x = cty.NumberIntVal(2) (x = cty.NumberIntVal(2))`,
+		`10 = cty.NumberIntVal(10) (x = cty.NumberIntVal(2))`,
+		`x * 10 = cty.NumberIntVal(20) (x = cty.NumberIntVal(2))`,
+		`[for x in [1, 2] : x * 10] = cty.TupleVal([]cty.Value{cty.NumberIntVal(10), cty.NumberIntVal(20)})`,
+		`[1] = cty.NumberIntVal(20)`,
+		`a + [for x in [1, 2] : x * 10][1] = cty.NumberIntVal(21)`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong trace\ngot:\n%s\nwant:\n%s", joinLines(got), joinLines(want))
+	}
+}
+
+func joinLines(lines []string) string {
+	var ret string
+	for _, line := range lines {
+		ret += "  " + line + "\n"
+	}
+	return ret
+}
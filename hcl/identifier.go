@@ -0,0 +1,91 @@
+package hcl
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:generate go run identifier_tables_gen.go
+
+// ValidIdentifier tests if the given string is a valid identifier in the
+// native syntax, and could therefore be used as an attribute name, block
+// type name or variable name and be traversed using the attribute traversal
+// syntax.
+//
+// An identifier begins with a letter or underscore, which may be followed by
+// letters, digits, underscores and dashes, where letters and digits are
+// characters with the Unicode ID_Start and ID_Continue properties
+// respectively. This gives the same result as ValidIdentifier in package
+// hclsyntax, and is provided here so that packages that do not otherwise
+// depend on the native syntax, such as schema builders, can agree with it
+// on which names are valid.
+//
+// Block labels and the keys of object constructors may be any string, but
+// they can be written without quotes in the native syntax only if they are
+// valid identifiers.
+func ValidIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !validIdentifierRune(r, i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// SanitizeIdentifier returns a valid identifier based on the given string,
+// which is useful for suggesting an alternative when a name taken from some
+// other source, such as a database column, is not a valid identifier.
+//
+// A valid identifier is returned unchanged. Otherwise, each character that
+// cannot appear in an identifier is replaced by an underscore, and an
+// underscore is added at the start if the first character can appear only
+// after the start of an identifier, as with digits and dashes. The result
+// for an empty string is a single underscore.
+//
+// Different strings, such as "a b" and "a.b", can have the same result, so
+// callers that need unique names must check for collisions themselves.
+func SanitizeIdentifier(s string) string {
+	if ValidIdentifier(s) {
+		return s
+	}
+
+	var buf strings.Builder
+	for _, r := range s {
+		switch {
+		case validIdentifierRune(r, buf.Len() == 0):
+			// Valid as-is
+		case buf.Len() == 0 && validIdentifierRune(r, false):
+			buf.WriteByte('_')
+		default:
+			r = '_'
+		}
+		buf.WriteRune(r)
+	}
+	if buf.Len() == 0 {
+		return "_"
+	}
+	return buf.String()
+}
+
+// validIdentifierRune returns true if the given character can appear in an
+// identifier, either at its start or after it.
+func validIdentifierRune(r rune, start bool) bool {
+	if r < utf8.RuneSelf {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			return true
+		case r >= '0' && r <= '9', r == '-':
+			return !start
+		default:
+			return false
+		}
+	}
+	if start {
+		return unicode.Is(identStartTable, r)
+	}
+	return unicode.Is(identContinueTable, r)
+}
@@ -0,0 +1,104 @@
+// This is a 'go generate'-oriented program for producing the Unicode range
+// tables used by ValidIdentifier. It tests every character against the
+// native syntax scanner, so that the tables agree exactly with the scanner,
+// which is generated from a particular version of the Unicode database
+// that may differ from that of the "unicode" package.
+
+// +build ignore
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+func main() {
+	var start, cont []rune
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if !utf8.ValidRune(r) {
+			continue
+		}
+		if hclsyntax.ValidIdentifier(string(r)) {
+			start = append(start, r)
+		}
+		if hclsyntax.ValidIdentifier("a" + string(r)) {
+			cont = append(cont, r)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprint(&buf, "// Code generated by identifier_tables_gen.go. DO NOT EDIT.\n\n")
+	fmt.Fprint(&buf, "package hcl\n\n")
+	fmt.Fprint(&buf, "import \"unicode\"\n\n")
+	fmt.Fprint(&buf, "// identStartTable contains the characters that can appear at the start of\n")
+	fmt.Fprint(&buf, "// an identifier.\n")
+	writeTable(&buf, "identStartTable", start)
+	fmt.Fprint(&buf, "\n// identContinueTable contains the characters that can appear in an\n")
+	fmt.Fprint(&buf, "// identifier after its first character.\n")
+	writeTable(&buf, "identContinueTable", cont)
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error formatting output: %s\n", err)
+		os.Exit(1)
+	}
+	err = ioutil.WriteFile("identifier_tables.go", src, 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing output: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+type runeRange struct {
+	lo, hi rune
+}
+
+// writeTable writes a declaration of a unicode.RangeTable with the given
+// name that contains exactly the given runes, which must be sorted.
+func writeTable(buf *bytes.Buffer, name string, runes []rune) {
+	var ranges []runeRange
+	for _, r := range runes {
+		if n := len(ranges); n > 0 && ranges[n-1].hi == r-1 {
+			ranges[n-1].hi = r
+			continue
+		}
+		ranges = append(ranges, runeRange{r, r})
+	}
+
+	latinOffset := 0
+	fmt.Fprintf(buf, "var %s = &unicode.RangeTable{\n", name)
+	fmt.Fprint(buf, "R16: []unicode.Range16{\n")
+	for _, rr := range ranges {
+		if rr.hi > 0xFFFF {
+			break
+		}
+		if rr.hi <= unicode.MaxLatin1 {
+			latinOffset++
+		}
+		fmt.Fprintf(buf, "{0x%04x, 0x%04x, 1},\n", rr.lo, rr.hi)
+	}
+	fmt.Fprint(buf, "},\n")
+	fmt.Fprint(buf, "R32: []unicode.Range32{\n")
+	for _, rr := range ranges {
+		switch {
+		case rr.hi <= 0xFFFF:
+			continue
+		case rr.lo <= 0xFFFF:
+			// A range cannot span both tables.
+			fmt.Fprintf(os.Stderr, "range %#x-%#x crosses the 16-bit boundary\n", rr.lo, rr.hi)
+			os.Exit(1)
+		}
+		fmt.Fprintf(buf, "{0x%x, 0x%x, 1},\n", rr.lo, rr.hi)
+	}
+	fmt.Fprint(buf, "},\n")
+	fmt.Fprintf(buf, "LatinOffset: %d,\n", latinOffset)
+	fmt.Fprint(buf, "}\n")
+}
@@ -0,0 +1,66 @@
+package hcl
+
+import (
+	"testing"
+)
+
+func TestValidIdentifier(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  bool
+	}{
+		{"", false},
+		{"hello", true},
+		{"hello.world", false},
+		{"hello world", false},
+		{"foo-bar", true},
+		{"foo-", true},
+		{"_foobar", true},
+		{"-foobar", false},
+		{"1blah", false},
+		{"blah1", true},
+		{"héllo", true}, // combining acute accent
+		{"\u0301e", false},
+		{"Χαίρετε", true},
+		{"今日は", true},
+		{"\x80", false},
+		{"a\x80", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			got := ValidIdentifier(test.Input)
+			if got != test.Want {
+				t.Errorf("wrong result %#v; want %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestSanitizeIdentifier(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  string
+	}{
+		{"", "_"},
+		{"hello", "hello"},
+		{"hello world", "hello_world"},
+		{"1blah", "_1blah"},
+		{"-foo", "_-foo"},
+		{"${var}", "__var_"},
+		{"\u0301e", "_\u0301e"},
+		{"a\x80b", "a_b"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			got := SanitizeIdentifier(test.Input)
+			if got != test.Want {
+				t.Errorf("wrong result %q; want %q", got, test.Want)
+			}
+			if !ValidIdentifier(got) {
+				t.Errorf("result %q is not a valid identifier", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package hcl
+
+import (
+	"time"
+)
+
+// Instrumentation receives events describing the work done while loading
+// configuration, allowing an application to feed them to a tracing or
+// metrics system to find out where the time is spent.
+//
+// The parsing of files is reported by a hclparse.Parser that has been given
+// an Instrumentation with its SetInstrumentation method. The decoding of
+// blocks and the evaluation of the expressions of attributes are reported
+// by the decoders in packages gohcl and hcldec when the EvalContext they are
+// given, or one of its ancestors, has an Instrumentation. Syntax packages
+// that are used directly, rather than through a parser, do not report
+// anything.
+//
+// Events may be reported concurrently if files are parsed or bodies decoded
+// concurrently, so implementations must be safe for concurrent use. They
+// should also be fast, since they are called synchronously.
+//
+// Implementations should embed NopInstrumentation so that they need only
+// implement the methods for the events they are interested in, and so that
+// they will continue to compile if methods for new events are added to this
+// interface in future.
+type Instrumentation interface {
+	// ParseStarted is called before parsing the file with the given name,
+	// whose source code has the given size in bytes.
+	ParseStarted(filename string, size int)
+
+	// ParseFinished is called after parsing the file with the given name,
+	// with the time taken and the resulting diagnostics.
+	ParseFinished(filename string, size int, elapsed time.Duration, diags Diagnostics)
+
+	// BlockDecoded is called after decoding the body of the given block,
+	// with the time taken and the resulting diagnostics. Nested blocks are
+	// decoded as part of the blocks that contain them, and so they are
+	// reported first and their time is included in the time of the block
+	// that contains them.
+	BlockDecoded(block *Block, elapsed time.Duration, diags Diagnostics)
+
+	// ExpressionEvaluated is called after evaluating an expression while
+	// decoding, such as that of an attribute, with the time taken and the
+	// resulting diagnostics. It is not called for each of the
+	// sub-expressions that make up a larger expression; to observe those, use
+	// EvalContext.Trace instead.
+	ExpressionEvaluated(expr Expression, elapsed time.Duration, diags Diagnostics)
+}
+
+// NopInstrumentation is an implementation of Instrumentation that ignores
+// all events, for embedding in other implementations.
+type NopInstrumentation struct{}
+
+var _ Instrumentation = NopInstrumentation{}
+
+// ParseStarted is an implementation of Instrumentation.ParseStarted.
+func (NopInstrumentation) ParseStarted(filename string, size int) {}
+
+// ParseFinished is an implementation of Instrumentation.ParseFinished.
+func (NopInstrumentation) ParseFinished(filename string, size int, elapsed time.Duration, diags Diagnostics) {
+}
+
+// BlockDecoded is an implementation of Instrumentation.BlockDecoded.
+func (NopInstrumentation) BlockDecoded(block *Block, elapsed time.Duration, diags Diagnostics) {}
+
+// ExpressionEvaluated is an implementation of
+// Instrumentation.ExpressionEvaluated.
+func (NopInstrumentation) ExpressionEvaluated(expr Expression, elapsed time.Duration, diags Diagnostics) {
+}
+
+// EffectiveInstrumentation returns the Instrumentation of the receiver or,
+// if it has none, of its nearest ancestor that has one. The result is nil
+// if there is none. The receiver may be nil.
+func (ctx *EvalContext) EffectiveInstrumentation() Instrumentation {
+	for thisCtx := ctx; thisCtx != nil; thisCtx = thisCtx.parent {
+		if thisCtx.Instrumentation != nil {
+			return thisCtx.Instrumentation
+		}
+	}
+	return nil
+}
+
+// StartDecodeBlock reports to the effective instrumentation of the receiver,
+// if any, that decoding of the body of the given block is starting. The
+// result is a function that the caller must call with the resulting
+// diagnostics once decoding is finished. The receiver may be nil.
+//
+// This is for use by decoders, and is not usually called directly by
+// applications:
+//
+//     finish := ctx.StartDecodeBlock(block)
+//     diags := decodeBody(block.Body, ctx)
+//     finish(diags)
+func (ctx *EvalContext) StartDecodeBlock(block *Block) func(Diagnostics) {
+	inst := ctx.EffectiveInstrumentation()
+	if inst == nil {
+		return nopFinish
+	}
+	start := time.Now()
+	return func(diags Diagnostics) {
+		inst.BlockDecoded(block, time.Since(start), diags)
+	}
+}
+
+// StartEvalExpression is like StartDecodeBlock, but reports the evaluation
+// of the given expression.
+func (ctx *EvalContext) StartEvalExpression(expr Expression) func(Diagnostics) {
+	inst := ctx.EffectiveInstrumentation()
+	if inst == nil {
+		return nopFinish
+	}
+	start := time.Now()
+	return func(diags Diagnostics) {
+		inst.ExpressionEvaluated(expr, time.Since(start), diags)
+	}
+}
+
+func nopFinish(Diagnostics) {}
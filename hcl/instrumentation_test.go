@@ -0,0 +1,45 @@
+package hcl
+
+import (
+	"testing"
+	"time"
+)
+
+type testBlockInstrumentation struct {
+	NopInstrumentation
+	blocks []*Block
+}
+
+func (i *testBlockInstrumentation) BlockDecoded(block *Block, elapsed time.Duration, diags Diagnostics) {
+	i.blocks = append(i.blocks, block)
+}
+
+func TestEvalContextInstrumentation(t *testing.T) {
+	block := &Block{Type: "test"}
+
+	// With no instrumentation, nothing is reported.
+	var nilCtx *EvalContext
+	nilCtx.StartDecodeBlock(block)(nil)
+	nilCtx.StartEvalExpression(nil)(nil)
+
+	inst := &testBlockInstrumentation{}
+	parent := &EvalContext{Instrumentation: inst}
+	for _, ctx := range []*EvalContext{parent, parent.NewChild(), parent.NewChild().Clone()} {
+		if ctx.EffectiveInstrumentation() != inst {
+			t.Errorf("wrong instrumentation %#v", ctx.EffectiveInstrumentation())
+		}
+		finish := ctx.StartDecodeBlock(block)
+		finish(nil)
+	}
+	if len(inst.blocks) != 3 || inst.blocks[0] != block {
+		t.Errorf("wrong blocks %#v", inst.blocks)
+	}
+
+	// A child can replace its parent's instrumentation.
+	child := parent.NewChild()
+	child.Instrumentation = NopInstrumentation{}
+	child.StartDecodeBlock(block)(nil)
+	if len(inst.blocks) != 3 {
+		t.Errorf("parent instrumentation was used by child with its own")
+	}
+}
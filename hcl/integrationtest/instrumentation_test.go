@@ -0,0 +1,113 @@
+package integrationtest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl2/gohcl"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type testInstrumentation struct {
+	hcl.NopInstrumentation
+	mu     sync.Mutex
+	events []string
+}
+
+func (i *testInstrumentation) BlockDecoded(block *hcl.Block, elapsed time.Duration, diags hcl.Diagnostics) {
+	i.record(fmt.Sprintf("block %s %v %t", block.Type, block.Labels, diags.HasErrors()))
+}
+
+func (i *testInstrumentation) ExpressionEvaluated(expr hcl.Expression, elapsed time.Duration, diags hcl.Diagnostics) {
+	i.record(fmt.Sprintf("expr %s %t", expr.Range(), diags.HasErrors()))
+}
+
+func (i *testInstrumentation) record(event string) {
+	i.mu.Lock()
+	i.events = append(i.events, event)
+	i.mu.Unlock()
+}
+
+const testInstrumentationSrc = `
+name = "a"
+service "web" {
+  port = 80
+  env {
+    value = nope
+  }
+}
+`
+
+func TestInstrumentationDecode(t *testing.T) {
+	file, diags := hclsyntax.ParseConfig([]byte(testInstrumentationSrc), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse errors: %s", diags.Error())
+	}
+
+	want := []string{
+		"expr test.hcl:2,8-11 false",
+		"expr test.hcl:4,10-12 false",
+		"expr test.hcl:6,13-17 true",
+		"block env [] true",
+		"block service [web] true",
+	}
+
+	t.Run("hcldec", func(t *testing.T) {
+		spec := hcldec.ObjectSpec{
+			"name": &hcldec.AttrSpec{Name: "name", Type: cty.String},
+			"services": &hcldec.BlockMapSpec{
+				TypeName:   "service",
+				LabelNames: []string{"name"},
+				Nested: hcldec.ObjectSpec{
+					"port": &hcldec.AttrSpec{Name: "port", Type: cty.Number},
+					"env": &hcldec.BlockListSpec{
+						TypeName: "env",
+						Nested:   &hcldec.AttrSpec{Name: "value", Type: cty.String},
+					},
+				},
+			},
+		}
+
+		inst := &testInstrumentation{}
+		ctx := (&hcl.EvalContext{Instrumentation: inst}).NewChild()
+		hcldec.Decode(file.Body, spec, ctx)
+
+		// The attributes of an ObjectSpec are decoded in no particular
+		// order.
+		got := append([]string(nil), inst.events...)
+		want := append([]string(nil), want...)
+		sort.Strings(got)
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong events\ngot:  %q\nwant: %q", got, want)
+		}
+	})
+
+	t.Run("gohcl", func(t *testing.T) {
+		type Env struct {
+			Value string `hcl:"value"`
+		}
+		type Service struct {
+			Name string `hcl:"name,label"`
+			Port int    `hcl:"port"`
+			Env  []Env  `hcl:"env,block"`
+		}
+		var config struct {
+			Name     string    `hcl:"name"`
+			Services []Service `hcl:"service,block"`
+		}
+
+		inst := &testInstrumentation{}
+		gohcl.DecodeBody(file.Body, &hcl.EvalContext{Instrumentation: inst}, &config)
+		if !reflect.DeepEqual(inst.events, want) {
+			t.Errorf("wrong events\ngot:  %q\nwant: %q", inst.events, want)
+		}
+	})
+}
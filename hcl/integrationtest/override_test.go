@@ -0,0 +1,215 @@
+package integrationtest
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hcl/json"
+	"github.com/hashicorp/hcl2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestMergeFilesWithOverrides(t *testing.T) {
+	spec := hcldec.ObjectSpec{
+		"name": &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: true},
+		"size": &hcldec.AttrSpec{Name: "size", Type: cty.Number},
+		"services": &hcldec.BlockMapSpec{
+			TypeName:   "service",
+			LabelNames: []string{"name"},
+			Nested: hcldec.ObjectSpec{
+				"port":  &hcldec.AttrSpec{Name: "port", Type: cty.Number},
+				"image": &hcldec.AttrSpec{Name: "image", Type: cty.String},
+				"env": &hcldec.BlockListSpec{
+					TypeName: "env",
+					Nested:   &hcldec.AttrSpec{Name: "value", Type: cty.String},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		base      string
+		overrides []string
+		want      cty.Value
+		diagCount int
+	}{
+		{
+			"no overrides",
+			`name = "a"`,
+			nil,
+			cty.ObjectVal(map[string]cty.Value{
+				"name":     cty.StringVal("a"),
+				"size":     cty.NullVal(cty.Number),
+				"services": cty.MapValEmpty(cty.Object(map[string]cty.Type{"port": cty.Number, "image": cty.String, "env": cty.List(cty.String)})),
+			}),
+			0,
+		},
+		{
+			"attributes",
+			`name = "a"`,
+			[]string{`name = "b"
+size = 2`, `size = 3`},
+			cty.ObjectVal(map[string]cty.Value{
+				"name":     cty.StringVal("b"),
+				"size":     cty.NumberIntVal(3),
+				"services": cty.MapValEmpty(cty.Object(map[string]cty.Type{"port": cty.Number, "image": cty.String, "env": cty.List(cty.String)})),
+			}),
+			0,
+		},
+		{
+			"blocks",
+			`
+name = "a"
+service "web" {
+  port  = 80
+  image = "web:1"
+  env {
+    value = "a"
+  }
+  env {
+    value = "b"
+  }
+}
+service "db" {
+  port = 5432
+}
+`,
+			[]string{`
+service "web" {
+  port = 8080
+  env {
+    value = "c"
+  }
+}
+`},
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("a"),
+				"size": cty.NullVal(cty.Number),
+				"services": cty.MapVal(map[string]cty.Value{
+					"web": cty.ObjectVal(map[string]cty.Value{
+						"port":  cty.NumberIntVal(8080),
+						"image": cty.StringVal("web:1"),
+						"env":   cty.ListVal([]cty.Value{cty.StringVal("c")}),
+					}),
+					"db": cty.ObjectVal(map[string]cty.Value{
+						"port":  cty.NumberIntVal(5432),
+						"image": cty.NullVal(cty.String),
+						"env":   cty.ListValEmpty(cty.String),
+					}),
+				}),
+			}),
+			0,
+		},
+		{
+			"JSON override",
+			`
+name = "a"
+service "web" {
+  port  = 80
+  image = "web:1"
+}
+`,
+			[]string{`{"service": {"web": {"image": "web:2"}}}`},
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("a"),
+				"size": cty.NullVal(cty.Number),
+				"services": cty.MapVal(map[string]cty.Value{
+					"web": cty.ObjectVal(map[string]cty.Value{
+						"port":  cty.NumberIntVal(80),
+						"image": cty.StringVal("web:2"),
+						"env":   cty.ListValEmpty(cty.String),
+					}),
+				}),
+			}),
+			0,
+		},
+		{
+			"missing base block",
+			`name = "a"`,
+			[]string{`
+service "web" {
+  port = 8080
+}
+`},
+			cty.ObjectVal(map[string]cty.Value{
+				"name":     cty.StringVal("a"),
+				"size":     cty.NullVal(cty.Number),
+				"services": cty.MapValEmpty(cty.Object(map[string]cty.Type{"port": cty.Number, "image": cty.String, "env": cty.List(cty.String)})),
+			}),
+			1, // Missing base block for override
+		},
+		{
+			"required attribute in override",
+			`size = 1`,
+			[]string{`name = "b"`},
+			cty.ObjectVal(map[string]cty.Value{
+				"name":     cty.StringVal("b"),
+				"size":     cty.NumberIntVal(1),
+				"services": cty.MapValEmpty(cty.Object(map[string]cty.Type{"port": cty.Number, "image": cty.String, "env": cty.List(cty.String)})),
+			}),
+			0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			base, diags := hclsyntax.ParseConfig([]byte(test.base), "base.hcl", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+			var overrides []*hcl.File
+			for _, src := range test.overrides {
+				var file *hcl.File
+				if src[0] == '{' {
+					file, diags = json.Parse([]byte(src), "override.json")
+				} else {
+					file, diags = hclsyntax.ParseConfig([]byte(src), "override.hcl", hcl.Pos{Line: 1, Column: 1})
+				}
+				if diags.HasErrors() {
+					t.Fatalf("unexpected parse errors: %s", diags.Error())
+				}
+				overrides = append(overrides, file)
+			}
+
+			body := hcl.MergeFilesWithOverrides([]*hcl.File{base}, overrides)
+			got, diags := hcldec.Decode(body, spec, nil)
+			if len(diags) != test.diagCount {
+				t.Errorf("wrong number of diagnostics %d; want %d\n%s", len(diags), test.diagCount, diags.Error())
+			}
+			for _, diag := range diags {
+				if test.diagCount != 0 && diag.Code != hcl.DiagMissingOverrideBase {
+					t.Errorf("wrong diagnostic code %q; want %q", diag.Code, hcl.DiagMissingOverrideBase)
+				}
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOverrideBodyAttributesInOrder(t *testing.T) {
+	base, _ := hclsyntax.ParseConfig([]byte("a = 1\nb = 2\nc = 3\n"), "base.hcl", hcl.Pos{Line: 1, Column: 1})
+	override, _ := hclsyntax.ParseConfig([]byte("d = 4\nb = 5\n"), "override.hcl", hcl.Pos{Line: 1, Column: 1})
+
+	attrs, diags := hcl.AttributesInOrder(hcl.OverrideBody(base.Body, override.Body))
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	var got []string
+	for _, attr := range attrs {
+		got = append(got, attr.Name+"@"+attr.Range.Filename)
+	}
+	want := []string{"a@base.hcl", "b@override.hcl", "c@base.hcl", "d@override.hcl"}
+	if len(got) != len(want) {
+		t.Fatalf("wrong result %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("wrong result %v; want %v", got, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package json
+
+import (
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// Comment is a property named "//" with a string value, which is used as a
+// comment by convention since JSON has no comment syntax of its own. Such
+// properties are ignored when an object is interpreted as a body.
+type Comment struct {
+	// Text is the value of the property.
+	Text string
+
+	// Range is the range of the whole property, from the start of its name
+	// to the end of its value.
+	Range hcl.Range
+
+	// Next is the range of the whole of the property that follows the
+	// comment in the same object, or nil if the comment is the last property
+	// of its object. A comment is often used to annotate the property that
+	// follows it.
+	Next *hcl.Range
+}
+
+// Comments returns the comments in the given file, in source order, as
+// described for Comment. This includes the comments in the objects of
+// attribute values, as well as in the objects that represent bodies.
+//
+// The file must have been produced by this package's parser. The result is
+// nil for any other file.
+func Comments(file *hcl.File) []Comment {
+	b, ok := file.Body.(*body)
+	if !ok {
+		return nil
+	}
+	var ret []Comment
+	collectComments(b.val, &ret)
+	return ret
+}
+
+func collectComments(n node, comments *[]Comment) {
+	switch tn := n.(type) {
+	case *objectVal:
+		for i, attr := range tn.Attrs {
+			if str, isStr := attr.Value.(*stringVal); isStr && attr.Name == "//" {
+				comment := Comment{
+					Text:  str.Value,
+					Range: propertyRange(attr),
+				}
+				if i+1 < len(tn.Attrs) {
+					next := propertyRange(tn.Attrs[i+1])
+					comment.Next = &next
+				}
+				*comments = append(*comments, comment)
+			}
+			collectComments(attr.Value, comments)
+		}
+	case *arrayVal:
+		for _, v := range tn.Values {
+			collectComments(v, comments)
+		}
+	}
+}
+
+func propertyRange(attr *objectAttr) hcl.Range {
+	return hcl.RangeBetween(attr.NameRange, attr.Value.Range())
+}
@@ -0,0 +1,46 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+func TestComments(t *testing.T) {
+	src := `{
+  "//": "first",
+  "a": 1,
+  "b": {
+    "c": [{"//": "nested"}],
+    "//": "last"
+  },
+  "//": ["not a comment"]
+}`
+	file, diags := Parse([]byte(src), "test.json")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	comments := Comments(file)
+	var got []string
+	for _, c := range comments {
+		got = append(got, c.Text)
+	}
+	if len(got) != 3 || got[0] != "first" || got[1] != "nested" || got[2] != "last" {
+		t.Fatalf("wrong comments %q", got)
+	}
+
+	if rng := comments[0].Range; rng.Start.Line != 2 || rng.Start.Column != 3 || rng.End.Column != 16 {
+		t.Errorf("wrong range for first comment: %s", rng)
+	}
+	if next := comments[0].Next; next == nil || next.Start.Line != 3 || next.End.Line != 3 {
+		t.Errorf("wrong next range for first comment: %v", next)
+	}
+	if comments[1].Next != nil || comments[2].Next != nil {
+		t.Errorf("comments at the end of their objects have next ranges")
+	}
+
+	if got := Comments(&hcl.File{}); got != nil {
+		t.Errorf("comments for a non-JSON file: %#v", got)
+	}
+}
@@ -1,6 +1,9 @@
 package json
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/agext/levenshtein"
 )
 
@@ -31,3 +34,33 @@ func nameSuggestion(given string, suggestions []string) string {
 	}
 	return ""
 }
+
+// maxCandidateNames is the largest number of supported names that
+// candidateNames will list in a diagnostic message.
+const maxCandidateNames = 8
+
+// candidateNames returns a sentence listing the given supported names, for
+// appending to a diagnostic message about an unsupported name when there is
+// no close suggestion. It returns the empty string if there are no names or
+// too many names for the list to be helpful.
+//
+// The noun and plural arguments name the kind of item being listed, such as
+// "argument" and "arguments".
+func candidateNames(noun, plural string, names []string) string {
+	switch {
+	case len(names) == 0 || len(names) > maxCandidateNames:
+		return ""
+	case len(names) == 1:
+		return fmt.Sprintf(" The only supported %s is %q.", noun, names[0])
+	}
+
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	last := len(quoted) - 1
+	return fmt.Sprintf(
+		" The supported %s are %s and %s.",
+		plural, strings.Join(quoted[:last], ", "), quoted[last],
+	)
+}
@@ -23,6 +23,7 @@ func parseFileContent(buf []byte, filename string) (node, hcl.Diagnostics) {
 		diags = diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Extraneous data after value",
+			Code:     hcl.DiagExtraneousJSONData,
 			Detail:   "Extra characters appear after the JSON value.",
 			Subject:  p.Peek().Range.Ptr(),
 		})
@@ -56,6 +57,7 @@ func parseValue(p *peeker) (node, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Missing JSON value",
+				Code:     hcl.DiagMissingJSONValue,
 				Detail:   "A JSON value must start with a brace, a bracket, a number, a string, or a keyword.",
 				Subject:  &tok.Range,
 			},
@@ -65,6 +67,7 @@ func parseValue(p *peeker) (node, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Missing array element value",
+				Code:     hcl.DiagMissingJSONArrayElement,
 				Detail:   "A JSON value must start with a brace, a bracket, a number, a string, or a keyword.",
 				Subject:  &tok.Range,
 			},
@@ -74,6 +77,7 @@ func parseValue(p *peeker) (node, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Missing value",
+				Code:     hcl.DiagUnexpectedEndOfJSON,
 				Detail:   "The JSON data ends prematurely.",
 				Subject:  &tok.Range,
 			},
@@ -83,6 +87,7 @@ func parseValue(p *peeker) (node, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid start of value",
+				Code:     hcl.DiagInvalidStartOfJSONValue,
 				Detail:   "A JSON value must start with a brace, a bracket, a number, a string, or a keyword.",
 				Subject:  &tok.Range,
 			},
@@ -145,6 +150,7 @@ Token:
 			return nil, diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid object property name",
+				Code:     hcl.DiagInvalidJSONPropertyName,
 				Detail:   "A JSON object property name must be a string",
 				Subject:  keyNode.StartRange().Ptr(),
 			})
@@ -162,6 +168,7 @@ Token:
 				return nil, diags.Append(&hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Missing object value",
+					Code:     hcl.DiagMissingJSONObjectValue,
 					Detail:   "A JSON object attribute must have a value, introduced by a colon.",
 					Subject:  &colon.Range,
 				})
@@ -172,6 +179,7 @@ Token:
 				return nil, diags.Append(&hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Missing property value colon",
+					Code:     hcl.DiagMissingJSONPropertyColon,
 					Detail:   "JSON uses a colon as its name/value delimiter, not an equals sign.",
 					Subject:  &colon.Range,
 				})
@@ -180,6 +188,7 @@ Token:
 			return nil, diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Missing property value colon",
+				Code:     hcl.DiagMissingJSONPropertyColon,
 				Detail:   "A colon must appear between an object property's name and its value.",
 				Subject:  &colon.Range,
 			})
@@ -205,6 +214,7 @@ Token:
 				return nil, diags.Append(&hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Trailing comma in object",
+					Code:     hcl.DiagTrailingCommaInJSONObject,
 					Detail:   "JSON does not permit a trailing comma after the final property in an object.",
 					Subject:  &comma.Range,
 				})
@@ -214,6 +224,7 @@ Token:
 			return nil, diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Unclosed object",
+				Code:     hcl.DiagUnclosedJSONContainer,
 				Detail:   "No closing brace was found for this JSON object.",
 				Subject:  &open.Range,
 			})
@@ -224,6 +235,7 @@ Token:
 			return nil, diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Mismatched braces",
+				Code:     hcl.DiagMismatchedJSONBraces,
 				Detail:   "A JSON object must be closed with a brace, not a bracket.",
 				Subject:  p.Peek().Range.Ptr(),
 			})
@@ -234,6 +246,7 @@ Token:
 			return nil, diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Missing attribute seperator comma",
+				Code:     hcl.DiagMissingJSONPropertySeparator,
 				Detail:   "A comma must appear between each property definition in an object.",
 				Subject:  p.Peek().Range.Ptr(),
 			})
@@ -301,6 +314,7 @@ Token:
 				return nil, diags.Append(&hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Trailing comma in array",
+					Code:     hcl.DiagTrailingCommaInJSONArray,
 					Detail:   "JSON does not permit a trailing comma after the final value in an array.",
 					Subject:  &comma.Range,
 				})
@@ -311,6 +325,7 @@ Token:
 			return nil, diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid array value",
+				Code:     hcl.DiagInvalidJSONArrayValue,
 				Detail:   "A colon is not used to introduce values in a JSON array.",
 				Subject:  p.Peek().Range.Ptr(),
 			})
@@ -319,6 +334,7 @@ Token:
 			return nil, diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Unclosed object",
+				Code:     hcl.DiagUnclosedJSONContainer,
 				Detail:   "No closing bracket was found for this JSON array.",
 				Subject:  &open.Range,
 			})
@@ -327,6 +343,7 @@ Token:
 			return nil, diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Mismatched brackets",
+				Code:     hcl.DiagMismatchedJSONBrackets,
 				Detail:   "A JSON array must be closed with a bracket, not a brace.",
 				Subject:  p.Peek().Range.Ptr(),
 			})
@@ -337,6 +354,7 @@ Token:
 			return nil, diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Missing attribute seperator comma",
+				Code:     hcl.DiagMissingJSONPropertySeparator,
 				Detail:   "A comma must appear between each value in an array.",
 				Subject:  p.Peek().Range.Ptr(),
 			})
@@ -364,6 +382,7 @@ func parseNumber(p *peeker) (node, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid JSON number",
+				Code:     hcl.DiagInvalidJSONNumber,
 				Detail:   fmt.Sprintf("There is a syntax error in the given JSON number."),
 				Subject:  &tok.Range,
 			},
@@ -383,6 +402,7 @@ func parseNumber(p *peeker) (node, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid JSON number",
+				Code:     hcl.DiagInvalidJSONNumber,
 				Detail:   fmt.Sprintf("There is a syntax error in the given JSON number."),
 				Subject:  &tok.Range,
 			},
@@ -437,6 +457,7 @@ func parseString(p *peeker) (node, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid JSON string",
+				Code:     hcl.DiagInvalidJSONString,
 				Detail:   fmt.Sprintf("There is a syntax error in the given JSON string."),
 				Subject:  &errRange,
 				Context:  contextRange,
@@ -474,6 +495,7 @@ func parseKeyword(p *peeker) (node, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid JSON keyword",
+				Code:     hcl.DiagInvalidJSONKeyword,
 				Detail:   fmt.Sprintf("The JavaScript identifier %q cannot be used in JSON.", s),
 				Subject:  &tok.Range,
 			},
@@ -488,6 +510,7 @@ func parseKeyword(p *peeker) (node, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid JSON keyword",
+				Code:     hcl.DiagInvalidJSONKeyword,
 				Detail:   fmt.Sprintf("%q is not a valid JSON keyword.%s", s, dym),
 				Subject:  &tok.Range,
 			},
@@ -18,6 +18,26 @@ import (
 // from its HasErrors method. If HasErrors returns true, the file represents
 // the subset of data that was able to be parsed, which may be none.
 func Parse(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
+	return ParseWithOptions(src, filename, ParseOptions{})
+}
+
+// ParseOptions customizes the interpretation of a file by ParseWithOptions.
+type ParseOptions struct {
+	// LiteralAttributes names attributes whose string values are taken
+	// literally, rather than as templates, even when they are evaluated with
+	// a non-nil EvalContext. This applies to the attributes of these names
+	// wherever they appear in the file, including in nested blocks, and to
+	// all of the strings nested within their array and object values.
+	//
+	// This allows machine-generated JSON to include values such as shell
+	// scripts, in which sequences like ${ are common, without the need to
+	// escape them as $${.
+	LiteralAttributes []string
+}
+
+// ParseWithOptions is like Parse, but customizes the interpretation of the
+// file with the given options.
+func ParseWithOptions(src []byte, filename string, opts ParseOptions) (*hcl.File, hcl.Diagnostics) {
 	rootNode, diags := parseFileContent(src, filename)
 
 	switch rootNode.(type) {
@@ -27,6 +47,7 @@ func Parse(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
 		diags = diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Root value must be object",
+			Code:     hcl.DiagJSONRootNotObject,
 			Detail:   "The root value in a JSON-based configuration must be either a JSON object or a JSON array of objects.",
 			Subject:  rootNode.StartRange().Ptr(),
 		})
@@ -52,9 +73,18 @@ func Parse(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
 		}
 	}
 
+	var literalAttrs map[string]struct{}
+	if len(opts.LiteralAttributes) > 0 {
+		literalAttrs = make(map[string]struct{}, len(opts.LiteralAttributes))
+		for _, name := range opts.LiteralAttributes {
+			literalAttrs[name] = struct{}{}
+		}
+	}
+
 	file := &hcl.File{
 		Body: &body{
-			val: rootNode,
+			val:          rootNode,
+			literalAttrs: literalAttrs,
 		},
 		Bytes: src,
 		Nav:   navigation{rootNode},
@@ -67,12 +97,19 @@ func Parse(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
 //
 // If the file cannot be read, an error diagnostic with nil context is returned.
 func ParseFile(filename string) (*hcl.File, hcl.Diagnostics) {
+	return ParseFileWithOptions(filename, ParseOptions{})
+}
+
+// ParseFileWithOptions is like ParseFile, but customizes the interpretation
+// of the file with the given options as for ParseWithOptions.
+func ParseFileWithOptions(filename string, opts ParseOptions) (*hcl.File, hcl.Diagnostics) {
 	f, err := os.Open(filename)
 	if err != nil {
 		return nil, hcl.Diagnostics{
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Failed to open file",
+				Code:     hcl.DiagFileOpenFailed,
 				Detail:   fmt.Sprintf("The file %q could not be opened.", filename),
 			},
 		}
@@ -85,10 +122,11 @@ func ParseFile(filename string) (*hcl.File, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Failed to read file",
+				Code:     hcl.DiagFileReadFailed,
 				Detail:   fmt.Sprintf("The file %q was opened, but an error occured while reading it.", filename),
 			},
 		}
 	}
 
-	return Parse(src, filename)
+	return ParseWithOptions(src, filename, opts)
 }
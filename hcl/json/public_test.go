@@ -95,3 +95,66 @@ func TestParseTemplateUnwrap(t *testing.T) {
 		t.Errorf("wrong result %#v; want %#v", val, cty.True)
 	}
 }
+
+func TestParseWithOptionsLiteralAttributes(t *testing.T) {
+	src := `{
+  "script": "echo ${HOME}",
+  "greeting": "hello ${name}",
+  "task": {
+    "build": {
+      "script": ["make ${TARGET}", {"${KEY}": "${VALUE}"}]
+    }
+  }
+}`
+	file, diags := ParseWithOptions([]byte(src), "", ParseOptions{
+		LiteralAttributes: []string{"script"},
+	})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics on parse: %s", diags.Error())
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"name": cty.StringVal("world"),
+		},
+	}
+	content, diags := file.Body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "script"}, {Name: "greeting"}},
+		Blocks:     []hcl.BlockHeaderSchema{{Type: "task", LabelNames: []string{"name"}}},
+	})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics on decode: %s", diags.Error())
+	}
+	attrs, diags := content.Blocks[0].Body.JustAttributes()
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics on nested decode: %s", diags.Error())
+	}
+
+	tests := []struct {
+		expr     hcl.Expression
+		want     cty.Value
+		wantVars int
+	}{
+		{content.Attributes["script"].Expr, cty.StringVal("echo ${HOME}"), 0},
+		{content.Attributes["greeting"].Expr, cty.StringVal("hello world"), 1},
+		{attrs["script"].Expr, cty.TupleVal([]cty.Value{
+			cty.StringVal("make ${TARGET}"),
+			cty.ObjectVal(map[string]cty.Value{
+				"${KEY}": cty.StringVal("${VALUE}"),
+			}),
+		}), 0},
+	}
+	for _, test := range tests {
+		got, diags := test.expr.Value(ctx)
+		if len(diags) != 0 {
+			t.Errorf("unexpected diagnostics for %s: %s", test.expr.Range(), diags.Error())
+			continue
+		}
+		if !got.RawEquals(test.want) {
+			t.Errorf("wrong result for %s\ngot:  %#v\nwant: %#v", test.expr.Range(), got, test.want)
+		}
+		if got := len(test.expr.Variables()); got != test.wantVars {
+			t.Errorf("wrong number of variables for %s: got %d, want %d", test.expr.Range(), got, test.wantVars)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package json
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/hashicorp/hcl2/hcl"
 	"github.com/hashicorp/hcl2/hcl/hclsyntax"
@@ -18,12 +19,23 @@ type body struct {
 	// be treated as non-existing. This is used when Body.PartialContent is
 	// called, to produce the "remaining content" Body.
 	hiddenAttrs map[string]struct{}
+
+	// If non-nil, the keys of this map are the names of attributes whose
+	// string values are literal rather than templates, as set by
+	// ParseOptions.LiteralAttributes. This applies also to the bodies of
+	// nested blocks.
+	literalAttrs map[string]struct{}
 }
 
 // expression is the implementation of "Expression" used for files processed
 // with the JSON parser.
 type expression struct {
 	src node
+
+	// literal is true if strings within this expression are to be taken
+	// literally rather than as templates, even when evaluated with a
+	// non-nil EvalContext.
+	literal bool
 }
 
 func (b *body) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
@@ -59,11 +71,14 @@ func (b *body) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostic
 			suggestion := nameSuggestion(k, nameSuggestions)
 			if suggestion != "" {
 				suggestion = fmt.Sprintf(" Did you mean %q?", suggestion)
+			} else {
+				suggestion = candidateNames("argument or block type", "argument and block types", nameSuggestions)
 			}
 
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Extraneous JSON object property",
+				Code:     hcl.DiagExtraneousJSONProperty,
 				Detail:   fmt.Sprintf("No argument or block type is named %q.%s", k, suggestion),
 				Subject:  &attr.NameRange,
 				Context:  attr.Range().Ptr(),
@@ -115,6 +130,7 @@ func (b *body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Duplicate argument",
+					Code:     hcl.DiagDuplicateArgument,
 					Detail:   fmt.Sprintf("The argument %q was already set at %s.", attrName, existing.Range),
 					Subject:  &jsonAttr.NameRange,
 					Context:  jsonAttr.Range().Ptr(),
@@ -124,7 +140,7 @@ func (b *body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 
 			content.Attributes[attrS.Name] = &hcl.Attribute{
 				Name:      attrS.Name,
-				Expr:      &expression{src: jsonAttr.Value},
+				Expr:      b.attrExpr(jsonAttr),
 				Range:     hcl.RangeBetween(jsonAttr.NameRange, jsonAttr.Value.Range()),
 				NameRange: jsonAttr.NameRange,
 			}
@@ -150,20 +166,55 @@ func (b *body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Bod
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Missing required argument",
+				Code:     hcl.DiagMissingRequiredArgument,
 				Detail:   fmt.Sprintf("The argument %q is required, but no definition was found.", attrS.Name),
 				Subject:  b.MissingItemRange().Ptr(),
 			})
 		}
 	}
 
+	diags = append(diags, deprecationDiagnostics(schema, content)...)
+
 	unusedBody := &body{
-		val:         b.val,
-		hiddenAttrs: usedNames,
+		val:          b.val,
+		hiddenAttrs:  usedNames,
+		literalAttrs: b.literalAttrs,
 	}
 
 	return content, unusedBody, diags
 }
 
+// blockIndex returns the value of hcl.Block.Index for the block whose body
+// is the given value. Since we can't tell which properties of a body
+// represent blocks without a schema, we use the position of the value in the
+// file, which increases in source order.
+func blockIndex(v node) int {
+	return v.StartRange().Start.Byte
+}
+
+// attrExpr returns the expression for the value of the given property of
+// the body, when it is interpreted as an attribute.
+func (b *body) attrExpr(jsonAttr *objectAttr) *expression {
+	_, literal := b.literalAttrs[jsonAttr.Name]
+	return &expression{src: jsonAttr.Value, literal: literal}
+}
+
+// deprecationDiagnostics wraps hcl.DeprecationDiagnostics to adapt the
+// suggested replacements for JSON, where the name ranges of attributes and
+// blocks include the quotes of their property names.
+func deprecationDiagnostics(schema *hcl.BodySchema, content *hcl.BodyContent) hcl.Diagnostics {
+	diags := hcl.DeprecationDiagnostics(schema, content)
+	for _, diag := range diags {
+		for _, suggestion := range diag.Suggestions {
+			for i := range suggestion.Edits {
+				edit := &suggestion.Edits[i]
+				edit.Replacement = strconv.Quote(edit.Replacement)
+			}
+		}
+	}
+	return diags
+}
+
 // JustAttributes for JSON bodies interprets all properties of the wrapped
 // JSON object as attributes and returns them.
 func (b *body) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
@@ -175,6 +226,7 @@ func (b *body) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Incorrect JSON value type",
+			Code:     hcl.DiagIncorrectJSONValueType,
 			Detail:   "A JSON object is required here, setting the arguments for this block.",
 			Subject:  b.val.StartRange().Ptr(),
 		})
@@ -197,6 +249,7 @@ func (b *body) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Duplicate attribute definition",
+				Code:     hcl.DiagDuplicateArgument,
 				Detail:   fmt.Sprintf("The argument %q was already set at %s.", name, existing.Range),
 				Subject:  &jsonAttr.NameRange,
 			})
@@ -205,7 +258,7 @@ func (b *body) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
 
 		attrs[name] = &hcl.Attribute{
 			Name:      name,
-			Expr:      &expression{src: jsonAttr.Value},
+			Expr:      b.attrExpr(jsonAttr),
 			Range:     hcl.RangeBetween(jsonAttr.NameRange, jsonAttr.Value.Range()),
 			NameRange: jsonAttr.NameRange,
 		}
@@ -239,6 +292,7 @@ func (b *body) unpackBlock(v node, typeName string, typeRange *hcl.Range, labels
 			diags = diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Missing block label",
+				Code:     hcl.DiagMissingBlockLabel,
 				Detail:   fmt.Sprintf("At least one object property is required, whose name represents the %s block's %s.", typeName, labelName),
 				Subject:  v.StartRange().Ptr(),
 			})
@@ -275,12 +329,14 @@ func (b *body) unpackBlock(v node, typeName string, typeRange *hcl.Range, labels
 			Type:   typeName,
 			Labels: labels,
 			Body: &body{
-				val: tv,
+				val:          tv,
+				literalAttrs: b.literalAttrs,
 			},
 
 			DefRange:    tv.OpenRange,
 			TypeRange:   *typeRange,
 			LabelRanges: labelR,
+			Index:       blockIndex(tv),
 		})
 	case *arrayVal:
 		// Multiple instances of the block
@@ -289,18 +345,21 @@ func (b *body) unpackBlock(v node, typeName string, typeRange *hcl.Range, labels
 				Type:   typeName,
 				Labels: labels,
 				Body: &body{
-					val: av, // might be mistyped; we'll find out when content is requested for this body
+					val:          av, // might be mistyped; we'll find out when content is requested for this body
+					literalAttrs: b.literalAttrs,
 				},
 
 				DefRange:    tv.OpenRange,
 				TypeRange:   *typeRange,
 				LabelRanges: labelR,
+				Index:       blockIndex(av),
 			})
 		}
 	default:
 		diags = diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Incorrect JSON value type",
+			Code:     hcl.DiagIncorrectJSONValueType,
 			Detail:   fmt.Sprintf("Either a JSON object or a JSON array is required, representing the contents of one or more %q blocks.", typeName),
 			Subject:  v.StartRange().Ptr(),
 		})
@@ -343,6 +402,7 @@ func (b *body) collectDeepAttrs(v node, labelName *string) ([]*objectAttr, hcl.D
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Incorrect JSON value type",
+						Code:     hcl.DiagIncorrectJSONValueType,
 						Detail:   fmt.Sprintf("A JSON object is required here, to specify %s labels for this block.", *labelName),
 						Subject:  ev.StartRange().Ptr(),
 					})
@@ -350,6 +410,7 @@ func (b *body) collectDeepAttrs(v node, labelName *string) ([]*objectAttr, hcl.D
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Incorrect JSON value type",
+						Code:     hcl.DiagIncorrectJSONValueType,
 						Detail:   "A JSON object is required here, to define arguments and child blocks.",
 						Subject:  ev.StartRange().Ptr(),
 					})
@@ -362,6 +423,7 @@ func (b *body) collectDeepAttrs(v node, labelName *string) ([]*objectAttr, hcl.D
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Incorrect JSON value type",
+				Code:     hcl.DiagIncorrectJSONValueType,
 				Detail:   fmt.Sprintf("Either a JSON object or JSON array of objects is required here, to specify %s labels for this block.", *labelName),
 				Subject:  v.StartRange().Ptr(),
 			})
@@ -369,6 +431,7 @@ func (b *body) collectDeepAttrs(v node, labelName *string) ([]*objectAttr, hcl.D
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Incorrect JSON value type",
+				Code:     hcl.DiagIncorrectJSONValueType,
 				Detail:   "Either a JSON object or JSON array of objects is required here, to define arguments and child blocks.",
 				Subject:  v.StartRange().Ptr(),
 			})
@@ -378,10 +441,13 @@ func (b *body) collectDeepAttrs(v node, labelName *string) ([]*objectAttr, hcl.D
 	return attrs, diags
 }
 
-func (e *expression) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+func (e *expression) Value(ctx *hcl.EvalContext) (retVal cty.Value, retDiags hcl.Diagnostics) {
+	defer func() {
+		ctx.TraceEval(e, retVal, retDiags)
+	}()
 	switch v := e.src.(type) {
 	case *stringVal:
-		if ctx != nil {
+		if ctx != nil && !e.literal {
 			// Parse string contents as a HCL native language expression.
 			// We only do this if we have a context, so passing a nil context
 			// is how the caller specifies that interpolations are not allowed
@@ -419,7 +485,7 @@ func (e *expression) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 		var diags hcl.Diagnostics
 		vals := []cty.Value{}
 		for _, jsonVal := range v.Values {
-			val, valDiags := (&expression{src: jsonVal}).Value(ctx)
+			val, valDiags := (&expression{src: jsonVal, literal: e.literal}).Value(ctx)
 			vals = append(vals, val)
 			diags = append(diags, valDiags...)
 		}
@@ -438,8 +504,8 @@ func (e *expression) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 			name, nameDiags := (&expression{src: &stringVal{
 				Value:    jsonAttr.Name,
 				SrcRange: jsonAttr.NameRange,
-			}}).Value(ctx)
-			valExpr := &expression{src: jsonAttr.Value}
+			}, literal: e.literal}).Value(ctx)
+			valExpr := &expression{src: jsonAttr.Value, literal: e.literal}
 			val, valDiags := valExpr.Value(ctx)
 			diags = append(diags, nameDiags...)
 			diags = append(diags, valDiags...)
@@ -450,6 +516,7 @@ func (e *expression) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity:    hcl.DiagError,
 					Summary:     "Invalid object key expression",
+					Code:        hcl.DiagInvalidJSONKeyExpression,
 					Detail:      fmt.Sprintf("Cannot use this expression as an object key: %s.", err),
 					Subject:     &jsonAttr.NameRange,
 					Expression:  valExpr,
@@ -461,6 +528,7 @@ func (e *expression) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity:    hcl.DiagError,
 					Summary:     "Invalid object key expression",
+					Code:        hcl.DiagInvalidJSONKeyExpression,
 					Detail:      "Cannot use null value as an object key.",
 					Subject:     &jsonAttr.NameRange,
 					Expression:  valExpr,
@@ -485,6 +553,7 @@ func (e *expression) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity:    hcl.DiagError,
 					Summary:     "Duplicate object attribute",
+					Code:        hcl.DiagDuplicateJSONProperty,
 					Detail:      fmt.Sprintf("An attribute named %q was already defined at %s.", nameStr, attrRanges[nameStr]),
 					Subject:     &jsonAttr.NameRange,
 					Expression:  e,
@@ -515,6 +584,9 @@ func (e *expression) Variables() []hcl.Traversal {
 
 	switch v := e.src.(type) {
 	case *stringVal:
+		if e.literal {
+			return vars
+		}
 		templateSrc := v.Value
 		expr, diags := hclsyntax.ParseTemplate(
 			[]byte(templateSrc),
@@ -538,7 +610,7 @@ func (e *expression) Variables() []hcl.Traversal {
 
 	case *arrayVal:
 		for _, jsonVal := range v.Values {
-			vars = append(vars, (&expression{src: jsonVal}).Variables()...)
+			vars = append(vars, (&expression{src: jsonVal, literal: e.literal}).Variables()...)
 		}
 	case *objectVal:
 		for _, jsonAttr := range v.Attrs {
@@ -546,8 +618,8 @@ func (e *expression) Variables() []hcl.Traversal {
 				Value:    jsonAttr.Name,
 				SrcRange: jsonAttr.NameRange,
 			}
-			vars = append(vars, (&expression{src: keyExpr}).Variables()...)
-			vars = append(vars, (&expression{src: jsonAttr.Value}).Variables()...)
+			vars = append(vars, (&expression{src: keyExpr, literal: e.literal}).Variables()...)
+			vars = append(vars, (&expression{src: jsonAttr.Value, literal: e.literal}).Variables()...)
 		}
 	}
 
@@ -608,7 +680,7 @@ func (e *expression) ExprList() []hcl.Expression {
 	case *arrayVal:
 		ret := make([]hcl.Expression, len(v.Values))
 		for i, node := range v.Values {
-			ret[i] = &expression{src: node}
+			ret[i] = &expression{src: node, literal: e.literal}
 		}
 		return ret
 	default:
@@ -626,8 +698,8 @@ func (e *expression) ExprMap() []hcl.KeyValuePair {
 				Key: &expression{src: &stringVal{
 					Value:    jsonAttr.Name,
 					SrcRange: jsonAttr.NameRange,
-				}},
-				Value: &expression{src: jsonAttr.Value},
+				}, literal: e.literal},
+				Value: &expression{src: jsonAttr.Value, literal: e.literal},
 			}
 		}
 		return ret
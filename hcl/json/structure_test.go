@@ -3,6 +3,7 @@ package json
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
@@ -405,6 +406,7 @@ func TestBodyPartialContent(t *testing.T) {
 							},
 						},
 
+						Index: 12,
 						DefRange: hcl.Range{
 							Filename: "test.json",
 							Start: hcl.Pos{
@@ -502,6 +504,7 @@ func TestBodyPartialContent(t *testing.T) {
 							},
 						},
 
+						Index: 13,
 						DefRange: hcl.Range{
 							Filename: "test.json",
 							Start: hcl.Pos{
@@ -578,6 +581,7 @@ func TestBodyPartialContent(t *testing.T) {
 							},
 						},
 
+						Index: 16,
 						DefRange: hcl.Range{
 							Filename: "test.json",
 							Start: hcl.Pos{
@@ -676,6 +680,7 @@ func TestBodyPartialContent(t *testing.T) {
 							},
 						},
 
+						Index: 35,
 						DefRange: hcl.Range{
 							Filename: "test.json",
 							Start: hcl.Pos{
@@ -801,6 +806,7 @@ func TestBodyPartialContent(t *testing.T) {
 							},
 						},
 
+						Index: 36,
 						DefRange: hcl.Range{
 							Filename: "test.json",
 							Start: hcl.Pos{
@@ -904,6 +910,7 @@ func TestBodyPartialContent(t *testing.T) {
 							},
 						},
 
+						Index: 48,
 						DefRange: hcl.Range{
 							Filename: "test.json",
 							Start: hcl.Pos{
@@ -1136,6 +1143,45 @@ func TestBodyContent(t *testing.T) {
 	}
 }
 
+func TestBodyContentDeprecated(t *testing.T) {
+	src := `{"ami": "a", "tag": [{}, {}]}`
+	file, diags := Parse([]byte(src), "test.json")
+	if len(diags) != 0 {
+		t.Fatalf("Parse produced diagnostics: %s", diags)
+	}
+
+	_, diags = file.Body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "ami", Deprecated: "Use image instead.", ReplacedBy: "image"},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "tag", Deprecated: "Use tags instead.", ReplacedBy: "tags"},
+		},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+
+	// The two blocks share a property, which is reported only once.
+	if len(diags) != 2 {
+		t.Fatalf("wrong number of diagnostics %d; want 2\n%s", len(diags), diags.Error())
+	}
+	for i, code := range []string{hcl.DiagDeprecatedArgument, hcl.DiagDeprecatedBlockType} {
+		if diags[i].Code != code {
+			t.Errorf("wrong code %q for diagnostic %d; want %q", diags[i].Code, i, code)
+		}
+	}
+
+	fixed, err := hcl.ApplyEdits([]byte(src), diags.Edits("test.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := `{"image": "a", "tags": [{}, {}]}`
+	if string(fixed) != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", fixed, want)
+	}
+}
+
 func TestJustAttributes(t *testing.T) {
 	// We test most of the functionality already in TestBodyPartialContent, so
 	// this test focuses on the handling of extraneous attributes.
@@ -1529,3 +1575,76 @@ func TestExpressionValue_Diags(t *testing.T) {
 	}
 
 }
+
+func TestExpressionTrace(t *testing.T) {
+	file, diags := Parse([]byte(`{"a": "${x}!"}`), "test.json")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	var got []cty.Value
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"x": cty.StringVal("hello"),
+		},
+		Trace: func(expr hcl.Expression, ctx *hcl.EvalContext, val cty.Value, diags hcl.Diagnostics) {
+			got = append(got, val)
+		},
+	}
+	if _, diags := attrs["a"].Expr.Value(ctx); diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	// The JSON expression is traced after the expressions in its template.
+	if len(got) < 2 {
+		t.Fatalf("too few trace calls %d; want at least 2", len(got))
+	}
+	if want := cty.StringVal("hello"); !got[0].RawEquals(want) {
+		t.Errorf("wrong first traced value %#v; want %#v", got[0], want)
+	}
+	if want := cty.StringVal("hello!"); !got[len(got)-1].RawEquals(want) {
+		t.Errorf("wrong last traced value %#v; want %#v", got[len(got)-1], want)
+	}
+}
+
+func TestBodyPartialContentBlockIndex(t *testing.T) {
+	src := `{"a": {}, "b": [{}, {}], "attr": 1, "c": {"x": {}, "y": {}}}`
+	file, diags := Parse([]byte(src), "test.json")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+
+	first, remain, diags := file.Body.PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "b"}},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	second, diags := remain.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "attr"}},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "c", LabelNames: []string{"name"}},
+			{Type: "a"},
+		},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	blocks := append(first.Blocks, second.Blocks...)
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return blocks[i].Index < blocks[j].Index
+	})
+	var got []int
+	for _, block := range blocks {
+		// The index is the position of the block's body in the source.
+		got = append(got, block.Index)
+	}
+	if want := []int{6, 16, 20, 47, 56}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong block order %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,167 @@
+package hcl
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/apparentlymart/go-textseg/textseg"
+)
+
+// LineIndex is an index of the lines in a source file, which allows
+// conversion between byte offsets and positions without re-scanning the
+// whole file for each conversion.
+//
+// Building an index requires a single pass over the source code. Each
+// conversion then requires only a binary search and a scan of a single line,
+// which is needed to count grapheme clusters for columns. Applications that
+// convert many positions in the same file, such as diagnostic printers and
+// language servers, should build an index once and retain it for as long as
+// the source is unchanged.
+//
+// A LineIndex is safe for concurrent use.
+type LineIndex struct {
+	filename string
+	src      []byte
+
+	// lineStarts are the byte offsets of the start of each line, so that
+	// lineStarts[0] is always zero.
+	lineStarts []int
+}
+
+// NewLineIndex builds a LineIndex for the given source code, which must not
+// be modified while the index is in use. The given filename is used in the
+// ranges returned by the index.
+func NewLineIndex(src []byte, filename string) *LineIndex {
+	lineStarts := []int{0}
+	for i, b := range src {
+		if b == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return &LineIndex{
+		filename:   filename,
+		src:        src,
+		lineStarts: lineStarts,
+	}
+}
+
+// LineCount returns the number of lines in the source code. A file that ends
+// with a newline has an additional empty line after it, as with the ranges
+// produced by the parsers.
+func (li *LineIndex) LineCount() int {
+	return len(li.lineStarts)
+}
+
+// PosForOffset returns the position of the given byte offset. Offsets beyond
+// either end of the source are clamped to the nearest end.
+func (li *LineIndex) PosForOffset(offset int) Pos {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(li.src) {
+		offset = len(li.src)
+	}
+
+	// Find the last line that starts at or before the offset.
+	line := sort.Search(len(li.lineStarts), func(i int) bool {
+		return li.lineStarts[i] > offset
+	}) - 1
+
+	start := li.lineStarts[line]
+	column := 1
+	rest := li.src[start:offset]
+	for len(rest) > 0 {
+		advance, _, _ := textseg.ScanGraphemeClusters(rest, true)
+		if advance == 0 {
+			break
+		}
+		rest = rest[advance:]
+		column++
+	}
+
+	return Pos{
+		Line:   line + 1,
+		Column: column,
+		Byte:   offset,
+	}
+}
+
+// PosForLineColumn returns the complete position, including the byte offset,
+// for the given line and column. The result is false if the line does not
+// exist or if the column is beyond the end of the line, where the position
+// immediately after the last character of a line is considered to be within
+// the line.
+func (li *LineIndex) PosForLineColumn(line, column int) (Pos, bool) {
+	lineSrc, ok := li.lineBytes(line)
+	if !ok || column < 1 {
+		return Pos{}, false
+	}
+
+	offset := li.lineStarts[line-1]
+	for col := 1; col < column; col++ {
+		advance, _, _ := textseg.ScanGraphemeClusters(lineSrc, true)
+		if advance == 0 {
+			return Pos{}, false
+		}
+		lineSrc = lineSrc[advance:]
+		offset += advance
+	}
+
+	return Pos{
+		Line:   line,
+		Column: column,
+		Byte:   offset,
+	}, true
+}
+
+// LineRange returns the range of the given line, not including its newline
+// sequence, and false if the line does not exist.
+func (li *LineIndex) LineRange(line int) (Range, bool) {
+	lineSrc, ok := li.lineBytes(line)
+	if !ok {
+		return Range{}, false
+	}
+	start := Pos{
+		Line:   line,
+		Column: 1,
+		Byte:   li.lineStarts[line-1],
+	}
+	return Range{
+		Filename: li.filename,
+		Start:    start,
+		End:      li.PosForOffset(start.Byte + len(lineSrc)),
+	}, true
+}
+
+// RangeForOffsets returns the range between the given byte offsets.
+func (li *LineIndex) RangeForOffsets(start, end int) Range {
+	return Range{
+		Filename: li.filename,
+		Start:    li.PosForOffset(start),
+		End:      li.PosForOffset(end),
+	}
+}
+
+// SourceText returns the source code covered by the given range, using only
+// its byte offsets, or nil if the range is not within the source code. The
+// result is a slice of the indexed source and so must not be modified.
+func (li *LineIndex) SourceText(rng Range) []byte {
+	if !rng.CanSliceBytes(li.src) {
+		return nil
+	}
+	return rng.SliceBytes(li.src)
+}
+
+// lineBytes returns the source code of the given line, without its newline
+// sequence.
+func (li *LineIndex) lineBytes(line int) ([]byte, bool) {
+	if line < 1 || line > len(li.lineStarts) {
+		return nil, false
+	}
+	start := li.lineStarts[line-1]
+	end := len(li.src)
+	if line < len(li.lineStarts) {
+		end = li.lineStarts[line] - 1 // exclude the newline
+	}
+	return bytes.TrimSuffix(li.src[start:end], []byte{'\r'}), true
+}
@@ -0,0 +1,63 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/apparentlymart/go-textseg/textseg"
+)
+
+func TestLineIndex(t *testing.T) {
+	src := []byte("a = 1\r\nbé = \"ü\"\n\nlast")
+	idx := NewLineIndex(src, "test.hcl")
+
+	if got, want := idx.LineCount(), 4; got != want {
+		t.Errorf("wrong line count %d; want %d", got, want)
+	}
+
+	// Every offset at the start of a grapheme cluster should agree with
+	// the positions produced by RangeScanner.
+	sc := NewRangeScanner(src, "test.hcl", textseg.ScanGraphemeClusters)
+	for sc.Scan() {
+		want := sc.Range().Start
+		if got := idx.PosForOffset(want.Byte); got != want {
+			t.Errorf("wrong position for offset %d\ngot:  %#v\nwant: %#v", want.Byte, got, want)
+		}
+		got, ok := idx.PosForLineColumn(want.Line, want.Column)
+		if !ok || got != want {
+			t.Errorf("wrong position for %d:%d\ngot:  %#v\nwant: %#v", want.Line, want.Column, got, want)
+		}
+	}
+
+	if got, want := idx.PosForOffset(len(src)+10), (Pos{Line: 4, Column: 5, Byte: len(src)}); got != want {
+		t.Errorf("wrong position past the end\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if _, ok := idx.PosForLineColumn(1, 8); ok {
+		t.Errorf("position beyond end of line 1 was accepted")
+	}
+	if _, ok := idx.PosForLineColumn(5, 1); ok {
+		t.Errorf("position on nonexistent line 5 was accepted")
+	}
+
+	rng, ok := idx.LineRange(2)
+	if !ok {
+		t.Fatalf("line 2 does not exist")
+	}
+	if got, want := string(idx.SourceText(rng)), "bé = \"ü\""; got != want {
+		t.Errorf("wrong text for line 2 %q; want %q", got, want)
+	}
+	if got, want := rng.End, (Pos{Line: 2, Column: 9, Byte: 17}); got != want {
+		t.Errorf("wrong end of line 2\ngot:  %#v\nwant: %#v", got, want)
+	}
+	rng, _ = idx.LineRange(1)
+	if got, want := string(idx.SourceText(rng)), "a = 1"; got != want {
+		t.Errorf("wrong text for line 1 %q; want %q", got, want)
+	}
+
+	rng = idx.RangeForOffsets(7, 10)
+	if got, want := string(idx.SourceText(rng)), "bé"; got != want {
+		t.Errorf("wrong text for range %q; want %q", got, want)
+	}
+	if idx.SourceText(Range{End: Pos{Byte: len(src) + 1}}) != nil {
+		t.Errorf("got text for out-of-bounds range")
+	}
+}
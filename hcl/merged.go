@@ -12,9 +12,15 @@ import (
 // the same name across multiple files, a diagnostic will be produced from
 // the Content and PartialContent methods describing this error in a
 // user-friendly way.
+//
+// Nil files are ignored, so that the result of parsing a set of files can be
+// merged even if some of them could not be read at all.
 func MergeFiles(files []*File) Body {
 	var bodies []Body
 	for _, file := range files {
+		if file == nil || file.Body == nil {
+			continue
+		}
 		bodies = append(bodies, file.Body)
 	}
 	return MergeBodies(bodies)
@@ -94,33 +100,48 @@ func (mb mergedBodies) PartialContent(schema *BodySchema) (*BodyContent, Body, D
 }
 
 func (mb mergedBodies) JustAttributes() (Attributes, Diagnostics) {
-	attrs := make(map[string]*Attribute)
+	ordered, diags := mb.JustAttributesInOrder()
+
+	attrs := make(map[string]*Attribute, len(ordered))
+	for _, attr := range ordered {
+		attrs[attr.Name] = attr
+	}
+
+	return attrs, diags
+}
+
+// JustAttributesInOrder returns the attributes of each of the merged bodies
+// in turn, with each body's attributes in source order. This is the method
+// used by AttributesInOrder.
+func (mb mergedBodies) JustAttributesInOrder() ([]*Attribute, Diagnostics) {
+	var attrs []*Attribute
+	seen := make(map[string]*Attribute)
 	var diags Diagnostics
 
 	for _, body := range mb {
-		thisAttrs, thisDiags := body.JustAttributes()
+		thisAttrs, thisDiags := AttributesInOrder(body)
 
 		if len(thisDiags) != 0 {
 			diags = append(diags, thisDiags...)
 		}
 
-		if thisAttrs != nil {
-			for name, attr := range thisAttrs {
-				if existing := attrs[name]; existing != nil {
-					diags = diags.Append(&Diagnostic{
-						Severity: DiagError,
-						Summary:  "Duplicate argument",
-						Detail: fmt.Sprintf(
-							"Argument %q was already set at %s",
-							name, existing.NameRange.String(),
-						),
-						Subject: &attr.NameRange,
-					})
-					continue
-				}
-
-				attrs[name] = attr
+		for _, attr := range thisAttrs {
+			if existing := seen[attr.Name]; existing != nil {
+				diags = diags.Append(&Diagnostic{
+					Severity: DiagError,
+					Summary:  "Duplicate argument",
+					Code:     DiagDuplicateArgument,
+					Detail: fmt.Sprintf(
+						"Argument %q was already set at %s. Each argument may be set only once across all of the merged files.",
+						attr.Name, existing.NameRange.String(),
+					),
+					Subject: &attr.NameRange,
+				})
+				continue
 			}
+
+			seen[attr.Name] = attr
+			attrs = append(attrs, attr)
 		}
 	}
 
@@ -135,7 +156,14 @@ func (mb mergedBodies) MissingItemRange() Range {
 		}
 	}
 
-	// arbitrarily use the first body's missing item range
+	// Arbitrarily use the first body's missing item range, since it's the
+	// primary file in typical usage, but skip over any bodies that can't
+	// give a range in a real file.
+	for _, body := range mb {
+		if rng := body.MissingItemRange(); rng.Filename != "" {
+			return rng
+		}
+	}
 	return mb[0].MissingItemRange()
 }
 
@@ -183,8 +211,9 @@ func (mb mergedBodies) mergedContent(schema *BodySchema, partial bool) (*BodyCon
 					diags = diags.Append(&Diagnostic{
 						Severity: DiagError,
 						Summary:  "Duplicate argument",
+						Code:     DiagDuplicateArgument,
 						Detail: fmt.Sprintf(
-							"Argument %q was already set at %s",
+							"Argument %q was already set at %s. Each argument may be set only once across all of the merged files.",
 							name, existing.NameRange.String(),
 						),
 						Subject: &attr.NameRange,
@@ -207,16 +236,23 @@ func (mb mergedBodies) mergedContent(schema *BodySchema, partial bool) (*BodyCon
 		}
 
 		if content.Attributes[attrS.Name] == nil {
-			// We don't have any context here to produce a good diagnostic,
+			// We don't have much context here to produce a good diagnostic,
 			// which is why we warn in the Content docstring to minimize the
-			// use of required attributes on merged bodies.
+			// use of required attributes on merged bodies. The best we can
+			// do is to point at the first body, if there is one.
+			var subject *Range
+			if len(mb) != 0 {
+				subject = mb.MissingItemRange().Ptr()
+			}
 			diags = diags.Append(&Diagnostic{
 				Severity: DiagError,
 				Summary:  "Missing required argument",
+				Code:     DiagMissingRequiredArgument,
 				Detail: fmt.Sprintf(
-					"The argument %q is required, but was not set.",
+					"The argument %q is required, but was not set in any of the merged files.",
 					attrS.Name,
 				),
+				Subject: subject,
 			})
 		}
 	}
@@ -656,10 +656,15 @@ func (v *testMergedBodiesVictim) JustAttributes() (Attributes, Diagnostics) {
 		Filename: v.Name,
 	}
 
-	for _, name := range v.HasAttributes {
+	for i, name := range v.HasAttributes {
 		attrs[name] = &Attribute{
 			Name:      name,
 			NameRange: rng,
+			Range: Range{
+				Filename: v.Name,
+				Start:    Pos{Byte: i},
+				End:      Pos{Byte: i},
+			},
 		}
 	}
 
@@ -681,3 +686,113 @@ func (v *testMergedBodiesVictim) MissingItemRange() Range {
 		Filename: v.Name,
 	}
 }
+
+func TestMergeFilesNil(t *testing.T) {
+	merged := MergeFiles([]*File{
+		nil,
+		{
+			Body: &testMergedBodiesVictim{
+				Name:          "second",
+				HasAttributes: []string{"name"},
+			},
+		},
+	})
+	content, diags := merged.Content(&BodySchema{
+		Attributes: []AttributeSchema{
+			{Name: "name", Required: true},
+		},
+	})
+	if len(diags) != 0 {
+		t.Errorf("unexpected diagnostics: %s", diags.Error())
+	}
+	if _, ok := content.Attributes["name"]; !ok {
+		t.Errorf("name attribute is missing")
+	}
+}
+
+func TestMergedBodiesMissingItemRange(t *testing.T) {
+	merged := MergeBodies([]Body{
+		&testMergedBodiesVictim{},
+		&testMergedBodiesVictim{Name: "second"},
+	})
+	if got, want := merged.MissingItemRange(), (Range{Filename: "second"}); got != want {
+		t.Errorf("wrong MissingItemRange %#v; want %#v", got, want)
+	}
+
+	_, diags := merged.Content(&BodySchema{
+		Attributes: []AttributeSchema{
+			{Name: "name", Required: true},
+		},
+	})
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1", len(diags))
+	}
+	if got, want := diags[0].Subject, (&Range{Filename: "second"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong subject %#v; want %#v", got, want)
+	}
+}
+
+func TestAttributesInOrder(t *testing.T) {
+	tests := []struct {
+		Body      Body
+		Want      []string
+		DiagCount int
+	}{
+		{
+			&testMergedBodiesVictim{
+				Name:          "only",
+				HasAttributes: []string{"c", "a", "b"},
+			},
+			[]string{"c", "a", "b"},
+			0,
+		},
+		{
+			MergeBodies([]Body{
+				&testMergedBodiesVictim{
+					Name:          "z",
+					HasAttributes: []string{"b", "a"},
+				},
+				&testMergedBodiesVictim{
+					Name:          "y",
+					HasAttributes: []string{"d", "c"},
+				},
+			}),
+			[]string{"b", "a", "d", "c"},
+			0,
+		},
+		{
+			MergeBodies([]Body{
+				&testMergedBodiesVictim{
+					Name:          "first",
+					HasAttributes: []string{"a", "b"},
+				},
+				&testMergedBodiesVictim{
+					Name:          "second",
+					HasAttributes: []string{"c", "a"},
+				},
+			}),
+			[]string{"a", "b", "c"},
+			1, // duplicate "a"
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			attrs, diags := AttributesInOrder(test.Body)
+			if len(diags) != test.DiagCount {
+				t.Errorf("wrong number of diagnostics %d; want %d", len(diags), test.DiagCount)
+				for _, diag := range diags {
+					t.Logf(" - %s", diag.Error())
+				}
+			}
+
+			var got []string
+			for _, attr := range attrs {
+				got = append(got, attr.Name)
+			}
+			if !reflect.DeepEqual(got, test.Want) {
+				t.Errorf("wrong order\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
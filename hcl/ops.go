@@ -3,6 +3,7 @@ package hcl
 import (
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/convert"
@@ -21,12 +22,55 @@ import (
 // though nil can be provided if the calling application is going to
 // ignore the subject of the returned diagnostics anyway.
 func Index(collection, key cty.Value, srcRange *Range) (cty.Value, Diagnostics) {
+	return index(collection, key, srcRange, stepOptions{})
+}
+
+// Index is like the package-level function Index, except that if the
+// receiver or any of its ancestors has UnknownForMissing set then indexing
+// with a key that does not identify an element of a known collection
+// produces an unknown value rather than an error. The receiver may be nil.
+func (ctx *EvalContext) Index(collection, key cty.Value, srcRange *Range) (cty.Value, Diagnostics) {
+	return index(collection, key, srcRange, stepOptions{
+		unknownForMissing: ctx.UnknownForMissingEnabled(),
+	})
+}
+
+// stepOptions customizes the behavior of index and getAttr for the steps of
+// a traversal.
+type stepOptions struct {
+	// path is the absolute traversal that produced the value being
+	// traversed, if known, which is used to describe the value in
+	// diagnostics.
+	path Traversal
+
+	// sensitive is set if the value being traversed is sensitive, in which
+	// case diagnostics will not describe its content.
+	sensitive bool
+
+	// unknownForMissing is set if a reference to an element or attribute
+	// that does not exist should produce an unknown value, as described
+	// for EvalContext.UnknownForMissing.
+	unknownForMissing bool
+}
+
+// describe returns a description of the value being traversed for use in
+// diagnostic messages, beginning with the given noun such as "value", or
+// the given fallback if the path of the value is not known.
+func (o stepOptions) describe(noun, fallback string) string {
+	if len(o.path) == 0 {
+		return fallback
+	}
+	return fmt.Sprintf("the %s %s", noun, o.path)
+}
+
+func index(collection, key cty.Value, srcRange *Range, opts stepOptions) (cty.Value, Diagnostics) {
 	if collection.IsNull() {
 		return cty.DynamicVal, Diagnostics{
 			{
 				Severity: DiagError,
 				Summary:  "Attempt to index null value",
-				Detail:   "This value is null, so it does not have any indices.",
+				Code:     DiagAttemptToIndexNullValue,
+				Detail:   fmt.Sprintf("%s is null, so it does not have any indices.", capitalize(opts.describe("value of", "this value"))),
 				Subject:  srcRange,
 			},
 		}
@@ -36,6 +80,7 @@ func Index(collection, key cty.Value, srcRange *Range) (cty.Value, Diagnostics)
 			{
 				Severity: DiagError,
 				Summary:  "Invalid index",
+				Code:     DiagInvalidIndex,
 				Detail:   "Can't use a null value as an indexing key.",
 				Subject:  srcRange,
 			},
@@ -47,6 +92,8 @@ func Index(collection, key cty.Value, srcRange *Range) (cty.Value, Diagnostics)
 		return cty.DynamicVal, nil
 	}
 
+	collDesc := opts.describe("collection", "this collection value")
+
 	switch {
 
 	case ty.IsListType() || ty.IsTupleType() || ty.IsMapType():
@@ -67,9 +114,10 @@ func Index(collection, key cty.Value, srcRange *Range) (cty.Value, Diagnostics)
 				{
 					Severity: DiagError,
 					Summary:  "Invalid index",
+					Code:     DiagInvalidIndex,
 					Detail: fmt.Sprintf(
-						"The given key does not identify an element in this collection value: %s.",
-						keyErr.Error(),
+						"The given key does not identify an element in %s: %s.",
+						collDesc, keyErr.Error(),
 					),
 					Subject: srcRange,
 				},
@@ -98,7 +146,8 @@ func Index(collection, key cty.Value, srcRange *Range) (cty.Value, Diagnostics)
 							{
 								Severity: DiagError,
 								Summary:  "Invalid index",
-								Detail:   fmt.Sprintf("The given key does not identify an element in this collection value: indexing a sequence requires a whole number, but the given index (%g) has a fractional part.", bf),
+								Code:     DiagInvalidIndex,
+								Detail:   fmt.Sprintf("The given key does not identify an element in %s: indexing a sequence requires a whole number, but the given index (%g) has a fractional part.", collDesc, bf),
 								Subject:  srcRange,
 							},
 						}
@@ -106,11 +155,28 @@ func Index(collection, key cty.Value, srcRange *Range) (cty.Value, Diagnostics)
 				}
 			}
 
+			if opts.unknownForMissing {
+				if ty.IsTupleType() {
+					return cty.DynamicVal, nil
+				}
+				return cty.UnknownVal(ty.ElementType()), nil
+			}
+
+			detail := fmt.Sprintf("The given key does not identify an element in %s.", collDesc)
+			if !opts.sensitive && key.IsKnown() {
+				switch {
+				case ty.IsMapType():
+					detail = fmt.Sprintf("The given key does not identify an element in %s: there is no element with the key %q.", collDesc, key.AsString())
+				case collection.IsKnown():
+					detail = fmt.Sprintf("The given key does not identify an element in %s: the index %s is out of range for a sequence of %s.", collDesc, key.AsBigFloat().Text('f', -1), elementCount(collection.LengthInt()))
+				}
+			}
 			return cty.DynamicVal, Diagnostics{
 				{
 					Severity: DiagError,
 					Summary:  "Invalid index",
-					Detail:   "The given key does not identify an element in this collection value.",
+					Code:     DiagInvalidIndex,
+					Detail:   detail,
 					Subject:  srcRange,
 				},
 			}
@@ -125,9 +191,10 @@ func Index(collection, key cty.Value, srcRange *Range) (cty.Value, Diagnostics)
 				{
 					Severity: DiagError,
 					Summary:  "Invalid index",
+					Code:     DiagInvalidIndex,
 					Detail: fmt.Sprintf(
-						"The given key does not identify an element in this collection value: %s.",
-						keyErr.Error(),
+						"The given key does not identify an element in %s: %s.",
+						collDesc, keyErr.Error(),
 					),
 					Subject: srcRange,
 				},
@@ -143,11 +210,15 @@ func Index(collection, key cty.Value, srcRange *Range) (cty.Value, Diagnostics)
 		attrName := key.AsString()
 
 		if !ty.HasAttribute(attrName) {
+			if opts.unknownForMissing {
+				return cty.DynamicVal, nil
+			}
 			return cty.DynamicVal, Diagnostics{
 				{
 					Severity: DiagError,
 					Summary:  "Invalid index",
-					Detail:   "The given key does not identify an element in this collection value.",
+					Code:     DiagInvalidIndex,
+					Detail:   fmt.Sprintf("The given key does not identify an element in %s.", collDesc),
 					Subject:  srcRange,
 				},
 			}
@@ -160,7 +231,8 @@ func Index(collection, key cty.Value, srcRange *Range) (cty.Value, Diagnostics)
 			{
 				Severity: DiagError,
 				Summary:  "Invalid index",
-				Detail:   "This value does not have any indices.",
+				Code:     DiagInvalidIndex,
+				Detail:   fmt.Sprintf("%s does not have any indices.", capitalize(opts.describe("value", "this value"))),
 				Subject:  srcRange,
 			},
 		}
@@ -181,12 +253,17 @@ func Index(collection, key cty.Value, srcRange *Range) (cty.Value, Diagnostics)
 // though nil can be provided if the calling application is going to
 // ignore the subject of the returned diagnostics anyway.
 func GetAttr(obj cty.Value, attrName string, srcRange *Range) (cty.Value, Diagnostics) {
+	return getAttr(obj, attrName, srcRange, stepOptions{})
+}
+
+func getAttr(obj cty.Value, attrName string, srcRange *Range, opts stepOptions) (cty.Value, Diagnostics) {
 	if obj.IsNull() {
 		return cty.DynamicVal, Diagnostics{
 			{
 				Severity: DiagError,
 				Summary:  "Attempt to get attribute from null value",
-				Detail:   "This value is null, so it does not have any attributes.",
+				Code:     DiagAttemptToGetAttributeFromNullValue,
+				Detail:   fmt.Sprintf("%s is null, so it does not have any attributes.", capitalize(opts.describe("value of", "this value"))),
 				Subject:  srcRange,
 			},
 		}
@@ -196,11 +273,15 @@ func GetAttr(obj cty.Value, attrName string, srcRange *Range) (cty.Value, Diagno
 	switch {
 	case ty.IsObjectType():
 		if !ty.HasAttribute(attrName) {
+			if opts.unknownForMissing {
+				return cty.DynamicVal, nil
+			}
 			return cty.DynamicVal, Diagnostics{
 				{
 					Severity: DiagError,
 					Summary:  "Unsupported attribute",
-					Detail:   fmt.Sprintf("This object does not have an attribute named %q.", attrName),
+					Code:     DiagUnsupportedAttribute,
+					Detail:   fmt.Sprintf("%s does not have an attribute named %q.", capitalize(opts.describe("object", "this object")), attrName),
 					Subject:  srcRange,
 				},
 			}
@@ -218,11 +299,15 @@ func GetAttr(obj cty.Value, attrName string, srcRange *Range) (cty.Value, Diagno
 
 		idx := cty.StringVal(attrName)
 		if obj.HasIndex(idx).False() {
+			if opts.unknownForMissing {
+				return cty.UnknownVal(ty.ElementType()), nil
+			}
 			return cty.DynamicVal, Diagnostics{
 				{
 					Severity: DiagError,
 					Summary:  "Missing map element",
-					Detail:   fmt.Sprintf("This map does not have an element with the key %q.", attrName),
+					Code:     DiagMissingMapElement,
+					Detail:   fmt.Sprintf("%s does not have an element with the key %q.", capitalize(opts.describe("map", "this map")), attrName),
 					Subject:  srcRange,
 				},
 			}
@@ -236,7 +321,8 @@ func GetAttr(obj cty.Value, attrName string, srcRange *Range) (cty.Value, Diagno
 			{
 				Severity: DiagError,
 				Summary:  "Unsupported attribute",
-				Detail:   "This value does not have any attributes.",
+				Code:     DiagUnsupportedAttribute,
+				Detail:   fmt.Sprintf("%s does not have any attributes.", capitalize(opts.describe("value", "this value"))),
 				Subject:  srcRange,
 			},
 		}
@@ -244,6 +330,23 @@ func GetAttr(obj cty.Value, attrName string, srcRange *Range) (cty.Value, Diagno
 
 }
 
+// elementCount describes a number of elements, such as "1 element".
+func elementCount(n int) string {
+	if n == 1 {
+		return "1 element"
+	}
+	return fmt.Sprintf("%d elements", n)
+}
+
+// capitalize returns the given English phrase with its first letter in
+// upper case.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
 // ApplyPath is a helper function that applies a cty.Path to a value using the
 // indexing and attribute access operations from HCL.
 //
@@ -272,6 +375,7 @@ func ApplyPath(val cty.Value, path cty.Path, srcRange *Range) (cty.Value, Diagno
 			diags = diags.Append(&Diagnostic{
 				Severity: DiagError,
 				Summary:  "Invalid path step",
+				Code:     DiagInvalidPathStep,
 				Detail:   fmt.Sprintf("Go type %T is not a valid path step. This is a bug in this program.", step),
 				Subject:  srcRange,
 			})
@@ -0,0 +1,227 @@
+package hcl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MergeFilesWithOverrides is like MergeFiles, except that the given override
+// files are then applied to the merged body in turn using OverrideBody, so
+// that their content replaces rather than adds to that of the other files.
+//
+// This implements the convention of "override files", which allow a
+// configuration to be adjusted for a particular environment without
+// modifying the files that define it. Package hclparse's ParseDir
+// identifies override files by their names.
+//
+// Nil files are ignored, as for MergeFiles.
+func MergeFilesWithOverrides(files, overrides []*File) Body {
+	body := MergeFiles(files)
+	for _, file := range overrides {
+		if file == nil || file.Body == nil {
+			continue
+		}
+		body = OverrideBody(body, file.Body)
+	}
+	return body
+}
+
+// OverrideBody returns a body that combines the content of the given base
+// body with that of the given override body, with the override body taking
+// precedence.
+//
+// Attributes in the override body replace any attributes of the same name
+// in the base body, and are otherwise added to it.
+//
+// Each block in the override body must have the same type and labels as a
+// block in the base body, or an error is reported since it has nothing to
+// override. If it matches exactly one block then it is merged with that
+// block, recursively applying the same rules to their bodies. If it matches
+// several blocks, as is common for nested blocks that have no labels, then
+// there is no way to tell which of them it is intended to override, and so
+// all of the blocks in the override body with that type and labels replace
+// all of those in the base body. Blocks in the base body keep their order,
+// with any replacements taking the place of the first block they replace.
+//
+// Content extracted from both bodies must conform to the given schema, and
+// a required attribute may be set in either of them.
+func OverrideBody(base, override Body) Body {
+	return overrideBody{
+		Base:     base,
+		Override: override,
+	}
+}
+
+type overrideBody struct {
+	Base     Body
+	Override Body
+}
+
+func (b overrideBody) Content(schema *BodySchema) (*BodyContent, Diagnostics) {
+	innerSchema := overrideSchema(schema)
+	content, diags := b.Base.Content(innerSchema)
+	overContent, overDiags := b.Override.Content(innerSchema)
+	diags = append(diags, overDiags...)
+	return b.mergeContent(schema, content, overContent, diags)
+}
+
+func (b overrideBody) PartialContent(schema *BodySchema) (*BodyContent, Body, Diagnostics) {
+	innerSchema := overrideSchema(schema)
+	content, remain, diags := b.Base.PartialContent(innerSchema)
+	overContent, overRemain, overDiags := b.Override.PartialContent(innerSchema)
+	diags = append(diags, overDiags...)
+	merged, diags := b.mergeContent(schema, content, overContent, diags)
+	return merged, OverrideBody(remain, overRemain), diags
+}
+
+func (b overrideBody) JustAttributes() (Attributes, Diagnostics) {
+	attrs, diags := b.Base.JustAttributes()
+	overAttrs, overDiags := b.Override.JustAttributes()
+	diags = append(diags, overDiags...)
+
+	ret := make(Attributes, len(attrs)+len(overAttrs))
+	for name, attr := range attrs {
+		ret[name] = attr
+	}
+	for name, attr := range overAttrs {
+		ret[name] = attr
+	}
+	return ret, diags
+}
+
+// JustAttributesInOrder returns the attributes of the base body in order,
+// with any that are overridden replaced in place, followed by the
+// attributes that are only in the override body. This is the method used by
+// AttributesInOrder.
+func (b overrideBody) JustAttributesInOrder() ([]*Attribute, Diagnostics) {
+	attrs, diags := AttributesInOrder(b.Base)
+	overAttrs, overDiags := AttributesInOrder(b.Override)
+	diags = append(diags, overDiags...)
+
+	overByName := make(map[string]*Attribute, len(overAttrs))
+	for _, attr := range overAttrs {
+		overByName[attr.Name] = attr
+	}
+	ret := make([]*Attribute, 0, len(attrs)+len(overAttrs))
+	for _, attr := range attrs {
+		if over, exists := overByName[attr.Name]; exists {
+			attr = over
+			delete(overByName, attr.Name)
+		}
+		ret = append(ret, attr)
+	}
+	for _, attr := range overAttrs {
+		if _, remaining := overByName[attr.Name]; remaining {
+			ret = append(ret, attr)
+		}
+	}
+	return ret, diags
+}
+
+func (b overrideBody) MissingItemRange() Range {
+	return b.Base.MissingItemRange()
+}
+
+func (b overrideBody) mergeContent(schema *BodySchema, content, overContent *BodyContent, diags Diagnostics) (*BodyContent, Diagnostics) {
+	ret := &BodyContent{
+		Attributes:       make(Attributes, len(content.Attributes)+len(overContent.Attributes)),
+		MissingItemRange: content.MissingItemRange,
+	}
+	for name, attr := range content.Attributes {
+		ret.Attributes[name] = attr
+	}
+	for name, attr := range overContent.Attributes {
+		ret.Attributes[name] = attr
+	}
+
+	for _, attrS := range schema.Attributes {
+		if attrS.Required && ret.Attributes[attrS.Name] == nil {
+			diags = append(diags, &Diagnostic{
+				Severity: DiagError,
+				Summary:  "Missing required argument",
+				Code:     DiagMissingRequiredArgument,
+				Detail:   fmt.Sprintf("The argument %q is required, but no definition was found.", attrS.Name),
+				Subject:  b.MissingItemRange().Ptr(),
+			})
+		}
+	}
+
+	overBlocks := make(map[string][]*Block)
+	for _, block := range overContent.Blocks {
+		key := overrideBlockKey(block)
+		overBlocks[key] = append(overBlocks[key], block)
+	}
+	baseCount := make(map[string]int)
+	for _, block := range content.Blocks {
+		baseCount[overrideBlockKey(block)]++
+	}
+
+	replaced := make(map[string]bool)
+	for _, block := range content.Blocks {
+		key := overrideBlockKey(block)
+		overs := overBlocks[key]
+		switch {
+		case len(overs) == 0:
+			ret.Blocks = append(ret.Blocks, block)
+		case baseCount[key] == 1:
+			// Shallow-copy the block so we can mutate it
+			newBlock := *block
+			for _, over := range overs {
+				newBlock.Body = OverrideBody(newBlock.Body, over.Body)
+			}
+			ret.Blocks = append(ret.Blocks, &newBlock)
+		case !replaced[key]:
+			ret.Blocks = append(ret.Blocks, overs...)
+			replaced[key] = true
+		}
+	}
+
+	for _, block := range overContent.Blocks {
+		if baseCount[overrideBlockKey(block)] != 0 {
+			continue
+		}
+		diags = append(diags, &Diagnostic{
+			Severity: DiagError,
+			Summary:  "Missing base block for override",
+			Code:     DiagMissingOverrideBase,
+			Detail:   fmt.Sprintf("There is no %s block to override. An override can only modify blocks that are already defined in the base configuration.", overrideBlockDesc(block)),
+			Subject:  block.DefRange.Ptr(),
+		})
+	}
+
+	return ret, diags
+}
+
+// overrideSchema returns a version of the given schema with none of the
+// attributes marked as required, since either of the bodies can provide
+// an attribute value. Override bodies check required attributes separately.
+func overrideSchema(schema *BodySchema) *BodySchema {
+	ret := &BodySchema{
+		Blocks: schema.Blocks,
+	}
+	for _, attrS := range schema.Attributes {
+		attrS.Required = false
+		ret.Attributes = append(ret.Attributes, attrS)
+	}
+	return ret
+}
+
+// overrideBlockKey returns a string that is unique for each distinct
+// combination of block type and labels.
+func overrideBlockKey(block *Block) string {
+	parts := make([]string, 0, len(block.Labels)+1)
+	parts = append(parts, block.Type)
+	parts = append(parts, block.Labels...)
+	return strings.Join(parts, "\x00")
+}
+
+// overrideBlockDesc describes the given block by its type and labels, as
+// they would be written in the native syntax.
+func overrideBlockDesc(block *Block) string {
+	parts := make([]string, 0, len(block.Labels)+1)
+	parts = append(parts, block.Type)
+	for _, label := range block.Labels {
+		parts = append(parts, fmt.Sprintf("%q", label))
+	}
+	return strings.Join(parts, " ")
+}
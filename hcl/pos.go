@@ -63,6 +63,19 @@ func RangeBetween(start, end Range) Range {
 	}
 }
 
+// RangeBetweenPos returns a new range in the given file that spans from the
+// given start position to the given end position.
+//
+// The result is meaningless if the end position appears before the start
+// position.
+func RangeBetweenPos(filename string, start, end Pos) Range {
+	return Range{
+		Filename: filename,
+		Start:    start,
+		End:      end,
+	}
+}
+
 // RangeOver returns a new range that covers both of the given ranges and
 // possibly additional content between them if the two ranges do not overlap.
 //
@@ -113,6 +126,33 @@ func (r Range) ContainsOffset(offset int) bool {
 	return offset >= r.Start.Byte && offset < r.End.Byte
 }
 
+// ContainsRange returns true if and only if all of the characters of the
+// given range are also within the receiving range. An empty range is
+// considered to be within the receiver if its position is within the receiver
+// or at its end.
+func (r Range) ContainsRange(other Range) bool {
+	return r.Filename == other.Filename &&
+		other.Start.Byte >= r.Start.Byte && other.End.Byte <= r.End.Byte
+}
+
+// PointAt returns an empty range at the given position in the same file as
+// the receiver, which can be used to represent an insertion point or a
+// cursor position.
+func (r Range) PointAt(pos Pos) Range {
+	return Range{
+		Filename: r.Filename,
+		Start:    pos,
+		End:      pos,
+	}
+}
+
+// Union returns a range that covers both the receiver and the given range,
+// along with any content between them. It is the same as RangeOver, which
+// describes its behavior in more detail.
+func (r Range) Union(other Range) Range {
+	return RangeOver(r, other)
+}
+
 // Ptr returns a pointer to a copy of the receiver. This is a convenience when
 // ranges in places where pointers are required, such as in Diagnostic, but
 // the range in question is returned from a method. Go would otherwise not
@@ -193,7 +233,8 @@ func (r Range) SliceBytes(b []byte) []byte {
 }
 
 // Overlaps returns true if the receiver and the other given range share any
-// characters in common.
+// characters in common. Ranges that are merely adjacent, where one ends
+// exactly where the other begins, do not overlap.
 func (r Range) Overlaps(other Range) bool {
 	switch {
 	case r.Filename != other.Filename:
@@ -202,12 +243,10 @@ func (r Range) Overlaps(other Range) bool {
 	case r.Empty() || other.Empty():
 		// Empty ranges can never overlap
 		return false
-	case r.ContainsOffset(other.Start.Byte) || r.ContainsOffset(other.End.Byte):
-		return true
-	case other.ContainsOffset(r.Start.Byte) || other.ContainsOffset(r.End.Byte):
-		return true
 	default:
-		return false
+		// Since the end offsets are exclusive, each range must start
+		// strictly before the other ends.
+		return r.Start.Byte < other.End.Byte && other.Start.Byte < r.End.Byte
 	}
 }
 
@@ -289,6 +289,34 @@ func TestPosOverlap(t *testing.T) {
 				End:   Pos{Byte: 4, Line: 1, Column: 5},
 			},
 		},
+		{
+			Range{ // ##
+				Start: Pos{Byte: 0, Line: 1, Column: 1},
+				End:   Pos{Byte: 2, Line: 1, Column: 3},
+			},
+			Range{ //   ##
+				Start: Pos{Byte: 2, Line: 1, Column: 3},
+				End:   Pos{Byte: 4, Line: 1, Column: 5},
+			},
+			Range{ // (no overlap)
+				Start: Pos{Byte: 0, Line: 1, Column: 1},
+				End:   Pos{Byte: 0, Line: 1, Column: 1},
+			},
+		},
+		{
+			Range{ //   ##
+				Start: Pos{Byte: 2, Line: 1, Column: 3},
+				End:   Pos{Byte: 4, Line: 1, Column: 5},
+			},
+			Range{ // ##
+				Start: Pos{Byte: 0, Line: 1, Column: 1},
+				End:   Pos{Byte: 2, Line: 1, Column: 3},
+			},
+			Range{ // (no overlap)
+				Start: Pos{Byte: 2, Line: 1, Column: 3},
+				End:   Pos{Byte: 2, Line: 1, Column: 3},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -465,3 +493,51 @@ func visRangeOffsets(rng Range) string {
 	}
 	return buf.String()
 }
+
+func TestRangeContains(t *testing.T) {
+	outer := RangeBetweenPos("test.hcl", Pos{Byte: 2, Line: 1, Column: 3}, Pos{Byte: 6, Line: 1, Column: 7})
+
+	tests := []struct {
+		Other Range
+		Want  bool
+	}{
+		{outer, true},
+		{RangeBetweenPos("test.hcl", Pos{Byte: 3}, Pos{Byte: 5}), true},
+		{RangeBetweenPos("test.hcl", Pos{Byte: 1}, Pos{Byte: 5}), false},
+		{RangeBetweenPos("test.hcl", Pos{Byte: 3}, Pos{Byte: 7}), false},
+		{outer.PointAt(outer.End), true},
+		{outer.PointAt(Pos{Byte: 7}), false},
+		{RangeBetweenPos("other.hcl", Pos{Byte: 3}, Pos{Byte: 5}), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Other.String(), func(t *testing.T) {
+			if got := outer.ContainsRange(test.Other); got != test.Want {
+				t.Errorf("wrong result %t; want %t", got, test.Want)
+			}
+		})
+	}
+
+	if outer.ContainsPos(outer.End) {
+		t.Errorf("range contains its own end position")
+	}
+	if !outer.ContainsPos(outer.Start) {
+		t.Errorf("range does not contain its own start position")
+	}
+}
+
+func TestRangeUnion(t *testing.T) {
+	a := RangeBetweenPos("test.hcl", Pos{Byte: 0, Line: 1, Column: 1}, Pos{Byte: 2, Line: 1, Column: 3})
+	b := RangeBetweenPos("test.hcl", Pos{Byte: 4, Line: 1, Column: 5}, Pos{Byte: 6, Line: 1, Column: 7})
+	want := RangeBetweenPos("test.hcl", a.Start, b.End)
+
+	if got := a.Union(b); got != want {
+		t.Errorf("wrong result %s; want %s", got, want)
+	}
+	if got := b.Union(a); got != want {
+		t.Errorf("wrong result %s; want %s", got, want)
+	}
+	if got := a.Union(b.PointAt(b.End)); got != a {
+		t.Errorf("wrong result with empty range %s; want %s", got, a)
+	}
+}
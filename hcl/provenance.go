@@ -0,0 +1,82 @@
+package hcl
+
+import (
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Provenance records where the values produced by decoding a body came from,
+// as a list of entries that each associate a path within the decoded result
+// with the source range of the expression, or block label, that produced the
+// value at that path.
+//
+// Since the recorded ranges are those of the expressions themselves, they
+// refer to the file where each expression was written even when the body
+// being decoded was produced by merging several files, or when the
+// expression was copied into several blocks by an extension such as
+// dynblock.
+//
+// Decoders that support provenance, such as hcldec.DecodeWithProvenance and
+// gohcl.DecodeBodyWithProvenance, document the form of the paths they
+// record.
+type Provenance []ProvenanceEntry
+
+// ProvenanceEntry is a single entry in a Provenance.
+type ProvenanceEntry struct {
+	Path  cty.Path
+	Range Range
+}
+
+// Add appends an entry with the given path and range.
+func (p *Provenance) Add(path cty.Path, rng Range) {
+	*p = append(*p, ProvenanceEntry{
+		Path:  path,
+		Range: rng,
+	})
+}
+
+// Range returns the source range of the value at the given path. The
+// entries record the source of each value that came directly from an
+// expression, so if there is no entry for the path itself then the result is
+// the range of the nearest ancestor of the path that has one, such as the
+// expression that produced a whole map when the path refers to one of its
+// elements.
+//
+// The second return value is false if neither the path nor any of its
+// ancestors has an entry.
+func (p Provenance) Range(path cty.Path) (Range, bool) {
+	var ret Range
+	best := -1
+	for _, entry := range p {
+		if len(entry.Path) > len(path) || len(entry.Path) <= best {
+			continue
+		}
+		if pathHasPrefix(path, entry.Path) {
+			ret = entry.Range
+			best = len(entry.Path)
+		}
+	}
+	return ret, best >= 0
+}
+
+func pathHasPrefix(path, prefix cty.Path) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i, step := range prefix {
+		switch ts := step.(type) {
+		case cty.GetAttrStep:
+			other, ok := path[i].(cty.GetAttrStep)
+			if !ok || other.Name != ts.Name {
+				return false
+			}
+		case cty.IndexStep:
+			other, ok := path[i].(cty.IndexStep)
+			if !ok || !other.Key.RawEquals(ts.Key) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestProvenanceRange(t *testing.T) {
+	rng := func(line int) Range {
+		return Range{
+			Filename: "test.hcl",
+			Start:    Pos{Line: line, Column: 1},
+			End:      Pos{Line: line, Column: 2},
+		}
+	}
+
+	var prov Provenance
+	prov.Add(cty.GetAttrPath("name"), rng(1))
+	prov.Add(cty.GetAttrPath("tags"), rng(2))
+	prov.Add(cty.GetAttrPath("listener").Index(cty.NumberIntVal(0)).GetAttr("port"), rng(3))
+	prov.Add(cty.GetAttrPath("listener").Index(cty.NumberIntVal(1)).GetAttr("port"), rng(4))
+	prov.Add(cty.GetAttrPath("tags").Index(cty.StringVal("env")), rng(5))
+
+	tests := []struct {
+		name   string
+		path   cty.Path
+		want   Range
+		wantOk bool
+	}{
+		{
+			"exact",
+			cty.GetAttrPath("name"),
+			rng(1),
+			true,
+		},
+		{
+			"nested",
+			cty.GetAttrPath("listener").Index(cty.NumberIntVal(1)).GetAttr("port"),
+			rng(4),
+			true,
+		},
+		{
+			"within an attribute value",
+			cty.GetAttrPath("name").Index(cty.NumberIntVal(0)),
+			rng(1),
+			true,
+		},
+		{
+			"longest prefix",
+			cty.GetAttrPath("tags").Index(cty.StringVal("env")),
+			rng(5),
+			true,
+		},
+		{
+			"shorter prefix",
+			cty.GetAttrPath("tags").Index(cty.StringVal("other")),
+			rng(2),
+			true,
+		},
+		{
+			"ancestor of entries",
+			cty.GetAttrPath("listener"),
+			Range{},
+			false,
+		},
+		{
+			"different step type",
+			cty.IndexPath(cty.StringVal("name")),
+			Range{},
+			false,
+		},
+		{
+			"empty path",
+			nil,
+			Range{},
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := prov.Range(test.path)
+			if ok != test.wantOk {
+				t.Fatalf("wrong ok %t; want %t", ok, test.wantOk)
+			}
+			if got != test.want {
+				t.Errorf("wrong range\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,18 @@ package hcl
 type BlockHeaderSchema struct {
 	Type       string
 	LabelNames []string
+
+	// Deprecated, if set, marks blocks of this type as deprecated. The
+	// Content and PartialContent methods of a body then return a warning
+	// for each block of this type that they return, which includes this
+	// message. The message should be one or more complete sentences, such
+	// as an explanation of what to use instead.
+	Deprecated string
+
+	// ReplacedBy, if set along with Deprecated, is the type of the blocks
+	// that replace the deprecated type. Deprecation warnings then suggest
+	// renaming the block type.
+	ReplacedBy string
 }
 
 // AttributeSchema represents the requirements for an attribute, and is used
@@ -12,6 +24,15 @@ type BlockHeaderSchema struct {
 type AttributeSchema struct {
 	Name     string
 	Required bool
+
+	// Deprecated, if set, marks the attribute as deprecated, with a message
+	// as described for the field of the same name in BlockHeaderSchema.
+	Deprecated string
+
+	// ReplacedBy, if set along with Deprecated, is the name of the
+	// attribute that replaces the deprecated one. Deprecation warnings then
+	// suggest renaming the attribute.
+	ReplacedBy string
 }
 
 // BodySchema represents the desired shallow structure of a body.
@@ -0,0 +1,216 @@
+package hcl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SchemaBuilder constructs a BodySchema, checking for mistakes that would
+// otherwise be detected only when the schema is used, if at all.
+//
+// The methods of SchemaBuilder return the receiver so that calls can be
+// chained:
+//
+//     schema := hcl.NewSchemaBuilder().
+//         RequiredAttribute("name").
+//         Attribute("description").
+//         Block("provisioner", "type").
+//         MustBuild()
+//
+// Any problems, such as duplicate names or names that are not valid
+// identifiers, are recorded as the schema is built and then reported
+// together by Build.
+type SchemaBuilder struct {
+	schema BodySchema
+	names  map[string]string
+	errs   []string
+}
+
+// NewSchemaBuilder returns a new SchemaBuilder for an initially-empty schema.
+func NewSchemaBuilder() *SchemaBuilder {
+	return &SchemaBuilder{
+		names: make(map[string]string),
+	}
+}
+
+// Attribute adds an optional attribute with the given name.
+func (b *SchemaBuilder) Attribute(name string) *SchemaBuilder {
+	return b.addAttribute(AttributeSchema{Name: name})
+}
+
+// RequiredAttribute adds a required attribute with the given name.
+func (b *SchemaBuilder) RequiredAttribute(name string) *SchemaBuilder {
+	return b.addAttribute(AttributeSchema{Name: name, Required: true})
+}
+
+// Block adds a block type with the given name and label names. Each block of
+// this type must have exactly one label for each of the given label names.
+func (b *SchemaBuilder) Block(typeName string, labelNames ...string) *SchemaBuilder {
+	return b.addBlock(BlockHeaderSchema{Type: typeName, LabelNames: labelNames})
+}
+
+// Deprecate marks the attribute or block type with the given name, which
+// must already have been added, as deprecated with the given message. If
+// replacedBy is not empty then it must be the name of another attribute or
+// block type of the same kind, which deprecation warnings will suggest
+// using instead. See the Deprecated field of AttributeSchema for more
+// information.
+func (b *SchemaBuilder) Deprecate(name, message, replacedBy string) *SchemaBuilder {
+	if message == "" {
+		b.errorf("deprecation of %q has no message", name)
+		return b
+	}
+	for i := range b.schema.Attributes {
+		if attrS := &b.schema.Attributes[i]; attrS.Name == name {
+			attrS.Deprecated = message
+			attrS.ReplacedBy = replacedBy
+			return b
+		}
+	}
+	for i := range b.schema.Blocks {
+		if blockS := &b.schema.Blocks[i]; blockS.Type == name {
+			blockS.Deprecated = message
+			blockS.ReplacedBy = replacedBy
+			return b
+		}
+	}
+	b.errorf("cannot deprecate undefined attribute or block type %q", name)
+	return b
+}
+
+// Extend adds all of the attributes and block types of the given schema, as
+// if they had been added individually. This can be used to build a schema
+// that extends another, such as a common set of arguments shared by several
+// block types.
+func (b *SchemaBuilder) Extend(schema *BodySchema) *SchemaBuilder {
+	for _, attrS := range schema.Attributes {
+		b.addAttribute(attrS)
+	}
+	for _, blockS := range schema.Blocks {
+		b.addBlock(blockS)
+	}
+	return b
+}
+
+// Build returns the constructed schema, or an error describing all of the
+// problems that were detected while building it.
+//
+// The builder may continue to be used after Build is called, and the schemas
+// returned by successive calls do not share any storage.
+func (b *SchemaBuilder) Build() (*BodySchema, error) {
+	errs := append(b.errs[:len(b.errs):len(b.errs)], b.replacementErrors()...)
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("invalid schema: %s", strings.Join(errs, "; "))
+	}
+
+	ret := &BodySchema{}
+	if len(b.schema.Attributes) != 0 {
+		ret.Attributes = make([]AttributeSchema, len(b.schema.Attributes))
+		copy(ret.Attributes, b.schema.Attributes)
+	}
+	for _, blockS := range b.schema.Blocks {
+		if blockS.LabelNames != nil {
+			labelNames := make([]string, len(blockS.LabelNames))
+			copy(labelNames, blockS.LabelNames)
+			blockS.LabelNames = labelNames
+		}
+		ret.Blocks = append(ret.Blocks, blockS)
+	}
+	return ret, nil
+}
+
+// MustBuild is like Build except that it panics if there are any problems
+// with the schema. This is intended for schemas that are defined statically
+// in the calling program, where a problem is a bug in that program.
+func (b *SchemaBuilder) MustBuild() *BodySchema {
+	schema, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+func (b *SchemaBuilder) addAttribute(attrS AttributeSchema) *SchemaBuilder {
+	if b.checkName(attrS.Name, "attribute") {
+		b.schema.Attributes = append(b.schema.Attributes, attrS)
+	}
+	return b
+}
+
+func (b *SchemaBuilder) addBlock(blockS BlockHeaderSchema) *SchemaBuilder {
+	if !b.checkName(blockS.Type, "block type") {
+		return b
+	}
+
+	seen := make(map[string]bool, len(blockS.LabelNames))
+	for i, name := range blockS.LabelNames {
+		switch {
+		case name == "":
+			b.errorf("label %d of block type %q has no name", i, blockS.Type)
+			return b
+		case seen[name]:
+			b.errorf("block type %q has more than one label named %q", blockS.Type, name)
+			return b
+		}
+		seen[name] = true
+	}
+
+	b.schema.Blocks = append(b.schema.Blocks, blockS)
+	return b
+}
+
+// replacementErrors returns an error for each deprecated item whose
+// replacement is not another item of the same kind. This is checked only
+// when building, since the replacement may be added after the deprecation.
+func (b *SchemaBuilder) replacementErrors() []string {
+	var errs []string
+	check := func(name, replacedBy, kind string) {
+		if replacedBy == "" {
+			return
+		}
+		switch b.names[replacedBy] {
+		case kind:
+			if replacedBy == name {
+				errs = append(errs, fmt.Sprintf("%s %q cannot be replaced by itself", kind, name))
+			}
+		case "":
+			errs = append(errs, fmt.Sprintf("%s %q is replaced by undefined %s %q", kind, name, kind, replacedBy))
+		default:
+			errs = append(errs, fmt.Sprintf("%s %q cannot be replaced by the %s %q", kind, name, b.names[replacedBy], replacedBy))
+		}
+	}
+	for _, attrS := range b.schema.Attributes {
+		check(attrS.Name, attrS.ReplacedBy, "attribute")
+	}
+	for _, blockS := range b.schema.Blocks {
+		check(blockS.Type, blockS.ReplacedBy, "block type")
+	}
+	return errs
+}
+
+// checkName records an error and returns false if the given name cannot be
+// used for a new attribute or block type.
+func (b *SchemaBuilder) checkName(name, kind string) bool {
+	if name == "" {
+		b.errorf("%s name must not be empty", kind)
+		return false
+	}
+	if !ValidIdentifier(name) {
+		b.errorf("%s name %q is not a valid identifier (try %q)", kind, name, SanitizeIdentifier(name))
+		return false
+	}
+	if existing, exists := b.names[name]; exists {
+		if existing == kind {
+			b.errorf("duplicate %s %q", kind, name)
+		} else {
+			b.errorf("%s %q conflicts with %s of the same name", kind, name, existing)
+		}
+		return false
+	}
+	b.names[name] = kind
+	return true
+}
+
+func (b *SchemaBuilder) errorf(format string, args ...interface{}) {
+	b.errs = append(b.errs, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,147 @@
+package hcl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestSchemaBuilder(t *testing.T) {
+	base := NewSchemaBuilder().
+		Attribute("count").
+		Block("lifecycle").
+		MustBuild()
+
+	tests := map[string]struct {
+		Builder *SchemaBuilder
+		Want    *BodySchema
+		WantErr string
+	}{
+		"empty": {
+			NewSchemaBuilder(),
+			&BodySchema{},
+			``,
+		},
+		"attributes and blocks": {
+			NewSchemaBuilder().
+				RequiredAttribute("name").
+				Attribute("description").
+				Block("provisioner", "type"),
+			&BodySchema{
+				Attributes: []AttributeSchema{
+					{Name: "name", Required: true},
+					{Name: "description"},
+				},
+				Blocks: []BlockHeaderSchema{
+					{Type: "provisioner", LabelNames: []string{"type"}},
+				},
+			},
+			``,
+		},
+		"extended": {
+			NewSchemaBuilder().
+				Attribute("name").
+				Extend(base),
+			&BodySchema{
+				Attributes: []AttributeSchema{
+					{Name: "name"},
+					{Name: "count"},
+				},
+				Blocks: []BlockHeaderSchema{
+					{Type: "lifecycle"},
+				},
+			},
+			``,
+		},
+		"duplicate attribute": {
+			NewSchemaBuilder().
+				Attribute("count").
+				Extend(base),
+			nil,
+			`invalid schema: duplicate attribute "count"`,
+		},
+		"attribute and block conflict": {
+			NewSchemaBuilder().
+				Block("name").
+				Attribute("name").
+				Block("", "a"),
+			nil,
+			`invalid schema: attribute "name" conflicts with block type of the same name; block type name must not be empty`,
+		},
+		"invalid labels": {
+			NewSchemaBuilder().
+				Block("a", "name", "name").
+				Block("b", ""),
+			nil,
+			`invalid schema: block type "a" has more than one label named "name"; label 0 of block type "b" has no name`,
+		},
+		"invalid names": {
+			NewSchemaBuilder().
+				Attribute("instance type").
+				Block("1st").
+				Attribute("instance-type"),
+			nil,
+			`invalid schema: attribute name "instance type" is not a valid identifier (try "instance_type"); block type name "1st" is not a valid identifier (try "_1st")`,
+		},
+		"deprecated": {
+			NewSchemaBuilder().
+				Attribute("ami").
+				Deprecate("ami", "Use image instead.", "image").
+				Attribute("image").
+				Block("tag").
+				Deprecate("tag", "Use the tags argument instead.", ""),
+			&BodySchema{
+				Attributes: []AttributeSchema{
+					{Name: "ami", Deprecated: "Use image instead.", ReplacedBy: "image"},
+					{Name: "image"},
+				},
+				Blocks: []BlockHeaderSchema{
+					{Type: "tag", Deprecated: "Use the tags argument instead."},
+				},
+			},
+			``,
+		},
+		"invalid deprecations": {
+			NewSchemaBuilder().
+				Attribute("a").
+				Block("b").
+				Deprecate("a", "Gone.", "b").
+				Deprecate("b", "Gone.", "c").
+				Deprecate("d", "Gone.", "").
+				Deprecate("a", "", ""),
+			nil,
+			`invalid schema: cannot deprecate undefined attribute or block type "d"; deprecation of "a" has no message; attribute "a" cannot be replaced by the block type "b"; block type "b" is replaced by undefined block type "c"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := test.Builder.Build()
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("no error; want %q", test.WantErr)
+				}
+				if err.Error() != test.WantErr {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", err, test.WantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(got, test.Want) {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", spew.Sdump(got), spew.Sdump(test.Want))
+			}
+		})
+	}
+}
+
+func TestSchemaBuilderMustBuild(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustBuild did not panic for an invalid schema")
+		}
+	}()
+	NewSchemaBuilder().Attribute("a").Attribute("a").MustBuild()
+}
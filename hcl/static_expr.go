@@ -38,3 +38,88 @@ func (e staticExpr) Range() Range {
 func (e staticExpr) StartRange() Range {
 	return e.rng
 }
+
+type traversalExpr struct {
+	traversal Traversal
+	rng       Range
+}
+
+// TraversalExpr returns an Expression that evaluates the given absolute
+// traversal against the EvalContext it is given, in the same way as a
+// variable reference written in configuration would.
+//
+// The returned expression reports the traversal from its Variables method
+// and can be analyzed with AbsTraversalForExpr, so callers that inspect
+// references statically will see it as they would see a native reference.
+//
+// If the given range is the zero value then the source range of the
+// traversal itself is used.
+//
+// This function will panic if the given traversal is relative.
+func TraversalExpr(traversal Traversal, rng Range) Expression {
+	if traversal.IsRelative() {
+		panic("TraversalExpr called with relative traversal")
+	}
+	if rng == (Range{}) {
+		rng = traversal.SourceRange()
+	}
+	return &traversalExpr{traversal, rng}
+}
+
+func (e *traversalExpr) Value(ctx *EvalContext) (cty.Value, Diagnostics) {
+	return e.traversal.TraverseAbs(ctx)
+}
+
+func (e *traversalExpr) Variables() []Traversal {
+	return []Traversal{e.traversal}
+}
+
+func (e *traversalExpr) Range() Range {
+	return e.rng
+}
+
+func (e *traversalExpr) StartRange() Range {
+	return e.rng
+}
+
+// AsTraversal implements the interface used by AbsTraversalForExpr.
+func (e *traversalExpr) AsTraversal() Traversal {
+	return e.traversal
+}
+
+type errorExpr struct {
+	diags Diagnostics
+	rng   Range
+}
+
+// ErrorExpr returns an Expression that always fails evaluation, returning
+// cty.DynamicVal along with the given diagnostics.
+//
+// This is useful when a caller must return an Expression but has already
+// detected a problem with the configuration it would have been derived from,
+// so that the problem is reported to whoever eventually evaluates it.
+//
+// The given diagnostics must have at least one diagnostic of severity
+// DiagError, or this function will panic.
+func ErrorExpr(diags Diagnostics, rng Range) Expression {
+	if !diags.HasErrors() {
+		panic("ErrorExpr called without any error diagnostics")
+	}
+	return &errorExpr{diags, rng}
+}
+
+func (e *errorExpr) Value(ctx *EvalContext) (cty.Value, Diagnostics) {
+	return cty.DynamicVal, e.diags
+}
+
+func (e *errorExpr) Variables() []Traversal {
+	return nil
+}
+
+func (e *errorExpr) Range() Range {
+	return e.rng
+}
+
+func (e *errorExpr) StartRange() Range {
+	return e.rng
+}
@@ -0,0 +1,151 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestStaticExpr(t *testing.T) {
+	rng := Range{Filename: "synthetic"}
+	expr := StaticExpr(cty.StringVal("hello"), rng)
+
+	got, diags := expr.Value(nil)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if !got.RawEquals(cty.StringVal("hello")) {
+		t.Errorf("wrong value %#v", got)
+	}
+	if expr.Range() != rng {
+		t.Errorf("wrong range %#v", expr.Range())
+	}
+	if vars := expr.Variables(); len(vars) != 0 {
+		t.Errorf("unexpected variables %#v", vars)
+	}
+}
+
+func TestTraversalExpr(t *testing.T) {
+	traversal := Traversal{
+		TraverseRoot{
+			Name:     "foo",
+			SrcRange: Range{Filename: "test.hcl", Start: Pos{Line: 1, Column: 1, Byte: 0}, End: Pos{Line: 1, Column: 4, Byte: 3}},
+		},
+		TraverseAttr{
+			Name:     "bar",
+			SrcRange: Range{Filename: "test.hcl", Start: Pos{Line: 1, Column: 4, Byte: 3}, End: Pos{Line: 1, Column: 8, Byte: 7}},
+		},
+	}
+	ctx := &EvalContext{
+		Variables: map[string]cty.Value{
+			"foo": cty.ObjectVal(map[string]cty.Value{
+				"bar": cty.True,
+			}),
+		},
+	}
+
+	tests := []struct {
+		rng       Range
+		wantRange Range
+	}{
+		{
+			Range{},
+			traversal.SourceRange(),
+		},
+		{
+			Range{Filename: "other.hcl"},
+			Range{Filename: "other.hcl"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.wantRange.String(), func(t *testing.T) {
+			expr := TraversalExpr(traversal, test.rng)
+
+			got, diags := expr.Value(ctx)
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics: %s", diags.Error())
+			}
+			if !got.RawEquals(cty.True) {
+				t.Errorf("wrong value %#v", got)
+			}
+			if got := expr.Range(); got != test.wantRange {
+				t.Errorf("wrong range\ngot:  %#v\nwant: %#v", got, test.wantRange)
+			}
+
+			vars := expr.Variables()
+			if len(vars) != 1 || !vars[0].Equal(traversal) {
+				t.Errorf("wrong variables %#v", vars)
+			}
+
+			abs, diags := AbsTraversalForExpr(expr)
+			if len(diags) != 0 {
+				t.Fatalf("unexpected diagnostics from AbsTraversalForExpr: %s", diags.Error())
+			}
+			if !abs.Equal(traversal) {
+				t.Errorf("wrong traversal from AbsTraversalForExpr %#v", abs)
+			}
+		})
+	}
+
+	t.Run("unknown variable", func(t *testing.T) {
+		expr := TraversalExpr(traversal, Range{})
+		_, diags := expr.Value(&EvalContext{
+			Variables: map[string]cty.Value{},
+		})
+		if !diags.HasErrors() {
+			t.Fatalf("expected errors, got none")
+		}
+	})
+}
+
+func TestErrorExpr(t *testing.T) {
+	rng := Range{Filename: "synthetic"}
+	diags := Diagnostics{
+		{
+			Severity: DiagError,
+			Summary:  "Broken",
+			Detail:   "This expression is broken.",
+		},
+	}
+	expr := ErrorExpr(diags, rng)
+
+	got, gotDiags := expr.Value(nil)
+	if !got.RawEquals(cty.DynamicVal) {
+		t.Errorf("wrong value %#v", got)
+	}
+	if len(gotDiags) != 1 || gotDiags[0].Summary != "Broken" {
+		t.Errorf("wrong diagnostics %#v", gotDiags)
+	}
+	if expr.Range() != rng {
+		t.Errorf("wrong range %#v", expr.Range())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("ErrorExpr did not panic without error diagnostics")
+		}
+	}()
+	ErrorExpr(nil, rng)
+}
+
+func TestSyntheticExprIdentity(t *testing.T) {
+	// Like the expressions produced by the parsers, each synthetic
+	// expression is distinct and can be used as a map key.
+	traversal := Traversal{TraverseRoot{Name: "a"}}
+	diags := Diagnostics{{Severity: DiagError, Summary: "Broken"}}
+	exprs := []Expression{
+		TraversalExpr(traversal, Range{}),
+		TraversalExpr(traversal, Range{}),
+		ErrorExpr(diags, Range{}),
+		ErrorExpr(diags, Range{}),
+	}
+
+	seen := make(map[Expression]bool)
+	for _, expr := range exprs {
+		seen[expr] = true
+	}
+	if got, want := len(seen), len(exprs); got != want {
+		t.Errorf("wrong number of distinct expressions %d; want %d", got, want)
+	}
+}
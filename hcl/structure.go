@@ -24,6 +24,20 @@ type Block struct {
 	DefRange    Range   // Range that can be considered the "definition" for seeking in an editor
 	TypeRange   Range   // Range for the block type declaration specifically.
 	LabelRanges []Range // Ranges for the label values specifically.
+
+	// Index orders the block among the other blocks of the body that
+	// defines it, in source order, including those that are returned by
+	// separate calls to PartialContent. This allows an application that
+	// decodes a body in several passes to recover the original order of
+	// its blocks, such as to produce ordered output.
+	//
+	// In the native syntax this is the position of the block among all of
+	// the blocks of its body, counting from zero. Other implementations may
+	// leave gaps between indices, such as the JSON syntax, which cannot tell
+	// which items of a body are blocks without a schema. Blocks generated
+	// from a single definition, such as by package dynblock, share the same
+	// index.
+	Index int
 }
 
 // Blocks is a sequence of Block.
@@ -74,6 +88,9 @@ type Body interface {
 }
 
 // BodyContent is the result of applying a BodySchema to a Body.
+//
+// Blocks are in the order they are defined in the source, regardless of
+// their types, as decided by their Index fields.
 type BodyContent struct {
 	Attributes Attributes
 	Blocks     Blocks
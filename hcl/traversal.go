@@ -42,12 +42,43 @@ func (t Traversal) TraverseRel(val cty.Value) (cty.Value, Diagnostics) {
 	if !t.IsRelative() {
 		panic("can't use TraverseRel on an absolute traversal")
 	}
+	return t.traverseRel(val, nil, stepOptions{})
+}
 
+// TraverseRel is like the TraverseRel method of the given traversal, except
+// that if the receiver or any of its ancestors has UnknownForMissing set then
+// steps that refer to attributes or elements that do not exist produce
+// unknown values rather than errors. The receiver may be nil.
+func (ctx *EvalContext) TraverseRel(t Traversal, val cty.Value) (cty.Value, Diagnostics) {
+	if !t.IsRelative() {
+		panic("can't use TraverseRel on an absolute traversal")
+	}
+	return t.traverseRel(val, nil, stepOptions{
+		unknownForMissing: ctx.UnknownForMissingEnabled(),
+	})
+}
+
+// traverseRel applies the receiving relative traversal to the given value.
+// If abs is not nil then it is the absolute traversal that produced the
+// value, which is used to describe the path to a failing step in
+// diagnostics.
+func (t Traversal) traverseRel(val cty.Value, abs Traversal, opts stepOptions) (cty.Value, Diagnostics) {
 	current := val
 	var diags Diagnostics
-	for _, tr := range t {
+	for i, tr := range t {
+		if abs != nil {
+			opts.path = TraversalJoin(abs, t[:i])
+		}
+
 		var newDiags Diagnostics
-		current, newDiags = tr.TraversalStep(current)
+		switch tn := tr.(type) {
+		case TraverseAttr:
+			current, newDiags = getAttr(current, tn.Name, &tn.SrcRange, opts)
+		case TraverseIndex:
+			current, newDiags = index(current, tn.Key, &tn.SrcRange, opts)
+		default:
+			current, newDiags = tr.TraversalStep(current)
+		}
 		diags = append(diags, newDiags...)
 		if newDiags.HasErrors() {
 			return cty.DynamicVal, diags
@@ -67,18 +98,40 @@ func (t Traversal) TraverseAbs(ctx *EvalContext) (cty.Value, Diagnostics) {
 	split := t.SimpleSplit()
 	root := split.Abs[0].(TraverseRoot)
 	name := root.Name
+	opts := stepOptions{
+		sensitive:         ctx.Sensitive(t),
+		unknownForMissing: ctx.UnknownForMissingEnabled(),
+	}
 
 	thisCtx := ctx
 	hasNonNil := false
 	for thisCtx != nil {
-		if thisCtx.Variables == nil {
+		if thisCtx.Variables == nil && thisCtx.VariableResolver == nil {
 			thisCtx = thisCtx.parent
 			continue
 		}
 		hasNonNil = true
 		val, exists := thisCtx.Variables[name]
 		if exists {
-			return split.Rel.TraverseRel(val)
+			return split.Rel.traverseRel(val, split.Abs, opts)
+		}
+		if thisCtx.VariableResolver != nil {
+			val, diags := thisCtx.VariableResolver(name)
+			for _, diag := range diags {
+				if diag.Subject == nil {
+					diag.Subject = root.SrcRange.Ptr()
+				}
+			}
+			if diags.HasErrors() {
+				if val == cty.NilVal {
+					val = cty.DynamicVal
+				}
+				return val, diags
+			}
+			if val != cty.NilVal {
+				val, moreDiags := split.Rel.traverseRel(val, split.Abs, opts)
+				return val, append(diags, moreDiags...)
+			}
 		}
 		thisCtx = thisCtx.parent
 	}
@@ -88,12 +141,17 @@ func (t Traversal) TraverseAbs(ctx *EvalContext) (cty.Value, Diagnostics) {
 			{
 				Severity: DiagError,
 				Summary:  "Variables not allowed",
+				Code:     DiagVariablesNotAllowed,
 				Detail:   "Variables may not be used here.",
 				Subject:  &root.SrcRange,
 			},
 		}
 	}
 
+	if opts.unknownForMissing {
+		return cty.DynamicVal, nil
+	}
+
 	suggestions := make([]string, 0, len(ctx.Variables))
 	thisCtx = ctx
 	for thisCtx != nil {
@@ -111,6 +169,7 @@ func (t Traversal) TraverseAbs(ctx *EvalContext) (cty.Value, Diagnostics) {
 		{
 			Severity: DiagError,
 			Summary:  "Unknown variable",
+			Code:     DiagUnknownVariable,
 			Detail:   fmt.Sprintf("There is no variable named %q.%s", name, suggestion),
 			Subject:  &root.SrcRange,
 		},
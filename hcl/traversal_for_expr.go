@@ -36,12 +36,46 @@ func AbsTraversalForExpr(expr Expression) (Traversal, Diagnostics) {
 		&Diagnostic{
 			Severity: DiagError,
 			Summary:  "Invalid expression",
+			Code:     DiagStaticExpressionRequired,
 			Detail:   "A single static variable reference is required: only attribute access and indexing with constant keys. No calculations, function calls, template expressions, etc are allowed here.",
 			Subject:  expr.Range().Ptr(),
 		},
 	}
 }
 
+// RelaxedAbsTraversalForExpr is like AbsTraversalForExpr except that it
+// additionally accepts a quoted string containing traversal syntax, such as
+// "aws_instance.example", in place of a naked traversal.
+//
+// This is intended for situations where an application previously required
+// references to be given as strings and now wishes to accept naked
+// references too, or where users are likely to quote references out of
+// habit. New applications should generally prefer AbsTraversalForExpr, so
+// that references look the same as they do elsewhere in the configuration.
+//
+// A particular Expression implementation can support quoted traversals by
+// offering a method called AsQuotedTraversal that takes no arguments and
+// returns a traversal and diagnostics, with a nil traversal and no
+// diagnostics indicating that the expression is not a quoted string.
+func RelaxedAbsTraversalForExpr(expr Expression) (Traversal, Diagnostics) {
+	type asQuotedTraversal interface {
+		AsQuotedTraversal() (Traversal, Diagnostics)
+	}
+
+	physExpr := UnwrapExpressionUntil(expr, func(expr Expression) bool {
+		_, supported := expr.(asQuotedTraversal)
+		return supported
+	})
+
+	if asT, supported := physExpr.(asQuotedTraversal); supported {
+		traversal, diags := asT.AsQuotedTraversal()
+		if traversal != nil || diags.HasErrors() {
+			return traversal, diags
+		}
+	}
+	return AbsTraversalForExpr(expr)
+}
+
 // RelTraversalForExpr is similar to AbsTraversalForExpr but it returns
 // a relative traversal instead. Due to the nature of HCL expressions, the
 // first element of the returned traversal is always a TraverseAttr, and
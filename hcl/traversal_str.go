@@ -0,0 +1,121 @@
+package hcl
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// String returns a representation of the traversal in the native syntax,
+// such as a.b[0].c, for use in messages and as a lookup key.
+//
+// String index keys are rendered as quoted strings and number index keys
+// as decimal numbers. Keys of other types cannot be written in the native
+// traversal syntax and so are rendered as "...", as are unknown keys, so
+// the result is not guaranteed to be parseable as a traversal.
+func (t Traversal) String() string {
+	var buf bytes.Buffer
+	writeTraversal(&buf, t)
+	return buf.String()
+}
+
+func writeTraversal(buf *bytes.Buffer, t Traversal) {
+	for _, step := range t {
+		switch tStep := step.(type) {
+		case TraverseRoot:
+			buf.WriteString(tStep.Name)
+		case TraverseAttr:
+			buf.WriteByte('.')
+			buf.WriteString(tStep.Name)
+		case TraverseIndex:
+			buf.WriteByte('[')
+			buf.WriteString(traversalKeyString(tStep.Key))
+			buf.WriteByte(']')
+		case TraverseSplat:
+			buf.WriteString("[*]")
+			writeTraversal(buf, tStep.Each)
+		}
+	}
+}
+
+func traversalKeyString(key cty.Value) string {
+	switch {
+	case !key.IsKnown():
+		return "..."
+	case key.IsNull():
+		return "null"
+	case key.Type() == cty.String:
+		return quoteTraversalKey(key.AsString())
+	case key.Type() == cty.Number:
+		return key.AsBigFloat().Text('f', -1)
+	case key.Type() == cty.Bool:
+		if key.True() {
+			return "true"
+		}
+		return "false"
+	default:
+		return "..."
+	}
+}
+
+var traversalKeyReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+	"${", "$${",
+	"%{", "%%{",
+)
+
+func quoteTraversalKey(s string) string {
+	return `"` + traversalKeyReplacer.Replace(s) + `"`
+}
+
+// Equal returns true if the receiver and the given traversal have the same
+// steps, regardless of their source ranges.
+func (t Traversal) Equal(other Traversal) bool {
+	if len(t) != len(other) {
+		return false
+	}
+	return t.HasPrefix(other)
+}
+
+// HasPrefix returns true if the steps of the given traversal are the same as
+// the first steps of the receiver, regardless of their source ranges. A
+// traversal is a prefix of itself, and the empty traversal is a prefix of
+// all traversals.
+//
+// This can be used, for example, to determine whether a reference to a.b[0]
+// depends on a value that was assigned to a.b.
+func (t Traversal) HasPrefix(prefix Traversal) bool {
+	if len(prefix) > len(t) {
+		return false
+	}
+	for i, step := range prefix {
+		if !traverserEqual(t[i], step) {
+			return false
+		}
+	}
+	return true
+}
+
+func traverserEqual(a, b Traverser) bool {
+	switch ta := a.(type) {
+	case TraverseRoot:
+		tb, ok := b.(TraverseRoot)
+		return ok && ta.Name == tb.Name
+	case TraverseAttr:
+		tb, ok := b.(TraverseAttr)
+		return ok && ta.Name == tb.Name
+	case TraverseIndex:
+		tb, ok := b.(TraverseIndex)
+		return ok && ta.Key.RawEquals(tb.Key)
+	case TraverseSplat:
+		tb, ok := b.(TraverseSplat)
+		return ok && ta.Each.Equal(tb.Each)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,134 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestTraversalString(t *testing.T) {
+	tests := []struct {
+		Traversal Traversal
+		Want      string
+	}{
+		{
+			nil,
+			``,
+		},
+		{
+			Traversal{
+				TraverseRoot{Name: "a"},
+				TraverseAttr{Name: "b"},
+				TraverseIndex{Key: cty.NumberIntVal(0)},
+				TraverseAttr{Name: "c"},
+			},
+			`a.b[0].c`,
+		},
+		{
+			Traversal{
+				TraverseRoot{Name: "a"},
+				TraverseIndex{Key: cty.StringVal("b \"${c}\"\n")},
+				TraverseIndex{Key: cty.NumberFloatVal(1.5)},
+			},
+			`a["b \"$${c}\"\n"][1.5]`,
+		},
+		{
+			Traversal{
+				TraverseAttr{Name: "b"},
+				TraverseIndex{Key: cty.UnknownVal(cty.String)},
+				TraverseIndex{Key: cty.ListValEmpty(cty.String)},
+			},
+			`.b[...][...]`,
+		},
+		{
+			Traversal{
+				TraverseRoot{Name: "a"},
+				TraverseSplat{
+					Each: Traversal{
+						TraverseAttr{Name: "id"},
+					},
+				},
+			},
+			`a[*].id`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Want, func(t *testing.T) {
+			got := test.Traversal.String()
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestTraversalHasPrefix(t *testing.T) {
+	rng := Range{Filename: "test.hcl", Start: Pos{Line: 2, Column: 1, Byte: 10}}
+	full := Traversal{
+		TraverseRoot{Name: "a", SrcRange: rng},
+		TraverseAttr{Name: "b", SrcRange: rng},
+		TraverseIndex{Key: cty.NumberIntVal(0), SrcRange: rng},
+	}
+
+	tests := []struct {
+		Prefix    Traversal
+		WantPre   bool
+		WantEqual bool
+	}{
+		{
+			nil,
+			true, false,
+		},
+		{
+			Traversal{
+				TraverseRoot{Name: "a"},
+				TraverseAttr{Name: "b"},
+			},
+			true, false,
+		},
+		{
+			Traversal{
+				TraverseRoot{Name: "a"},
+				TraverseAttr{Name: "b"},
+				TraverseIndex{Key: cty.NumberIntVal(0)},
+			},
+			true, true,
+		},
+		{
+			Traversal{
+				TraverseRoot{Name: "a"},
+				TraverseAttr{Name: "b"},
+				TraverseIndex{Key: cty.StringVal("0")},
+			},
+			false, false,
+		},
+		{
+			Traversal{
+				TraverseRoot{Name: "a"},
+				TraverseIndex{Key: cty.StringVal("b")},
+			},
+			false, false,
+		},
+		{
+			Traversal{
+				TraverseRoot{Name: "a"},
+				TraverseAttr{Name: "b"},
+				TraverseIndex{Key: cty.NumberIntVal(0)},
+				TraverseAttr{Name: "c"},
+			},
+			false, false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Prefix.String(), func(t *testing.T) {
+			if got := full.HasPrefix(test.Prefix); got != test.WantPre {
+				t.Errorf("wrong HasPrefix result %t; want %t", got, test.WantPre)
+			}
+			if got := full.Equal(test.Prefix); got != test.WantEqual {
+				t.Errorf("wrong Equal result %t; want %t", got, test.WantEqual)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestTraversalStepDiagnostics(t *testing.T) {
+	rng := func(start, end int) Range {
+		return Range{
+			Filename: "test.hcl",
+			Start:    Pos{Line: 1, Column: start + 1, Byte: start},
+			End:      Pos{Line: 1, Column: end + 1, Byte: end},
+		}
+	}
+	ctx := &EvalContext{
+		Variables: map[string]cty.Value{
+			"obj": cty.ObjectVal(map[string]cty.Value{
+				"null": cty.NullVal(cty.DynamicPseudoType),
+				"list": cty.ListVal([]cty.Value{cty.True, cty.False}),
+				"map":  cty.MapVal(map[string]cty.Value{"a": cty.True}),
+			}),
+			"secret": cty.ListVal([]cty.Value{cty.True}),
+		},
+		SensitiveVariables: map[string]bool{
+			"secret": true,
+		},
+	}
+
+	tests := []struct {
+		traversal   Traversal
+		wantSubject Range
+		wantDetail  string
+	}{
+		{
+			// obj.null.foo
+			Traversal{
+				TraverseRoot{Name: "obj", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "null", SrcRange: rng(3, 8)},
+				TraverseAttr{Name: "foo", SrcRange: rng(8, 12)},
+			},
+			rng(8, 12),
+			`The value of obj.null is null, so it does not have any attributes.`,
+		},
+		{
+			// obj.null[0]
+			Traversal{
+				TraverseRoot{Name: "obj", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "null", SrcRange: rng(3, 8)},
+				TraverseIndex{Key: cty.NumberIntVal(0), SrcRange: rng(8, 11)},
+			},
+			rng(8, 11),
+			`The value of obj.null is null, so it does not have any indices.`,
+		},
+		{
+			// obj.list[2]
+			Traversal{
+				TraverseRoot{Name: "obj", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "list", SrcRange: rng(3, 8)},
+				TraverseIndex{Key: cty.NumberIntVal(2), SrcRange: rng(8, 11)},
+			},
+			rng(8, 11),
+			`The given key does not identify an element in the collection obj.list: the index 2 is out of range for a sequence of 2 elements.`,
+		},
+		{
+			// obj.map.b
+			Traversal{
+				TraverseRoot{Name: "obj", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "map", SrcRange: rng(3, 7)},
+				TraverseAttr{Name: "b", SrcRange: rng(7, 9)},
+			},
+			rng(7, 9),
+			`The map obj.map does not have an element with the key "b".`,
+		},
+		{
+			// obj.missing
+			Traversal{
+				TraverseRoot{Name: "obj", SrcRange: rng(0, 3)},
+				TraverseAttr{Name: "missing", SrcRange: rng(3, 11)},
+			},
+			rng(3, 11),
+			`The object obj does not have an attribute named "missing".`,
+		},
+		{
+			// secret[1]
+			Traversal{
+				TraverseRoot{Name: "secret", SrcRange: rng(0, 6)},
+				TraverseIndex{Key: cty.NumberIntVal(1), SrcRange: rng(6, 9)},
+			},
+			rng(6, 9),
+			`The given key does not identify an element in the collection secret.`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.traversal.String(), func(t *testing.T) {
+			_, diags := test.traversal.TraverseAbs(ctx)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.Error())
+			}
+			if got := *diags[0].Subject; got != test.wantSubject {
+				t.Errorf("wrong subject %s; want %s", got, test.wantSubject)
+			}
+			if got := diags[0].Detail; got != test.wantDetail {
+				t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, test.wantDetail)
+			}
+		})
+	}
+
+	// Relative traversals don't know the path of the value they start from.
+	_, diags := Traversal{TraverseAttr{Name: "foo"}}.TraverseRel(cty.NullVal(cty.DynamicPseudoType))
+	if got, want := diags[0].Detail, `This value is null, so it does not have any attributes.`; got != want {
+		t.Errorf("wrong detail for relative traversal\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestTraversalUnknownForMissing(t *testing.T) {
+	parent := &EvalContext{
+		Variables: map[string]cty.Value{
+			"obj": cty.ObjectVal(map[string]cty.Value{
+				"list":  cty.ListVal([]cty.Value{cty.True}),
+				"map":   cty.MapVal(map[string]cty.Value{"a": cty.StringVal("x")}),
+				"tuple": cty.EmptyTupleVal,
+				"null":  cty.NullVal(cty.DynamicPseudoType),
+			}),
+		},
+		UnknownForMissing: true,
+	}
+	ctx := parent.NewChild()
+
+	tests := []struct {
+		traversal Traversal
+		want      cty.Value
+		wantErr   bool
+	}{
+		{
+			Traversal{TraverseRoot{Name: "missing"}, TraverseAttr{Name: "foo"}},
+			cty.DynamicVal,
+			false,
+		},
+		{
+			Traversal{TraverseRoot{Name: "obj"}, TraverseAttr{Name: "missing"}},
+			cty.DynamicVal,
+			false,
+		},
+		{
+			Traversal{TraverseRoot{Name: "obj"}, TraverseAttr{Name: "list"}, TraverseIndex{Key: cty.NumberIntVal(5)}},
+			cty.UnknownVal(cty.Bool),
+			false,
+		},
+		{
+			Traversal{TraverseRoot{Name: "obj"}, TraverseAttr{Name: "map"}, TraverseAttr{Name: "b"}},
+			cty.UnknownVal(cty.String),
+			false,
+		},
+		{
+			Traversal{TraverseRoot{Name: "obj"}, TraverseAttr{Name: "map"}, TraverseIndex{Key: cty.StringVal("b")}},
+			cty.UnknownVal(cty.String),
+			false,
+		},
+		{
+			Traversal{TraverseRoot{Name: "obj"}, TraverseAttr{Name: "tuple"}, TraverseIndex{Key: cty.NumberIntVal(0)}},
+			cty.DynamicVal,
+			false,
+		},
+		{
+			// Null values are not missing, so this is still an error.
+			Traversal{TraverseRoot{Name: "obj"}, TraverseAttr{Name: "null"}, TraverseAttr{Name: "foo"}},
+			cty.DynamicVal,
+			true,
+		},
+		{
+			// Type errors are still errors.
+			Traversal{TraverseRoot{Name: "obj"}, TraverseAttr{Name: "list"}, TraverseAttr{Name: "foo"}},
+			cty.DynamicVal,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.traversal.String(), func(t *testing.T) {
+			got, diags := test.traversal.TraverseAbs(ctx)
+			if diags.HasErrors() != test.wantErr {
+				t.Fatalf("wrong error result %t; want %t\n%s", diags.HasErrors(), test.wantErr, diags.Error())
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result %#v; want %#v", got, test.want)
+			}
+		})
+	}
+
+	got, diags := ctx.Index(cty.ListValEmpty(cty.Number), cty.NumberIntVal(0), nil)
+	if diags.HasErrors() || !got.RawEquals(cty.UnknownVal(cty.Number)) {
+		t.Errorf("wrong result from Index: %#v, %s", got, diags.Error())
+	}
+	got, diags = ctx.TraverseRel(Traversal{TraverseIndex{Key: cty.NumberIntVal(0)}}, cty.ListValEmpty(cty.Number))
+	if diags.HasErrors() || !got.RawEquals(cty.UnknownVal(cty.Number)) {
+		t.Errorf("wrong result from TraverseRel: %#v, %s", got, diags.Error())
+	}
+	if _, diags := Index(cty.ListValEmpty(cty.Number), cty.NumberIntVal(0), nil); !diags.HasErrors() {
+		t.Errorf("package-level Index is unknown-tolerant")
+	}
+}
@@ -24,6 +24,24 @@ func decode(body hcl.Body, blockLabels []blockLabel, ctx *hcl.EvalContext, spec
 	return val, leftovers, diags
 }
 
+// decodeBlock decodes the body of the given block, which has the given
+// labels, reporting it to the instrumentation of the given context.
+func decodeBlock(block *hcl.Block, blockLabels []blockLabel, ctx *hcl.EvalContext, spec Spec) (cty.Value, hcl.Diagnostics) {
+	finish := ctx.StartDecodeBlock(block)
+	val, _, diags := decode(block.Body, blockLabels, ctx, spec, false)
+	finish(diags)
+	return val, diags
+}
+
+// evalExpr evaluates the given expression, reporting it to the
+// instrumentation of the given context.
+func evalExpr(expr hcl.Expression, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	finish := ctx.StartEvalExpression(expr)
+	val, diags := expr.Value(ctx)
+	finish(diags)
+	return val, diags
+}
+
 func impliedType(spec Spec) cty.Type {
 	return spec.impliedType()
 }
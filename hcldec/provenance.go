@@ -0,0 +1,188 @@
+package hcldec
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// DecodeWithProvenance is like Decode except that it also returns the
+// provenance of the values within the result, recording where each of them
+// came from.
+//
+// The paths in the provenance refer to the returned value. There is an entry
+// for the value of each attribute decoded by an AttrSpec, whose range is that
+// of the attribute's expression, and for each block label decoded by a
+// BlockLabelSpec. The values in a block decoded by BlockAttrsSpec have
+// entries for each of the elements of the resulting map.
+//
+// A value produced by a TransformExprSpec or TransformFuncSpec has a single
+// entry for the whole value rather than for its elements, since the
+// structure of a transformed value is not known. Values that do not come
+// from the body, such as those of a LiteralSpec or ExprSpec, have no entry.
+//
+// Elements of a set produced by BlockSetSpec are identified by their values,
+// as is usual for cty paths. Because the nested spec is decoded a second time
+// to find these values, their entries are incorrect if decoding the nested
+// spec does not always produce the same value, such as if it calls impure
+// functions.
+func DecodeWithProvenance(body hcl.Body, spec Spec, ctx *hcl.EvalContext) (cty.Value, hcl.Provenance, hcl.Diagnostics) {
+	val, diags := Decode(body, spec, ctx)
+	var prov hcl.Provenance
+	bodyProvenance(&prov, body, nil, spec, ctx, nil)
+	return val, prov, diags
+}
+
+func bodyProvenance(prov *hcl.Provenance, body hcl.Body, blockLabels []blockLabel, spec Spec, ctx *hcl.EvalContext, path cty.Path) {
+	content, _, _ := body.PartialContent(ImpliedSchema(spec))
+	contentProvenance(prov, content, blockLabels, spec, ctx, path)
+}
+
+func contentProvenance(prov *hcl.Provenance, content *hcl.BodyContent, blockLabels []blockLabel, spec Spec, ctx *hcl.EvalContext, path cty.Path) {
+	switch s := spec.(type) {
+	case *ObjectSpec:
+		contentProvenance(prov, content, blockLabels, *s, ctx, path)
+
+	case *TupleSpec:
+		contentProvenance(prov, content, blockLabels, *s, ctx, path)
+
+	case ObjectSpec:
+		keys := make([]string, 0, len(s))
+		for k := range s {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			contentProvenance(prov, content, blockLabels, s[k], ctx, path.GetAttr(k))
+		}
+
+	case TupleSpec:
+		for i, child := range s {
+			contentProvenance(prov, content, blockLabels, child, ctx, path.Index(cty.NumberIntVal(int64(i))))
+		}
+
+	case *AttrSpec:
+		if attr, exists := content.Attributes[s.Name]; exists {
+			prov.Add(path, attr.Expr.Range())
+		}
+
+	case *BlockLabelSpec:
+		if s.Index < len(blockLabels) {
+			prov.Add(path, blockLabels[s.Index].Range)
+		}
+
+	case *DefaultSpec:
+		// The default is used only if the primary spec produces null, which
+		// we approximate as it finding nothing in the body.
+		count := len(*prov)
+		contentProvenance(prov, content, blockLabels, s.Primary, ctx, path)
+		if len(*prov) == count {
+			contentProvenance(prov, content, blockLabels, s.Default, ctx, path)
+		}
+
+	case *TransformExprSpec:
+		transformProvenance(prov, content, blockLabels, s.Wrapped, ctx, path)
+
+	case *TransformFuncSpec:
+		transformProvenance(prov, content, blockLabels, s.Wrapped, ctx, path)
+
+	case *BlockSpec:
+		for _, childBlock := range content.Blocks {
+			if childBlock.Type == s.TypeName {
+				bodyProvenance(prov, childBlock.Body, labelsForBlock(childBlock), s.Nested, ctx, path)
+				break
+			}
+		}
+
+	case *BlockListSpec:
+		blocksProvenance(prov, content, s.TypeName, s.Nested, ctx, path)
+
+	case *BlockTupleSpec:
+		blocksProvenance(prov, content, s.TypeName, s.Nested, ctx, path)
+
+	case *BlockSetSpec:
+		for _, childBlock := range content.Blocks {
+			if childBlock.Type != s.TypeName {
+				continue
+			}
+			val, _, _ := decode(childBlock.Body, labelsForBlock(childBlock), ctx, s.Nested, false)
+			bodyProvenance(prov, childBlock.Body, labelsForBlock(childBlock), s.Nested, ctx, path.Index(val))
+		}
+
+	case *BlockMapSpec:
+		labeledBlocksProvenance(prov, content, s.TypeName, len(s.LabelNames), s.Nested, ctx, path, func(path cty.Path, label string) cty.Path {
+			return path.Index(cty.StringVal(label))
+		})
+
+	case *BlockObjectSpec:
+		labeledBlocksProvenance(prov, content, s.TypeName, len(s.LabelNames), s.Nested, ctx, path, func(path cty.Path, label string) cty.Path {
+			return path.GetAttr(label)
+		})
+
+	case *BlockAttrsSpec:
+		block, _ := s.findBlock(content)
+		if block == nil {
+			return
+		}
+		attrs, _ := block.Body.JustAttributes()
+		names := make([]string, 0, len(attrs))
+		for name := range attrs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prov.Add(path.Index(cty.StringVal(name)), attrs[name].Expr.Range())
+		}
+	}
+}
+
+// transformProvenance adds a single entry for the value of a transform spec
+// that wraps the given spec, if the wrapped spec's value comes from the body.
+func transformProvenance(prov *hcl.Provenance, content *hcl.BodyContent, blockLabels []blockLabel, wrapped Spec, ctx *hcl.EvalContext, path cty.Path) {
+	var inner hcl.Provenance
+	contentProvenance(&inner, content, blockLabels, wrapped, ctx, path)
+	if len(inner) > 0 {
+		prov.Add(path, wrapped.sourceRange(content, blockLabels))
+	}
+}
+
+// blocksProvenance adds the entries for the blocks of the given type, which
+// are decoded into a sequence in the order they appear in the body.
+func blocksProvenance(prov *hcl.Provenance, content *hcl.BodyContent, typeName string, nested Spec, ctx *hcl.EvalContext, path cty.Path) {
+	i := 0
+	for _, childBlock := range content.Blocks {
+		if childBlock.Type != typeName {
+			continue
+		}
+		bodyProvenance(prov, childBlock.Body, labelsForBlock(childBlock), nested, ctx, path.Index(cty.NumberIntVal(int64(i))))
+		i++
+	}
+}
+
+// labeledBlocksProvenance adds the entries for the blocks of the given type,
+// which are decoded into nested maps or objects keyed by their first
+// labelCount labels. The given function adds the step for each label to a
+// path. As when decoding, only the first of several blocks with the same
+// labels is used.
+func labeledBlocksProvenance(prov *hcl.Provenance, content *hcl.BodyContent, typeName string, labelCount int, nested Spec, ctx *hcl.EvalContext, path cty.Path, step func(cty.Path, string) cty.Path) {
+	seen := make(map[string]struct{})
+	for _, childBlock := range content.Blocks {
+		if childBlock.Type != typeName || len(childBlock.Labels) < labelCount {
+			continue
+		}
+		labelsKey := strings.Join(childBlock.Labels[:labelCount], "\x00")
+		if _, exists := seen[labelsKey]; exists {
+			continue
+		}
+		seen[labelsKey] = struct{}{}
+
+		childPath := path
+		for _, label := range childBlock.Labels[:labelCount] {
+			childPath = step(childPath, label)
+		}
+		childLabels := labelsForBlock(childBlock)
+		bodyProvenance(prov, childBlock.Body, childLabels[labelCount:], nested, ctx, childPath)
+	}
+}
@@ -0,0 +1,228 @@
+package hcldec
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecodeWithProvenance(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		spec      Spec
+		want      []string
+		diagCount int
+	}{
+		{
+			"attributes",
+			"a = 1\nb = \"x\"\n",
+			&ObjectSpec{
+				"a": &AttrSpec{Name: "a", Type: cty.Number},
+				"b": &DefaultSpec{
+					Primary: &AttrSpec{Name: "b", Type: cty.String},
+					Default: &LiteralSpec{Value: cty.StringVal("d")},
+				},
+				"c": &DefaultSpec{
+					Primary: &AttrSpec{Name: "c", Type: cty.String},
+					Default: &LiteralSpec{Value: cty.StringVal("d")},
+				},
+				"d": &LiteralSpec{Value: cty.True},
+			},
+			[]string{
+				".a test.hcl:1,5-6",
+				".b test.hcl:2,5-8",
+			},
+			0,
+		},
+		{
+			"tuple",
+			"a = 1\n",
+			TupleSpec{
+				&LiteralSpec{Value: cty.True},
+				&AttrSpec{Name: "a", Type: cty.Number},
+			},
+			[]string{
+				"[1] test.hcl:1,5-6",
+			},
+			0,
+		},
+		{
+			"block list with labels",
+			"b \"x\" {\n  v = 1\n}\nb \"y\" {\n  v = 2\n}\n",
+			ObjectSpec{
+				"bs": &BlockListSpec{
+					TypeName: "b",
+					Nested: ObjectSpec{
+						"name": &BlockLabelSpec{Index: 0, Name: "name"},
+						"v":    &AttrSpec{Name: "v", Type: cty.Number},
+					},
+				},
+			},
+			[]string{
+				".bs[0].name test.hcl:1,3-6",
+				".bs[0].v test.hcl:2,7-8",
+				".bs[1].name test.hcl:4,3-6",
+				".bs[1].v test.hcl:5,7-8",
+			},
+			0,
+		},
+		{
+			"single block",
+			"b {\n  v = 1\n}\n",
+			&BlockSpec{
+				TypeName: "b",
+				Nested:   &AttrSpec{Name: "v", Type: cty.Number},
+			},
+			[]string{
+				" test.hcl:2,7-8",
+			},
+			0,
+		},
+		{
+			"block set",
+			"b {\n  v = 1\n}\n",
+			&BlockSetSpec{
+				TypeName: "b",
+				Nested: ObjectSpec{
+					"v": &AttrSpec{Name: "v", Type: cty.Number},
+				},
+			},
+			[]string{
+				"[cty.ObjectVal(map[string]cty.Value{\"v\":cty.NumberIntVal(1)})].v test.hcl:2,7-8",
+			},
+			0,
+		},
+		{
+			"block map",
+			"b \"x\" \"y\" {\n  v = 1\n}\nb \"x\" \"y\" {\n  v = 2\n}\n",
+			&BlockMapSpec{
+				TypeName:   "b",
+				LabelNames: []string{"first", "second"},
+				Nested: ObjectSpec{
+					"v": &AttrSpec{Name: "v", Type: cty.Number},
+				},
+			},
+			[]string{
+				// The second block is a duplicate, and so is not used.
+				"[\"x\"][\"y\"].v test.hcl:2,7-8",
+			},
+			1, // duplicate block
+		},
+		{
+			"block object",
+			"b \"x\" \"y\" {\n}\n",
+			&BlockObjectSpec{
+				TypeName:   "b",
+				LabelNames: []string{"first"},
+				Nested: ObjectSpec{
+					"second": &BlockLabelSpec{Index: 0, Name: "second"},
+				},
+			},
+			[]string{
+				".x.second test.hcl:1,7-10",
+			},
+			0,
+		},
+		{
+			"block attrs",
+			"b {\n  y = 1\n  x = 2\n}\n",
+			&BlockAttrsSpec{
+				TypeName:    "b",
+				ElementType: cty.Number,
+			},
+			[]string{
+				"[\"x\"] test.hcl:3,7-8",
+				"[\"y\"] test.hcl:2,7-8",
+			},
+			0,
+		},
+		{
+			"transform",
+			"a = [1, 2]\n",
+			&TransformExprSpec{
+				Wrapped: &AttrSpec{Name: "a", Type: cty.List(cty.Number)},
+				Expr:    hcl.StaticExpr(cty.True, hcl.Range{}),
+				VarName: "v",
+			},
+			[]string{
+				" test.hcl:1,5-11",
+			},
+			0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.config), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+
+			_, prov, diags := DecodeWithProvenance(file.Body, test.spec, nil)
+			if len(diags) != test.diagCount {
+				t.Errorf("wrong number of diagnostics %d; want %d\n%s", len(diags), test.diagCount, diags.Error())
+			}
+
+			got := make([]string, len(prov))
+			for i, entry := range prov {
+				got[i] = fmt.Sprintf("%s %s", testPathString(entry.Path), entry.Range)
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestDecodeWithProvenanceMerged(t *testing.T) {
+	fileA, _ := hclsyntax.ParseConfig([]byte("a = 1\n"), "a.hcl", hcl.Pos{Line: 1, Column: 1})
+	fileB, _ := hclsyntax.ParseConfig([]byte("\nb = 2\n"), "b.hcl", hcl.Pos{Line: 1, Column: 1})
+	body := hcl.MergeFiles([]*hcl.File{fileA, fileB})
+
+	spec := ObjectSpec{
+		"a": &AttrSpec{Name: "a", Type: cty.Number},
+		"b": &AttrSpec{Name: "b", Type: cty.Number},
+	}
+	_, prov, diags := DecodeWithProvenance(body, spec, nil)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	for name, want := range map[string]string{"a": "a.hcl:1,5-6", "b": "b.hcl:2,5-6"} {
+		rng, ok := prov.Range(cty.GetAttrPath(name))
+		if !ok {
+			t.Errorf("no provenance for %q", name)
+			continue
+		}
+		if got := rng.String(); got != want {
+			t.Errorf("wrong range for %q %s; want %s", name, got, want)
+		}
+	}
+}
+
+// testPathString returns a compact representation of the given path for
+// comparing in tests.
+func testPathString(path cty.Path) string {
+	var buf strings.Builder
+	for _, step := range path {
+		switch ts := step.(type) {
+		case cty.GetAttrStep:
+			fmt.Fprintf(&buf, ".%s", ts.Name)
+		case cty.IndexStep:
+			switch ts.Key.Type() {
+			case cty.String:
+				fmt.Fprintf(&buf, "[%q]", ts.Key.AsString())
+			case cty.Number:
+				fmt.Fprintf(&buf, "[%s]", ts.Key.AsBigFloat().String())
+			default:
+				fmt.Fprintf(&buf, "[%#v]", ts.Key)
+			}
+		}
+	}
+	return buf.String()
+}
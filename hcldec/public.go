@@ -20,6 +20,12 @@ func Decode(body hcl.Body, spec Spec, ctx *hcl.EvalContext) (cty.Value, hcl.Diag
 // the top-level body, which are returned as a new body to allow for
 // further processing.
 //
+// This allows an application to decode a body in multiple phases, where
+// each phase decodes only the portion of the body it is concerned with and
+// passes the returned body on to the next phase. The final phase should
+// then use Decode, so that any items not consumed by any phase will produce
+// error diagnostics.
+//
 // Any descendent block bodies are _not_ decoded partially and thus must
 // be fully described by the given specification.
 func PartialDecode(body hcl.Body, spec Spec, ctx *hcl.EvalContext) (cty.Value, hcl.Body, hcl.Diagnostics) {
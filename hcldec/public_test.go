@@ -3,6 +3,7 @@ package hcldec
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/hcl2/hcl"
@@ -1017,6 +1018,28 @@ b "bar" {
 			}),
 			0,
 		},
+		{
+			"a = 1\n",
+			&AttrSpec{
+				Name:       "a",
+				Type:       cty.Number,
+				Deprecated: "Use b instead.",
+			},
+			nil,
+			cty.NumberIntVal(1),
+			1, // attribute "a" is deprecated
+		},
+		{
+			"b {\n}\nb {\n}\n",
+			&BlockListSpec{
+				TypeName:   "b",
+				Nested:     &ObjectSpec{},
+				Deprecated: "Use c instead.",
+			},
+			nil,
+			cty.ListVal([]cty.Value{cty.EmptyObjectVal, cty.EmptyObjectVal}),
+			2, // each block of type "b" is deprecated
+		},
 	}
 
 	for i, test := range tests {
@@ -1043,6 +1066,92 @@ b "bar" {
 	}
 }
 
+func TestPartialDecode(t *testing.T) {
+	config := `
+a = 1
+b = "two"
+
+c {
+  d = true
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(config), "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+
+	// The first phase consumes only the "a" attribute, leaving everything
+	// else for the second phase.
+	firstSpec := ObjectSpec{
+		"a": &AttrSpec{
+			Name: "a",
+			Type: cty.Number,
+		},
+	}
+	got, remain, diags := PartialDecode(file.Body, firstSpec, nil)
+	if len(diags) != 0 {
+		t.Errorf("unexpected diagnostics in first phase")
+		for _, diag := range diags {
+			t.Logf(" - %s", diag.Error())
+		}
+	}
+	want := cty.ObjectVal(map[string]cty.Value{
+		"a": cty.NumberIntVal(1),
+	})
+	if !got.RawEquals(want) {
+		t.Errorf("wrong first phase result\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if remain == nil {
+		t.Fatalf("no remaining body after first phase")
+	}
+
+	// The second phase consumes the remainder, so a full Decode must
+	// succeed without any "unsupported argument" errors.
+	secondSpec := ObjectSpec{
+		"b": &AttrSpec{
+			Name: "b",
+			Type: cty.String,
+		},
+		"c": &BlockSpec{
+			TypeName: "c",
+			Nested: &AttrSpec{
+				Name: "d",
+				Type: cty.Bool,
+			},
+		},
+	}
+	got, diags = Decode(remain, secondSpec, nil)
+	if len(diags) != 0 {
+		t.Errorf("unexpected diagnostics in second phase")
+		for _, diag := range diags {
+			t.Logf(" - %s", diag.Error())
+		}
+	}
+	want = cty.ObjectVal(map[string]cty.Value{
+		"b": cty.StringVal("two"),
+		"c": cty.True,
+	})
+	if !got.RawEquals(want) {
+		t.Errorf("wrong second phase result\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	// The attribute consumed by the first phase is no longer visible in
+	// the remaining body.
+	got, _, diags = PartialDecode(remain, firstSpec, nil)
+	if len(diags) != 0 {
+		t.Errorf("unexpected diagnostics re-decoding remainder")
+		for _, diag := range diags {
+			t.Logf(" - %s", diag.Error())
+		}
+	}
+	want = cty.ObjectVal(map[string]cty.Value{
+		"a": cty.NullVal(cty.Number),
+	})
+	if !got.RawEquals(want) {
+		t.Errorf("wrong result re-decoding remainder\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
 func TestSourceRange(t *testing.T) {
 	tests := []struct {
 		config string
@@ -1120,3 +1229,38 @@ b {
 	}
 
 }
+
+func TestDecodeDuplicateBlocksAcrossFiles(t *testing.T) {
+	fileA, _ := hclsyntax.ParseConfig([]byte("b \"foo\" {\n}\n"), "a.hcl", hcl.Pos{Line: 1, Column: 1})
+	fileB, _ := hclsyntax.ParseConfig([]byte("b \"bar\" {\n}\nb \"foo\" {\n}\n"), "b.hcl", hcl.Pos{Line: 1, Column: 1})
+	body := hcl.MergeFiles([]*hcl.File{fileA, fileB})
+
+	specs := map[string]Spec{
+		"BlockMapSpec": &BlockMapSpec{
+			TypeName:   "b",
+			LabelNames: []string{"name"},
+			Nested:     &ObjectSpec{},
+		},
+		"BlockObjectSpec": &BlockObjectSpec{
+			TypeName:   "b",
+			LabelNames: []string{"name"},
+			Nested:     &ObjectSpec{},
+		},
+	}
+
+	for name, spec := range specs {
+		t.Run(name, func(t *testing.T) {
+			_, diags := Decode(body, spec, nil)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1", len(diags))
+			}
+			diag := diags[0]
+			if got, want := diag.Subject.Filename, "b.hcl"; got != want {
+				t.Errorf("wrong subject filename %q; want %q", got, want)
+			}
+			if want := "already defined at a.hcl:1,1-8."; !strings.Contains(diag.Detail, want) {
+				t.Errorf("detail %q does not mention previous definition %q", diag.Detail, want)
+			}
+		})
+	}
+}
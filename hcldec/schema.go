@@ -34,3 +34,32 @@ func ImpliedSchema(spec Spec) *hcl.BodySchema {
 		Blocks:     blocks,
 	}
 }
+
+// ImpliedNestedSpecs returns the specs that will be used to decode the bodies
+// of the blocks in the schema implied by the given spec, keyed by block type
+// name. Block types whose bodies are interpreted directly as attributes, as
+// with BlockAttrsSpec, are not included.
+//
+// Together with ImpliedSchema, this allows a caller to discover the schema
+// of an entire configuration tree, for example to provide editor support.
+func ImpliedNestedSpecs(spec Spec) map[string]Spec {
+	ret := make(map[string]Spec)
+
+	var visit visitFunc
+	visit = func(s Spec) {
+		if bs, ok := s.(blockSpec); ok {
+			nested := bs.nestedSpec()
+			if _, noop := nested.(noopSpec); nested != nil && !noop {
+				for _, hs := range bs.blockHeaderSchemata() {
+					ret[hs.Type] = nested
+				}
+			}
+		}
+
+		s.visitSameBodyChildren(visit)
+	}
+
+	visit(spec)
+
+	return ret
+}
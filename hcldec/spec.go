@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/hashicorp/hcl2/hcl"
 	"github.com/zclconf/go-cty/cty"
@@ -150,6 +151,11 @@ type AttrSpec struct {
 	Name     string
 	Type     cty.Type
 	Required bool
+
+	// Deprecated, if set, marks the attribute as deprecated, so that
+	// decoding a body that defines it produces a warning that includes this
+	// message. See the field of the same name in hcl.AttributeSchema.
+	Deprecated string
 }
 
 func (s *AttrSpec) visitSameBodyChildren(cb visitFunc) {
@@ -170,8 +176,9 @@ func (s *AttrSpec) variablesNeeded(content *hcl.BodyContent) []hcl.Traversal {
 func (s *AttrSpec) attrSchemata() []hcl.AttributeSchema {
 	return []hcl.AttributeSchema{
 		{
-			Name:     s.Name,
-			Required: s.Required,
+			Name:       s.Name,
+			Required:   s.Required,
+			Deprecated: s.Deprecated,
 		},
 	}
 }
@@ -193,7 +200,7 @@ func (s *AttrSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ct
 		return cty.NullVal(s.Type), nil
 	}
 
-	val, diags := attr.Expr.Value(ctx)
+	val, diags := evalExpr(attr.Expr, ctx)
 
 	convVal, err := convert.Convert(val, s.Type)
 	if err != nil {
@@ -263,7 +270,7 @@ func (s *ExprSpec) variablesNeeded(content *hcl.BodyContent) []hcl.Traversal {
 }
 
 func (s *ExprSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
-	return s.Expr.Value(ctx)
+	return evalExpr(s.Expr, ctx)
 }
 
 func (s *ExprSpec) impliedType() cty.Type {
@@ -285,6 +292,12 @@ type BlockSpec struct {
 	TypeName string
 	Nested   Spec
 	Required bool
+
+	// Deprecated, if set, marks the block type as deprecated, so that
+	// decoding a body that contains blocks of this type produces a warning
+	// that includes this message. See the field of the same name in
+	// hcl.BlockHeaderSchema.
+	Deprecated string
 }
 
 func (s *BlockSpec) visitSameBodyChildren(cb visitFunc) {
@@ -297,6 +310,8 @@ func (s *BlockSpec) blockHeaderSchemata() []hcl.BlockHeaderSchema {
 		{
 			Type:       s.TypeName,
 			LabelNames: findLabelSpecs(s.Nested),
+
+			Deprecated: s.Deprecated,
 		},
 	}
 }
@@ -367,7 +382,7 @@ func (s *BlockSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel, c
 	if s.Nested == nil {
 		panic("BlockSpec with no Nested Spec")
 	}
-	val, _, childDiags := decode(childBlock.Body, labelsForBlock(childBlock), ctx, s.Nested, false)
+	val, childDiags := decodeBlock(childBlock, labelsForBlock(childBlock), ctx, s.Nested)
 	diags = append(diags, childDiags...)
 	return val, diags
 }
@@ -401,6 +416,9 @@ type BlockListSpec struct {
 	Nested   Spec
 	MinItems int
 	MaxItems int
+
+	// Deprecated is as for BlockSpec.
+	Deprecated string
 }
 
 func (s *BlockListSpec) visitSameBodyChildren(cb visitFunc) {
@@ -413,6 +431,8 @@ func (s *BlockListSpec) blockHeaderSchemata() []hcl.BlockHeaderSchema {
 		{
 			Type:       s.TypeName,
 			LabelNames: findLabelSpecs(s.Nested),
+
+			Deprecated: s.Deprecated,
 		},
 	}
 }
@@ -451,7 +471,7 @@ func (s *BlockListSpec) decode(content *hcl.BodyContent, blockLabels []blockLabe
 			continue
 		}
 
-		val, _, childDiags := decode(childBlock.Body, labelsForBlock(childBlock), ctx, s.Nested, false)
+		val, childDiags := decodeBlock(childBlock, labelsForBlock(childBlock), ctx, s.Nested)
 		diags = append(diags, childDiags...)
 		elems = append(elems, val)
 		sourceRanges = append(sourceRanges, sourceRange(childBlock.Body, labelsForBlock(childBlock), s.Nested))
@@ -558,6 +578,9 @@ type BlockTupleSpec struct {
 	Nested   Spec
 	MinItems int
 	MaxItems int
+
+	// Deprecated is as for BlockSpec.
+	Deprecated string
 }
 
 func (s *BlockTupleSpec) visitSameBodyChildren(cb visitFunc) {
@@ -570,6 +593,8 @@ func (s *BlockTupleSpec) blockHeaderSchemata() []hcl.BlockHeaderSchema {
 		{
 			Type:       s.TypeName,
 			LabelNames: findLabelSpecs(s.Nested),
+
+			Deprecated: s.Deprecated,
 		},
 	}
 }
@@ -608,7 +633,7 @@ func (s *BlockTupleSpec) decode(content *hcl.BodyContent, blockLabels []blockLab
 			continue
 		}
 
-		val, _, childDiags := decode(childBlock.Body, labelsForBlock(childBlock), ctx, s.Nested, false)
+		val, childDiags := decodeBlock(childBlock, labelsForBlock(childBlock), ctx, s.Nested)
 		diags = append(diags, childDiags...)
 		elems = append(elems, val)
 		sourceRanges = append(sourceRanges, sourceRange(childBlock.Body, labelsForBlock(childBlock), s.Nested))
@@ -675,6 +700,9 @@ type BlockSetSpec struct {
 	Nested   Spec
 	MinItems int
 	MaxItems int
+
+	// Deprecated is as for BlockSpec.
+	Deprecated string
 }
 
 func (s *BlockSetSpec) visitSameBodyChildren(cb visitFunc) {
@@ -687,6 +715,8 @@ func (s *BlockSetSpec) blockHeaderSchemata() []hcl.BlockHeaderSchema {
 		{
 			Type:       s.TypeName,
 			LabelNames: findLabelSpecs(s.Nested),
+
+			Deprecated: s.Deprecated,
 		},
 	}
 }
@@ -725,7 +755,7 @@ func (s *BlockSetSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel
 			continue
 		}
 
-		val, _, childDiags := decode(childBlock.Body, labelsForBlock(childBlock), ctx, s.Nested, false)
+		val, childDiags := decodeBlock(childBlock, labelsForBlock(childBlock), ctx, s.Nested)
 		diags = append(diags, childDiags...)
 		elems = append(elems, val)
 		sourceRanges = append(sourceRanges, sourceRange(childBlock.Body, labelsForBlock(childBlock), s.Nested))
@@ -830,6 +860,9 @@ type BlockMapSpec struct {
 	TypeName   string
 	LabelNames []string
 	Nested     Spec
+
+	// Deprecated is as for BlockSpec.
+	Deprecated string
 }
 
 func (s *BlockMapSpec) visitSameBodyChildren(cb visitFunc) {
@@ -842,6 +875,8 @@ func (s *BlockMapSpec) blockHeaderSchemata() []hcl.BlockHeaderSchema {
 		{
 			Type:       s.TypeName,
 			LabelNames: append(s.LabelNames, findLabelSpecs(s.Nested)...),
+
+			Deprecated: s.Deprecated,
 		},
 	}
 }
@@ -877,13 +912,14 @@ func (s *BlockMapSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel
 	}
 
 	elems := map[string]interface{}{}
+	defRanges := map[string]hcl.Range{}
 	for _, childBlock := range content.Blocks {
 		if childBlock.Type != s.TypeName {
 			continue
 		}
 
 		childLabels := labelsForBlock(childBlock)
-		val, _, childDiags := decode(childBlock.Body, childLabels[len(s.LabelNames):], ctx, s.Nested, false)
+		val, childDiags := decodeBlock(childBlock, childLabels[len(s.LabelNames):], ctx, s.Nested)
 		targetMap := elems
 		for _, key := range childBlock.Labels[:len(s.LabelNames)-1] {
 			if _, exists := targetMap[key]; !exists {
@@ -895,6 +931,7 @@ func (s *BlockMapSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel
 		diags = append(diags, childDiags...)
 
 		key := childBlock.Labels[len(s.LabelNames)-1]
+		labelsKey := strings.Join(childBlock.Labels[:len(s.LabelNames)], "\x00")
 		if _, exists := targetMap[key]; exists {
 			labelsBuf := bytes.Buffer{}
 			for _, label := range childBlock.Labels {
@@ -904,8 +941,8 @@ func (s *BlockMapSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel
 				Severity: hcl.DiagError,
 				Summary:  fmt.Sprintf("Duplicate %s block", s.TypeName),
 				Detail: fmt.Sprintf(
-					"A block for %s%s was already defined. The %s labels must be unique.",
-					s.TypeName, labelsBuf.String(), s.TypeName,
+					"A block for %s%s was already defined at %s. The %s labels must be unique.",
+					s.TypeName, labelsBuf.String(), defRanges[labelsKey].String(), s.TypeName,
 				),
 				Subject: &childBlock.DefRange,
 			})
@@ -913,6 +950,7 @@ func (s *BlockMapSpec) decode(content *hcl.BodyContent, blockLabels []blockLabel
 		}
 
 		targetMap[key] = val
+		defRanges[labelsKey] = childBlock.DefRange
 	}
 
 	if len(elems) == 0 {
@@ -979,6 +1017,9 @@ type BlockObjectSpec struct {
 	TypeName   string
 	LabelNames []string
 	Nested     Spec
+
+	// Deprecated is as for BlockSpec.
+	Deprecated string
 }
 
 func (s *BlockObjectSpec) visitSameBodyChildren(cb visitFunc) {
@@ -991,6 +1032,8 @@ func (s *BlockObjectSpec) blockHeaderSchemata() []hcl.BlockHeaderSchema {
 		{
 			Type:       s.TypeName,
 			LabelNames: append(s.LabelNames, findLabelSpecs(s.Nested)...),
+
+			Deprecated: s.Deprecated,
 		},
 	}
 }
@@ -1023,13 +1066,14 @@ func (s *BlockObjectSpec) decode(content *hcl.BodyContent, blockLabels []blockLa
 	}
 
 	elems := map[string]interface{}{}
+	defRanges := map[string]hcl.Range{}
 	for _, childBlock := range content.Blocks {
 		if childBlock.Type != s.TypeName {
 			continue
 		}
 
 		childLabels := labelsForBlock(childBlock)
-		val, _, childDiags := decode(childBlock.Body, childLabels[len(s.LabelNames):], ctx, s.Nested, false)
+		val, childDiags := decodeBlock(childBlock, childLabels[len(s.LabelNames):], ctx, s.Nested)
 		targetMap := elems
 		for _, key := range childBlock.Labels[:len(s.LabelNames)-1] {
 			if _, exists := targetMap[key]; !exists {
@@ -1041,6 +1085,7 @@ func (s *BlockObjectSpec) decode(content *hcl.BodyContent, blockLabels []blockLa
 		diags = append(diags, childDiags...)
 
 		key := childBlock.Labels[len(s.LabelNames)-1]
+		labelsKey := strings.Join(childBlock.Labels[:len(s.LabelNames)], "\x00")
 		if _, exists := targetMap[key]; exists {
 			labelsBuf := bytes.Buffer{}
 			for _, label := range childBlock.Labels {
@@ -1050,8 +1095,8 @@ func (s *BlockObjectSpec) decode(content *hcl.BodyContent, blockLabels []blockLa
 				Severity: hcl.DiagError,
 				Summary:  fmt.Sprintf("Duplicate %s block", s.TypeName),
 				Detail: fmt.Sprintf(
-					"A block for %s%s was already defined. The %s labels must be unique.",
-					s.TypeName, labelsBuf.String(), s.TypeName,
+					"A block for %s%s was already defined at %s. The %s labels must be unique.",
+					s.TypeName, labelsBuf.String(), defRanges[labelsKey].String(), s.TypeName,
 				),
 				Subject: &childBlock.DefRange,
 			})
@@ -1059,6 +1104,7 @@ func (s *BlockObjectSpec) decode(content *hcl.BodyContent, blockLabels []blockLa
 		}
 
 		targetMap[key] = val
+		defRanges[labelsKey] = childBlock.DefRange
 	}
 
 	if len(elems) == 0 {
@@ -1129,6 +1175,9 @@ type BlockAttrsSpec struct {
 	TypeName    string
 	ElementType cty.Type
 	Required    bool
+
+	// Deprecated is as for BlockSpec.
+	Deprecated string
 }
 
 func (s *BlockAttrsSpec) visitSameBodyChildren(cb visitFunc) {
@@ -1141,6 +1190,8 @@ func (s *BlockAttrsSpec) blockHeaderSchemata() []hcl.BlockHeaderSchema {
 		{
 			Type:       s.TypeName,
 			LabelNames: nil,
+
+			Deprecated: s.Deprecated,
 		},
 	}
 }
@@ -1221,7 +1272,7 @@ func (s *BlockAttrsSpec) decode(content *hcl.BodyContent, blockLabels []blockLab
 
 	vals := make(map[string]cty.Value, len(attrs))
 	for name, attr := range attrs {
-		attrVal, attrDiags := attr.Expr.Value(ctx)
+		attrVal, attrDiags := evalExpr(attr.Expr, ctx)
 		diags = append(diags, attrDiags...)
 
 		attrVal, err := convert.Convert(attrVal, s.ElementType)
@@ -139,3 +139,23 @@ bar = barval
 		}
 	})
 }
+
+func TestImpliedNestedSpecs(t *testing.T) {
+	inner := &AttrSpec{Name: "port", Type: cty.Number}
+	spec := ObjectSpec{
+		"name": &AttrSpec{Name: "name", Type: cty.String},
+		"service": &DefaultSpec{
+			Primary: &BlockListSpec{TypeName: "service", Nested: inner},
+			Default: &LiteralSpec{Value: cty.ListValEmpty(cty.Number)},
+		},
+		"tags": &BlockAttrsSpec{TypeName: "tags", ElementType: cty.String},
+	}
+
+	got := ImpliedNestedSpecs(spec)
+	want := map[string]Spec{
+		"service": inner,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
@@ -0,0 +1,248 @@
+package specfile
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecode(t *testing.T) {
+	tests := map[string]struct {
+		specSrc   string
+		inputSrc  string
+		want      cty.Value
+		diagCount int
+	}{
+		"attr": {
+			`
+attr {
+  name     = "name"
+  type     = string
+  required = true
+}
+`,
+			`name = "foo"`,
+			cty.StringVal("foo"),
+			0,
+		},
+		"object with block list": {
+			`
+object {
+  attr "name" {
+    type = string
+  }
+  block_list "service" {
+    object {
+      attr "port" {
+        type = number
+      }
+    }
+  }
+}
+`,
+			`
+name = "foo"
+service {
+  port = 80
+}
+service {
+  port = 443
+}
+`,
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("foo"),
+				"service": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"port": cty.NumberIntVal(80),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"port": cty.NumberIntVal(443),
+					}),
+				}),
+			}),
+			0,
+		},
+		"block_attrs": {
+			`
+block_attrs {
+  block_type   = "tags"
+  element_type = string
+}
+`,
+			`
+tags {
+  a = "b"
+}
+`,
+			cty.MapVal(map[string]cty.Value{
+				"a": cty.StringVal("b"),
+			}),
+			0,
+		},
+		"variables and literal": {
+			`
+variables {
+  greeting = "hello"
+}
+
+default {
+  attr {
+    name = "name"
+    type = string
+  }
+  literal {
+    value = upper("anonymous")
+  }
+}
+`,
+			``,
+			cty.StringVal("ANONYMOUS"),
+			0,
+		},
+		"block_list with label": {
+			`
+block_list {
+  block_type = "route"
+
+  object {
+    label "name" {
+      index = 0
+    }
+    attr "path" {
+      type = string
+    }
+  }
+}
+`,
+			`
+route "home" {
+  path = "/"
+}
+route "about" {
+  path = "/about"
+}
+`,
+			cty.ListVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal("home"),
+					"path": cty.StringVal("/"),
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal("about"),
+					"path": cty.StringVal("/about"),
+				}),
+			}),
+			0,
+		},
+		"label outside of block": {
+			`
+object {
+  label "name" {
+  }
+}
+`,
+			``,
+			cty.NullVal(cty.DynamicPseudoType),
+			1, // Invalid label spec
+		},
+		"missing root spec": {
+			``,
+			``,
+			cty.NullVal(cty.DynamicPseudoType),
+			1, // Missing spec block
+		},
+		"invalid type": {
+			`
+attr {
+  name = "name"
+  type = 5
+}
+`,
+			``,
+			cty.NullVal(cty.DynamicPseudoType),
+			1, // Invalid type expression
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			specFile, diags := hclsyntax.ParseConfig([]byte(test.specSrc), "spec.hcldec", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected spec parse diagnostics: %s", diags.Error())
+			}
+
+			f, diags := Decode(specFile.Body)
+			if len(diags) != test.diagCount {
+				t.Errorf("wrong number of diagnostics %d; want %d", len(diags), test.diagCount)
+				for _, diag := range diags {
+					t.Logf(" - %s", diag.Error())
+				}
+			}
+			if diags.HasErrors() {
+				return
+			}
+
+			inputFile, diags := hclsyntax.ParseConfig([]byte(test.inputSrc), "input.hcl", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected input parse diagnostics: %s", diags.Error())
+			}
+
+			ctx := &hcl.EvalContext{
+				Variables: f.Variables,
+				Functions: f.Functions,
+			}
+			got, diags := hcldec.Decode(inputFile.Body, f.RootSpec, ctx)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected decode diagnostics: %s", diags.Error())
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodeDeprecated(t *testing.T) {
+	specSrc := `
+object {
+  attr "name" {
+    type       = string
+    deprecated = "Use title instead."
+  }
+  block_list "service" {
+    deprecated = "Use services instead."
+    object {}
+  }
+}
+`
+	specFile, diags := hclsyntax.ParseConfig([]byte(specSrc), "spec.hcldec", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected spec parse diagnostics: %s", diags.Error())
+	}
+	f, diags := Decode(specFile.Body)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected spec diagnostics: %s", diags.Error())
+	}
+
+	inputFile, diags := hclsyntax.ParseConfig([]byte("name = \"foo\"\nservice {\n}\n"), "input.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected input parse diagnostics: %s", diags.Error())
+	}
+	_, diags = hcldec.Decode(inputFile.Body, f.RootSpec, nil)
+
+	want := []string{
+		`input.hcl:1,1-5: Deprecated argument; The argument "name" is deprecated. Use title instead.`,
+		`input.hcl:2,1-8: Deprecated block type; Blocks of type "service" are deprecated. Use services instead.`,
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("wrong number of diagnostics %d; want %d\n%s", len(diags), len(want), diags.Error())
+	}
+	for i, diag := range diags {
+		if got := diag.Error(); got != want[i] {
+			t.Errorf("wrong diagnostic %d\ngot:  %s\nwant: %s", i, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,21 @@
+// Package specfile implements a loader for hcldec specifications that are
+// themselves written in HCL, allowing an application to describe the
+// structure of its configuration without writing Go code.
+//
+// The spec file format is documented in detail in the documentation for the
+// hcldec command line tool, which is the primary consumer of this package:
+//
+//     object {
+//       attr "name" {
+//         type     = string
+//         required = true
+//       }
+//       block_list "service" {
+//         object {
+//           attr "port" {
+//             type = number
+//           }
+//         }
+//       }
+//     }
+package specfile
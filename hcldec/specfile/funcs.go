@@ -1,4 +1,4 @@
-package main
+package specfile
 
 import (
 	"github.com/zclconf/go-cty/cty/function"
@@ -0,0 +1,266 @@
+package hcldoc
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/hashicorp/hcl2/ext/typeexpr"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcldec"
+	"github.com/hashicorp/hcl2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Body describes the attributes and nested blocks that are expected in a
+// body.
+type Body struct {
+	Attributes []*Attribute `json:"attributes,omitempty"`
+	Blocks     []*Block     `json:"blocks,omitempty"`
+}
+
+// Attribute describes an attribute that is expected in a body.
+type Attribute struct {
+	Name string `json:"name"`
+
+	// Path identifies the attribute within the whole configuration, as
+	// described for Descriptions.
+	Path string `json:"path"`
+
+	// Type is the type of the attribute's value in type expression syntax,
+	// as accepted by the typeexpr extension, or empty if the type is not
+	// known, as for attributes described by a BodySchema.
+	Type string `json:"type,omitempty"`
+
+	Required bool `json:"required"`
+
+	// Default is the value used when the attribute is not set, in native
+	// syntax, or empty if there is no default value or it is not known.
+	Default string `json:"default,omitempty"`
+
+	Deprecated  string `json:"deprecated,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Block describes a type of nested block that is expected in a body.
+type Block struct {
+	TypeName string `json:"type_name"`
+
+	// Path identifies the block type within the whole configuration, as
+	// described for Descriptions.
+	Path string `json:"path"`
+
+	LabelNames []string `json:"labels,omitempty"`
+	Nesting    Nesting  `json:"nesting"`
+	Required   bool     `json:"required"`
+
+	// MinItems and MaxItems are the limits on the number of blocks of this
+	// type, where zero means no limit. These are used only for list and set
+	// nesting.
+	MinItems int `json:"min_items,omitempty"`
+	MaxItems int `json:"max_items,omitempty"`
+
+	// ElementType is the type of the attributes in the block's body in type
+	// expression syntax, used only for NestingAttrs.
+	ElementType string `json:"element_type,omitempty"`
+
+	Deprecated  string `json:"deprecated,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// Body describes the content of the blocks. It is nil for NestingAttrs,
+	// and for block types whose content is not known.
+	Body *Body `json:"body,omitempty"`
+}
+
+// Nesting describes how the blocks of a particular type are combined in the
+// decoded result.
+type Nesting string
+
+const (
+	// NestingSingle means that at most one block of the type is allowed.
+	NestingSingle Nesting = "single"
+
+	// NestingList and NestingSet mean that any number of blocks of the type
+	// are allowed, possibly within the limits given by MinItems and MaxItems.
+	NestingList Nesting = "list"
+	NestingSet  Nesting = "set"
+
+	// NestingMap means that any number of blocks of the type are allowed,
+	// each identified by a unique set of labels.
+	NestingMap Nesting = "map"
+
+	// NestingAttrs means that at most one block of the type is allowed, and
+	// that its body contains arbitrary attributes rather than a fixed schema.
+	NestingAttrs Nesting = "attrs"
+
+	// NestingUnknown is used for block types described by a BodySchema,
+	// which does not say how many blocks of each type are allowed.
+	NestingUnknown Nesting = "unknown"
+)
+
+// Descriptions provides the descriptions of the items in a configuration,
+// keyed by path. The path of an attribute or block type at the top level is
+// its name, while the path of an item within a block is the path of the
+// block type followed by a period and the name of the item, such as
+// "listener.port".
+type Descriptions map[string]string
+
+// FromSchema builds a Body describing the given schema. Since a schema does
+// not say anything about the content of nested blocks or the types of
+// attributes, the result includes only the names, labels and deprecation
+// messages of the items at the top level, along with their descriptions.
+//
+// The descriptions may be nil.
+func FromSchema(schema *hcl.BodySchema, descs Descriptions) *Body {
+	ret := &Body{}
+	for _, as := range schema.Attributes {
+		ret.Attributes = append(ret.Attributes, &Attribute{
+			Name:        as.Name,
+			Path:        as.Name,
+			Required:    as.Required,
+			Deprecated:  as.Deprecated,
+			Description: descs[as.Name],
+		})
+	}
+	for _, bs := range schema.Blocks {
+		ret.Blocks = append(ret.Blocks, &Block{
+			TypeName:    bs.Type,
+			Path:        bs.Type,
+			LabelNames:  bs.LabelNames,
+			Nesting:     NestingUnknown,
+			Deprecated:  bs.Deprecated,
+			Description: descs[bs.Type],
+		})
+	}
+	return ret
+}
+
+// FromSpec builds a Body describing the bodies that can be decoded by the
+// given spec, including the content of nested blocks.
+//
+// Attributes are described in the order they are found in the spec, except
+// that the items of an ObjectSpec are visited in order of their keys. The
+// default value of an attribute is known only if it is given by a
+// LiteralSpec within a DefaultSpec.
+//
+// The descriptions may be nil.
+func FromSpec(spec hcldec.Spec, descs Descriptions) *Body {
+	ret := &Body{}
+	addSpec(ret, spec, "", descs)
+	return ret
+}
+
+func addSpec(body *Body, spec hcldec.Spec, prefix string, descs Descriptions) {
+	switch s := spec.(type) {
+	case hcldec.ObjectSpec:
+		keys := make([]string, 0, len(s))
+		for k := range s {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			addSpec(body, s[k], prefix, descs)
+		}
+	case hcldec.TupleSpec:
+		for _, child := range s {
+			addSpec(body, child, prefix, descs)
+		}
+	case *hcldec.AttrSpec:
+		path := prefix + s.Name
+		body.Attributes = append(body.Attributes, &Attribute{
+			Name:        s.Name,
+			Path:        path,
+			Type:        typeString(s.Type),
+			Required:    s.Required,
+			Deprecated:  s.Deprecated,
+			Description: descs[path],
+		})
+	case *hcldec.DefaultSpec:
+		first := len(body.Attributes)
+		addSpec(body, s.Primary, prefix, descs)
+		lit, isLit := s.Default.(*hcldec.LiteralSpec)
+		if isLit && len(body.Attributes) == first+1 && lit.Value.IsWhollyKnown() && !lit.Value.IsNull() {
+			body.Attributes[first].Default = string(hclwrite.TokensForValue(lit.Value).Bytes())
+		}
+	case *hcldec.TransformExprSpec:
+		addSpec(body, s.Wrapped, prefix, descs)
+	case *hcldec.TransformFuncSpec:
+		addSpec(body, s.Wrapped, prefix, descs)
+	case *hcldec.BlockSpec:
+		block := addBlock(body, s, s.TypeName, NestingSingle, s.Nested, prefix, descs)
+		block.Required = s.Required
+		block.Deprecated = s.Deprecated
+	case *hcldec.BlockListSpec:
+		block := addBlock(body, s, s.TypeName, NestingList, s.Nested, prefix, descs)
+		block.Required = s.MinItems > 0
+		block.MinItems, block.MaxItems = s.MinItems, s.MaxItems
+		block.Deprecated = s.Deprecated
+	case *hcldec.BlockTupleSpec:
+		block := addBlock(body, s, s.TypeName, NestingList, s.Nested, prefix, descs)
+		block.Required = s.MinItems > 0
+		block.MinItems, block.MaxItems = s.MinItems, s.MaxItems
+		block.Deprecated = s.Deprecated
+	case *hcldec.BlockSetSpec:
+		block := addBlock(body, s, s.TypeName, NestingSet, s.Nested, prefix, descs)
+		block.Required = s.MinItems > 0
+		block.MinItems, block.MaxItems = s.MinItems, s.MaxItems
+		block.Deprecated = s.Deprecated
+	case *hcldec.BlockMapSpec:
+		block := addBlock(body, s, s.TypeName, NestingMap, s.Nested, prefix, descs)
+		block.Deprecated = s.Deprecated
+	case *hcldec.BlockObjectSpec:
+		block := addBlock(body, s, s.TypeName, NestingMap, s.Nested, prefix, descs)
+		block.Deprecated = s.Deprecated
+	case *hcldec.BlockAttrsSpec:
+		block := addBlock(body, s, s.TypeName, NestingAttrs, nil, prefix, descs)
+		block.Required = s.Required
+		block.ElementType = typeString(s.ElementType)
+		block.Deprecated = s.Deprecated
+	}
+
+	// Other specs, such as LiteralSpec and BlockLabelSpec, do not correspond
+	// to any attributes or blocks in the body.
+}
+
+func addBlock(body *Body, spec hcldec.Spec, typeName string, nesting Nesting, nested hcldec.Spec, prefix string, descs Descriptions) *Block {
+	path := prefix + typeName
+	block := &Block{
+		TypeName:    typeName,
+		Path:        path,
+		Nesting:     nesting,
+		Description: descs[path],
+	}
+
+	// The label names can come either from the spec itself or from the
+	// BlockLabelSpecs within its nested spec, so we let hcldec work them out.
+	if schema := hcldec.ImpliedSchema(spec); len(schema.Blocks) == 1 {
+		block.LabelNames = schema.Blocks[0].LabelNames
+	}
+
+	if nested != nil {
+		block.Body = &Body{}
+		addSpec(block.Body, nested, path+".", descs)
+	}
+	body.Blocks = append(body.Blocks, block)
+	return block
+}
+
+func typeString(ty cty.Type) string {
+	switch {
+	case ty == cty.NilType:
+		return ""
+	case ty.IsCapsuleType():
+		return ty.FriendlyName()
+	default:
+		return typeexpr.TypeString(ty)
+	}
+}
+
+// WriteJSON writes the body to the given writer as an indented JSON object,
+// using the field names given by the struct tags of Body and the types it
+// refers to.
+func (b *Body) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
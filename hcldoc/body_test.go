@@ -0,0 +1,264 @@
+package hcldoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestFromSchema(t *testing.T) {
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "name", Required: true},
+			{Name: "old", Deprecated: "Use name instead."},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "listener", LabelNames: []string{"protocol"}},
+		},
+	}
+	got := FromSchema(schema, Descriptions{
+		"name":     "The name of the server.",
+		"listener": "A port to listen on.",
+	})
+	want := &Body{
+		Attributes: []*Attribute{
+			{
+				Name:        "name",
+				Path:        "name",
+				Required:    true,
+				Description: "The name of the server.",
+			},
+			{
+				Name:       "old",
+				Path:       "old",
+				Deprecated: "Use name instead.",
+			},
+		},
+		Blocks: []*Block{
+			{
+				TypeName:    "listener",
+				Path:        "listener",
+				LabelNames:  []string{"protocol"},
+				Nesting:     NestingUnknown,
+				Description: "A port to listen on.",
+			},
+		},
+	}
+	for _, problem := range deep.Equal(got, want) {
+		t.Error(problem)
+	}
+}
+
+func TestFromSpec(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  hcldec.Spec
+		descs Descriptions
+		want  *Body
+	}{
+		{
+			"empty",
+			hcldec.ObjectSpec{},
+			nil,
+			&Body{},
+		},
+		{
+			"attributes in key order",
+			hcldec.ObjectSpec{
+				"b": &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: true},
+				"a": &hcldec.AttrSpec{Name: "tags", Type: cty.Map(cty.String), Deprecated: "Use labels."},
+				"c": &hcldec.LiteralSpec{Value: cty.True},
+			},
+			Descriptions{"name": "The name."},
+			&Body{
+				Attributes: []*Attribute{
+					{
+						Name:       "tags",
+						Path:       "tags",
+						Type:       "map(string)",
+						Deprecated: "Use labels.",
+					},
+					{
+						Name:        "name",
+						Path:        "name",
+						Type:        "string",
+						Required:    true,
+						Description: "The name.",
+					},
+				},
+			},
+		},
+		{
+			"defaults and transforms",
+			hcldec.TupleSpec{
+				&hcldec.DefaultSpec{
+					Primary: &hcldec.AttrSpec{Name: "port", Type: cty.Number},
+					Default: &hcldec.LiteralSpec{Value: cty.NumberIntVal(8080)},
+				},
+				&hcldec.DefaultSpec{
+					Primary: &hcldec.AttrSpec{Name: "host", Type: cty.String},
+					Default: &hcldec.ExprSpec{},
+				},
+				&hcldec.TransformFuncSpec{
+					Wrapped: &hcldec.AttrSpec{Name: "any", Type: cty.DynamicPseudoType},
+				},
+			},
+			nil,
+			&Body{
+				Attributes: []*Attribute{
+					{Name: "port", Path: "port", Type: "number", Default: "8080"},
+					{Name: "host", Path: "host", Type: "string"},
+					{Name: "any", Path: "any", Type: "any"},
+				},
+			},
+		},
+		{
+			"nested blocks",
+			hcldec.ObjectSpec{
+				"listeners": &hcldec.BlockListSpec{
+					TypeName: "listener",
+					MinItems: 1,
+					MaxItems: 3,
+					Nested: hcldec.ObjectSpec{
+						"protocol": &hcldec.BlockLabelSpec{Index: 0, Name: "protocol"},
+						"port":     &hcldec.AttrSpec{Name: "port", Type: cty.Number, Required: true},
+						"tls": &hcldec.BlockSpec{
+							TypeName: "tls",
+							Nested: hcldec.ObjectSpec{
+								"cert": &hcldec.AttrSpec{Name: "cert", Type: cty.String},
+							},
+						},
+					},
+				},
+				"env": &hcldec.BlockAttrsSpec{
+					TypeName:    "env",
+					ElementType: cty.String,
+				},
+				"users": &hcldec.BlockMapSpec{
+					TypeName:   "user",
+					LabelNames: []string{"name"},
+					Nested:     hcldec.ObjectSpec{},
+					Deprecated: "Use accounts.",
+				},
+			},
+			Descriptions{
+				"listener":          "A port to listen on.",
+				"listener.port":     "The port number.",
+				"listener.tls.cert": "The certificate.",
+			},
+			&Body{
+				Blocks: []*Block{
+					{
+						TypeName:    "env",
+						Path:        "env",
+						Nesting:     NestingAttrs,
+						ElementType: "string",
+					},
+					{
+						TypeName:    "listener",
+						Path:        "listener",
+						LabelNames:  []string{"protocol"},
+						Nesting:     NestingList,
+						Required:    true,
+						MinItems:    1,
+						MaxItems:    3,
+						Description: "A port to listen on.",
+						Body: &Body{
+							Attributes: []*Attribute{
+								{
+									Name:        "port",
+									Path:        "listener.port",
+									Type:        "number",
+									Required:    true,
+									Description: "The port number.",
+								},
+							},
+							Blocks: []*Block{
+								{
+									TypeName: "tls",
+									Path:     "listener.tls",
+									Nesting:  NestingSingle,
+									Body: &Body{
+										Attributes: []*Attribute{
+											{
+												Name:        "cert",
+												Path:        "listener.tls.cert",
+												Type:        "string",
+												Description: "The certificate.",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					{
+						TypeName:   "user",
+						Path:       "user",
+						LabelNames: []string{"name"},
+						Nesting:    NestingMap,
+						Deprecated: "Use accounts.",
+						Body:       &Body{},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := FromSpec(test.spec, test.descs)
+			for _, problem := range deep.Equal(got, test.want) {
+				t.Error(problem)
+			}
+		})
+	}
+}
+
+func TestBodyWriteJSON(t *testing.T) {
+	body := &Body{
+		Attributes: []*Attribute{
+			{Name: "port", Path: "port", Type: "number", Default: "8080"},
+		},
+		Blocks: []*Block{
+			{TypeName: "tls", Path: "tls", Nesting: NestingSingle, Body: &Body{}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := body.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var got interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %s\n%s", err, buf.Bytes())
+	}
+	want := map[string]interface{}{
+		"attributes": []interface{}{
+			map[string]interface{}{
+				"name":     "port",
+				"path":     "port",
+				"type":     "number",
+				"required": false,
+				"default":  "8080",
+			},
+		},
+		"blocks": []interface{}{
+			map[string]interface{}{
+				"type_name": "tls",
+				"path":      "tls",
+				"nesting":   "single",
+				"required":  false,
+				"body":      map[string]interface{}{},
+			},
+		},
+	}
+	for _, problem := range deep.Equal(got, want) {
+		t.Error(problem)
+	}
+}
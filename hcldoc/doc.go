@@ -0,0 +1,10 @@
+// Package hcldoc generates reference documentation for a configuration
+// language from the schema that an application uses to decode it, so that
+// the documentation cannot drift from the schema as the application changes.
+//
+// FromSchema and FromSpec build a Body, which describes the attributes and
+// nested blocks that a body may contain. Since schemas do not record any
+// prose, the caller can provide a description of each item, keyed by its
+// path. The resulting Body can then be rendered as Markdown, using
+// WriteMarkdown, or marshalled to JSON for processing by other tools.
+package hcldoc
@@ -0,0 +1,146 @@
+package hcldoc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes Markdown documentation for the body to the given
+// writer. The attributes are written as a bulleted list, followed by a
+// section for each block type whose heading is at the given level, from 1
+// to 6. The sections of nested block types use deeper headings, up to the
+// deepest level that Markdown supports.
+func (b *Body) WriteMarkdown(w io.Writer, headingLevel int) error {
+	bw := bufio.NewWriter(w)
+	writeMarkdownBody(bw, b, headingLevel)
+	return bw.Flush()
+}
+
+func writeMarkdownBody(w *bufio.Writer, b *Body, level int) {
+	if len(b.Attributes) > 0 {
+		for _, attr := range b.Attributes {
+			writeMarkdownAttribute(w, attr)
+		}
+		w.WriteString("\n")
+	}
+
+	for _, block := range b.Blocks {
+		writeMarkdownBlock(w, block, level)
+	}
+}
+
+func writeMarkdownAttribute(w *bufio.Writer, attr *Attribute) {
+	var props []string
+	if attr.Type != "" {
+		props = append(props, markdownCode(attr.Type))
+	}
+	if attr.Required {
+		props = append(props, "required")
+	} else {
+		props = append(props, "optional")
+	}
+	if attr.Default != "" {
+		props = append(props, "default "+markdownCode(attr.Default))
+	}
+
+	fmt.Fprintf(w, "* %s (%s)", markdownCode(attr.Name), strings.Join(props, ", "))
+	if attr.Description != "" {
+		fmt.Fprintf(w, ": %s", attr.Description)
+	}
+	if attr.Deprecated != "" {
+		fmt.Fprintf(w, " **Deprecated:** %s", attr.Deprecated)
+	}
+	w.WriteString("\n")
+}
+
+func writeMarkdownBlock(w *bufio.Writer, block *Block, level int) {
+	if level < 1 {
+		level = 1
+	}
+	if level > 6 {
+		level = 6
+	}
+
+	header := block.TypeName
+	for _, name := range block.LabelNames {
+		header += fmt.Sprintf(" %q", name)
+	}
+	fmt.Fprintf(w, "%s %s block\n\n", strings.Repeat("#", level), markdownCode(header))
+
+	if block.Deprecated != "" {
+		fmt.Fprintf(w, "**Deprecated:** %s\n\n", block.Deprecated)
+	}
+	if block.Description != "" {
+		fmt.Fprintf(w, "%s\n\n", block.Description)
+	}
+	if usage := blockUsage(block); usage != "" {
+		fmt.Fprintf(w, "%s\n\n", usage)
+	}
+
+	if block.Body != nil {
+		writeMarkdownBody(w, block.Body, level+1)
+	}
+}
+
+// blockUsage returns a sentence describing how many blocks of the given type
+// are allowed, and what they contain if that is not described separately.
+func blockUsage(block *Block) string {
+	switch block.Nesting {
+	case NestingSingle:
+		if block.Required {
+			return "Exactly one block of this type is required."
+		}
+		return "At most one block of this type is allowed."
+	case NestingList, NestingSet:
+		switch {
+		case block.MinItems > 0 && block.MaxItems > 0:
+			return fmt.Sprintf("At least %d and at most %s of this type are allowed.", block.MinItems, blockCount(block.MaxItems))
+		case block.MinItems > 0:
+			return fmt.Sprintf("At least %s of this type %s required.", blockCount(block.MinItems), pluralVerb(block.MinItems))
+		case block.MaxItems > 0:
+			return fmt.Sprintf("At most %s of this type %s allowed.", blockCount(block.MaxItems), pluralVerb(block.MaxItems))
+		}
+		return "Any number of blocks of this type are allowed."
+	case NestingMap:
+		return "Any number of blocks of this type are allowed, each with a unique set of labels."
+	case NestingAttrs:
+		count := "At most one block of this type is allowed."
+		if block.Required {
+			count = "Exactly one block of this type is required."
+		}
+		if block.ElementType == "" {
+			return count + " Its body may contain any attributes."
+		}
+		return fmt.Sprintf("%s Its body may contain any attributes of type %s.", count, markdownCode(block.ElementType))
+	}
+	return ""
+}
+
+func blockCount(n int) string {
+	if n == 1 {
+		return "1 block"
+	}
+	return fmt.Sprintf("%d blocks", n)
+}
+
+func pluralVerb(n int) string {
+	if n == 1 {
+		return "is"
+	}
+	return "are"
+}
+
+// markdownCode returns the given text as a Markdown code span, using enough
+// backticks to delimit any backticks within the text.
+func markdownCode(s string) string {
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
@@ -0,0 +1,115 @@
+package hcldoc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBodyWriteMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  *Body
+		level int
+		want  string
+	}{
+		{
+			"empty",
+			&Body{},
+			2,
+			"",
+		},
+		{
+			"attributes",
+			&Body{
+				Attributes: []*Attribute{
+					{Name: "name", Type: "string", Required: true, Description: "The name."},
+					{Name: "port", Type: "number", Default: "8080"},
+					{Name: "old", Deprecated: "Use name instead."},
+				},
+			},
+			2,
+			"* `name` (`string`, required): The name.\n" +
+				"* `port` (`number`, optional, default `8080`)\n" +
+				"* `old` (optional) **Deprecated:** Use name instead.\n" +
+				"\n",
+		},
+		{
+			"nested blocks",
+			&Body{
+				Blocks: []*Block{
+					{
+						TypeName:    "listener",
+						LabelNames:  []string{"protocol"},
+						Nesting:     NestingList,
+						MinItems:    1,
+						Description: "A port to listen on.",
+						Body: &Body{
+							Attributes: []*Attribute{
+								{Name: "port", Type: "number", Required: true},
+							},
+							Blocks: []*Block{
+								{
+									TypeName: "tls",
+									Nesting:  NestingSingle,
+									Required: true,
+									Body:     &Body{},
+								},
+							},
+						},
+					},
+					{
+						TypeName:    "env",
+						Nesting:     NestingAttrs,
+						ElementType: "string",
+						Deprecated:  "Use variables.",
+					},
+				},
+			},
+			6,
+			"###### `listener \"protocol\"` block\n" +
+				"\n" +
+				"A port to listen on.\n" +
+				"\n" +
+				"At least 1 block of this type is required.\n" +
+				"\n" +
+				"* `port` (`number`, required)\n" +
+				"\n" +
+				"###### `tls` block\n" +
+				"\n" +
+				"Exactly one block of this type is required.\n" +
+				"\n" +
+				"###### `env` block\n" +
+				"\n" +
+				"**Deprecated:** Use variables.\n" +
+				"\n" +
+				"At most one block of this type is allowed. Its body may contain any attributes of type `string`.\n" +
+				"\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := test.body.WriteMarkdown(&buf, test.level); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownCode(t *testing.T) {
+	tests := map[string]string{
+		"foo":      "`foo`",
+		"a`b":      "``a`b``",
+		"`a`":      "`` `a` ``",
+		"map(any)": "`map(any)`",
+	}
+	for input, want := range tests {
+		if got := markdownCode(input); got != want {
+			t.Errorf("wrong result for %q: got %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package hcled
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// CompletionKind describes what sort of item a Completion would insert.
+type CompletionKind int
+
+const (
+	// CompletionAttribute is an attribute that may be defined in the body
+	// at the completion position.
+	CompletionAttribute CompletionKind = iota + 1
+
+	// CompletionBlock is a block type that may be used in the body at the
+	// completion position.
+	CompletionBlock
+
+	// CompletionVariable is a variable, or an attribute or key within the
+	// value of a variable, that may be referenced in an expression.
+	CompletionVariable
+
+	// CompletionFunction is a function that may be called in an expression.
+	CompletionFunction
+)
+
+// Completion is a candidate for insertion at a particular position in a file.
+type Completion struct {
+	// Label is the name of the candidate, as it should be shown to the user.
+	Label string
+
+	// Kind describes what sort of item the candidate is.
+	Kind CompletionKind
+
+	// Detail is a short, optional, additional description of the candidate,
+	// such as a function signature or a type.
+	Detail string
+
+	// InsertText is the text that should replace the Replace range of the
+	// file if the candidate is selected.
+	InsertText string
+
+	// Replace is the range of the file that would be replaced by the
+	// candidate, which covers the partial name already typed at the
+	// position, if any.
+	Replace hcl.Range
+}
+
+// Completions returns completion candidates for the given position in the
+// given file, which must have been parsed from native syntax with its source
+// code retained. The result is nil for files in other syntaxes.
+//
+// Within a body, the candidates are the attributes and block types from the
+// given schema that are not yet used, matching the partial name before the
+// position. Within an attribute's expression, the candidates are the
+// variables and functions in the given EvalContext, or the attributes of a
+// variable's value when the position follows a traversal such as "var.".
+// Either the schema or the EvalContext may be nil.
+//
+// The candidates are sorted by label.
+func Completions(file *hcl.File, pos hcl.Pos, schema *Schema, ctx *hcl.EvalContext) []Completion {
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok || file.Bytes == nil || pos.Byte < 0 || pos.Byte > len(file.Bytes) {
+		return nil
+	}
+
+	for _, block := range bodyPathAt(body, pos.Byte) {
+		body = block.Body
+		schema = schema.blockSchema(block.Type)
+	}
+
+	lineStart := bytes.LastIndexByte(file.Bytes[:pos.Byte], '\n') + 1
+	linePrefix := string(file.Bytes[lineStart:pos.Byte])
+	partial := partialTraversal(linePrefix)
+	replace := hcl.Range{
+		Filename: body.SrcRange.Filename,
+		Start: hcl.Pos{
+			Line:   pos.Line,
+			Column: pos.Column - len(partial),
+			Byte:   pos.Byte - len(partial),
+		},
+		End: pos,
+	}
+
+	var ret []Completion
+	if strings.Contains(linePrefix, "=") {
+		ret = expressionCompletions(partial, replace, ctx)
+	} else if strings.TrimSpace(linePrefix) == partial && !strings.Contains(partial, ".") {
+		ret = bodyCompletions(body, partial, replace, schema)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Label < ret[j].Label
+	})
+	return ret
+}
+
+func bodyCompletions(body *hclsyntax.Body, partial string, replace hcl.Range, schema *Schema) []Completion {
+	if schema == nil || schema.Body == nil {
+		return nil
+	}
+
+	var ret []Completion
+	for _, attrS := range schema.Body.Attributes {
+		if _, defined := body.Attributes[attrS.Name]; defined {
+			continue
+		}
+		if !strings.HasPrefix(attrS.Name, partial) {
+			continue
+		}
+		ret = append(ret, Completion{
+			Label:      attrS.Name,
+			Kind:       CompletionAttribute,
+			InsertText: attrS.Name + " = ",
+			Replace:    replace,
+		})
+	}
+	for _, blockS := range schema.Body.Blocks {
+		if !strings.HasPrefix(blockS.Type, partial) {
+			continue
+		}
+		insert := blockS.Type
+		for range blockS.LabelNames {
+			insert += ` ""`
+		}
+		insert += " {\n}"
+		ret = append(ret, Completion{
+			Label:      blockS.Type,
+			Kind:       CompletionBlock,
+			Detail:     strings.Join(blockS.LabelNames, " "),
+			InsertText: insert,
+			Replace:    replace,
+		})
+	}
+	return ret
+}
+
+func expressionCompletions(partial string, replace hcl.Range, ctx *hcl.EvalContext) []Completion {
+	if ctx == nil {
+		return nil
+	}
+
+	if dot := strings.LastIndexByte(partial, '.'); dot >= 0 {
+		// We're completing an attribute within a traversal, so we'll only
+		// replace the final step.
+		replace.Start.Byte += dot + 1
+		replace.Start.Column += dot + 1
+		return traversalCompletions(partial[:dot], partial[dot+1:], replace, ctx)
+	}
+
+	var ret []Completion
+	for name, val := range ctx.EffectiveVariables() {
+		if !strings.HasPrefix(name, partial) {
+			continue
+		}
+		ret = append(ret, Completion{
+			Label:      name,
+			Kind:       CompletionVariable,
+			Detail:     val.Type().FriendlyName(),
+			InsertText: name,
+			Replace:    replace,
+		})
+	}
+	for name := range ctx.EffectiveFunctions() {
+		if !strings.HasPrefix(name, partial) {
+			continue
+		}
+		var detail string
+		if sig, ok := ctx.FunctionSignature(name); ok {
+			detail = sig.String()
+		}
+		ret = append(ret, Completion{
+			Label:      name,
+			Kind:       CompletionFunction,
+			Detail:     detail,
+			InsertText: name + "(",
+			Replace:    replace,
+		})
+	}
+	return ret
+}
+
+func traversalCompletions(base, partial string, replace hcl.Range, ctx *hcl.EvalContext) []Completion {
+	steps := strings.Split(base, ".")
+	val, exists := ctx.Variable(steps[0])
+	if !exists {
+		return nil
+	}
+	for _, step := range steps[1:] {
+		val = attrValue(val, step)
+		if val == cty.NilVal {
+			return nil
+		}
+	}
+	if val.IsNull() || !val.IsKnown() {
+		return nil
+	}
+	ty := val.Type()
+	if !(ty.IsObjectType() || ty.IsMapType()) {
+		return nil
+	}
+
+	var ret []Completion
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		name := k.AsString()
+		if !strings.HasPrefix(name, partial) || !hclsyntax.ValidIdentifier(name) {
+			continue
+		}
+		ret = append(ret, Completion{
+			Label:      name,
+			Kind:       CompletionVariable,
+			Detail:     v.Type().FriendlyName(),
+			InsertText: name,
+			Replace:    replace,
+		})
+	}
+	return ret
+}
+
+// attrValue returns the value of the attribute or map element of the given
+// name within the given value, or cty.NilVal if there is no such attribute.
+func attrValue(val cty.Value, name string) cty.Value {
+	if val.IsNull() || !val.IsKnown() {
+		return cty.NilVal
+	}
+	ty := val.Type()
+	switch {
+	case ty.IsObjectType():
+		if !ty.HasAttribute(name) {
+			return cty.NilVal
+		}
+		return val.GetAttr(name)
+	case ty.IsMapType():
+		key := cty.StringVal(name)
+		if val.HasIndex(key).False() {
+			return cty.NilVal
+		}
+		return val.Index(key)
+	default:
+		return cty.NilVal
+	}
+}
+
+// partialTraversal returns the suffix of the given line prefix that could be
+// the start of a traversal, like "foo" or "foo.bar.b".
+func partialTraversal(linePrefix string) string {
+	i := len(linePrefix)
+	for i > 0 {
+		c := linePrefix[i-1]
+		if c == '.' || c == '_' || c == '-' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			i--
+			continue
+		}
+		break
+	}
+	return strings.TrimLeft(linePrefix[i:], ".-0123456789")
+}
+
+// String returns a name for the completion kind, for use in debugging.
+func (k CompletionKind) String() string {
+	switch k {
+	case CompletionAttribute:
+		return "attribute"
+	case CompletionBlock:
+		return "block"
+	case CompletionVariable:
+		return "variable"
+	case CompletionFunction:
+		return "function"
+	default:
+		return fmt.Sprintf("CompletionKind(%d)", int(k))
+	}
+}
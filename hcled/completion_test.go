@@ -0,0 +1,140 @@
+package hcled
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestCompletions(t *testing.T) {
+	schema := &Schema{
+		Body: &hcl.BodySchema{
+			Attributes: []hcl.AttributeSchema{
+				{Name: "name"},
+				{Name: "namespace"},
+				{Name: "port"},
+			},
+			Blocks: []hcl.BlockHeaderSchema{
+				{Type: "service", LabelNames: []string{"name"}},
+			},
+		},
+		Blocks: map[string]*Schema{
+			"service": {
+				Body: &hcl.BodySchema{
+					Attributes: []hcl.AttributeSchema{
+						{Name: "image"},
+						{Name: "replicas"},
+					},
+				},
+			},
+		},
+	}
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var": cty.ObjectVal(map[string]cty.Value{
+				"region":   cty.StringVal("us"),
+				"replicas": cty.NumberIntVal(1),
+			}),
+			"version": cty.StringVal("1.0"),
+		},
+		Functions: map[string]function.Function{
+			"upper": stdlib.UpperFunc,
+		},
+	}
+
+	tests := map[string]struct {
+		src  string // "|" marks the cursor position
+		want []string
+	}{
+		"root body": {
+			"name = \"a\"\nna|\n",
+			[]string{"attribute namespace"},
+		},
+		"root body empty line": {
+			"name = \"a\"\n|\n",
+			[]string{"attribute namespace", "attribute port", "block service"},
+		},
+		"nested body": {
+			"service \"web\" {\n  r|\n}\n",
+			[]string{"attribute replicas"},
+		},
+		"expression": {
+			"name = v|\n",
+			[]string{"variable var", "variable version"},
+		},
+		"expression function": {
+			"name = u|\n",
+			[]string{"function upper"},
+		},
+		"traversal": {
+			"service \"web\" {\n  replicas = var.r|\n}\n",
+			[]string{"variable region", "variable replicas"},
+		},
+		"traversal unknown root": {
+			"name = nope.|\n",
+			nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			offset := strings.Index(test.src, "|")
+			src := test.src[:offset] + test.src[offset+1:]
+			file, _ := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			pos := posForOffset(src, offset)
+
+			var got []string
+			for _, c := range Completions(file, pos, schema, ctx) {
+				got = append(got, c.Kind.String()+" "+c.Label)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCompletionsDetail(t *testing.T) {
+	src := "service \"web\" {\n  image = var.re\n}\n"
+	file, _ := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	offset := strings.Index(src, "re\n") + 2
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var": cty.ObjectVal(map[string]cty.Value{
+				"region": cty.StringVal("us"),
+			}),
+		},
+	}
+
+	got := Completions(file, posForOffset(src, offset), nil, ctx)
+	want := []Completion{
+		{
+			Label:      "region",
+			Kind:       CompletionVariable,
+			Detail:     "string",
+			InsertText: "region",
+			Replace: hcl.Range{
+				Filename: "test.hcl",
+				Start:    hcl.Pos{Line: 2, Column: 15, Byte: 30},
+				End:      hcl.Pos{Line: 2, Column: 17, Byte: 32},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+// posForOffset returns the position of the given byte offset in the given
+// ASCII source.
+func posForOffset(src string, offset int) hcl.Pos {
+	line := strings.Count(src[:offset], "\n") + 1
+	col := offset - strings.LastIndex(src[:offset], "\n")
+	return hcl.Pos{Line: line, Column: col, Byte: offset}
+}
@@ -0,0 +1,137 @@
+package hcled
+
+import (
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Symbol describes where something that can be referred to by a traversal
+// is defined.
+type Symbol struct {
+	// Range is the range where the symbol is defined.
+	Range hcl.Range
+
+	// Children gives the definitions of symbols nested within this one,
+	// keyed by the attribute name or index key used to refer to them. Number
+	// index keys are written in decimal.
+	Children SymbolTable
+}
+
+// SymbolTable maps the names that traversals can begin with to the symbols
+// they refer to. An application builds a symbol table from whatever parts of
+// its configuration can be referred to.
+type SymbolTable map[string]*Symbol
+
+// Define adds a symbol defined at the given range to the table, at the
+// given path of names. Any symbols along the path that are not yet defined
+// are created without a range, so that, for example, labeled blocks can be
+// defined as:
+//
+//     symbols.Define([]string{"service", block.Labels[0]}, block.DefRange)
+//
+// Define panics if the given path is empty.
+func (t SymbolTable) Define(path []string, rng hcl.Range) *Symbol {
+	if len(path) == 0 {
+		panic("Define with empty path")
+	}
+	sym := t[path[0]]
+	if sym == nil {
+		sym = &Symbol{}
+		t[path[0]] = sym
+	}
+	if len(path) == 1 {
+		sym.Range = rng
+		return sym
+	}
+	if sym.Children == nil {
+		sym.Children = make(SymbolTable)
+	}
+	return sym.Children.Define(path[1:], rng)
+}
+
+// TraversalAt returns the absolute traversal in the given file that contains
+// the given position, if any. Only files parsed from native syntax are
+// supported.
+func TraversalAt(file *hcl.File, pos hcl.Pos) (hcl.Traversal, bool) {
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, false
+	}
+
+	var ret hcl.Traversal
+	hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		if expr, ok := node.(*hclsyntax.ScopeTraversalExpr); ok && expr.SrcRange.ContainsOffset(pos.Byte) {
+			ret = expr.Traversal
+		}
+		return nil
+	})
+	return ret, ret != nil
+}
+
+// Definition finds the traversal at the given position in the given file
+// and returns the range where the symbol it refers to is defined, according
+// to the given symbol table.
+//
+// The traversal is resolved only as far as the step that contains the
+// position, so that a position within the "foo" of "var.foo.bar" finds the
+// definition of "var.foo". If no symbol is defined for that step then the
+// nearest enclosing symbol that has a range is used instead. The boolean
+// result is false if no definition can be found.
+func Definition(file *hcl.File, pos hcl.Pos, symbols SymbolTable) (hcl.Range, bool) {
+	traversal, ok := TraversalAt(file, pos)
+	if !ok {
+		return hcl.Range{}, false
+	}
+
+	var found *Symbol
+	table := symbols
+	for i, step := range traversal {
+		if i > 0 && step.SourceRange().Start.Byte > pos.Byte {
+			// We've passed the step that contains the position.
+			break
+		}
+		name, ok := traverserKey(step)
+		if !ok {
+			break
+		}
+		sym := table[name]
+		if sym == nil {
+			break
+		}
+		if !sym.Range.Empty() {
+			found = sym
+		}
+		table = sym.Children
+	}
+
+	if found == nil {
+		return hcl.Range{}, false
+	}
+	return found.Range, true
+}
+
+// traverserKey returns the symbol table key for the given traversal step.
+func traverserKey(step hcl.Traverser) (string, bool) {
+	switch ts := step.(type) {
+	case hcl.TraverseRoot:
+		return ts.Name, true
+	case hcl.TraverseAttr:
+		return ts.Name, true
+	case hcl.TraverseIndex:
+		switch {
+		case ts.Key.IsNull() || !ts.Key.IsKnown():
+			return "", false
+		case ts.Key.Type() == cty.String:
+			return ts.Key.AsString(), true
+		case ts.Key.Type() == cty.Number:
+			bf := ts.Key.AsBigFloat()
+			if !bf.IsInt() {
+				return "", false
+			}
+			i, _ := bf.Int(nil)
+			return i.Text(10), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,75 @@
+package hcled
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+func TestDefinition(t *testing.T) {
+	src := `
+service "web" {
+  port = 80
+}
+
+a = service.web.port
+b = var.region
+c = service.web
+d = list[1]
+e = unknown.thing
+f = service.web["port"]
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+
+	webRange := hcl.Range{Filename: "test.hcl", Start: hcl.Pos{Line: 2, Column: 1, Byte: 1}, End: hcl.Pos{Line: 2, Column: 14, Byte: 14}}
+	portRange := hcl.Range{Filename: "test.hcl", Start: hcl.Pos{Line: 3, Column: 3, Byte: 18}, End: hcl.Pos{Line: 3, Column: 12, Byte: 27}}
+	regionRange := hcl.Range{Filename: "vars.hcl", Start: hcl.Pos{Line: 1, Column: 1, Byte: 0}, End: hcl.Pos{Line: 1, Column: 7, Byte: 6}}
+	listRange := hcl.Range{Filename: "vars.hcl", Start: hcl.Pos{Line: 5, Column: 1, Byte: 40}, End: hcl.Pos{Line: 5, Column: 5, Byte: 44}}
+	elemRange := hcl.Range{Filename: "vars.hcl", Start: hcl.Pos{Line: 7, Column: 1, Byte: 50}, End: hcl.Pos{Line: 7, Column: 5, Byte: 54}}
+
+	symbols := make(SymbolTable)
+	symbols.Define([]string{"service", "web"}, webRange)
+	symbols.Define([]string{"service", "web", "port"}, portRange)
+	symbols.Define([]string{"var", "region"}, regionRange)
+	symbols.Define([]string{"list"}, listRange)
+	symbols.Define([]string{"list", "1"}, elemRange)
+
+	tests := []struct {
+		at     string // text whose first character the cursor is placed on
+		want   hcl.Range
+		wantOk bool
+	}{
+		{"port\nb", portRange, true},
+		{"web.port", webRange, true},
+		{"service.web.port", hcl.Range{}, false}, // "service" itself has no range
+		{"region", regionRange, true},
+		{"var.region", hcl.Range{}, false},
+		{"web\nd", webRange, true},
+		{"[1]", elemRange, true},
+		{"list[1]", listRange, true},
+		{"unknown", hcl.Range{}, false},
+		{`["port"]`, portRange, true},
+		{"port = 80", hcl.Range{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.at, func(t *testing.T) {
+			offset := strings.Index(src, test.at)
+			if offset < 0 {
+				t.Fatalf("%q not found in source", test.at)
+			}
+			got, ok := Definition(file, posForOffset(src, offset), symbols)
+			if ok != test.wantOk {
+				t.Fatalf("wrong ok %t; want %t", ok, test.wantOk)
+			}
+			if got != test.want {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package hcled
+
+import (
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// maxPreviewLen is the maximum length in bytes of HoverInfo.Preview, beyond
+// which it is truncated.
+const maxPreviewLen = 200
+
+// HoverInfo describes the expression at a particular position in a file,
+// for display in an editor's hover popup.
+type HoverInfo struct {
+	// Range is the source range of the expression.
+	Range hcl.Range
+
+	// Type is the type of the expression's value, or cty.DynamicPseudoType
+	// if it could not be determined.
+	Type cty.Type
+
+	// Value is the value of the expression, or cty.NilVal if it could not
+	// be evaluated or if it is sensitive.
+	Value cty.Value
+
+	// Sensitive is true if the expression's value is derived from a
+	// sensitive variable, in which case Value is not populated.
+	Sensitive bool
+
+	// Preview is a short rendering of the value in native syntax, suitable
+	// for display, or an empty string if Value is not populated.
+	Preview string
+}
+
+// Hover returns information about the innermost expression that contains
+// the given position in the given file, which must have been parsed from
+// native syntax. The boolean result is false if there is no expression at
+// the given position.
+//
+// The expression is evaluated in the given EvalContext, which may be nil
+// to evaluate only expressions that don't refer to any variables or
+// functions. Values derived from variables that the context marks as
+// sensitive are not returned.
+func Hover(file *hcl.File, pos hcl.Pos, ctx *hcl.EvalContext) (*HoverInfo, bool) {
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, false
+	}
+
+	var expr hclsyntax.Expression
+	hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		// Since VisitAll is depth-first, the last matching expression we
+		// visit is the innermost one.
+		if e, ok := node.(hclsyntax.Expression); ok && e.Range().ContainsOffset(pos.Byte) {
+			expr = e
+		}
+		return nil
+	})
+	if expr == nil {
+		return nil, false
+	}
+
+	ret := &HoverInfo{
+		Range: expr.Range(),
+		Type:  cty.DynamicPseudoType,
+	}
+	val, diags := expr.Value(ctx)
+	if diags.HasErrors() {
+		return ret, true
+	}
+	ret.Type = val.Type()
+	if ctx.ExpressionSensitive(expr) {
+		ret.Sensitive = true
+		return ret, true
+	}
+	ret.Value = val
+	ret.Preview = valuePreview(val)
+	return ret, true
+}
+
+// valuePreview renders the given value in native syntax, truncated to
+// maxPreviewLen bytes.
+func valuePreview(val cty.Value) string {
+	if !val.IsWhollyKnown() {
+		return "(not yet known)"
+	}
+	src := string(hclwrite.TokensForValue(val).Bytes())
+	if len(src) > maxPreviewLen {
+		end := maxPreviewLen
+		for end > 0 && !utf8.RuneStart(src[end]) {
+			end--
+		}
+		src = src[:end] + "..."
+	}
+	return src
+}
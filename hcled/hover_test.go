@@ -0,0 +1,75 @@
+package hcled
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestHover(t *testing.T) {
+	src := `
+a = "hello ${name}"
+b = password
+c = [1, 2, 3]
+d = nope
+e = strrep
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"name":     cty.StringVal("world"),
+			"password": cty.StringVal("hunter2"),
+			"strrep":   cty.StringVal(strings.Repeat("x", 300)),
+		},
+		SensitiveVariables: map[string]bool{
+			"password": true,
+		},
+	}
+
+	tests := []struct {
+		at            string
+		wantType      cty.Type
+		wantPreview   string
+		wantSensitive bool
+	}{
+		{`"hello`, cty.String, `"hello world"`, false},
+		{`name}`, cty.String, `"world"`, false},
+		{`password`, cty.String, ``, true},
+		{`2,`, cty.Number, `2`, false},
+		{`[1`, cty.Tuple([]cty.Type{cty.Number, cty.Number, cty.Number}), `[1, 2, 3]`, false},
+		{`nope`, cty.DynamicPseudoType, ``, false},
+		{`strrep`, cty.String, `"` + strings.Repeat("x", 199) + `...`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.at, func(t *testing.T) {
+			offset := strings.Index(src, test.at)
+			got, ok := Hover(file, posForOffset(src, offset), ctx)
+			if !ok {
+				t.Fatalf("no hover information")
+			}
+			if !got.Type.Equals(test.wantType) {
+				t.Errorf("wrong type %#v; want %#v", got.Type, test.wantType)
+			}
+			if got.Preview != test.wantPreview {
+				t.Errorf("wrong preview %q; want %q", got.Preview, test.wantPreview)
+			}
+			if got.Sensitive != test.wantSensitive {
+				t.Errorf("wrong sensitive %t; want %t", got.Sensitive, test.wantSensitive)
+			}
+			if got.Sensitive && got.Value != cty.NilVal {
+				t.Errorf("sensitive value was returned")
+			}
+		})
+	}
+
+	if _, ok := Hover(file, hcl.Pos{Line: 1, Column: 1, Byte: 0}, ctx); ok {
+		t.Errorf("unexpected hover information outside of any expression")
+	}
+}
@@ -0,0 +1,184 @@
+package hcled
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/apparentlymart/go-textseg/textseg"
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// LSPPosition is a position in a text document as defined by the Language
+// Server Protocol, with zero-based line and character numbers and with
+// characters counted in UTF-16 code units.
+type LSPPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// LSPRange is a range in a text document as defined by the Language Server
+// Protocol.
+type LSPRange struct {
+	Start LSPPosition `json:"start"`
+	End   LSPPosition `json:"end"`
+}
+
+// LSP diagnostic severities, as defined by the Language Server Protocol.
+const (
+	LSPSeverityError       = 1
+	LSPSeverityWarning     = 2
+	LSPSeverityInformation = 3
+	LSPSeverityHint        = 4
+)
+
+// LSPDiagnostic is a diagnostic as defined by the Language Server Protocol,
+// suitable for serializing as JSON in a textDocument/publishDiagnostics
+// notification.
+type LSPDiagnostic struct {
+	Range    LSPRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code,omitempty"`
+	Source   string   `json:"source,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// LSPDiagnostics converts the given diagnostics into Language Server Protocol
+// diagnostics for the given file, which is used to convert the source
+// ranges to the positions expected by LSP clients. Diagnostics whose subject
+// is in a different file are not included, and diagnostics with no subject
+// are placed at the start of the file.
+//
+// The given source, which may be empty, is used as the Source of each result
+// to indicate to the user where the diagnostics came from.
+func LSPDiagnostics(diags hcl.Diagnostics, file *hcl.File, filename string, source string) []LSPDiagnostic {
+	ret := make([]LSPDiagnostic, 0, len(diags))
+	for _, diag := range diags {
+		var rng LSPRange
+		if diag.Subject != nil {
+			if diag.Subject.Filename != filename {
+				continue
+			}
+			rng = LSPRangeForRange(file, *diag.Subject)
+		}
+
+		severity := LSPSeverityError
+		switch diag.Severity {
+		case hcl.DiagWarning:
+			severity = LSPSeverityWarning
+		case hcl.DiagInfo:
+			severity = LSPSeverityInformation
+		}
+
+		message := diag.Summary
+		if diag.Detail != "" {
+			message += "\n\n" + diag.Detail
+		}
+
+		ret = append(ret, LSPDiagnostic{
+			Range:    rng,
+			Severity: severity,
+			Code:     diag.Code,
+			Source:   source,
+			Message:  message,
+		})
+	}
+	return ret
+}
+
+// LSPRangeForRange converts the given HCL source range into a Language Server
+// Protocol range within the given file.
+//
+// HCL source columns count grapheme clusters while LSP counts UTF-16 code
+// units, so the source code of the file is used to make the conversion.
+// If the file or its source code is not available then the HCL column
+// numbers are used as an approximation.
+func LSPRangeForRange(file *hcl.File, rng hcl.Range) LSPRange {
+	return LSPRange{
+		Start: LSPPositionForPos(file, rng.Start),
+		End:   LSPPositionForPos(file, rng.End),
+	}
+}
+
+// LSPPositionForPos converts the given HCL source position into a Language
+// Server Protocol position within the given file, as for LSPRangeForRange.
+func LSPPositionForPos(file *hcl.File, pos hcl.Pos) LSPPosition {
+	ret := LSPPosition{
+		Line:      pos.Line - 1,
+		Character: pos.Column - 1,
+	}
+	if ret.Line < 0 {
+		ret.Line = 0
+	}
+	if ret.Character < 0 {
+		ret.Character = 0
+	}
+
+	if file == nil || file.Bytes == nil || pos.Byte < 0 || pos.Byte > len(file.Bytes) {
+		return ret
+	}
+
+	src := file.Bytes
+	lineStart := bytes.LastIndexByte(src[:pos.Byte], '\n') + 1
+	prefix := src[lineStart:pos.Byte]
+	chars := 0
+	for len(prefix) > 0 {
+		r, size := utf8.DecodeRune(prefix)
+		prefix = prefix[size:]
+		if r >= 0x10000 {
+			chars += 2 // encoded as a surrogate pair in UTF-16
+		} else {
+			chars++
+		}
+	}
+	ret.Character = chars
+	return ret
+}
+
+// PosForLSPPosition converts the given Language Server Protocol position into
+// an HCL source position within the given file, which is the inverse of
+// LSPPositionForPos. Positions beyond the end of a line are clamped to the
+// end of that line, and lines beyond the end of the file are clamped to the
+// end of the file.
+//
+// If the file or its source code is not available then the LSP character
+// number is used as an approximation of the column number, and the byte
+// offset of the result is zero.
+func PosForLSPPosition(file *hcl.File, pos LSPPosition) hcl.Pos {
+	if file == nil || file.Bytes == nil {
+		return hcl.Pos{Line: pos.Line + 1, Column: pos.Character + 1}
+	}
+
+	src := file.Bytes
+	lineStart := 0
+	line := 0
+	for line < pos.Line {
+		nl := bytes.IndexByte(src[lineStart:], '\n')
+		if nl < 0 {
+			break
+		}
+		lineStart += nl + 1
+		line++
+	}
+
+	offset := lineStart
+	chars := 0
+	for offset < len(src) && chars < pos.Character {
+		r, size := utf8.DecodeRune(src[offset:])
+		if r == '\n' || r == '\r' {
+			break
+		}
+		offset += size
+		if r >= 0x10000 {
+			chars += 2 // encoded as a surrogate pair in UTF-16
+		} else {
+			chars++
+		}
+	}
+
+	cols, _ := textseg.TokenCount(src[lineStart:offset], textseg.ScanGraphemeClusters)
+	return hcl.Pos{
+		Line:   line + 1,
+		Column: cols + 1,
+		Byte:   offset,
+	}
+}
@@ -0,0 +1,95 @@
+package hcled
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+func TestLSPDiagnostics(t *testing.T) {
+	file := &hcl.File{
+		Bytes: []byte("a = 1\nb = \"\U0001F600\" + x\n"),
+	}
+	diags := hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Unknown variable",
+			Detail:   "There is no variable named \"x\".",
+			Subject: &hcl.Range{
+				Filename: "test.hcl",
+				Start:    hcl.Pos{Line: 2, Column: 11, Byte: 19},
+				End:      hcl.Pos{Line: 2, Column: 12, Byte: 20},
+			},
+		},
+		{
+			Severity: hcl.DiagWarning,
+			Summary:  "Elsewhere",
+			Subject: &hcl.Range{
+				Filename: "other.hcl",
+				Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+				End:      hcl.Pos{Line: 1, Column: 2, Byte: 1},
+			},
+		},
+		{
+			Severity: hcl.DiagWarning,
+			Summary:  "No subject",
+		},
+	}
+
+	got := LSPDiagnostics(diags, file, "test.hcl", "hcl")
+	want := []LSPDiagnostic{
+		{
+			Range: LSPRange{
+				Start: LSPPosition{Line: 1, Character: 11},
+				End:   LSPPosition{Line: 1, Character: 12},
+			},
+			Severity: LSPSeverityError,
+			Source:   "hcl",
+			Message:  "Unknown variable\n\nThere is no variable named \"x\".",
+		},
+		{
+			Severity: LSPSeverityWarning,
+			Source:   "hcl",
+			Message:  "No subject",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestLSPPositionForPosWithoutSource(t *testing.T) {
+	got := LSPPositionForPos(nil, hcl.Pos{Line: 3, Column: 5, Byte: 20})
+	want := LSPPosition{Line: 2, Character: 4}
+	if got != want {
+		t.Errorf("wrong result %#v; want %#v", got, want)
+	}
+}
+
+func TestPosForLSPPosition(t *testing.T) {
+	file := &hcl.File{
+		Bytes: []byte("a = 1\nb = \"\U0001F600x\"\n"),
+	}
+
+	tests := []struct {
+		pos  LSPPosition
+		want hcl.Pos
+	}{
+		{LSPPosition{Line: 0, Character: 0}, hcl.Pos{Line: 1, Column: 1, Byte: 0}},
+		{LSPPosition{Line: 0, Character: 4}, hcl.Pos{Line: 1, Column: 5, Byte: 4}},
+		{LSPPosition{Line: 0, Character: 50}, hcl.Pos{Line: 1, Column: 6, Byte: 5}},
+		{LSPPosition{Line: 1, Character: 7}, hcl.Pos{Line: 2, Column: 7, Byte: 15}},
+		{LSPPosition{Line: 5, Character: 0}, hcl.Pos{Line: 3, Column: 1, Byte: 18}},
+	}
+
+	for _, test := range tests {
+		got := PosForLSPPosition(file, test.pos)
+		if got != test.want {
+			t.Errorf("wrong result for %#v\ngot:  %#v\nwant: %#v", test.pos, got, test.want)
+		}
+		if back := LSPPositionForPos(file, got); test.pos.Character < 50 && test.pos.Line < 5 && back != test.pos {
+			t.Errorf("wrong round trip for %#v: %#v", test.pos, back)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package hcled
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+// OutlineKind describes what sort of item an OutlineItem represents.
+type OutlineKind int
+
+const (
+	// OutlineBlock is a block, whose children are the items of its body.
+	OutlineBlock OutlineKind = iota + 1
+
+	// OutlineAttribute is an attribute.
+	OutlineAttribute
+)
+
+// OutlineItem is an entry in the hierarchical outline of a file.
+type OutlineItem struct {
+	// Name is the block type or attribute name.
+	Name string
+
+	// Detail is the quoted block labels separated by spaces, or an empty
+	// string for attributes and blocks without labels.
+	Detail string
+
+	// Kind describes whether the item is a block or an attribute.
+	Kind OutlineKind
+
+	// Range covers the whole item, including any block body.
+	Range hcl.Range
+
+	// SelectionRange covers the part of the item that identifies it, which
+	// is the block type or the attribute name.
+	SelectionRange hcl.Range
+
+	// Children are the items in the body of a block, in source order.
+	Children []*OutlineItem
+}
+
+// Outline returns the attributes and blocks of the given file as a tree of
+// items, in source order. Only files parsed from native syntax are
+// supported, and the result is nil for other files.
+func Outline(file *hcl.File) []*OutlineItem {
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	return outlineBody(body)
+}
+
+func outlineBody(body *hclsyntax.Body) []*OutlineItem {
+	ret := make([]*OutlineItem, 0, len(body.Attributes)+len(body.Blocks))
+	for _, attr := range body.Attributes {
+		ret = append(ret, &OutlineItem{
+			Name:           attr.Name,
+			Kind:           OutlineAttribute,
+			Range:          attr.SrcRange,
+			SelectionRange: attr.NameRange,
+		})
+	}
+	for _, block := range body.Blocks {
+		labels := make([]string, len(block.Labels))
+		for i, label := range block.Labels {
+			labels[i] = fmt.Sprintf("%q", label)
+		}
+		ret = append(ret, &OutlineItem{
+			Name:           block.Type,
+			Detail:         strings.Join(labels, " "),
+			Kind:           OutlineBlock,
+			Range:          block.Range(),
+			SelectionRange: block.TypeRange,
+			Children:       outlineBody(block.Body),
+		})
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Range.Start.Byte < ret[j].Range.Start.Byte
+	})
+	return ret
+}
+
+// LSP symbol kinds used for document symbols, as defined by the Language
+// Server Protocol.
+const (
+	LSPSymbolKindProperty = 7
+	LSPSymbolKindObject   = 19
+)
+
+// LSPDocumentSymbol is a document symbol as defined by the Language Server
+// Protocol, suitable for serializing as JSON in a response to a
+// textDocument/documentSymbol request.
+type LSPDocumentSymbol struct {
+	Name           string              `json:"name"`
+	Detail         string              `json:"detail,omitempty"`
+	Kind           int                 `json:"kind"`
+	Range          LSPRange            `json:"range"`
+	SelectionRange LSPRange            `json:"selectionRange"`
+	Children       []LSPDocumentSymbol `json:"children,omitempty"`
+}
+
+// LSPDocumentSymbols returns the outline of the given file, as produced by
+// Outline, as Language Server Protocol document symbols. Blocks have the
+// "object" symbol kind and attributes have the "property" symbol kind.
+func LSPDocumentSymbols(file *hcl.File) []LSPDocumentSymbol {
+	return lspDocumentSymbols(file, Outline(file))
+}
+
+func lspDocumentSymbols(file *hcl.File, items []*OutlineItem) []LSPDocumentSymbol {
+	if len(items) == 0 {
+		return nil
+	}
+	ret := make([]LSPDocumentSymbol, len(items))
+	for i, item := range items {
+		kind := LSPSymbolKindProperty
+		if item.Kind == OutlineBlock {
+			kind = LSPSymbolKindObject
+		}
+		ret[i] = LSPDocumentSymbol{
+			Name:           item.Name,
+			Detail:         item.Detail,
+			Kind:           kind,
+			Range:          LSPRangeForRange(file, item.Range),
+			SelectionRange: LSPRangeForRange(file, item.SelectionRange),
+			Children:       lspDocumentSymbols(file, item.Children),
+		}
+	}
+	return ret
+}
@@ -0,0 +1,85 @@
+package hcled
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hcl/json"
+)
+
+func TestOutline(t *testing.T) {
+	src := `a = 1
+service "web" "prod" {
+  port = 80
+  health {
+  }
+}
+b = 2
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+
+	got := Outline(file)
+	if len(got) != 3 {
+		t.Fatalf("wrong number of items %d; want 3", len(got))
+	}
+	if got[0].Name != "a" || got[0].Kind != OutlineAttribute {
+		t.Errorf("wrong first item %#v", got[0])
+	}
+	if got[2].Name != "b" || got[2].Kind != OutlineAttribute {
+		t.Errorf("wrong last item %#v", got[2])
+	}
+
+	svc := got[1]
+	if svc.Name != "service" || svc.Kind != OutlineBlock {
+		t.Fatalf("wrong block item %#v", svc)
+	}
+	if got, want := svc.Detail, `"web" "prod"`; got != want {
+		t.Errorf("wrong detail %q; want %q", got, want)
+	}
+	if svc.Range.Start.Line != 2 || svc.Range.End.Line != 6 {
+		t.Errorf("wrong block range %s", svc.Range)
+	}
+	if got, want := svc.SelectionRange.Start.Column, 1; got != want {
+		t.Errorf("wrong selection start column %d; want %d", got, want)
+	}
+	if len(svc.Children) != 2 {
+		t.Fatalf("wrong number of children %d; want 2", len(svc.Children))
+	}
+	if svc.Children[0].Name != "port" || svc.Children[1].Name != "health" {
+		t.Errorf("wrong children %#v, %#v", svc.Children[0], svc.Children[1])
+	}
+
+	syms := LSPDocumentSymbols(file)
+	if len(syms) != 3 {
+		t.Fatalf("wrong number of symbols %d; want 3", len(syms))
+	}
+	if got, want := syms[1].Kind, LSPSymbolKindObject; got != want {
+		t.Errorf("wrong block kind %d; want %d", got, want)
+	}
+	if got, want := syms[0].Kind, LSPSymbolKindProperty; got != want {
+		t.Errorf("wrong attribute kind %d; want %d", got, want)
+	}
+	if got, want := syms[1].Range.Start, (LSPPosition{Line: 1, Character: 0}); got != want {
+		t.Errorf("wrong block start %#v; want %#v", got, want)
+	}
+	if got, want := len(syms[1].Children), 2; got != want {
+		t.Errorf("wrong number of child symbols %d; want %d", got, want)
+	}
+	if syms[0].Children != nil {
+		t.Errorf("attribute has children %#v", syms[0].Children)
+	}
+}
+
+func TestOutlineJSON(t *testing.T) {
+	file, diags := json.Parse([]byte(`{"a": 1}`), "test.json")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	if got := Outline(file); got != nil {
+		t.Errorf("unexpected outline %#v", got)
+	}
+}
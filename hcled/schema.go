@@ -0,0 +1,45 @@
+package hcled
+
+import (
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+// Schema describes the expected content of a body, and of the bodies of the
+// blocks nested within it, for use by the editor features in this package.
+type Schema struct {
+	// Body is the schema for the body itself. It may be nil if the expected
+	// content of the body is not known.
+	Body *hcl.BodySchema
+
+	// Blocks gives the schemas for the bodies of nested blocks, keyed by
+	// block type.
+	Blocks map[string]*Schema
+}
+
+// blockSchema returns the schema for the body of a block of the given type
+// nested in a body with the receiving schema, or nil if none is known.
+// It is safe to call on a nil schema.
+func (s *Schema) blockSchema(typeName string) *Schema {
+	if s == nil {
+		return nil
+	}
+	return s.Blocks[typeName]
+}
+
+// bodyPathAt returns the sequence of nested native syntax blocks that
+// contain the given byte offset, outermost first.
+func bodyPathAt(body *hclsyntax.Body, offset int) []*hclsyntax.Block {
+	var path []*hclsyntax.Block
+Bodies:
+	for {
+		for _, block := range body.Blocks {
+			if offset > block.OpenBraceRange.Start.Byte && offset <= block.CloseBraceRange.Start.Byte {
+				path = append(path, block)
+				body = block.Body
+				continue Bodies
+			}
+		}
+		return path
+	}
+}
@@ -0,0 +1,196 @@
+package hcled
+
+import (
+	"bytes"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+// SemanticTokenType is a semantic category for a range of source code.
+type SemanticTokenType int
+
+const (
+	SemanticBlockType SemanticTokenType = iota + 1
+	SemanticBlockLabel
+	SemanticAttributeName
+	SemanticVariable
+	SemanticFunction
+	SemanticString
+	SemanticNumber
+	SemanticComment
+)
+
+// SemanticToken is a range of source code classified into a semantic
+// category.
+type SemanticToken struct {
+	Type  SemanticTokenType
+	Range hcl.Range
+}
+
+// SemanticTokens classifies ranges of the given file into semantic
+// categories, returning tokens that do not overlap, in source order.
+//
+// Block types, labels, attribute names, variable references and function
+// names are found using the syntax tree, so that for example an attribute
+// name is distinguished from a reference to a variable of the same name.
+// Object keys given as bare identifiers are classified as attribute names.
+// Strings, numbers and comments are found by scanning the source code.
+//
+// Only files parsed from native syntax are supported, and the result is nil
+// for other files. The file must have been parsed starting at the beginning
+// of its source code.
+func SemanticTokens(file *hcl.File) []SemanticToken {
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+
+	var ret []SemanticToken
+	add := func(ty SemanticTokenType, rng hcl.Range) {
+		ret = append(ret, SemanticToken{Type: ty, Range: rng})
+	}
+
+	hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		switch tn := node.(type) {
+		case *hclsyntax.Attribute:
+			add(SemanticAttributeName, tn.NameRange)
+		case *hclsyntax.Block:
+			add(SemanticBlockType, tn.TypeRange)
+			for _, rng := range tn.LabelRanges {
+				add(SemanticBlockLabel, rng)
+			}
+		case *hclsyntax.ObjectConsKeyExpr:
+			// This is visited before the expression it wraps, so a bare
+			// key takes precedence over the traversal it is parsed as.
+			if hcl.ExprAsKeyword(tn) != "" {
+				add(SemanticAttributeName, tn.Range())
+			}
+		case *hclsyntax.ScopeTraversalExpr:
+			add(SemanticVariable, tn.Traversal[0].SourceRange())
+		case *hclsyntax.FunctionCallExpr:
+			add(SemanticFunction, tn.NameRange)
+		}
+		return nil
+	})
+
+	filename := body.SrcRange.Filename
+	tokens, _ := hclsyntax.LexConfig(file.Bytes, filename, hcl.Pos{Line: 1, Column: 1})
+	for _, tok := range tokens {
+		switch tok.Type {
+		case hclsyntax.TokenComment:
+			add(SemanticComment, tok.Range)
+		case hclsyntax.TokenNumberLit:
+			add(SemanticNumber, tok.Range)
+		case hclsyntax.TokenOQuote, hclsyntax.TokenCQuote, hclsyntax.TokenQuotedLit,
+			hclsyntax.TokenOHeredoc, hclsyntax.TokenCHeredoc, hclsyntax.TokenStringLit:
+			add(SemanticString, tok.Range)
+		}
+	}
+
+	// Tokens from the syntax tree were added first, so they win over any
+	// overlapping tokens from the scanner.
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Range.Start.Byte < ret[j].Range.Start.Byte
+	})
+	kept := ret[:0]
+	end := 0
+	for _, tok := range ret {
+		if tok.Range.Start.Byte < end || tok.Range.Empty() {
+			continue
+		}
+		kept = append(kept, tok)
+		end = tok.Range.End.Byte
+	}
+	return kept
+}
+
+// LSPSemanticTokenTypes is the legend of token types used by
+// LSPSemanticTokens, in the order of their indices in the encoded data.
+var LSPSemanticTokenTypes = []string{
+	SemanticBlockType - 1:     "keyword",
+	SemanticBlockLabel - 1:    "enumMember",
+	SemanticAttributeName - 1: "property",
+	SemanticVariable - 1:      "variable",
+	SemanticFunction - 1:      "function",
+	SemanticString - 1:        "string",
+	SemanticNumber - 1:        "number",
+	SemanticComment - 1:       "comment",
+}
+
+// LSPSemanticTokens encodes the given tokens, as returned by SemanticTokens,
+// in the relative integer format used for the data of Language Server
+// Protocol semantic tokens. Token types are indices into
+// LSPSemanticTokenTypes and no token modifiers are used.
+//
+// Tokens that span multiple lines, such as block comments, are split into
+// one token per line.
+func LSPSemanticTokens(file *hcl.File, tokens []SemanticToken) []uint32 {
+	var ret []uint32
+	prev := LSPPosition{}
+	for _, tok := range tokens {
+		for _, piece := range lspTokenPieces(file, tok.Range) {
+			deltaLine := piece.Start.Line - prev.Line
+			deltaChar := piece.Start.Character
+			if deltaLine == 0 {
+				deltaChar -= prev.Character
+			}
+			ret = append(ret,
+				uint32(deltaLine),
+				uint32(deltaChar),
+				uint32(piece.End.Character-piece.Start.Character),
+				uint32(tok.Type-1),
+				0,
+			)
+			prev = piece.Start
+		}
+	}
+	return ret
+}
+
+// lspTokenPieces splits the given range into single-line LSP ranges,
+// omitting any that are empty.
+func lspTokenPieces(file *hcl.File, rng hcl.Range) []LSPRange {
+	start := LSPPositionForPos(file, rng.Start)
+	if file == nil || file.Bytes == nil || rng.End.Byte > len(file.Bytes) || rng.Start.Byte > rng.End.Byte {
+		end := LSPPositionForPos(file, rng.End)
+		if end.Line != start.Line || end.Character <= start.Character {
+			return nil
+		}
+		return []LSPRange{{Start: start, End: end}}
+	}
+
+	var ret []LSPRange
+	lines := bytes.Split(file.Bytes[rng.Start.Byte:rng.End.Byte], []byte{'\n'})
+	for i, line := range lines {
+		line = bytes.TrimSuffix(line, []byte{'\r'})
+		if i > 0 {
+			start = LSPPosition{Line: start.Line + 1}
+		}
+		length := utf16Len(line)
+		if length == 0 {
+			continue
+		}
+		ret = append(ret, LSPRange{
+			Start: start,
+			End:   LSPPosition{Line: start.Line, Character: start.Character + length},
+		})
+	}
+	return ret
+}
+
+func utf16Len(b []byte) int {
+	n := 0
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		b = b[size:]
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,72 @@
+package hcled
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+func TestSemanticTokens(t *testing.T) {
+	src := `# comment
+service "web" {
+  port = upper(port)
+  tags = { name = "a${b}" }
+  n    = 1.5
+}
+/* multi
+line */
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+
+	type tokenText struct {
+		Type SemanticTokenType
+		Text string
+	}
+	var got []tokenText
+	for _, tok := range SemanticTokens(file) {
+		got = append(got, tokenText{tok.Type, string(tok.Range.SliceBytes(file.Bytes))})
+	}
+	want := []tokenText{
+		{SemanticComment, "# comment\n"},
+		{SemanticBlockType, "service"},
+		{SemanticBlockLabel, `"web"`},
+		{SemanticAttributeName, "port"},
+		{SemanticFunction, "upper"},
+		{SemanticVariable, "port"},
+		{SemanticAttributeName, "tags"},
+		{SemanticAttributeName, "name"},
+		{SemanticString, `"`},
+		{SemanticString, "a"},
+		{SemanticVariable, "b"},
+		{SemanticString, `"`},
+		{SemanticAttributeName, "n"},
+		{SemanticNumber, "1.5"},
+		{SemanticComment, "/* multi\nline */"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong tokens\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	data := LSPSemanticTokens(file, SemanticTokens(file))
+	// The first token is the comment on line 0, whose newline is omitted.
+	if got, want := data[:5], []uint32{0, 0, 9, uint32(SemanticComment - 1), 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong first token %#v; want %#v", got, want)
+	}
+	// The block comment is split into one token per line.
+	n := len(data)
+	wantLast := []uint32{
+		2, 0, 8, uint32(SemanticComment - 1), 0,
+		1, 0, 7, uint32(SemanticComment - 1), 0,
+	}
+	if got := data[n-10:]; !reflect.DeepEqual(got, wantLast) {
+		t.Errorf("wrong last tokens %#v; want %#v", got, wantLast)
+	}
+	if got, want := LSPSemanticTokenTypes[SemanticVariable-1], "variable"; got != want {
+		t.Errorf("wrong legend entry %q; want %q", got, want)
+	}
+}
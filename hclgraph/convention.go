@@ -0,0 +1,69 @@
+package hclgraph
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// Address identifies a symbol defined by a configuration, as a sequence of
+// names, such as ["var", "region"] for a symbol referenced in expressions
+// as var.region.
+type Address []string
+
+// String returns the address as it would be written in a traversal, with
+// its names separated by periods.
+func (a Address) String() string {
+	return strings.Join(a, ".")
+}
+
+// Convention describes how the blocks of a configuration language define
+// symbols, keyed by block type. Blocks whose types are not included do not
+// define symbols and are not included in the graph.
+type Convention map[string]BlockConvention
+
+// BlockConvention describes how blocks of a particular type define symbols.
+type BlockConvention struct {
+	// LabelNames are the names of the labels expected on the block type.
+	// The label values follow Prefix in the addresses of the symbols defined
+	// by the block.
+	LabelNames []string
+
+	// Prefix is the sequence of names that begins the address of each symbol
+	// defined by blocks of this type. It may be empty if the block labels
+	// alone form the address.
+	Prefix []string
+
+	// If ReportUnused is set, the symbols defined by blocks of this type are
+	// reported by Graph.Unused when nothing refers to them.
+	ReportUnused bool
+
+	// If Attributes is set, each attribute in the body of the block defines
+	// a symbol whose address is Prefix followed by the block labels and the
+	// attribute name, and the block itself does not define a symbol. This
+	// is suitable for blocks like "locals" whose attributes may refer to one
+	// another.
+	Attributes bool
+}
+
+func (c Convention) schema() *hcl.BodySchema {
+	schema := &hcl.BodySchema{}
+	for typeName, bc := range c {
+		schema.Blocks = append(schema.Blocks, hcl.BlockHeaderSchema{
+			Type:       typeName,
+			LabelNames: bc.LabelNames,
+		})
+	}
+	sort.Slice(schema.Blocks, func(i, j int) bool {
+		return schema.Blocks[i].Type < schema.Blocks[j].Type
+	})
+	return schema
+}
+
+func (bc BlockConvention) address(block *hcl.Block) Address {
+	addr := make(Address, 0, len(bc.Prefix)+len(block.Labels)+1)
+	addr = append(addr, bc.Prefix...)
+	addr = append(addr, block.Labels...)
+	return addr
+}
@@ -0,0 +1,9 @@
+// Package hclgraph provides analyses of the references between the items
+// defined in a configuration, such as blocks that refer to one another by
+// name in their expressions.
+//
+// HCL itself does not define how blocks and attributes are named or how
+// they refer to one another, so the caller describes its configuration
+// language's convention for this using a Convention, from which Build
+// constructs a Graph.
+package hclgraph
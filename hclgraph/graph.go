@@ -0,0 +1,309 @@
+package hclgraph
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Graph is a graph of the references between the symbols defined by a
+// configuration.
+type Graph struct {
+	// Nodes are the symbols defined by the configuration, in the order
+	// they were defined.
+	Nodes []*Node
+
+	// External are the references from parts of the configuration that do
+	// not define symbols, such as attributes in the root bodies or blocks of
+	// types not included in the convention. They have no From node.
+	External []*Reference
+
+	// Unresolved are the references whose traversals do not refer to any
+	// symbol in the graph, such as references to variables that the
+	// application defines itself.
+	Unresolved []*Reference
+
+	nodes map[string]*Node
+}
+
+// Node is a symbol defined by a configuration.
+type Node struct {
+	Address Address
+
+	// DeclRange is the source range of the definition of the symbol, which
+	// is the header of the block or the name of the attribute.
+	DeclRange hcl.Range
+
+	// Block is the block that defines the symbol, or that contains the
+	// attribute that defines the symbol.
+	Block *hcl.Block
+
+	// Attribute is the attribute that defines the symbol, or nil if the
+	// symbol is defined by a block.
+	Attribute *hcl.Attribute
+
+	// References are the references from the expressions in the definition
+	// of this symbol, in source order, and ReferencedBy are the references
+	// to this symbol from the definitions of any symbols.
+	References   []*Reference
+	ReferencedBy []*Reference
+
+	reportUnused bool
+}
+
+// Reference is a traversal in the definition of one symbol that refers to
+// another symbol.
+type Reference struct {
+	// From is the symbol whose definition contains the traversal, or nil
+	// for an external reference, and To is the symbol it refers to, or nil
+	// if the reference is unresolved.
+	From, To *Node
+
+	Traversal hcl.Traversal
+
+	// Range is the source range of the traversal.
+	Range hcl.Range
+}
+
+// Build constructs the graph of references between the symbols defined by
+// the given bodies, which are usually the root bodies of the files of a
+// configuration, according to the given convention.
+//
+// A traversal refers to the symbol with the longest address matching the
+// names at the start of the traversal. For example, a traversal
+// aws_instance.web[0].id refers to a symbol aws_instance.web if one exists.
+// Names may be given as attributes or as string index keys.
+//
+// Error diagnostics are returned if any symbol is defined more than once, in
+// which case only the first definition is included in the graph, or if the
+// blocks that define symbols are not valid according to the convention.
+func Build(bodies []hcl.Body, conv Convention) (*Graph, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	g := &Graph{
+		nodes: make(map[string]*Node),
+	}
+	var traversals [][]hcl.Traversal
+
+	define := func(node *Node, vars []hcl.Traversal) {
+		key := node.Address.String()
+		if existing, exists := g.nodes[key]; exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate definition",
+				Detail:   fmt.Sprintf("%s was already defined at %s.", key, existing.DeclRange),
+				Subject:  node.DeclRange.Ptr(),
+			})
+			return
+		}
+		g.nodes[key] = node
+		g.Nodes = append(g.Nodes, node)
+		traversals = append(traversals, vars)
+	}
+
+	var external []hcl.Traversal
+	schema := conv.schema()
+	for _, body := range bodies {
+		content, remain, contentDiags := body.PartialContent(schema)
+		diags = append(diags, contentDiags...)
+		external = append(external, externalVariables(body, remain, conv)...)
+
+		for _, block := range content.Blocks {
+			bc := conv[block.Type]
+			if !bc.Attributes {
+				define(&Node{
+					Address:      bc.address(block),
+					DeclRange:    block.DefRange,
+					Block:        block,
+					reportUnused: bc.ReportUnused,
+				}, bodyVariables(block.Body))
+				continue
+			}
+
+			attrs, attrsDiags := hcl.AttributesInOrder(block.Body)
+			diags = append(diags, attrsDiags...)
+			for _, attr := range attrs {
+				define(&Node{
+					Address:      append(bc.address(block), attr.Name),
+					DeclRange:    attr.NameRange,
+					Block:        block,
+					Attribute:    attr,
+					reportUnused: bc.ReportUnused,
+				}, attr.Expr.Variables())
+			}
+		}
+	}
+
+	for i, node := range g.Nodes {
+		for _, traversal := range traversals[i] {
+			node.References = append(node.References, g.addReference(node, traversal))
+		}
+	}
+	for _, traversal := range external {
+		g.External = append(g.External, g.addReference(nil, traversal))
+	}
+
+	return g, diags
+}
+
+func (g *Graph) addReference(from *Node, traversal hcl.Traversal) *Reference {
+	ref := &Reference{
+		From:      from,
+		To:        g.Resolve(traversal),
+		Traversal: traversal,
+		Range:     traversal.SourceRange(),
+	}
+	if ref.To != nil {
+		ref.To.ReferencedBy = append(ref.To.ReferencedBy, ref)
+	} else {
+		g.Unresolved = append(g.Unresolved, ref)
+	}
+	return ref
+}
+
+// Node returns the node with the given address, or nil if there is no such
+// node in the graph.
+func (g *Graph) Node(addr Address) *Node {
+	return g.nodes[addr.String()]
+}
+
+// Resolve returns the node referred to by the given traversal, as described
+// for Build, or nil if the traversal does not refer to any node in the graph.
+func (g *Graph) Resolve(traversal hcl.Traversal) *Node {
+	var addr Address
+	var ret *Node
+	for _, step := range traversal {
+		switch ts := step.(type) {
+		case hcl.TraverseRoot:
+			addr = append(addr, ts.Name)
+		case hcl.TraverseAttr:
+			addr = append(addr, ts.Name)
+		case hcl.TraverseIndex:
+			if !ts.Key.IsKnown() || ts.Key.IsNull() || ts.Key.Type() != cty.String {
+				return ret
+			}
+			addr = append(addr, ts.Key.AsString())
+		default:
+			return ret
+		}
+		if node, exists := g.nodes[addr.String()]; exists {
+			ret = node
+		}
+	}
+	return ret
+}
+
+// Dependencies returns the nodes referred to by the definition of the given
+// node, without duplicates, in the order of their first reference.
+func (n *Node) Dependencies() []*Node {
+	var ret []*Node
+	seen := make(map[*Node]struct{})
+	for _, ref := range n.References {
+		if ref.To == nil {
+			continue
+		}
+		if _, exists := seen[ref.To]; !exists {
+			seen[ref.To] = struct{}{}
+			ret = append(ret, ref.To)
+		}
+	}
+	return ret
+}
+
+// Dependents returns the nodes whose definitions refer to the given node,
+// without duplicates, in the order of their references. External references
+// are not included.
+func (n *Node) Dependents() []*Node {
+	var ret []*Node
+	seen := make(map[*Node]struct{})
+	for _, ref := range n.ReferencedBy {
+		if ref.From == nil {
+			continue
+		}
+		if _, exists := seen[ref.From]; !exists {
+			seen[ref.From] = struct{}{}
+			ret = append(ret, ref.From)
+		}
+	}
+	return ret
+}
+
+// Impacted returns all of the nodes that depend on any of the given nodes,
+// either directly or indirectly, and so could be affected by a change to
+// them. The given nodes themselves are included only if they depend on
+// one another. The result is in breadth-first order.
+func (g *Graph) Impacted(nodes ...*Node) []*Node {
+	var ret []*Node
+	seen := make(map[*Node]struct{})
+	queue := append([]*Node(nil), nodes...)
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, dep := range node.Dependents() {
+			if _, exists := seen[dep]; exists {
+				continue
+			}
+			seen[dep] = struct{}{}
+			ret = append(ret, dep)
+			queue = append(queue, dep)
+		}
+	}
+	return ret
+}
+
+// bodyVariables returns the traversals in all of the expressions in the
+// given body. For bodies in native syntax this includes the expressions in
+// nested blocks, while for other bodies only the attributes of the body
+// itself can be found, because their structure depends on a schema.
+func bodyVariables(body hcl.Body) []hcl.Traversal {
+	var ret []hcl.Traversal
+	if sb, ok := body.(*hclsyntax.Body); ok {
+		hclsyntax.VisitAll(sb, func(node hclsyntax.Node) hcl.Diagnostics {
+			if attr, ok := node.(*hclsyntax.Attribute); ok {
+				ret = append(ret, attr.Expr.Variables()...)
+			}
+			return nil
+		})
+		sortTraversals(ret)
+		return ret
+	}
+
+	attrs, _ := hcl.AttributesInOrder(body)
+	for _, attr := range attrs {
+		ret = append(ret, attr.Expr.Variables()...)
+	}
+	return ret
+}
+
+// externalVariables returns the traversals in the parts of the given body
+// that do not define symbols according to the given convention. The remain
+// body is the result of the body's PartialContent method with the schema
+// of the convention.
+func externalVariables(body, remain hcl.Body, conv Convention) []hcl.Traversal {
+	sb, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return bodyVariables(remain)
+	}
+
+	// The remaining body in native syntax still exposes the blocks it hides
+	// through its syntax tree, so we walk the original body ourselves.
+	var ret []hcl.Traversal
+	for _, attr := range sb.Attributes {
+		ret = append(ret, attr.Expr.Variables()...)
+	}
+	for _, block := range sb.Blocks {
+		if _, isSymbol := conv[block.Type]; !isSymbol {
+			ret = append(ret, bodyVariables(block.Body)...)
+		}
+	}
+	sortTraversals(ret)
+	return ret
+}
+
+func sortTraversals(traversals []hcl.Traversal) {
+	sort.SliceStable(traversals, func(i, j int) bool {
+		return traversals[i].SourceRange().Start.Byte < traversals[j].SourceRange().Start.Byte
+	})
+}
@@ -0,0 +1,161 @@
+package hclgraph
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hcl/json"
+)
+
+var testConvention = Convention{
+	"variable": {
+		LabelNames: []string{"name"},
+		Prefix:     []string{"var"},
+	},
+	"resource": {
+		LabelNames: []string{"type", "name"},
+	},
+	"locals": {
+		Prefix:     []string{"local"},
+		Attributes: true,
+	},
+}
+
+func parseBodies(t *testing.T, srcs ...string) []hcl.Body {
+	var bodies []hcl.Body
+	for i, src := range srcs {
+		filename := string(rune('a'+i)) + ".hcl"
+		file, diags := hclsyntax.ParseConfig([]byte(src), filename, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			t.Fatalf("unexpected diagnostics: %s", diags)
+		}
+		bodies = append(bodies, file.Body)
+	}
+	return bodies
+}
+
+func addrs(nodes []*Node) []string {
+	var ret []string
+	for _, node := range nodes {
+		ret = append(ret, node.Address.String())
+	}
+	return ret
+}
+
+func TestBuild(t *testing.T) {
+	bodies := parseBodies(t, `
+variable "region" {}
+
+locals {
+  prefix = "app-${var.region}"
+  name   = "${local.prefix}-web"
+}
+`, `
+resource "server" "web" {
+  name = local.name
+  network {
+    zone = resource.server["db"].zone
+  }
+  port = server.db.port
+  size = count.index
+}
+
+resource "server" "db" {
+  zone = [for r in regions : r]
+}
+`)
+	g, diags := Build(bodies, testConvention)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+
+	if got, want := addrs(g.Nodes), []string{"var.region", "local.prefix", "local.name", "server.web", "server.db"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong nodes\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	web := g.Node(Address{"server", "web"})
+	if web == nil {
+		t.Fatalf("server.web not found")
+	}
+	if got, want := addrs(web.Dependencies()), []string{"local.name", "server.db"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong dependencies\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got, want := web.DeclRange.Start.Line, 2; got != want {
+		t.Errorf("wrong decl line %d; want %d", got, want)
+	}
+	ref := web.References[2]
+	if got, want := ref.Range.Start.Line, 7; got != want {
+		t.Errorf("wrong reference line %d; want %d", got, want)
+	}
+
+	// "resource" is not a symbol, and the iteration variable in the for
+	// expression is not a reference at all.
+	var unresolved []string
+	for _, ref := range g.Unresolved {
+		unresolved = append(unresolved, ref.Traversal.RootName())
+	}
+	if got, want := unresolved, []string{"resource", "count", "regions"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong unresolved roots\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	region := g.Node(Address{"var", "region"})
+	if got, want := addrs(g.Impacted(region)), []string{"local.prefix", "local.name", "server.web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong impacted nodes\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got, want := addrs(region.Dependents()), []string{"local.prefix"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong dependents\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestBuildIndexKeys(t *testing.T) {
+	bodies := parseBodies(t, `
+locals {
+  a = 1
+  b = local["a"]
+  c = local.b[0]
+}
+`)
+	g, diags := Build(bodies, testConvention)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	if got, want := addrs(g.Node(Address{"local", "a"}).Dependents()), []string{"local.b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong dependents of local.a %#v; want %#v", got, want)
+	}
+	if got, want := addrs(g.Node(Address{"local", "b"}).Dependents()), []string{"local.c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong dependents of local.b %#v; want %#v", got, want)
+	}
+}
+
+func TestBuildDuplicate(t *testing.T) {
+	bodies := parseBodies(t, `variable "a" {}`, `variable "a" {}`)
+	g, diags := Build(bodies, testConvention)
+	if len(diags) != 1 || diags[0].Summary != "Duplicate definition" {
+		t.Fatalf("wrong diagnostics: %s", diags)
+	}
+	if got, want := diags[0].Subject.Filename, "b.hcl"; got != want {
+		t.Errorf("wrong subject file %q; want %q", got, want)
+	}
+	if got := len(g.Nodes); got != 1 {
+		t.Errorf("wrong number of nodes %d; want 1", got)
+	}
+}
+
+func TestBuildJSON(t *testing.T) {
+	file, diags := json.Parse([]byte(`{
+  "variable": {"a": {}},
+  "resource": {"server": {"web": {"name": "${var.a}"}}}
+}`), "test.json")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	g, diags := Build([]hcl.Body{file.Body}, testConvention)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	if got, want := addrs(g.Node(Address{"server", "web"}).Dependencies()), []string{"var.a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong dependencies %#v; want %#v", got, want)
+	}
+}
@@ -0,0 +1,132 @@
+package hclgraph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// EvalOrder returns the given attributes in an order in which they can be
+// evaluated, such that each attribute comes after all of the attributes that
+// its expression refers to. Attributes that do not depend on one another
+// remain in source order.
+//
+// The attributes refer to one another by traversals starting with the given
+// prefix followed by the attribute name, such as local.name for attributes
+// of a "locals" block with the prefix ["local"]. With an empty prefix, they
+// refer to one another by name alone. Other traversals are ignored.
+//
+// An error diagnostic is returned for each cycle of references, describing
+// the path of the cycle. Attributes that are part of a cycle, or that depend
+// on an attribute that is part of a cycle, cannot be evaluated and so are
+// not included in the result.
+func EvalOrder(attrs hcl.Attributes, prefix Address) ([]*hcl.Attribute, hcl.Diagnostics) {
+	type dep struct {
+		name string
+		rng  hcl.Range
+	}
+	deps := make(map[string][]dep)
+	for name, attr := range attrs {
+		for _, traversal := range attr.Expr.Variables() {
+			if target, ok := attrReference(traversal, prefix); ok && attrs[target] != nil {
+				deps[name] = append(deps[name], dep{target, traversal.SourceRange()})
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+		failed
+	)
+	var diags hcl.Diagnostics
+	var ret []*hcl.Attribute
+	state := make(map[string]int)
+	var path []dep
+
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		switch state[name] {
+		case done:
+			return true
+		case failed:
+			return false
+		case visiting:
+			// We've found a cycle, which is the part of the path from
+			// the earlier visit of this attribute to here.
+			start := 0
+			for path[start].name != name {
+				start++
+			}
+			cycle := path[start:]
+			names := make([]string, 0, len(cycle)+1)
+			for _, step := range cycle {
+				names = append(names, append(prefix[:len(prefix):len(prefix)], step.name).String())
+			}
+			names = append(names, names[0])
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Reference cycle",
+				Detail:   fmt.Sprintf("The following attributes refer to one another in a cycle, so they cannot be evaluated: %s.", strings.Join(names, " -> ")),
+				Subject:  cycle[len(cycle)-1].rng.Ptr(),
+			})
+			return false
+		}
+
+		state[name] = visiting
+		ok := true
+		for _, d := range deps[name] {
+			path = append(path, dep{name, d.rng})
+			if !visit(d.name) {
+				ok = false
+			}
+			path = path[:len(path)-1]
+		}
+		if !ok {
+			state[name] = failed
+			return false
+		}
+		state[name] = done
+		ret = append(ret, attrs[name])
+		return true
+	}
+
+	for _, attr := range attrs.InSourceOrder() {
+		visit(attr.Name)
+	}
+	return ret, diags
+}
+
+// attrReference returns the name of the attribute referred to by the given
+// traversal, which must begin with the given prefix.
+func attrReference(traversal hcl.Traversal, prefix Address) (string, bool) {
+	if len(traversal) <= len(prefix) {
+		return "", false
+	}
+	for i, step := range traversal[:len(prefix)+1] {
+		var name string
+		switch ts := step.(type) {
+		case hcl.TraverseRoot:
+			name = ts.Name
+		case hcl.TraverseAttr:
+			name = ts.Name
+		case hcl.TraverseIndex:
+			if !ts.Key.IsKnown() || ts.Key.IsNull() || ts.Key.Type() != cty.String {
+				return "", false
+			}
+			name = ts.Key.AsString()
+		default:
+			return "", false
+		}
+		if i == len(prefix) {
+			return name, true
+		}
+		if name != prefix[i] {
+			return "", false
+		}
+	}
+	return "", false
+}
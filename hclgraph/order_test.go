@@ -0,0 +1,82 @@
+package hclgraph
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+func TestEvalOrder(t *testing.T) {
+	tests := []struct {
+		src        string
+		prefix     Address
+		want       []string
+		wantCycles []string
+	}{
+		{
+			"a = 1\nb = 2\n",
+			Address{"local"},
+			[]string{"a", "b"},
+			nil,
+		},
+		{
+			"a = local.c\nb = local[\"a\"] + var.x\nc = 3\n",
+			Address{"local"},
+			[]string{"c", "a", "b"},
+			nil,
+		},
+		{
+			"a = c\nb = a\nc = 1\nd = local.a\n",
+			nil,
+			[]string{"c", "a", "b", "d"},
+			nil,
+		},
+		{
+			"a = local.b\nb = local.c\nc = local.a\nd = local.a\ne = 1\n",
+			Address{"local"},
+			[]string{"e"},
+			[]string{"The following attributes refer to one another in a cycle, so they cannot be evaluated: local.a -> local.b -> local.c -> local.a."},
+		},
+		{
+			"a = a + 1\nb = [for a in c : a]\nc = []\n",
+			nil,
+			[]string{"c", "b"},
+			[]string{"The following attributes refer to one another in a cycle, so they cannot be evaluated: a -> a."},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags)
+			}
+			attrs, diags := file.Body.JustAttributes()
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags)
+			}
+
+			order, diags := EvalOrder(attrs, test.prefix)
+			var got []string
+			for _, attr := range order {
+				got = append(got, attr.Name)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong order\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+
+			var gotCycles []string
+			for _, diag := range diags {
+				gotCycles = append(gotCycles, diag.Detail)
+				if diag.Subject == nil {
+					t.Errorf("diagnostic has no subject")
+				}
+			}
+			if !reflect.DeepEqual(gotCycles, test.wantCycles) {
+				t.Errorf("wrong cycles\ngot:  %#v\nwant: %#v", gotCycles, test.wantCycles)
+			}
+		})
+	}
+}
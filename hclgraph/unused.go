@@ -0,0 +1,39 @@
+package hclgraph
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// Unused returns a warning diagnostic for each symbol in the graph that is
+// defined by a block type whose convention sets ReportUnused but that is not
+// referred to by any external reference or by the definition of any other
+// symbol. References from a symbol's own definition do not count.
+//
+// The subject of each diagnostic is the source range of the definition, and
+// the diagnostics are in the order the symbols were defined.
+func (g *Graph) Unused() hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	for _, node := range g.Nodes {
+		if !node.reportUnused || node.used() {
+			continue
+		}
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Unused definition",
+			Detail:   fmt.Sprintf("%s is defined but never referenced.", node.Address),
+			Subject:  node.DeclRange.Ptr(),
+		})
+	}
+	return diags
+}
+
+func (n *Node) used() bool {
+	for _, ref := range n.ReferencedBy {
+		if ref.From != n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package hclgraph
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+func TestGraphUnused(t *testing.T) {
+	conv := Convention{
+		"variable": {
+			LabelNames:   []string{"name"},
+			Prefix:       []string{"var"},
+			ReportUnused: true,
+		},
+		"locals": {
+			Prefix:       []string{"local"},
+			Attributes:   true,
+			ReportUnused: true,
+		},
+		"resource": {
+			LabelNames: []string{"type", "name"},
+		},
+	}
+	bodies := parseBodies(t, `
+variable "used_by_resource" {}
+variable "used_by_root" {}
+variable "used_by_output" {}
+variable "unused" {}
+
+locals {
+  a = var.used_by_resource
+  b = local.b
+  c = local.d
+  d = local.c
+}
+`, `
+name = var.used_by_root
+
+resource "server" "web" {
+  name = local.a
+}
+
+resource "server" "unused" {
+}
+
+output "x" {
+  value = var.used_by_output
+}
+`)
+	g, diags := Build(bodies, conv)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+
+	var got []string
+	for _, diag := range g.Unused() {
+		if diag.Severity != hcl.DiagWarning {
+			t.Errorf("wrong severity for %s", diag.Subject)
+		}
+		got = append(got, diag.Detail)
+	}
+	want := []string{
+		"var.unused is defined but never referenced.",
+		"local.b is defined but never referenced.",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong unused definitions\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	if got, want := len(g.External), 2; got != want {
+		t.Errorf("wrong number of external references %d; want %d", got, want)
+	}
+}
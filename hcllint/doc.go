@@ -0,0 +1,43 @@
+// Package hcllint is a framework for checking HCL configuration files for
+// problems of style and usage that are not errors in the language itself.
+//
+// Checks are implemented as rules, each of which inspects whole files,
+// individual bodies or individual expressions and returns diagnostics
+// describing any problems found, optionally with suggested fixes. A Linter
+// loads files, runs each of its registered rules against them, and filters
+// out any diagnostics that are suppressed by comments in the source code.
+//
+// Diagnostics can be suppressed by comments in native syntax files. A comment
+// beginning with "hcllint:ignore" at the end of a line suppresses diagnostics
+// whose subject starts on that line, while such a comment on a line of its
+// own applies to the following line. The names of the rules to suppress, or
+// the codes of the diagnostics to suppress, may follow, separated by spaces
+// or commas. If none are given then all diagnostics are suppressed:
+//
+//     # hcllint:ignore redundant_parens
+//     timeout = (30)
+//
+//     name = "${var.name}" // hcllint:ignore
+//
+// The names may also be separated from the prefix by an equals sign, as in
+// "hcllint:ignore=redundant_parens".
+//
+// JSON has no comments, but by convention a property named "//" is ignored
+// when an object is interpreted as a body, and so can be used as one. Such a
+// property whose value is a string beginning with "hcllint:ignore" suppresses
+// diagnostics whose subject starts within the property that follows it in
+// the same object:
+//
+//     {
+//       "//": "hcllint:ignore HCL4012",
+//       "old_name": "value"
+//     }
+//
+// FileSuppressions returns the suppression comments of a file, which an
+// application can also use to filter the warnings that it produces itself,
+// and then to report the comments that did not suppress anything.
+//
+// A selection of generally-applicable rules is provided by BuiltinRules,
+// and applications may implement their own rules to enforce conventions
+// specific to their configuration languages.
+package hcllint
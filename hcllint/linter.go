@@ -0,0 +1,161 @@
+package hcllint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hclparse"
+)
+
+// Category is the Category of the diagnostics returned by a Linter, unless
+// the rule that produced a diagnostic sets its own category.
+const Category = "lint"
+
+// Linter runs a set of rules against configuration files.
+//
+// The zero value of Linter is not valid. Use NewLinter to create one.
+type Linter struct {
+	rules  []Rule
+	names  map[string]struct{}
+	parser *hclparse.Parser
+}
+
+// NewLinter creates a new Linter with the given rules registered, as if
+// passed one at a time to Register.
+func NewLinter(rules ...Rule) *Linter {
+	l := &Linter{
+		names:  make(map[string]struct{}),
+		parser: hclparse.NewParser(),
+	}
+	for _, rule := range rules {
+		l.Register(rule)
+	}
+	return l
+}
+
+// Register adds the given rule to the rules run by the linter.
+//
+// Register panics if the rule does not implement any of FileRule, BodyRule
+// and ExpressionRule, or if a rule with the same name is already registered.
+func (l *Linter) Register(rule Rule) {
+	_, isFile := rule.(FileRule)
+	_, isBody := rule.(BodyRule)
+	_, isExpr := rule.(ExpressionRule)
+	if !(isFile || isBody || isExpr) {
+		panic(fmt.Sprintf("lint rule %q does not implement any of FileRule, BodyRule or ExpressionRule", rule.Name()))
+	}
+	if _, exists := l.names[rule.Name()]; exists {
+		panic(fmt.Sprintf("duplicate lint rule %q", rule.Name()))
+	}
+	l.names[rule.Name()] = struct{}{}
+	l.rules = append(l.rules, rule)
+}
+
+// Rules returns the rules registered with the linter, in the order they
+// were registered.
+func (l *Linter) Rules() []Rule {
+	ret := make([]Rule, len(l.rules))
+	copy(ret, l.rules)
+	return ret
+}
+
+// Files returns the files loaded by LintFile so far, keyed by filename, which
+// can be used to create a DiagnosticWriter for the returned diagnostics.
+// The result is a copy, which does not reflect any files loaded later.
+func (l *Linter) Files() map[string]*hcl.File {
+	return l.parser.FilesSnapshot()
+}
+
+// LintFile loads the file with the given name and lints it. Files whose
+// names end in ".json" are parsed as JSON, and all other files are parsed
+// as native syntax.
+//
+// Any diagnostics from parsing the file are returned along with those from
+// the lint rules. Rules are not run for a file that has syntax errors.
+func (l *Linter) LintFile(filename string) hcl.Diagnostics {
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(filename, ".json") {
+		file, diags = l.parser.ParseJSONFile(filename)
+	} else {
+		file, diags = l.parser.ParseHCLFile(filename)
+	}
+	if diags.HasErrors() {
+		return diags
+	}
+	return append(diags, l.Lint(file)...)
+}
+
+// Lint runs the registered rules against the given file, which has already
+// been parsed, and returns the resulting diagnostics.
+//
+// Diagnostics that do not specify a severity are given warning severity,
+// and those that do not specify a code or a category are given the rule
+// name as code and Category as category. Any diagnostics that are suppressed
+// by comments in the source code, as described in the package
+// documentation, are omitted, whatever their severity.
+func (l *Linter) Lint(file *hcl.File) hcl.Diagnostics {
+	return l.LintWithSuppressions(file, FileSuppressions(file))
+}
+
+// LintWithSuppressions is like Lint, but uses the given suppressions, which
+// must have been returned by FileSuppressions for the same file. This allows
+// an application to also use them to filter its own diagnostics for the
+// file, and then to report the comments that suppressed nothing at all.
+func (l *Linter) LintWithSuppressions(file *hcl.File, suppressions *Suppressions) hcl.Diagnostics {
+	var ret hcl.Diagnostics
+	for _, rule := range l.rules {
+		for _, diag := range l.runRule(rule, file) {
+			if !suppressions.Suppresses(diag, rule.Name()) {
+				ret = append(ret, diag)
+			}
+		}
+	}
+	return ret
+}
+
+func (l *Linter) runRule(rule Rule, file *hcl.File) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	if fr, ok := rule.(FileRule); ok {
+		diags = append(diags, fr.CheckFile(file)...)
+	}
+
+	if body, ok := file.Body.(*hclsyntax.Body); ok {
+		br, isBody := rule.(BodyRule)
+		er, isExpr := rule.(ExpressionRule)
+		if isBody || isExpr {
+			var walkDiags hcl.Diagnostics
+			hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+				switch tn := node.(type) {
+				case *hclsyntax.Body:
+					if isBody {
+						walkDiags = append(walkDiags, br.CheckBody(tn, file)...)
+					}
+				case hclsyntax.Expression:
+					if isExpr {
+						walkDiags = append(walkDiags, er.CheckExpression(tn, file)...)
+					}
+				}
+				return nil
+			})
+			// The walk visits attributes in no particular order.
+			sortDiagnostics(walkDiags)
+			diags = append(diags, walkDiags...)
+		}
+	}
+
+	for _, diag := range diags {
+		if diag.Severity == hcl.DiagInvalid {
+			diag.Severity = hcl.DiagWarning
+		}
+		if diag.Code == "" {
+			diag.Code = rule.Name()
+		}
+		if diag.Category == "" {
+			diag.Category = Category
+		}
+	}
+	return diags
+}
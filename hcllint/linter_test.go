@@ -0,0 +1,219 @@
+package hcllint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// attrNameRule reports every attribute with the given name, for testing.
+type attrNameRule struct {
+	name string
+	code string
+}
+
+func (r attrNameRule) Name() string {
+	return "no_" + r.name
+}
+
+func (r attrNameRule) CheckBody(body *hclsyntax.Body, file *hcl.File) hcl.Diagnostics {
+	attr, ok := body.Attributes[r.name]
+	if !ok {
+		return nil
+	}
+	return hcl.Diagnostics{{
+		Summary: "Forbidden attribute",
+		Code:    r.code,
+		Subject: &attr.NameRange,
+	}}
+}
+
+func TestLinterLint(t *testing.T) {
+	src := `foo = 1
+bar = 1 # hcllint:ignore no_bar
+# hcllint:ignore
+foo = 2
+block {
+  foo = 3 # hcllint:ignore no_bar, CUSTOM1
+  bar = 4 // hcllint:ignore no_foo
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		// Duplicate attributes are an error, but the body is still usable.
+		var rest hcl.Diagnostics
+		for _, diag := range diags {
+			if diag.Code != hcl.DiagAttributeRedefined {
+				rest = append(rest, diag)
+			}
+		}
+		if rest.HasErrors() {
+			t.Fatalf("unexpected diagnostics: %s", rest)
+		}
+	}
+
+	linter := NewLinter(
+		attrNameRule{name: "foo", code: "CUSTOM1"},
+		attrNameRule{name: "bar"},
+	)
+	diags = linter.Lint(file)
+
+	type result struct {
+		Line     int
+		Code     string
+		Category string
+		Severity hcl.DiagnosticSeverity
+	}
+	var got []result
+	for _, diag := range diags {
+		got = append(got, result{diag.Subject.Start.Line, diag.Code, diag.Category, diag.Severity})
+	}
+	want := []result{
+		{1, "CUSTOM1", Category, hcl.DiagWarning},
+		{7, "no_bar", Category, hcl.DiagWarning},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("wrong diagnostics\ngot:  %#v\nwant: %#v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("wrong diagnostic %d\ngot:  %#v\nwant: %#v", i, got[i], want[i])
+		}
+	}
+
+	// The comment on line 3 is unused because the duplicate attribute on
+	// line 4 is not part of the body, and that on line 7 names a rule that
+	// does not apply to the attribute.
+	suppressions := FileSuppressions(file)
+	linter.LintWithSuppressions(file, suppressions)
+	unused := suppressions.Unused()
+	if len(unused) != 2 || unused[0].Subject.Start.Line != 3 || unused[1].Subject.Start.Line != 7 {
+		t.Errorf("wrong unused suppressions\n%s", unused.Error())
+	}
+}
+
+func TestLinterRegister(t *testing.T) {
+	linter := NewLinter(BuiltinRules()...)
+	if got, want := len(linter.Rules()), 3; got != want {
+		t.Fatalf("wrong number of rules %d; want %d", got, want)
+	}
+
+	mustPanic := func(name string, rule Rule) {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register did not panic")
+				}
+			}()
+			linter.Register(rule)
+		})
+	}
+	mustPanic("duplicate", RedundantParens())
+	mustPanic("no checks", namedRule("nothing"))
+}
+
+type namedRule string
+
+func (r namedRule) Name() string {
+	return string(r)
+}
+
+func TestLinterLintFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hcllint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	good := filepath.Join(dir, "good.hcl")
+	bad := filepath.Join(dir, "bad.hcl")
+	json := filepath.Join(dir, "config.json")
+	ioutil.WriteFile(good, []byte("a = (1)\n"), 0644)
+	ioutil.WriteFile(bad, []byte("a = (1\n"), 0644)
+	ioutil.WriteFile(json, []byte(`{"a": "${b}"}`), 0644)
+
+	linter := NewLinter(BuiltinRules()...)
+
+	diags := linter.LintFile(good)
+	if len(diags) != 1 || diags[0].Code != "redundant_parens" {
+		t.Errorf("wrong diagnostics for good file: %s", diags)
+	}
+
+	diags = linter.LintFile(bad)
+	if len(diags) != 1 || diags[0].Code != hcl.DiagUnbalancedParentheses {
+		t.Errorf("wrong diagnostics for bad file: %s", diags)
+	}
+
+	// The built-in rules inspect only native syntax.
+	diags = linter.LintFile(json)
+	if len(diags) != 0 {
+		t.Errorf("unexpected diagnostics for JSON file: %s", diags)
+	}
+
+	if got := linter.Files(); got[good] == nil || got[bad] == nil || got[json] == nil {
+		t.Errorf("files not recorded: %#v", got)
+	}
+}
+
+// numberRule reports every number literal, giving the number as the summary.
+// Zero is reported without a subject, for testing diagnostics that do not
+// relate to any particular part of the source.
+type numberRule struct{}
+
+func (r numberRule) Name() string {
+	return "numbers"
+}
+
+func (r numberRule) CheckExpression(expr hclsyntax.Expression, file *hcl.File) hcl.Diagnostics {
+	lit, ok := expr.(*hclsyntax.LiteralValueExpr)
+	if !ok || lit.Val.Type() != cty.Number {
+		return nil
+	}
+	diag := &hcl.Diagnostic{
+		Summary: lit.Val.AsBigFloat().String(),
+	}
+	if !lit.Val.RawEquals(cty.Zero) {
+		diag.Subject = lit.SrcRange.Ptr()
+	}
+	return hcl.Diagnostics{diag}
+}
+
+func TestLinterLintOrder(t *testing.T) {
+	src := `c = 3
+b = 2
+block {
+  z = 0
+  d = 4
+  e = 5
+}
+a = 1
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+
+	// Attributes are visited in no particular order, so this is repeated
+	// to make it likely that at least one unsorted walk is seen.
+	for i := 0; i < 10; i++ {
+		diags = NewLinter(numberRule{}).Lint(file)
+
+		var got []string
+		for _, diag := range diags {
+			got = append(got, diag.Summary)
+		}
+		want := []string{"0", "3", "2", "4", "5", "1"}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("wrong order\ngot:  %#v\nwant: %#v", got, want)
+		}
+		if diags[0].Subject != nil || diags[0].Code != "numbers" || diags[0].Severity != hcl.DiagWarning {
+			t.Fatalf("wrong diagnostic without subject: %#v", diags[0])
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package hcllint
+
+import (
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+// Rule is the interface implemented by all lint rules.
+//
+// In addition to this interface, each rule must implement at least one of
+// FileRule, BodyRule and ExpressionRule, which determine which parts of
+// each file the rule will inspect.
+type Rule interface {
+	// Name returns a short, unique name for the rule, such as
+	// "redundant_parens". It is used as the Code of the diagnostics
+	// produced by the rule, unless the rule sets its own code, and to
+	// refer to the rule in suppression comments.
+	Name() string
+}
+
+// FileRule is implemented by rules that inspect whole files. File rules are
+// run for files in any syntax.
+type FileRule interface {
+	Rule
+	CheckFile(file *hcl.File) hcl.Diagnostics
+}
+
+// BodyRule is implemented by rules that inspect bodies. It is called for the
+// root body of each file and for the bodies of all nested blocks, but only
+// for files in native syntax.
+type BodyRule interface {
+	Rule
+	CheckBody(body *hclsyntax.Body, file *hcl.File) hcl.Diagnostics
+}
+
+// ExpressionRule is implemented by rules that inspect expressions. It is
+// called for every expression in each file, including expressions nested
+// inside others, but only for files in native syntax.
+type ExpressionRule interface {
+	Rule
+	CheckExpression(expr hclsyntax.Expression, file *hcl.File) hcl.Diagnostics
+}
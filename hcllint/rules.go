@@ -0,0 +1,302 @@
+package hcllint
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+// BuiltinRules returns new instances of all of the rules provided by this
+// package, which are applicable to any configuration language.
+func BuiltinRules() []Rule {
+	return []Rule{
+		InterpolationOnly(),
+		RedundantParens(),
+		InconsistentIndentation(),
+	}
+}
+
+// InterpolationOnly returns a rule that reports string templates consisting
+// only of a single interpolation sequence, like "${var.name}", which
+// were required in older versions of the language but which are now
+// equivalent to the interpolated expression alone.
+func InterpolationOnly() Rule {
+	return interpolationOnlyRule{}
+}
+
+type interpolationOnlyRule struct{}
+
+func (r interpolationOnlyRule) Name() string {
+	return "interpolation_only"
+}
+
+func (r interpolationOnlyRule) CheckExpression(expr hclsyntax.Expression, file *hcl.File) hcl.Diagnostics {
+	wrap, ok := expr.(*hclsyntax.TemplateWrapExpr)
+	if !ok {
+		return nil
+	}
+	rng := wrap.SrcRange
+	diag := &hcl.Diagnostic{
+		Summary: "Interpolation-only expression",
+		Detail:  "A string template containing only a single interpolation sequence is redundant. Use the interpolated expression directly instead.",
+		Subject: &rng,
+	}
+	if file.Bytes != nil {
+		diag.Suggestions = []hcl.Suggestion{{
+			Description: "Remove the template",
+			Edits: []hcl.Edit{{
+				Range:       rng,
+				Replacement: string(wrap.Wrapped.Range().SliceBytes(file.Bytes)),
+			}},
+		}}
+	}
+	return hcl.Diagnostics{diag}
+}
+
+// RedundantParens returns a rule that reports parentheses that have no effect
+// on the meaning of an expression, either because they surround the entire
+// value of an attribute or because they directly surround another set of
+// parentheses.
+func RedundantParens() Rule {
+	return redundantParensRule{}
+}
+
+type redundantParensRule struct{}
+
+func (r redundantParensRule) Name() string {
+	return "redundant_parens"
+}
+
+func (r redundantParensRule) CheckFile(file *hcl.File) hcl.Diagnostics {
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok || file.Bytes == nil {
+		return nil
+	}
+
+	tokens, _ := hclsyntax.LexConfig(file.Bytes, body.SrcRange.Filename, hcl.Pos{Line: 1, Column: 1})
+	matches := make(map[int]int)
+	var stack []int
+	for i, tok := range tokens {
+		switch tok.Type {
+		case hclsyntax.TokenOParen:
+			stack = append(stack, i)
+		case hclsyntax.TokenCParen:
+			if len(stack) > 0 {
+				matches[stack[len(stack)-1]] = i
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	var diags hcl.Diagnostics
+	report := func(open, close hclsyntax.Token, detail string) {
+		rng := hcl.RangeBetween(open.Range, close.Range)
+		diags = append(diags, &hcl.Diagnostic{
+			Summary: "Redundant parentheses",
+			Detail:  detail,
+			Subject: &rng,
+			Suggestions: []hcl.Suggestion{{
+				Description: "Remove the parentheses",
+				Edits: []hcl.Edit{
+					{Range: open.Range},
+					{Range: close.Range},
+				},
+			}},
+		})
+	}
+
+	for open, close := range matches {
+		if inner, ok := matches[open+1]; ok && inner == close-1 {
+			report(tokens[open+1], tokens[inner], "These parentheses directly surround another set of parentheses, and so have no effect.")
+		}
+	}
+
+	hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		attr, ok := node.(*hclsyntax.Attribute)
+		if !ok {
+			return nil
+		}
+		open := 0
+		for open < len(tokens) && tokens[open].Range.Start.Byte < attr.EqualsRange.End.Byte {
+			open++
+		}
+		close, ok := matches[open]
+		if !ok || close+1 >= len(tokens) {
+			return nil
+		}
+		switch tokens[close+1].Type {
+		case hclsyntax.TokenNewline, hclsyntax.TokenEOF, hclsyntax.TokenComment, hclsyntax.TokenCBrace:
+			report(tokens[open], tokens[close], "These parentheses surround the entire value of the attribute, and so have no effect.")
+		}
+		return nil
+	})
+
+	sortDiagnostics(diags)
+	return diags
+}
+
+// InconsistentIndentation returns a rule that reports lines indented with
+// tabs in a file that is otherwise indented with spaces, or vice versa. The
+// indentation style of a file is the style of its first indented line.
+//
+// Where the file is indented with spaces, each diagnostic suggests replacing
+// each tab with two spaces, which is the indentation style produced by
+// package hclwrite.
+func InconsistentIndentation() Rule {
+	return inconsistentIndentationRule{}
+}
+
+type inconsistentIndentationRule struct{}
+
+func (r inconsistentIndentationRule) Name() string {
+	return "inconsistent_indentation"
+}
+
+func (r inconsistentIndentationRule) CheckFile(file *hcl.File) hcl.Diagnostics {
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok || file.Bytes == nil {
+		return nil
+	}
+	filename := body.SrcRange.Filename
+
+	// Lines inside heredoc templates and multi-line comments are not
+	// indented as part of the configuration structure, so we skip them.
+	skip := make(map[int]bool)
+	tokens, _ := hclsyntax.LexConfig(file.Bytes, filename, hcl.Pos{Line: 1, Column: 1})
+	heredocStart := 0
+	for _, tok := range tokens {
+		switch tok.Type {
+		case hclsyntax.TokenOHeredoc:
+			heredocStart = tok.Range.Start.Line
+		case hclsyntax.TokenCHeredoc:
+			for line := heredocStart + 1; line <= tok.Range.Start.Line; line++ {
+				skip[line] = true
+			}
+		case hclsyntax.TokenComment:
+			for line := tok.Range.Start.Line + 1; line <= tok.Range.End.Line; line++ {
+				if line < tok.Range.End.Line || tok.Range.End.Column > 1 {
+					skip[line] = true
+				}
+			}
+		}
+	}
+
+	var diags hcl.Diagnostics
+	style := byte(0)
+	offset := 0
+	for i, line := range bytes.Split(file.Bytes, []byte{'\n'}) {
+		lineNum := i + 1
+		lineStart := offset
+		offset += len(line) + 1
+
+		indent := len(line) - len(bytes.TrimLeft(line, " \t"))
+		if indent == 0 || indent == len(bytes.TrimRight(line, "\r")) || skip[lineNum] {
+			continue
+		}
+		ws := line[:indent]
+		if style == 0 {
+			style = ws[0]
+		}
+		other := byte('\t')
+		if style == '\t' {
+			other = ' '
+		}
+		if bytes.IndexByte(ws, other) < 0 {
+			continue
+		}
+
+		rng := hcl.Range{
+			Filename: filename,
+			Start:    hcl.Pos{Line: lineNum, Column: 1, Byte: lineStart},
+			End:      hcl.Pos{Line: lineNum, Column: indent + 1, Byte: lineStart + indent},
+		}
+		diag := &hcl.Diagnostic{
+			Summary: "Inconsistent indentation",
+			Detail:  fmt.Sprintf("This line is indented with %s, but this file is indented with %s.", indentName(other), indentName(style)),
+			Subject: &rng,
+		}
+		if style == ' ' {
+			diag.Suggestions = []hcl.Suggestion{{
+				Description: "Indent with spaces",
+				Edits: []hcl.Edit{{
+					Range:       rng,
+					Replacement: strings.Replace(string(ws), "\t", "  ", -1),
+				}},
+			}}
+		}
+		diags = append(diags, diag)
+	}
+	return diags
+}
+
+func indentName(c byte) string {
+	if c == '\t' {
+		return "tabs"
+	}
+	return "spaces"
+}
+
+// ComplexExpressions returns a rule that reports attribute values whose
+// expressions are more complex than the given limits allow, as measured by
+// hclsyntax.ExpressionMetrics. Such expressions are hard to read and to
+// maintain, and are better split into several simpler parts.
+//
+// Only the Expressions, Depth, FunctionCalls, Operators and ForExprs fields of
+// the limits are used, and a limit of zero means no limit.
+//
+// This rule is not included in BuiltinRules, because suitable limits depend
+// on the application.
+func ComplexExpressions(limits hclsyntax.Metrics) Rule {
+	return complexExpressionsRule{limits: limits}
+}
+
+type complexExpressionsRule struct {
+	limits hclsyntax.Metrics
+}
+
+func (r complexExpressionsRule) Name() string {
+	return "complex_expression"
+}
+
+func (r complexExpressionsRule) CheckBody(body *hclsyntax.Body, file *hcl.File) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	for _, attr := range body.Attributes {
+		m := hclsyntax.ExpressionMetrics(attr.Expr)
+		var problems []string
+		check := func(got, limit int, noun string) {
+			if limit > 0 && got > limit {
+				problems = append(problems, fmt.Sprintf("%d %s, more than the limit of %d", got, noun, limit))
+			}
+		}
+		check(m.Expressions, r.limits.Expressions, "expression nodes")
+		check(m.Depth, r.limits.Depth, "levels of nesting")
+		check(m.FunctionCalls, r.limits.FunctionCalls, "function calls")
+		check(m.Operators, r.limits.Operators, "operators")
+		check(m.ForExprs, r.limits.ForExprs, "'for' expressions")
+		if len(problems) == 0 {
+			continue
+		}
+		rng := attr.Expr.Range()
+		diags = append(diags, &hcl.Diagnostic{
+			Summary: "Overly complex expression",
+			Detail:  fmt.Sprintf("The value of %q has %s. Consider splitting it into several simpler expressions.", attr.Name, strings.Join(problems, ", and ")),
+			Subject: &rng,
+		})
+	}
+	return diags
+}
+
+// sortDiagnostics sorts diagnostics with subjects into source order, after
+// any diagnostics that have no subject.
+func sortDiagnostics(diags hcl.Diagnostics) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Subject == nil || diags[j].Subject == nil {
+			return diags[i].Subject == nil && diags[j].Subject != nil
+		}
+		return diags[i].Subject.Start.Byte < diags[j].Subject.Start.Byte
+	})
+}
@@ -0,0 +1,141 @@
+package hcllint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+func TestBuiltinRules(t *testing.T) {
+	tests := []struct {
+		rule      Rule
+		src       string
+		wantLines []int
+		wantFixed string
+	}{
+		{
+			InterpolationOnly(),
+			"a = \"${b}\"\nc = \"x${d}\"\ne = \"${f(\"${g}\")}\"\n",
+			[]int{1, 3, 3},
+			"a = b\nc = \"x${d}\"\ne = f(\"${g}\")\n",
+		},
+		{
+			RedundantParens(),
+			"a = (1 + 2)\nb = (1 + 2) * 3\nc = f((x))\nd = { (k) = 1 }\ne = ((y)) # comment\n",
+			[]int{1, 3, 5, 5},
+			"a = 1 + 2\nb = (1 + 2) * 3\nc = f(x)\nd = { (k) = 1 }\ne = y # comment\n",
+		},
+		{
+			InconsistentIndentation(),
+			"a {\n  b = 1\n\tc = 2\n \td = 3\n  e = <<EOT\n\tfoo\nEOT\n}\n",
+			[]int{3, 4},
+			"a {\n  b = 1\n  c = 2\n   d = 3\n  e = <<EOT\n\tfoo\nEOT\n}\n",
+		},
+		{
+			InconsistentIndentation(),
+			"a {\n\tb = 1\n  c = 2\n\t/*\n  comment\n\t*/\n}\n",
+			[]int{3},
+			"a {\n\tb = 1\n  c = 2\n\t/*\n  comment\n\t*/\n}\n",
+		},
+		{
+			ComplexExpressions(hclsyntax.Metrics{Depth: 3, Operators: 1}),
+			"a = 1 + 2\nb = f(g(h(x)))\nc {\n  d = x + y - z\n}\n",
+			[]int{2, 4},
+			"a = 1 + 2\nb = f(g(h(x)))\nc {\n  d = x + y - z\n}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.rule.Name(), func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(test.src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse diagnostics: %s", diags)
+			}
+
+			diags = NewLinter(test.rule).Lint(file)
+			var gotLines []int
+			var edits []hcl.Edit
+			for _, diag := range diags {
+				gotLines = append(gotLines, diag.Subject.Start.Line)
+				if len(diag.Suggestions) > 0 {
+					edits = append(edits, diag.Suggestions[0].Edits...)
+				}
+			}
+			if !intsEqual(gotLines, test.wantLines) {
+				t.Errorf("wrong diagnostic lines %v; want %v\n%s", gotLines, test.wantLines, diags)
+			}
+
+			// Where diagnostics overlap we apply only the fixes for the
+			// outermost, since the edits would otherwise conflict.
+			fixed := applyNonOverlapping(t, file.Bytes, edits)
+			if got := string(fixed); got != test.wantFixed {
+				t.Errorf("wrong fixed result\ngot:\n%s\nwant:\n%s", got, test.wantFixed)
+			}
+		})
+	}
+}
+
+func applyNonOverlapping(t *testing.T, src []byte, edits []hcl.Edit) []byte {
+	var kept []hcl.Edit
+	for _, edit := range edits {
+		overlaps := false
+		for _, k := range kept {
+			if edit.Range.Start.Byte < k.Range.End.Byte && k.Range.Start.Byte < edit.Range.End.Byte {
+				overlaps = true
+			}
+			if edit.Range.Start.Byte >= k.Range.Start.Byte && edit.Range.End.Byte <= k.Range.End.Byte && k.Replacement != "" {
+				overlaps = true
+			}
+		}
+		if !overlaps {
+			kept = append(kept, edit)
+		}
+	}
+	ret, err := hcl.ApplyEdits(src, kept)
+	if err != nil {
+		t.Fatalf("failed to apply edits: %s", err)
+	}
+	return ret
+}
+
+func intsEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSortDiagnostics(t *testing.T) {
+	at := func(byte int) *hcl.Range {
+		return &hcl.Range{
+			Filename: "test.hcl",
+			Start:    hcl.Pos{Line: 1, Column: byte + 1, Byte: byte},
+			End:      hcl.Pos{Line: 1, Column: byte + 2, Byte: byte + 1},
+		}
+	}
+	diags := hcl.Diagnostics{
+		{Summary: "c", Subject: at(8)},
+		{Summary: "a", Subject: at(2)},
+		{Summary: "none1"},
+		{Summary: "b1", Subject: at(5)},
+		{Summary: "none2"},
+		{Summary: "b2", Subject: at(5)},
+	}
+	sortDiagnostics(diags)
+
+	var got []string
+	for _, diag := range diags {
+		got = append(got, diag.Summary)
+	}
+	want := []string{"none1", "none2", "a", "b1", "b2", "c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong order\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
@@ -0,0 +1,214 @@
+package hcllint
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hcl/json"
+)
+
+const suppressPrefix = "hcllint:ignore"
+
+// UnusedSuppressionCode is the Code of the diagnostics returned by
+// Suppressions.Unused.
+const UnusedSuppressionCode = "unused_suppression"
+
+// Suppression is a comment that suppresses diagnostics, as described in the
+// package documentation.
+type Suppression struct {
+	// Names are the rule names and diagnostic codes given in the comment,
+	// or nil if the comment suppresses all diagnostics.
+	Names []string
+
+	// Range is the range of the comment itself, and Scope is the range of
+	// the source code that it applies to. A diagnostic is suppressed if the
+	// start of its subject is within the scope.
+	Range hcl.Range
+	Scope hcl.Range
+}
+
+// Suppressions are the suppression comments of a single file, as returned by
+// FileSuppressions. As well as deciding which diagnostics are suppressed,
+// they record which of the comments have suppressed anything, so that unused
+// comments can be reported.
+//
+// Suppressions are not safe for concurrent use by multiple goroutines.
+type Suppressions struct {
+	list []Suppression
+	used []bool
+}
+
+// FileSuppressions finds the suppression comments in the given file, which
+// may be in either native or JSON syntax. The result is empty for files of
+// other syntaxes and for files whose source code is not available.
+func FileSuppressions(file *hcl.File) *Suppressions {
+	ret := &Suppressions{}
+	if body, ok := file.Body.(*hclsyntax.Body); ok && file.Bytes != nil {
+		ret.addNative(file.Bytes, body.SrcRange.Filename)
+	}
+	for _, comment := range json.Comments(file) {
+		names, ok := parseSuppression(comment.Text)
+		if ok && comment.Next != nil {
+			ret.add(names, comment.Range, *comment.Next)
+		}
+	}
+	return ret
+}
+
+func (s *Suppressions) addNative(src []byte, filename string) {
+	lines := hcl.NewLineIndex(src, filename)
+	tokens, _ := hclsyntax.LexConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	lastContentLine := 0
+	for _, tok := range tokens {
+		if tok.Type != hclsyntax.TokenComment {
+			if tok.Type != hclsyntax.TokenNewline {
+				lastContentLine = tok.Range.End.Line
+			}
+			continue
+		}
+		text := string(tok.Bytes)
+		switch {
+		case strings.HasPrefix(text, "#"):
+			text = text[1:]
+		case strings.HasPrefix(text, "//"):
+			text = text[2:]
+		case strings.HasPrefix(text, "/*"):
+			text = strings.TrimSuffix(text[2:], "*/")
+		}
+		names, ok := parseSuppression(text)
+		if !ok {
+			continue
+		}
+
+		// A comment at the end of a line applies to that line, while a
+		// comment on a line of its own applies to the following line.
+		line := tok.Range.Start.Line
+		if line != lastContentLine {
+			line++
+		}
+		rng := tok.Range
+		if strings.HasSuffix(string(tok.Bytes), "\n") {
+			// Line comments include their terminating newline, which is
+			// not really part of the comment.
+			rng.End = lines.PosForOffset(rng.End.Byte - 1)
+		}
+		scope, ok := lines.LineRange(line)
+		if !ok {
+			// A comment on the last line of a file applies to nothing.
+			scope = rng
+		}
+		s.add(names, rng, scope)
+	}
+}
+
+// parseSuppression parses the text of a comment, returning the names that
+// it suppresses and true if it is a suppression comment.
+func parseSuppression(text string) ([]string, bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, suppressPrefix) {
+		return nil, false
+	}
+	rest := text[len(suppressPrefix):]
+	if rest != "" && !strings.ContainsAny(rest[:1], "=, \t") {
+		return nil, false // a different word that starts with our prefix
+	}
+	names := strings.FieldsFunc(rest, func(r rune) bool {
+		return r == '=' || r == ',' || r == ' ' || r == '\t'
+	})
+	if len(names) == 0 {
+		names = nil // suppresses all diagnostics
+	}
+	return names, true
+}
+
+func (s *Suppressions) add(names []string, rng, scope hcl.Range) {
+	s.list = append(s.list, Suppression{
+		Names: names,
+		Range: rng,
+		Scope: scope,
+	})
+	s.used = append(s.used, false)
+}
+
+// List returns the suppression comments of the file, in source order.
+func (s *Suppressions) List() []Suppression {
+	ret := make([]Suppression, len(s.list))
+	copy(ret, s.list)
+	return ret
+}
+
+// Suppresses returns true if the given diagnostic, produced by the lint rule
+// with the given name, is suppressed by one of the comments. A comment can
+// refer either to the rule name or to the code of the diagnostic. The rule
+// name may be empty for diagnostics that were not produced by a lint rule.
+//
+// Any comment that suppresses the diagnostic is marked as used.
+func (s *Suppressions) Suppresses(diag *hcl.Diagnostic, ruleName string) bool {
+	if diag.Subject == nil {
+		return false
+	}
+	start := diag.Subject.Start.Byte
+	ret := false
+	for i, sup := range s.list {
+		if diag.Subject.Filename != sup.Scope.Filename || start < sup.Scope.Start.Byte || start > sup.Scope.End.Byte {
+			continue
+		}
+		if sup.Names == nil || containsString(sup.Names, ruleName) || containsString(sup.Names, diag.Code) {
+			s.used[i] = true
+			ret = true
+		}
+	}
+	return ret
+}
+
+// Filter returns the given diagnostics without those that are suppressed,
+// as decided by Suppresses. This can be used to allow users to suppress the
+// warnings produced by an application, in addition to those produced by
+// lint rules. Only warnings can be suppressed, so error diagnostics are
+// always retained.
+func (s *Suppressions) Filter(diags hcl.Diagnostics) hcl.Diagnostics {
+	var ret hcl.Diagnostics
+	for _, diag := range diags {
+		if diag.Severity == hcl.DiagWarning && s.Suppresses(diag, "") {
+			continue
+		}
+		ret = append(ret, diag)
+	}
+	return ret
+}
+
+// Unused returns a warning for each of the comments that has not suppressed
+// any diagnostics so far, which can be reported once all of the diagnostics
+// for the file have been produced. Such comments are likely to be left over
+// from problems that have since been fixed.
+func (s *Suppressions) Unused() hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	for i, sup := range s.list {
+		if s.used[i] {
+			continue
+		}
+		rng := sup.Range
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Unused suppression comment",
+			Detail:   "This comment does not suppress any diagnostics, and so can be removed.",
+			Code:     UnusedSuppressionCode,
+			Category: Category,
+			Subject:  &rng,
+		})
+	}
+	return diags
+}
+
+func containsString(list []string, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package hcllint
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hcl/json"
+)
+
+func TestFileSuppressions(t *testing.T) {
+	warningAt := func(filename string, line, byte int, code string) *hcl.Diagnostic {
+		return &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Test warning",
+			Code:     code,
+			Subject: &hcl.Range{
+				Filename: filename,
+				Start:    hcl.Pos{Line: line, Column: 1, Byte: byte},
+				End:      hcl.Pos{Line: line, Column: 2, Byte: byte + 1},
+			},
+		}
+	}
+
+	t.Run("native", func(t *testing.T) {
+		src := "a = 1 # hcllint:ignore=HCL0001\n# hcllint:ignore HCL0002\nb = 2\nc = 3 # hcllint:ignorethis\n"
+		file, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			t.Fatalf("unexpected diagnostics: %s", diags.Error())
+		}
+
+		s := FileSuppressions(file)
+		list := s.List()
+		if len(list) != 2 {
+			t.Fatalf("wrong number of suppressions %d; want 2", len(list))
+		}
+		if got := list[0].Names; len(got) != 1 || got[0] != "HCL0001" {
+			t.Errorf("wrong names %q", got)
+		}
+		if got := list[0].Range; got.Start.Column != 7 || got.End.Column != 31 {
+			t.Errorf("wrong comment range %s", got)
+		}
+		if got := list[1].Scope; got.Start.Line != 3 || got.End.Line != 3 {
+			t.Errorf("wrong scope %s", got)
+		}
+
+		errDiag := warningAt("test.hcl", 1, 0, "HCL0001")
+		errDiag.Severity = hcl.DiagError
+		got := s.Filter(hcl.Diagnostics{
+			warningAt("test.hcl", 1, 0, "HCL0001"),
+			warningAt("test.hcl", 1, 0, "HCL0002"),
+			warningAt("other.hcl", 1, 0, "HCL0001"),
+			errDiag,
+		})
+		if len(got) != 3 || got[0].Code != "HCL0002" || got[1].Subject.Filename != "other.hcl" || got[2] != errDiag {
+			t.Errorf("wrong filtered diagnostics\n%s", got.Error())
+		}
+
+		unused := s.Unused()
+		if len(unused) != 1 || unused[0].Subject.Start.Line != 2 || unused[0].Code != UnusedSuppressionCode {
+			t.Errorf("wrong unused suppressions\n%s", unused.Error())
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		src := `{
+  "//": "hcllint:ignore",
+  "a": {
+    "b": 1
+  },
+  "c": 2,
+  "//": "hcllint:ignore"
+}`
+		file, diags := json.Parse([]byte(src), "test.json")
+		if diags.HasErrors() {
+			t.Fatalf("unexpected diagnostics: %s", diags.Error())
+		}
+
+		s := FileSuppressions(file)
+		if got := len(s.List()); got != 1 {
+			t.Fatalf("wrong number of suppressions %d; want 1", got)
+		}
+		got := s.Filter(hcl.Diagnostics{
+			warningAt("test.json", 4, 41, "HCL0001"),
+			warningAt("test.json", 6, 55, "HCL0001"),
+		})
+		if len(got) != 1 || got[0].Subject.Start.Line != 6 {
+			t.Errorf("wrong filtered diagnostics\n%s", got.Error())
+		}
+		if unused := s.Unused(); len(unused) != 0 {
+			t.Errorf("unexpected unused suppressions\n%s", unused.Error())
+		}
+	})
+}
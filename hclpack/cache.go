@@ -0,0 +1,97 @@
+package hclpack
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// Cache is a persistent cache of packed native syntax files, stored as files
+// in a directory on disk. It allows a program that repeatedly parses the
+// same unchanged files, such as a CLI tool run many times in the same working
+// directory, to load the packed representation rather than re-parsing the
+// source each time.
+//
+// Entries are keyed by a hash of the filename, start position, and source
+// code, so a changed file will never be loaded from a stale entry. Stale
+// entries are not removed automatically; the caller may delete the cache
+// directory at any time.
+//
+// Failures to read or write cache entries are not reported, since the cache
+// is only an optimization: the source is parsed as normal in that case.
+type Cache struct {
+	dir string
+}
+
+// NewCache creates a cache that stores its entries in the given directory,
+// which will be created on the first write if it does not already exist.
+func NewCache(dir string) *Cache {
+	return &Cache{
+		dir: dir,
+	}
+}
+
+// PackNativeFile is like the package-level function of the same name, but
+// returns a body loaded from the cache if there is an entry for the given
+// arguments, and otherwise stores the result in the cache.
+//
+// Results with error diagnostics are not cached, so that those diagnostics
+// are returned again on subsequent calls.
+func (c *Cache) PackNativeFile(src []byte, filename string, start hcl.Pos) (*Body, hcl.Diagnostics) {
+	entry := c.entryPath(src, filename, start)
+
+	if data, err := ioutil.ReadFile(entry); err == nil {
+		body := &Body{}
+		if err := body.UnmarshalJSON(data); err == nil {
+			return body, nil
+		}
+		// If the entry is corrupt then we'll just overwrite it below.
+	}
+
+	body, diags := PackNativeFile(src, filename, start)
+	if diags.HasErrors() {
+		return body, diags
+	}
+
+	data, err := body.MarshalJSON()
+	if err != nil {
+		return body, diags
+	}
+	c.write(entry, data)
+	return body, diags
+}
+
+// entryPath returns the path of the cache entry for the given arguments.
+func (c *Cache) entryPath(src []byte, filename string, start hcl.Pos) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%q %d %d %d\n", filename, start.Line, start.Column, start.Byte)
+	h.Write(src)
+	return filepath.Join(c.dir, hex.EncodeToString(h.Sum(nil))+".json")
+}
+
+// write atomically replaces the given cache entry with the given data,
+// ignoring any errors.
+func (c *Cache) write(entry string, data []byte) {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return
+	}
+	f, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), entry)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
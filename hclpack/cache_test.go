@@ -0,0 +1,72 @@
+package hclpack
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hclpack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache := NewCache(filepath.Join(dir, "cache"))
+	src := []byte("service \"example\" {\n  priority = 2\n}\n")
+	start := hcl.Pos{Line: 1, Column: 1}
+
+	first, diags := cache.PackNativeFile(src, "example.svc", start)
+	if diags.HasErrors() {
+		t.Fatalf("Failed to parse: %s", diags.Error())
+	}
+	entries, _ := filepath.Glob(filepath.Join(dir, "cache", "*.json"))
+	if len(entries) != 1 {
+		t.Fatalf("wrong number of cache entries %d; want 1", len(entries))
+	}
+
+	second, diags := cache.PackNativeFile(src, "example.svc", start)
+	if diags.HasErrors() {
+		t.Fatalf("Failed to load: %s", diags.Error())
+	}
+	if !cmp.Equal(first, second) {
+		t.Errorf("cached body does not match\n%s", cmp.Diff(first, second))
+	}
+
+	// Corrupt the entry to prove that the second call above read from it,
+	// and that a corrupt entry is replaced.
+	if err := ioutil.WriteFile(entries[0], []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	third, diags := cache.PackNativeFile(src, "example.svc", start)
+	if diags.HasErrors() {
+		t.Fatalf("Failed to parse: %s", diags.Error())
+	}
+	if !cmp.Equal(first, third) {
+		t.Errorf("re-parsed body does not match\n%s", cmp.Diff(first, third))
+	}
+
+	// A different filename or source produces a separate entry.
+	cache.PackNativeFile(src, "other.svc", start)
+	cache.PackNativeFile(append(src, '\n'), "example.svc", start)
+	entries, _ = filepath.Glob(filepath.Join(dir, "cache", "*.json"))
+	if len(entries) != 3 {
+		t.Errorf("wrong number of cache entries %d; want 3", len(entries))
+	}
+
+	// Invalid source is not cached.
+	_, diags = cache.PackNativeFile([]byte("priority = \n"), "broken.svc", start)
+	if !diags.HasErrors() {
+		t.Fatalf("no errors for invalid source")
+	}
+	entries, _ = filepath.Glob(filepath.Join(dir, "cache", "*.json"))
+	if len(entries) != 3 {
+		t.Errorf("wrong number of cache entries %d; want 3", len(entries))
+	}
+}
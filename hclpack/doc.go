@@ -11,4 +11,8 @@
 // and via other APIs the caller must somehow gain access to the original source
 // code that the packed representation was built from, which is a problem that
 // must be solved somehow by the calling application.
+//
+// Because packed structures can be serialized, they can also be retained
+// between runs of a program to avoid re-parsing unchanged source files. Cache
+// provides a simple on-disk cache for this purpose.
 package hclpack
@@ -183,11 +183,13 @@ func (b *Body) content(schema *hcl.BodySchema, remain *Body) (*hcl.BodyContent,
 		blocks = append(blocks, block.asHCLBlock())
 	}
 
-	return &hcl.BodyContent{
+	content := &hcl.BodyContent{
 		Attributes:       attrs,
 		Blocks:           blocks,
 		MissingItemRange: b.MissingItemRange_,
-	}, diags
+	}
+	diags = append(diags, hcl.DeprecationDiagnostics(schema, content)...)
+	return content, diags
 }
 
 // JustAttributes is an implementation of the method of the same name on hcl.Body.
@@ -262,6 +264,11 @@ func (b *Block) asHCLBlock() *hcl.Block {
 		TypeRange:   b.TypeRange,
 		DefRange:    b.DefRange,
 		LabelRanges: b.LabelRanges,
+
+		// The blocks of a "remain" body are a subset of those of the
+		// original body, so we use the position of the block in its source
+		// file to give consistent indices.
+		Index: b.DefRange.Start.Byte,
 	}
 }
 
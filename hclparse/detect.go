@@ -0,0 +1,103 @@
+package hclparse
+
+import (
+	"bytes"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// Syntax identifies one of the configuration syntaxes supported by Parser.
+type Syntax int
+
+const (
+	// SyntaxUnknown is returned by DetectSyntax when the syntax cannot be
+	// determined.
+	SyntaxUnknown Syntax = iota
+
+	// SyntaxHCL is the native HCL syntax.
+	SyntaxHCL
+
+	// SyntaxJSON is the JSON-based HCL syntax.
+	SyntaxJSON
+)
+
+// DetectSyntax determines which syntax the given source is written in.
+//
+// If the filename has an extension registered for a syntax, including the
+// built-in ".hcl" and ".json", then the extension alone decides the result.
+// Otherwise, the content is inspected: after
+// skipping any byte order mark and whitespace, a leading "{" or "[" indicates
+// JSON, while an identifier or a comment (which JSON does not permit)
+// indicates native syntax. An empty source is treated as native syntax,
+// since it is a valid empty body in that syntax.
+//
+// SyntaxUnknown is returned if the content does not begin with anything
+// that would be valid in either syntax.
+func DetectSyntax(src []byte, filename string) Syntax {
+	if syntax := SyntaxForFilename(filename); syntax != SyntaxUnknown {
+		return syntax
+	}
+
+	src = bytes.TrimPrefix(src, []byte("\xef\xbb\xbf"))
+	src = bytes.TrimLeftFunc(src, unicode.IsSpace)
+	if len(src) == 0 {
+		return SyntaxHCL
+	}
+
+	switch src[0] {
+	case '{', '[':
+		return SyntaxJSON
+	case '#':
+		// Also covers a "#!" interpreter line, which native syntax
+		// treats as a comment.
+		return SyntaxHCL
+	case '/':
+		if len(src) > 1 && (src[1] == '/' || src[1] == '*') {
+			return SyntaxHCL
+		}
+		return SyntaxUnknown
+	}
+
+	r, _ := utf8.DecodeRune(src)
+	if unicode.IsLetter(r) || r == '_' {
+		return SyntaxHCL
+	}
+	return SyntaxUnknown
+}
+
+// ParseDetect parses the given buffer (which is assumed to have been loaded
+// from the given filename) using the syntax chosen by DetectSyntax. This is
+// useful when reading from sources such as stdin or URLs, where the filename
+// may have no extension.
+//
+// An error diagnostic is returned if the syntax cannot be detected.
+func (p *Parser) ParseDetect(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
+	if existing := p.existing(filename); existing != nil {
+		return existing, nil
+	}
+
+	switch syntax := DetectSyntax(src, filename); syntax {
+	case SyntaxUnknown:
+		start := hcl.Pos{Byte: 0, Line: 1, Column: 1}
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Unrecognized configuration syntax",
+				Detail: fmt.Sprintf(
+					"Unable to determine the syntax of %q from its content. Configuration must be either in native syntax, starting with an argument or block, or in JSON syntax, starting with an opening brace.",
+					filename,
+				),
+				Subject: &hcl.Range{
+					Filename: filename,
+					Start:    start,
+					End:      start,
+				},
+			},
+		}
+	default:
+		return p.ParseSyntax(syntax, src, filename)
+	}
+}
@@ -0,0 +1,66 @@
+package hclparse
+
+import (
+	"testing"
+)
+
+func TestDetectSyntax(t *testing.T) {
+	tests := []struct {
+		Src      string
+		Filename string
+		Want     Syntax
+	}{
+		{``, "stdin", SyntaxHCL},
+		{"  \n\t", "stdin", SyntaxHCL},
+		{`foo = "bar"`, "stdin", SyntaxHCL},
+		{`service "a" {}`, "stdin", SyntaxHCL},
+		{"# comment\nfoo = 1", "stdin", SyntaxHCL},
+		{"#!/usr/bin/env app\nfoo = 1", "stdin", SyntaxHCL},
+		{"// comment\nfoo = 1", "stdin", SyntaxHCL},
+		{"/* comment */ foo = 1", "stdin", SyntaxHCL},
+		{`{"foo": "bar"}`, "stdin", SyntaxJSON},
+		{"\xef\xbb\xbf\n  {}", "stdin", SyntaxJSON},
+		{`[{}]`, "stdin", SyntaxJSON},
+		{`{"foo": "bar"}`, "config.hcl", SyntaxHCL},
+		{`foo = "bar"`, "config.JSON", SyntaxJSON},
+		{`"foo"`, "stdin", SyntaxUnknown},
+		{`/ foo`, "stdin", SyntaxUnknown},
+		{`12`, "stdin", SyntaxUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Src, func(t *testing.T) {
+			got := DetectSyntax([]byte(test.Src), test.Filename)
+			if got != test.Want {
+				t.Errorf("wrong result for %q in %s: got %d, want %d", test.Src, test.Filename, got, test.Want)
+			}
+		})
+	}
+}
+
+func TestParserParseDetect(t *testing.T) {
+	p := NewParser()
+
+	file, diags := p.ParseDetect([]byte(`{"foo": "bar"}`), "<stdin>")
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	attrs, _ := file.Body.JustAttributes()
+	if attrs["foo"] == nil {
+		t.Errorf("missing attribute from JSON source")
+	}
+
+	file, diags = p.ParseDetect([]byte(`foo = "bar"`), "https://example.com/config")
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	attrs, _ = file.Body.JustAttributes()
+	if attrs["foo"] == nil {
+		t.Errorf("missing attribute from native syntax source")
+	}
+
+	_, diags = p.ParseDetect([]byte(`"foo"`), "<other>")
+	if len(diags) != 1 || diags[0].Summary != "Unrecognized configuration syntax" {
+		t.Errorf("wrong diagnostics for unrecognized syntax: %s", diags.Error())
+	}
+}
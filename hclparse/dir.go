@@ -0,0 +1,150 @@
+package hclparse
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+// ParsedDir is the result of ParseDir.
+type ParsedDir struct {
+	// Filenames are the names of the files that were parsed, in the order
+	// in which their bodies were merged.
+	Filenames []string
+
+	// Files maps each of the names in Filenames to the corresponding file.
+	// Files that could not be read at all are not included.
+	Files map[string]*hcl.File
+
+	// Body is the result of merging the bodies of all of the files using
+	// hcl.MergeFiles, in the order given in Filenames.
+	Body hcl.Body
+}
+
+// ParseDir parses all of the configuration files in the given directory,
+// returning the individual files along with a body that merges them all.
+//
+// A file is considered to be a configuration file if its extension is
+// registered for a syntax, including the built-in ".hcl" and ".json". Files
+// whose names begin with a period, or that appear to be editor temporary
+// files, are ignored. Subdirectories are not searched.
+//
+// Files are merged in lexical order by name, except that any file whose name
+// without its extension is "override" or ends in "_override" is placed after
+// all of the others, so that the later files can be given precedence by
+// applications that support overriding. Such applications can use the
+// BodyWithOverrides method of the result to give them that precedence.
+//
+// If parallelism is greater than one then up to that many files are parsed
+// concurrently. Diagnostics are returned in the same order as Filenames
+// regardless of parallelism. The parser is then in use by several goroutines,
+// so the map returned by Files must not be accessed until ParseDir returns.
+func (p *Parser) ParseDir(dir string, parallelism int) (*ParsedDir, hcl.Diagnostics) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read directory",
+				Code:     hcl.DiagFileReadFailed,
+				Detail:   fmt.Sprintf("The configuration directory %q could not be read.", dir),
+			},
+		}
+	}
+
+	var primary, override []string
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || isIgnoredFile(name) || SyntaxForFilename(name) == SyntaxUnknown {
+			continue
+		}
+		fn := filepath.Join(dir, name)
+		if isOverrideFile(name) {
+			override = append(override, fn)
+		} else {
+			primary = append(primary, fn)
+		}
+	}
+	sort.Strings(primary)
+	sort.Strings(override)
+	filenames := append(primary, override...)
+
+	files := make([]*hcl.File, len(filenames))
+	fileDiags := make([]hcl.Diagnostics, len(filenames))
+	if parallelism > 1 {
+		work := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < parallelism; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range work {
+					files[i], fileDiags[i] = p.ParseFile(filenames[i])
+				}
+			}()
+		}
+		for i := range filenames {
+			work <- i
+		}
+		close(work)
+		wg.Wait()
+	} else {
+		for i, fn := range filenames {
+			files[i], fileDiags[i] = p.ParseFile(fn)
+		}
+	}
+
+	ret := &ParsedDir{
+		Files: make(map[string]*hcl.File, len(filenames)),
+	}
+	var diags hcl.Diagnostics
+	var merge []*hcl.File
+	for i, fn := range filenames {
+		diags = append(diags, fileDiags[i]...)
+		if files[i] == nil {
+			continue
+		}
+		ret.Filenames = append(ret.Filenames, fn)
+		ret.Files[fn] = files[i]
+		merge = append(merge, files[i])
+	}
+	ret.Body = hcl.MergeFiles(merge)
+
+	return ret, diags
+}
+
+// BodyWithOverrides returns a body that merges the parsed files like Body,
+// except that the override files, those whose names place them last in
+// Filenames, are applied to the others using hcl.MergeFilesWithOverrides so
+// that their content replaces rather than adds to that of the other files.
+func (d *ParsedDir) BodyWithOverrides() hcl.Body {
+	var primary, override []*hcl.File
+	for _, fn := range d.Filenames {
+		if isOverrideFile(filepath.Base(fn)) {
+			override = append(override, d.Files[fn])
+		} else {
+			primary = append(primary, d.Files[fn])
+		}
+	}
+	return hcl.MergeFilesWithOverrides(primary, override)
+}
+
+// isIgnoredFile returns true if the given filename should be ignored by
+// ParseDir, because it is hidden or appears to be an editor temporary file.
+func isIgnoredFile(name string) bool {
+	return strings.HasPrefix(name, ".") || // Unix-like hidden files
+		strings.HasSuffix(name, "~") || // vim
+		(strings.HasPrefix(name, "#") && strings.HasSuffix(name, "#")) // emacs
+}
+
+// isOverrideFile returns true if the given filename, without its extension,
+// is "override" or ends with "_override".
+func isOverrideFile(name string) bool {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	return base == "override" || strings.HasSuffix(base, "_override")
+}
@@ -0,0 +1,134 @@
+package hclparse
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+func TestParserParseDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hclparse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"b.hcl":               "b = 1\n",
+		"a.json":              `{"a": 1}`,
+		"override.hcl":        "c = 1\n",
+		"a_override.hcl":      "d = 1\n",
+		"broken.hcl":          "e = \n",
+		".hidden.hcl":         "f = 1\n",
+		"b.hcl~":              "g = 1\n",
+		"#b.hcl#":             "h = 1\n",
+		"README.md":           "i = 1\n",
+		"sub/nested.hcl":      "j = 1\n",
+		"sub_override.notcfg": "k = 1\n",
+	}
+	for name, src := range files {
+		fn := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fn, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wantFilenames := []string{
+		filepath.Join(dir, "a.json"),
+		filepath.Join(dir, "b.hcl"),
+		filepath.Join(dir, "broken.hcl"),
+		filepath.Join(dir, "a_override.hcl"),
+		filepath.Join(dir, "override.hcl"),
+	}
+
+	for _, parallelism := range []int{1, 4} {
+		p := NewParser()
+		result, diags := p.ParseDir(dir, parallelism)
+		if len(diags) != 1 {
+			t.Errorf("wrong number of diagnostics %d; want 1", len(diags))
+			for _, diag := range diags {
+				t.Logf(" - %s", diag.Error())
+			}
+		}
+
+		if !reflect.DeepEqual(result.Filenames, wantFilenames) {
+			t.Errorf("wrong filenames with parallelism %d\ngot:  %#v\nwant: %#v", parallelism, result.Filenames, wantFilenames)
+		}
+		for _, fn := range wantFilenames {
+			if result.Files[fn] == nil {
+				t.Errorf("missing file %s", fn)
+			}
+			if p.Files()[fn] != result.Files[fn] {
+				t.Errorf("file %s is not in the parser's registry", fn)
+			}
+		}
+
+		attrs, _ := result.Body.JustAttributes()
+		for _, name := range []string{"a", "b", "c", "d"} {
+			if attrs[name] == nil {
+				t.Errorf("merged body is missing attribute %q", name)
+			}
+		}
+	}
+}
+
+func TestParserParseDirMissing(t *testing.T) {
+	p := NewParser()
+	result, diags := p.ParseDir(filepath.Join(os.TempDir(), "hclparse-does-not-exist"), 1)
+	if result != nil {
+		t.Errorf("unexpected result for missing directory")
+	}
+	if len(diags) != 1 || diags[0].Severity != hcl.DiagError {
+		t.Errorf("wrong diagnostics for missing directory: %s", diags.Error())
+	}
+}
+
+func TestParsedDirBodyWithOverrides(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hclparse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"main.hcl":          "a = 1\nb = 1\n",
+		"main_override.hcl": "a = 2\n",
+		"override.hcl":      "b = 3\n",
+	}
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, diags := NewParser().ParseDir(dir, 1)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	// The plain merged body treats the overridden attributes as duplicates.
+	if _, diags := result.Body.JustAttributes(); len(diags) != 2 {
+		t.Errorf("wrong number of diagnostics %d for merged body; want 2", len(diags))
+	}
+
+	attrs, diags := result.BodyWithOverrides().JustAttributes()
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	for name, want := range map[string]string{"a": "main_override.hcl", "b": "override.hcl"} {
+		if attrs[name] == nil {
+			t.Errorf("missing attribute %q", name)
+			continue
+		}
+		if got := filepath.Base(attrs[name].Range.Filename); got != want {
+			t.Errorf("attribute %q is from %s; want %s", name, got, want)
+		}
+	}
+}
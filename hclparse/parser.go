@@ -2,7 +2,10 @@ package hclparse
 
 import (
 	"fmt"
+	"io/fs"
 	"io/ioutil"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/hcl2/hcl"
 	"github.com/hashicorp/hcl2/hcl/hclsyntax"
@@ -23,8 +26,20 @@ import (
 // call to parse that file. Callers are expected to collect up diagnostics
 // and present them together, so returning diagnostics for the same file
 // multiple times would create a confusing result.
+//
+// A Parser is safe for concurrent use by multiple goroutines, with the
+// exception of the map returned by Files. If two goroutines parse the same
+// filename concurrently then both will receive the same file object, though
+// each may spend time parsing it.
 type Parser struct {
+	mu    sync.RWMutex
 	files map[string]*hcl.File
+
+	// lineIndexes caches the results of LineIndex, and is guarded by mu.
+	lineIndexes map[string]*hcl.LineIndex
+
+	// instrumentation is set by SetInstrumentation, and is guarded by mu.
+	instrumentation hcl.Instrumentation
 }
 
 // NewParser creates a new parser, ready to parse configuration files.
@@ -38,20 +53,22 @@ func NewParser() *Parser {
 // the given filename) as a native-syntax configuration file and returns the
 // hcl.File object representing it.
 func (p *Parser) ParseHCL(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
-	if existing := p.files[filename]; existing != nil {
+	if existing := p.existing(filename); existing != nil {
 		return existing, nil
 	}
 
-	file, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Byte: 0, Line: 1, Column: 1})
-	p.files[filename] = file
-	return file, diags
+	return p.parse(src, filename, parseHCL)
+}
+
+func parseHCL(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
+	return hclsyntax.ParseConfig(src, filename, hcl.Pos{Byte: 0, Line: 1, Column: 1})
 }
 
 // ParseHCLFile reads the given filename and parses it as a native-syntax HCL
 // configuration file. An error diagnostic is returned if the given file
 // cannot be read.
 func (p *Parser) ParseHCLFile(filename string) (*hcl.File, hcl.Diagnostics) {
-	if existing := p.files[filename]; existing != nil {
+	if existing := p.existing(filename); existing != nil {
 		return existing, nil
 	}
 
@@ -61,6 +78,7 @@ func (p *Parser) ParseHCLFile(filename string) (*hcl.File, hcl.Diagnostics) {
 			{
 				Severity: hcl.DiagError,
 				Summary:  "Failed to read file",
+				Code:     hcl.DiagFileReadFailed,
 				Detail:   fmt.Sprintf("The configuration file %q could not be read.", filename),
 			},
 		}
@@ -69,34 +87,185 @@ func (p *Parser) ParseHCLFile(filename string) (*hcl.File, hcl.Diagnostics) {
 	return p.ParseHCL(src, filename)
 }
 
+// ParseHCLFileFS is like ParseHCLFile but reads the given filename from the
+// given filesystem rather than from the operating system's filesystem. This
+// allows configuration to be loaded from embedded files, archives, or other
+// virtual filesystems.
+//
+// The filename is used as given, which must be a valid path as defined by
+// fs.ValidPath, both to read the file and to record it in the parser's file
+// registry.
+func (p *Parser) ParseHCLFileFS(fsys fs.FS, filename string) (*hcl.File, hcl.Diagnostics) {
+	if existing := p.existing(filename); existing != nil {
+		return existing, nil
+	}
+
+	src, diags := readFileFS(fsys, filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return p.ParseHCL(src, filename)
+}
+
 // ParseJSON parses the given JSON buffer (which is assumed to have been loaded
 // from the given filename) and returns the hcl.File object representing it.
 func (p *Parser) ParseJSON(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
-	if existing := p.files[filename]; existing != nil {
+	if existing := p.existing(filename); existing != nil {
 		return existing, nil
 	}
 
-	file, diags := json.Parse(src, filename)
-	p.files[filename] = file
-	return file, diags
+	return p.parse(src, filename, json.Parse)
 }
 
 // ParseJSONFile reads the given filename and parses it as JSON, similarly to
 // ParseJSON. An error diagnostic is returned if the given file cannot be read.
 func (p *Parser) ParseJSONFile(filename string) (*hcl.File, hcl.Diagnostics) {
-	if existing := p.files[filename]; existing != nil {
+	if existing := p.existing(filename); existing != nil {
 		return existing, nil
 	}
 
-	file, diags := json.ParseFile(filename)
-	p.files[filename] = file
-	return file, diags
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to open file",
+				Code:     hcl.DiagFileReadFailed,
+				Detail:   fmt.Sprintf("The file %q could not be opened.", filename),
+			},
+		}
+	}
+
+	return p.ParseJSON(src, filename)
+}
+
+// ParseJSONFileFS is like ParseJSONFile but reads the given filename from the
+// given filesystem rather than from the operating system's filesystem, in the
+// same way as ParseHCLFileFS.
+func (p *Parser) ParseJSONFileFS(fsys fs.FS, filename string) (*hcl.File, hcl.Diagnostics) {
+	if existing := p.existing(filename); existing != nil {
+		return existing, nil
+	}
+
+	src, diags := readFileFS(fsys, filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return p.ParseJSON(src, filename)
 }
 
 // AddFile allows a caller to record in a parser a file that was parsed some
 // other way, thus allowing it to be included in the registry of sources.
 func (p *Parser) AddFile(filename string, file *hcl.File) {
+	p.mu.Lock()
 	p.files[filename] = file
+	delete(p.lineIndexes, filename)
+	p.mu.Unlock()
+}
+
+// ForgetFile removes the given filename from the parser's registry, if
+// present, so that a subsequent call to parse the same filename will parse
+// it again rather than returning the previously-parsed file. This allows
+// long-running programs to re-load files that have changed.
+//
+// The result is true if the file was present in the registry.
+func (p *Parser) ForgetFile(filename string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, exists := p.files[filename]
+	delete(p.files, filename)
+	delete(p.lineIndexes, filename)
+	return exists
+}
+
+// LineIndex returns a line index for the source code of the given
+// previously-parsed file, or nil if the parser has no source code for that
+// filename. The index is built on the first request and then retained until
+// the file is forgotten or replaced, so that repeated position conversions
+// for the same file do not need to re-scan its source code.
+func (p *Parser) LineIndex(filename string) *hcl.LineIndex {
+	p.mu.RLock()
+	idx := p.lineIndexes[filename]
+	file := p.files[filename]
+	p.mu.RUnlock()
+	if idx != nil {
+		return idx
+	}
+	if file == nil || file.Bytes == nil {
+		return nil
+	}
+
+	idx = hcl.NewLineIndex(file.Bytes, filename)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.files[filename] != file {
+		// The file was replaced while we were building the index, so
+		// our index is for the old source code.
+		return idx
+	}
+	if existing := p.lineIndexes[filename]; existing != nil {
+		return existing
+	}
+	if p.lineIndexes == nil {
+		p.lineIndexes = make(map[string]*hcl.LineIndex)
+	}
+	p.lineIndexes[filename] = idx
+	return idx
+}
+
+// SetInstrumentation sets the instrumentation to which the parser reports
+// the parsing of each file, or removes it if given nil. Files that are
+// already in the parser's registry are returned without being parsed again,
+// and so are not reported. See hcl.Instrumentation for more information.
+func (p *Parser) SetInstrumentation(inst hcl.Instrumentation) {
+	p.mu.Lock()
+	p.instrumentation = inst
+	p.mu.Unlock()
+}
+
+// parse parses the given source code using the given function, reporting
+// it to the parser's instrumentation, if any, and records the result.
+func (p *Parser) parse(src []byte, filename string, parse SyntaxParser) (*hcl.File, hcl.Diagnostics) {
+	p.mu.RLock()
+	inst := p.instrumentation
+	p.mu.RUnlock()
+
+	if inst == nil {
+		file, diags := parse(src, filename)
+		return p.record(filename, file, diags)
+	}
+
+	inst.ParseStarted(filename, len(src))
+	start := time.Now()
+	file, diags := parse(src, filename)
+	inst.ParseFinished(filename, len(src), time.Since(start), diags)
+	return p.record(filename, file, diags)
+}
+
+// existing returns the previously-parsed file for the given filename, or nil
+// if there is none.
+func (p *Parser) existing(filename string) *hcl.File {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.files[filename]
+}
+
+// record stores the result of parsing the given filename in the registry,
+// unless another goroutine has recorded it in the meantime, in which case
+// the file recorded earlier is returned without diagnostics.
+func (p *Parser) record(filename string, file *hcl.File, diags hcl.Diagnostics) (*hcl.File, hcl.Diagnostics) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if existing := p.files[filename]; existing != nil {
+		return existing, nil
+	}
+	if file != nil {
+		p.files[filename] = file
+	}
+	return file, diags
 }
 
 // Sources returns a map from filenames to the raw source code that was
@@ -105,6 +274,8 @@ func (p *Parser) AddFile(filename string, file *hcl.File) {
 //
 // The arrays underlying the returned slices should not be modified.
 func (p *Parser) Sources() map[string][]byte {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	ret := make(map[string][]byte)
 	for fn, f := range p.files {
 		ret[fn] = f.Bytes
@@ -116,8 +287,53 @@ func (p *Parser) Sources() map[string][]byte {
 // This is intended to be used, for example, to print diagnostics with
 // contextual information.
 //
+// Files is not safe for concurrent use. The returned map is the parser's own
+// registry, which is modified without synchronization by any later call that
+// parses, adds or forgets a file, including calls to ParseDir with
+// parallelism greater than one. It must therefore not be accessed while any
+// other goroutine may be using the parser. Programs that use a parser from
+// more than one goroutine should use FilesSnapshot instead.
+//
 // The returned map and all of the objects it refers to directly or indirectly
 // must not be modified.
 func (p *Parser) Files() map[string]*hcl.File {
 	return p.files
 }
+
+// FilesSnapshot is like Files but returns a copy of the registry as of the
+// time of the call, which is safe to use while other goroutines continue to
+// use the parser.
+func (p *Parser) FilesSnapshot() map[string]*hcl.File {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	ret := make(map[string]*hcl.File, len(p.files))
+	for fn, f := range p.files {
+		ret[fn] = f
+	}
+	return ret
+}
+
+// Bundle returns a new hcl.Bundle containing the given diagnostics along
+// with a snapshot of the files parsed so far, as returned by FilesSnapshot,
+// so that the diagnostics can be rendered without access to the parser.
+func (p *Parser) Bundle(diags hcl.Diagnostics) *hcl.Bundle {
+	ret := &hcl.Bundle{}
+	ret.AddFiles(p.FilesSnapshot())
+	ret.AddDiagnostics(diags)
+	return ret
+}
+
+func readFileFS(fsys fs.FS, filename string) ([]byte, hcl.Diagnostics) {
+	src, err := fs.ReadFile(fsys, filename)
+	if err != nil {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read file",
+				Code:     hcl.DiagFileReadFailed,
+				Detail:   fmt.Sprintf("The configuration file %q could not be read.", filename),
+			},
+		}
+	}
+	return src, nil
+}
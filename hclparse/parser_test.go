@@ -0,0 +1,261 @@
+package hclparse
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+func TestParserFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/main.hcl": &fstest.MapFile{
+			Data: []byte("name = \"main\"\n"),
+		},
+		"config/extra.json": &fstest.MapFile{
+			Data: []byte(`{"name": "extra"}`),
+		},
+		"config/broken.hcl": &fstest.MapFile{
+			Data: []byte("name = \n"),
+		},
+	}
+
+	p := NewParser()
+
+	hclFile, diags := p.ParseHCLFileFS(fsys, "config/main.hcl")
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	jsonFile, diags := p.ParseJSONFileFS(fsys, "config/extra.json")
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	attrs, diags := hclFile.Body.JustAttributes()
+	if len(diags) != 0 || attrs["name"] == nil {
+		t.Errorf("wrong attributes %#v for HCL file; diagnostics: %s", attrs, diags.Error())
+	}
+	attrs, diags = jsonFile.Body.JustAttributes()
+	if len(diags) != 0 || attrs["name"] == nil {
+		t.Errorf("wrong attributes %#v for JSON file; diagnostics: %s", attrs, diags.Error())
+	}
+
+	// Parsing the same file again returns the same object, without
+	// diagnostics, even if the filesystem is different.
+	again, diags := p.ParseHCLFileFS(fstest.MapFS{}, "config/main.hcl")
+	if len(diags) != 0 || again != hclFile {
+		t.Errorf("second parse did not return the same file")
+	}
+
+	_, diags = p.ParseHCLFileFS(fsys, "config/broken.hcl")
+	if !diags.HasErrors() {
+		t.Errorf("no errors for invalid file")
+	}
+
+	_, diags = p.ParseHCLFileFS(fsys, "config/missing.hcl")
+	if len(diags) != 1 || diags[0].Summary != "Failed to read file" {
+		t.Errorf("wrong diagnostics for missing file: %s", diags.Error())
+	}
+
+	sources := p.Sources()
+	for _, fn := range []string{"config/main.hcl", "config/extra.json", "config/broken.hcl"} {
+		if _, ok := sources[fn]; !ok {
+			t.Errorf("file registry does not include %q", fn)
+		}
+	}
+}
+
+func TestParserMissingFile(t *testing.T) {
+	missing := filepath.Join(os.TempDir(), "hclparse-does-not-exist")
+	tests := map[string]func(p *Parser) hcl.Diagnostics{
+		"ParseHCLFile": func(p *Parser) hcl.Diagnostics {
+			_, diags := p.ParseHCLFile(missing + ".hcl")
+			return diags
+		},
+		"ParseJSONFile": func(p *Parser) hcl.Diagnostics {
+			_, diags := p.ParseJSONFile(missing + ".json")
+			return diags
+		},
+		"ParseFile": func(p *Parser) hcl.Diagnostics {
+			_, diags := p.ParseFile(missing + ".hcl")
+			return diags
+		},
+		"ParseHCLFileFS": func(p *Parser) hcl.Diagnostics {
+			_, diags := p.ParseHCLFileFS(fstest.MapFS{}, "missing.hcl")
+			return diags
+		},
+		"ParseJSONFileFS": func(p *Parser) hcl.Diagnostics {
+			_, diags := p.ParseJSONFileFS(fstest.MapFS{}, "missing.json")
+			return diags
+		},
+		"ParseDir": func(p *Parser) hcl.Diagnostics {
+			_, diags := p.ParseDir(missing, 1)
+			return diags
+		},
+	}
+
+	for name, parse := range tests {
+		t.Run(name, func(t *testing.T) {
+			diags := parse(NewParser())
+			if len(diags) != 1 || diags[0].Code != hcl.DiagFileReadFailed {
+				t.Errorf("wrong diagnostics: %s", diags.Error())
+			}
+		})
+	}
+}
+
+func TestParserForgetFile(t *testing.T) {
+	p := NewParser()
+
+	first, _ := p.ParseHCL([]byte("a = 1\n"), "test.hcl")
+	cached, _ := p.ParseHCL([]byte("b = 2\n"), "test.hcl")
+	if cached != first {
+		t.Fatalf("second parse did not return the cached file")
+	}
+
+	if !p.ForgetFile("test.hcl") {
+		t.Errorf("ForgetFile returned false for a registered file")
+	}
+	if p.ForgetFile("test.hcl") {
+		t.Errorf("ForgetFile returned true for a file that was already forgotten")
+	}
+	if _, ok := p.Files()["test.hcl"]; ok {
+		t.Errorf("forgotten file is still in the registry")
+	}
+
+	second, _ := p.ParseHCL([]byte("b = 2\n"), "test.hcl")
+	if second == first {
+		t.Fatalf("parse after ForgetFile returned the old file")
+	}
+	if got, want := string(p.Sources()["test.hcl"]), "b = 2\n"; got != want {
+		t.Errorf("wrong source %q; want %q", got, want)
+	}
+}
+
+func TestParserBundle(t *testing.T) {
+	p := NewParser()
+	file, diags := p.ParseHCL([]byte("a = \n"), "test.hcl")
+	if !diags.HasErrors() {
+		t.Fatalf("expected parse errors")
+	}
+
+	bundle := p.Bundle(diags)
+	if bundle.Files["test.hcl"] != file {
+		t.Errorf("bundle does not include the parsed file")
+	}
+	if len(bundle.Diagnostics) != len(diags) {
+		t.Errorf("wrong number of diagnostics %d; want %d", len(bundle.Diagnostics), len(diags))
+	}
+
+	// The bundle is a snapshot, so it is unaffected by later parsing.
+	p.ParseHCL([]byte("b = 2\n"), "other.hcl")
+	if _, ok := bundle.Files["other.hcl"]; ok {
+		t.Errorf("bundle includes a file parsed after it was created")
+	}
+}
+
+func TestParserLineIndex(t *testing.T) {
+	p := NewParser()
+
+	if p.LineIndex("test.hcl") != nil {
+		t.Errorf("got line index for a file that has not been parsed")
+	}
+
+	p.ParseHCL([]byte("a = 1\nb = 2\n"), "test.hcl")
+	idx := p.LineIndex("test.hcl")
+	if idx == nil {
+		t.Fatalf("no line index for a parsed file")
+	}
+	if p.LineIndex("test.hcl") != idx {
+		t.Errorf("line index was not cached")
+	}
+	if got, want := idx.PosForOffset(8).Line, 2; got != want {
+		t.Errorf("wrong line %d; want %d", got, want)
+	}
+
+	p.ForgetFile("test.hcl")
+	p.ParseHCL([]byte("a = 1\n\n\nb = 2\n"), "test.hcl")
+	idx = p.LineIndex("test.hcl")
+	if got, want := idx.LineCount(), 5; got != want {
+		t.Errorf("wrong line count %d after reparsing; want %d", got, want)
+	}
+}
+
+func TestParserConcurrent(t *testing.T) {
+	p := NewParser()
+	src := []byte("a = 1\n")
+
+	var wg sync.WaitGroup
+	results := make([]*hcl.File, 20)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			filename := fmt.Sprintf("file%d.hcl", i%4)
+			results[i], _ = p.ParseHCL(src, filename)
+			p.Sources()
+			p.FilesSnapshot()
+		}(i)
+	}
+	wg.Wait()
+
+	files := p.Files()
+	if len(files) != 4 {
+		t.Errorf("wrong number of files %d; want 4", len(files))
+	}
+	for i, got := range results {
+		if want := files[fmt.Sprintf("file%d.hcl", i%4)]; got != want {
+			t.Errorf("result %d is not the registered file", i)
+		}
+	}
+}
+
+type testParseInstrumentation struct {
+	hcl.NopInstrumentation
+	mu     sync.Mutex
+	events []string
+}
+
+func (i *testParseInstrumentation) ParseStarted(filename string, size int) {
+	i.mu.Lock()
+	i.events = append(i.events, fmt.Sprintf("started %s %d", filename, size))
+	i.mu.Unlock()
+}
+
+func (i *testParseInstrumentation) ParseFinished(filename string, size int, elapsed time.Duration, diags hcl.Diagnostics) {
+	i.mu.Lock()
+	i.events = append(i.events, fmt.Sprintf("finished %s %d %t", filename, size, diags.HasErrors()))
+	i.mu.Unlock()
+}
+
+func TestParserInstrumentation(t *testing.T) {
+	inst := &testParseInstrumentation{}
+	p := NewParser()
+	p.SetInstrumentation(inst)
+
+	p.ParseHCL([]byte("a = 1\n"), "a.hcl")
+	p.ParseJSON([]byte(`{"b": 2}`), "b.json")
+	p.ParseDetect([]byte("c = \n"), "c.conf")
+	p.ParseHCL([]byte("a = 1\n"), "a.hcl") // already parsed
+
+	p.SetInstrumentation(nil)
+	p.ParseHCL([]byte("d = 1\n"), "d.hcl")
+
+	want := []string{
+		"started a.hcl 6",
+		"finished a.hcl 6 false",
+		"started b.json 8",
+		"finished b.json 8 false",
+		"started c.conf 5",
+		"finished c.conf 5 true",
+	}
+	if !reflect.DeepEqual(inst.events, want) {
+		t.Errorf("wrong events\ngot:  %q\nwant: %q", inst.events, want)
+	}
+}
@@ -0,0 +1,208 @@
+package hclparse
+
+import (
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/json"
+)
+
+// SyntaxParser is the signature of a function that parses source code in a
+// particular syntax, as used with RegisterSyntax.
+//
+// As with the parsers for the built-in syntaxes, the result should be a
+// non-nil file even if there are errors, so that the source can be used when
+// printing diagnostics.
+type SyntaxParser func(src []byte, filename string) (*hcl.File, hcl.Diagnostics)
+
+// SyntaxDefinition describes a syntax that can be registered with
+// RegisterSyntax.
+type SyntaxDefinition struct {
+	// Name is a human-readable name for the syntax, such as "YAML".
+	Name string
+
+	// Extensions are the filename extensions, including the leading dot,
+	// that indicate the syntax, such as ".yaml". Extensions are matched
+	// case-insensitively.
+	Extensions []string
+
+	// MediaTypes are the MIME types that indicate the syntax, such as
+	// "application/yaml".
+	MediaTypes []string
+
+	// Parse is the function used to parse source in this syntax.
+	Parse SyntaxParser
+}
+
+var syntaxesMu sync.RWMutex
+var syntaxes = []*SyntaxDefinition{
+	SyntaxUnknown: nil,
+	SyntaxHCL: {
+		Name:       "HCL",
+		Extensions: []string{".hcl"},
+		Parse:      parseHCL,
+	},
+	SyntaxJSON: {
+		Name:       "JSON",
+		Extensions: []string{".json"},
+		MediaTypes: []string{"application/json"},
+		Parse:      json.Parse,
+	},
+}
+
+// RegisterSyntax adds a new syntax that Parser can use, selected by filename
+// extension or media type. Files parsed in a registered syntax are recorded
+// in the parser's registry in the same way as files in the built-in syntaxes,
+// so that their sources are available when printing diagnostics.
+//
+// The result is a new Syntax value identifying the registered syntax, which
+// can be passed to Parser.ParseSyntax.
+//
+// RegisterSyntax panics if the definition has no Parse function or if any of
+// its extensions or media types are already registered. It is intended to
+// be called during program initialization.
+func RegisterSyntax(def SyntaxDefinition) Syntax {
+	if def.Parse == nil {
+		panic(fmt.Sprintf("syntax %q has no Parse function", def.Name))
+	}
+
+	syntaxesMu.Lock()
+	defer syntaxesMu.Unlock()
+
+	for _, ext := range def.Extensions {
+		if existing := syntaxForExtension(ext); existing != SyntaxUnknown {
+			panic(fmt.Sprintf("extension %q is already registered for syntax %s", ext, syntaxes[existing].Name))
+		}
+	}
+	for _, mt := range def.MediaTypes {
+		if existing := syntaxForMediaType(mt); existing != SyntaxUnknown {
+			panic(fmt.Sprintf("media type %q is already registered for syntax %s", mt, syntaxes[existing].Name))
+		}
+	}
+
+	syntaxes = append(syntaxes, &def)
+	return Syntax(len(syntaxes) - 1)
+}
+
+// String returns the name of the syntax.
+func (s Syntax) String() string {
+	syntaxesMu.RLock()
+	defer syntaxesMu.RUnlock()
+	if s <= SyntaxUnknown || int(s) >= len(syntaxes) {
+		return "unknown"
+	}
+	return syntaxes[s].Name
+}
+
+// SyntaxForFilename returns the syntax indicated by the extension of the
+// given filename, or SyntaxUnknown if the extension is not registered.
+func SyntaxForFilename(filename string) Syntax {
+	syntaxesMu.RLock()
+	defer syntaxesMu.RUnlock()
+	return syntaxForExtension(filepath.Ext(filename))
+}
+
+// SyntaxForMediaType returns the syntax indicated by the given MIME type,
+// which may include parameters such as a charset, or SyntaxUnknown if the
+// media type is not registered.
+func SyntaxForMediaType(mediaType string) Syntax {
+	syntaxesMu.RLock()
+	defer syntaxesMu.RUnlock()
+	return syntaxForMediaType(mediaType)
+}
+
+func syntaxForExtension(ext string) Syntax {
+	if ext == "" {
+		return SyntaxUnknown
+	}
+	for i, def := range syntaxes {
+		if def == nil {
+			continue
+		}
+		for _, candidate := range def.Extensions {
+			if strings.EqualFold(candidate, ext) {
+				return Syntax(i)
+			}
+		}
+	}
+	return SyntaxUnknown
+}
+
+func syntaxForMediaType(mediaType string) Syntax {
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+	for i, def := range syntaxes {
+		if def == nil {
+			continue
+		}
+		for _, candidate := range def.MediaTypes {
+			if strings.EqualFold(candidate, mediaType) {
+				return Syntax(i)
+			}
+		}
+	}
+	return SyntaxUnknown
+}
+
+// ParseSyntax parses the given buffer (which is assumed to have been loaded
+// from the given filename) using the given syntax, which may be one of the
+// built-in syntaxes or a syntax added with RegisterSyntax.
+//
+// ParseSyntax panics if given SyntaxUnknown or a value that was not returned
+// by RegisterSyntax.
+func (p *Parser) ParseSyntax(syntax Syntax, src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
+	if existing := p.existing(filename); existing != nil {
+		return existing, nil
+	}
+
+	syntaxesMu.RLock()
+	if syntax <= SyntaxUnknown || int(syntax) >= len(syntaxes) {
+		syntaxesMu.RUnlock()
+		panic(fmt.Sprintf("ParseSyntax called with invalid syntax %d", syntax))
+	}
+	parse := syntaxes[syntax].Parse
+	syntaxesMu.RUnlock()
+
+	return p.parse(src, filename, parse)
+}
+
+// ParseFile reads the given filename and parses it using the syntax
+// indicated by its extension or, if the extension is not registered, by
+// its content, as for ParseDetect. An error diagnostic is returned if the
+// given file cannot be read.
+func (p *Parser) ParseFile(filename string) (*hcl.File, hcl.Diagnostics) {
+	if existing := p.existing(filename); existing != nil {
+		return existing, nil
+	}
+
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read file",
+				Code:     hcl.DiagFileReadFailed,
+				Detail:   fmt.Sprintf("The configuration file %q could not be read.", filename),
+			},
+		}
+	}
+
+	return p.ParseDetect(src, filename)
+}
+
+// ParseMediaType parses the given buffer using the syntax registered for the
+// given MIME type, such as a Content-Type header from an HTTP response. If
+// the media type is not recognized then the syntax is detected from the
+// filename and content, as for ParseDetect.
+func (p *Parser) ParseMediaType(src []byte, filename string, mediaType string) (*hcl.File, hcl.Diagnostics) {
+	if syntax := SyntaxForMediaType(mediaType); syntax != SyntaxUnknown {
+		return p.ParseSyntax(syntax, src, filename)
+	}
+	return p.ParseDetect(src, filename)
+}
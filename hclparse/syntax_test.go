@@ -0,0 +1,104 @@
+package hclparse
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+var testSyntaxParsed []string
+
+var testSyntax = RegisterSyntax(SyntaxDefinition{
+	Name:       "Test",
+	Extensions: []string{".test"},
+	MediaTypes: []string{"application/x-test"},
+	Parse: func(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
+		testSyntaxParsed = append(testSyntaxParsed, filename)
+		return &hcl.File{
+			Body:  hcl.EmptyBody(),
+			Bytes: src,
+		}, nil
+	},
+})
+
+func TestSyntaxLookup(t *testing.T) {
+	tests := []struct {
+		Got  Syntax
+		Want Syntax
+	}{
+		{SyntaxForFilename("foo.hcl"), SyntaxHCL},
+		{SyntaxForFilename("dir/foo.JSON"), SyntaxJSON},
+		{SyntaxForFilename("foo.test"), testSyntax},
+		{SyntaxForFilename("foo.yaml"), SyntaxUnknown},
+		{SyntaxForFilename("foo"), SyntaxUnknown},
+		{SyntaxForMediaType("application/json"), SyntaxJSON},
+		{SyntaxForMediaType("application/json; charset=utf-8"), SyntaxJSON},
+		{SyntaxForMediaType("application/x-test"), testSyntax},
+		{SyntaxForMediaType("text/plain"), SyntaxUnknown},
+		{DetectSyntax([]byte("{}"), "foo.test"), testSyntax},
+	}
+
+	for i, test := range tests {
+		if test.Got != test.Want {
+			t.Errorf("%d: wrong result %s; want %s", i, test.Got, test.Want)
+		}
+	}
+
+	if got, want := testSyntax.String(), "Test"; got != want {
+		t.Errorf("wrong name %q; want %q", got, want)
+	}
+}
+
+func TestParserRegisteredSyntax(t *testing.T) {
+	testSyntaxParsed = nil
+	p := NewParser()
+
+	_, diags := p.ParseDetect([]byte("anything"), "a.test")
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	_, diags = p.ParseMediaType([]byte("anything"), "<remote>", "application/x-test")
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	file, diags := p.ParseMediaType([]byte(`{"a": 1}`), "<other>", "text/plain")
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if attrs, _ := file.Body.JustAttributes(); attrs["a"] == nil {
+		t.Errorf("unrecognized media type was not parsed by content")
+	}
+
+	dir, err := ioutil.TempDir("", "hclparse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "b.test")
+	if err := ioutil.WriteFile(filename, []byte("anything"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, diags = p.ParseFile(filename)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	want := []string{"a.test", "<remote>", filename}
+	if len(testSyntaxParsed) != len(want) {
+		t.Fatalf("wrong files parsed %#v; want %#v", testSyntaxParsed, want)
+	}
+	for i := range want {
+		if testSyntaxParsed[i] != want[i] {
+			t.Errorf("wrong files parsed %#v; want %#v", testSyntaxParsed, want)
+		}
+	}
+
+	for _, fn := range want {
+		if _, ok := p.Sources()[fn]; !ok {
+			t.Errorf("file registry does not include %q", fn)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package hcltest
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// BodyBuilder constructs a mock body, as returned by MockBody, using a
+// chain of method calls:
+//
+//     body := hcltest.NewBody().
+//         Attr("name", cty.StringVal("Ermintrude")).
+//         AttrSrc("greeting", `"Hello, ${name}"`).
+//         Block("pet", []string{"cat"}, hcltest.NewBody().
+//             Attr("legs", cty.NumberIntVal(4)),
+//         ).
+//         Body()
+//
+// Attributes and blocks are given the range most recently set with At, or
+// a placeholder range in the file "MockBody" if At has not been called.
+//
+// As with the other functions in this package, the builder is intended for
+// use only in tests, and so it panics if it is used incorrectly, such as
+// by defining the same attribute twice.
+type BodyBuilder struct {
+	content *hcl.BodyContent
+	rng     hcl.Range
+}
+
+// NewBody returns a builder for a new, empty mock body.
+func NewBody() *BodyBuilder {
+	rng := hcl.Range{
+		Filename: "MockBody",
+	}
+	return &BodyBuilder{
+		content: &hcl.BodyContent{
+			Attributes:       hcl.Attributes{},
+			MissingItemRange: rng,
+		},
+		rng: rng,
+	}
+}
+
+// At sets the source range given to the attributes and blocks added after
+// it is called. All of the ranges of each attribute or block are set to the
+// given range, including the ranges of its name and labels.
+func (b *BodyBuilder) At(rng hcl.Range) *BodyBuilder {
+	b.rng = rng
+	return b
+}
+
+// MissingItemRange sets the range returned by the body's MissingItemRange
+// method, which is used as the subject of diagnostics about missing
+// attributes and blocks.
+func (b *BodyBuilder) MissingItemRange(rng hcl.Range) *BodyBuilder {
+	b.content.MissingItemRange = rng
+	return b
+}
+
+// Attr adds an attribute whose expression evaluates to the given value.
+func (b *BodyBuilder) Attr(name string, val cty.Value) *BodyBuilder {
+	return b.AttrExpr(name, MockExprLiteral(val))
+}
+
+// AttrExpr adds an attribute with the given expression.
+func (b *BodyBuilder) AttrExpr(name string, expr hcl.Expression) *BodyBuilder {
+	if _, exists := b.content.Attributes[name]; exists {
+		panic(fmt.Sprintf("duplicate attribute %q in mock body", name))
+	}
+	b.content.Attributes[name] = &hcl.Attribute{
+		Name:      name,
+		Expr:      expr,
+		Range:     b.rng,
+		NameRange: b.rng,
+	}
+	return b
+}
+
+// AttrSrc adds an attribute whose expression is given as source code in the
+// native syntax, which is parsed as if it began at the start of the range
+// most recently set with At.
+//
+// This method is primarily for testing with hard-coded expression strings,
+// so it will panic if the given string is not syntactically correct.
+func (b *BodyBuilder) AttrSrc(name string, src string) *BodyBuilder {
+	start := b.rng.Start
+	if start.Line == 0 {
+		start = hcl.Pos{Line: 1, Column: 1}
+	}
+	expr, diags := hclsyntax.ParseExpression([]byte(src), b.rng.Filename, start)
+	if diags.HasErrors() {
+		panic(fmt.Sprintf("invalid expression string for attribute %q: %s", name, diags.Error()))
+	}
+	return b.AttrExpr(name, expr)
+}
+
+// Block adds a block with the given type, labels and body. If body is nil,
+// the block has an empty body.
+func (b *BodyBuilder) Block(typeName string, labels []string, body *BodyBuilder) *BodyBuilder {
+	if body == nil {
+		body = NewBody()
+	}
+	labelRanges := make([]hcl.Range, len(labels))
+	for i := range labelRanges {
+		labelRanges[i] = b.rng
+	}
+	b.content.Blocks = append(b.content.Blocks, &hcl.Block{
+		Type:        typeName,
+		Labels:      labels,
+		Body:        body.Body(),
+		DefRange:    b.rng,
+		TypeRange:   b.rng,
+		LabelRanges: labelRanges,
+		Index:       len(b.content.Blocks),
+	})
+	return b
+}
+
+// Content returns a copy of the content built so far, as would be passed
+// to MockBody.
+func (b *BodyBuilder) Content() *hcl.BodyContent {
+	attrs := make(hcl.Attributes, len(b.content.Attributes))
+	for name, attr := range b.content.Attributes {
+		attrs[name] = attr
+	}
+	return &hcl.BodyContent{
+		Attributes:       attrs,
+		Blocks:           append(hcl.Blocks(nil), b.content.Blocks...),
+		MissingItemRange: b.content.MissingItemRange,
+	}
+}
+
+// Body returns a mock body with the content built so far. The builder may
+// continue to be used afterwards without affecting the returned body.
+func (b *BodyBuilder) Body() hcl.Body {
+	return MockBody(b.Content())
+}
@@ -0,0 +1,118 @@
+package hcltest
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestBodyBuilder(t *testing.T) {
+	rng := hcl.Range{
+		Filename: "test.hcl",
+		Start:    hcl.Pos{Line: 2, Column: 3, Byte: 10},
+		End:      hcl.Pos{Line: 2, Column: 8, Byte: 15},
+	}
+	missing := hcl.Range{
+		Filename: "test.hcl",
+		Start:    hcl.Pos{Line: 9, Column: 1, Byte: 90},
+		End:      hcl.Pos{Line: 9, Column: 1, Byte: 90},
+	}
+
+	builder := NewBody().
+		Attr("name", cty.StringVal("Ermintrude")).
+		At(rng).
+		AttrSrc("greeting", `"Hello, ${name}"`).
+		Block("pet", []string{"cat", "Tom"}, NewBody().
+			Attr("legs", cty.NumberIntVal(4)),
+		).
+		Block("empty", nil, nil).
+		MissingItemRange(missing)
+	body := builder.Body()
+
+	content, diags := body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "name"},
+			{Name: "greeting"},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "pet", LabelNames: []string{"type", "name"}},
+			{Type: "empty"},
+		},
+	})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	if got, want := content.Attributes["name"].Range.Filename, "MockBody"; got != want {
+		t.Errorf("wrong default filename %q; want %q", got, want)
+	}
+
+	greeting := content.Attributes["greeting"]
+	if got := greeting.NameRange; got != rng {
+		t.Errorf("wrong greeting name range %#v; want %#v", got, rng)
+	}
+	if got, want := greeting.Expr.Range().Start, rng.Start; got != want {
+		t.Errorf("wrong greeting expression start %#v; want %#v", got, want)
+	}
+	val, diags := greeting.Expr.Value(&hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"name": cty.StringVal("Ermintrude"),
+		},
+	})
+	if len(diags) != 0 {
+		t.Errorf("unexpected diagnostics: %s", diags.Error())
+	}
+	if want := cty.StringVal("Hello, Ermintrude"); !val.RawEquals(want) {
+		t.Errorf("wrong greeting value %#v; want %#v", val, want)
+	}
+
+	if got, want := len(content.Blocks), 2; got != want {
+		t.Fatalf("wrong number of blocks %d; want %d", got, want)
+	}
+	pet := content.Blocks[0]
+	if got, want := len(pet.LabelRanges), 2; got != want {
+		t.Errorf("wrong number of label ranges %d; want %d", got, want)
+	}
+	if got := pet.DefRange; got != rng {
+		t.Errorf("wrong block range %#v; want %#v", got, rng)
+	}
+	attrs, diags := pet.Body.JustAttributes()
+	if len(diags) != 0 {
+		t.Errorf("unexpected diagnostics: %s", diags.Error())
+	}
+	if _, ok := attrs["legs"]; !ok {
+		t.Errorf("nested block has no \"legs\" attribute")
+	}
+
+	if got := body.MissingItemRange(); got != missing {
+		t.Errorf("wrong missing item range %#v; want %#v", got, missing)
+	}
+
+	// Further changes to the builder must not affect the body we already
+	// built.
+	builder.Attr("extra", cty.True)
+	if _, diags := body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "name"},
+			{Name: "greeting"},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "pet", LabelNames: []string{"type", "name"}},
+			{Type: "empty"},
+		},
+	}); len(diags) != 0 {
+		t.Errorf("body was modified by later builder calls: %s", diags.Error())
+	}
+}
+
+func TestBodyBuilderDuplicateAttr(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("no panic for duplicate attribute")
+		}
+	}()
+	NewBody().
+		Attr("a", cty.True).
+		Attr("a", cty.False)
+}
@@ -0,0 +1,263 @@
+package hcltest
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl2/ext/typeexpr"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var updateFlag = flag.Bool("hcltest.update", false, "update golden files instead of comparing against them")
+
+// updateGolden returns true if golden files should be updated rather than
+// compared, which is requested by running the tests with -hcltest.update,
+// with an -update flag defined by the test package itself, or with the
+// environment variable HCLTEST_UPDATE set to a non-empty value.
+func updateGolden() bool {
+	if *updateFlag || os.Getenv("HCLTEST_UPDATE") != "" {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			if update, ok := getter.Get().(bool); ok {
+				return update
+			}
+		}
+	}
+	return false
+}
+
+// AssertGolden compares the given string against the content of the given
+// golden file, failing the test with a line-based diff if they differ.
+//
+// When golden files are being updated, it instead writes the given string
+// to the file, creating it and its parent directories if necessary. Golden
+// files are updated by running the tests with the -hcltest.update flag, or
+// with the environment variable HCLTEST_UPDATE set to a non-empty value.
+// Test packages that define their own boolean -update flag may use that
+// flag instead.
+func AssertGolden(t testing.TB, filename string, got string) {
+	t.Helper()
+
+	if updateGolden() {
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("failed to create directory for golden file: %s", err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(got), 0644); err != nil {
+			t.Fatalf("failed to update golden file: %s", err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Errorf("failed to read golden file: %s\n(run the tests with -hcltest.update to create it)", err)
+		return
+	}
+	if string(want) != got {
+		t.Errorf(
+			"result does not match golden file %s\n(run the tests with -hcltest.update to update it)\n\n%s",
+			filename, lineDiff(string(want), got),
+		)
+	}
+}
+
+// AssertGoldenDiagnostics is a helper that compares the given diagnostics,
+// rendered by FormatDiagnostics, against the given golden file.
+func AssertGoldenDiagnostics(t testing.TB, filename string, diags hcl.Diagnostics) {
+	t.Helper()
+	AssertGolden(t, filename, FormatDiagnostics(diags))
+}
+
+// AssertGoldenValue is a helper that compares the given value, rendered by
+// FormatValue, against the given golden file.
+func AssertGoldenValue(t testing.TB, filename string, val cty.Value) {
+	t.Helper()
+	AssertGolden(t, filename, FormatValue(val))
+}
+
+// FormatDiagnostics renders the given diagnostics in a stable textual form
+// that is intended for comparison in tests, such as with golden files.
+//
+// Each diagnostic is rendered as a line giving its severity, code and
+// summary, followed by indented lines giving its subject range and detail.
+// The diagnostics are sorted by subject range and then by summary, since
+// many operations produce diagnostics in an unpredictable order. An empty
+// set of diagnostics renders as an empty string.
+func FormatDiagnostics(diags hcl.Diagnostics) string {
+	sorted := make(hcl.Diagnostics, len(diags))
+	copy(sorted, diags)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		switch {
+		case a.Subject == nil || b.Subject == nil:
+			// Diagnostics without subjects sort first.
+			if (a.Subject == nil) != (b.Subject == nil) {
+				return a.Subject == nil
+			}
+		case a.Subject.Filename != b.Subject.Filename:
+			return a.Subject.Filename < b.Subject.Filename
+		case a.Subject.Start.Byte != b.Subject.Start.Byte:
+			return a.Subject.Start.Byte < b.Subject.Start.Byte
+		case a.Subject.End.Byte != b.Subject.End.Byte:
+			return a.Subject.End.Byte < b.Subject.End.Byte
+		}
+		return a.Summary < b.Summary
+	})
+
+	var buf strings.Builder
+	for _, diag := range sorted {
+		switch diag.Severity {
+		case hcl.DiagError:
+			buf.WriteString("Error")
+		case hcl.DiagWarning:
+			buf.WriteString("Warning")
+		case hcl.DiagInfo:
+			buf.WriteString("Info")
+		default:
+			buf.WriteString("Unknown")
+		}
+		if diag.Code != "" {
+			fmt.Fprintf(&buf, " [%s]", diag.Code)
+		}
+		fmt.Fprintf(&buf, ": %s\n", diag.Summary)
+		if diag.Subject != nil {
+			fmt.Fprintf(&buf, "  at %s\n", diag.Subject)
+		}
+		if diag.Detail != "" {
+			for _, line := range strings.Split(diag.Detail, "\n") {
+				fmt.Fprintf(&buf, "  %s\n", line)
+			}
+		}
+	}
+	return buf.String()
+}
+
+// FormatValue renders the given value in a stable textual form that is
+// intended for comparison in tests, such as with golden files.
+//
+// The value is rendered in a syntax similar to the native syntax, with each
+// collection element on a separate line, preceded by a comment line giving
+// the type of the value. Unlike a direct comparison of values, this makes
+// differences between results easy to see in a diff, including differences
+// of type that would not otherwise be apparent, such as between lists and
+// tuples.
+func FormatValue(val cty.Value) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "# %s\n", typeexpr.TypeString(val.Type()))
+	formatValue(&buf, val, "")
+	buf.WriteByte('\n')
+	return buf.String()
+}
+
+func formatValue(buf *strings.Builder, val cty.Value, indent string) {
+	ty := val.Type()
+	switch {
+	case !val.IsKnown():
+		fmt.Fprintf(buf, "(unknown %s)", typeexpr.TypeString(ty))
+	case val.IsNull():
+		fmt.Fprintf(buf, "(null %s)", typeexpr.TypeString(ty))
+	case ty == cty.String:
+		buf.WriteString(strconv.Quote(val.AsString()))
+	case ty == cty.Number:
+		buf.WriteString(val.AsBigFloat().Text('f', -1))
+	case ty == cty.Bool:
+		buf.WriteString(strconv.FormatBool(val.True()))
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		if val.LengthInt() == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[\n")
+		for it := val.ElementIterator(); it.Next(); {
+			_, ev := it.Element()
+			buf.WriteString(indent + "  ")
+			formatValue(buf, ev, indent+"  ")
+			buf.WriteString(",\n")
+		}
+		buf.WriteString(indent + "]")
+	case ty.IsMapType() || ty.IsObjectType():
+		if val.LengthInt() == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for it := val.ElementIterator(); it.Next(); {
+			ek, ev := it.Element()
+			key := ek.AsString()
+			if !hclsyntax.ValidIdentifier(key) {
+				key = strconv.Quote(key)
+			}
+			fmt.Fprintf(buf, "%s  %s = ", indent, key)
+			formatValue(buf, ev, indent+"  ")
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "}")
+	default:
+		fmt.Fprintf(buf, "(%s)", ty.FriendlyName())
+	}
+}
+
+// lineDiff returns a description of the differences between the lines of
+// the two given strings, with removed lines prefixed by "-", added lines
+// prefixed by "+", and unchanged lines prefixed by a space.
+func lineDiff(want, got string) string {
+	a := strings.SplitAfter(want, "\n")
+	b := strings.SplitAfter(got, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]. Golden files are small, so the quadratic cost is fine.
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var buf strings.Builder
+	writeLine := func(prefix, line string) {
+		if line == "" {
+			return
+		}
+		buf.WriteString(prefix)
+		buf.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			writeLine(" ", a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			writeLine("-", a[i])
+			i++
+		default:
+			writeLine("+", b[j])
+			j++
+		}
+	}
+	return buf.String()
+}
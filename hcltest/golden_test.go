@@ -0,0 +1,219 @@
+package hcltest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestFormatDiagnostics(t *testing.T) {
+	rng := func(filename string, start, end int) *hcl.Range {
+		return &hcl.Range{
+			Filename: filename,
+			Start:    hcl.Pos{Line: 1, Column: start + 1, Byte: start},
+			End:      hcl.Pos{Line: 1, Column: end + 1, Byte: end},
+		}
+	}
+
+	tests := []struct {
+		diags hcl.Diagnostics
+		want  string
+	}{
+		{
+			nil,
+			``,
+		},
+		{
+			hcl.Diagnostics{
+				{
+					Severity: hcl.DiagWarning,
+					Summary:  "Second",
+					Subject:  rng("a.hcl", 5, 6),
+				},
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Other file",
+					Subject:  rng("b.hcl", 0, 1),
+				},
+				{
+					Severity: hcl.DiagError,
+					Summary:  "First",
+					Detail:   "This has a detail\nwith two lines.",
+					Code:     "X1",
+					Subject:  rng("a.hcl", 0, 3),
+				},
+				{
+					Severity: hcl.DiagInfo,
+					Summary:  "No subject",
+				},
+			},
+			`Info: No subject
+Error [X1]: First
+  at a.hcl:1,1-4
+  This has a detail
+  with two lines.
+Warning: Second
+  at a.hcl:1,6-7
+Error: Other file
+  at b.hcl:1,1-2
+`,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			got := FormatDiagnostics(test.diags)
+			if got != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		val  cty.Value
+		want string
+	}{
+		{
+			cty.StringVal("hello\n"),
+			"# string\n\"hello\\n\"\n",
+		},
+		{
+			cty.NumberFloatVal(1.5),
+			"# number\n1.5\n",
+		},
+		{
+			cty.NullVal(cty.Bool),
+			"# bool\n(null bool)\n",
+		},
+		{
+			cty.UnknownVal(cty.String),
+			"# string\n(unknown string)\n",
+		},
+		{
+			cty.ListValEmpty(cty.String),
+			"# list(string)\n[]\n",
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"name":    cty.StringVal("Ermintrude"),
+				"not-id!": cty.True,
+				"pets": cty.TupleVal([]cty.Value{
+					cty.MapVal(map[string]cty.Value{
+						"type": cty.StringVal("cat"),
+					}),
+				}),
+			}),
+			`# object({name=string,"not-id!"=bool,pets=tuple([map(string)])})
+{
+  name = "Ermintrude"
+  "not-id!" = true
+  pets = [
+    {
+      type = "cat"
+    },
+  ]
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.val.GoString(), func(t *testing.T) {
+			got := FormatValue(test.val)
+			if got != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		want, got string
+		diff      string
+	}{
+		{
+			"a\nb\n",
+			"a\nb\n",
+			" a\n b\n",
+		},
+		{
+			"a\nb\nc\n",
+			"a\nx\nc\n",
+			" a\n-b\n+x\n c\n",
+		},
+		{
+			"a\n",
+			"a\nb",
+			" a\n+b\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want+"|"+test.got, func(t *testing.T) {
+			got := lineDiff(test.want, test.got)
+			if got != test.diff {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.diff)
+			}
+		})
+	}
+}
+
+// recordingTB is a testing.TB that records failures rather than reporting
+// them, so we can test the assertion helpers.
+type recordingTB struct {
+	testing.TB
+	failed bool
+}
+
+func (t *recordingTB) Helper() {}
+
+func (t *recordingTB) Errorf(format string, args ...interface{}) {
+	t.failed = true
+}
+
+func (t *recordingTB) Fatalf(format string, args ...interface{}) {
+	t.failed = true
+}
+
+func TestAssertGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hcltest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "sub", "result.golden")
+
+	tb := &recordingTB{TB: t}
+	AssertGolden(tb, filename, "hello\n")
+	if !tb.failed {
+		t.Errorf("no failure for missing golden file")
+	}
+
+	os.Setenv("HCLTEST_UPDATE", "1")
+	tb = &recordingTB{TB: t}
+	AssertGolden(tb, filename, "hello\n")
+	os.Unsetenv("HCLTEST_UPDATE")
+	if tb.failed {
+		t.Fatalf("failure while updating golden file")
+	}
+
+	tb = &recordingTB{TB: t}
+	AssertGolden(tb, filename, "hello\n")
+	if tb.failed {
+		t.Errorf("failure for matching golden file")
+	}
+
+	tb = &recordingTB{TB: t}
+	AssertGolden(tb, filename, "goodbye\n")
+	if !tb.failed {
+		t.Errorf("no failure for mismatched golden file")
+	}
+}
@@ -102,6 +102,8 @@ func (b mockBody) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.
 		}
 	}
 
+	diags = append(diags, hcl.DeprecationDiagnostics(schema, ret)...)
+
 	return ret, mockBody{remain}, diags
 }
 
@@ -180,6 +182,11 @@ func (e mockExprVariable) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostic
 		if val, ok := ctx.Variables[name]; ok {
 			return val, nil
 		}
+		if ctx.VariableResolver != nil {
+			if val, diags := ctx.VariableResolver(name); val != cty.NilVal || diags.HasErrors() {
+				return val, diags
+			}
+		}
 		ctx = ctx.Parent()
 	}
 
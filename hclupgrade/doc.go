@@ -0,0 +1,12 @@
+// Package hclupgrade rewrites configuration written for the original version
+// of HCL, known as HCL1, into the native syntax of this version of HCL.
+//
+// The two languages are similar enough that most configuration needs only
+// small changes, and so the upgrade is done by making targeted edits to the
+// original source code, which preserves comments and layout. The result is
+// then formatted in the canonical style.
+//
+// Because HCL1 has no schema-independent distinction between attributes
+// whose values are maps and nested blocks, the caller must name any
+// attributes that were written with block syntax. See Options.
+package hclupgrade
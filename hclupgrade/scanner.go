@@ -0,0 +1,236 @@
+package hclupgrade
+
+import (
+	"bytes"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+type tokenType rune
+
+const (
+	tokenEOF     tokenType = 0
+	tokenNewline tokenType = '\n'
+	tokenComment tokenType = '#'
+	tokenIdent   tokenType = 'I'
+	tokenString  tokenType = 'S'
+	tokenHeredoc tokenType = 'H'
+	tokenNumber  tokenType = 'N'
+	tokenOBrace  tokenType = '{'
+	tokenCBrace  tokenType = '}'
+	tokenOBrack  tokenType = '['
+	tokenCBrack  tokenType = ']'
+	tokenEqual   tokenType = '='
+	tokenComma   tokenType = ','
+)
+
+type token struct {
+	Type  tokenType
+	Bytes []byte
+	Range hcl.Range
+}
+
+// scanner splits HCL1 source code into tokens. Whitespace other than
+// newlines is skipped, since the upgrade retains it from the original
+// source code.
+type scanner struct {
+	src      []byte
+	filename string
+	pos      hcl.Pos
+}
+
+func (s *scanner) peekByte(offset int) byte {
+	if s.pos.Byte+offset >= len(s.src) {
+		return 0
+	}
+	return s.src[s.pos.Byte+offset]
+}
+
+// advance moves forward by the given number of bytes, keeping track of
+// line and column numbers.
+func (s *scanner) advance(n int) {
+	for i := 0; i < n && s.pos.Byte < len(s.src); {
+		r, size := utf8.DecodeRune(s.src[s.pos.Byte:])
+		s.pos.Byte += size
+		i += size
+		if r == '\n' {
+			s.pos.Line++
+			s.pos.Column = 1
+		} else {
+			s.pos.Column++
+		}
+	}
+}
+
+func (s *scanner) scanAll() ([]token, hcl.Diagnostics) {
+	var tokens []token
+	for {
+		tok, diags := s.next()
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		tokens = append(tokens, tok)
+		if tok.Type == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (s *scanner) next() (token, hcl.Diagnostics) {
+	for {
+		c := s.peekByte(0)
+		if c != ' ' && c != '\t' && c != '\r' {
+			break
+		}
+		s.advance(1)
+	}
+
+	start := s.pos
+	if start.Byte >= len(s.src) {
+		return s.token(tokenEOF, start), nil
+	}
+
+	c := s.peekByte(0)
+	switch {
+	case c == '\n':
+		s.advance(1)
+		return s.token(tokenNewline, start), nil
+	case c == '#' || (c == '/' && s.peekByte(1) == '/'):
+		end := bytes.IndexByte(s.src[start.Byte:], '\n')
+		if end < 0 {
+			end = len(s.src) - start.Byte
+		}
+		s.advance(end)
+		return s.token(tokenComment, start), nil
+	case c == '/' && s.peekByte(1) == '*':
+		end := bytes.Index(s.src[start.Byte+2:], []byte("*/"))
+		if end < 0 {
+			return token{}, s.errorf(start, "Unterminated comment", "There is no closing */ for this comment.")
+		}
+		s.advance(end + 4)
+		return s.token(tokenComment, start), nil
+	case c == '"':
+		return s.scanString(start)
+	case c == '<' && s.peekByte(1) == '<':
+		return s.scanHeredoc(start)
+	case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		s.advance(1)
+		for {
+			c := s.peekByte(0)
+			if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F' || c == '.' || c == 'x' || c == 'X' || c == '+' || c == '-') {
+				break
+			}
+			if (c == '+' || c == '-') && s.src[s.pos.Byte-1] != 'e' && s.src[s.pos.Byte-1] != 'E' {
+				break
+			}
+			s.advance(1)
+		}
+		return s.token(tokenNumber, start), nil
+	case c == '{' || c == '}' || c == '[' || c == ']' || c == '=' || c == ',':
+		s.advance(1)
+		return s.token(tokenType(c), start), nil
+	}
+
+	r, _ := utf8.DecodeRune(s.src[start.Byte:])
+	if unicode.IsLetter(r) || r == '_' {
+		for {
+			r, size := utf8.DecodeRune(s.src[s.pos.Byte:])
+			if s.pos.Byte >= len(s.src) || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.') {
+				break
+			}
+			s.advance(size)
+		}
+		return s.token(tokenIdent, start), nil
+	}
+
+	return token{}, s.errorf(start, "Invalid character", fmt.Sprintf("The character %q is not valid here.", r))
+}
+
+// scanString scans a quoted string, which in HCL1 may contain interpolation
+// sequences that themselves contain quoted strings.
+func (s *scanner) scanString(start hcl.Pos) (token, hcl.Diagnostics) {
+	s.advance(1) // opening quote
+	braces := 0
+	for {
+		c := s.peekByte(0)
+		switch {
+		case s.pos.Byte >= len(s.src) || c == '\n':
+			return token{}, s.errorf(start, "Unterminated string", "There is no closing quote for this string.")
+		case c == '\\':
+			s.advance(2)
+			continue
+		case c == '"' && braces == 0:
+			s.advance(1)
+			return s.token(tokenString, start), nil
+		case c == '$' && s.peekByte(1) == '{' && braces == 0:
+			braces++
+			s.advance(2)
+			continue
+		case c == '{' && braces > 0:
+			braces++
+		case c == '}' && braces > 0:
+			braces--
+		}
+		s.advance(1)
+	}
+}
+
+func (s *scanner) scanHeredoc(start hcl.Pos) (token, hcl.Diagnostics) {
+	lineEnd := bytes.IndexByte(s.src[start.Byte:], '\n')
+	if lineEnd < 0 {
+		return token{}, s.errorf(start, "Invalid heredoc", "A heredoc marker must be followed by a newline.")
+	}
+	marker := bytes.TrimSpace(s.src[start.Byte+2 : start.Byte+lineEnd])
+	indented := bytes.HasPrefix(marker, []byte("-"))
+	marker = bytes.TrimPrefix(marker, []byte("-"))
+	if len(marker) == 0 {
+		return token{}, s.errorf(start, "Invalid heredoc", "A heredoc must have an identifier after the opening marker.")
+	}
+	s.advance(lineEnd + 1)
+
+	for s.pos.Byte < len(s.src) {
+		end := bytes.IndexByte(s.src[s.pos.Byte:], '\n')
+		if end < 0 {
+			end = len(s.src) - s.pos.Byte
+		}
+		line := bytes.TrimRight(s.src[s.pos.Byte:s.pos.Byte+end], "\r")
+		if indented {
+			line = bytes.TrimLeft(line, " \t")
+		}
+		if bytes.Equal(line, marker) {
+			s.advance(end)
+			return s.token(tokenHeredoc, start), nil
+		}
+		s.advance(end + 1)
+	}
+	return token{}, s.errorf(start, "Unterminated heredoc", fmt.Sprintf("There is no closing %s marker for this heredoc.", marker))
+}
+
+func (s *scanner) token(ty tokenType, start hcl.Pos) token {
+	return token{
+		Type:  ty,
+		Bytes: s.src[start.Byte:s.pos.Byte],
+		Range: hcl.Range{
+			Filename: s.filename,
+			Start:    start,
+			End:      s.pos,
+		},
+	}
+}
+
+func (s *scanner) errorf(start hcl.Pos, summary, detail string) hcl.Diagnostics {
+	rng := hcl.Range{
+		Filename: s.filename,
+		Start:    start,
+		End:      s.pos,
+	}
+	return hcl.Diagnostics{{
+		Severity: hcl.DiagError,
+		Summary:  summary,
+		Detail:   detail,
+		Subject:  &rng,
+	}}
+}
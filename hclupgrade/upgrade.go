@@ -0,0 +1,363 @@
+package hclupgrade
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hclwrite"
+)
+
+// Options controls the behavior of Upgrade.
+type Options struct {
+	// MapAttributes are the names of attributes whose values are maps, which
+	// HCL1 allowed to be written with block syntax, as in "tags { a = 1 }".
+	// Such constructs are rewritten as attributes, as in "tags = { a = 1 }",
+	// whereas all other constructs using block syntax remain blocks.
+	MapAttributes []string
+}
+
+// Upgrade rewrites the given HCL1 source code as native syntax, making the
+// following changes:
+//
+//     * Block types given as quoted strings are unquoted, and block labels
+//       given as identifiers are quoted.
+//     * Attribute names given as quoted strings are unquoted.
+//     * Strings containing only a single interpolation sequence, such as
+//       "${var.name}", are replaced with the interpolated expression.
+//     * Quotes escaped with backslashes within interpolation sequences are
+//       unescaped, since the native syntax allows them to be nested.
+//     * Literal sequences that would now begin template directives are
+//       escaped.
+//     * Attributes named in the options that were written with block syntax,
+//       and the nested objects within attribute values, are rewritten to use
+//       object constructor syntax.
+//
+// The result is formatted in the canonical style unless it has errors.
+// Error diagnostics are returned if the source code is not valid HCL1, or if
+// it uses constructs that cannot be upgraded automatically, in which case
+// the result is nil, or if the upgraded result is not valid native syntax,
+// in which case the unformatted result is returned for inspection.
+func Upgrade(src []byte, filename string, opts *Options) ([]byte, hcl.Diagnostics) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	sc := &scanner{
+		src:      src,
+		filename: filename,
+		pos:      hcl.Pos{Line: 1, Column: 1},
+	}
+	tokens, diags := sc.scanAll()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	u := &upgrader{
+		tokens:  tokens,
+		mapAttr: make(map[string]bool),
+	}
+	for _, name := range opts.MapAttributes {
+		u.mapAttr[name] = true
+	}
+	u.body(false)
+	if !u.diags.HasErrors() && u.peek().Type != tokenEOF {
+		u.unexpected(u.peek(), "a block or attribute")
+	}
+	if u.diags.HasErrors() {
+		return nil, u.diags
+	}
+
+	out, err := hcl.ApplyEdits(src, u.edits)
+	if err != nil {
+		// Should never happen, since our edits never overlap.
+		panic(fmt.Sprintf("invalid upgrade edits: %s", err))
+	}
+
+	_, diags = hclsyntax.ParseConfig(out, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return out, diags
+	}
+	return hclwrite.Format(out), nil
+}
+
+type upgrader struct {
+	tokens  []token
+	next    int
+	mapAttr map[string]bool
+	edits   []hcl.Edit
+	diags   hcl.Diagnostics
+}
+
+func (u *upgrader) peek() token {
+	return u.tokens[u.next]
+}
+
+func (u *upgrader) read() token {
+	tok := u.tokens[u.next]
+	if tok.Type != tokenEOF {
+		u.next++
+	}
+	return tok
+}
+
+// skip skips over newlines, comments and, in objects and lists, commas.
+func (u *upgrader) skip(commas bool) {
+	for {
+		switch u.peek().Type {
+		case tokenNewline, tokenComment:
+			u.read()
+		case tokenComma:
+			if !commas {
+				return
+			}
+			u.read()
+		default:
+			return
+		}
+	}
+}
+
+func (u *upgrader) replace(rng hcl.Range, replacement string) {
+	u.edits = append(u.edits, hcl.Edit{Range: rng, Replacement: replacement})
+}
+
+func (u *upgrader) insert(pos hcl.Pos, text string) {
+	u.replace(hcl.Range{Start: pos, End: pos}, text)
+}
+
+func (u *upgrader) unexpected(tok token, want string) {
+	what := fmt.Sprintf("%q", tok.Bytes)
+	if tok.Type == tokenEOF {
+		what = "end of file"
+	}
+	u.diags = append(u.diags, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid HCL1 syntax",
+		Detail:   fmt.Sprintf("Expected %s, but found %s.", want, what),
+		Subject:  tok.Range.Ptr(),
+	})
+}
+
+// body upgrades the items of a body, or of an object if inObject is set,
+// until the closing brace or the end of the file.
+func (u *upgrader) body(inObject bool) {
+	for !u.diags.HasErrors() {
+		u.skip(inObject)
+		switch u.peek().Type {
+		case tokenCBrace, tokenEOF:
+			return
+		}
+
+		var keys []token
+		for u.peek().Type == tokenIdent || u.peek().Type == tokenString {
+			keys = append(keys, u.read())
+		}
+		if len(keys) == 0 {
+			u.unexpected(u.peek(), "a block or attribute name")
+			return
+		}
+
+		switch tok := u.read(); tok.Type {
+		case tokenEqual:
+			if len(keys) > 1 {
+				u.unexpected(keys[1], "an equals sign")
+				return
+			}
+			if !inObject {
+				u.attrName(keys[0])
+			}
+			u.value()
+
+		case tokenOBrace:
+			if inObject || (len(keys) == 1 && u.mapAttr[string(keys[0].Bytes)]) {
+				u.nestedObjects(keys, tok)
+				continue
+			}
+			u.blockHeader(keys)
+			if u.diags.HasErrors() {
+				return
+			}
+			u.body(false)
+			if close := u.read(); close.Type != tokenCBrace {
+				u.unexpected(close, "a closing brace")
+			}
+
+		default:
+			u.unexpected(tok, "an equals sign or an opening brace")
+		}
+	}
+}
+
+// nestedObjects upgrades an object written with block syntax, which in HCL1
+// may have several keys, as in `a "b" { c = 1 }`, meaning the same as
+// `a = { b = { c = 1 } }`.
+func (u *upgrader) nestedObjects(keys []token, open token) {
+	var buf strings.Builder
+	buf.WriteString("= ")
+	for _, key := range keys[1:] {
+		fmt.Fprintf(&buf, "{ %s = ", key.Bytes)
+	}
+	u.insert(keys[0].Range.End, " ")
+	for _, key := range keys[1:] {
+		u.replace(key.Range, "")
+	}
+	u.insert(open.Range.Start, buf.String())
+
+	u.body(true)
+	close := u.read()
+	if close.Type != tokenCBrace {
+		u.unexpected(close, "a closing brace")
+		return
+	}
+	u.insert(close.Range.End, strings.Repeat(" }", len(keys)-1))
+}
+
+func (u *upgrader) blockHeader(keys []token) {
+	typeTok := keys[0]
+	if typeTok.Type == tokenString {
+		name := unquote(typeTok)
+		if !hclsyntax.ValidIdentifier(name) {
+			u.diags = append(u.diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid block type",
+				Detail:   fmt.Sprintf("The block type %q is not a valid identifier, so it cannot be used in the native syntax.", name),
+				Subject:  typeTok.Range.Ptr(),
+			})
+			return
+		}
+		u.replace(typeTok.Range, name)
+	}
+	for _, label := range keys[1:] {
+		if label.Type == tokenIdent {
+			u.replace(label.Range, `"`+string(label.Bytes)+`"`)
+		}
+	}
+}
+
+func (u *upgrader) attrName(tok token) {
+	if tok.Type != tokenString {
+		return
+	}
+	name := unquote(tok)
+	if !hclsyntax.ValidIdentifier(name) {
+		u.diags = append(u.diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid attribute name",
+			Detail:   fmt.Sprintf("The attribute name %q is not a valid identifier, so it cannot be used in the native syntax.", name),
+			Subject:  tok.Range.Ptr(),
+		})
+		return
+	}
+	u.replace(tok.Range, name)
+}
+
+func (u *upgrader) value() {
+	tok := u.read()
+	switch tok.Type {
+	case tokenString:
+		if upgraded := upgradeString(tok.Bytes); upgraded != string(tok.Bytes) {
+			u.replace(tok.Range, upgraded)
+		}
+	case tokenNumber, tokenIdent, tokenHeredoc:
+		// These are unchanged in the native syntax.
+	case tokenOBrack:
+		for !u.diags.HasErrors() {
+			u.skip(true)
+			if u.peek().Type == tokenCBrack {
+				u.read()
+				return
+			}
+			u.value()
+		}
+	case tokenOBrace:
+		u.body(true)
+		if close := u.read(); close.Type != tokenCBrace {
+			u.unexpected(close, "a closing brace")
+		}
+	default:
+		u.unexpected(tok, "a value")
+	}
+}
+
+func unquote(tok token) string {
+	// HCL1 keys rarely contain escape sequences, and any that do are not
+	// valid identifiers, so we don't need to decode them.
+	return string(tok.Bytes[1 : len(tok.Bytes)-1])
+}
+
+// upgradeString returns the native syntax equivalent of the given quoted
+// HCL1 string, including its quotes.
+func upgradeString(quoted []byte) string {
+	content := quoted[1 : len(quoted)-1]
+
+	var buf bytes.Buffer
+	interps := 0
+	literal := false
+	for i := 0; i < len(content); {
+		switch {
+		case content[i] == '\\' && i+1 < len(content):
+			buf.Write(content[i : i+2])
+			i += 2
+			literal = true
+		case bytes.HasPrefix(content[i:], []byte("$${")):
+			buf.WriteString("$${")
+			i += 3
+			literal = true
+		case bytes.HasPrefix(content[i:], []byte("${")):
+			end := interpEnd(content, i+2)
+			buf.WriteString("${")
+			buf.Write(unescapeInterp(content[i+2 : end]))
+			buf.WriteString("}")
+			i = end + 1
+			interps++
+		case bytes.HasPrefix(content[i:], []byte("%{")):
+			// This would begin a template directive in the native syntax.
+			buf.WriteString("%%{")
+			i += 2
+			literal = true
+		default:
+			buf.WriteByte(content[i])
+			i++
+			literal = true
+		}
+	}
+
+	result := buf.String()
+	if interps == 1 && !literal {
+		// The string consists only of a single interpolation sequence,
+		// which we can replace with the interpolated expression.
+		return strings.TrimSpace(result[2 : len(result)-1])
+	}
+	return `"` + result + `"`
+}
+
+// interpEnd returns the index of the closing brace of the interpolation
+// sequence whose content begins at the given index of the given string
+// content.
+func interpEnd(content []byte, start int) int {
+	braces := 1
+	for i := start; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '{':
+			braces++
+		case '}':
+			braces--
+			if braces == 0 {
+				return i
+			}
+		}
+	}
+	return len(content) - 1
+}
+
+// unescapeInterp removes the backslash escapes from quotes and backslashes
+// in the given interpolation sequence content, which HCL1 required within
+// quoted strings but the native syntax does not allow.
+func unescapeInterp(src []byte) []byte {
+	src = bytes.Replace(src, []byte(`\"`), []byte(`"`), -1)
+	return bytes.Replace(src, []byte(`\\`), []byte(`\`), -1)
+}
@@ -0,0 +1,153 @@
+package hclupgrade
+
+import (
+	"testing"
+)
+
+func TestUpgrade(t *testing.T) {
+	tests := []struct {
+		input     string
+		opts      *Options
+		want      string
+		diagCount int
+	}{
+		{
+			``,
+			nil,
+			``,
+			0,
+		},
+		{
+			"a = \"${var.foo}\"\n",
+			nil,
+			"a = var.foo\n",
+			0,
+		},
+		{
+			"a = \"hello ${var.foo}\"\n",
+			nil,
+			"a = \"hello ${var.foo}\"\n",
+			0,
+		},
+		{
+			"a = \"${lookup(var.m, \\\"k\\\")}\"\n",
+			nil,
+			"a = lookup(var.m, \"k\")\n",
+			0,
+		},
+		{
+			"a = \"x-${join(\\\",\\\", var.l)}\"\n",
+			nil,
+			"a = \"x-${join(\",\", var.l)}\"\n",
+			0,
+		},
+		{
+			"a = \"100%{x}\"\n",
+			nil,
+			"a = \"100%%{x}\"\n",
+			0,
+		},
+		{
+			"a = \"$${literal}\"\n",
+			nil,
+			"a = \"$${literal}\"\n",
+			0,
+		},
+		{
+			"\"foo\" = 1\n",
+			nil,
+			"foo = 1\n",
+			0,
+		},
+		{
+			"resource aws_instance web {\n  ami = \"${var.ami}\"\n}\n",
+			nil,
+			"resource \"aws_instance\" \"web\" {\n  ami = var.ami\n}\n",
+			0,
+		},
+		{
+			"\"resource\" \"a\" {\n}\n",
+			nil,
+			"resource \"a\" {\n}\n",
+			0,
+		},
+		{
+			"\"not valid\" {\n}\n",
+			nil,
+			"",
+			1,
+		},
+		{
+			"tags {\n  Name = \"web\"\n}\n",
+			&Options{MapAttributes: []string{"tags"}},
+			"tags = {\n  Name = \"web\"\n}\n",
+			0,
+		},
+		{
+			"tags {\n  Name = \"web\"\n}\n",
+			nil,
+			"tags {\n  Name = \"web\"\n}\n",
+			0,
+		},
+		{
+			"a = {\n  b c {\n    d = 1\n  }\n}\n",
+			nil,
+			"a = {\n  b = { c = {\n    d = 1\n  } }\n}\n",
+			0,
+		},
+		{
+			"a = [\"${x}\", 1, true]\n",
+			nil,
+			"a = [x, 1, true]\n",
+			0,
+		},
+		{
+			"# comment\na = 1 // trailing\n/* block */\n",
+			nil,
+			"# comment\na = 1 // trailing\n/* block */\n",
+			0,
+		},
+		{
+			"a = <<EOT\n${foo}\nEOT\n",
+			nil,
+			"a = <<EOT\n${foo}\nEOT\n",
+			0,
+		},
+		{
+			"a = \n",
+			nil,
+			"",
+			1,
+		},
+		{
+			"a b = 1\n",
+			nil,
+			"",
+			1,
+		},
+		{
+			"a {\n",
+			nil,
+			"",
+			1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, diags := Upgrade([]byte(test.input), "test.hcl", test.opts)
+			if len(diags) != test.diagCount {
+				t.Errorf("wrong number of diagnostics %d; want %d", len(diags), test.diagCount)
+				for _, diag := range diags {
+					t.Logf("- %s", diag)
+				}
+			}
+			if test.diagCount != 0 {
+				return
+			}
+			if string(got) != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,9 @@ package hclwrite
 import (
 	"bytes"
 	"io"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
 )
 
 type File struct {
@@ -48,6 +51,31 @@ func (f *File) Bytes() []byte {
 	return buf.Bytes()
 }
 
+// Validate parses the source code that the receiving file would produce, as
+// returned by Bytes, and returns any diagnostics from doing so. This allows
+// a program that generates configuration to detect that it has produced
+// invalid source code, such as by appending unstructured tokens, before
+// writing it out.
+//
+// The diagnostics refer to the produced source code as if it were in a file
+// with the given name.
+func (f *File) Validate(filename string) hcl.Diagnostics {
+	_, diags := f.ValidBytes(filename)
+	return diags
+}
+
+// ValidBytes is like Bytes, but also validates the result as described for
+// Validate. If the result is not valid then the returned buffer is nil, and
+// the diagnostics include at least one error.
+func (f *File) ValidBytes(filename string) ([]byte, hcl.Diagnostics) {
+	src := f.Bytes()
+	_, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return src, diags
+}
+
 type comments struct {
 	leafNode
 
@@ -1,6 +1,7 @@
 package hclwrite
 
 import (
+	"github.com/hashicorp/hcl2/hcl"
 	"github.com/hashicorp/hcl2/hcl/hclsyntax"
 )
 
@@ -46,3 +47,45 @@ func (a *Attribute) init(name string, expr *Expression) {
 func (a *Attribute) Expr() *Expression {
 	return a.expr.content.(*Expression)
 }
+
+// WrapExpressionInCall replaces the attribute's expression with a call to
+// the function of the given name that passes the original expression as its
+// only argument, preserving the original expression's source layout.
+func (a *Attribute) WrapExpressionInCall(funcName string) {
+	inner := a.Expr().BuildTokens(nil)
+	spacesBefore := 0
+	if len(inner) > 0 {
+		spacesBefore = inner[0].SpacesBefore
+		first := *inner[0]
+		first.SpacesBefore = 0
+		inner = append(Tokens{&first}, inner[1:]...)
+	}
+
+	src := make([]byte, 0, len(funcName)+len(inner.Bytes())+2)
+	src = append(src, funcName...)
+	src = append(src, '(')
+	src = append(src, inner.Bytes()...)
+	src = append(src, ')')
+
+	// We use the native parser to find the traversals in the new expression,
+	// in the same way as when parsing a whole file.
+	nativeExpr, diags := hclsyntax.ParseExpression(src, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		// Should never happen, since the inner expression was valid.
+		panic("failed to parse wrapped expression: " + diags.Error())
+	}
+	nativeTokens, _ := hclsyntax.LexExpression(src, "", hcl.Pos{Line: 1, Column: 1})
+	if n := len(nativeTokens); n > 0 && nativeTokens[n-1].Type == hclsyntax.TokenEOF {
+		nativeTokens = nativeTokens[:n-1]
+	}
+	writerToks := writerTokens(nativeTokens)
+	if len(writerToks) > 0 {
+		writerToks[0].SpacesBefore = spacesBefore
+	}
+
+	exprNode := parseExpression(nativeExpr, inputTokens{
+		nativeTokens: nativeTokens,
+		writerTokens: writerToks,
+	})
+	a.expr = a.expr.ReplaceWith(exprNode.content)
+}
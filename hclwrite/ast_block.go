@@ -1,6 +1,7 @@
 package hclwrite
 
 import (
+	"github.com/hashicorp/hcl2/hcl"
 	"github.com/hashicorp/hcl2/hcl/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -72,3 +73,35 @@ func (b *Block) init(typeName string, labels []string) {
 func (b *Block) Body() *Body {
 	return b.body.content.(*Body)
 }
+
+// Type returns the type name of the receiving block.
+func (b *Block) Type() string {
+	return string(b.typeName.content.(*identifier).token.Bytes)
+}
+
+// Labels returns the values of the labels of the receiving block, in the
+// order they appear.
+func (b *Block) Labels() []string {
+	nodes := b.labels.List()
+	ret := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		switch label := n.content.(type) {
+		case *identifier:
+			ret = append(ret, string(label.token.Bytes))
+		case *quoted:
+			// The quoted label may contain escape sequences, so we'll lean
+			// on the native parser to interpret it.
+			src := label.BuildTokens(nil).Bytes()
+			expr, diags := hclsyntax.ParseExpression(src, "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				// Should never happen for a label that was accepted by
+				// the parser or generated by NewBlock.
+				ret = append(ret, string(src))
+				continue
+			}
+			val, _ := expr.Value(nil)
+			ret = append(ret, val.AsString())
+		}
+	}
+	return ret
+}
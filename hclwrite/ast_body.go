@@ -55,10 +55,11 @@ func (b *Body) Attributes() map[string]*Attribute {
 	return ret
 }
 
-// Blocks returns a new slice of all the blocks in the body.
+// Blocks returns a new slice of all the blocks in the body, in the order
+// they appear in the body.
 func (b *Body) Blocks() []*Block {
 	ret := make([]*Block, 0, len(b.items))
-	for n := range b.items {
+	for _, n := range b.items.List() {
 		if block, isBlock := n.content.(*Block); isBlock {
 			ret = append(ret, block)
 		}
@@ -82,6 +83,54 @@ func (b *Body) GetAttribute(name string) *Attribute {
 	return nil
 }
 
+// RemoveAttribute removes the attribute with the given name from the body,
+// along with any comments attached to it, and returns the removed attribute.
+// It returns nil if there is no attribute of the given name.
+func (b *Body) RemoveAttribute(name string) *Attribute {
+	for n := range b.items {
+		if attr, isAttr := n.content.(*Attribute); isAttr {
+			nameObj := attr.name.content.(*identifier)
+			if nameObj.hasName(name) {
+				b.items.Remove(n)
+				n.Detach()
+				return attr
+			}
+		}
+	}
+	return nil
+}
+
+// RenameAttribute changes the name of the attribute with the given name,
+// preserving its expression and comments. It returns false if there is no
+// attribute with the given name.
+//
+// The caller is responsible for ensuring that the new name is not already
+// in use in the body.
+func (b *Body) RenameAttribute(fromName, toName string) bool {
+	attr := b.GetAttribute(fromName)
+	if attr == nil {
+		return false
+	}
+	oldTok := attr.name.content.(*identifier).token
+	newTok := newIdentToken(toName)
+	newTok.SpacesBefore = oldTok.SpacesBefore
+	attr.name = attr.name.ReplaceWith(newIdentifier(newTok))
+	return true
+}
+
+// RemoveBlock removes the given block from the body, along with any comments
+// attached to it. It returns false if the block does not belong to the body.
+func (b *Body) RemoveBlock(block *Block) bool {
+	for n := range b.items {
+		if n.content == block {
+			b.items.Remove(n)
+			n.Detach()
+			return true
+		}
+	}
+	return false
+}
+
 // SetAttributeValue either replaces the expression of an existing attribute
 // of the given name or adds a new attribute definition to the end of the block.
 //
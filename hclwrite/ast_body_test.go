@@ -882,3 +882,90 @@ func TestBodyAppendBlock(t *testing.T) {
 		})
 	}
 }
+
+func TestBodyEdits(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		edit func(*Body)
+		want string
+	}{
+		"remove attribute": {
+			"a = 1\n# about b\nb = 2\nc = 3\n",
+			func(body *Body) {
+				if body.RemoveAttribute("b") == nil {
+					t.Errorf("attribute b not found")
+				}
+				if body.RemoveAttribute("nope") != nil {
+					t.Errorf("removed nonexistent attribute")
+				}
+			},
+			"a = 1\nc = 3\n",
+		},
+		"rename attribute": {
+			"a   = 1 # the a\nb = 2\n",
+			func(body *Body) {
+				if !body.RenameAttribute("a", "alpha") {
+					t.Errorf("attribute a not found")
+				}
+			},
+			"alpha = 1 # the a\nb     = 2\n",
+		},
+		"remove block": {
+			"a {\n}\nb \"x\" {\n  c = 1\n}\nd {}\n",
+			func(body *Body) {
+				for _, block := range body.Blocks() {
+					if block.Type() == "b" {
+						body.RemoveBlock(block)
+					}
+				}
+			},
+			"a {\n}\nd {}\n",
+		},
+		"wrap expression": {
+			"a =   var.foo # comment\n",
+			func(body *Body) {
+				body.GetAttribute("a").WrapExpressionInCall("upper")
+			},
+			"a = upper(var.foo) # comment\n",
+		},
+		"wrap then rename variable": {
+			"a = [var.foo, 1]\n",
+			func(body *Body) {
+				attr := body.GetAttribute("a")
+				attr.WrapExpressionInCall("tolist")
+				attr.Expr().RenameVariablePrefix([]string{"var", "foo"}, []string{"var", "bar"})
+			},
+			"a = tolist([var.bar, 1])\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, diags := ParseConfig([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags)
+			}
+			test.edit(f.Body())
+			if got := string(f.Bytes()); got != test.want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBlockTypeLabels(t *testing.T) {
+	f, diags := ParseConfig([]byte("a {}\nb \"x\" y \"z\\\"\" {}\n"), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+	f.Body().AppendNewBlock("c", []string{"new"})
+
+	var got []string
+	for _, block := range f.Body().Blocks() {
+		got = append(got, fmt.Sprintf("%s%q", block.Type(), block.Labels()))
+	}
+	want := []string{`a[]`, `b["x" "y" "z\""]`, `c["new"]`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
@@ -3,6 +3,10 @@ package hclwrite
 import (
 	"fmt"
 	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
 )
 
 type TestTreeNode struct {
@@ -45,3 +49,36 @@ func makeTestTree(n *node) (root TestTreeNode) {
 
 	return root
 }
+
+func TestFileValidate(t *testing.T) {
+	f := NewEmptyFile()
+	f.Body().SetAttributeValue("a", cty.StringVal("${not a template}"))
+	got, diags := f.ValidBytes("generated.hcl")
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if want := "a = \"$${not a template}\"\n"; string(got) != want {
+		t.Errorf("wrong result %q; want %q", got, want)
+	}
+
+	// Unstructured tokens are not checked as they are appended, and so may
+	// produce invalid source code.
+	f.Body().AppendUnstructuredTokens(Tokens{
+		{Type: hclsyntax.TokenIdent, Bytes: []byte("b")},
+		{Type: hclsyntax.TokenEqual, Bytes: []byte("=")},
+		{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+	})
+	got, diags = f.ValidBytes("generated.hcl")
+	if !diags.HasErrors() {
+		t.Fatalf("no errors for invalid result %q", f.Bytes())
+	}
+	if got != nil {
+		t.Errorf("result is non-nil for invalid file: %q", got)
+	}
+	if subj := diags[0].Subject; subj == nil || subj.Filename != "generated.hcl" || subj.Start.Line != 2 {
+		t.Errorf("wrong diagnostic subject %#v", subj)
+	}
+	if len(f.Validate("generated.hcl")) != len(diags) {
+		t.Errorf("Validate returned different diagnostics than ValidBytes")
+	}
+}
@@ -0,0 +1,24 @@
+package hclwrite
+
+import (
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+// CompareFiles compares the content of two files for structural equality,
+// returning nil if they are equal or a description of the first difference
+// otherwise. Layout and comments are ignored, as described for
+// hclsyntax.CompareBodies, so this can be used to check whether a set of
+// edits changed the meaning of a file.
+//
+// The files are compared by parsing the source code that they would produce,
+// and so error diagnostics are returned if either of them is not valid.
+func CompareFiles(a, b *File) (*hclsyntax.Difference, hcl.Diagnostics) {
+	fileA, diags := hclsyntax.ParseConfig(a.Bytes(), "a", hcl.Pos{Line: 1, Column: 1})
+	fileB, moreDiags := hclsyntax.ParseConfig(b.Bytes(), "b", hcl.Pos{Line: 1, Column: 1})
+	diags = append(diags, moreDiags...)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return hclsyntax.CompareBodies(fileA.Body.(*hclsyntax.Body), fileB.Body.(*hclsyntax.Body)), diags
+}
@@ -0,0 +1,39 @@
+package hclwrite
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestCompareFiles(t *testing.T) {
+	parsed, diags := ParseConfig([]byte("# greeting\nname = \"Ermintrude\"\n\npet \"cat\" {\n  legs = 4\n}\n"), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+
+	built := NewFile()
+	built.Body().SetAttributeValue("name", cty.StringVal("Ermintrude"))
+	built.Body().AppendNewBlock("pet", []string{"cat"}).Body().SetAttributeValue("legs", cty.NumberIntVal(4))
+
+	diff, diags := CompareFiles(parsed, built)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if diff != nil {
+		t.Errorf("unexpected difference: %s", diff)
+	}
+
+	built.Body().SetAttributeValue("name", cty.StringVal("Agatha"))
+	diff, diags = CompareFiles(parsed, built)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Error())
+	}
+	if diff == nil {
+		t.Fatalf("no difference found")
+	}
+	if got, want := diff.Path[0], "name"; got != want {
+		t.Errorf("wrong path %q; want %q", got, want)
+	}
+}
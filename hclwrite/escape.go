@@ -0,0 +1,109 @@
+package hclwrite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+// QuoteString returns the native syntax source code for a quoted string
+// literal whose value is exactly the given string, whatever it contains.
+//
+// Quotes, backslashes and non-printable characters are escaped with
+// backslash sequences, and the template introducers "${" and "%{" are
+// escaped as "$${" and "%%{", so that no part of the string can be
+// interpreted as anything other than literal text. This makes the result
+// safe for embedding strings from untrusted sources into generated code.
+//
+// This produces the same result as the tokens returned by TokensForValue for
+// a string value.
+func QuoteString(s string) string {
+	return `"` + string(escapeQuotedStringLit(s)) + `"`
+}
+
+// EscapeTemplate returns the given string with the template introducers
+// "${" and "%{" escaped as "$${" and "%%{", so that it is interpreted
+// literally when used as the content of a heredoc template.
+//
+// The result is not suitable for use between quotes, since it does not
+// escape quotes, backslashes or newlines; use QuoteString for that.
+func EscapeTemplate(s string) string {
+	s = strings.Replace(s, "${", "$${", -1)
+	return strings.Replace(s, "%{", "%%{", -1)
+}
+
+// HeredocSafe returns true if the given string can be written literally as
+// the content of a heredoc template introduced by "<<" with the given
+// delimiter, after escaping it with EscapeTemplate.
+//
+// That requires that the delimiter be a valid identifier, that the string be
+// empty or end with a newline, since the content of a heredoc always does,
+// and that none of the lines of the string consist of the delimiter alone,
+// which would end the heredoc early.
+func HeredocSafe(s, delimiter string) bool {
+	if !hcl.ValidIdentifier(delimiter) {
+		return false
+	}
+	if s == "" {
+		return true
+	}
+	if !strings.HasSuffix(s, "\n") {
+		return false
+	}
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if strings.TrimSpace(line) == delimiter {
+			return false
+		}
+	}
+	return true
+}
+
+// HeredocDelimiter returns a delimiter that can be used to write the given
+// string as the content of a heredoc template, as checked by HeredocSafe.
+// This is "EOT" unless the string contains a line consisting of that alone,
+// in which case a numeric suffix is added to make it unique, as in "EOT1".
+//
+// The given string must be empty or end with a newline, or this function
+// will panic since there is no delimiter that would make it safe.
+func HeredocDelimiter(s string) string {
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		panic("cannot write a string that does not end with a newline as a heredoc")
+	}
+	delimiter := "EOT"
+	for i := 1; !HeredocSafe(s, delimiter); i++ {
+		delimiter = fmt.Sprintf("EOT%d", i)
+	}
+	return delimiter
+}
+
+// TokensForHeredoc returns a sequence of tokens for a heredoc template whose
+// value is exactly the given string, using the given delimiter.
+//
+// The string and the delimiter must be safe for use together, as checked by
+// HeredocSafe, or this function will panic. HeredocDelimiter can be used to
+// choose a safe delimiter.
+func TokensForHeredoc(s, delimiter string) Tokens {
+	if !HeredocSafe(s, delimiter) {
+		panic(fmt.Sprintf("cannot write string as a heredoc with delimiter %q", delimiter))
+	}
+
+	toks := Tokens{
+		{
+			Type:  hclsyntax.TokenOHeredoc,
+			Bytes: []byte("<<" + delimiter + "\n"),
+		},
+	}
+	if s != "" {
+		toks = append(toks, &Token{
+			Type:  hclsyntax.TokenStringLit,
+			Bytes: []byte(EscapeTemplate(s)),
+		})
+	}
+	toks = append(toks, &Token{
+		Type:  hclsyntax.TokenCHeredoc,
+		Bytes: []byte(delimiter),
+	})
+	return toks
+}
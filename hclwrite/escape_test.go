@@ -0,0 +1,121 @@
+package hclwrite
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// testUntrustedStrings are strings that might be used in attempts to inject
+// code into generated configuration.
+var testUntrustedStrings = []string{
+	"",
+	"hello",
+	`"`,
+	`\`,
+	`\"`,
+	"${var.secret}",
+	"$${var.secret}",
+	"$$${var.secret}",
+	"%{ if true }x%{ endif }",
+	"%%{ if true }",
+	"$",
+	"%",
+	"${",
+	"a\"\n}\nmalicious = true\n",
+	"tab\there\r\nand\x00null",
+	" é\U0001F600",
+}
+
+func TestQuoteString(t *testing.T) {
+	for _, s := range testUntrustedStrings {
+		t.Run(s, func(t *testing.T) {
+			src := QuoteString(s)
+			if want := string(TokensForValue(cty.StringVal(s)).Bytes()); src != want {
+				t.Errorf("result %s differs from TokensForValue %s", src, want)
+			}
+
+			expr, diags := hclsyntax.ParseExpression([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("result %s does not parse: %s", src, diags.Error())
+			}
+			// Evaluating with an empty context ensures the result contains
+			// no references or function calls.
+			val, diags := expr.Value(&hcl.EvalContext{})
+			if diags.HasErrors() {
+				t.Fatalf("result %s does not evaluate: %s", src, diags.Error())
+			}
+			if !val.RawEquals(cty.StringVal(s)) {
+				t.Errorf("result %s has wrong value %#v; want %#v", src, val, cty.StringVal(s))
+			}
+		})
+	}
+}
+
+func TestTokensForHeredoc(t *testing.T) {
+	for _, s := range testUntrustedStrings {
+		s += "\n"
+		t.Run(s, func(t *testing.T) {
+			toks := TokensForHeredoc(s, HeredocDelimiter(s))
+			src := string(toks.Bytes())
+
+			expr, diags := hclsyntax.ParseExpression([]byte(src+"\n"), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("result does not parse: %s\n%s", diags.Error(), src)
+			}
+			val, diags := expr.Value(&hcl.EvalContext{})
+			if diags.HasErrors() {
+				t.Fatalf("result does not evaluate: %s\n%s", diags.Error(), src)
+			}
+			if !val.RawEquals(cty.StringVal(s)) {
+				t.Errorf("result has wrong value %#v; want %#v\n%s", val, cty.StringVal(s), src)
+			}
+		})
+	}
+}
+
+func TestHeredocSafe(t *testing.T) {
+	tests := []struct {
+		s, delimiter string
+		want         bool
+	}{
+		{"", "EOT", true},
+		{"hello\n", "EOT", true},
+		{"hello", "EOT", false},
+		{"hello\n", "not valid", false},
+		{"EOT\n", "EOT", false},
+		{"a\n  EOT \nb\n", "EOT", false},
+		{"a\nEOTX\nb\n", "EOT", true},
+		{"a EOT\n", "EOT", true},
+		{"EOT\n", "END", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			if got := HeredocSafe(test.s, test.delimiter); got != test.want {
+				t.Errorf("wrong result %t; want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHeredocDelimiter(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{"", "EOT"},
+		{"hello\n", "EOT"},
+		{"EOT\n", "EOT1"},
+		{"EOT\nEOT1\n", "EOT2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			if got := HeredocDelimiter(test.s); got != test.want {
+				t.Errorf("wrong result %q; want %q", got, test.want)
+			}
+		})
+	}
+}
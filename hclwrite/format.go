@@ -55,56 +55,63 @@ func formatIndent(lines []formatLine) {
 	indents := make([]int, 0, 10)
 
 	for i := range lines {
-		line := &lines[i]
-		if len(line.lead) == 0 {
-			continue
-		}
+		indents = formatLineIndent(&lines[i], indents)
+	}
+}
 
-		if line.lead[0].Type == hclsyntax.TokenNewline {
-			// Never place spaces before a newline
-			line.lead[0].SpacesBefore = 0
-			continue
-		}
+// formatLineIndent adjusts the indentation of the given line, as described
+// for formatIndent, given the indent stack resulting from the lines before
+// it. It returns the indent stack for the following line.
+func formatLineIndent(line *formatLine, indents []int) []int {
+	if len(line.lead) == 0 {
+		return indents
+	}
 
-		netBrackets := 0
-		for _, token := range line.lead {
-			netBrackets += tokenBracketChange(token)
-			if token.Type == hclsyntax.TokenOHeredoc {
-				break
-			}
-		}
+	if line.lead[0].Type == hclsyntax.TokenNewline {
+		// Never place spaces before a newline
+		line.lead[0].SpacesBefore = 0
+		return indents
+	}
 
-		for _, token := range line.assign {
-			netBrackets += tokenBracketChange(token)
+	netBrackets := 0
+	for _, token := range line.lead {
+		netBrackets += tokenBracketChange(token)
+		if token.Type == hclsyntax.TokenOHeredoc {
+			break
 		}
+	}
 
-		switch {
-		case netBrackets > 0:
-			line.lead[0].SpacesBefore = 2 * len(indents)
-			indents = append(indents, netBrackets)
-		case netBrackets < 0:
-			closed := -netBrackets
-			for closed > 0 && len(indents) > 0 {
-				switch {
-
-				case closed > indents[len(indents)-1]:
-					closed -= indents[len(indents)-1]
-					indents = indents[:len(indents)-1]
-
-				case closed < indents[len(indents)-1]:
-					indents[len(indents)-1] -= closed
-					closed = 0
-
-				default:
-					indents = indents[:len(indents)-1]
-					closed = 0
-				}
+	for _, token := range line.assign {
+		netBrackets += tokenBracketChange(token)
+	}
+
+	switch {
+	case netBrackets > 0:
+		line.lead[0].SpacesBefore = 2 * len(indents)
+		indents = append(indents, netBrackets)
+	case netBrackets < 0:
+		closed := -netBrackets
+		for closed > 0 && len(indents) > 0 {
+			switch {
+
+			case closed > indents[len(indents)-1]:
+				closed -= indents[len(indents)-1]
+				indents = indents[:len(indents)-1]
+
+			case closed < indents[len(indents)-1]:
+				indents[len(indents)-1] -= closed
+				closed = 0
+
+			default:
+				indents = indents[:len(indents)-1]
+				closed = 0
 			}
-			line.lead[0].SpacesBefore = 2 * len(indents)
-		default:
-			line.lead[0].SpacesBefore = 2 * len(indents)
 		}
+		line.lead[0].SpacesBefore = 2 * len(indents)
+	default:
+		line.lead[0].SpacesBefore = 2 * len(indents)
 	}
+	return indents
 }
 
 func formatSpaces(lines []formatLine) {
@@ -121,7 +128,7 @@ func formatSpaces(lines []formatLine) {
 			} else {
 				after = nilToken
 			}
-			if spaceAfterToken(token, before, after) {
+			if spaceAfterToken(token, before, after) && !startsNamespaceSep(line.lead, i+1) {
 				after.SpacesBefore = 1
 			} else {
 				after.SpacesBefore = 0
@@ -145,7 +152,7 @@ func formatSpaces(lines []formatLine) {
 			} else {
 				after = nilToken
 			}
-			if spaceAfterToken(token, before, after) {
+			if spaceAfterToken(token, before, after) && !startsNamespaceSep(line.assign, i+1) {
 				after.SpacesBefore = 1
 			} else {
 				after.SpacesBefore = 0
@@ -155,6 +162,17 @@ func formatSpaces(lines []formatLine) {
 	}
 }
 
+// startsNamespaceSep returns true if the token at the given index begins a
+// "::" separator in a namespaced function name, such as in
+// provider::cidr::host(). Such a separator is two adjacent colon tokens,
+// which we must not separate with spaces.
+func startsNamespaceSep(tokens []*Token, i int) bool {
+	if i < 0 || i+1 >= len(tokens) {
+		return false
+	}
+	return tokens[i].Type == hclsyntax.TokenColon && tokens[i+1].Type == hclsyntax.TokenColon && tokens[i+1].SpacesBefore == 0
+}
+
 func formatCells(lines []formatLine) {
 
 	chainStart := -1
@@ -235,6 +253,10 @@ func spaceAfterToken(subject, before, after *Token) bool {
 		// Don't split a function name from open paren in a call
 		return false
 
+	case subject.Type == hclsyntax.TokenColon && (before.Type == hclsyntax.TokenColon || after.Type == hclsyntax.TokenColon):
+		// Don't use spaces within or after a "::" function namespace separator
+		return false
+
 	case subject.Type == hclsyntax.TokenDot || after.Type == hclsyntax.TokenDot:
 		// Don't use spaces around attribute access dots
 		return false
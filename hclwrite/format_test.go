@@ -35,6 +35,14 @@ func TestFormat(t *testing.T) {
 			`a=b()[c]`,
 			`a = b()[c]`,
 		},
+		{
+			`a=provider :: cidr::host( b , 1 )`,
+			`a = provider::cidr::host(b, 1)`,
+		},
+		{
+			`a={b:c::d(e)}`,
+			`a = { b : c::d(e) }`,
+		},
 		{
 			`a=["hello"][0]`,
 			`a = ["hello"][0]`,
@@ -2,6 +2,9 @@ package hclwrite
 
 import (
 	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 
@@ -21,7 +24,30 @@ import (
 // values. A caller can call the value's IsWhollyKnown method to verify that
 // no unknown values are present before calling TokensForValue.
 func TokensForValue(val cty.Value) Tokens {
-	toks := appendTokensForValue(val, nil)
+	return TokensForValueWithOptions(val, ValueOptions{})
+}
+
+// ValueOptions customizes the tokens produced by TokensForValueWithOptions.
+type ValueOptions struct {
+	// NumberPrecision is the maximum number of significant digits used to
+	// write a number, which is rounded if it has more. If it is zero, each
+	// number is written with as many digits as are needed to represent it
+	// exactly.
+	NumberPrecision int
+
+	// ExponentThreshold, if greater than zero, causes numbers whose decimal
+	// exponent is at least this value, or at most its negation, to be
+	// written in exponent form. For example, with a threshold of 6 the
+	// numbers 1500000 and 0.0000015 are written as 1.5e+06 and 1.5e-06,
+	// while 150000 is written as is. If it is zero, numbers are never
+	// written in exponent form.
+	ExponentThreshold int
+}
+
+// TokensForValueWithOptions is like TokensForValue, but customizes the
+// formatting of the result with the given options.
+func TokensForValueWithOptions(val cty.Value, opts ValueOptions) Tokens {
+	toks := appendTokensForValue(val, opts, nil)
 	format(toks) // fiddle with the SpacesBefore field to get canonical spacing
 	return toks
 }
@@ -29,6 +55,9 @@ func TokensForValue(val cty.Value) Tokens {
 // TokensForTraversal returns a sequence of tokens that represents the given
 // traversal.
 //
+// Attribute steps whose names are not valid identifiers are written using
+// the equivalent index syntax, as in foo["bar baz"].
+//
 // If the traversal is absolute then the result is a self-contained, valid
 // reference expression. If the traversal is relative then the returned tokens
 // could be appended to some other expression tokens to traverse into the
@@ -39,7 +68,7 @@ func TokensForTraversal(traversal hcl.Traversal) Tokens {
 	return toks
 }
 
-func appendTokensForValue(val cty.Value, toks Tokens) Tokens {
+func appendTokensForValue(val cty.Value, opts ValueOptions, toks Tokens) Tokens {
 	switch {
 
 	case !val.IsKnown():
@@ -64,8 +93,7 @@ func appendTokensForValue(val cty.Value, toks Tokens) Tokens {
 		})
 
 	case val.Type() == cty.Number:
-		bf := val.AsBigFloat()
-		srcStr := bf.Text('f', -1)
+		srcStr := formatNumber(val.AsBigFloat(), opts)
 		toks = append(toks, &Token{
 			Type:  hclsyntax.TokenNumberLit,
 			Bytes: []byte(srcStr),
@@ -105,7 +133,7 @@ func appendTokensForValue(val cty.Value, toks Tokens) Tokens {
 				})
 			}
 			_, eVal := it.Element()
-			toks = appendTokensForValue(eVal, toks)
+			toks = appendTokensForValue(eVal, opts, toks)
 			i++
 		}
 
@@ -129,19 +157,19 @@ func appendTokensForValue(val cty.Value, toks Tokens) Tokens {
 				})
 			}
 			eKey, eVal := it.Element()
-			if hclsyntax.ValidIdentifier(eKey.AsString()) {
+			if hcl.ValidIdentifier(eKey.AsString()) {
 				toks = append(toks, &Token{
 					Type:  hclsyntax.TokenIdent,
 					Bytes: []byte(eKey.AsString()),
 				})
 			} else {
-				toks = appendTokensForValue(eKey, toks)
+				toks = appendTokensForValue(eKey, opts, toks)
 			}
 			toks = append(toks, &Token{
 				Type:  hclsyntax.TokenEqual,
 				Bytes: []byte{'='},
 			})
-			toks = appendTokensForValue(eVal, toks)
+			toks = appendTokensForValue(eVal, opts, toks)
 			i++
 		}
 
@@ -157,14 +185,58 @@ func appendTokensForValue(val cty.Value, toks Tokens) Tokens {
 	return toks
 }
 
+// formatNumber returns the source code for the given number, formatted as
+// described by the given options.
+func formatNumber(bf *big.Float, opts ValueOptions) string {
+	if bf.IsInf() || (opts.NumberPrecision <= 0 && opts.ExponentThreshold <= 0) {
+		return bf.Text('f', -1)
+	}
+
+	prec := -1
+	if opts.NumberPrecision > 0 {
+		prec = opts.NumberPrecision - 1
+	}
+	// The result has the form "-d.ddde+dd", where the sign and the fraction
+	// are optional.
+	str := bf.Text('e', prec)
+	var sign string
+	if str[0] == '-' {
+		sign, str = "-", str[1:]
+	}
+	eIdx := strings.IndexByte(str, 'e')
+	exp, _ := strconv.Atoi(str[eIdx+1:])
+	digits := strings.Replace(str[:eIdx], ".", "", 1)
+	digits = strings.TrimRight(digits, "0")
+	if digits == "" {
+		return "0"
+	}
+
+	if opts.ExponentThreshold > 0 && (exp >= opts.ExponentThreshold || exp <= -opts.ExponentThreshold) {
+		mant := digits[:1]
+		if len(digits) > 1 {
+			mant += "." + digits[1:]
+		}
+		return fmt.Sprintf("%s%se%+03d", sign, mant, exp)
+	}
+
+	switch {
+	case exp < 0:
+		return sign + "0." + strings.Repeat("0", -exp-1) + digits
+	case exp+1 >= len(digits):
+		return sign + digits + strings.Repeat("0", exp+1-len(digits))
+	default:
+		return sign + digits[:exp+1] + "." + digits[exp+1:]
+	}
+}
+
 func appendTokensForTraversal(traversal hcl.Traversal, toks Tokens) Tokens {
 	for _, step := range traversal {
-		appendTokensForTraversalStep(step, toks)
+		toks = appendTokensForTraversalStep(step, toks)
 	}
 	return toks
 }
 
-func appendTokensForTraversalStep(step hcl.Traverser, toks Tokens) {
+func appendTokensForTraversalStep(step hcl.Traverser, toks Tokens) Tokens {
 	switch ts := step.(type) {
 	case hcl.TraverseRoot:
 		toks = append(toks, &Token{
@@ -172,6 +244,13 @@ func appendTokensForTraversalStep(step hcl.Traverser, toks Tokens) {
 			Bytes: []byte(ts.Name),
 		})
 	case hcl.TraverseAttr:
+		if !hcl.ValidIdentifier(ts.Name) {
+			// Attributes whose names are not valid identifiers can be
+			// accessed only with the index syntax.
+			return appendTokensForTraversalStep(hcl.TraverseIndex{
+				Key: cty.StringVal(ts.Name),
+			}, toks)
+		}
 		toks = append(
 			toks,
 			&Token{
@@ -188,7 +267,7 @@ func appendTokensForTraversalStep(step hcl.Traverser, toks Tokens) {
 			Type:  hclsyntax.TokenOBrack,
 			Bytes: []byte{'['},
 		})
-		appendTokensForValue(ts.Key, toks)
+		toks = appendTokensForValue(ts.Key, ValueOptions{}, toks)
 		toks = append(toks, &Token{
 			Type:  hclsyntax.TokenCBrack,
 			Bytes: []byte{']'},
@@ -196,6 +275,7 @@ func appendTokensForTraversalStep(step hcl.Traverser, toks Tokens) {
 	default:
 		panic(fmt.Sprintf("unsupported traversal step type %T", step))
 	}
+	return toks
 }
 
 func escapeQuotedStringLit(s string) []byte {
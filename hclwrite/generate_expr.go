@@ -0,0 +1,366 @@
+package hclwrite
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// TokensForExpression returns a sequence of tokens that represents the given
+// native syntax expression.
+//
+// This can render expressions that were constructed or transformed
+// programmatically, such as those returned by hclsyntax.Specialize, for
+// which there is no source code. The result has canonical formatting, and
+// parentheses are added only where they are needed to preserve the
+// structure of the expression, since the syntax tree does not record the
+// parentheses of the original source. Templates, including heredoc
+// templates, are rendered as quoted strings.
+//
+// Number literals that were parsed from source code keep their original
+// form, such as "1.50" or "1e3". Other literal values are rendered as by
+// TokensForValue, and so the same limitations apply. In particular, a list
+// or set literal is rendered as a tuple constructor and a map literal as an
+// object constructor, so their values will have a different type if the
+// result is parsed and evaluated.
+//
+// This function panics if given a type of expression that is not defined by
+// package hclsyntax, or a BinaryOpExpr or UnaryOpExpr with an operation
+// that is not one of those predefined by that package.
+func TokensForExpression(expr hclsyntax.Expression) Tokens {
+	toks := appendTokensForExpression(expr, nil)
+	format(toks) // fiddle with the SpacesBefore field to get canonical spacing
+	return toks
+}
+
+// binaryOpTokens gives the token type and precedence of each of the binary
+// operations, with higher numbers binding more tightly.
+var binaryOpTokens = map[*hclsyntax.Operation]struct {
+	Type       hclsyntax.TokenType
+	Bytes      string
+	Precedence int
+}{
+	hclsyntax.OpLogicalOr:          {hclsyntax.TokenOr, "||", 1},
+	hclsyntax.OpLogicalAnd:         {hclsyntax.TokenAnd, "&&", 2},
+	hclsyntax.OpEqual:              {hclsyntax.TokenEqualOp, "==", 3},
+	hclsyntax.OpNotEqual:           {hclsyntax.TokenNotEqual, "!=", 3},
+	hclsyntax.OpGreaterThan:        {hclsyntax.TokenGreaterThan, ">", 4},
+	hclsyntax.OpGreaterThanOrEqual: {hclsyntax.TokenGreaterThanEq, ">=", 4},
+	hclsyntax.OpLessThan:           {hclsyntax.TokenLessThan, "<", 4},
+	hclsyntax.OpLessThanOrEqual:    {hclsyntax.TokenLessThanEq, "<=", 4},
+	hclsyntax.OpAdd:                {hclsyntax.TokenPlus, "+", 5},
+	hclsyntax.OpSubtract:           {hclsyntax.TokenMinus, "-", 5},
+	hclsyntax.OpMultiply:           {hclsyntax.TokenStar, "*", 6},
+	hclsyntax.OpDivide:             {hclsyntax.TokenSlash, "/", 6},
+	hclsyntax.OpModulo:             {hclsyntax.TokenPercent, "%", 6},
+}
+
+func appendTokensForExpression(expr hclsyntax.Expression, toks Tokens) Tokens {
+	switch e := expr.(type) {
+
+	case *hclsyntax.LiteralValueExpr:
+		if lit := e.NumberLit(); lit != "" {
+			// Preserve the original form of a number from the source code.
+			toks = append(toks, newToken(hclsyntax.TokenNumberLit, lit))
+		} else {
+			toks = appendTokensForValue(e.Val, ValueOptions{}, toks)
+		}
+
+	case *hclsyntax.ScopeTraversalExpr:
+		toks = appendTokensForTraversal(e.Traversal, toks)
+
+	case *hclsyntax.RelativeTraversalExpr:
+		toks = appendTokensForOperand(e.Source, needsParensAsSource(e.Source), toks)
+		toks = appendTokensForTraversal(e.Traversal, toks)
+
+	case *hclsyntax.IndexExpr:
+		toks = appendTokensForOperand(e.Collection, needsParensAsSource(e.Collection), toks)
+		toks = append(toks, newToken(hclsyntax.TokenOBrack, "["))
+		toks = appendTokensForExpression(e.Key, toks)
+		toks = append(toks, newToken(hclsyntax.TokenCBrack, "]"))
+
+	case *hclsyntax.SplatExpr:
+		toks = appendTokensForOperand(e.Source, needsParensAsSource(e.Source), toks)
+		toks = append(
+			toks,
+			newToken(hclsyntax.TokenOBrack, "["),
+			newToken(hclsyntax.TokenStar, "*"),
+			newToken(hclsyntax.TokenCBrack, "]"),
+		)
+		toks = appendTokensForExpression(e.Each, toks)
+
+	case *hclsyntax.AnonSymbolExpr:
+		// This represents the current element within the Each expression of
+		// a splat expression, which is implied by the splat operator itself.
+
+	case *hclsyntax.FunctionCallExpr:
+		toks = append(
+			toks,
+			newToken(hclsyntax.TokenIdent, e.Name),
+			newToken(hclsyntax.TokenOParen, "("),
+		)
+		for i, arg := range e.Args {
+			if i > 0 {
+				toks = append(toks, newToken(hclsyntax.TokenComma, ","))
+			}
+			toks = appendTokensForExpression(arg, toks)
+		}
+		if e.ExpandFinal {
+			toks = append(toks, newToken(hclsyntax.TokenEllipsis, "..."))
+		}
+		toks = append(toks, newToken(hclsyntax.TokenCParen, ")"))
+
+	case *hclsyntax.ConditionalExpr:
+		_, nested := e.Condition.(*hclsyntax.ConditionalExpr)
+		toks = appendTokensForOperand(e.Condition, nested, toks)
+		toks = append(toks, newToken(hclsyntax.TokenQuestion, "?"))
+		toks = appendTokensForExpression(e.TrueResult, toks)
+		toks = append(toks, newToken(hclsyntax.TokenColon, ":"))
+		toks = appendTokensForExpression(e.FalseResult, toks)
+
+	case *hclsyntax.BinaryOpExpr:
+		op, ok := binaryOpTokens[e.Op]
+		if !ok {
+			panic("cannot produce tokens for unsupported binary operation")
+		}
+		toks = appendTokensForOperand(e.LHS, needsParensAsOperand(e.LHS, op.Precedence, false), toks)
+		toks = append(toks, newToken(op.Type, op.Bytes))
+		toks = appendTokensForOperand(e.RHS, needsParensAsOperand(e.RHS, op.Precedence, true), toks)
+
+	case *hclsyntax.UnaryOpExpr:
+		switch e.Op {
+		case hclsyntax.OpLogicalNot:
+			toks = append(toks, newToken(hclsyntax.TokenBang, "!"))
+		case hclsyntax.OpNegate:
+			toks = append(toks, newToken(hclsyntax.TokenMinus, "-"))
+		default:
+			panic("cannot produce tokens for unsupported unary operation")
+		}
+		var parens bool
+		switch val := e.Val.(type) {
+		case *hclsyntax.ConditionalExpr, *hclsyntax.BinaryOpExpr:
+			parens = true
+		case *hclsyntax.UnaryOpExpr:
+			// Avoid a double minus, as in -(-a).
+			parens = val.Op == hclsyntax.OpNegate && e.Op == hclsyntax.OpNegate
+		case *hclsyntax.LiteralValueExpr:
+			// Likewise for a negative number, as in -(-1).
+			parens = val.Val.Type() == cty.Number && val.Val.IsKnown() && !val.Val.IsNull() && val.Val.AsBigFloat().Sign() < 0
+		}
+		toks = appendTokensForOperand(e.Val, parens, toks)
+
+	case *hclsyntax.TupleConsExpr:
+		toks = append(toks, newToken(hclsyntax.TokenOBrack, "["))
+		for i, elem := range e.Exprs {
+			if i > 0 {
+				toks = append(toks, newToken(hclsyntax.TokenComma, ","))
+			}
+			toks = appendTokensForExpression(elem, toks)
+		}
+		toks = append(toks, newToken(hclsyntax.TokenCBrack, "]"))
+
+	case *hclsyntax.ObjectConsExpr:
+		toks = append(toks, newToken(hclsyntax.TokenOBrace, "{"))
+		for i, item := range e.Items {
+			if i > 0 {
+				toks = append(toks, newToken(hclsyntax.TokenComma, ","))
+			}
+			toks = appendTokensForExpression(item.KeyExpr, toks)
+			toks = append(toks, newToken(hclsyntax.TokenEqual, "="))
+			toks = appendTokensForExpression(item.ValueExpr, toks)
+		}
+		toks = append(toks, newToken(hclsyntax.TokenCBrace, "}"))
+
+	case *hclsyntax.ObjectConsKeyExpr:
+		if name := hcl.ExprAsKeyword(e.Wrapped); name != "" {
+			toks = append(toks, newToken(hclsyntax.TokenIdent, name))
+			break
+		}
+		// Any key expression other than a template or a literal must be
+		// in parentheses to prevent it from being taken as a keyword.
+		var parens bool
+		switch e.Wrapped.(type) {
+		case *hclsyntax.TemplateExpr, *hclsyntax.TemplateWrapExpr, *hclsyntax.LiteralValueExpr, *hclsyntax.FunctionCallExpr:
+		default:
+			parens = true
+		}
+		toks = appendTokensForOperand(e.Wrapped, parens, toks)
+
+	case *hclsyntax.ForExpr:
+		openType, closeType := hclsyntax.TokenOBrack, hclsyntax.TokenCBrack
+		openBytes, closeBytes := "[", "]"
+		if e.KeyExpr != nil {
+			openType, closeType = hclsyntax.TokenOBrace, hclsyntax.TokenCBrace
+			openBytes, closeBytes = "{", "}"
+		}
+		toks = append(toks, newToken(openType, openBytes))
+		toks = appendTokensForForIntro(e, toks)
+		toks = append(toks, newToken(hclsyntax.TokenColon, ":"))
+		if e.KeyExpr != nil {
+			toks = appendTokensForOperand(e.KeyExpr, isConditional(e.KeyExpr), toks)
+			toks = append(toks, newToken(hclsyntax.TokenFatArrow, "=>"))
+		}
+		toks = appendTokensForOperand(e.ValExpr, isConditional(e.ValExpr), toks)
+		if e.Group {
+			toks = append(toks, newToken(hclsyntax.TokenEllipsis, "..."))
+		}
+		if e.CondExpr != nil {
+			toks = append(toks, newToken(hclsyntax.TokenIdent, "if"))
+			toks = appendTokensForExpression(e.CondExpr, toks)
+		}
+		toks = append(toks, newToken(closeType, closeBytes))
+
+	case *hclsyntax.TemplateExpr, *hclsyntax.TemplateWrapExpr, *hclsyntax.TemplateJoinExpr:
+		toks = append(toks, newToken(hclsyntax.TokenOQuote, `"`))
+		toks = appendTokensForTemplate(expr, toks)
+		toks = append(toks, newToken(hclsyntax.TokenCQuote, `"`))
+
+	default:
+		panic(fmt.Sprintf("cannot produce tokens for %T", expr))
+	}
+
+	return toks
+}
+
+// appendTokensForTemplate appends the tokens for the content of the given
+// template, without the surrounding quotes.
+func appendTokensForTemplate(expr hclsyntax.Expression, toks Tokens) Tokens {
+	switch e := expr.(type) {
+	case *hclsyntax.TemplateExpr:
+		for _, part := range e.Parts {
+			toks = appendTokensForTemplate(part, toks)
+		}
+
+	case *hclsyntax.TemplateWrapExpr:
+		toks = appendTokensForTemplate(e.Wrapped, toks)
+
+	case *hclsyntax.LiteralValueExpr:
+		if e.Val.Type() != cty.String || e.Val.IsNull() {
+			toks = appendTokensForInterp(e, toks)
+			break
+		}
+		if src := escapeQuotedStringLit(e.Val.AsString()); len(src) > 0 {
+			toks = append(toks, &Token{
+				Type:  hclsyntax.TokenQuotedLit,
+				Bytes: src,
+			})
+		}
+
+	case *hclsyntax.TemplateJoinExpr:
+		forExpr, ok := e.Tuple.(*hclsyntax.ForExpr)
+		if !ok || forExpr.KeyExpr != nil || forExpr.CondExpr != nil || forExpr.Group {
+			// The tuple is no longer a template "for" directive, so we
+			// iterate over its elements instead.
+			forExpr = &hclsyntax.ForExpr{
+				ValVar:   "v",
+				CollExpr: e.Tuple,
+				ValExpr: &hclsyntax.ScopeTraversalExpr{
+					Traversal: hcl.Traversal{hcl.TraverseRoot{Name: "v"}},
+				},
+			}
+		}
+		toks = append(toks, newToken(hclsyntax.TokenTemplateControl, "%{"))
+		toks = appendTokensForForIntro(forExpr, toks)
+		toks = append(toks, newToken(hclsyntax.TokenTemplateSeqEnd, "}"))
+		toks = appendTokensForTemplate(forExpr.ValExpr, toks)
+		toks = append(
+			toks,
+			newToken(hclsyntax.TokenTemplateControl, "%{"),
+			newToken(hclsyntax.TokenIdent, "endfor"),
+			newToken(hclsyntax.TokenTemplateSeqEnd, "}"),
+		)
+
+	default:
+		toks = appendTokensForInterp(expr, toks)
+	}
+	return toks
+}
+
+func appendTokensForInterp(expr hclsyntax.Expression, toks Tokens) Tokens {
+	toks = append(toks, newToken(hclsyntax.TokenTemplateInterp, "${"))
+	toks = appendTokensForExpression(expr, toks)
+	return append(toks, newToken(hclsyntax.TokenTemplateSeqEnd, "}"))
+}
+
+// appendTokensForForIntro appends the "for k, v in coll" portion of a 'for'
+// expression or directive.
+func appendTokensForForIntro(e *hclsyntax.ForExpr, toks Tokens) Tokens {
+	toks = append(toks, newToken(hclsyntax.TokenIdent, "for"))
+	if e.KeyVar != "" {
+		toks = append(
+			toks,
+			newToken(hclsyntax.TokenIdent, e.KeyVar),
+			newToken(hclsyntax.TokenComma, ","),
+		)
+	}
+	toks = append(
+		toks,
+		newToken(hclsyntax.TokenIdent, e.ValVar),
+		newToken(hclsyntax.TokenIdent, "in"),
+	)
+	return appendTokensForOperand(e.CollExpr, isConditional(e.CollExpr), toks)
+}
+
+func appendTokensForOperand(expr hclsyntax.Expression, parens bool, toks Tokens) Tokens {
+	if !parens {
+		return appendTokensForExpression(expr, toks)
+	}
+	toks = append(toks, newToken(hclsyntax.TokenOParen, "("))
+	toks = appendTokensForExpression(expr, toks)
+	return append(toks, newToken(hclsyntax.TokenCParen, ")"))
+}
+
+// needsParensAsOperand returns true if the given expression must be placed
+// in parentheses to be an operand of an operator with the given precedence.
+// Since the binary operators are left-associative, an operand on the right
+// hand side also needs parentheses if its operator has the same precedence.
+func needsParensAsOperand(expr hclsyntax.Expression, precedence int, rhs bool) bool {
+	switch e := expr.(type) {
+	case *hclsyntax.ConditionalExpr:
+		return true
+	case *hclsyntax.BinaryOpExpr:
+		op, ok := binaryOpTokens[e.Op]
+		if !ok {
+			return true
+		}
+		return op.Precedence < precedence || (rhs && op.Precedence == precedence)
+	default:
+		return false
+	}
+}
+
+// needsParensAsSource returns true if the given expression must be placed in
+// parentheses to be the source of a traversal, index or splat operator.
+func needsParensAsSource(expr hclsyntax.Expression) bool {
+	switch e := expr.(type) {
+	case *hclsyntax.ScopeTraversalExpr, *hclsyntax.RelativeTraversalExpr,
+		*hclsyntax.IndexExpr, *hclsyntax.FunctionCallExpr,
+		*hclsyntax.TupleConsExpr, *hclsyntax.ObjectConsExpr,
+		*hclsyntax.ForExpr, *hclsyntax.AnonSymbolExpr:
+		return false
+	case *hclsyntax.LiteralValueExpr:
+		// A number would absorb a following attribute access as its
+		// fractional part, so we only leave out the parentheses for
+		// collections and strings, which have their own delimiters.
+		ty := e.Val.Type()
+		return e.Val.IsNull() || !(ty == cty.String || ty.IsCollectionType() || ty.IsObjectType() || ty.IsTupleType())
+	default:
+		// This includes splat expressions, since any operators that follow
+		// a splat expression apply to each of its elements.
+		return true
+	}
+}
+
+func isConditional(expr hclsyntax.Expression) bool {
+	_, ok := expr.(*hclsyntax.ConditionalExpr)
+	return ok
+}
+
+func newToken(ty hclsyntax.TokenType, bytes string) *Token {
+	return &Token{
+		Type:  ty,
+		Bytes: []byte(bytes),
+	}
+}
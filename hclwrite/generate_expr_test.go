@@ -0,0 +1,178 @@
+package hclwrite
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestTokensForExpression(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`1`, `1`},
+		{`1.50`, `1.50`},
+		{`1e3 + 2E-1`, `1e3 + 2E-1`},
+		{`"hello"`, `"hello"`},
+		{`var.foo[0]["bar"]`, `var.foo[0]["bar"]`},
+		{`foo(a, b...)`, `foo(a, b...)`},
+		{`a+b*c`, `a + b * c`},
+		{`(a+b)*c`, `(a + b) * c`},
+		{`a - (b - c)`, `a - (b - c)`},
+		{`(a - b) - c`, `a - b - c`},
+		{`!(a&&b)`, `! (a && b)`},
+		{`-(-a)`, `-(-a)`},
+		{`!!a`, `! ! a`},
+		{`-(-1)`, `-(-1)`},
+		{`a ? b : c`, `a ? b : c`},
+		{`(a ? b : c) ? d : e`, `(a ? b : c) ? d : e`},
+		{`(a ? b : c) + 1`, `(a ? b : c) + 1`},
+		{`[a, [b]]`, `[a, [b]]`},
+		{`{a = 1, "b c" = 2, (k) = 3, (a + b) = 4, f(x) = 5}`, `{ a = 1, "b c" = 2, k = 3, (a + b) = 4, f(x) = 5 }`},
+		{`a[*].b`, `a[*].b`},
+		{`a.*.b`, `a[*].b`},
+		{`a[*].b[*].c`, `a[*].b[*].c`},
+		{`(a[*].b)[0]`, `(a[*].b)[0]`},
+		{`(a+b).c`, `(a + b).c`},
+		{`f(x)[k]`, `f(x)[k]`},
+		{`[for k, v in m: v if k != ""]`, `[for k, v in m : v if k != ""]`},
+		{`{for v in l: v.k => v...}`, `{ for v in l : v.k => v... }`},
+		{`"a${b}c"`, `"a${b}c"`},
+		{`"${a}"`, `"${a}"`},
+		{`"$${a} \"q\"\n"`, `"$${a} \"q\"\n"`},
+		{`"%{ if a }x%{ else }y%{ endif }"`, `"${a ? "x" : "y"}"`},
+		{`"%{ for v in l }${v},%{ endfor }"`, `"%{for v in l}${v},%{endfor}"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+
+			got := string(TokensForExpression(expr).Bytes())
+			if got != test.want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.want)
+			}
+
+			// The result must itself be a valid expression.
+			_, diags = hclsyntax.ParseExpression([]byte(got), "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+			if diags.HasErrors() {
+				t.Errorf("result does not parse: %s", diags.Error())
+			}
+		})
+	}
+}
+
+func TestTokensForExpressionLiterals(t *testing.T) {
+	tests := []struct {
+		expr hclsyntax.Expression
+		want string
+	}{
+		{
+			&hclsyntax.LiteralValueExpr{
+				Val: cty.ObjectVal(map[string]cty.Value{
+					"a": cty.ListVal([]cty.Value{cty.NumberIntVal(1)}),
+				}),
+			},
+			`{ a = [1] }`,
+		},
+		{
+			&hclsyntax.RelativeTraversalExpr{
+				Source: &hclsyntax.LiteralValueExpr{
+					Val: cty.NumberIntVal(1),
+				},
+				Traversal: hcl.Traversal{hcl.TraverseAttr{Name: "a"}},
+			},
+			`(1).a`,
+		},
+		{
+			&hclsyntax.TemplateExpr{
+				Parts: []hclsyntax.Expression{
+					&hclsyntax.LiteralValueExpr{Val: cty.StringVal("${x} ")},
+					&hclsyntax.LiteralValueExpr{Val: cty.NumberIntVal(2)},
+				},
+			},
+			`"$${x} ${2}"`,
+		},
+		{
+			&hclsyntax.TemplateExpr{
+				Parts: []hclsyntax.Expression{
+					&hclsyntax.TemplateJoinExpr{
+						Tuple: &hclsyntax.ScopeTraversalExpr{
+							Traversal: hcl.Traversal{hcl.TraverseRoot{Name: "l"}},
+						},
+					},
+				},
+			},
+			`"%{for v in l}${v}%{endfor}"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			got := string(TokensForExpression(test.expr).Bytes())
+			if got != test.want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTokensForTraversal(t *testing.T) {
+	traversal := hcl.Traversal{
+		hcl.TraverseRoot{Name: "foo"},
+		hcl.TraverseAttr{Name: "bar"},
+		hcl.TraverseIndex{Key: cty.StringVal("baz")},
+		hcl.TraverseIndex{Key: cty.NumberIntVal(1)},
+		hcl.TraverseAttr{Name: "not an identifier"},
+	}
+	got := string(TokensForTraversal(traversal).Bytes())
+	want := `foo.bar["baz"][1]["not an identifier"]`
+	if got != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestTokensForExpressionSpecialized(t *testing.T) {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"name": cty.StringVal("world"),
+			"n":    cty.NumberIntVal(2),
+			"tags": cty.MapVal(map[string]cty.Value{
+				"env": cty.StringVal("prod"),
+			}),
+		},
+	}
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`"hello ${name}${suffix}"`, `"hello world${suffix}"`},
+		{`n * 2 + count`, `4 + count`},
+		{`merge(tags, { name = name, id = id })`, `merge({ env = "prod" }, { name = "world", id = id })`},
+		{`[for x in list : x * n]`, `[for x in list : x * 2]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.src, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(test.src), "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse errors: %s", diags.Error())
+			}
+			expr, diags = hclsyntax.Specialize(expr, ctx)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+
+			got := string(TokensForExpression(expr).Bytes())
+			if got != test.want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
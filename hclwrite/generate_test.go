@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl2/hcl"
 	"github.com/hashicorp/hcl2/hcl/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -472,3 +473,99 @@ func TestTokensForValue(t *testing.T) {
 		})
 	}
 }
+
+func TestTokensForValueWithOptions(t *testing.T) {
+	tests := []struct {
+		Val  cty.Value
+		Opts ValueOptions
+		Want string
+	}{
+		{
+			cty.NumberFloatVal(1.5),
+			ValueOptions{},
+			`1.5`,
+		},
+		{
+			cty.MustParseNumberVal("3.14159"),
+			ValueOptions{NumberPrecision: 3},
+			`3.14`,
+		},
+		{
+			cty.MustParseNumberVal("1.50001"),
+			ValueOptions{NumberPrecision: 3},
+			`1.5`,
+		},
+		{
+			cty.NumberIntVal(123456),
+			ValueOptions{NumberPrecision: 2},
+			`120000`,
+		},
+		{
+			cty.MustParseNumberVal("-0.00123456"),
+			ValueOptions{NumberPrecision: 2},
+			`-0.0012`,
+		},
+		{
+			cty.NumberIntVal(0),
+			ValueOptions{NumberPrecision: 2, ExponentThreshold: 3},
+			`0`,
+		},
+		{
+			cty.NumberIntVal(1500000),
+			ValueOptions{ExponentThreshold: 6},
+			`1.5e+06`,
+		},
+		{
+			cty.NumberIntVal(150000),
+			ValueOptions{ExponentThreshold: 6},
+			`150000`,
+		},
+		{
+			cty.MustParseNumberVal("0.0000015"),
+			ValueOptions{ExponentThreshold: 6},
+			`1.5e-06`,
+		},
+		{
+			cty.MustParseNumberVal("-1e100"),
+			ValueOptions{ExponentThreshold: 21},
+			`-1e+100`,
+		},
+		{
+			cty.MustParseNumberVal("123.456e30"),
+			ValueOptions{NumberPrecision: 2, ExponentThreshold: 21},
+			`1.2e+32`,
+		},
+		{
+			cty.TupleVal([]cty.Value{
+				cty.MustParseNumberVal("0.333333"),
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.MustParseNumberVal("0.666666"),
+				}),
+			}),
+			ValueOptions{NumberPrecision: 2},
+			`[0.33, { a = 0.67 }]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Val.GoString(), func(t *testing.T) {
+			got := string(TokensForValueWithOptions(test.Val, test.Opts).Bytes())
+			if got != test.Want {
+				t.Errorf("wrong result\nvalue: %#v\ngot:   %s\nwant:  %s", test.Val, got, test.Want)
+			}
+
+			// The result must parse as the same number, allowing for the
+			// rounding requested by the options.
+			expr, diags := hclsyntax.ParseExpression([]byte(got), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("result does not parse: %s", diags.Error())
+			}
+			if test.Opts.NumberPrecision == 0 {
+				val, _ := expr.Value(nil)
+				if !val.Equals(test.Val).True() {
+					t.Errorf("result has wrong value %#v", val)
+				}
+			}
+		})
+	}
+}
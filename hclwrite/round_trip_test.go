@@ -17,6 +17,8 @@ func TestRoundTripVerbatim(t *testing.T) {
 	tests := []string{
 		``,
 		`foo = 1
+`,
+		`foo = [1.50, 1e3, 2.5E-3, 007]
 `,
 		`
 foobar = 1
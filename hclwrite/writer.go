@@ -0,0 +1,140 @@
+package hclwrite
+
+import (
+	"io"
+
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+// TokenWriter writes tokens to an io.Writer as they are given, formatting
+// them in the canonical style on the way.
+//
+// This is an alternative to building a File in memory, for applications that
+// generate configuration too large to hold comfortably as a syntax tree,
+// such as a file with a block for each of the rows exported from a database.
+// The tokens for each construct can be obtained from functions such as
+// TokensForValue and TokensForTraversal, or constructed directly, and passed
+// to the writer one at a time or in sequences:
+//
+//     tw := hclwrite.NewTokenWriter(w)
+//     for _, row := range rows {
+//         tw.WriteTokens(hclwrite.Tokens{
+//             {Type: hclsyntax.TokenIdent, Bytes: []byte("user")},
+//             {Type: hclsyntax.TokenOBrace, Bytes: []byte("{")},
+//             {Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+//             {Type: hclsyntax.TokenIdent, Bytes: []byte("name")},
+//             {Type: hclsyntax.TokenEqual, Bytes: []byte("=")},
+//         })
+//         tw.WriteTokens(hclwrite.TokensForValue(row.Name))
+//         ...
+//     }
+//     err := tw.Flush()
+//
+// The result is the same as if all of the tokens were formatted together, as
+// by Format. The indentation of each line is decided by tracking the
+// brackets that are open at its start, and the spacing between tokens by
+// the tokens on the same line, and so each line can be written as soon as it
+// is complete, except that the equals signs and comments of consecutive
+// lines are aligned with each other; those lines are held until the end of
+// the run of lines that must be aligned.
+//
+// The writer modifies the SpacesBefore field of the tokens it is given. The
+// caller must call Flush after writing the final token to ensure that all of
+// the output has been written.
+type TokenWriter struct {
+	w       io.Writer
+	line    Tokens
+	pending []formatLine
+	indents []int
+	err     error
+}
+
+// NewTokenWriter returns a TokenWriter that writes to the given writer.
+func NewTokenWriter(w io.Writer) *TokenWriter {
+	return &TokenWriter{
+		w:       w,
+		indents: make([]int, 0, 10),
+	}
+}
+
+// WriteToken adds the given token to the output.
+//
+// If an error occurs when writing to the underlying writer then it is
+// returned, and the same error is returned by all subsequent calls without
+// writing anything further. Since output is delayed until the end of each
+// line, the error may have been caused by an earlier token.
+func (tw *TokenWriter) WriteToken(tok *Token) error {
+	if tw.err != nil {
+		return tw.err
+	}
+	if tok.Type == hclsyntax.TokenEOF {
+		// The EOF token has no content, and is only used to terminate
+		// token sequences.
+		return nil
+	}
+
+	tw.line = append(tw.line, tok)
+	if tokenIsNewline(tok) {
+		tw.endLine()
+	}
+	return tw.err
+}
+
+// WriteTokens adds each of the given tokens to the output in turn, as with
+// WriteToken.
+func (tw *TokenWriter) WriteTokens(toks Tokens) error {
+	for _, tok := range toks {
+		if err := tw.WriteToken(tok); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any output that is still pending, including an incomplete
+// final line, to the underlying writer.
+//
+// Writing can continue after a flush, but any line that was incomplete is
+// then treated as a whole line, and the alignment of the lines before and
+// after the flush is independent.
+func (tw *TokenWriter) Flush() error {
+	if tw.err != nil {
+		return tw.err
+	}
+	if len(tw.line) > 0 {
+		tw.endLine()
+	}
+	tw.writePending()
+	return tw.err
+}
+
+// endLine formats the tokens of the current line, adding it to the pending
+// lines and writing them out if it ends a run of aligned lines.
+func (tw *TokenWriter) endLine() {
+	line := linesForFormat(tw.line)[0]
+	tw.line = nil
+
+	tw.indents = formatLineIndent(&line, tw.indents)
+	formatSpaces([]formatLine{line})
+	tw.pending = append(tw.pending, line)
+
+	// Only lines with an assignment or a trailing comment are aligned with
+	// their neighbors, so any other line ends all runs of aligned lines.
+	if line.assign == nil && line.comment == nil {
+		tw.writePending()
+	}
+}
+
+// writePending aligns and then writes out the pending lines.
+func (tw *TokenWriter) writePending() {
+	formatCells(tw.pending)
+	for _, line := range tw.pending {
+		for _, cell := range []Tokens{line.lead, line.assign, line.comment} {
+			if tw.err != nil {
+				break
+			}
+			_, tw.err = cell.WriteTo(tw.w)
+		}
+	}
+	tw.pending = tw.pending[:0]
+}
@@ -0,0 +1,127 @@
+package hclwrite
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestTokenWriter(t *testing.T) {
+	tests := []string{
+		``,
+		`a=1`,
+		"a=1\n",
+		"a=1\nbcd=2 # comment\n\n# lead comment\nef = [\n1,\n2,\n]\n",
+		"block \"label\" {\na=1\nbb = {\nc=\"d\" // comment\ndd=\"${e}\"\n}\n}\n",
+		"a = <<EOT\n  hello\nEOT\nbb = -1\n",
+		"x = [for v in vals: v if v > 0]\ny = provider::ns::fn(1)\n",
+		"a {\nb {\nc = 1\n}\n}\nd = 2\n",
+	}
+
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			want := string(Format([]byte(src)))
+
+			var buf bytes.Buffer
+			tw := NewTokenWriter(&buf)
+			for _, tok := range lexConfig([]byte(src)) {
+				if err := tw.WriteToken(tok); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			}
+			if err := tw.Flush(); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got := buf.String(); got != want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestTokenWriterIncremental(t *testing.T) {
+	var buf bytes.Buffer
+	tw := NewTokenWriter(&buf)
+
+	for i := 0; i < 2; i++ {
+		tw.WriteTokens(Tokens{
+			{Type: hclsyntax.TokenIdent, Bytes: []byte("user")},
+			{Type: hclsyntax.TokenOBrace, Bytes: []byte("{")},
+			{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+		})
+		// Each complete line that need not be aligned with the lines
+		// after it is written immediately.
+		if got, want := bytes.Count(buf.Bytes(), []byte("\n")), i*5+1; got != want {
+			t.Errorf("%d lines have been written; want %d", got, want)
+		}
+
+		for _, attr := range []struct {
+			name string
+			val  cty.Value
+		}{
+			{"id", cty.NumberIntVal(int64(i))},
+			{"email", cty.StringVal(fmt.Sprintf("user%d@example.com", i))},
+		} {
+			tw.WriteTokens(Tokens{
+				{Type: hclsyntax.TokenIdent, Bytes: []byte(attr.name)},
+				{Type: hclsyntax.TokenEqual, Bytes: []byte("=")},
+			})
+			tw.WriteTokens(TokensForValue(attr.val))
+			tw.WriteToken(&Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+		}
+		tw.WriteTokens(Tokens{
+			{Type: hclsyntax.TokenCBrace, Bytes: []byte("}")},
+			{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+			{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+		})
+	}
+	if err := tw.Flush(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := `user {
+  id    = 0
+  email = "user0@example.com"
+}
+
+user {
+  id    = 1
+  email = "user1@example.com"
+}
+
+`
+	if got := buf.String(); got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTokenWriterError(t *testing.T) {
+	wantErr := errors.New("write failed")
+	tw := NewTokenWriter(testFailingWriter{wantErr})
+
+	if err := tw.WriteToken(&Token{Type: hclsyntax.TokenIdent, Bytes: []byte("a")}); err != nil {
+		t.Fatalf("unexpected error before end of line: %s", err)
+	}
+	if err := tw.WriteToken(&Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")}); err != wantErr {
+		t.Errorf("wrong error %v; want %v", err, wantErr)
+	}
+	if err := tw.WriteToken(&Token{Type: hclsyntax.TokenIdent, Bytes: []byte("b")}); err != wantErr {
+		t.Errorf("wrong error %v after failure; want %v", err, wantErr)
+	}
+	if err := tw.Flush(); err != wantErr {
+		t.Errorf("wrong error %v from Flush; want %v", err, wantErr)
+	}
+}
+
+type testFailingWriter struct {
+	err error
+}
+
+func (w testFailingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
@@ -0,0 +1,326 @@
+package specsuite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcldec"
+	"github.com/hashicorp/hcl2/hcldec/specfile"
+	"github.com/hashicorp/hcl2/hclparse"
+)
+
+// Decoder is the interface to the implementation under test, which decodes
+// an input file using a decoder specification file in the format accepted
+// by the hcldec program.
+//
+// Both methods must return diagnostics with source ranges that refer to the
+// given filenames, since test files describe expected diagnostics by their
+// position within the input file.
+type Decoder interface {
+	// Decode decodes the given input file, which is in the JSON syntax if
+	// its name ends in ".json" and in the native syntax otherwise.
+	Decode(specFile, inputFile string) (cty.Value, hcl.Diagnostics)
+
+	// Variables returns the variables referenced by the given input file
+	// when decoded with the given spec.
+	Variables(specFile, inputFile string) ([]hcl.Traversal, hcl.Diagnostics)
+}
+
+// ExecDecoder returns a decoder that runs the program at the given path,
+// which must accept the same command line arguments and produce the same
+// output as the hcldec program in this repository.
+//
+// This allows the suite to be run against other implementations of HCL,
+// which can provide their own version of hcldec.
+func ExecDecoder(path string) Decoder {
+	return &execDecoder{
+		path: path,
+	}
+}
+
+type execDecoder struct {
+	path string
+}
+
+func (d *execDecoder) Decode(specFile, inputFile string) (cty.Value, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	var outBuffer bytes.Buffer
+	var errBuffer bytes.Buffer
+
+	cmd := &exec.Cmd{
+		Path: d.path,
+		Args: []string{
+			d.path,
+			"--spec=" + specFile,
+			"--diags=json",
+			"--with-type",
+			inputFile,
+		},
+		Stdout: &outBuffer,
+		Stderr: &errBuffer,
+	}
+	err := cmd.Run()
+	if err != nil {
+		if _, isExit := err.(*exec.ExitError); !isExit {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to run hcldec",
+				Detail:   fmt.Sprintf("Sub-program hcldec failed to start: %s.", err),
+			})
+			return cty.DynamicVal, diags
+		}
+
+		// If we exited unsuccessfully then we'll expect diagnostics on stderr
+		moreDiags := decodeJSONDiagnostics(errBuffer.Bytes())
+		diags = append(diags, moreDiags...)
+		return cty.DynamicVal, diags
+	} else {
+		// Otherwise, we expect a JSON result value on stdout. Since we used
+		// --with-type above, we can decode as DynamicPseudoType to recover
+		// exactly the type that was saved, without the usual JSON lossiness.
+		val, err := ctyjson.Unmarshal(outBuffer.Bytes(), cty.DynamicPseudoType)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to parse hcldec result",
+				Detail:   fmt.Sprintf("Sub-program hcldec produced an invalid result: %s.", err),
+			})
+			return cty.DynamicVal, diags
+		}
+		return val, diags
+	}
+}
+
+func (d *execDecoder) Variables(specFile, inputFile string) ([]hcl.Traversal, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	var outBuffer bytes.Buffer
+	var errBuffer bytes.Buffer
+
+	cmd := &exec.Cmd{
+		Path: d.path,
+		Args: []string{
+			d.path,
+			"--spec=" + specFile,
+			"--diags=json",
+			"--var-refs",
+			inputFile,
+		},
+		Stdout: &outBuffer,
+		Stderr: &errBuffer,
+	}
+	err := cmd.Run()
+	if err != nil {
+		if _, isExit := err.(*exec.ExitError); !isExit {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to run hcldec",
+				Detail:   fmt.Sprintf("Sub-program hcldec (evaluating input) failed to start: %s.", err),
+			})
+			return nil, diags
+		}
+
+		// If we exited unsuccessfully then we'll expect diagnostics on stderr
+		moreDiags := decodeJSONDiagnostics(errBuffer.Bytes())
+		diags = append(diags, moreDiags...)
+		return nil, diags
+	} else {
+		// Otherwise, we expect a JSON description of the traversals on stdout.
+		type PosJSON struct {
+			Line   int `json:"line"`
+			Column int `json:"column"`
+			Byte   int `json:"byte"`
+		}
+		type RangeJSON struct {
+			Filename string  `json:"filename"`
+			Start    PosJSON `json:"start"`
+			End      PosJSON `json:"end"`
+		}
+		type StepJSON struct {
+			Kind  string          `json:"kind"`
+			Name  string          `json:"name,omitempty"`
+			Key   json.RawMessage `json:"key,omitempty"`
+			Range RangeJSON       `json:"range"`
+		}
+		type TraversalJSON struct {
+			Steps []StepJSON `json:"steps"`
+		}
+
+		var raw []TraversalJSON
+		err := json.Unmarshal(outBuffer.Bytes(), &raw)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to parse hcldec result",
+				Detail:   fmt.Sprintf("Sub-program hcldec (with --var-refs) produced an invalid result: %s.", err),
+			})
+			return nil, diags
+		}
+
+		var ret []hcl.Traversal
+		if len(raw) == 0 {
+			return ret, diags
+		}
+
+		ret = make([]hcl.Traversal, 0, len(raw))
+		for _, rawT := range raw {
+			traversal := make(hcl.Traversal, 0, len(rawT.Steps))
+			for _, rawS := range rawT.Steps {
+				rng := hcl.Range{
+					Filename: rawS.Range.Filename,
+					Start: hcl.Pos{
+						Line:   rawS.Range.Start.Line,
+						Column: rawS.Range.Start.Column,
+						Byte:   rawS.Range.Start.Byte,
+					},
+					End: hcl.Pos{
+						Line:   rawS.Range.End.Line,
+						Column: rawS.Range.End.Column,
+						Byte:   rawS.Range.End.Byte,
+					},
+				}
+
+				switch rawS.Kind {
+
+				case "root":
+					traversal = append(traversal, hcl.TraverseRoot{
+						Name:     rawS.Name,
+						SrcRange: rng,
+					})
+
+				case "attr":
+					traversal = append(traversal, hcl.TraverseAttr{
+						Name:     rawS.Name,
+						SrcRange: rng,
+					})
+
+				case "index":
+					ty, err := ctyjson.ImpliedType([]byte(rawS.Key))
+					if err != nil {
+						diags = append(diags, &hcl.Diagnostic{
+							Severity: hcl.DiagError,
+							Summary:  "Failed to parse hcldec result",
+							Detail:   fmt.Sprintf("Sub-program hcldec (with --var-refs) produced an invalid result: traversal step has invalid index key %s.", rawS.Key),
+						})
+						return nil, diags
+					}
+					keyVal, err := ctyjson.Unmarshal([]byte(rawS.Key), ty)
+					if err != nil {
+						diags = append(diags, &hcl.Diagnostic{
+							Severity: hcl.DiagError,
+							Summary:  "Failed to parse hcldec result",
+							Detail:   fmt.Sprintf("Sub-program hcldec (with --var-refs) produced a result with an invalid index key %s: %s.", rawS.Key, err),
+						})
+						return nil, diags
+					}
+
+					traversal = append(traversal, hcl.TraverseIndex{
+						Key:      keyVal,
+						SrcRange: rng,
+					})
+
+				default:
+					// Should never happen since the above cases are exhaustive,
+					// but we'll catch it gracefully since this is coming from
+					// a possibly-buggy hcldec implementation that we're testing.
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Failed to parse hcldec result",
+						Detail:   fmt.Sprintf("Sub-program hcldec (with --var-refs) produced an invalid result: traversal step of unsupported kind %q.", rawS.Kind),
+					})
+					return nil, diags
+				}
+			}
+
+			ret = append(ret, traversal)
+		}
+		return ret, diags
+	}
+}
+
+// NativeDecoder returns a decoder that uses the parsers and the hcldec
+// package from this repository directly, without running a separate
+// program.
+func NativeDecoder() Decoder {
+	return nativeDecoder{}
+}
+
+type nativeDecoder struct{}
+
+func (d nativeDecoder) Decode(specFile, inputFile string) (cty.Value, hcl.Diagnostics) {
+	spec, body, ctx, diags := d.load(specFile, inputFile)
+	if diags.HasErrors() {
+		return cty.DynamicVal, diags
+	}
+	val, moreDiags := hcldec.Decode(body, spec, ctx)
+	diags = append(diags, moreDiags...)
+	if diags.HasErrors() {
+		return cty.DynamicVal, diags
+	}
+	return val, diags
+}
+
+func (d nativeDecoder) Variables(specFile, inputFile string) ([]hcl.Traversal, hcl.Diagnostics) {
+	spec, body, _, diags := d.load(specFile, inputFile)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return hcldec.Variables(body, spec), diags
+}
+
+// load prepares to decode the given input file in the same way as the
+// hcldec program, with the variables and functions from the spec file.
+func (d nativeDecoder) load(specFile, inputFile string) (hcldec.Spec, hcl.Body, *hcl.EvalContext, hcl.Diagnostics) {
+	// We use a new parser each time, since the parser caches files by name
+	// and so would not notice if a file changed between tests.
+	parser := hclparse.NewParser()
+
+	f, diags := parser.ParseHCLFile(specFile)
+	if diags.HasErrors() {
+		return nil, nil, nil, diags
+	}
+	content, moreDiags := specfile.Decode(f.Body)
+	diags = append(diags, moreDiags...)
+	if moreDiags.HasErrors() {
+		return nil, nil, nil, diags
+	}
+
+	if strings.HasSuffix(inputFile, ".json") {
+		f, moreDiags = parser.ParseJSONFile(inputFile)
+	} else {
+		f, moreDiags = parser.ParseHCLFile(inputFile)
+	}
+	diags = append(diags, moreDiags...)
+	if moreDiags.HasErrors() {
+		return nil, nil, nil, diags
+	}
+
+	// As in hcldec, we leave the context nil when the spec file doesn't
+	// define any variables or functions, so that we'll produce "variables
+	// are not allowed" errors rather than "variable not found" errors.
+	var ctx *hcl.EvalContext
+	if len(content.Variables) != 0 || len(content.Functions) != 0 {
+		ctx = &hcl.EvalContext{}
+		if len(content.Variables) != 0 {
+			ctx.Variables = make(map[string]cty.Value, len(content.Variables))
+			for name, val := range content.Variables {
+				ctx.Variables[name] = val
+			}
+		}
+		if len(content.Functions) != 0 {
+			ctx.Functions = make(map[string]function.Function, len(content.Functions))
+			for name, f := range content.Functions {
+				ctx.Functions[name] = f
+			}
+		}
+	}
+
+	return content.RootSpec, f.Body, ctx, diags
+}
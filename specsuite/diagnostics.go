@@ -1,4 +1,4 @@
-package main
+package specsuite
 
 import (
 	"encoding/json"
@@ -52,6 +52,8 @@ func decodeJSONDiagnostics(src []byte) hcl.Diagnostics {
 			severity = hcl.DiagError
 		case "warning":
 			severity = hcl.DiagWarning
+		case "info":
+			severity = hcl.DiagInfo
 		default:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
@@ -94,6 +96,8 @@ func severityString(severity hcl.DiagnosticSeverity) string {
 		return "error"
 	case hcl.DiagWarning:
 		return "warning"
+	case hcl.DiagInfo:
+		return "info"
 	default:
 		return "unsupported-severity"
 	}
@@ -0,0 +1,9 @@
+// Package specsuite runs the HCL specification test suite, whose test files
+// are in the "tests" subdirectory of this package's directory.
+//
+// The suite is run against a Decoder, which is either an external program
+// that behaves like the hcldec program in this repository, as returned by
+// ExecDecoder, or the implementation in this repository itself, as returned
+// by NativeDecoder. See the README file in this directory for a description
+// of the test file format.
+package specsuite
@@ -1,4 +1,4 @@
-package main
+package specsuite
 
 import (
 	"github.com/hashicorp/hcl2/hcl"
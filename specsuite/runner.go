@@ -1,33 +1,54 @@
-package main
+package specsuite
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/convert"
-	ctyjson "github.com/zclconf/go-cty/cty/json"
 
 	"github.com/hashicorp/hcl2/ext/typeexpr"
 	"github.com/hashicorp/hcl2/hcl"
 	"github.com/hashicorp/hcl2/hclparse"
 )
 
+// Runner runs the tests in a directory of test files against a Decoder.
 type Runner struct {
 	parser      *hclparse.Parser
-	hcldecPath  string
+	decoder     Decoder
 	baseDir     string
 	logBegin    LogBeginCallback
 	logProblems LogProblemsCallback
 }
 
+// NewRunner returns a runner for the tests in the given directory and its
+// subdirectories, using the given decoder.
+//
+// The given parser is used to load the test files, and the spec and input
+// files are also added to it so that the returned diagnostics can be
+// rendered with source snippets. If it is nil, a new parser is used.
+//
+// The log callbacks are optional. If logProblems is set, the diagnostics
+// for each test are passed to it rather than being returned from Run.
+func NewRunner(dir string, decoder Decoder, parser *hclparse.Parser, logBegin LogBeginCallback, logProblems LogProblemsCallback) *Runner {
+	if parser == nil {
+		parser = hclparse.NewParser()
+	}
+	return &Runner{
+		parser:      parser,
+		decoder:     decoder,
+		baseDir:     dir,
+		logBegin:    logBegin,
+		logProblems: logProblems,
+	}
+}
+
+// Run runs all of the tests, returning diagnostics that describe any test
+// failures along with any problems with the test files themselves.
 func (r *Runner) Run() hcl.Diagnostics {
 	return r.runDir(r.baseDir)
 }
@@ -155,7 +176,7 @@ func (r *Runner) runTestInput(specFilename, inputFilename string, tf *TestFile)
 	var diags hcl.Diagnostics
 
 	if tf.ChecksTraversals {
-		gotTraversals, moreDiags := r.hcldecVariables(specFilename, inputFilename)
+		gotTraversals, moreDiags := r.decoder.Variables(specFilename, inputFilename)
 		diags = append(diags, moreDiags...)
 		if !moreDiags.HasErrors() {
 			expected := tf.ExpectedTraversals
@@ -190,7 +211,7 @@ func (r *Runner) runTestInput(specFilename, inputFilename string, tf *TestFile)
 
 	}
 
-	val, transformDiags := r.hcldecTransform(specFilename, inputFilename)
+	val, transformDiags := r.decoder.Decode(specFilename, inputFilename)
 	if len(tf.ExpectedDiags) == 0 {
 		diags = append(diags, transformDiags...)
 		if transformDiags.HasErrors() {
@@ -306,201 +327,6 @@ func (r *Runner) runTestInput(specFilename, inputFilename string, tf *TestFile)
 	return diags
 }
 
-func (r *Runner) hcldecTransform(specFile, inputFile string) (cty.Value, hcl.Diagnostics) {
-	var diags hcl.Diagnostics
-	var outBuffer bytes.Buffer
-	var errBuffer bytes.Buffer
-
-	cmd := &exec.Cmd{
-		Path: r.hcldecPath,
-		Args: []string{
-			r.hcldecPath,
-			"--spec=" + specFile,
-			"--diags=json",
-			"--with-type",
-			inputFile,
-		},
-		Stdout: &outBuffer,
-		Stderr: &errBuffer,
-	}
-	err := cmd.Run()
-	if err != nil {
-		if _, isExit := err.(*exec.ExitError); !isExit {
-			diags = append(diags, &hcl.Diagnostic{
-				Severity: hcl.DiagError,
-				Summary:  "Failed to run hcldec",
-				Detail:   fmt.Sprintf("Sub-program hcldec failed to start: %s.", err),
-			})
-			return cty.DynamicVal, diags
-		}
-
-		// If we exited unsuccessfully then we'll expect diagnostics on stderr
-		moreDiags := decodeJSONDiagnostics(errBuffer.Bytes())
-		diags = append(diags, moreDiags...)
-		return cty.DynamicVal, diags
-	} else {
-		// Otherwise, we expect a JSON result value on stdout. Since we used
-		// --with-type above, we can decode as DynamicPseudoType to recover
-		// exactly the type that was saved, without the usual JSON lossiness.
-		val, err := ctyjson.Unmarshal(outBuffer.Bytes(), cty.DynamicPseudoType)
-		if err != nil {
-			diags = append(diags, &hcl.Diagnostic{
-				Severity: hcl.DiagError,
-				Summary:  "Failed to parse hcldec result",
-				Detail:   fmt.Sprintf("Sub-program hcldec produced an invalid result: %s.", err),
-			})
-			return cty.DynamicVal, diags
-		}
-		return val, diags
-	}
-}
-
-func (r *Runner) hcldecVariables(specFile, inputFile string) ([]hcl.Traversal, hcl.Diagnostics) {
-	var diags hcl.Diagnostics
-	var outBuffer bytes.Buffer
-	var errBuffer bytes.Buffer
-
-	cmd := &exec.Cmd{
-		Path: r.hcldecPath,
-		Args: []string{
-			r.hcldecPath,
-			"--spec=" + specFile,
-			"--diags=json",
-			"--var-refs",
-			inputFile,
-		},
-		Stdout: &outBuffer,
-		Stderr: &errBuffer,
-	}
-	err := cmd.Run()
-	if err != nil {
-		if _, isExit := err.(*exec.ExitError); !isExit {
-			diags = append(diags, &hcl.Diagnostic{
-				Severity: hcl.DiagError,
-				Summary:  "Failed to run hcldec",
-				Detail:   fmt.Sprintf("Sub-program hcldec (evaluating input) failed to start: %s.", err),
-			})
-			return nil, diags
-		}
-
-		// If we exited unsuccessfully then we'll expect diagnostics on stderr
-		moreDiags := decodeJSONDiagnostics(errBuffer.Bytes())
-		diags = append(diags, moreDiags...)
-		return nil, diags
-	} else {
-		// Otherwise, we expect a JSON description of the traversals on stdout.
-		type PosJSON struct {
-			Line   int `json:"line"`
-			Column int `json:"column"`
-			Byte   int `json:"byte"`
-		}
-		type RangeJSON struct {
-			Filename string  `json:"filename"`
-			Start    PosJSON `json:"start"`
-			End      PosJSON `json:"end"`
-		}
-		type StepJSON struct {
-			Kind  string          `json:"kind"`
-			Name  string          `json:"name,omitempty"`
-			Key   json.RawMessage `json:"key,omitempty"`
-			Range RangeJSON       `json:"range"`
-		}
-		type TraversalJSON struct {
-			Steps []StepJSON `json:"steps"`
-		}
-
-		var raw []TraversalJSON
-		err := json.Unmarshal(outBuffer.Bytes(), &raw)
-		if err != nil {
-			diags = append(diags, &hcl.Diagnostic{
-				Severity: hcl.DiagError,
-				Summary:  "Failed to parse hcldec result",
-				Detail:   fmt.Sprintf("Sub-program hcldec (with --var-refs) produced an invalid result: %s.", err),
-			})
-			return nil, diags
-		}
-
-		var ret []hcl.Traversal
-		if len(raw) == 0 {
-			return ret, diags
-		}
-
-		ret = make([]hcl.Traversal, 0, len(raw))
-		for _, rawT := range raw {
-			traversal := make(hcl.Traversal, 0, len(rawT.Steps))
-			for _, rawS := range rawT.Steps {
-				rng := hcl.Range{
-					Filename: rawS.Range.Filename,
-					Start: hcl.Pos{
-						Line:   rawS.Range.Start.Line,
-						Column: rawS.Range.Start.Column,
-						Byte:   rawS.Range.Start.Byte,
-					},
-					End: hcl.Pos{
-						Line:   rawS.Range.End.Line,
-						Column: rawS.Range.End.Column,
-						Byte:   rawS.Range.End.Byte,
-					},
-				}
-
-				switch rawS.Kind {
-
-				case "root":
-					traversal = append(traversal, hcl.TraverseRoot{
-						Name:     rawS.Name,
-						SrcRange: rng,
-					})
-
-				case "attr":
-					traversal = append(traversal, hcl.TraverseAttr{
-						Name:     rawS.Name,
-						SrcRange: rng,
-					})
-
-				case "index":
-					ty, err := ctyjson.ImpliedType([]byte(rawS.Key))
-					if err != nil {
-						diags = append(diags, &hcl.Diagnostic{
-							Severity: hcl.DiagError,
-							Summary:  "Failed to parse hcldec result",
-							Detail:   fmt.Sprintf("Sub-program hcldec (with --var-refs) produced an invalid result: traversal step has invalid index key %s.", rawS.Key),
-						})
-						return nil, diags
-					}
-					keyVal, err := ctyjson.Unmarshal([]byte(rawS.Key), ty)
-					if err != nil {
-						diags = append(diags, &hcl.Diagnostic{
-							Severity: hcl.DiagError,
-							Summary:  "Failed to parse hcldec result",
-							Detail:   fmt.Sprintf("Sub-program hcldec (with --var-refs) produced a result with an invalid index key %s: %s.", rawS.Key, err),
-						})
-						return nil, diags
-					}
-
-					traversal = append(traversal, hcl.TraverseIndex{
-						Key:      keyVal,
-						SrcRange: rng,
-					})
-
-				default:
-					// Should never happen since the above cases are exhaustive,
-					// but we'll catch it gracefully since this is coming from
-					// a possibly-buggy hcldec implementation that we're testing.
-					diags = append(diags, &hcl.Diagnostic{
-						Severity: hcl.DiagError,
-						Summary:  "Failed to parse hcldec result",
-						Detail:   fmt.Sprintf("Sub-program hcldec (with --var-refs) produced an invalid result: traversal step of unsupported kind %q.", rawS.Kind),
-					})
-					return nil, diags
-				}
-			}
-
-			ret = append(ret, traversal)
-		}
-		return ret, diags
-	}
-}
-
 func (r *Runner) prettyDirName(dir string) string {
 	rel, err := filepath.Rel(r.baseDir, dir)
 	if err != nil {
@@ -0,0 +1,22 @@
+package specsuite
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+)
+
+func TestNativeDecoder(t *testing.T) {
+	logProblems := func(name string, file *TestFile, diags hcl.Diagnostics) {
+		t.Run(name, func(t *testing.T) {
+			for _, diag := range diags {
+				t.Error(diag)
+			}
+		})
+	}
+	runner := NewRunner("tests", NativeDecoder(), nil, nil, logProblems)
+
+	for _, diag := range runner.Run() {
+		t.Error(diag)
+	}
+}
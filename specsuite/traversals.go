@@ -1,15 +1,14 @@
-package main
+package specsuite
 
 import (
 	"fmt"
-	"reflect"
 
 	"github.com/hashicorp/hcl2/hcl"
 )
 
 func findTraversalSpec(got hcl.Traversal, candidates []*TestFileExpectTraversal) *TestFileExpectTraversal {
 	for _, candidate := range candidates {
-		if traversalsAreEquivalent(candidate.Traversal, got) {
+		if candidate.Traversal.Equal(got) {
 			return candidate
 		}
 	}
@@ -18,50 +17,13 @@ func findTraversalSpec(got hcl.Traversal, candidates []*TestFileExpectTraversal)
 
 func findTraversalForSpec(want *TestFileExpectTraversal, have []hcl.Traversal) hcl.Traversal {
 	for _, candidate := range have {
-		if traversalsAreEquivalent(candidate, want.Traversal) {
+		if candidate.Equal(want.Traversal) {
 			return candidate
 		}
 	}
 	return nil
 }
 
-func traversalsAreEquivalent(a, b hcl.Traversal) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for i := range a {
-		aStep := a[i]
-		bStep := b[i]
-
-		if reflect.TypeOf(aStep) != reflect.TypeOf(bStep) {
-			return false
-		}
-
-		// We can now assume that both are of the same type.
-		switch ts := aStep.(type) {
-
-		case hcl.TraverseRoot:
-			if bStep.(hcl.TraverseRoot).Name != ts.Name {
-				return false
-			}
-
-		case hcl.TraverseAttr:
-			if bStep.(hcl.TraverseAttr).Name != ts.Name {
-				return false
-			}
-
-		case hcl.TraverseIndex:
-			if !bStep.(hcl.TraverseIndex).Key.RawEquals(ts.Key) {
-				return false
-			}
-
-		default:
-			return false
-		}
-	}
-	return true
-}
-
 // checkTraversalsMatch determines if a given traversal matches the given
 // expectation, which must've been produced by an earlier call to
 // findTraversalSpec for the same traversal.